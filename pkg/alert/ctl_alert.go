@@ -28,6 +28,7 @@ import (
 
 	blackduckv1 "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
 	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -50,18 +51,29 @@ type FlagTree struct {
 	DeploymentResourcesFilePath string
 	Registry                    string
 	PullSecrets                 []string
+	ImagePullPolicy             string
+	PinImageDigests             bool
 	StandAlone                  string
 	ExposeService               string
+	IngressClass                string
+	IngressHost                 string
+	IngressTLSSecret            string
 	EncryptionPassword          string
 	EncryptionGlobalSalt        string
 	CertificateFilePath         string
 	CertificateKeyFilePath      string
+	CertificateIssuer           string
 	JavaKeyStoreFilePath        string
+	KeystorePasswordFilePath    string
+	JavaKeyStoreFromPEM         []string
 	Environs                    []string
+	SecretEnvironsFilePath      string
 	PersistentStorage           string
 	PVCStorageClass             string
 	PVCFilePath                 string
 	SecurityContextFilePath     string
+	ExtraVolumes                []string
+	ExtraVolumesFilePath        string
 	Port                        int32
 
 	// Postgres
@@ -116,67 +128,83 @@ func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
 	}
 }
 
-// AddCobraFlagsToCommand adds flags for the Alert Helm Chart to the cmd
-func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
-	// [DEV NOTE:] please organize flags in order of importance
-	cmd.Flags().SortFlags = false
-
-	defaults := &FlagTree{}
-	if isCreateCmd {
-		defaults = GetDefaultFlagTree()
-	}
-
+// alertFlags are the flags of the Alert Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var alertFlags = flags.NewSet("Alert", []flags.Flag{
 	// Application Version and Image Tag
-	cmd.Flags().StringVar(&ctl.flagTree.Version, "version", defaults.Version, "Version of Alert\n")
+	{Name: "version", Field: "Version", Usage: "Version of Alert\n", Path: []string{"alert", "imageTag"}},
 
 	// Storage
-	cmd.Flags().StringVar(&ctl.flagTree.DeploymentResourcesFilePath, "deployment-resources-file-path", defaults.DeploymentResourcesFilePath, "Absolute path to a file containing a list of deployment Resources json structs")
-	if isCreateCmd {
-		cmd.Flags().StringVar(&ctl.flagTree.PVCStorageClass, "pvc-storage-class", defaults.PVCStorageClass, "Storage class for the persistent volume claim")
-		cmd.Flags().StringVar(&ctl.flagTree.PersistentStorage, "persistent-storage", defaults.PersistentStorage, "If true, Alert has persistent storage [true|false]")
-	}
-	cmd.Flags().StringVar(&ctl.flagTree.PVCFilePath, "pvc-file-path", defaults.PVCFilePath, "Absolute path to a file containing a list of PVC json structs\n")
+	{Name: "deployment-resources-file-path", Field: "DeploymentResourcesFilePath", Usage: "Absolute path to a file containing a list of deployment Resources json structs"},
+	{Name: "pvc-storage-class", Field: "PVCStorageClass", Usage: "Storage class for the persistent volume claim", Path: []string{"storageClass"}, CreateOnly: true},
+	{Name: "persistent-storage", Field: "PersistentStorage", Kind: flags.Bool, Usage: "If true, Alert has persistent storage [true|false]", Path: []string{"enablePersistentStorage"}, CreateOnly: true},
+	{Name: "pvc-file-path", Field: "PVCFilePath", Usage: "Absolute path to a file containing a list of PVC json structs\n"},
 
 	// Pulling images values
-	cmd.Flags().StringVar(&ctl.flagTree.Registry, "registry", defaults.Registry, "Name of the registry to use for images")
-	cmd.Flags().StringSliceVar(&ctl.flagTree.PullSecrets, "pull-secret-name", defaults.PullSecrets, "Only if the registry requires authentication\n")
+	{Name: "registry", Field: "Registry", Usage: "Name of the registry to use for images", Path: []string{"registry"}},
+	{Name: "pull-secret-name", Field: "PullSecrets", Usage: "Only if the registry requires authentication", Path: []string{"imagePullSecrets"}},
+	{Name: "image-pull-policy", Field: "ImagePullPolicy", Usage: "Image pull policy of all containers [Always|IfNotPresent|Never], empty keeps the policies of the chart"},
+	{Name: "pin-image-digests", Field: "PinImageDigests", Kind: flags.Bool, Usage: "If true, resolve the image tags to digests at install time and record them in the values so the images do not change until the next version change\n"},
 
 	// Standalone (uses it's own cfssl)
-	cmd.Flags().StringVar(&ctl.flagTree.StandAlone, "standalone", defaults.StandAlone, "If true, Alert runs in standalone mode [true|false]\n")
+	{Name: "standalone", Field: "StandAlone", Kind: flags.Bool, Usage: "If true, Alert runs in standalone mode [true|false]\n", Path: []string{"enableStandalone"}},
 
 	// Exposing the UI
-	cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", defaults.ExposeService, "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n")
+	{Name: "expose-ui", Field: "ExposeService", Usage: "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|INGRESS|OPENSHIFT|NONE]"},
+	{Name: "ingress-class", Field: "IngressClass", Usage: "Ingress class of the ingress of Alert's user interface (default the default class of the cluster)"},
+	{Name: "ingress-host", Field: "IngressHost", Usage: "Host of the ingress of Alert's user interface"},
+	{Name: "ingress-tls-secret", Field: "IngressTLSSecret", Usage: "Name of the TLS secret of the ingress of Alert's user interface, empty terminates TLS with the default certificate of the ingress controller\n"},
 
 	// Secrets Values
-	cmd.Flags().StringVar(&ctl.flagTree.EncryptionPassword, "encryption-password", defaults.EncryptionPassword, "Encryption Password for Alert")
-	cmd.Flags().StringVar(&ctl.flagTree.EncryptionGlobalSalt, "encryption-global-salt", defaults.EncryptionGlobalSalt, "Encryption Global Salt for Alert")
-	cmd.Flags().StringVar(&ctl.flagTree.CertificateFilePath, "certificate-file-path", defaults.CertificateFilePath, "Absolute path to the PEM certificate to use for Alert")
-	cmd.Flags().StringVar(&ctl.flagTree.CertificateKeyFilePath, "certificate-key-file-path", defaults.CertificateKeyFilePath, "Absolute path to the PEM certificate key for Alert")
-	cmd.Flags().StringVar(&ctl.flagTree.JavaKeyStoreFilePath, "java-keystore-file-path", defaults.JavaKeyStoreFilePath, "Absolute path to the Java Keystore to use for Alert\n")
+	{Name: "encryption-password", Field: "EncryptionPassword", Usage: "Encryption Password for Alert", Secret: true},
+	{Name: "encryption-global-salt", Field: "EncryptionGlobalSalt", Usage: "Encryption Global Salt for Alert", Secret: true},
+	{Name: "certificate-file-path", Field: "CertificateFilePath", Usage: "Absolute path to the PEM certificate to use for Alert"},
+	{Name: "certificate-key-file-path", Field: "CertificateKeyFilePath", Usage: "Absolute path to the PEM certificate key for Alert"},
+	{Name: "certificate-issuer", Field: "CertificateIssuer", Usage: "cert-manager issuer of the certificate of the Alert host instead of --certificate-file-path, in the format [Issuer/|ClusterIssuer/]NAME, empty to stop using it"},
+	{Name: "java-keystore-file-path", Field: "JavaKeyStoreFilePath", Usage: "Absolute path to the Java Keystore to use for Alert"},
+	{Name: "keystore-password-file", Field: "KeystorePasswordFilePath", Usage: "Absolute path to a file containing the password of the Java Keystore (default password 'changeit')"},
+	{Name: "from-pem", Field: "JavaKeyStoreFromPEM", Usage: "Absolute paths to PEM certificates to build the Java Keystore from instead of --java-keystore-file-path\n"},
 
 	// Environs
-	cmd.Flags().StringSliceVar(&ctl.flagTree.Environs, "environs", defaults.Environs, "Environment variables of Alert\n")
+	{Name: "environs", Field: "Environs", Usage: "Environment variables of Alert"},
+	{Name: "secret-environs-file", Field: "SecretEnvironsFilePath", Usage: "Absolute path to a file mapping the names of environment variables to the secret and key they are read from, replaces the previous secret environment variables\n"},
 
 	// Security Contexts
-	cmd.Flags().StringVar(&ctl.flagTree.SecurityContextFilePath, "security-context-file-path", defaults.SecurityContextFilePath, "Absolute path to a file containing a map of pod names to security contexts runAsUser, fsGroup, and runAsGroup\n")
+	{Name: "security-context-file-path", Field: "SecurityContextFilePath", Usage: "Absolute path to a file containing a map of pod names to security contexts runAsUser, fsGroup, and runAsGroup"},
+	{Name: "extra-volume", Field: "ExtraVolumes", Usage: "Extra volume to mount into all containers of a component in the format component=name:pvc|configmap|secret|emptydir:/mount/path[:ro], replaces the previous extra volumes", Repeatable: true},
+	{Name: "extra-volumes-file-path", Field: "ExtraVolumesFilePath", Usage: "Absolute path to a file containing a list of extra volumes with component, name, type, mountPath, subPath and readOnly\n"},
 
 	// Port
-	cmd.Flags().Int32Var(&ctl.flagTree.Port, "port", defaults.Port, "Port of Alert") // only for devs
-	cmd.Flags().MarkHidden("port")
+	{Name: "port", Field: "Port", Usage: "Port of Alert", Path: []string{"alert", "port"}, Hidden: true}, // only for devs
 
 	// Postgres
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresIsExternal, "postgres-external", defaults.PostgresIsExternal, "If true, Synopsys Alert uses external database [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresHost, "postgres-host", defaults.PostgresHost, "Host of Postgres")
-	cmd.Flags().Int32Var(&ctl.flagTree.PostgresPort, "postgres-port", defaults.PostgresPort, "Port of Postgres")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresUsername, "postgres-user", defaults.PostgresUsername, "Name of 'user' of Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresPassword, "postgres-password", defaults.PostgresPassword, "'user' password of Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresDatabaseName, "postgres-database", defaults.PostgresDatabaseName, "Name of Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresSsl, "postgres-ssl", defaults.PostgresSsl, "If true, Synopsys Alert uses SSL for external Postgres connection [true|false]")
+	{Name: "postgres-external", Field: "PostgresIsExternal", Kind: flags.Bool, Usage: "If true, Synopsys Alert uses external database [true|false]", Path: []string{"postgres", "isExternal"}},
+	{Name: "postgres-host", Field: "PostgresHost", Usage: "Host of Postgres", Path: []string{"postgres", "host"}},
+	{Name: "postgres-port", Field: "PostgresPort", Usage: "Port of Postgres", Path: []string{"postgres", "port"}},
+	{Name: "postgres-user", Field: "PostgresUsername", Usage: "Name of 'user' of Postgres database", Path: []string{"postgres", "userUserName"}},
+	{Name: "postgres-password", Field: "PostgresPassword", Usage: "'user' password of Postgres database", Path: []string{"postgres", "userPassword"}, Secret: true},
+	{Name: "postgres-database", Field: "PostgresDatabaseName", Usage: "Name of Postgres database", Path: []string{"postgres", "databaseName"}},
+	{Name: "postgres-ssl", Field: "PostgresSsl", Kind: flags.Bool, Usage: "If true, Synopsys Alert uses SSL for external Postgres connection [true|false]", Path: []string{"postgres", "ssl"}},
+})
+
+// AddCobraFlagsToCommand adds flags for the Alert Helm Chart to the cmd
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
+	}
+
+	alertFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
 }
 
 // CheckValuesFromFlags returns an error if a value stored in the struct will not be able to be
 // used in the AlertSpec
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if err := alertFlags.Validate(flagset); err != nil {
+		return err
+	}
 	if FlagWasSet(flagset, "encryption-password") {
 		encryptPassLength := len(ctl.flagTree.EncryptionPassword)
 		if encryptPassLength > 0 && encryptPassLength < 16 {
@@ -192,12 +220,32 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 	if FlagWasSet(flagset, "expose-ui") {
 		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService)
 		if !isValid {
-			return fmt.Errorf("expose ui must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
+			return fmt.Errorf("expose ui must be '%s', '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.INGRESS, util.OPENSHIFT, util.NONE)
 		}
 	}
+	if (FlagWasSet(flagset, "ingress-class") || FlagWasSet(flagset, "ingress-host") || FlagWasSet(flagset, "ingress-tls-secret")) && FlagWasSet(flagset, "expose-ui") && !strings.EqualFold(ctl.flagTree.ExposeService, util.INGRESS) {
+		return fmt.Errorf("ingress-class, ingress-host and ingress-tls-secret require expose-ui %s", util.INGRESS)
+	}
+	if FlagWasSet(flagset, "expose-ui") && strings.EqualFold(ctl.flagTree.ExposeService, util.INGRESS) && !FlagWasSet(flagset, "ingress-host") {
+		return fmt.Errorf("ingress-host must be set for expose-ui %s", util.INGRESS)
+	}
 	if (FlagWasSet(flagset, "certificate-file-path") || FlagWasSet(flagset, "certificate-key-file-path")) && !(FlagWasSet(flagset, "certificate-file-path") && FlagWasSet(flagset, "certificate-key-file-path")) {
 		return fmt.Errorf("must set both certificate-file-path and certificate-key-file-path")
 	}
+	if FlagWasSet(flagset, "certificate-issuer") && len(ctl.flagTree.CertificateIssuer) > 0 {
+		if FlagWasSet(flagset, "certificate-file-path") {
+			return fmt.Errorf("cannot set both certificate-issuer and certificate-file-path")
+		}
+		if _, err := util.ParseCertificateIssuer(ctl.flagTree.CertificateIssuer); err != nil {
+			return err
+		}
+	}
+	if FlagWasSet(flagset, "java-keystore-file-path") && FlagWasSet(flagset, "from-pem") {
+		return fmt.Errorf("cannot set both java-keystore-file-path and from-pem")
+	}
+	if FlagWasSet(flagset, "keystore-password-file") && !FlagWasSet(flagset, "java-keystore-file-path") && !FlagWasSet(flagset, "from-pem") {
+		return fmt.Errorf("keystore-password-file requires java-keystore-file-path or from-pem")
+	}
 	return nil
 }
 
@@ -246,27 +294,29 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 	if f.Changed {
 		log.Debugf("flag '%s': CHANGED", f.Name)
 		switch f.Name {
-		case "version":
-			util.SetHelmValueInMap(ctl.args, []string{"alert", "imageTag"}, ctl.flagTree.Version)
-		case "standalone":
-			standAloneVal := strings.ToUpper(ctl.flagTree.StandAlone) == "TRUE"
-			util.SetHelmValueInMap(ctl.args, []string{"enableStandalone"}, standAloneVal)
 		case "deployment-resources-file-path":
 			util.GetDeploymentResources(ctl.flagTree.DeploymentResourcesFilePath, ctl.args, "heapMaxMemory")
 		case "expose-ui":
 			util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, true)
-			switch ctl.flagTree.ExposeService {
+			util.DisableIngressInHelmValues(ctl.args)
+			switch strings.ToUpper(ctl.flagTree.ExposeService) {
 			case util.NODEPORT:
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "NodePort")
 			case util.LOADBALANCER:
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "LoadBalancer")
 			case util.OPENSHIFT:
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "OpenShift")
+			case util.INGRESS:
+				util.EnableIngressInHelmValues(ctl.args)
 			default:
 				util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, false)
 			}
-		case "port":
-			util.SetHelmValueInMap(ctl.args, []string{"alert", "port"}, ctl.flagTree.Port)
+		case "ingress-class":
+			util.SetIngressHelmValue(ctl.args, "className", ctl.flagTree.IngressClass)
+		case "ingress-host":
+			util.SetIngressHelmValue(ctl.args, "host", ctl.flagTree.IngressHost)
+		case "ingress-tls-secret":
+			util.SetIngressHelmValue(ctl.args, "tlsSecretName", ctl.flagTree.IngressTLSSecret)
 		case "encryption-password":
 			util.SetHelmValueInMap(ctl.args, []string{"setEncryptionSecretData"}, true)
 			util.SetHelmValueInMap(ctl.args, []string{"alertEncryptionPassword"}, ctl.flagTree.EncryptionPassword)
@@ -303,11 +353,6 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 				util.SetHelmValueInMap(ctl.args, append(pathToHelmValue, "storageClass"), pvc.StorageClass)
 				util.SetHelmValueInMap(ctl.args, append(pathToHelmValue, "volumeName"), pvc.VolumeName)
 			}
-		case "persistent-storage":
-			persistentStorageVal := strings.ToUpper(ctl.flagTree.PersistentStorage) == "TRUE"
-			util.SetHelmValueInMap(ctl.args, []string{"enablePersistentStorage"}, persistentStorageVal)
-		case "pvc-storage-class":
-			util.SetHelmValueInMap(ctl.args, []string{"storageClass"}, ctl.flagTree.PVCStorageClass)
 		case "environs":
 			// TODO: Make sure this is converted correclty
 			envMap := map[string]interface{}{}
@@ -316,10 +361,28 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 				envMap[envSplit[0]] = envSplit[1]
 			}
 			util.SetHelmValueInMap(ctl.args, []string{"environs"}, envMap)
-		case "registry":
-			util.SetHelmValueInMap(ctl.args, []string{"registry"}, ctl.flagTree.Registry)
-		case "pull-secret-name":
-			util.SetHelmValueInMap(ctl.args, []string{"imagePullSecrets"}, ctl.flagTree.PullSecrets)
+		case "secret-environs-file":
+			environs, err := util.ReadSecretEnvironsFile(ctl.flagTree.SecretEnvironsFilePath)
+			if err != nil {
+				log.Fatalf("failed to read secret environs: %+v", err)
+			}
+			if err := util.SetSecretEnvironsInHelmValues(ctl.args, environs); err != nil {
+				log.Fatalf("failed to set secret environs: %+v", err)
+			}
+		case "image-pull-policy":
+			if err := util.SetImagePullPolicyInHelmValues(ctl.args, ctl.flagTree.ImagePullPolicy); err != nil {
+				log.Fatalf("%+v", err)
+			}
+		case "pin-image-digests":
+			util.SetPinImageDigestsInHelmValues(ctl.args, ctl.flagTree.PinImageDigests)
+		case "extra-volume", "extra-volumes-file-path":
+			volumes, err := util.GetExtraVolumesFromFlags(ctl.flagTree.ExtraVolumes, ctl.flagTree.ExtraVolumesFilePath)
+			if err != nil {
+				log.Fatalf("failed to read extra volumes: %+v", err)
+			}
+			if err := util.SetExtraVolumesInHelmValues(ctl.args, volumes); err != nil {
+				log.Fatalf("failed to set extra volumes: %+v", err)
+			}
 		case "security-context-file-path":
 			data, err := util.ReadFileData(ctl.flagTree.SecurityContextFilePath)
 			if err != nil {
@@ -335,23 +398,12 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 			for k, v := range securityContexts {
 				util.SetHelmValueInMap(ctl.args, []string{k, "podSecurityContext"}, blackduck.CorePodSecurityContextToHelm(v))
 			}
-			// Postgres
-		case "postgres-external":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "isExternal"}, strings.ToUpper(ctl.flagTree.PostgresIsExternal) == "TRUE")
-		case "postgres-host":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "host"}, ctl.flagTree.PostgresHost)
-		case "postgres-port":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "port"}, ctl.flagTree.PostgresPort)
-		case "postgres-user":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "userUserName"}, ctl.flagTree.PostgresUsername)
-		case "postgres-password":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "userPassword"}, ctl.flagTree.PostgresPassword)
-		case "postgres-database":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "databaseName"}, ctl.flagTree.PostgresDatabaseName)
-		case "postgres-ssl":
-			util.SetHelmValueInMap(ctl.args, []string{"postgres", "ssl"}, strings.ToUpper(ctl.flagTree.PostgresSsl) == "TRUE")
 		default:
-			log.Debugf("flag '%s': NOT FOUND", f.Name)
+			if found, err := alertFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+				log.Fatalf("%+v", err)
+			} else if !found {
+				log.Debugf("flag '%s': NOT FOUND", f.Name)
+			}
 		}
 	} else {
 		log.Debugf("flag '%s': UNCHANGED", f.Name)
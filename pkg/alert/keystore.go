@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package alert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// GetJavaKeystoreDataFromFlags returns the Java Keystore configured by the java-keystore-file-path or the from-pem flags.
+// The keystore is validated and its certificates are listed before it is returned. The boolean is false if neither flag was set
+func GetJavaKeystoreDataFromFlags(flagset *pflag.FlagSet) (string, bool, error) {
+	password := ""
+	if FlagWasSet(flagset, "keystore-password-file") {
+		passwordData, err := util.ReadFromFile(flagset.Lookup("keystore-password-file").Value.String())
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read Java Keystore password file: %+v", err)
+		}
+		password = strings.TrimRight(string(passwordData), "\r\n")
+	}
+
+	var javaKeystoreData []byte
+	switch {
+	case FlagWasSet(flagset, "from-pem"):
+		pemFilePaths, err := flagset.GetStringSlice("from-pem")
+		if err != nil {
+			return "", false, err
+		}
+		pemData := [][]byte{}
+		for _, pemFilePath := range pemFilePaths {
+			data, err := util.ReadFromFile(pemFilePath)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to read PEM certificate file: %+v", err)
+			}
+			pemData = append(pemData, data)
+		}
+		if len(password) == 0 {
+			password = util.DefaultJavaKeystorePassword
+		}
+		if javaKeystoreData, err = util.NewJavaKeystoreFromPEM(pemData, password); err != nil {
+			return "", false, fmt.Errorf("failed to build Java Keystore from PEM certificates: %+v", err)
+		}
+	case FlagWasSet(flagset, "java-keystore-file-path"):
+		data, err := util.ReadFromFile(flagset.Lookup("java-keystore-file-path").Value.String())
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read Java Keystore file: %+v", err)
+		}
+		javaKeystoreData = data
+	default:
+		return "", false, nil
+	}
+
+	entries, err := util.ParseJavaKeystore(javaKeystoreData, password)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid Java Keystore: %+v", err)
+	}
+	if len(entries) == 0 {
+		log.Warnf("the Java Keystore does not contain any certificate")
+	}
+	for _, entry := range entries {
+		for _, certificate := range entry.Certificates {
+			log.Infof("Java Keystore entry '%s': subject '%s', issuer '%s', expires %s", entry.Alias, certificate.Subject, certificate.Issuer, certificate.NotAfter.Format("2006-01-02"))
+		}
+	}
+	return string(javaKeystoreData), true, nil
+}
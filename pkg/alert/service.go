@@ -22,14 +22,33 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
+// GetExposure returns the exposed service, route and ingress of an Alert instance. The name label of the instance is its
+// Helm release name, Alert instances created by the operator were labeled with their name
+func GetExposure(namespace string, customerAppName string) util.Exposure {
+	return util.Exposure{
+		App:           util.AlertName,
+		Namespace:     namespace,
+		Name:          fmt.Sprintf("%s%s", customerAppName, globals.AlertPostSuffix),
+		ServiceName:   util.GetResourceName(customerAppName, util.AlertName, "exposed"),
+		RouteName:     util.GetResourceName(customerAppName, util.AlertName, ""),
+		IngressName:   util.GetResourceName(customerAppName, util.AlertName, ""),
+		PreviousNames: []string{customerAppName},
+	}
+}
+
 // CRUDServiceOrRoute will create or update Alert exposed service, or route in case of OpenShift
 func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, customerAppName string, isExposedUI interface{}, exposedServiceType interface{}, isChanged bool) error {
+	if err := util.ReconcileExposure(restConfig, kubeClient, GetExposure(namespace, customerAppName)); err != nil {
+		return err
+	}
 	serviceName := util.GetResourceName(customerAppName, util.AlertName, "exposed")
 	routeName := util.GetResourceName(customerAppName, util.AlertName, "")
 	isOpenShift := util.IsOpenshift(kubeClient)
@@ -106,3 +125,17 @@ func crudExposedService(restConfig *rest.Config, kubeClient *kubernetes.Clientse
 	}
 	return nil
 }
+
+// GetIngress returns the ingress of Alert's user interface, or nil if the Helm values don't expose it with an ingress
+func GetIngress(namespace string, customerAppName string, helmValues map[string]interface{}) (*unstructured.Unstructured, error) {
+	return util.NewExposureIngress(GetExposure(namespace, customerAppName), helmValues, util.GetResourceName(customerAppName, util.AlertName, ""), 8443)
+}
+
+// CRUDIngress creates, updates or deletes the ingress of Alert's user interface based on the Helm values of the instance
+func CRUDIngress(restConfig *rest.Config, namespace string, customerAppName string, helmValues map[string]interface{}) error {
+	ingress, err := GetIngress(namespace, customerAppName, helmValues)
+	if err != nil {
+		return err
+	}
+	return util.ReconcileExposureIngress(restConfig, GetExposure(namespace, customerAppName), ingress)
+}
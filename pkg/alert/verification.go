@@ -0,0 +1,82 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package alert
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// alertAbout is the part of the response of the about endpoint of Alert that the verification uses
+type alertAbout struct {
+	Version     string `json:"version"`
+	Initialized bool   `json:"initialized"`
+	Providers   []struct {
+		Name string `json:"name"`
+	} `json:"providers"`
+}
+
+// VerificationChecks returns the post-install checks of an Alert instance: the login page is reachable, the API reports
+// the version of the release and Alert is initialized with a provider
+func VerificationChecks(customerAppName string) []products.VerificationCheck {
+	service := util.GetResourceName(customerAppName, util.AlertName, "")
+	return []products.VerificationCheck{
+		products.NewPageCheck("login-page", "https", service, "8443", "alert/"),
+		products.NewVersionCheck("api-version", "https", service, "8443", "alert/api/about"),
+		{
+			Name: "provider",
+			Run: func(target *products.VerificationTarget) products.VerificationResult {
+				body, err := target.ProxyGet("https", service, "8443", "alert/api/about")
+				if err != nil {
+					return products.NewVerificationResult("provider", products.VerificationFail, "endpoint '/alert/api/about' of service '%s' failed: %+v", service, err)
+				}
+				return checkAlertProvider(body)
+			},
+		},
+	}
+}
+
+// checkAlertProvider checks that the about response reports an initialized Alert with a provider
+func checkAlertProvider(body []byte) products.VerificationResult {
+	about := alertAbout{}
+	if err := json.Unmarshal(body, &about); err != nil {
+		return products.NewVerificationResult("provider", products.VerificationFail, "unable to parse the about response due to %+v", err)
+	}
+	if !about.Initialized {
+		return products.NewVerificationResult("provider", products.VerificationFail, "Alert is not initialized, complete its setup")
+	}
+	if about.Providers == nil {
+		// older versions of Alert don't report their providers
+		return products.NewVerificationResult("provider", products.VerificationSkip, "Alert is initialized but doesn't report its providers")
+	}
+	names := []string{}
+	for _, provider := range about.Providers {
+		names = append(names, provider.Name)
+	}
+	if len(names) == 0 {
+		return products.NewVerificationResult("provider", products.VerificationFail, "Alert has no provider")
+	}
+	return products.NewVerificationResult("provider", products.VerificationPass, "Alert is initialized with the provider(s) %s", strings.Join(names, ", "))
+}
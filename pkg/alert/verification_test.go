@@ -0,0 +1,52 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package alert
+
+import (
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAlertProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	result := checkAlertProvider([]byte(`{"version":"6.0.0","initialized":true,"providers":[{"name":"provider_blackduck"}]}`))
+	assert.Equal(products.VerificationPass, result.Status)
+	assert.Contains(result.Message, "provider_blackduck")
+
+	assert.Equal(products.VerificationFail, checkAlertProvider([]byte(`{"version":"6.0.0","initialized":false}`)).Status)
+	assert.Equal(products.VerificationFail, checkAlertProvider([]byte(`{"version":"6.0.0","initialized":true,"providers":[]}`)).Status)
+	assert.Equal(products.VerificationSkip, checkAlertProvider([]byte(`{"version":"5.3.1","initialized":true}`)).Status)
+	assert.Equal(products.VerificationFail, checkAlertProvider([]byte(`<html>`)).Status)
+}
+
+func TestVerificationChecks(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{}
+	for _, check := range VerificationChecks("my") {
+		names = append(names, check.Name)
+	}
+	assert.Equal([]string{"login-page", "api-version", "provider"}, names)
+}
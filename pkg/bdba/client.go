@@ -0,0 +1,172 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package bdba
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// Statuses of a BDBA scan
+const (
+	ScanStatusBusy   = "B"
+	ScanStatusReady  = "R"
+	ScanStatusFailed = "F"
+)
+
+// uploadRequestLimit is the timeout of the requests, uploads of large images can take a long time
+const uploadRequestLimit = 6 * time.Hour
+
+// scanPollingPeriod is the period of polling a busy scan
+var scanPollingPeriod = 10 * time.Second
+
+// Client uploads files to the REST API of a BDBA instance and fetches their scan results
+type Client struct {
+	*util.ProductAPIClient
+	Group string
+}
+
+// NewClient returns a client of the BDBA instance at the URL. The group is the id of the BDBA group
+// the scans are uploaded to, the default group of the user is used if it is empty
+func NewClient(bdbaURL, username, password, group string) *Client {
+	return &Client{
+		ProductAPIClient: util.NewProductAPIClient(bdbaURL, &util.ProductAPIBasicAuth{Username: username, Password: password}, uploadRequestLimit),
+		Group:            group,
+	}
+}
+
+// ScanResult is the summary of the scan of an uploaded file
+type ScanResult struct {
+	ProductID  int             `json:"productId"`
+	Name       string          `json:"name"`
+	Status     string          `json:"status"`
+	Verdict    string          `json:"verdict"`
+	VulnCount  int             `json:"vulnerabilities"`
+	Components []ScanComponent `json:"components"`
+}
+
+// ScanComponent is a component found by a scan along with its known vulnerabilities
+type ScanComponent struct {
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	Vulnerabilities []string `json:"vulnerabilities,omitempty"`
+}
+
+// productResponse is the response of the upload and product endpoints of the BDBA API
+type productResponse struct {
+	Results struct {
+		ProductID int    `json:"product_id"`
+		Filename  string `json:"filename"`
+		Status    string `json:"status"`
+		Summary   struct {
+			Verdict struct {
+				Short string `json:"short"`
+			} `json:"verdict"`
+			VulnCount struct {
+				Total int `json:"total"`
+			} `json:"vuln-count"`
+		} `json:"summary"`
+		Components []struct {
+			Lib     string `json:"lib"`
+			Version string `json:"version"`
+			Vulns   []struct {
+				Vuln struct {
+					CVE string `json:"cve"`
+				} `json:"vuln"`
+				Exact bool `json:"exact"`
+			} `json:"vulns"`
+		} `json:"components"`
+	} `json:"results"`
+}
+
+func (r *productResponse) scanResult() *ScanResult {
+	result := &ScanResult{
+		ProductID:  r.Results.ProductID,
+		Name:       r.Results.Filename,
+		Status:     r.Results.Status,
+		Verdict:    r.Results.Summary.Verdict.Short,
+		VulnCount:  r.Results.Summary.VulnCount.Total,
+		Components: []ScanComponent{},
+	}
+	for _, component := range r.Results.Components {
+		scanComponent := ScanComponent{Name: component.Lib, Version: component.Version}
+		for _, vuln := range component.Vulns {
+			if vuln.Exact {
+				scanComponent.Vulnerabilities = append(scanComponent.Vulnerabilities, vuln.Vuln.CVE)
+			}
+		}
+		result.Components = append(result.Components, scanComponent)
+	}
+	return result
+}
+
+// Upload uploads the file to BDBA to be scanned and returns the id of the product that holds the scan
+func (c *Client) Upload(fileName string, file io.ReadSeeker) (int, error) {
+	headers := map[string]string{"Accept": "application/json"}
+	if len(c.Group) > 0 {
+		headers["Group"] = c.Group
+	}
+	resp, err := c.Do(http.MethodPut, fmt.Sprintf("/api/upload/%s", url.PathEscape(fileName)), file, headers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload '%s' to BDBA due to %+v", fileName, err)
+	}
+	defer resp.Body.Close()
+	product := &productResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(product); err != nil {
+		return 0, fmt.Errorf("failed to decode the BDBA response due to %+v", err)
+	}
+	return product.Results.ProductID, nil
+}
+
+// GetScanResult returns the result of the scan of the product
+func (c *Client) GetScanResult(productID int) (*ScanResult, error) {
+	product := &productResponse{}
+	if err := c.DoJSON(http.MethodGet, fmt.Sprintf("/api/product/%d/", productID), nil, product); err != nil {
+		return nil, fmt.Errorf("failed to get the scan of product %d due to %+v", productID, err)
+	}
+	product.Results.ProductID = productID
+	return product.scanResult(), nil
+}
+
+// WaitForScanResult polls the scan of the product until it is no longer busy or the timeout expires
+func (c *Client) WaitForScanResult(productID int, timeout time.Duration) (*ScanResult, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := c.GetScanResult(productID)
+		if err != nil {
+			return nil, err
+		}
+		if result.Status != ScanStatusBusy {
+			return result, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("the scan of product %d did not finish within %s", productID, timeout)
+		}
+		time.Sleep(scanPollingPeriod)
+	}
+}
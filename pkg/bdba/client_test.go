@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package bdba
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	scanPollingPeriod = time.Millisecond
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/api/upload/webapp.tar":
+			if data, _ := ioutil.ReadAll(r.Body); string(data) != "image" || r.Header.Get("Group") != "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"results": {"product_id": 42, "status": "B"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/product/42/":
+			polls++
+			if polls == 1 {
+				fmt.Fprint(w, `{"results": {"status": "B"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"results": {"filename": "webapp.tar", "status": "R", "summary": {"verdict": {"short": "Vulns"}, "vuln-count": {"total": 1}},
+				"components": [{"lib": "openssl", "version": "1.0.2", "vulns": [{"vuln": {"cve": "CVE-2020-1967"}, "exact": true}]}, {"lib": "zlib", "version": "1.2.11", "vulns": []}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "admin", "secret", "2")
+	productID, err := client.Upload("webapp.tar", strings.NewReader("image"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if productID != 42 {
+		t.Fatalf("expected product 42, got %d", productID)
+	}
+
+	result, err := client.GetScanResult(productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != ScanStatusBusy {
+		t.Errorf("expected a busy scan, got %+v", result)
+	}
+
+	result, err = client.WaitForScanResult(productID, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != ScanStatusReady || result.Verdict != "Vulns" || result.VulnCount != 1 || len(result.Components) != 2 || len(result.Components[0].Vulnerabilities) != 1 {
+		t.Errorf("unexpected scan result %+v", result)
+	}
+
+	if _, err := NewClient(server.URL, "admin", "wrong", "").GetScanResult(42); err == nil {
+		t.Errorf("expected an error for invalid credentials")
+	}
+}
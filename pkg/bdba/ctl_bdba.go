@@ -23,7 +23,9 @@ package bdba
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -116,6 +118,18 @@ type FlagTree struct {
 	// Minio
 	MinioMode string `json:"minioMode"`
 
+	// Object storage
+	UploadRetentionDays   int    `json:"uploadRetentionDays"`
+	ExternalS3Endpoint    string `json:"externalS3Endpoint"`
+	ExternalS3Region      string `json:"externalS3Region"`
+	ExternalS3Bucket      string `json:"externalS3Bucket"`
+	ExternalS3AccessKeyID string `json:"externalS3AccessKeyID"`
+	ExternalS3SecretKey   string `json:"externalS3SecretKey"`
+
+	// Exposed user interface
+	ExposeService   string `json:"exposeService"`
+	ExposedHostname string `json:"exposedHostname"`
+
 	// Ingress
 	IngressEnabled       bool   `json:"ingressEnabled"`
 	IngressHost          string `json:"ingressHost"`
@@ -181,6 +195,9 @@ var DefaultFlagTree = FlagTree{
 	WorkerConcurrency: 1,
 	// Minio
 	MinioMode: "standalone",
+	// Object storage
+	UploadRetentionDays: 0,
+	ExternalS3Region:    "us-east-1",
 	// Networking and security
 	// Ingress
 	IngressEnabled:    false,
@@ -218,112 +235,155 @@ func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
 	}
 }
 
-// AddCobraFlagsToCommand adds flags for the BDBA helm chart to the cmd
-func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
-	// [DEV NOTE:] please organize flags in order of importance
-	cmd.Flags().SortFlags = false
-
-	defaults := &FlagTree{}
-	if isCreateCmd {
-		defaults = GetDefaultFlagTree()
-	}
-
-	cmd.Flags().StringVar(&ctl.flagTree.Version, "version", defaults.Version, "Version of BDBA you want to install\n")
+// bdbaFlags are the flags of the BDBA Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var bdbaFlags = flags.NewSet("BDBA", []flags.Flag{
+	{Name: "version", Field: "Version", Usage: "Version of BDBA you want to install\n"},
 
-	cmd.Flags().StringVar(&ctl.flagTree.ClusterDomain, "cluster-domain", defaults.ClusterDomain, "Kubernetes cluster domain\n")
+	{Name: "cluster-domain", Field: "ClusterDomain", Usage: "Kubernetes cluster domain\n"},
 
 	// Storage
-	cmd.Flags().StringVar(&ctl.flagTree.PGStorageClass, "postgres-storage-class", defaults.PGStorageClass, "Storage class for PostgreSQL")
-	cmd.Flags().StringVar(&ctl.flagTree.PGPVCSize, "postgres-size", defaults.PGPVCSize, "Persistent volument claim size for PostgreSQL")
-	cmd.Flags().StringVar(&ctl.flagTree.PGExistingClaim, "postgres-existing-claim", defaults.PGExistingClaim, "Existing claim to use for PostgreSQL")
-	cmd.Flags().StringVar(&ctl.flagTree.MinioStorageClass, "minio-storage-class", defaults.MinioStorageClass, "Storage class for minio")
-	cmd.Flags().StringVar(&ctl.flagTree.MinioPVCSize, "minio-size", defaults.MinioPVCSize, "Persistent volume claim size of minio")
-	cmd.Flags().StringVar(&ctl.flagTree.MinioExistingClaim, "minio-existing-claim", defaults.MinioExistingClaim, "Existing claim to use for minio")
-	cmd.Flags().StringVar(&ctl.flagTree.RabbitMQStorageClass, "rabbitmq-storage-class", defaults.RabbitMQStorageClass, "Storage class for RabbitMQ")
-	cmd.Flags().StringVar(&ctl.flagTree.RabbitMQPVCSize, "rabbitmq-size", defaults.RabbitMQPVCSize, "Persistent volument claim size for RabbitMQ")
-	cmd.Flags().StringVar(&ctl.flagTree.RabbitMQExistingClaim, "rabbitmq-existing-claim", defaults.RabbitMQExistingClaim, "Existing claim to use for RabbitMQ\n")
+	{Name: "postgres-storage-class", Field: "PGStorageClass", Usage: "Storage class for PostgreSQL", Path: []string{"postgresql", "persistence", "storageClass"}},
+	{Name: "postgres-size", Field: "PGPVCSize", Kind: flags.Quantity, Usage: "Persistent volument claim size for PostgreSQL", Path: []string{"postgresql", "persistence", "size"}},
+	{Name: "postgres-existing-claim", Field: "PGExistingClaim", Usage: "Existing claim to use for PostgreSQL", Path: []string{"postgresql", "persistence", "existingClaim"}},
+	{Name: "minio-storage-class", Field: "MinioStorageClass", Usage: "Storage class for minio", Path: []string{"minio", "persistence", "storageClass"}},
+	{Name: "minio-size", Field: "MinioPVCSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of minio", Path: []string{"minio", "persistence", "size"}},
+	{Name: "minio-existing-claim", Field: "MinioExistingClaim", Usage: "Existing claim to use for minio", Path: []string{"minio", "persistence", "existingClaim"}},
+	{Name: "rabbitmq-storage-class", Field: "RabbitMQStorageClass", Usage: "Storage class for RabbitMQ", Path: []string{"rabbitmq", "persistence", "storageClass"}},
+	{Name: "rabbitmq-size", Field: "RabbitMQPVCSize", Kind: flags.Quantity, Usage: "Persistent volument claim size for RabbitMQ", Path: []string{"rabbitmq", "persistence", "size"}},
+	{Name: "rabbitmq-existing-claim", Field: "RabbitMQExistingClaim", Usage: "Existing claim to use for RabbitMQ\n", Path: []string{"rabbitmq", "persistence", "existingClaim"}},
 
 	// Licensing
-	cmd.Flags().StringVar(&ctl.flagTree.LicensingUsername, "license-username", defaults.LicensingUsername, "Username for licensing")
-	cmd.Flags().StringVar(&ctl.flagTree.LicensingPassword, "license-password", defaults.LicensingPassword, "Username for password")
-	cmd.Flags().StringVar(&ctl.flagTree.LicensingUpstream, "license-upstream", defaults.LicensingUpstream, "Upstream server for data updates\n")
+	{Name: "license-username", Field: "LicensingUsername", Usage: "Username for licensing", Path: []string{"frontend", "licensing", "username"}},
+	{Name: "license-password", Field: "LicensingPassword", Usage: "Username for password", Path: []string{"frontend", "licensing", "password"}, Secret: true},
+	{Name: "license-upstream", Field: "LicensingUpstream", Usage: "Upstream server for data updates\n", Path: []string{"frontend", "licensing", "upstream"}},
 
 	// Web frontend configuration
-	cmd.Flags().IntVar(&ctl.flagTree.SessionCookieAge, "session-cookie-age", defaults.SessionCookieAge, "Session cookie expiration time")
-	cmd.Flags().IntVar(&ctl.flagTree.FrontendReplicas, "frontend-replicas", defaults.FrontendReplicas, "Number of web application replicas")
-	cmd.Flags().BoolVar(&ctl.flagTree.HideLicenses, "hide-licenses", defaults.HideLicenses, "Hide licensing information")
-	cmd.Flags().BoolVar(&ctl.flagTree.OfflineMode, "enable-offline-mode", defaults.OfflineMode, "Run in airgapped mode")
-	cmd.Flags().StringVar(&ctl.flagTree.AdminEmail, "admin-email", defaults.AdminEmail, "Administrator email address")
-	cmd.Flags().StringVar(&ctl.flagTree.RootURL, "root-url", defaults.RootURL, "Root URL of application to be used in tempates\n")
+	{Name: "session-cookie-age", Field: "SessionCookieAge", Usage: "Session cookie expiration time", Path: []string{"frontend", "web", "sessionCookieAge"}},
+	{Name: "frontend-replicas", Field: "FrontendReplicas", Usage: "Number of web application replicas", Path: []string{"frontend", "web", "replicas"}},
+	{Name: "hide-licenses", Field: "HideLicenses", Kind: flags.Bool, Usage: "Hide licensing information", Path: []string{"frontend", "web", "hideLicenses"}},
+	{Name: "enable-offline-mode", Field: "OfflineMode", Kind: flags.Bool, Usage: "Run in airgapped mode", Path: []string{"frontend", "web", "offlineMode"}},
+	{Name: "admin-email", Field: "AdminEmail", Usage: "Administrator email address", Path: []string{"frontend", "web", "admin"}},
+	{Name: "root-url", Field: "RootURL", Usage: "Root URL of application to be used in tempates\n", Path: []string{"frontend", "web", "rootURL"}},
 
 	// SMTP configuration
-	cmd.Flags().BoolVar(&ctl.flagTree.EmailEnabled, "enable-email", defaults.EmailEnabled, "Enable STMP to send emails")
-	cmd.Flags().StringVar(&ctl.flagTree.EmailSMTPHost, "email-smtp-host", defaults.EmailSMTPHost, "SMTP server address")
-	cmd.Flags().IntVar(&ctl.flagTree.EmailSMTPPort, "email-smtp-port", defaults.EmailSMTPPort, "SMTP server port")
-	cmd.Flags().StringVar(&ctl.flagTree.EmailSMTPUser, "email-smtp-user", defaults.EmailSMTPUser, "SMTP user")
-	cmd.Flags().StringVar(&ctl.flagTree.EmailSMTPPassword, "email-smtp-password", defaults.EmailSMTPPassword, "SMTP password")
-	cmd.Flags().StringVar(&ctl.flagTree.EmailFrom, "email-from", defaults.EmailFrom, "Email sender address")
-	cmd.Flags().StringVar(&ctl.flagTree.EmailSecurity, "email-security", defaults.EmailSecurity, "Email security mode [none|ssl|starttls]")
-	cmd.Flags().BoolVar(&ctl.flagTree.EmailVerify, "verify-email", defaults.EmailVerify, "Verify SMTP server certificate\n")
+	{Name: "enable-email", Field: "EmailEnabled", Kind: flags.Bool, Usage: "Enable STMP to send emails", Path: []string{"frontend", "email", "enabled"}},
+	{Name: "email-smtp-host", Field: "EmailSMTPHost", Usage: "SMTP server address", Path: []string{"frontend", "email", "smtpHost"}},
+	{Name: "email-smtp-port", Field: "EmailSMTPPort", Usage: "SMTP server port", Path: []string{"frontend", "email", "smtpPort"}},
+	{Name: "email-smtp-user", Field: "EmailSMTPUser", Usage: "SMTP user", Path: []string{"frontend", "email", "smtpUser"}},
+	{Name: "email-smtp-password", Field: "EmailSMTPPassword", Usage: "SMTP password", Path: []string{"frontend", "email", "smtpPassword"}, Secret: true},
+	{Name: "email-from", Field: "EmailFrom", Usage: "Email sender address", Path: []string{"frontend", "email", "from"}},
+	{Name: "email-security", Field: "EmailSecurity", Kind: flags.Enum, Usage: "Email security mode [none|ssl|starttls]", Path: []string{"frontend", "email", "security"}, Values: []string{"none", "ssl", "starttls"}},
+	{Name: "verify-email", Field: "EmailVerify", Kind: flags.Bool, Usage: "Verify SMTP server certificate\n", Path: []string{"frontend", "email", "verify"}},
 
 	// LDAP Authentication
-	cmd.Flags().BoolVar(&ctl.flagTree.LDAPEnabled, "enable-ldap", defaults.LDAPEnabled, "Enable LDAP for authentication")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPServerURI, "ldap-server-uri", defaults.LDAPServerURI, "LDAP server URI")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPUserDNTemplate, "ldap-user-dn-template", defaults.LDAPUserDNTemplate, "LDAP user DN template")
-	cmd.Flags().BoolVar(&ctl.flagTree.LDAPBindAsAuthenticating, "enable-ldap-bind-as-authenticating", DefaultFlagTree.LDAPBindAsAuthenticating, "Bind as authenticating user")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPBindDN, "ldap-bind-dn", defaults.LDAPBindDN, "Generic LDAP bind username (optional)")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPBindPassword, "ldap-bind-password", defaults.LDAPBindPassword, "Generic LDAP bind password (optional)")
-	cmd.Flags().BoolVar(&ctl.flagTree.LDAPStartTLS, "ldap-start-tls", defaults.LDAPStartTLS, "Enable start TLS for LDAP")
-	cmd.Flags().BoolVar(&ctl.flagTree.LDAPVerify, "verify-ldap", defaults.LDAPVerify, "Verify LDAP server certificate")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPRootCASecret, "ldap-root-ca-secret", defaults.LDAPRootCASecret, "Secret to use for LDAP root certificate")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPRequireGroup, "ldap-require-group", defaults.LDAPRequireGroup, "LDAP group required to allow login")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPUserSearch, "ldap-user-search", defaults.LDAPUserSearch, "Base DN for user branch")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPUserSearchScope, "ldap-user-search-scope", defaults.LDAPUserSearchScope, "LDAP search filter for users")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPGroupSearch, "ldap-group-search", defaults.LDAPGroupSearch, "Base DN for groups branch")
-	cmd.Flags().StringVar(&ctl.flagTree.LDAPGroupSearchScope, "ldap-group-search-scope", defaults.LDAPGroupSearchScope, "LDAP search filter for groups")
-	cmd.Flags().BoolVar(&ctl.flagTree.LDAPNestedSearch, "enable-ldap-nested-search", defaults.LDAPNestedSearch, "Enable nested search\n")
+	{Name: "enable-ldap", Field: "LDAPEnabled", Kind: flags.Bool, Usage: "Enable LDAP for authentication", Path: []string{"frontend", "ldap", "enabled"}},
+	{Name: "ldap-server-uri", Field: "LDAPServerURI", Usage: "LDAP server URI", Path: []string{"frontend", "ldap", "serverUri"}},
+	{Name: "ldap-user-dn-template", Field: "LDAPUserDNTemplate", Usage: "LDAP user DN template", Path: []string{"frontend", "ldap", "userDNTemplate"}},
+	{Name: "enable-ldap-bind-as-authenticating", Field: "LDAPBindAsAuthenticating", Kind: flags.Bool, Usage: "Bind as authenticating user", Path: []string{"frontend", "ldap", "bindAsAuthenticating"}},
+	{Name: "ldap-bind-dn", Field: "LDAPBindDN", Usage: "Generic LDAP bind username (optional)", Path: []string{"frontend", "ldap", "bindDN"}},
+	{Name: "ldap-bind-password", Field: "LDAPBindPassword", Usage: "Generic LDAP bind password (optional)", Path: []string{"frontend", "ldap", "bindPassword"}, Secret: true},
+	{Name: "ldap-start-tls", Field: "LDAPStartTLS", Kind: flags.Bool, Usage: "Enable start TLS for LDAP", Path: []string{"frontend", "ldap", "startTLS"}},
+	{Name: "verify-ldap", Field: "LDAPVerify", Kind: flags.Bool, Usage: "Verify LDAP server certificate", Path: []string{"frontend", "ldap", "verify"}},
+	{Name: "ldap-root-ca-secret", Field: "LDAPRootCASecret", Usage: "Secret to use for LDAP root certificate", Path: []string{"frontend", "ldap", "rootCASecret"}},
+	{Name: "ldap-require-group", Field: "LDAPRequireGroup", Usage: "LDAP group required to allow login", Path: []string{"frontend", "ldap", "requireGroup"}},
+	{Name: "ldap-user-search", Field: "LDAPUserSearch", Usage: "Base DN for user branch", Path: []string{"frontend", "ldap", "userSearch"}},
+	{Name: "ldap-user-search-scope", Field: "LDAPUserSearchScope", Usage: "LDAP search filter for users", Path: []string{"frontend", "ldap", "userSearchScope"}},
+	{Name: "ldap-group-search", Field: "LDAPGroupSearch", Usage: "Base DN for groups branch", Path: []string{"frontend", "ldap", "groupSearch"}},
+	{Name: "ldap-group-search-scope", Field: "LDAPGroupSearchScope", Usage: "LDAP search filter for groups", Path: []string{"frontend", "ldap", "groupSearchScope"}},
+	{Name: "enable-ldap-nested-search", Field: "LDAPNestedSearch", Kind: flags.Bool, Usage: "Enable nested search\n", Path: []string{"frontend", "ldap", "nestedSearch"}},
 
 	// Logging
-	cmd.Flags().BoolVar(&ctl.flagTree.DisableFrontendLogging, "disable-frontend-logging", defaults.DisableFrontendLogging, "Disable log collection in web application pods")
-	cmd.Flags().BoolVar(&ctl.flagTree.DisableWorkerLogging, "disable-worker-logging", defaults.DisableWorkerLogging, "Disable log collection in scanner pods")
-	cmd.Flags().IntVar(&ctl.flagTree.LogRetention, "log-retention", defaults.LogRetention, "Retain logs for number of days\n")
+	{Name: "disable-frontend-logging", Field: "DisableFrontendLogging", Kind: flags.Bool, Usage: "Disable log collection in web application pods", Path: []string{"frontend", "applicationLogging", "enabled"}, Invert: true},
+	{Name: "disable-worker-logging", Field: "DisableWorkerLogging", Kind: flags.Bool, Usage: "Disable log collection in scanner pods", Path: []string{"worker", "applicationLogging", "enabled"}, Invert: true},
+	{Name: "log-retention", Field: "LogRetention", Usage: "Retain logs for number of days\n", Path: []string{"logRetention"}},
 
 	// Worker scaling
-	cmd.Flags().IntVar(&ctl.flagTree.WorkerReplicas, "worker-replicas", defaults.WorkerReplicas, "Number of worker replicas")
-	cmd.Flags().IntVar(&ctl.flagTree.WorkerConcurrency, "worker-concurrency", defaults.WorkerConcurrency, "Amount of concurrent workers per pod\n")
+	{Name: "worker-replicas", Field: "WorkerReplicas", Usage: "Number of worker replicas", Path: []string{"worker", "replicas"}},
+	{Name: "worker-concurrency", Field: "WorkerConcurrency", Usage: "Amount of concurrent workers per pod\n", Path: []string{"worker", "concurrency"}},
 
 	// Minio
-	cmd.Flags().StringVar(&ctl.flagTree.MinioMode, "minio-mode", defaults.MinioMode, "Minio mode [standalone|distributed]\n")
+	{Name: "minio-mode", Field: "MinioMode", Kind: flags.Enum, Usage: "Minio mode [standalone|distributed]\n", Path: []string{"minio", "mode"}, Values: []string{"standalone", "distributed"}},
+
+	// Object storage
+	{Name: "upload-retention-days", Field: "UploadRetentionDays", Usage: "Days the uploaded binaries are kept in the object storage, 0 keeps them until they are deleted in BDBA", Path: []string{"frontend", "web", "uploadRetentionDays"}},
+	{Name: "external-s3-endpoint", Field: "ExternalS3Endpoint", Usage: "URL of an external S3 compatible object storage used instead of minio"},
+	{Name: "external-s3-region", Field: "ExternalS3Region", Usage: "Region of the external S3 bucket", Path: []string{"s3", "region"}},
+	{Name: "external-s3-bucket", Field: "ExternalS3Bucket", Usage: "Name of the external S3 bucket for the uploads", Path: []string{"s3", "bucket"}},
+	{Name: "external-s3-access-key-id", Field: "ExternalS3AccessKeyID", Usage: "Access key id of the external S3 bucket", Path: []string{"s3", "accessKeyId"}},
+	{Name: "external-s3-secret-access-key", Field: "ExternalS3SecretKey", Usage: "Secret access key of the external S3 bucket\n", Path: []string{"s3", "secretAccessKey"}, Secret: true},
 
 	// Networking and security
-	cmd.Flags().StringVar(&ctl.flagTree.RootCASecret, "root-ca-secret", defaults.RootCASecret, "Additional root certificate")
-	cmd.Flags().StringVar(&ctl.flagTree.HTTPProxy, "http-proxy", defaults.HTTPProxy, "HTTP Proxy to use")
-	cmd.Flags().StringVar(&ctl.flagTree.HTTPNoProxy, "http-no-proxy", defaults.HTTPNoProxy, "Comma-separated list of domain extensions to omit proxy\n")
+	{Name: "root-ca-secret", Field: "RootCASecret", Usage: "Additional root certificate", Path: []string{"rootCASecret"}},
+	{Name: "http-proxy", Field: "HTTPProxy", Usage: "HTTP Proxy to use", Path: []string{"httpProxy"}},
+	{Name: "http-no-proxy", Field: "HTTPNoProxy", Usage: "Comma-separated list of domain extensions to omit proxy\n", Path: []string{"httpNoProxy"}},
+
+	// Exposed user interface
+	{Name: "expose-ui", Field: "ExposeService", Usage: "Service type of BDBA's user interface [NODEPORT|LOADBALANCER|INGRESS|OPENSHIFT|NONE]"},
+	{Name: "expose-ui-hostname", Field: "ExposedHostname", Usage: "Hostname of BDBA's user interface for the ingress or route\n"},
 
 	// Ingress
-	cmd.Flags().BoolVar(&ctl.flagTree.IngressEnabled, "enable-ingress", defaults.IngressEnabled, "Enable ingress")
-	cmd.Flags().StringVar(&ctl.flagTree.IngressHost, "ingress-host", defaults.IngressHost, "Hostname for ingress")
-	cmd.Flags().BoolVar(&ctl.flagTree.IngressTLSEnabled, "enable-ingress-tls", defaults.IngressTLSEnabled, "Enable TLS for ingress")
-	cmd.Flags().StringVar(&ctl.flagTree.IngressTLSSecretName, "ingress-tls-secret", defaults.IngressTLSSecretName, "TLS Secret to use for ingress\n")
+	{Name: "enable-ingress", Field: "IngressEnabled", Kind: flags.Bool, Usage: "Enable ingress", Path: []string{"ingress", "enabled"}},
+	{Name: "ingress-host", Field: "IngressHost", Usage: "Hostname for ingress", Path: []string{"ingress", "host"}},
+	{Name: "enable-ingress-tls", Field: "IngressTLSEnabled", Kind: flags.Bool, Usage: "Enable TLS for ingress", Path: []string{"ingress", "tls", "enabled"}},
+	{Name: "ingress-tls-secret", Field: "IngressTLSSecretName", Usage: "TLS Secret to use for ingress\n", Path: []string{"ingress", "tls", "secretName"}},
 
 	// External PG
-	cmd.Flags().BoolVar(&ctl.flagTree.ExternalPG, "external-postgres", defaults.ExternalPG, "Use external PostgreSQL")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGHost, "external-postgres-host", defaults.ExternalPGHost, "Hostname for external postgresql database")
-	cmd.Flags().IntVar(&ctl.flagTree.ExternalPGPort, "external-postgres-port", defaults.ExternalPGPort, "Port for external PostgreSQL database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGDataBase, "external-postgres-database", defaults.ExternalPGDataBase, "Database for external PostgreSQL database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGUser, "external-postgres-user", defaults.ExternalPGUser, "User for external PostgreSQL database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGPassword, "external-postgres-password", defaults.ExternalPGPassword, "Password for external PostgreSQL database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGSSLMode, "external-postgres-ssl-mode", defaults.ExternalPGSSLMode, "PostgreSQL SSL mode [disable|allow|prefer|require|verify-ca|verify-full]")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGClientSecret, "external-postgres-client-secret", defaults.ExternalPGClientSecret, "Secret name for external PostgreSQL client certificate (TLS Secret)")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPGRootCASecret, "external-postgres-rootca-secret", defaults.ExternalPGRootCASecret, "Secret name for external PostgreSQL root certificate\n")
+	{Name: "external-postgres", Field: "ExternalPG", Kind: flags.Bool, Usage: "Use external PostgreSQL", Path: []string{"postgresql", "enabled"}, Invert: true},
+	{Name: "external-postgres-host", Field: "ExternalPGHost", Usage: "Hostname for external postgresql database", Path: []string{"frontend", "database", "postgresqlHost"}},
+	{Name: "external-postgres-port", Field: "ExternalPGPort", Usage: "Port for external PostgreSQL database", Path: []string{"frontend", "database", "postgresqlPort"}},
+	{Name: "external-postgres-database", Field: "ExternalPGDataBase", Usage: "Database for external PostgreSQL database", Path: []string{"frontend", "database", "postgresqlDatabase"}},
+	{Name: "external-postgres-user", Field: "ExternalPGUser", Usage: "User for external PostgreSQL database", Path: []string{"frontend", "database", "postgresqlUsername"}},
+	{Name: "external-postgres-password", Field: "ExternalPGPassword", Usage: "Password for external PostgreSQL database", Path: []string{"frontend", "database", "postgresqlPassword"}, Secret: true},
+	{Name: "external-postgres-ssl-mode", Field: "ExternalPGSSLMode", Kind: flags.Enum, Usage: "PostgreSQL SSL mode [disable|allow|prefer|require|verify-ca|verify-full]", Path: []string{"frontend", "database", "postgresqlSslMode"}, Values: []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}},
+	{Name: "external-postgres-client-secret", Field: "ExternalPGClientSecret", Usage: "Secret name for external PostgreSQL client certificate (TLS Secret)", Path: []string{"frontend", "database", "clientSecretName"}},
+	{Name: "external-postgres-rootca-secret", Field: "ExternalPGRootCASecret", Usage: "Secret name for external PostgreSQL root certificate\n", Path: []string{"frontend", "database", "rootCASecretName"}},
 
 	// Secrets
-	cmd.Flags().StringVar(&ctl.flagTree.PGPassword, "postgres-password", defaults.PGPassword, "PostgreSQL password")
-	cmd.Flags().StringVar(&ctl.flagTree.PGExistingSecret, "postgres-secret", defaults.PGExistingSecret, "Existing secret for PostgreSQL")
+	{Name: "postgres-password", Field: "PGPassword", Usage: "PostgreSQL password", Path: []string{"postgresql", "postgresqlPassword"}, Secret: true},
+	{Name: "postgres-secret", Field: "PGExistingSecret", Usage: "Existing secret for PostgreSQL", Path: []string{"global", "postgresql", "existingSecret"}},
+})
+
+// AddCobraFlagsToCommand adds flags for the BDBA helm chart to the cmd
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
+	}
+
+	bdbaFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
 }
 
 // CheckValuesFromFlags returns an error if a value set by a flag is invalid
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if err := bdbaFlags.Validate(flagset); err != nil {
+		return err
+	}
+	if flagset.Lookup("upload-retention-days").Changed && ctl.flagTree.UploadRetentionDays < 0 {
+		return fmt.Errorf("--upload-retention-days must be 0 or more")
+	}
+	if flagset.Lookup("external-s3-endpoint").Changed {
+		for _, name := range []string{"external-s3-bucket", "external-s3-access-key-id", "external-s3-secret-access-key"} {
+			if !flagset.Lookup(name).Changed {
+				return fmt.Errorf("--%s must be set for --external-s3-endpoint", name)
+			}
+		}
+	}
+
+	if flagset.Lookup("expose-ui").Changed {
+		switch strings.ToUpper(ctl.flagTree.ExposeService) {
+		case util.NONE, util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT:
+		case util.INGRESS:
+			if !flagset.Lookup("expose-ui-hostname").Changed && !flagset.Lookup("ingress-host").Changed {
+				return fmt.Errorf("--expose-ui-hostname must be set for --expose-ui %s", util.INGRESS)
+			}
+		default:
+			return fmt.Errorf("expose ui must be '%s', '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.INGRESS, util.OPENSHIFT, util.NONE)
+		}
+	}
+
 	if flagset.Lookup("enable-email").Value.String() == "true" {
 		if !flagset.Lookup("email-smtp-host").Changed {
 			return fmt.Errorf("--email-smtp-host my be set for email")
@@ -416,6 +476,20 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 	return nil
 }
 
+// GetExternalS3Bucket returns the external S3 bucket set by the flags, or nil if the minio of the chart is used
+func (ctl *HelmValuesFromCobraFlags) GetExternalS3Bucket() *util.S3Bucket {
+	if len(ctl.flagTree.ExternalS3Endpoint) == 0 {
+		return nil
+	}
+	return &util.S3Bucket{
+		Endpoint:        ctl.flagTree.ExternalS3Endpoint,
+		Region:          ctl.flagTree.ExternalS3Region,
+		Bucket:          ctl.flagTree.ExternalS3Bucket,
+		AccessKeyID:     ctl.flagTree.ExternalS3AccessKeyID,
+		SecretAccessKey: ctl.flagTree.ExternalS3SecretKey,
+	}
+}
+
 // GenerateHelmFlagsFromCobraFlags checks each flag in synopsysctl and updates the map to
 // contain the corresponding helm chart field and value
 func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pflag.FlagSet) (map[string]interface{}, error) {
@@ -437,139 +511,37 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 		case "cluster-domain":
 			util.SetHelmValueInMap(ctl.args, []string{"rabbitmq", "rabbitmq", "clustering", "k8s_domain"}, ctl.flagTree.ClusterDomain)
 			util.SetHelmValueInMap(ctl.args, []string{"minio", "clusterDomain"}, ctl.flagTree.ClusterDomain)
-		case "postgres-storage-class":
-			util.SetHelmValueInMap(ctl.args, []string{"postgresql", "persistence", "storageClass"}, ctl.flagTree.PGStorageClass)
-		case "postgres-size":
-			util.SetHelmValueInMap(ctl.args, []string{"postgresql", "persistence", "size"}, ctl.flagTree.PGPVCSize)
-		case "postgres-existing-claim":
-			util.SetHelmValueInMap(ctl.args, []string{"postgresql", "persistence", "existingClaim"}, ctl.flagTree.PGExistingClaim)
-		case "minio-storage-class":
-			util.SetHelmValueInMap(ctl.args, []string{"minio", "persistence", "storageClass"}, ctl.flagTree.MinioStorageClass)
-		case "minio-size":
-			util.SetHelmValueInMap(ctl.args, []string{"minio", "persistence", "size"}, ctl.flagTree.MinioPVCSize)
-		case "minio-existing-claim":
-			util.SetHelmValueInMap(ctl.args, []string{"minio", "persistence", "existingClaim"}, ctl.flagTree.MinioExistingClaim)
-		case "rabbitmq-storage-class":
-			util.SetHelmValueInMap(ctl.args, []string{"rabbitmq", "persistence", "storageClass"}, ctl.flagTree.RabbitMQStorageClass)
-		case "rabbitmq-size":
-			util.SetHelmValueInMap(ctl.args, []string{"rabbitmq", "persistence", "size"}, ctl.flagTree.RabbitMQPVCSize)
-		case "rabbitmq-existing-claim":
-			util.SetHelmValueInMap(ctl.args, []string{"rabbitmq", "persistence", "existingClaim"}, ctl.flagTree.RabbitMQExistingClaim)
-		case "license-username":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "licensing", "username"}, ctl.flagTree.LicensingUsername)
-		case "license-password":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "licensing", "password"}, ctl.flagTree.LicensingPassword)
-		case "license-upstream":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "licensing", "upstream"}, ctl.flagTree.LicensingUpstream)
-		case "session-cookie-age":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "sessionCookieAge"}, ctl.flagTree.SessionCookieAge)
-		case "frontend-replicas":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "replicas"}, ctl.flagTree.FrontendReplicas)
-		case "hide-licenses":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "hideLicenses"}, ctl.flagTree.HideLicenses)
-		case "enable-offline-mode":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "offlineMode"}, ctl.flagTree.OfflineMode)
-		case "admin-email":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "admin"}, ctl.flagTree.AdminEmail)
-		case "root-url":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "web", "rootURL"}, ctl.flagTree.RootURL)
-		case "enable-email":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "enabled"}, ctl.flagTree.EmailEnabled)
-		case "email-smtp-host":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "smtpHost"}, ctl.flagTree.EmailSMTPHost)
-		case "email-smtp-port":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "smtpPort"}, ctl.flagTree.EmailSMTPPort)
-		case "email-smtp-user":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "smtpUser"}, ctl.flagTree.EmailSMTPUser)
-		case "email-smtp-password":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "smtpPassword"}, ctl.flagTree.EmailSMTPPassword)
-		case "email-from":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "from"}, ctl.flagTree.EmailFrom)
-		case "email-security":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "security"}, ctl.flagTree.EmailSecurity)
-		case "verify-email":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "email", "verify"}, ctl.flagTree.EmailVerify)
-		case "enable-ldap":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "enabled"}, ctl.flagTree.LDAPEnabled)
-		case "ldap-server-uri":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "serverUri"}, ctl.flagTree.LDAPServerURI)
-		case "ldap-user-dn-template":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "userDNTemplate"}, ctl.flagTree.LDAPUserDNTemplate)
-		case "enable-ldap-bind-as-authenticating":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "bindAsAuthenticating"}, ctl.flagTree.LDAPBindAsAuthenticating)
-		case "ldap-bind-dn":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "bindDN"}, ctl.flagTree.LDAPBindDN)
-		case "ldap-bind-password":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "bindPassword"}, ctl.flagTree.LDAPBindPassword)
-		case "ldap-start-tls":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "startTLS"}, ctl.flagTree.LDAPStartTLS)
-		case "verify-ldap":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "verify"}, ctl.flagTree.LDAPVerify)
-		case "ldap-root-ca-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "rootCASecret"}, ctl.flagTree.LDAPRootCASecret)
-		case "ldap-require-group":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "requireGroup"}, ctl.flagTree.LDAPRequireGroup)
-		case "ldap-user-search":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "userSearch"}, ctl.flagTree.LDAPUserSearch)
-		case "ldap-user-search-scope":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "userSearchScope"}, ctl.flagTree.LDAPUserSearchScope)
-		case "ldap-group-search":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "groupSearch"}, ctl.flagTree.LDAPGroupSearch)
-		case "ldap-group-search-scope":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "groupSearchScope"}, ctl.flagTree.LDAPGroupSearchScope)
-		case "enable-ldap-nested-search":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "ldap", "nestedSearch"}, ctl.flagTree.LDAPNestedSearch)
-		case "disable-frontend-logging":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "applicationLogging", "enabled"}, !ctl.flagTree.DisableFrontendLogging)
-		case "disable-worker-logging":
-			util.SetHelmValueInMap(ctl.args, []string{"worker", "applicationLogging", "enabled"}, !ctl.flagTree.DisableWorkerLogging)
-		case "log-retention":
-			util.SetHelmValueInMap(ctl.args, []string{"logRetention"}, ctl.flagTree.LogRetention)
-		case "worker-replicas":
-			util.SetHelmValueInMap(ctl.args, []string{"worker", "replicas"}, ctl.flagTree.WorkerReplicas)
-		case "worker-concurrency":
-			util.SetHelmValueInMap(ctl.args, []string{"worker", "concurrency"}, ctl.flagTree.WorkerConcurrency)
-		case "minio-mode":
-			util.SetHelmValueInMap(ctl.args, []string{"minio", "mode"}, ctl.flagTree.MinioMode)
-		case "root-ca-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"rootCASecret"}, ctl.flagTree.RootCASecret)
-		case "http-proxy":
-			util.SetHelmValueInMap(ctl.args, []string{"httpProxy"}, ctl.flagTree.HTTPProxy)
-		case "http-no-proxy":
-			util.SetHelmValueInMap(ctl.args, []string{"httpNoProxy"}, ctl.flagTree.HTTPNoProxy)
-		case "enable-ingress":
-			util.SetHelmValueInMap(ctl.args, []string{"ingress", "enabled"}, ctl.flagTree.IngressEnabled)
-		case "ingress-host":
-			util.SetHelmValueInMap(ctl.args, []string{"ingress", "host"}, ctl.flagTree.IngressHost)
-		case "enable-ingress-tls":
-			util.SetHelmValueInMap(ctl.args, []string{"ingress", "tls", "enabled"}, ctl.flagTree.IngressTLSEnabled)
-		case "ingress-tls-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"ingress", "tls", "secretName"}, ctl.flagTree.IngressTLSSecretName)
-		case "external-postgres":
-			util.SetHelmValueInMap(ctl.args, []string{"postgresql", "enabled"}, !ctl.flagTree.ExternalPG)
-		case "external-postgres-host":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlHost"}, ctl.flagTree.ExternalPGHost)
-		case "external-postgres-port":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlPort"}, ctl.flagTree.ExternalPGPort)
-		case "external-postgres-database":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlDatabase"}, ctl.flagTree.ExternalPGDataBase)
-		case "external-postgres-user":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlUsername"}, ctl.flagTree.ExternalPGUser)
-		case "external-postgres-password":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlPassword"}, ctl.flagTree.ExternalPGPassword)
-		case "external-postgres-ssl-mode":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "postgresqlSslMode"}, ctl.flagTree.ExternalPGSSLMode)
-		case "external-postgres-client-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "clientSecretName"}, ctl.flagTree.ExternalPGClientSecret)
-		case "external-postgres-rootca-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"frontend", "database", "rootCASecretName"}, ctl.flagTree.ExternalPGRootCASecret)
-		case "postgres-password":
-			util.SetHelmValueInMap(ctl.args, []string{"postgresql", "postgresqlPassword"}, ctl.flagTree.PGPassword)
-		case "postgres-secret":
-			util.SetHelmValueInMap(ctl.args, []string{"global", "postgresql", "existingSecret"}, ctl.flagTree.PGExistingSecret)
-
+		case "external-s3-endpoint":
+			util.SetHelmValueInMap(ctl.args, []string{"minio", "enabled"}, len(ctl.flagTree.ExternalS3Endpoint) == 0)
+			util.SetHelmValueInMap(ctl.args, []string{"s3", "endpoint"}, ctl.flagTree.ExternalS3Endpoint)
+		case "expose-ui":
+			// the exposed service and route are managed by synopsysctl, the ingress is part of the chart
+			util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, true)
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "enabled"}, false)
+			switch strings.ToUpper(ctl.flagTree.ExposeService) {
+			case util.NODEPORT:
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "NodePort")
+			case util.LOADBALANCER:
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "LoadBalancer")
+			case util.OPENSHIFT:
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "OpenShift")
+			case util.INGRESS:
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "Ingress")
+				util.SetHelmValueInMap(ctl.args, []string{"ingress", "enabled"}, true)
+			default:
+				util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, false)
+			}
+		case "expose-ui-hostname":
+			util.SetHelmValueInMap(ctl.args, []string{"exposedHostname"}, ctl.flagTree.ExposedHostname)
+			if strings.EqualFold(ctl.flagTree.ExposeService, util.INGRESS) {
+				util.SetHelmValueInMap(ctl.args, []string{"ingress", "host"}, ctl.flagTree.ExposedHostname)
+			}
 		default:
-			log.Debugf("flag '%s': NOT FOUND", f.Name)
+			if found, err := bdbaFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+				log.Fatalf("%+v", err)
+			} else if !found {
+				log.Debugf("flag '%s': NOT FOUND", f.Name)
+			}
 		}
 	} else {
 		log.Debugf("flag '%s': UNCHANGED", f.Name)
@@ -91,6 +91,38 @@ func TestSetCRSpecFieldByFlag(t *testing.T) {
 				},
 			},
 		},
+		// case
+		{
+			flagName: "expose-ui",
+			changedCtl: &HelmValuesFromCobraFlags{
+				flagTree: FlagTree{
+					ExposeService: "INGRESS",
+				},
+			},
+			changedArgs: map[string]interface{}{
+				"exposeui":           true,
+				"exposedServiceType": "Ingress",
+				"ingress": map[string]interface{}{
+					"enabled": true,
+				},
+			},
+		},
+		// case
+		{
+			flagName: "expose-ui",
+			changedCtl: &HelmValuesFromCobraFlags{
+				flagTree: FlagTree{
+					ExposeService: "NODEPORT",
+				},
+			},
+			changedArgs: map[string]interface{}{
+				"exposeui":           true,
+				"exposedServiceType": "NodePort",
+				"ingress": map[string]interface{}{
+					"enabled": false,
+				},
+			},
+		},
 		// TODO: More test cases ...
 	}
 
@@ -0,0 +1,64 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package bdba
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// QueueStatus is the state of a RabbitMQ queue reported by the management API of the BDBA RabbitMQ
+type QueueStatus struct {
+	Name                   string `json:"name"`
+	Messages               int    `json:"messages"`
+	MessagesReady          int    `json:"messages_ready"`
+	MessagesUnacknowledged int    `json:"messages_unacknowledged"`
+	Consumers              int    `json:"consumers"`
+}
+
+// ParseQueues parses the response of the '/api/queues' endpoint of the RabbitMQ management API. The queues are sorted by name
+func ParseQueues(data []byte) ([]QueueStatus, error) {
+	queues := []QueueStatus{}
+	if err := json.Unmarshal(data, &queues); err != nil {
+		return nil, fmt.Errorf("unable to parse the RabbitMQ queues due to %+v", err)
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// WorkerUtilization returns the percentage of the worker slots that are busy. The number of slots is the number of
+// ready worker replicas times the concurrency of each worker, the busy slots are the unacknowledged messages
+func WorkerUtilization(queues []QueueStatus, readyReplicas int, concurrency int) float64 {
+	slots := readyReplicas * concurrency
+	if slots <= 0 {
+		return 0
+	}
+	busy := 0
+	for _, queue := range queues {
+		busy += queue.MessagesUnacknowledged
+	}
+	if busy > slots {
+		busy = slots
+	}
+	return float64(busy) * 100 / float64(slots)
+}
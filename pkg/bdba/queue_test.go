@@ -0,0 +1,53 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package bdba
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueues(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte(`[
+		{"name": "tasks", "messages": 12, "messages_ready": 9, "messages_unacknowledged": 3, "consumers": 4, "vhost": "/"},
+		{"name": "celery", "messages": 0, "messages_ready": 0, "messages_unacknowledged": 0, "consumers": 4, "vhost": "/"}
+	]`)
+	queues, err := ParseQueues(data)
+	assert.NoError(err)
+	assert.Equal([]QueueStatus{
+		{Name: "celery", Consumers: 4},
+		{Name: "tasks", Messages: 12, MessagesReady: 9, MessagesUnacknowledged: 3, Consumers: 4},
+	}, queues)
+
+	_, err = ParseQueues([]byte("not json"))
+	assert.Error(err)
+}
+
+func TestWorkerUtilization(t *testing.T) {
+	assert := assert.New(t)
+	queues := []QueueStatus{{Name: "tasks", MessagesUnacknowledged: 3}, {Name: "celery", MessagesUnacknowledged: 1}}
+	assert.Equal(50.0, WorkerUtilization(queues, 4, 2))
+	assert.Equal(100.0, WorkerUtilization(queues, 1, 2))
+	assert.Equal(0.0, WorkerUtilization(queues, 0, 2))
+}
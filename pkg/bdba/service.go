@@ -0,0 +1,133 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package bdba
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/api"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// GetExposedResourceName returns the name of the service or route that exposes the BDBA user interface
+func GetExposedResourceName(releaseName string) string {
+	return fmt.Sprintf("%s-exposed", releaseName)
+}
+
+// GetExposure returns the exposed service and route of the BDBA user interface
+func GetExposure(namespace string, releaseName string) util.Exposure {
+	return util.Exposure{
+		App:         globals.BDBAName,
+		Namespace:   namespace,
+		Name:        releaseName,
+		ServiceName: GetExposedResourceName(releaseName),
+		RouteName:   GetExposedResourceName(releaseName),
+	}
+}
+
+// CRUDServiceOrRoute creates, updates or deletes the service or the OpenShift route that exposes the BDBA user interface
+// based on the Helm values of the instance. The ingress is part of the chart, the service and route are removed if it is used
+func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, releaseName string, helmValues map[string]interface{}) error {
+	exposed, _ := util.GetHelmValueFromMap(helmValues, []string{"exposeui"}).(bool)
+	serviceType, _ := util.GetHelmValueFromMap(helmValues, []string{"exposedServiceType"}).(string)
+	hostname, _ := util.GetHelmValueFromMap(helmValues, []string{"exposedHostname"}).(string)
+	if !exposed || serviceType == "Ingress" {
+		return DeleteServiceOrRoute(restConfig, kubeClient, namespace, releaseName)
+	}
+	if err := util.ReconcileExposure(restConfig, kubeClient, GetExposure(namespace, releaseName)); err != nil {
+		return err
+	}
+
+	frontend, err := getFrontendService(kubeClient, namespace, releaseName)
+	if err != nil {
+		return err
+	}
+	name := GetExposedResourceName(releaseName)
+	labels := map[string]string{"app": globals.BDBAName, "name": releaseName, "component": "exposed"}
+
+	switch serviceType {
+	case "NodePort", "LoadBalancer":
+		svc := util.GetKubeService(namespace, name, labels, frontend.Spec.Selector, frontend.Spec.Ports[0].Port, frontend.Spec.Ports[0].TargetPort.String(), corev1.ServiceType(serviceType))
+		existing, err := util.GetService(kubeClient, namespace, name)
+		if err != nil {
+			if _, err := util.CreateKubeService(kubeClient, namespace, svc); err != nil {
+				return fmt.Errorf("unable to create the BDBA exposed service due to %+v", err)
+			}
+		} else if !strings.EqualFold(string(existing.Spec.Type), serviceType) {
+			// the type of a service can't be changed in place if node ports were allocated
+			if err := util.DeleteService(kubeClient, namespace, name); err != nil {
+				return fmt.Errorf("unable to delete the BDBA exposed service due to %+v", err)
+			}
+			if _, err := util.CreateKubeService(kubeClient, namespace, svc); err != nil {
+				return fmt.Errorf("unable to create the BDBA exposed service due to %+v", err)
+			}
+		}
+	case "OpenShift":
+		if !util.IsOpenshift(kubeClient) {
+			return fmt.Errorf("the BDBA user interface can only be exposed with a route on OpenShift")
+		}
+		routeClient := util.GetRouteClient(restConfig, kubeClient, namespace)
+		route := util.GetRouteComponent(&api.Route{
+			Namespace:   namespace,
+			Name:        name,
+			Kind:        "Service",
+			ServiceName: frontend.Name,
+			PortName:    frontend.Spec.Ports[0].Name,
+		}, labels)
+		route.Spec.Host = hostname
+		if existing, err := util.GetRoute(routeClient, namespace, name); err == nil {
+			existing.Spec.Host = route.Spec.Host
+			existing.Spec.To = route.Spec.To
+			existing.Spec.Port = route.Spec.Port
+			if _, err := util.UpdateRoute(routeClient, namespace, existing); err != nil {
+				return fmt.Errorf("unable to update the BDBA route due to %+v", err)
+			}
+		} else if _, err := util.CreateRoute(routeClient, namespace, route); err != nil {
+			return fmt.Errorf("unable to create the BDBA route due to %+v", err)
+		}
+	}
+	return nil
+}
+
+// DeleteServiceOrRoute deletes the service and the OpenShift route that expose the BDBA user interface
+func DeleteServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, releaseName string) error {
+	return util.DeleteExposure(restConfig, kubeClient, GetExposure(namespace, releaseName))
+}
+
+// getFrontendService returns the service of the BDBA web frontend created by the chart
+func getFrontendService(kubeClient *kubernetes.Clientset, namespace string, releaseName string) (*corev1.Service, error) {
+	services, err := util.ListServices(kubeClient, namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the services in namespace '%s' due to %+v", namespace, err)
+	}
+	for i, svc := range services.Items {
+		if strings.HasPrefix(svc.Name, releaseName) && strings.Contains(svc.Name, "frontend") && len(svc.Spec.Ports) > 0 {
+			return &services.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find the BDBA frontend service in namespace '%s'", namespace)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// Actions of the import of an in-app setting
+const (
+	AppConfigActionCreate = "create"
+	AppConfigActionUpdate = "update"
+)
+
+// appConfigPageSize is the number of items requested per page of the Black Duck API
+var appConfigPageSize = 100
+
+// AppConfigTimeout is the timeout of the requests to the Black Duck API
+var AppConfigTimeout = time.Minute
+
+// AppConfigResource is a kind of in-app setting of Black Duck, listed and created at its path of the API, and
+// matched between instances by its key
+type AppConfigResource struct {
+	Kind string
+	Path string
+	Key  string
+}
+
+// AppConfigResources are the in-app settings exported and imported, in the order they are imported
+var AppConfigResources = []AppConfigResource{
+	{Kind: "usergroups", Path: "/api/usergroups", Key: "name"},
+	{Kind: "policy-rules", Path: "/api/policy-rules", Key: "name"},
+	{Kind: "external-extensions", Path: "/api/external-extensions", Key: "name"},
+}
+
+// appConfigServerFields are the fields of the items that are set by the instance and are not imported
+var appConfigServerFields = []string{"_meta", "createdAt", "createdBy", "createdByUser", "updatedAt", "updatedBy", "updatedByUser"}
+
+// AppConfig is the in-app settings of a Black Duck instance by kind
+type AppConfig struct {
+	Version  string                              `json:"version,omitempty"`
+	Settings map[string][]map[string]interface{} `json:"settings"`
+}
+
+// AppConfigChange is a change made, or to be made, by the import of the in-app settings
+type AppConfigChange struct {
+	Kind   string
+	Name   string
+	Action string
+}
+
+// appConfigPage is a page of items of the Black Duck API
+type appConfigPage struct {
+	TotalCount int                      `json:"totalCount"`
+	Items      []map[string]interface{} `json:"items"`
+}
+
+// NewAppConfigClient returns a client of the Black Duck API at the URL authenticated by the API token
+func NewAppConfigClient(blackDuckURL, apiToken string) *util.ProductAPIClient {
+	return util.NewProductAPIClient(blackDuckURL, &util.BlackDuckAPITokenAuth{APIToken: apiToken}, AppConfigTimeout)
+}
+
+// listAppConfigItems lists all the items of a kind of in-app setting
+func listAppConfigItems(client *util.ProductAPIClient, resource AppConfigResource) ([]map[string]interface{}, error) {
+	items := []map[string]interface{}{}
+	for offset := 0; ; offset += appConfigPageSize {
+		page := appConfigPage{}
+		if err := client.DoJSON(http.MethodGet, fmt.Sprintf("%s?limit=%d&offset=%d", resource.Path, appConfigPageSize, offset), nil, &page); err != nil {
+			return nil, fmt.Errorf("unable to list the %s due to %+v", resource.Kind, err)
+		}
+		items = append(items, page.Items...)
+		if len(page.Items) == 0 || len(items) >= page.TotalCount {
+			return items, nil
+		}
+	}
+}
+
+// CleanAppConfigItem returns a copy of the item without the fields set by the instance
+func CleanAppConfigItem(item map[string]interface{}) map[string]interface{} {
+	cleaned := make(map[string]interface{}, len(item))
+	for key, value := range item {
+		cleaned[key] = value
+	}
+	for _, field := range appConfigServerFields {
+		delete(cleaned, field)
+	}
+	return cleaned
+}
+
+// getAppConfigItemPath returns the path of an item in the API from the href of its metadata
+func getAppConfigItemPath(item map[string]interface{}) (string, error) {
+	meta, _ := item["_meta"].(map[string]interface{})
+	href, _ := meta["href"].(string)
+	if len(href) == 0 {
+		return "", fmt.Errorf("the item has no href")
+	}
+	itemURL, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	return itemURL.RequestURI(), nil
+}
+
+// ExportAppConfig exports the in-app settings of the Black Duck instance
+func ExportAppConfig(client *util.ProductAPIClient) (*AppConfig, error) {
+	config := &AppConfig{Settings: map[string][]map[string]interface{}{}}
+	version := struct {
+		Version string `json:"version"`
+	}{}
+	if err := client.DoJSON(http.MethodGet, "/api/current-version", nil, &version); err != nil {
+		return nil, fmt.Errorf("unable to get the version of Black Duck due to %+v", err)
+	}
+	config.Version = version.Version
+	for _, resource := range AppConfigResources {
+		items, err := listAppConfigItems(client, resource)
+		if err != nil {
+			return nil, err
+		}
+		cleaned := []map[string]interface{}{}
+		for _, item := range items {
+			cleaned = append(cleaned, CleanAppConfigItem(item))
+		}
+		sort.SliceStable(cleaned, func(i, j int) bool {
+			return fmt.Sprintf("%v", cleaned[i][resource.Key]) < fmt.Sprintf("%v", cleaned[j][resource.Key])
+		})
+		config.Settings[resource.Kind] = cleaned
+	}
+	return config, nil
+}
+
+// ImportAppConfig creates the in-app settings that don't exist in the Black Duck instance and updates the ones that
+// exist, matched by their key. The changes are only returned if dryRun is set
+func ImportAppConfig(client *util.ProductAPIClient, config *AppConfig, dryRun bool) ([]AppConfigChange, error) {
+	changes := []AppConfigChange{}
+	for _, resource := range AppConfigResources {
+		items, ok := config.Settings[resource.Kind]
+		if !ok {
+			continue
+		}
+		existingItems, err := listAppConfigItems(client, resource)
+		if err != nil {
+			return changes, err
+		}
+		existingPaths := map[string]string{}
+		for _, existingItem := range existingItems {
+			if path, err := getAppConfigItemPath(existingItem); err == nil {
+				existingPaths[fmt.Sprintf("%v", existingItem[resource.Key])] = path
+			}
+		}
+		for _, item := range items {
+			name := fmt.Sprintf("%v", item[resource.Key])
+			item = CleanAppConfigItem(item)
+			change := AppConfigChange{Kind: resource.Kind, Name: name, Action: AppConfigActionCreate}
+			path, exists := existingPaths[name]
+			if exists {
+				change.Action = AppConfigActionUpdate
+			}
+			if !dryRun {
+				if exists {
+					err = client.DoJSON(http.MethodPut, path, item, nil)
+				} else {
+					err = client.DoJSON(http.MethodPost, resource.Path, item, nil)
+				}
+				if err != nil {
+					return changes, fmt.Errorf("unable to %s the %s '%s' due to %+v", change.Action, resource.Kind, name, err)
+				}
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
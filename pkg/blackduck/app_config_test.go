@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAppConfigServer returns a Black Duck API with a policy rule and records the requests that change it
+func newAppConfigServer(requests *[]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/tokens/authenticate":
+			fmt.Fprint(w, `{"bearerToken": "bearer", "expiresInMilliseconds": 7200000}`)
+		case r.URL.Path == "/api/current-version":
+			fmt.Fprint(w, `{"version": "2020.6.0"}`)
+		case r.Method != http.MethodGet:
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			*requests = append(*requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body["name"]))
+		case r.URL.Path == "/api/policy-rules" && r.URL.Query().Get("offset") == "0":
+			fmt.Fprintf(w, `{"totalCount": 2, "items": [{"name": "no-gpl", "enabled": true, "createdAt": "2020-06-01T10:00:00.000Z", "_meta": {"href": "%s/api/policy-rules/1"}}]}`, server.URL)
+		case r.URL.Path == "/api/policy-rules":
+			fmt.Fprintf(w, `{"totalCount": 2, "items": [{"name": "high-vulnerabilities", "enabled": false, "_meta": {"href": "%s/api/policy-rules/2"}}]}`, server.URL)
+		default:
+			fmt.Fprint(w, `{"totalCount": 0, "items": []}`)
+		}
+	}))
+	return server
+}
+
+func TestExportAppConfig(t *testing.T) {
+	assert := assert.New(t)
+	requests := []string{}
+	server := newAppConfigServer(&requests)
+	defer server.Close()
+
+	appConfigPageSize = 1
+	defer func() { appConfigPageSize = 100 }()
+	config, err := ExportAppConfig(NewAppConfigClient(server.URL, "api-token"))
+	assert.NoError(err)
+	assert.Equal("2020.6.0", config.Version)
+	assert.Equal([]map[string]interface{}{
+		{"name": "high-vulnerabilities", "enabled": false},
+		{"name": "no-gpl", "enabled": true},
+	}, config.Settings["policy-rules"])
+	assert.Equal([]map[string]interface{}{}, config.Settings["usergroups"])
+	assert.Empty(requests)
+}
+
+func TestImportAppConfig(t *testing.T) {
+	assert := assert.New(t)
+	requests := []string{}
+	server := newAppConfigServer(&requests)
+	defer server.Close()
+
+	config := &AppConfig{Settings: map[string][]map[string]interface{}{
+		"policy-rules": {{"name": "no-gpl", "enabled": false}, {"name": "no-agpl", "enabled": true}},
+		"usergroups":   {{"name": "developers", "createdAt": "2020-06-01T10:00:00.000Z"}},
+	}}
+	expectedChanges := []AppConfigChange{
+		{Kind: "usergroups", Name: "developers", Action: AppConfigActionCreate},
+		{Kind: "policy-rules", Name: "no-gpl", Action: AppConfigActionUpdate},
+		{Kind: "policy-rules", Name: "no-agpl", Action: AppConfigActionCreate},
+	}
+
+	changes, err := ImportAppConfig(NewAppConfigClient(server.URL, "api-token"), config, true)
+	assert.NoError(err)
+	assert.Equal(expectedChanges, changes)
+	assert.Empty(requests)
+
+	changes, err = ImportAppConfig(NewAppConfigClient(server.URL, "api-token"), config, false)
+	assert.NoError(err)
+	assert.Equal(expectedChanges, changes)
+	assert.Equal([]string{"POST /api/usergroups developers", "PUT /api/policy-rules/1 no-gpl", "POST /api/policy-rules no-agpl"}, requests)
+}
@@ -30,12 +30,12 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math/big"
 	"os"
 	"time"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -123,12 +123,12 @@ func CreateSelfSignedCert() (string, string) {
 
 // GetCertificateSecretFromFile generates secret from file
 func GetCertificateSecretFromFile(secretName, namespace, certPath, keyPath string) (*corev1.Secret, error) {
-	cert, err := ioutil.ReadFile(certPath)
+	cert, err := util.ReadFromFile(certPath)
 	if err != nil {
 		return nil, err
 	}
 
-	key, err := ioutil.ReadFile(keyPath)
+	key, err := util.ReadFromFile(keyPath)
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +22,8 @@
 package blackduck
 
 import (
-	"io/ioutil"
+	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/blackducksoftware/synopsysctl/pkg/api"
@@ -134,6 +135,9 @@ func OperatorAPISecurityContextToHelm(opSecurityContext api.SecurityContext) map
 	return helmSecurityContexts
 }
 
+// ReportingPostgresPasswordSecretKey is the key of the password in the secret of the reporting Postgres credentials
+const ReportingPostgresPasswordSecretKey = "HUB_POSTGRES_REPORTING_PASSWORD_FILE"
+
 // GetCertsFromFlagsAndSetHelmValue converts synopsysctl certificate files to kube secrets
 func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pflag.FlagSet, helmVal map[string]interface{}) ([]corev1.Secret, error) {
 	var objects []corev1.Secret
@@ -142,7 +146,20 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		keyPath := flagset.Lookup("certificate-key-file-path").Value.String()
 		secretName := util.GetResourceName(name, util.BlackDuckName, "webserver-certificate")
 
-		secret, err := GetCertificateSecretFromFile(secretName, namespace, certPath, keyPath)
+		cert, err := util.ReadFromFile(certPath)
+		if err != nil {
+			return nil, err
+		}
+		key, err := util.ReadFromFile(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		hostname, _ := util.GetHelmValueFromMap(helmVal, []string{"environs", "PUBLIC_HUB_WEBSERVER_HOST"}).(string)
+		if err := util.ValidateCertificateAndKey(cert, key, hostname); err != nil {
+			return nil, fmt.Errorf("invalid Black Duck certificate: %+v", err)
+		}
+
+		secret, err := GetCertificateSecret(secretName, namespace, cert, key)
 		if err != nil {
 			return nil, err
 		}
@@ -154,7 +171,7 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		certPath := flagset.Lookup("proxy-certificate-file-path").Value.String()
 		secretName := util.GetResourceName(name, util.BlackDuckName, "proxy-certificate")
 
-		cert, err := ioutil.ReadFile(certPath)
+		cert, err := util.ReadFromFile(certPath)
 		if err != nil {
 			return nil, err
 		}
@@ -171,7 +188,7 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		certPath := flagset.Lookup("auth-custom-ca-file-path").Value.String()
 		secretName := util.GetResourceName(name, util.BlackDuckName, "auth-custom-ca")
 
-		cert, err := ioutil.ReadFile(certPath)
+		cert, err := util.ReadFromFile(certPath)
 		if err != nil {
 			return nil, err
 		}
@@ -188,7 +205,7 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		certPath := flagset.Lookup("proxy-password-file-path").Value.String()
 		secretName := util.GetResourceName(name, util.BlackDuckName, "proxy-password")
 
-		cert, err := ioutil.ReadFile(certPath)
+		cert, err := util.ReadFromFile(certPath)
 		if err != nil {
 			return nil, err
 		}
@@ -205,7 +222,7 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		certPath := flagset.Lookup("ldap-password-file-path").Value.String()
 		secretName := util.GetResourceName(name, util.BlackDuckName, "ldap-password")
 
-		cert, err := ioutil.ReadFile(certPath)
+		cert, err := util.ReadFromFile(certPath)
 		if err != nil {
 			return nil, err
 		}
@@ -218,5 +235,22 @@ func GetCertsFromFlagsAndSetHelmValue(name string, namespace string, flagset *pf
 		objects = append(objects, *secret)
 	}
 
+	if flagset.Lookup("reporting-postgres-password-file-path").Changed {
+		passwordPath := flagset.Lookup("reporting-postgres-password-file-path").Value.String()
+		secretName := util.GetResourceName(name, util.BlackDuckName, "reporting-db-creds")
+
+		password, err := util.ReadFromFile(passwordPath)
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := GetSecret(secretName, namespace, bytes.TrimSpace(password), ReportingPostgresPasswordSecretKey)
+		if err != nil {
+			return nil, err
+		}
+		util.SetHelmValueInMap(helmVal, []string{"postgres", "reporting", "secretName"}, secretName)
+		objects = append(objects, *secret)
+	}
+
 	return objects, nil
 }
@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	blackduckv1 "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -50,15 +51,22 @@ type FlagTree struct {
 	Registry        string
 	PullSecrets     []string
 	ImageRegistries []string
+	ImagePullPolicy string
+	PinImageDigests bool
 
 	PvcStorageClass             string
 	PersistentStorage           string
 	PVCFilePath                 string
 	Size                        string
+	SizeFilePath                string
+	SizeConfigMap               string
 	DeploymentResourcesFilePath string
 
-	ExposeService   string
-	ExposedNodePort string
+	ExposeService    string
+	ExposedNodePort  string
+	IngressClass     string
+	IngressHost      string
+	IngressTLSSecret string
 
 	ExternalPostgresHost          string
 	ExternalPostgresPort          int
@@ -72,6 +80,13 @@ type FlagTree struct {
 	UserPassword                  string
 	PostgresInitPostCommand       string
 
+	ReportingPostgresHost             string
+	ReportingPostgresPort             int
+	ReportingPostgresDatabase         string
+	ReportingPostgresUser             string
+	ReportingPostgresSsl              string
+	ReportingPostgresPasswordFilePath string
+
 	RedisTLSEnabled         bool
 	RedisMaxTotalConnection int
 	RedisMaxIdleConnection  int
@@ -79,6 +94,7 @@ type FlagTree struct {
 	CertificateName          string
 	CertificateFilePath      string
 	CertificateKeyFilePath   string
+	CertificateIssuer        string
 	ProxyCertificateFilePath string
 	AuthCustomCAFilePath     string
 	ProxyPasswordFilePath    string
@@ -86,15 +102,22 @@ type FlagTree struct {
 
 	SealKey string
 
-	Environs []string
+	Environs               []string
+	SecretEnvironsFilePath string
 
 	LivenessProbes         string
 	EnableBinaryAnalysis   bool
 	EnableSourceCodeUpload bool
 	EnableInitContainer    string
+	WaitForDBInit          bool
 
 	NodeAffinityFilePath    string
 	SecurityContextFilePath string
+	FsGroup                 int64
+	RunAsUser               int64
+	SupplementalGroups      []int
+	ExtraVolumes            []string
+	ExtraVolumesFilePath    string
 }
 
 // DefaultFlagTree ...
@@ -114,6 +137,11 @@ var DefaultFlagTree = FlagTree{
 	ExternalPostgresUser: "blackduck_user",
 	ExternalPostgresSsl:  "true",
 	PostgresClaimSize:    "150Gi",
+	// Reporting Postgres
+	ReportingPostgresPort:     5432,
+	ReportingPostgresDatabase: "bds_hub",
+	ReportingPostgresUser:     "blackduck_reporter",
+	ReportingPostgresSsl:      "true",
 	// Redis
 	RedisTLSEnabled:         false,
 	RedisMaxTotalConnection: 128,
@@ -153,111 +181,131 @@ func (ctl *HelmValuesFromCobraFlags) GetArgs() map[string]interface{} {
 	return ctl.args
 }
 
-// AddCobraFlagsToCommand adds flags to a Cobra Command that are need for BlackDuck's Spec.
-// The flags map to fields in the CRSpecBuilderFromCobraFlags struct.
-func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
-	// [DEV NOTE:] please organize flags in order of importance
-	cmd.Flags().SortFlags = false
-
-	defaults := &FlagTree{}
-	if isCreateCmd {
-		defaults = GetDefaultFlagTree()
-	}
-
+// blackDuckFlags are the flags of the Black Duck Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var blackDuckFlags = flags.NewSet("Black Duck", []flags.Flag{
 	// Version
-	cmd.Flags().StringVar(&ctl.flagTree.Version, "version", defaults.Version, "Version of Black Duck")
+	{Name: "version", Field: "Version", Usage: "Version of Black Duck", Path: []string{"imageTag"}},
 
 	// Registry Config
-	cmd.Flags().StringVar(&ctl.flagTree.Registry, "registry", defaults.Registry, "Name of the registry to use for images e.g. docker.io/blackducksoftware")
-	cmd.Flags().StringSliceVar(&ctl.flagTree.PullSecrets, "pull-secret-name", defaults.PullSecrets, "Only if the registry requires authentication\n")
-	cmd.Flags().StringSliceVar(&ctl.flagTree.ImageRegistries, "image-registries", defaults.ImageRegistries, "Set the image registry for each image")
-	cmd.Flags().MarkHidden("image-registries") // only for devs
+	{Name: "registry", Field: "Registry", Usage: "Name of the registry to use for images e.g. docker.io/blackducksoftware"},
+	{Name: "pull-secret-name", Field: "PullSecrets", Usage: "Only if the registry requires authentication"},
+	{Name: "image-pull-policy", Field: "ImagePullPolicy", Usage: "Image pull policy of all containers [Always|IfNotPresent|Never], empty keeps the policies of the chart"},
+	{Name: "pin-image-digests", Field: "PinImageDigests", Kind: flags.Bool, Usage: "If true, resolve the image tags to digests at install time and record them in the values so the images do not change until the next version change\n"},
+	{Name: "image-registries", Field: "ImageRegistries", Usage: "Set the image registry for each image", Hidden: true},
 
 	// Storage
-	if isCreateCmd {
-		cmd.Flags().StringVar(&ctl.flagTree.PvcStorageClass, "pvc-storage-class", defaults.PvcStorageClass, "Name of Storage Class for the PVC")
-		cmd.Flags().StringVar(&ctl.flagTree.PersistentStorage, "persistent-storage", defaults.PersistentStorage, "If true, Black Duck has persistent storage [true|false]")
-		cmd.Flags().StringVar(&ctl.flagTree.PVCFilePath, "pvc-file-path", defaults.PVCFilePath, "Absolute path to a file containing a list of PVC json structs")
-	}
-	cmd.Flags().StringVar(&ctl.flagTree.Size, "size", defaults.Size, "Size of Black Duck [small|medium|large|x-large]")
-	cmd.Flags().StringVar(&ctl.flagTree.DeploymentResourcesFilePath, "deployment-resources-file-path", defaults.DeploymentResourcesFilePath, "Absolute path to a file containing a list of deployment Resources json structs\n")
+	{Name: "pvc-storage-class", Field: "PvcStorageClass", Usage: "Name of Storage Class for the PVC", Path: []string{"storageClass"}, CreateOnly: true},
+	{Name: "persistent-storage", Field: "PersistentStorage", Kind: flags.Bool, Usage: "If true, Black Duck has persistent storage [true|false]", Path: []string{"enablePersistentStorage"}, CreateOnly: true},
+	{Name: "pvc-file-path", Field: "PVCFilePath", Usage: "Absolute path to a file containing a list of PVC json structs", CreateOnly: true},
+	{Name: "size", Field: "Size", Usage: "Size of Black Duck [small|medium|large|x-large] or the name of a custom size"},
+	{Name: "size-file-path", Field: "SizeFilePath", Usage: "Absolute path to a file containing a map of custom size names to the Helm values of their resources"},
+	{Name: "size-configmap", Field: "SizeConfigMap", Usage: "Name of a config map in the namespace with a <size>.yaml key for each custom size"},
+	{Name: "deployment-resources-file-path", Field: "DeploymentResourcesFilePath", Usage: "Absolute path to a file containing a list of deployment Resources json structs\n"},
 
 	// Expose UI
-	cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", defaults.ExposeService, "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n")
-	cmd.Flags().StringVar(&ctl.flagTree.ExposedNodePort, "node-port", defaults.ExposedNodePort, "Value for the NodePort's port (default random)\n")
+	{Name: "expose-ui", Field: "ExposeService", Usage: "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|INGRESS|OPENSHIFT|NONE]"},
+	{Name: "ingress-class", Field: "IngressClass", Usage: "Ingress class of the ingress of Black Duck webserver's user interface (default the default class of the cluster)"},
+	{Name: "ingress-host", Field: "IngressHost", Usage: "Host of the ingress of Black Duck webserver's user interface"},
+	{Name: "ingress-tls-secret", Field: "IngressTLSSecret", Usage: "Name of the TLS secret of the ingress of Black Duck webserver's user interface, empty terminates TLS with the default certificate of the ingress controller"},
+	{Name: "node-port", Field: "ExposedNodePort", Usage: "Value for the NodePort's port (default random)\n", Path: []string{"exposedNodePort"}, MinVersion: "2020.6.0"},
 
 	// Postgres
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresHost, "external-postgres-host", defaults.ExternalPostgresHost, "Host of external Postgres")
-	cmd.Flags().IntVar(&ctl.flagTree.ExternalPostgresPort, "external-postgres-port", defaults.ExternalPostgresPort, "Port of external Postgres")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresAdmin, "external-postgres-admin", defaults.ExternalPostgresAdmin, "Name of 'admin' of external Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresUser, "external-postgres-user", defaults.ExternalPostgresUser, "Name of 'user' of external Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresSsl, "external-postgres-ssl", defaults.ExternalPostgresSsl, "If true, Black Duck uses SSL for external Postgres connection [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresAdminPassword, "external-postgres-admin-password", defaults.ExternalPostgresAdminPassword, "'admin' password of external Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresUserPassword, "external-postgres-user-password", defaults.ExternalPostgresUserPassword, "'user' password of external Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresClaimSize, "postgres-claim-size", defaults.PostgresClaimSize, "Size of the blackduck-postgres PVC")
-	cmd.Flags().StringVar(&ctl.flagTree.AdminPassword, "admin-password", defaults.AdminPassword, "'admin' password of Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.UserPassword, "user-password", defaults.UserPassword, "'user' password of Postgres database")
-	cmd.Flags().StringVar(&ctl.flagTree.PostgresInitPostCommand, "postgres-init-post-command", defaults.PostgresInitPostCommand, "Postgres initialization post command. This flag is supported from Black Duck version 2020.8.0 and above\n")
+	{Name: "external-postgres-host", Field: "ExternalPostgresHost", Usage: "Host of external Postgres", Path: []string{"postgres", "host"}},
+	{Name: "external-postgres-port", Field: "ExternalPostgresPort", Usage: "Port of external Postgres", Path: []string{"postgres", "port"}},
+	{Name: "external-postgres-admin", Field: "ExternalPostgresAdmin", Usage: "Name of 'admin' of external Postgres database", Path: []string{"postgres", "adminUserName"}},
+	{Name: "external-postgres-user", Field: "ExternalPostgresUser", Usage: "Name of 'user' of external Postgres database", Path: []string{"postgres", "userUserName"}},
+	{Name: "external-postgres-ssl", Field: "ExternalPostgresSsl", Kind: flags.Bool, Usage: "If true, Black Duck uses SSL for external Postgres connection [true|false]", Path: []string{"postgres", "ssl"}},
+	{Name: "external-postgres-admin-password", Field: "ExternalPostgresAdminPassword", Usage: "'admin' password of external Postgres database", Path: []string{"postgres", "adminPassword"}, Secret: true},
+	{Name: "external-postgres-user-password", Field: "ExternalPostgresUserPassword", Usage: "'user' password of external Postgres database", Path: []string{"postgres", "userPassword"}, Secret: true},
+	{Name: "postgres-claim-size", Field: "PostgresClaimSize", Kind: flags.Quantity, Usage: "Size of the blackduck-postgres PVC", Path: []string{"postgres", "claimSize"}},
+	{Name: "admin-password", Field: "AdminPassword", Usage: "'admin' password of Postgres database", Secret: true},
+	{Name: "user-password", Field: "UserPassword", Usage: "'user' password of Postgres database", Secret: true},
+	{Name: "postgres-init-post-command", Field: "PostgresInitPostCommand", Usage: "Postgres initialization post command. This flag is supported from Black Duck version 2020.8.0 and above\n", Path: []string{"init", "postCommand"}, MinVersion: "2020.8.0"},
+
+	// Reporting Postgres
+	{Name: "reporting-postgres-host", Field: "ReportingPostgresHost", Usage: "Host of the read-replica Postgres that serves the reporting queries", Path: []string{"postgres", "reporting", "host"}},
+	{Name: "reporting-postgres-port", Field: "ReportingPostgresPort", Usage: "Port of the reporting Postgres", Path: []string{"postgres", "reporting", "port"}},
+	{Name: "reporting-postgres-database", Field: "ReportingPostgresDatabase", Usage: "Name of the database of the reporting Postgres", Path: []string{"postgres", "reporting", "database"}},
+	{Name: "reporting-postgres-user", Field: "ReportingPostgresUser", Usage: "Name of the user of the reporting Postgres", Path: []string{"postgres", "reporting", "userUserName"}},
+	{Name: "reporting-postgres-ssl", Field: "ReportingPostgresSsl", Kind: flags.Bool, Usage: "If true, Black Duck uses SSL for the reporting Postgres connection [true|false]", Path: []string{"postgres", "reporting", "ssl"}},
+	{Name: "reporting-postgres-password-file-path", Field: "ReportingPostgresPasswordFilePath", Usage: "Absolute path to a file containing the password of the user of the reporting Postgres\n"},
 
 	// Redis
-	cmd.Flags().BoolVar(&ctl.flagTree.RedisTLSEnabled, "redis-tls-enabled", defaults.RedisTLSEnabled, "Enable TLS connections between client and Redis")
-	cmd.Flags().IntVar(&ctl.flagTree.RedisMaxTotalConnection, "redis-max-total", defaults.RedisMaxTotalConnection, "Maximum number of concurrent client connections that can be connected to Redis")
-	cmd.Flags().IntVar(&ctl.flagTree.RedisMaxIdleConnection, "redis-max-idle", defaults.RedisMaxIdleConnection, "Maximum number of concurrent client connections that can remain idle in the pool, without extra ones being released\n")
+	{Name: "redis-tls-enabled", Field: "RedisTLSEnabled", Kind: flags.Bool, Usage: "Enable TLS connections between client and Redis", Path: []string{"redis", "tlsEnabled"}, MinVersion: "2020.8.0"},
+	{Name: "redis-max-total", Field: "RedisMaxTotalConnection", Usage: "Maximum number of concurrent client connections that can be connected to Redis", Path: []string{"redis", "maxTotal"}, MinVersion: "2020.10.0"},
+	{Name: "redis-max-idle", Field: "RedisMaxIdleConnection", Usage: "Maximum number of concurrent client connections that can remain idle in the pool, without extra ones being released\n", Path: []string{"redis", "maxIdle"}, MinVersion: "2020.10.0"},
 
 	// Certificates
-	cmd.Flags().StringVar(&ctl.flagTree.CertificateName, "certificate-name", defaults.CertificateName, "Name of Black Duck nginx certificate")
-	cmd.Flags().StringVar(&ctl.flagTree.CertificateFilePath, "certificate-file-path", defaults.CertificateFilePath, "Absolute path to a file for the Black Duck nginx certificate")
-	cmd.Flags().StringVar(&ctl.flagTree.CertificateKeyFilePath, "certificate-key-file-path", defaults.CertificateKeyFilePath, "Absolute path to a file for the Black Duck nginx certificate key")
-	cmd.Flags().StringVar(&ctl.flagTree.ProxyCertificateFilePath, "proxy-certificate-file-path", defaults.ProxyCertificateFilePath, "Absolute path to a file for the Black Duck proxy server’s Certificate Authority (CA)")
-	cmd.Flags().StringVar(&ctl.flagTree.AuthCustomCAFilePath, "auth-custom-ca-file-path", defaults.AuthCustomCAFilePath, "Absolute path to a file for the Certificate authentication using custom CA for Black Duck")
-	cmd.Flags().StringVar(&ctl.flagTree.ProxyPasswordFilePath, "proxy-password-file-path", defaults.ProxyPasswordFilePath, "Absolute path to a file for the Proxy Password for Black Duck")
-	cmd.Flags().StringVar(&ctl.flagTree.LdapPasswordFilePath, "ldap-password-file-path", defaults.LdapPasswordFilePath, "Absolute path to a file for the LDAP Password for Black Duck\n")
+	{Name: "certificate-name", Field: "CertificateName", Usage: "Name of Black Duck nginx certificate"},
+	{Name: "certificate-file-path", Field: "CertificateFilePath", Usage: "Absolute path to a file for the Black Duck nginx certificate"},
+	{Name: "certificate-key-file-path", Field: "CertificateKeyFilePath", Usage: "Absolute path to a file for the Black Duck nginx certificate key"},
+	{Name: "certificate-issuer", Field: "CertificateIssuer", Usage: "cert-manager issuer of the certificate of the webserver host instead of --certificate-file-path, in the format [Issuer/|ClusterIssuer/]NAME, empty to stop using it"},
+	{Name: "proxy-certificate-file-path", Field: "ProxyCertificateFilePath", Usage: "Absolute path to a file for the Black Duck proxy server’s Certificate Authority (CA)"},
+	{Name: "auth-custom-ca-file-path", Field: "AuthCustomCAFilePath", Usage: "Absolute path to a file for the Certificate authentication using custom CA for Black Duck"},
+	{Name: "proxy-password-file-path", Field: "ProxyPasswordFilePath", Usage: "Absolute path to a file for the Proxy Password for Black Duck", MinVersion: "2020.12.0"},
+	{Name: "ldap-password-file-path", Field: "LdapPasswordFilePath", Usage: "Absolute path to a file for the LDAP Password for Black Duck\n", MinVersion: "2020.12.0"},
 
 	// Seal Key
-	if isCreateCmd {
-		cmd.Flags().StringVar(&ctl.flagTree.SealKey, "seal-key", defaults.SealKey, "Seal key to encrypt the master key when Source code upload is enabled and it should be of length 32\n")
-	}
+	{Name: "seal-key", Field: "SealKey", Usage: "Seal key to encrypt the master key when Source code upload is enabled and it should be of length 32\n", Path: []string{"sealKey"}, CreateOnly: true, Secret: true},
 
 	// Environs
-	cmd.Flags().StringSliceVar(&ctl.flagTree.Environs, "environs", defaults.Environs, "List of environment variables\n")
+	{Name: "environs", Field: "Environs", Usage: "List of environment variables"},
+	{Name: "secret-environs-file", Field: "SecretEnvironsFilePath", Usage: "Absolute path to a file mapping the names of environment variables to the secret and key they are read from, replaces the previous secret environment variables\n"},
 
 	// Enable Features
-	cmd.Flags().StringVar(&ctl.flagTree.LivenessProbes, "liveness-probes", defaults.LivenessProbes, "If true, Black Duck uses liveness probes [true|false]")
-	cmd.Flags().BoolVar(&ctl.flagTree.EnableBinaryAnalysis, "enable-binary-analysis", defaults.EnableBinaryAnalysis, "If true, enable binary analysis by setting the environment variable (this takes priority over environs flag values)")
-	cmd.Flags().BoolVar(&ctl.flagTree.EnableSourceCodeUpload, "enable-source-code-upload", defaults.EnableSourceCodeUpload, "If true, enable source code upload by setting the environment variable (this takes priority over environs flag values)\n")
-	cmd.Flags().StringVar(&ctl.flagTree.EnableInitContainer, "enable-init-container", defaults.EnableInitContainer, "If true, Black Duck adds init container to each service to check whether the Postgres is initialized with the databases [true|false]. This flag is supported from Black Duck version 2020.6.1 and above")
+	{Name: "liveness-probes", Field: "LivenessProbes", Kind: flags.Bool, Usage: "If true, Black Duck uses liveness probes [true|false]", Path: []string{"enableLivenessProbe"}},
+	{Name: "enable-binary-analysis", Field: "EnableBinaryAnalysis", Kind: flags.Bool, Usage: "If true, enable binary analysis by setting the environment variable (this takes priority over environs flag values)", Path: []string{"enableBinaryScanner"}},
+	{Name: "enable-source-code-upload", Field: "EnableSourceCodeUpload", Kind: flags.Bool, Usage: "If true, enable source code upload by setting the environment variable (this takes priority over environs flag values)\n", Path: []string{"enableSourceCodeUpload"}},
+	{Name: "enable-init-container", Field: "EnableInitContainer", Kind: flags.Bool, Usage: "If true, Black Duck adds init container to each service to check whether the Postgres is initialized with the databases [true|false]. This flag is supported from Black Duck version 2020.6.1 and above", Path: []string{"enableInitContainer"}},
+	{Name: "wait-for-db-init", Field: "WaitForDBInit", Kind: flags.Bool, Usage: "If true, synopsysctl adds an init container to the components using the database that waits until Postgres accepts connections. Unlike enable-init-container, this flag is supported by all Black Duck versions"},
 
 	// Extra Config Settings
-	cmd.Flags().StringVar(&ctl.flagTree.NodeAffinityFilePath, "node-affinity-file-path", defaults.NodeAffinityFilePath, "Absolute path to a file containing a list of node affinities")
-	cmd.Flags().StringVar(&ctl.flagTree.SecurityContextFilePath, "security-context-file-path", defaults.SecurityContextFilePath, "Absolute path to a file containing a map of pod names to security contexts runAsUser, fsGroup, and runAsGroup")
-}
+	{Name: "node-affinity-file-path", Field: "NodeAffinityFilePath", Usage: "Absolute path to a file containing a list of node affinities"},
+	{Name: "security-context-file-path", Field: "SecurityContextFilePath", Usage: "Absolute path to a file containing a map of pod names to security contexts runAsUser, fsGroup, and runAsGroup"},
+	{Name: "fs-group", Field: "FsGroup", Usage: "Group ID that owns the volumes of all pods, pods in security-context-file-path use the security context of the file"},
+	{Name: "run-as-user", Field: "RunAsUser", Usage: "User ID that runs the containers of all pods, pods in security-context-file-path use the security context of the file"},
+	{Name: "supplemental-groups", Field: "SupplementalGroups", Usage: "Additional group IDs of the containers of all pods, pods in security-context-file-path use the security context of the file"},
+	{Name: "extra-volume", Field: "ExtraVolumes", Usage: "Extra volume to mount into all containers of a component in the format component=name:pvc|configmap|secret|emptydir:/mount/path[:ro], replaces the previous extra volumes", Repeatable: true},
+	{Name: "extra-volumes-file-path", Field: "ExtraVolumesFilePath", Usage: "Absolute path to a file containing a list of extra volumes with component, name, type, mountPath, subPath and readOnly"},
+})
 
-func isValidSize(size string) bool {
-	switch strings.ToLower(size) {
-	case
-		"",
-		"small",
-		"medium",
-		"large",
-		"x-large":
-		return true
+// AddCobraFlagsToCommand adds flags to a Cobra Command that are need for BlackDuck's Spec.
+// The flags map to fields in the CRSpecBuilderFromCobraFlags struct.
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
 	}
-	return false
+
+	blackDuckFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
 }
 
 // CheckValuesFromFlags returns an error if a value stored in the struct will not be able to be used
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if err := blackDuckFlags.Validate(flagset); err != nil {
+		return err
+	}
 	if FlagWasSet(flagset, "size") {
-		if !isValidSize(ctl.flagTree.Size) {
-			return fmt.Errorf("size must be 'small', 'medium', 'large' or 'x-large'")
+		hasCustomSizes := FlagWasSet(flagset, "size-file-path") || FlagWasSet(flagset, "size-configmap")
+		if len(ctl.flagTree.Size) > 0 && !IsBuiltInSize(ctl.flagTree.Size) && !hasCustomSizes {
+			return fmt.Errorf("size must be 'small', 'medium', 'large' or 'x-large', or a custom size from --size-file-path or --size-configmap")
 		}
 	}
 	if FlagWasSet(flagset, "expose-ui") {
 		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService)
 		if !isValid {
-			return fmt.Errorf("expose ui must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
+			return fmt.Errorf("expose ui must be '%s', '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.INGRESS, util.OPENSHIFT, util.NONE)
 		}
 	}
+	if (FlagWasSet(flagset, "ingress-class") || FlagWasSet(flagset, "ingress-host") || FlagWasSet(flagset, "ingress-tls-secret")) && FlagWasSet(flagset, "expose-ui") && !strings.EqualFold(ctl.flagTree.ExposeService, util.INGRESS) {
+		return fmt.Errorf("ingress-class, ingress-host and ingress-tls-secret require expose-ui %s", util.INGRESS)
+	}
+	if FlagWasSet(flagset, "expose-ui") && strings.EqualFold(ctl.flagTree.ExposeService, util.INGRESS) && !FlagWasSet(flagset, "ingress-host") {
+		return fmt.Errorf("ingress-host must be set for expose-ui %s", util.INGRESS)
+	}
 	if FlagWasSet(flagset, "environs") {
 		for _, environ := range ctl.flagTree.Environs {
 			if !strings.Contains(environ, ":") {
@@ -270,6 +318,14 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 			return fmt.Errorf("seal key should be of length 32")
 		}
 	}
+	if FlagWasSet(flagset, "certificate-issuer") && len(ctl.flagTree.CertificateIssuer) > 0 {
+		if FlagWasSet(flagset, "certificate-file-path") {
+			return fmt.Errorf("cannot set both certificate-issuer and certificate-file-path")
+		}
+		if _, err := util.ParseCertificateIssuer(ctl.flagTree.CertificateIssuer); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -293,7 +349,7 @@ func (ctl *HelmValuesFromCobraFlags) MarkRequiredFlags(flagset *pflag.FlagSet, v
 
 	cobra.MarkFlagRequired(flagset, "seal-key")
 
-	if util.CompareVersions(version, "2020.6.0") < 0 {
+	if util.CompareVersions(version, "2020.6.0") < 0 && !FlagWasSet(flagset, "certificate-issuer") {
 		cobra.MarkFlagRequired(flagset, "certificate-file-path")
 		cobra.MarkFlagRequired(flagset, "certificate-key-file-path")
 	}
@@ -301,28 +357,14 @@ func (ctl *HelmValuesFromCobraFlags) MarkRequiredFlags(flagset *pflag.FlagSet, v
 	return nil
 }
 
+// GetFlagsUnsupportedByVersion returns the flags that the chart of the Black Duck version doesn't support
+func GetFlagsUnsupportedByVersion(version string) []string {
+	return blackDuckFlags.GetUnsupportedByVersion(version)
+}
+
 // VerifyChartVersionSupportsChangedFlags ...
 func (ctl *HelmValuesFromCobraFlags) VerifyChartVersionSupportsChangedFlags(flagset *pflag.FlagSet, version string) error {
-	if flagset.Lookup("node-port").Changed && (util.CompareVersions(version, "2020.6.0") < 0) {
-		return fmt.Errorf("--node-port is not supported in Black Duck versions before 2020.6.0")
-	}
-
-	if flagset.Lookup("postgres-init-post-command").Changed && (util.CompareVersions(version, "2020.8.0") < 0) {
-		return fmt.Errorf("--postgres-init-post-command is not supported in Black Duck versions before 2020.8.0")
-	}
-
-	if flagset.Lookup("redis-tls-enabled").Changed && (util.CompareVersions(version, "2020.8.0") < 0) {
-		return fmt.Errorf("--redis-tls-enabled is not supported in Black Duck versions before 2020.8.0")
-	}
-
-	if (flagset.Lookup("redis-max-total").Changed || flagset.Lookup("redis-max-idle").Changed) && (util.CompareVersions(version, "2020.10.0") < 0) {
-		return fmt.Errorf("--redis-max-total or --redis-max-idle is not supported in Black Duck versions before 2020.10.0")
-	}
-
-	if (flagset.Lookup("proxy-password-file-path").Changed || flagset.Lookup("ldap-password-file-path").Changed) && (util.CompareVersions(version, "2020.12.0") < 0) {
-		return fmt.Errorf("--proxy-password-file-path or --ldap-password-file-path is not supported in Black Duck versions before 2020.12.0")
-	}
-	return nil
+	return blackDuckFlags.VerifyVersion(flagset, version)
 }
 
 // FlagWasSet returns true if a flag was changed and it exists, otherwise it returns false
@@ -340,29 +382,37 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 	if err != nil {
 		return nil, err
 	}
+	// the security context file replaces the security context of its pods, so set the flags of all pods first
+	setSecurityContextFromFlags(ctl.args, flagset, &ctl.flagTree)
+
 	foundErrors := false
 	flagset.VisitAll(func(f *pflag.Flag) {
 		if f.Changed {
 			log.Debugf("flag '%s': CHANGED", f.Name)
 			switch f.Name {
-			case "version":
-				util.SetHelmValueInMap(ctl.args, []string{"imageTag"}, ctl.flagTree.Version)
 			case "size":
 				util.SetHelmValueInMap(ctl.args, []string{"size"}, strings.ToLower(ctl.flagTree.Size))
 			case "expose-ui":
 				util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, true)
-				switch ctl.flagTree.ExposeService {
+				util.DisableIngressInHelmValues(ctl.args)
+				switch strings.ToUpper(ctl.flagTree.ExposeService) {
 				case util.NODEPORT:
 					util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "NodePort")
 				case util.LOADBALANCER:
 					util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "LoadBalancer")
 				case util.OPENSHIFT:
 					util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "OpenShift")
+				case util.INGRESS:
+					util.EnableIngressInHelmValues(ctl.args)
 				default:
 					util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, false)
 				}
-			case "node-port":
-				util.SetHelmValueInMap(ctl.args, []string{"exposedNodePort"}, ctl.flagTree.ExposedNodePort)
+			case "ingress-class":
+				util.SetIngressHelmValue(ctl.args, "className", ctl.flagTree.IngressClass)
+			case "ingress-host":
+				util.SetIngressHelmValue(ctl.args, "host", ctl.flagTree.IngressHost)
+			case "ingress-tls-secret":
+				util.SetIngressHelmValue(ctl.args, "tlsSecretName", ctl.flagTree.IngressTLSSecret)
 			case "environs":
 				for _, value := range ctl.flagTree.Environs {
 					values := strings.SplitN(value, ":", 2)
@@ -371,34 +421,20 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 					}
 					util.SetHelmValueInMap(ctl.args, []string{"environs", values[0]}, values[1])
 				}
-			case "enable-binary-analysis":
-				util.SetHelmValueInMap(ctl.args, []string{"enableBinaryScanner"}, ctl.flagTree.EnableBinaryAnalysis)
-			case "enable-source-code-upload":
-				util.SetHelmValueInMap(ctl.args, []string{"enableSourceCodeUpload"}, ctl.flagTree.EnableSourceCodeUpload)
-			case "external-postgres-host":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "host"}, ctl.flagTree.ExternalPostgresHost)
-			case "external-postgres-port":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "port"}, ctl.flagTree.ExternalPostgresPort)
-			case "external-postgres-admin":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "adminUserName"}, ctl.flagTree.ExternalPostgresAdmin)
-			case "external-postgres-user":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "userUserName"}, ctl.flagTree.ExternalPostgresUser)
-			case "external-postgres-ssl":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "ssl"}, strings.ToUpper(ctl.flagTree.ExternalPostgresSsl) == "TRUE")
-			case "external-postgres-admin-password":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "adminPassword"}, ctl.flagTree.ExternalPostgresAdminPassword)
-			case "external-postgres-user-password":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "userPassword"}, ctl.flagTree.ExternalPostgresUserPassword)
-			case "postgres-init-post-command":
-				util.SetHelmValueInMap(ctl.args, []string{"init", "postCommand"}, ctl.flagTree.PostgresInitPostCommand)
-			case "pvc-storage-class":
-				util.SetHelmValueInMap(ctl.args, []string{"storageClass"}, ctl.flagTree.PvcStorageClass)
-			case "liveness-probes":
-				util.SetHelmValueInMap(ctl.args, []string{"enableLivenessProbe"}, strings.ToUpper(ctl.flagTree.LivenessProbes) == "TRUE")
-			case "enable-init-container":
-				util.SetHelmValueInMap(ctl.args, []string{"enableInitContainer"}, strings.ToUpper(ctl.flagTree.EnableInitContainer) == "TRUE")
-			case "persistent-storage":
-				util.SetHelmValueInMap(ctl.args, []string{"enablePersistentStorage"}, strings.ToUpper(ctl.flagTree.PersistentStorage) == "TRUE")
+			case "secret-environs-file":
+				environs, err := util.ReadSecretEnvironsFile(ctl.flagTree.SecretEnvironsFilePath)
+				if err != nil {
+					log.Errorf("failed to read secret environs: %+v", err)
+					foundErrors = true
+					return
+				}
+				if err := util.SetSecretEnvironsInHelmValues(ctl.args, environs); err != nil {
+					log.Errorf("failed to set secret environs: %+v", err)
+					foundErrors = true
+					return
+				}
+			case "wait-for-db-init":
+				util.SetWaitForDBInitInHelmValues(ctl.args, ctl.flagTree.WaitForDBInit, globals.DefaultPostgresClientImage)
 			case "pvc-file-path":
 				data, err := util.ReadFileData(ctl.flagTree.PVCFilePath)
 				if err != nil {
@@ -471,25 +507,6 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 					log.Errorf("failed to unmarshal security contexts: %+v", err)
 					return
 				}
-				securityContextIDNameToHelmPath := map[string][]string{
-					"blackduck-postgres":       {"postgres", "podSecurityContext"},
-					"blackduck-init":           {"init", "securityContext"},
-					"blackduck-authentication": {"authentication", "podSecurityContext"},
-					"blackduck-binnaryscanner": {"binaryscanner", "podSecurityContext"},
-					"blackduck-cfssl":          {"cfssl", "podSecurityContext"},
-					"blackduck-documentation":  {"documentation", "podSecurityContext"},
-					"blackduck-jobrunner":      {"jobrunner", "podSecurityContext"},
-					"blackduck-rabbitmq":       {"rabbitmq", "podSecurityContext"},
-					"blackduck-registration":   {"registration", "podSecurityContext"},
-					"blackduck-scan":           {"scan", "podSecurityContext"},
-					"blackduck-uploadcache":    {"uploadcache", "podSecurityContext"},
-					"blackduck-webapp":         {"webapp", "podSecurityContext"},
-					"blackduck-logstash":       {"logstash", "securityContext"},
-					"blackduck-nginx":          {"webserver", "podSecurityContext"},
-					"appcheck-worker":          {"binaryscanner", "podSecurityContext"},
-					"blackduck-redis":          {"redis", "podSecurityContext"},
-					"blackduck-bomengine":      {"bomengine", "podSecurityContext"},
-				}
 				for k, v := range securityContexts {
 					pathToHelmValue := []string{k, "podSecurityContext"}                  // default path for new pods
 					if newPathToHelmValue, ok := securityContextIDNameToHelmPath[k]; ok { // Override the security if it's present in the list
@@ -497,8 +514,18 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 					}
 					util.SetHelmValueInMap(ctl.args, pathToHelmValue, CorePodSecurityContextToHelm(v))
 				}
-			case "postgres-claim-size":
-				util.SetHelmValueInMap(ctl.args, []string{"postgres", "claimSize"}, ctl.flagTree.PostgresClaimSize)
+			case "extra-volume", "extra-volumes-file-path":
+				volumes, err := util.GetExtraVolumesFromFlags(ctl.flagTree.ExtraVolumes, ctl.flagTree.ExtraVolumesFilePath)
+				if err != nil {
+					log.Errorf("failed to read extra volumes: %+v", err)
+					foundErrors = true
+					return
+				}
+				if err := util.SetExtraVolumesInHelmValues(ctl.args, volumes); err != nil {
+					log.Errorf("failed to set extra volumes: %+v", err)
+					foundErrors = true
+					return
+				}
 			case "admin-password":
 				util.SetHelmValueInMap(ctl.args, []string{"postgres", "adminPassword"}, ctl.flagTree.AdminPassword)
 				util.SetHelmValueInMap(ctl.args, []string{"postgres", "isExternal"}, false)
@@ -513,6 +540,14 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 				if !ImageRegistryIsSet(ctl.flagTree.ImageRegistries, "bdba-worker") {
 					util.SetHelmValueInMap(ctl.args, []string{"binaryscanner", "registry"}, ctl.flagTree.Registry)
 				}
+			case "image-pull-policy":
+				if err := util.SetImagePullPolicyInHelmValues(ctl.args, ctl.flagTree.ImagePullPolicy); err != nil {
+					log.Errorf("%+v", err)
+					foundErrors = true
+					return
+				}
+			case "pin-image-digests":
+				util.SetPinImageDigestsInHelmValues(ctl.args, ctl.flagTree.PinImageDigests)
 			case "image-registries":
 				SetBlackDuckImageRegistriesInHelmValuesMap(ctl.args, ctl.flagTree.ImageRegistries)
 			case "pull-secret-name":
@@ -521,16 +556,13 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 					pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: v})
 				}
 				util.SetHelmValueInMap(ctl.args, []string{"imagePullSecrets"}, pullSecrets)
-			case "seal-key":
-				util.SetHelmValueInMap(ctl.args, []string{"sealKey"}, ctl.flagTree.SealKey)
-			case "redis-tls-enabled":
-				util.SetHelmValueInMap(ctl.args, []string{"redis", "tlsEnabled"}, ctl.flagTree.RedisTLSEnabled)
-			case "redis-max-total":
-				util.SetHelmValueInMap(ctl.args, []string{"redis", "maxTotal"}, ctl.flagTree.RedisMaxTotalConnection)
-			case "redis-max-idle":
-				util.SetHelmValueInMap(ctl.args, []string{"redis", "maxIdle"}, ctl.flagTree.RedisMaxIdleConnection)
 			default:
-				log.Debugf("flag '%s': NOT FOUND", f.Name)
+				if found, err := blackDuckFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+					log.Errorf("%+v", err)
+					foundErrors = true
+				} else if !found {
+					log.Debugf("flag '%s': NOT FOUND", f.Name)
+				}
 			}
 		} else {
 			log.Debugf("flag '%s': UNCHANGED", f.Name)
@@ -544,6 +576,59 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 	return ctl.args, nil
 }
 
+// securityContextIDNameToHelmPath maps the pod names of the security context file to the path of their security context
+// in the Helm values
+var securityContextIDNameToHelmPath = map[string][]string{
+	"blackduck-postgres":       {"postgres", "podSecurityContext"},
+	"blackduck-init":           {"init", "securityContext"},
+	"blackduck-authentication": {"authentication", "podSecurityContext"},
+	"blackduck-binnaryscanner": {"binaryscanner", "podSecurityContext"},
+	"blackduck-cfssl":          {"cfssl", "podSecurityContext"},
+	"blackduck-documentation":  {"documentation", "podSecurityContext"},
+	"blackduck-jobrunner":      {"jobrunner", "podSecurityContext"},
+	"blackduck-rabbitmq":       {"rabbitmq", "podSecurityContext"},
+	"blackduck-registration":   {"registration", "podSecurityContext"},
+	"blackduck-scan":           {"scan", "podSecurityContext"},
+	"blackduck-uploadcache":    {"uploadcache", "podSecurityContext"},
+	"blackduck-webapp":         {"webapp", "podSecurityContext"},
+	"blackduck-logstash":       {"logstash", "securityContext"},
+	"blackduck-nginx":          {"webserver", "podSecurityContext"},
+	"appcheck-worker":          {"binaryscanner", "podSecurityContext"},
+	"blackduck-redis":          {"redis", "podSecurityContext"},
+	"blackduck-bomengine":      {"bomengine", "podSecurityContext"},
+}
+
+// setSecurityContextFromFlags sets --fs-group, --run-as-user and --supplemental-groups in the security context of every
+// pod. Container security contexts only take the user
+func setSecurityContextFromFlags(helmValues map[string]interface{}, flagset *pflag.FlagSet, flagTree *FlagTree) {
+	fields := map[string]interface{}{}
+	if FlagWasSet(flagset, "run-as-user") {
+		fields["runAsUser"] = flagTree.RunAsUser
+	}
+	if FlagWasSet(flagset, "fs-group") {
+		fields["fsGroup"] = flagTree.FsGroup
+	}
+	if FlagWasSet(flagset, "supplemental-groups") {
+		groups := make([]int64, 0, len(flagTree.SupplementalGroups))
+		for _, group := range flagTree.SupplementalGroups {
+			groups = append(groups, int64(group))
+		}
+		fields["supplementalGroups"] = groups
+	}
+	if len(fields) == 0 {
+		return
+	}
+	for _, pathToHelmValue := range securityContextIDNameToHelmPath {
+		isPodSecurityContext := pathToHelmValue[len(pathToHelmValue)-1] == "podSecurityContext"
+		for field, value := range fields {
+			if !isPodSecurityContext && field != "runAsUser" {
+				continue
+			}
+			util.SetHelmValueInMap(helmValues, append(append([]string{}, pathToHelmValue...), field), value)
+		}
+	}
+}
+
 // SetBlackDuckImageRegistriesInHelmValuesMap uses the image name to set the registry and tag
 // in the Helm Chart for each image in imageRegistries
 func SetBlackDuckImageRegistriesInHelmValuesMap(helmValues map[string]interface{}, imageRegistries []string) {
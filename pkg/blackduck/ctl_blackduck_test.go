@@ -61,6 +61,45 @@ func TestGenerateHelmFlagsFromCobraFlags(t *testing.T) {
 
 }
 
+func TestGetFlagsUnsupportedByVersion(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"redis-max-total", "redis-max-idle", "proxy-password-file-path", "ldap-password-file-path"}, GetFlagsUnsupportedByVersion("2020.8.0"))
+	assert.Equal([]string{}, GetFlagsUnsupportedByVersion("2020.12.0"))
+}
+
+func TestVerifyChartVersionSupportsChangedFlags(t *testing.T) {
+	assert := assert.New(t)
+	cobraHelper := NewHelmValuesFromCobraFlags()
+	cmd := &cobra.Command{}
+	cobraHelper.AddCobraFlagsToCommand(cmd, true)
+	flagset := cmd.Flags()
+	flagset.Lookup("redis-max-idle").Changed = true
+
+	assert.EqualError(cobraHelper.VerifyChartVersionSupportsChangedFlags(flagset, "2020.8.0"), "--redis-max-total or --redis-max-idle is not supported in Black Duck versions before 2020.10.0")
+	assert.NoError(cobraHelper.VerifyChartVersionSupportsChangedFlags(flagset, "2020.10.0"))
+}
+
+func TestSetSecurityContextFromFlags(t *testing.T) {
+	assert := assert.New(t)
+	cobraHelper := NewHelmValuesFromCobraFlags()
+	cmd := &cobra.Command{}
+	cobraHelper.AddCobraFlagsToCommand(cmd, true)
+	flagset := cmd.Flags()
+	flagset.Set("fs-group", "2000")
+	flagset.Set("run-as-user", "1001")
+	flagset.Set("supplemental-groups", "65534,5555")
+
+	setSecurityContextFromFlags(cobraHelper.args, flagset, &cobraHelper.flagTree)
+
+	assert.Equal(map[string]interface{}{
+		"fsGroup":            int64(2000),
+		"runAsUser":          int64(1001),
+		"supplementalGroups": []int64{65534, 5555},
+	}, cobraHelper.args["postgres"].(map[string]interface{})["podSecurityContext"])
+	// container security contexts only take the user
+	assert.Equal(map[string]interface{}{"runAsUser": int64(1001)}, cobraHelper.args["logstash"].(map[string]interface{})["securityContext"])
+}
+
 func TestSetCRSpecFieldByFlag(t *testing.T) {
 	assert := assert.New(t)
 
@@ -271,6 +310,38 @@ func TestSetCRSpecFieldByFlag(t *testing.T) {
 			},
 		},
 		// case
+		{
+			flagName: "reporting-postgres-host",
+			changedCtl: &HelmValuesFromCobraFlags{
+				flagTree: FlagTree{
+					ReportingPostgresHost: "replica.example.com",
+				},
+			},
+			changedArgs: map[string]interface{}{
+				"postgres": map[string]interface{}{
+					"reporting": map[string]interface{}{
+						"host": "replica.example.com",
+					},
+				},
+			},
+		},
+		// case
+		{
+			flagName: "reporting-postgres-ssl",
+			changedCtl: &HelmValuesFromCobraFlags{
+				flagTree: FlagTree{
+					ReportingPostgresSsl: "false",
+				},
+			},
+			changedArgs: map[string]interface{}{
+				"postgres": map[string]interface{}{
+					"reporting": map[string]interface{}{
+						"ssl": false,
+					},
+				},
+			},
+		},
+		// case
 		{
 			flagName: "pvc-storage-class",
 			changedCtl: &HelmValuesFromCobraFlags{
@@ -0,0 +1,170 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// ExternalPostgres is the external Postgres database server of a Black Duck instance
+type ExternalPostgres struct {
+	Host          string
+	Port          int
+	Admin         string
+	User          string
+	SSL           bool
+	AdminPassword string
+	UserPassword  string
+}
+
+// ExternalPostgresExtensions are the extensions Black Duck requires in the bds_hub database of an external Postgres
+var ExternalPostgresExtensions = []string{"pgcrypto"}
+
+// External Postgres credential keys of the secret used by the connectivity check
+const (
+	ExternalPostgresAdminPasswordKey = "HUB_POSTGRES_ADMIN_PASSWORD_FILE"
+	ExternalPostgresUserPasswordKey  = "HUB_POSTGRES_USER_PASSWORD_FILE"
+)
+
+// GetExternalPostgres returns the external Postgres of the Helm values, or nil if Black Duck uses its internal Postgres.
+// An error names the flags that are missing to connect to the external Postgres
+func GetExternalPostgres(helmValues map[string]interface{}) (*ExternalPostgres, error) {
+	if isExternal, ok := util.GetHelmValueFromMap(helmValues, []string{"postgres", "isExternal"}).(bool); ok && !isExternal {
+		return nil, nil
+	}
+	getString := func(key string) string {
+		if value := util.GetHelmValueFromMap(helmValues, []string{"postgres", key}); value != nil {
+			return strings.TrimSpace(fmt.Sprintf("%v", value))
+		}
+		return ""
+	}
+	host := getString("host")
+	if len(host) == 0 {
+		return nil, nil
+	}
+
+	postgres := &ExternalPostgres{
+		Host:          host,
+		Port:          DefaultFlagTree.ExternalPostgresPort,
+		Admin:         getString("adminUserName"),
+		User:          getString("userUserName"),
+		SSL:           getString("ssl") != "false",
+		AdminPassword: getString("adminPassword"),
+		UserPassword:  getString("userPassword"),
+	}
+	if port := getString("port"); len(port) > 0 {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return nil, util.ValidationError("--external-postgres-port must be a port number, but got '%s'", port)
+		}
+		postgres.Port = p
+	}
+	if len(postgres.User) == 0 {
+		postgres.User = DefaultFlagTree.ExternalPostgresUser
+	}
+
+	missing := []string{}
+	if len(postgres.Admin) == 0 {
+		missing = append(missing, "--external-postgres-admin")
+	}
+	if len(postgres.AdminPassword) == 0 {
+		missing = append(missing, "--external-postgres-admin-password")
+	}
+	if len(postgres.UserPassword) == 0 {
+		missing = append(missing, "--external-postgres-user-password")
+	}
+	if len(missing) > 0 {
+		return nil, util.ValidationError("external Postgres '%s' requires %s", host, strings.Join(missing, ", "))
+	}
+	return postgres, nil
+}
+
+// SSLMode returns the libpq sslmode of the connections to the external Postgres
+func (p *ExternalPostgres) SSLMode() string {
+	if p.SSL {
+		return "require"
+	}
+	return "disable"
+}
+
+// ExternalPostgresCheckScript connects to the external Postgres as the admin and the user, and prints a line
+// 'key=value' for each check. Libpq reads the host, port and sslmode of the environment, the credentials are in
+// ADMIN_USER, ADMIN_PASSWORD, USER_USER and USER_PASSWORD
+const ExternalPostgresCheckScript = `
+admin() { PGUSER="$ADMIN_USER" PGPASSWORD="$ADMIN_PASSWORD" psql -tA -v ON_ERROR_STOP=1 "$@"; }
+if ! version=$(admin -d postgres -c 'SHOW server_version_num' 2>&1); then echo "admin-connection=$version" | tr '\n' ' '; echo; exit 0; fi
+echo "admin-connection=ok"
+echo "server-version=$version"
+for database in $DATABASES; do
+  if [ "$(admin -d postgres -c "SELECT 1 FROM pg_database WHERE datname = '$database'")" = "1" ]; then echo "database-$database=ok"; else echo "database-$database=missing"; fi
+done
+for extension in $EXTENSIONS; do
+  if [ "$(admin -d postgres -c "SELECT 1 FROM pg_available_extensions WHERE name = '$extension'")" = "1" ]; then echo "extension-$extension=ok"; else echo "extension-$extension=missing"; fi
+done
+if output=$(PGUSER="$USER_USER" PGPASSWORD="$USER_PASSWORD" psql -tA -d bds_hub -c 'SELECT 1' 2>&1); then echo "user-connection=ok"; else echo "user-connection=$output" | tr '\n' ' '; echo; fi
+`
+
+// externalPostgresMinimumVersion is the oldest Postgres server version Black Duck supports, as server_version_num
+const externalPostgresMinimumVersion = 90600
+
+// ParseExternalPostgresCheck returns the problems reported by the output of ExternalPostgresCheckScript with the
+// action that fixes each of them
+func ParseExternalPostgresCheck(postgres *ExternalPostgres, output string) []string {
+	results := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if parts := strings.SplitN(strings.TrimSpace(line), "=", 2); len(parts) == 2 {
+			results[parts[0]] = strings.TrimSpace(parts[1])
+		}
+	}
+	address := fmt.Sprintf("%s:%d", postgres.Host, postgres.Port)
+
+	if result, ok := results["admin-connection"]; !ok || result != "ok" {
+		if !ok {
+			result = "no output"
+		}
+		return []string{fmt.Sprintf("user '%s' can't connect to Postgres '%s' (%s), check the network access from the cluster, --external-postgres-admin-password and --external-postgres-ssl", postgres.Admin, address, result)}
+	}
+
+	problems := []string{}
+	if version, err := strconv.Atoi(results["server-version"]); err == nil && version < externalPostgresMinimumVersion {
+		problems = append(problems, fmt.Sprintf("Postgres '%s' has version %d, Black Duck requires 9.6 or later", address, version))
+	}
+	for _, database := range bdutil.BlackDuckDatabases {
+		if results["database-"+database] != "ok" {
+			problems = append(problems, fmt.Sprintf("database '%s' doesn't exist in Postgres '%s', run the Black Duck external database init script first", database, address))
+		}
+	}
+	for _, extension := range ExternalPostgresExtensions {
+		if results["extension-"+extension] != "ok" {
+			problems = append(problems, fmt.Sprintf("extension '%s' isn't available in Postgres '%s', install the Postgres contrib package or enable it in the settings of the managed database", extension, address))
+		}
+	}
+	if result := results["user-connection"]; result != "ok" {
+		problems = append(problems, fmt.Sprintf("user '%s' can't connect to database 'bds_hub' of Postgres '%s' (%s), check --external-postgres-user and --external-postgres-user-password", postgres.User, address, result))
+	}
+	return problems
+}
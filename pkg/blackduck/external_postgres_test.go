@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExternalPostgres(t *testing.T) {
+	assert := assert.New(t)
+
+	postgres, err := GetExternalPostgres(map[string]interface{}{})
+	assert.NoError(err)
+	assert.Nil(postgres)
+
+	postgres, err = GetExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{"host": "db.example.com", "isExternal": false}})
+	assert.NoError(err)
+	assert.Nil(postgres)
+
+	_, err = GetExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{"host": "db.example.com", "adminUserName": "blackduck"}})
+	assert.EqualError(err, "external Postgres 'db.example.com' requires --external-postgres-admin-password, --external-postgres-user-password")
+
+	_, err = GetExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{"host": "db.example.com", "port": "abc"}})
+	assert.Error(err)
+
+	postgres, err = GetExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{
+		"host":          "db.example.com",
+		"port":          5433,
+		"adminUserName": "blackduck",
+		"adminPassword": "admin",
+		"userPassword":  "user",
+		"ssl":           false,
+	}})
+	assert.NoError(err)
+	assert.Equal(&ExternalPostgres{Host: "db.example.com", Port: 5433, Admin: "blackduck", User: "blackduck_user", AdminPassword: "admin", UserPassword: "user"}, postgres)
+	assert.Equal("disable", postgres.SSLMode())
+}
+
+func TestParseExternalPostgresCheck(t *testing.T) {
+	assert := assert.New(t)
+	postgres := &ExternalPostgres{Host: "db.example.com", Port: 5432, Admin: "blackduck", User: "blackduck_user"}
+
+	problems := ParseExternalPostgresCheck(postgres, "admin-connection=psql: FATAL: password authentication failed for user \"blackduck\"\n")
+	assert.Len(problems, 1)
+	assert.Contains(problems[0], "password authentication failed")
+
+	assert.Len(ParseExternalPostgresCheck(postgres, ""), 1)
+
+	output := "admin-connection=ok\nserver-version=110005\ndatabase-bds_hub=ok\ndatabase-bds_hub_report=ok\ndatabase-bdio=ok\nextension-pgcrypto=ok\nuser-connection=ok\n"
+	assert.Empty(ParseExternalPostgresCheck(postgres, output))
+
+	output = "admin-connection=ok\nserver-version=90500\ndatabase-bds_hub=ok\ndatabase-bds_hub_report=missing\ndatabase-bdio=ok\nextension-pgcrypto=missing\nuser-connection=ok\n"
+	problems = ParseExternalPostgresCheck(postgres, output)
+	assert.Len(problems, 3)
+	assert.Contains(problems[0], "version 90500")
+	assert.Contains(problems[1], "'bds_hub_report'")
+	assert.Contains(problems[2], "'pgcrypto'")
+}
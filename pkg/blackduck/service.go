@@ -27,12 +27,28 @@ import (
 
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
+// GetExposure returns the exposed webserver service, route and ingress of a Black Duck instance
+func GetExposure(namespace string, name string) util.Exposure {
+	return util.Exposure{
+		App:         util.BlackDuckName,
+		Namespace:   namespace,
+		Name:        name,
+		ServiceName: util.GetResourceName(name, util.BlackDuckName, "webserver-exposed"),
+		RouteName:   util.GetResourceName(name, util.BlackDuckName, ""),
+		IngressName: util.GetResourceName(name, util.BlackDuckName, ""),
+	}
+}
+
 // CRUDServiceOrRoute will create or update Black Duck exposed service or route in case of OpenShift
 func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, name string, isExposedUI interface{}, exposedServiceType interface{}, isChanged bool) error {
+	if err := util.ReconcileExposure(restConfig, kubeClient, GetExposure(namespace, name)); err != nil {
+		return err
+	}
 	serviceName := util.GetResourceName(name, util.BlackDuckName, "webserver-exposed")
 	routeName := util.GetResourceName(name, util.BlackDuckName, "")
 	isOpenShift := util.IsOpenshift(kubeClient)
@@ -109,3 +125,19 @@ func crudExposedService(restConfig *rest.Config, kubeClient *kubernetes.Clientse
 	}
 	return nil
 }
+
+// GetIngress returns the ingress of the Black Duck webserver's user interface, or nil if the Helm values don't expose it
+// with an ingress
+func GetIngress(namespace string, name string, helmValues map[string]interface{}) (*unstructured.Unstructured, error) {
+	return util.NewExposureIngress(GetExposure(namespace, name), helmValues, util.GetResourceName(name, util.BlackDuckName, "webserver"), 443)
+}
+
+// CRUDIngress creates, updates or deletes the ingress of the Black Duck webserver's user interface based on the Helm
+// values of the instance
+func CRUDIngress(restConfig *rest.Config, namespace string, name string, helmValues map[string]interface{}) error {
+	ingress, err := GetIngress(namespace, name, helmValues)
+	if err != nil {
+		return err
+	}
+	return util.ReconcileExposureIngress(restConfig, GetExposure(namespace, name), ingress)
+}
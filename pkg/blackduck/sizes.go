@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// builtInSizes are the sizes whose resources are defined by a <size>.yaml file in the Black Duck chart
+var builtInSizes = []string{"small", "medium", "large", "x-large"}
+
+// IsBuiltInSize returns true if the resources of the size are defined by the Black Duck chart
+func IsBuiltInSize(size string) bool {
+	for _, builtInSize := range builtInSizes {
+		if strings.EqualFold(size, builtInSize) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBuiltInSizes returns the sizes whose resources are defined by the Black Duck chart
+func GetBuiltInSizes() []string {
+	return append([]string{}, builtInSizes...)
+}
+
+// SizeRegistry contains custom sizes, i.e. named sets of Helm values that tune the resources of the
+// Black Duck components without changing the chart
+type SizeRegistry struct {
+	sizes map[string]map[string]interface{}
+}
+
+// NewSizeRegistry returns an empty SizeRegistry
+func NewSizeRegistry() *SizeRegistry {
+	return &SizeRegistry{sizes: map[string]map[string]interface{}{}}
+}
+
+// AddSizesFromFile adds the sizes of a YAML or JSON file that maps the name of each size to its Helm values
+func (r *SizeRegistry) AddSizesFromFile(filePath string) error {
+	data, err := util.ReadFileData(filePath)
+	if err != nil {
+		return err
+	}
+	sizes := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(data), &sizes); err != nil {
+		return fmt.Errorf("failed to parse the sizes in file '%s' due to %+v", filePath, err)
+	}
+	for name, values := range sizes {
+		if err := r.AddSize(name, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSizesFromConfigMap adds a size for each <size>.yaml key of the ConfigMap, the same layout as the sizes in the chart
+func (r *SizeRegistry) AddSizesFromConfigMap(cm *corev1.ConfigMap) error {
+	for key, data := range cm.Data {
+		if !strings.HasSuffix(key, ".yaml") {
+			continue
+		}
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(data), &values); err != nil {
+			return fmt.Errorf("failed to parse the size '%s' in config map '%s' due to %+v", key, cm.Name, err)
+		}
+		if err := r.AddSize(strings.TrimSuffix(key, ".yaml"), values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSize adds a custom size, the built-in sizes can't be redefined
+func (r *SizeRegistry) AddSize(name string, values map[string]interface{}) error {
+	if len(name) == 0 {
+		return fmt.Errorf("the name of a size can't be empty")
+	}
+	if IsBuiltInSize(name) {
+		return fmt.Errorf("size '%s' is defined by the Black Duck chart and can't be redefined", name)
+	}
+	r.sizes[strings.ToLower(name)] = values
+	return nil
+}
+
+// GetSize returns the Helm values of a custom size
+func (r *SizeRegistry) GetSize(name string) (map[string]interface{}, bool) {
+	values, ok := r.sizes[strings.ToLower(name)]
+	return values, ok
+}
+
+// GetSizeNames returns the names of the custom sizes
+func (r *SizeRegistry) GetSizeNames() []string {
+	names := []string{}
+	for name := range r.sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsBuiltInSize(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsBuiltInSize("X-Large"))
+	assert.False(IsBuiltInSize("tuned"))
+	assert.False(IsBuiltInSize(""))
+}
+
+func TestSizeRegistry(t *testing.T) {
+	assert := assert.New(t)
+	sizes := NewSizeRegistry()
+
+	err := sizes.AddSizesFromConfigMap(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "sizes"},
+		Data: map[string]string{
+			"tuned.yaml": "scan:\n  replicas: 4\n",
+			"README":     "ignored",
+		},
+	})
+	assert.NoError(err)
+	values, ok := sizes.GetSize("Tuned")
+	assert.True(ok)
+	assert.Equal(map[string]interface{}{"scan": map[string]interface{}{"replicas": float64(4)}}, values)
+	assert.Equal([]string{"tuned"}, sizes.GetSizeNames())
+
+	assert.Error(sizes.AddSize("small", map[string]interface{}{}))
+	_, ok = sizes.GetSize("small")
+	assert.False(ok)
+}
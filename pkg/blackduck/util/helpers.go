@@ -23,14 +23,17 @@ package util
 
 import (
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	blackduckv1 "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
 	blackduckclient "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -51,43 +54,33 @@ func GetHubVersion(environs []string) string {
 	return ""
 }
 
-// GetLoadBalancerIPAddress will return the load balance service ip address
+// GetLoadBalancerIPAddress will return the addresses of the load balancer service, separated by commas
 func GetLoadBalancerIPAddress(kubeClient *kubernetes.Clientset, namespace string, serviceName string) (string, error) {
 	service, err := util.GetService(kubeClient, namespace, serviceName)
 	if err != nil {
 		return "", fmt.Errorf("unable to get service %s in %s namespace because %s", serviceName, namespace, err.Error())
 	}
 
-	if len(service.Status.LoadBalancer.Ingress) > 0 {
-		ipAddress := service.Status.LoadBalancer.Ingress[0].IP
-		return ipAddress, nil
+	hosts := util.GetLoadBalancerHosts(service)
+	if len(hosts) > 0 {
+		return strings.Join(hosts, ","), nil
 	}
 
 	return "", fmt.Errorf("unable to get ip address for the service %s in %s namespace", serviceName, namespace)
 }
 
-// GetNodePortIPAddress will return the node port service ip address
+// GetNodePortIPAddress will return the node addresses and node port of the service, separated by commas
 func GetNodePortIPAddress(kubeClient *kubernetes.Clientset, namespace string, serviceName string) (string, error) {
-	// Get the node port service
 	service, err := util.GetService(kubeClient, namespace, serviceName)
 	if err != nil {
 		return "", fmt.Errorf("unable to get service %s in %s namespace because %s", serviceName, namespace, err.Error())
 	}
 
-	var nodePort []int32
-	// Get the nodeport
-	for _, port := range service.Spec.Ports {
-		nodePort = append(nodePort, port.NodePort)
-	}
-	return intArrayToStringArray(nodePort, ","), nil
-}
-
-func intArrayToStringArray(intArr []int32, delim string) string {
-	var strArr []string
-	for i := range intArr {
-		strArr = append(strArr, fmt.Sprintf("<<NODE_IP_ADDRESS>>:%+v", intArr[i]))
+	endpoints, err := util.GetServiceEndpoints(kubeClient, service)
+	if err != nil {
+		return "", err
 	}
-	return strings.Join(strArr, delim)
+	return strings.Join(endpoints, ","), nil
 }
 
 // UpdateState will be used to update the hub object
@@ -101,29 +94,83 @@ func UpdateState(h *blackduckclient.Clientset, name string, namespace string, st
 	return h.SynopsysV1().Blackducks(namespace).Patch(name, types.MergePatchType, []byte(patch))
 }
 
+// DBPasswordOptions configures how the Black Duck database passwords are retrieved
+type DBPasswordOptions struct {
+	// UserPassword and AdminPassword are used instead of the passwords in the secret if both are set
+	UserPassword  string
+	AdminPassword string
+	// Retries is the number of times the secret is fetched again while it doesn't exist
+	Retries  int
+	Interval time.Duration
+}
+
+// DefaultDBPasswordOptions waits up to 2 minutes for the secret with the database passwords
+var DefaultDBPasswordOptions = DBPasswordOptions{Retries: 12, Interval: 10 * time.Second}
+
 // GetHubDBPassword will retrieve the blackduck and blackduck_user db password
-func GetHubDBPassword(kubeClient *kubernetes.Clientset, namespace string, name string) (string, string, error) {
+func GetHubDBPassword(kubeClient kubernetes.Interface, namespace string, name string) (string, string, error) {
 	var userPw, adminPw string
 
-	secret, err := util.GetSecret(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "db-creds"))
+	secretName := util.GetResourceName(name, util.BlackDuckName, "db-creds")
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
 	if err != nil {
 		return userPw, adminPw, err
 	}
 
 	s, ok := secret.Data["HUB_POSTGRES_USER_PASSWORD_FILE"]
 	if !ok {
-		return "", "", fmt.Errorf("HUB_POSTGRES_USER_PASSWORD_FILE is missing")
+		return "", "", fmt.Errorf("HUB_POSTGRES_USER_PASSWORD_FILE is missing in secret '%s' in namespace '%s'", secretName, namespace)
 	}
 	userPw = string(s)
 
 	s, ok = secret.Data["HUB_POSTGRES_ADMIN_PASSWORD_FILE"]
 	if !ok {
-		return "", "", fmt.Errorf("HUB_POSTGRES_ADMIN_PASSWORD_FILE is missing")
+		return "", "", fmt.Errorf("HUB_POSTGRES_ADMIN_PASSWORD_FILE is missing in secret '%s' in namespace '%s'", secretName, namespace)
 	}
 	adminPw = string(s)
 	return userPw, adminPw, nil
 }
 
+// GetHubDBPasswordWithRetry will retrieve the blackduck and blackduck_user db password, the secret is fetched again
+// a bounded number of times while it doesn't exist yet. The passwords of the options are returned if they are set
+func GetHubDBPasswordWithRetry(kubeClient kubernetes.Interface, namespace string, name string, opts DBPasswordOptions) (string, string, error) {
+	if len(opts.UserPassword) > 0 && len(opts.AdminPassword) > 0 {
+		return opts.UserPassword, opts.AdminPassword, nil
+	}
+	if _, err := kubeClient.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", "", fmt.Errorf("namespace '%s' of Black Duck '%s' doesn't exist", namespace, name)
+		}
+		return "", "", fmt.Errorf("unable to get namespace '%s' due to %+v", namespace, err)
+	}
+
+	secretName := util.GetResourceName(name, util.BlackDuckName, "db-creds")
+	for attempt := 0; ; attempt++ {
+		userPw, adminPw, err := GetHubDBPassword(kubeClient, namespace, name)
+		if err == nil {
+			return userPw, adminPw, nil
+		}
+		if !k8serrors.IsNotFound(err) {
+			return "", "", fmt.Errorf("unable to get the database passwords of Black Duck '%s' due to %+v", name, err)
+		}
+		if attempt >= opts.Retries {
+			return "", "", fmt.Errorf("secret '%s' with the database passwords doesn't exist in namespace '%s' after %d attempts, provide the passwords explicitly instead", secretName, namespace, attempt+1)
+		}
+		log.Debugf("secret '%s' doesn't exist in namespace '%s' yet, retrying in %s", secretName, namespace, opts.Interval)
+		time.Sleep(opts.Interval)
+	}
+}
+
+// DBPasswordRenderLookups returns the lookups that reuse the database passwords of an existing Black Duck instance
+// while rendering its manifests
+func DBPasswordRenderLookups(name string) []util.RenderLookup {
+	secretName := util.GetResourceName(name, util.BlackDuckName, "db-creds")
+	return []util.RenderLookup{
+		{SecretName: secretName, Key: "HUB_POSTGRES_ADMIN_PASSWORD_FILE", ValuePath: []string{"postgres", "adminPassword"}},
+		{SecretName: secretName, Key: "HUB_POSTGRES_USER_PASSWORD_FILE", ValuePath: []string{"postgres", "userPassword"}},
+	}
+}
+
 // CloneJob create a Kube job to clone a postgres instance
 func CloneJob(clientset *kubernetes.Clientset, fromNamespace string, from string, toNamespace string, to string, password string) error {
 	command := fmt.Sprintf("pg_dumpall -h %s.%s.svc.cluster.local -U postgres | psql -h %s.%s.svc.cluster.local -U postgres", util.GetResourceName(from, util.BlackDuckName, "postgres"), fromNamespace, util.GetResourceName(to, util.BlackDuckName, "postgres"), toNamespace)
@@ -183,3 +230,397 @@ func CloneJob(clientset *kubernetes.Clientset, fromNamespace string, from string
 		}
 	}
 }
+
+// PostgresEndpoint is a Black Duck Postgres database server that is reachable from the cluster
+type PostgresEndpoint struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+}
+
+// BlackDuckDatabases are the databases of a Black Duck instance
+var BlackDuckDatabases = []string{"bds_hub", "bds_hub_report", "bdio"}
+
+// GetCloneDatabaseScript returns the script of the clone job, it waits for the target Postgres and pipes the dump of
+// every Black Duck database of the source into the target. The servers and credentials are read from the environs
+func GetCloneDatabaseScript() string {
+	commands := []string{
+		"until pg_isready -h \"$TARGET_HOST\" -p \"$TARGET_PORT\"; do sleep 5; done",
+	}
+	for _, database := range BlackDuckDatabases {
+		commands = append(commands, fmt.Sprintf("PGPASSWORD=\"$SOURCE_PASSWORD\" pg_dump -h \"$SOURCE_HOST\" -p \"$SOURCE_PORT\" -U \"$SOURCE_USER\" --clean --if-exists -d %s | PGPASSWORD=\"$TARGET_PASSWORD\" psql -v ON_ERROR_STOP=1 -h \"$TARGET_HOST\" -p \"$TARGET_PORT\" -U \"$TARGET_USER\" -d %s", database, database))
+	}
+	return "set -o pipefail -e; " + strings.Join(commands, "; ")
+}
+
+// CloneDatabaseJob creates a Kube job that dumps the Black Duck databases of the source Postgres and restores them
+// into the target Postgres, and waits for it to complete
+func CloneDatabaseJob(clientset *kubernetes.Clientset, namespace string, name string, image string, source PostgresEndpoint, target PostgresEndpoint, timeout time.Duration) error {
+	backoffLimit := int32(0)
+
+	cloneJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   util.GetResourceName(name, util.BlackDuckName, "clone-job"),
+			Labels: map[string]string{"app": util.BlackDuckName, "name": name, "component": "clone-job"},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "clone",
+							Image:   image,
+							Command: []string{"/bin/bash"},
+							Args:    []string{"-c", GetCloneDatabaseScript()},
+							Env: []corev1.EnvVar{
+								{Name: "SOURCE_HOST", Value: source.Host},
+								{Name: "SOURCE_PORT", Value: fmt.Sprintf("%d", source.Port)},
+								{Name: "SOURCE_USER", Value: source.User},
+								{Name: "SOURCE_PASSWORD", Value: source.Password},
+								{Name: "TARGET_HOST", Value: target.Host},
+								{Name: "TARGET_PORT", Value: fmt.Sprintf("%d", target.Port)},
+								{Name: "TARGET_USER", Value: target.User},
+								{Name: "TARGET_PASSWORD", Value: target.Password},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Create(cloneJob)
+	if err != nil {
+		return fmt.Errorf("unable to create the clone job in namespace '%s' due to %+v", namespace, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	ticker := time.NewTicker(10 * time.Second)
+	defer timer.Stop()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("the clone job '%s' in namespace '%s' didn't complete within %s", job.Name, namespace, timeout))
+		case <-ticker.C:
+			job, err = clientset.BatchV1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to get the clone job '%s' in namespace '%s' due to %+v", cloneJob.Name, namespace, err)
+			}
+			if job.Status.Succeeded > 0 {
+				return nil
+			}
+			if job.Status.Failed > 0 {
+				return fmt.Errorf("the clone job '%s' in namespace '%s' failed, see the logs of its pod for details", job.Name, namespace)
+			}
+		}
+	}
+}
+
+// PostUpgradeCheckJobComponent is the component label of the job running the post-upgrade checks
+const PostUpgradeCheckJobComponent = "post-upgrade-check"
+
+// NewPostUpgradeCheckJob returns the Kube job that runs the SQL checks against a Black Duck database in read-only
+// transactions. Any error of the SQL, e.g. a RAISE EXCEPTION or a division by zero of a failed assertion, fails the job
+func NewPostUpgradeCheckJob(name string, image string, endpoint PostgresEndpoint, database string, sql string) *batchv1.Job {
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   util.GetResourceName(name, util.BlackDuckName, PostUpgradeCheckJobComponent),
+			Labels: map[string]string{"app": util.BlackDuckName, "name": name, "component": PostUpgradeCheckJobComponent},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "check",
+							Image:   image,
+							Command: []string{"/bin/bash"},
+							Args:    []string{"-c", "set -e; until pg_isready -h \"$PGHOST\" -p \"$PGPORT\"; do sleep 5; done; printf '%s\\n' \"$CHECK_SQL\" | psql -v ON_ERROR_STOP=1 -d \"$PGDATABASE\""},
+							Env: []corev1.EnvVar{
+								{Name: "PGHOST", Value: endpoint.Host},
+								{Name: "PGPORT", Value: fmt.Sprintf("%d", endpoint.Port)},
+								{Name: "PGUSER", Value: endpoint.User},
+								{Name: "PGPASSWORD", Value: endpoint.Password},
+								{Name: "PGDATABASE", Value: database},
+								// every transaction of the session is read-only, the checks can't modify the database
+								{Name: "PGOPTIONS", Value: "-c default_transaction_read_only=on"},
+								{Name: "CHECK_SQL", Value: sql},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
+
+// PostUpgradeCheckJob replaces the job of a previous check with the job of NewPostUpgradeCheckJob, and waits for it
+// to complete
+func PostUpgradeCheckJob(clientset kubernetes.Interface, namespace string, name string, image string, endpoint PostgresEndpoint, database string, sql string, timeout time.Duration) error {
+	checkJob := NewPostUpgradeCheckJob(name, image, endpoint, database, sql)
+	propagation := metav1.DeletePropagationBackground
+
+	// remove the job of a previous check
+	if err := clientset.BatchV1().Jobs(namespace).Delete(checkJob.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete the previous post-upgrade check job '%s' in namespace '%s' due to %+v", checkJob.Name, namespace, err)
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Create(checkJob)
+	if err != nil {
+		return fmt.Errorf("unable to create the post-upgrade check job in namespace '%s' due to %+v", namespace, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	ticker := time.NewTicker(5 * time.Second)
+	defer timer.Stop()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("the post-upgrade check job '%s' in namespace '%s' didn't complete within %s", job.Name, namespace, timeout))
+		case <-ticker.C:
+			job, err = clientset.BatchV1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to get the post-upgrade check job '%s' in namespace '%s' due to %+v", checkJob.Name, namespace, err)
+			}
+			if job.Status.Succeeded > 0 {
+				return nil
+			}
+			if job.Status.Failed > 0 {
+				return fmt.Errorf("the post-upgrade check job '%s' in namespace '%s' failed, see the logs of its pod for the failed check", job.Name, namespace)
+			}
+		}
+	}
+}
+
+// BackupJobComponent is the component label of the job dumping the databases of a backup
+const BackupJobComponent = "backup"
+
+// Paths of the backup job. The job creates BackupCompleteFile when all databases are dumped, and waits for
+// BackupCollectedFile if the dumps are copied out of the job
+const (
+	BackupMountPath     = "/backup"
+	BackupCompleteFile  = ".complete"
+	BackupCollectedFile = ".collected"
+)
+
+// BackupDatabaseJob creates a Kube job that dumps the Black Duck databases in the custom format of pg_dump into a
+// directory of the claim, or of an empty dir if claim is empty, and waits until the dumps are complete. Without a
+// claim the job keeps running until BackupCollectedFile is created or the timeout expires, its pod is returned to copy
+// the dumps from. With a claim and a positive retention, the job deletes the backups of the instance in the claim that
+// are older than the newest retention backups
+func BackupDatabaseJob(clientset *kubernetes.Clientset, namespace string, name string, image string, endpoint PostgresEndpoint, claim string, directory string, retention int, timeout time.Duration) (*corev1.Pod, error) {
+	jobName := util.GetResourceName(name, util.BlackDuckName, BackupJobComponent)
+	backoffLimit := int32(0)
+	deadline := int64(timeout.Seconds())
+	propagation := metav1.DeletePropagationBackground
+
+	// remove the job of a previous backup
+	if err := clientset.BatchV1().Jobs(namespace).Delete(jobName, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to delete the previous backup job '%s' in namespace '%s' due to %+v", jobName, namespace, err)
+	}
+
+	backupDir := BackupMountPath
+	volume := corev1.Volume{Name: "backup", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	if len(claim) > 0 {
+		backupDir = path.Join(BackupMountPath, directory)
+		volume.VolumeSource = corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}}
+	}
+	commands := []string{
+		"mkdir -p \"$BACKUP_DIR\"",
+		"for database in $DATABASES; do echo \"dumping $database\"; pg_dump -Fc -d \"$database\" -f \"$BACKUP_DIR/$database.dump\"; done",
+		fmt.Sprintf("touch \"$BACKUP_DIR/%s\"", BackupCompleteFile),
+	}
+	if len(claim) == 0 {
+		commands = append(commands, fmt.Sprintf("until [ -f \"$BACKUP_DIR/%s\" ]; do sleep 2; done", BackupCollectedFile))
+	} else if retention > 0 {
+		// the pattern is expanded unquoted, the timestamps of the names sort chronologically
+		pattern := path.Join(BackupMountPath, util.GetBackupNamePattern(util.BlackDuckName, name))
+		commands = append(commands, fmt.Sprintf("ls -1d %s 2>/dev/null | sort | head -n -%d | xargs -r rm -rf", pattern, retention))
+	}
+
+	backupJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   jobName,
+			Labels: map[string]string{"app": util.BlackDuckName, "name": name, "component": BackupJobComponent},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &deadline,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": util.BlackDuckName, "name": name, "component": BackupJobComponent},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "backup",
+							Image:   image,
+							Command: []string{"/bin/bash"},
+							Args:    []string{"-c", "set -e; until pg_isready; do sleep 5; done; " + strings.Join(commands, "; ")},
+							Env: []corev1.EnvVar{
+								{Name: "PGHOST", Value: endpoint.Host},
+								{Name: "PGPORT", Value: fmt.Sprintf("%d", endpoint.Port)},
+								{Name: "PGUSER", Value: endpoint.User},
+								{Name: "PGPASSWORD", Value: endpoint.Password},
+								{Name: "DATABASES", Value: strings.Join(BlackDuckDatabases, " ")},
+								{Name: "BACKUP_DIR", Value: backupDir},
+							},
+							VolumeMounts: []corev1.VolumeMount{{Name: "backup", MountPath: BackupMountPath}},
+							// the pod is ready when the dumps are complete
+							ReadinessProbe: &corev1.Probe{
+								Handler:       corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"test", "-f", path.Join(backupDir, BackupCompleteFile)}}},
+								PeriodSeconds: 5,
+							},
+						},
+					},
+					Volumes:       []corev1.Volume{volume},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Create(backupJob)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the backup job in namespace '%s' due to %+v", namespace, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	ticker := time.NewTicker(5 * time.Second)
+	defer timer.Stop()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return nil, util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("the backup job '%s' in namespace '%s' didn't complete within %s", job.Name, namespace, timeout))
+		case <-ticker.C:
+			job, err = clientset.BatchV1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the backup job '%s' in namespace '%s' due to %+v", backupJob.Name, namespace, err)
+			}
+			if job.Status.Failed > 0 {
+				return nil, fmt.Errorf("the backup job '%s' in namespace '%s' failed, see the logs of its pod for details", job.Name, namespace)
+			}
+			if job.Status.Succeeded > 0 {
+				return nil, nil
+			}
+			if len(claim) > 0 {
+				continue
+			}
+			pods, err := util.ListPodsWithLabels(clientset, namespace, fmt.Sprintf("job-name=%s", job.Name))
+			if err != nil {
+				return nil, fmt.Errorf("unable to list the pods of the backup job '%s' in namespace '%s' due to %+v", job.Name, namespace, err)
+			}
+			for i := range pods.Items {
+				for _, condition := range pods.Items[i].Status.Conditions {
+					if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+						return &pods.Items[i], nil
+					}
+				}
+			}
+		}
+	}
+}
+
+// RestoreJobComponent is the component label of the job restoring the databases of a backup
+const RestoreJobComponent = "restore"
+
+// Lines printed by the restore job when it fails before restoring a database, with the details after '='
+const (
+	RestoreMissingDumpOutput = "missing-dump"
+	RestoreNotEmptyOutput    = "not-empty"
+	// RestoredOutput is printed with the name of the database after it is restored
+	RestoredOutput = "restored"
+)
+
+// restoreDatabaseScript waits for the dumps, refuses to overwrite a database with tables unless FORCE is true, restores
+// every database and analyzes it, as the statistics of the planner aren't part of the dumps
+const restoreDatabaseScript = `set -e
+until pg_isready; do sleep 5; done
+until [ -f "$BACKUP_DIR/%[1]s" ]; do sleep 2; done
+for database in $DATABASES; do
+  if [ ! -f "$BACKUP_DIR/$database.dump" ]; then echo "%[2]s=$database"; exit 2; fi
+done
+if [ "$FORCE" != "true" ]; then
+  tables=$(psql -tA -d bds_hub -c "SELECT count(*) FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema')")
+  if [ "$tables" != "0" ]; then echo "%[3]s=$tables"; exit 3; fi
+fi
+for database in $DATABASES; do
+  echo "restoring $database"
+  pg_restore --clean --if-exists --exit-on-error -d "$database" "$BACKUP_DIR/$database.dump"
+  psql -v ON_ERROR_STOP=1 -d "$database" -c 'ANALYZE'
+  echo "%[4]s $database"
+done`
+
+// CreateRestoreDatabaseJob creates a Kube job that restores the dumps of a backup into the Black Duck databases. The
+// dumps are read from the directory of the claim, or from an empty dir if claim is empty, that the caller copies the
+// dumps into. The job starts restoring when BackupCompleteFile exists in the directory of the dumps
+func CreateRestoreDatabaseJob(clientset *kubernetes.Clientset, namespace string, name string, image string, endpoint PostgresEndpoint, claim string, directory string, force bool, timeout time.Duration) (*batchv1.Job, error) {
+	jobName := util.GetResourceName(name, util.BlackDuckName, RestoreJobComponent)
+	backoffLimit := int32(0)
+	deadline := int64(timeout.Seconds())
+	propagation := metav1.DeletePropagationBackground
+
+	// remove the job of a previous restore
+	if err := clientset.BatchV1().Jobs(namespace).Delete(jobName, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to delete the previous restore job '%s' in namespace '%s' due to %+v", jobName, namespace, err)
+	}
+
+	backupDir := BackupMountPath
+	volume := corev1.Volume{Name: "backup", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	if len(claim) > 0 {
+		backupDir = path.Join(BackupMountPath, directory)
+		volume.VolumeSource = corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim, ReadOnly: true}}
+	}
+	labels := map[string]string{"app": util.BlackDuckName, "name": name, "component": RestoreJobComponent}
+
+	restoreJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: jobName, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &deadline,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "restore",
+							Image:   image,
+							Command: []string{"/bin/bash"},
+							Args:    []string{"-c", fmt.Sprintf(restoreDatabaseScript, BackupCompleteFile, RestoreMissingDumpOutput, RestoreNotEmptyOutput, RestoredOutput)},
+							Env: []corev1.EnvVar{
+								{Name: "PGHOST", Value: endpoint.Host},
+								{Name: "PGPORT", Value: fmt.Sprintf("%d", endpoint.Port)},
+								{Name: "PGUSER", Value: endpoint.User},
+								{Name: "PGPASSWORD", Value: endpoint.Password},
+								{Name: "DATABASES", Value: strings.Join(BlackDuckDatabases, " ")},
+								{Name: "BACKUP_DIR", Value: backupDir},
+								{Name: "FORCE", Value: fmt.Sprintf("%t", force)},
+							},
+							VolumeMounts: []corev1.VolumeMount{{Name: "backup", MountPath: BackupMountPath, ReadOnly: len(claim) > 0}},
+						},
+					},
+					Volumes:       []corev1.Volume{volume},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Create(restoreJob)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the restore job in namespace '%s' due to %+v", namespace, err)
+	}
+	return job, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2018 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// countSecretGets returns the number of times the clientset fetched a secret
+func countSecretGets(kubeClient *fake.Clientset) int {
+	count := 0
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "secrets" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGetHubDBPasswordWithRetry(t *testing.T) {
+	assert := assert.New(t)
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bd-ns"}}
+	secretName := util.GetResourceName("bd", util.BlackDuckName, "db-creds")
+	opts := DBPasswordOptions{Retries: 2}
+
+	// the secret is fetched once, then again up to the number of retries
+	kubeClient := fake.NewSimpleClientset(namespace)
+	_, _, err := GetHubDBPasswordWithRetry(kubeClient, "bd-ns", "bd", opts)
+	assert.EqualError(err, "secret '"+secretName+"' with the database passwords doesn't exist in namespace 'bd-ns' after 3 attempts, provide the passwords explicitly instead")
+	assert.Equal(3, countSecretGets(kubeClient))
+
+	kubeClient = fake.NewSimpleClientset()
+	_, _, err = GetHubDBPasswordWithRetry(kubeClient, "bd-ns", "bd", opts)
+	assert.EqualError(err, "namespace 'bd-ns' of Black Duck 'bd' doesn't exist")
+	assert.Equal(0, countSecretGets(kubeClient))
+
+	kubeClient = fake.NewSimpleClientset(namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "bd-ns"},
+		Data:       map[string][]byte{"HUB_POSTGRES_USER_PASSWORD_FILE": []byte("user"), "HUB_POSTGRES_ADMIN_PASSWORD_FILE": []byte("admin")},
+	})
+	userPw, adminPw, err := GetHubDBPasswordWithRetry(kubeClient, "bd-ns", "bd", opts)
+	assert.NoError(err)
+	assert.Equal("user", userPw)
+	assert.Equal("admin", adminPw)
+	assert.Equal(1, countSecretGets(kubeClient))
+
+	// a secret without a password isn't fetched again
+	kubeClient = fake.NewSimpleClientset(namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "bd-ns"},
+		Data:       map[string][]byte{"HUB_POSTGRES_USER_PASSWORD_FILE": []byte("user")},
+	})
+	_, _, err = GetHubDBPasswordWithRetry(kubeClient, "bd-ns", "bd", opts)
+	assert.EqualError(err, "unable to get the database passwords of Black Duck 'bd' due to HUB_POSTGRES_ADMIN_PASSWORD_FILE is missing in secret '"+secretName+"' in namespace 'bd-ns'")
+	assert.Equal(1, countSecretGets(kubeClient))
+
+	// the passwords of the options are used without the secret
+	kubeClient = fake.NewSimpleClientset()
+	userPw, adminPw, err = GetHubDBPasswordWithRetry(kubeClient, "bd-ns", "bd", DBPasswordOptions{UserPassword: "u", AdminPassword: "a"})
+	assert.NoError(err)
+	assert.Equal("u", userPw)
+	assert.Equal("a", adminPw)
+	assert.Empty(kubeClient.Actions())
+}
+
+func TestGetCloneDatabaseScript(t *testing.T) {
+	assert := assert.New(t)
+
+	script := GetCloneDatabaseScript()
+	assert.True(strings.HasPrefix(script, "set -o pipefail -e; until pg_isready "))
+	for _, database := range BlackDuckDatabases {
+		assert.Contains(script, fmt.Sprintf("--clean --if-exists -d %s | ", database))
+		assert.Contains(script, fmt.Sprintf("-U \"$TARGET_USER\" -d %s", database))
+	}
+	assert.Equal(len(BlackDuckDatabases), strings.Count(script, "pg_dump "))
+	// the credentials are only read from the environs of the job
+	assert.NotContains(script, "blackduck")
+}
+
+func TestNewPostUpgradeCheckJob(t *testing.T) {
+	assert := assert.New(t)
+
+	endpoint := PostgresEndpoint{Host: "bd-blackduck-postgres.ns.svc.cluster.local", Port: 5432, User: "blackduck_user", Password: "user-password"}
+	job := NewPostUpgradeCheckJob("bd", "postgres:11", endpoint, "bds_hub", "SELECT 1;")
+
+	assert.Equal("bd-blackduck-post-upgrade-check", job.Name)
+	assert.Equal(map[string]string{"app": util.BlackDuckName, "name": "bd", "component": PostUpgradeCheckJobComponent}, job.Labels)
+	assert.Equal(int32(0), *job.Spec.BackoffLimit)
+	assert.Equal(corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Len(job.Spec.Template.Spec.Containers, 1)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal("postgres:11", container.Image)
+	assert.Equal([]corev1.EnvVar{
+		{Name: "PGHOST", Value: "bd-blackduck-postgres.ns.svc.cluster.local"},
+		{Name: "PGPORT", Value: "5432"},
+		{Name: "PGUSER", Value: "blackduck_user"},
+		{Name: "PGPASSWORD", Value: "user-password"},
+		{Name: "PGDATABASE", Value: "bds_hub"},
+		{Name: "PGOPTIONS", Value: "-c default_transaction_read_only=on"},
+		{Name: "CHECK_SQL", Value: "SELECT 1;"},
+	}, container.Env)
+	// the SQL is passed in an environ, not in the script
+	assert.NotContains(container.Args[1], "SELECT")
+	assert.Contains(container.Args[1], "psql -v ON_ERROR_STOP=1")
+}
+
+func TestPostUpgradeCheckJob(t *testing.T) {
+	assert := assert.New(t)
+
+	// the job of a previous check that failed
+	previous := NewPostUpgradeCheckJob("bd", "postgres:11", PostgresEndpoint{}, "bds_hub", "SELECT 0;")
+	previous.Namespace = "ns"
+	previous.Status.Failed = 1
+	kubeClient := fake.NewSimpleClientset(previous)
+
+	// the fake job never completes
+	err := PostUpgradeCheckJob(kubeClient, "ns", "bd", "postgres:11", PostgresEndpoint{}, "bds_hub", "SELECT 1;", 10*time.Millisecond)
+	assert.Error(err)
+	assert.Equal(util.ExitCodeTimeout, util.ExitCode(err))
+
+	job, err := kubeClient.BatchV1().Jobs("ns").Get("bd-blackduck-post-upgrade-check", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(int32(0), job.Status.Failed)
+	assert.Equal("SELECT 1;", job.Spec.Template.Spec.Containers[0].Env[6].Value)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// VerificationChecks returns the post-install checks of a Black Duck instance: the login page is reachable, the API
+// reports the version of the release and a scan container registered with the scan service
+func VerificationChecks(name string) []products.VerificationCheck {
+	webserver := util.GetResourceName(name, util.BlackDuckName, "webserver")
+	return []products.VerificationCheck{
+		products.NewPageCheck("login-page", "https", webserver, "443", ""),
+		products.NewVersionCheck("api-version", "https", webserver, "443", "api/current-version"),
+		products.NewServiceEndpointsCheck("scan-container", util.GetResourceName(name, util.BlackDuckName, "scan")),
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package coverity
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// HelmValuesFromCobraFlags is a type for converting synopsysctl flags
+// to Helm Chart fields and values
+// args: map of helm chart field to value
+type HelmValuesFromCobraFlags struct {
+	args     map[string]interface{}
+	flagTree FlagTree
+}
+
+// FlagTree is a set of fields needed to configure the Coverity Helm Chart
+type FlagTree struct {
+	Version string `json:"version"`
+
+	// Images
+	ImageRegistry   string `json:"imageRegistry"`
+	ImagePullSecret string `json:"imagePullSecret"`
+
+	// License
+	LicenseFilePath string `json:"licenseFilePath"`
+
+	// PostgreSQL
+	PostgresHost     string `json:"postgresHost"`
+	PostgresPort     int    `json:"postgresPort"`
+	PostgresUser     string `json:"postgresUser"`
+	PostgresPassword string `json:"postgresPassword"`
+	PostgresDatabase string `json:"postgresDatabase"`
+	PostgresSSLMode  string `json:"postgresSSLMode"`
+
+	// Storage
+	StorageClass string `json:"storageClass"`
+	CIMPVCSize   string `json:"cimPVCSize"`
+
+	// Coverity Connect
+	CIMReplicas   int    `json:"cimReplicas"`
+	ExposeService string `json:"exposeService"`
+
+	// Ingress
+	IngressEnabled       bool   `json:"ingressEnabled"`
+	IngressHost          string `json:"ingressHost"`
+	IngressTLSSecretName string `json:"ingressTLSSecretName"`
+
+	// Scan service
+	ScanServiceEnabled  bool `json:"scanServiceEnabled"`
+	ScanServiceReplicas int  `json:"scanServiceReplicas"`
+
+	// Networking and security
+	RootCASecret string `json:"rootCASecret"`
+	HTTPProxy    string `json:"httpProxy"`
+	HTTPNoProxy  string `json:"httpNoProxy"`
+}
+
+// DefaultFlagTree ...
+// [Dev Note]: These should match the Helm Chart's Values.yaml
+var DefaultFlagTree = FlagTree{
+	// Version
+	Version: globals.CoverityVersion,
+	// PostgreSQL
+	PostgresPort:     5432,
+	PostgresUser:     "postgres",
+	PostgresDatabase: "cim",
+	PostgresSSLMode:  "disable",
+	// Storage
+	StorageClass: "default",
+	CIMPVCSize:   "50Gi",
+	// Coverity Connect
+	CIMReplicas:   1,
+	ExposeService: util.NONE,
+	// Ingress
+	IngressEnabled: false,
+	// Scan service
+	ScanServiceEnabled:  true,
+	ScanServiceReplicas: 1,
+}
+
+// GetDefaultFlagTree ...
+func GetDefaultFlagTree() *FlagTree {
+	return &DefaultFlagTree
+}
+
+// NewHelmValuesFromCobraFlags returns an initialized HelmValuesFromCobraFlags
+func NewHelmValuesFromCobraFlags() *HelmValuesFromCobraFlags {
+	return &HelmValuesFromCobraFlags{
+		args:     make(map[string]interface{}, 0),
+		flagTree: FlagTree{},
+	}
+}
+
+// GetArgs returns the map of helm chart fields to values
+func (ctl *HelmValuesFromCobraFlags) GetArgs() map[string]interface{} {
+	return ctl.args
+}
+
+// SetArgs set the map to values
+func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
+	for key, value := range args {
+		ctl.args[key] = value
+	}
+}
+
+// coverityFlags are the flags of the Coverity Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var coverityFlags = flags.NewSet("Coverity", []flags.Flag{
+	{Name: "version", Field: "Version", Usage: "Version of Coverity you want to install\n"},
+
+	// Images
+	{Name: "image-registry", Field: "ImageRegistry", Usage: "Registry of the Coverity images, e.g. a mirror for an air gapped cluster", Path: []string{"imageRegistry"}},
+	{Name: "image-pull-secret", Field: "ImagePullSecret", Usage: "Secret to pull the Coverity images\n", Path: []string{"imagePullSecret"}},
+
+	// License
+	{Name: "license-file-path", Field: "LicenseFilePath", Kind: flags.FileSecret, Usage: "Path to the Coverity license file\n", Path: []string{"license", "data"}},
+
+	// PostgreSQL
+	{Name: "postgres-host", Field: "PostgresHost", Usage: "Hostname of the PostgreSQL database of Coverity Connect", Path: []string{"postgres", "host"}},
+	{Name: "postgres-port", Field: "PostgresPort", Usage: "Port of the PostgreSQL database", Path: []string{"postgres", "port"}},
+	{Name: "postgres-user", Field: "PostgresUser", Usage: "User of the PostgreSQL database", Path: []string{"postgres", "user"}},
+	{Name: "postgres-password", Field: "PostgresPassword", Usage: "Password of the PostgreSQL user", Path: []string{"postgres", "password"}, Secret: true},
+	{Name: "postgres-database", Field: "PostgresDatabase", Usage: "Name of the PostgreSQL database", Path: []string{"postgres", "database"}},
+	{Name: "postgres-ssl-mode", Field: "PostgresSSLMode", Kind: flags.Enum, Usage: "PostgreSQL SSL mode [disable|allow|prefer|require|verify-ca|verify-full]\n", Path: []string{"postgres", "sslMode"}, Values: []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}},
+
+	// Storage
+	{Name: "storage-class", Field: "StorageClass", Usage: "Storage class of the persistent volume claims", Path: []string{"persistence", "storageClass"}},
+	{Name: "cim-size", Field: "CIMPVCSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of Coverity Connect\n", Path: []string{"cim", "persistence", "size"}},
+
+	// Coverity Connect
+	{Name: "cim-replicas", Field: "CIMReplicas", Usage: "Number of Coverity Connect replicas", Path: []string{"cim", "replicas"}},
+	{Name: "expose-ui", Field: "ExposeService", Kind: flags.Enum, Usage: "Service type of Coverity Connect's user interface [NODEPORT|LOADBALANCER|NONE]\n", Path: []string{"cim", "service", "type"}, Values: []string{util.NODEPORT, util.LOADBALANCER, util.NONE}, Mapping: map[string]interface{}{util.NODEPORT: "NodePort", util.LOADBALANCER: "LoadBalancer", util.NONE: "ClusterIP"}},
+
+	// Ingress
+	{Name: "enable-ingress", Field: "IngressEnabled", Kind: flags.Bool, Usage: "Enable the ingress of Coverity Connect", Path: []string{"ingress", "enabled"}},
+	{Name: "ingress-host", Field: "IngressHost", Usage: "Hostname of the ingress", Path: []string{"ingress", "host"}},
+	{Name: "ingress-tls-secret", Field: "IngressTLSSecretName", Usage: "TLS Secret of the ingress\n", Path: []string{"ingress", "tls", "secretName"}},
+
+	// Scan service
+	{Name: "enable-scan-service", Field: "ScanServiceEnabled", Kind: flags.Bool, Usage: "Enable the scan service that runs the analysis jobs in the cluster", Path: []string{"scanService", "enabled"}},
+	{Name: "scan-service-replicas", Field: "ScanServiceReplicas", Usage: "Number of scan service replicas\n", Path: []string{"scanService", "replicas"}},
+
+	// Networking and security
+	{Name: "root-ca-secret", Field: "RootCASecret", Usage: "Secret with additional root certificates", Path: []string{"rootCASecret"}},
+	{Name: "http-proxy", Field: "HTTPProxy", Usage: "HTTP Proxy to use", Path: []string{"httpProxy"}},
+	{Name: "http-no-proxy", Field: "HTTPNoProxy", Usage: "Comma-separated list of domain extensions to omit proxy", Path: []string{"httpNoProxy"}},
+})
+
+// AddCobraFlagsToCommand adds flags for the Coverity helm chart to the cmd
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
+	}
+
+	coverityFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
+}
+
+// CheckValuesFromFlags returns an error if a value set by a flag is invalid
+func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if err := coverityFlags.Validate(flagset); err != nil {
+		return err
+	}
+	for _, name := range []string{"cim-replicas", "scan-service-replicas"} {
+		if f := flagset.Lookup(name); f.Changed && f.Value.String() == "0" {
+			return fmt.Errorf("--%s must be 1 or more", name)
+		}
+	}
+	if flagset.Lookup("postgres-host").Changed && len(ctl.flagTree.PostgresHost) == 0 {
+		return fmt.Errorf("--postgres-host must not be empty")
+	}
+	if flagset.Lookup("enable-ingress").Changed && ctl.flagTree.IngressEnabled && len(ctl.flagTree.IngressHost) == 0 {
+		return fmt.Errorf("--ingress-host must be set for the ingress")
+	}
+	return nil
+}
+
+// GenerateHelmFlagsFromCobraFlags checks each flag in synopsysctl and updates the map to
+// contain the corresponding helm chart field and value
+func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pflag.FlagSet) (map[string]interface{}, error) {
+	err := ctl.CheckValuesFromFlags(flagset)
+	if err != nil {
+		return nil, err
+	}
+	flagset.VisitAll(ctl.AddHelmValueByCobraFlag)
+
+	return ctl.args, nil
+}
+
+// AddHelmValueByCobraFlag adds the helm chart field and value based on the flag set
+// in synopsysctl
+func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
+	if f.Changed {
+		log.Debugf("flag '%s': CHANGED", f.Name)
+		if found, err := coverityFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+			log.Fatalf("%+v", err)
+		} else if !found {
+			log.Debugf("flag '%s': NOT FOUND", f.Name)
+		}
+	} else {
+		log.Debugf("flag '%s': UNCHANGED", f.Name)
+	}
+}
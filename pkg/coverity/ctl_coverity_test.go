@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package coverity
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHelmValuesFromCobraFlags(t *testing.T) {
+	assert := assert.New(t)
+	coverityCobraHelper := NewHelmValuesFromCobraFlags()
+	assert.Equal(&HelmValuesFromCobraFlags{
+		args:     map[string]interface{}{},
+		flagTree: FlagTree{},
+	}, coverityCobraHelper)
+}
+
+func TestGenerateHelmFlagsFromCobraFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	coverityCobraHelper := NewHelmValuesFromCobraFlags()
+	cmd := &cobra.Command{}
+	coverityCobraHelper.AddCobraFlagsToCommand(cmd, true)
+	flagset := cmd.Flags()
+	assert.NoError(flagset.Set("postgres-host", "postgres.example.com"))
+	assert.NoError(flagset.Set("expose-ui", "nodeport"))
+	assert.NoError(flagset.Set("enable-scan-service", "false"))
+
+	args, err := coverityCobraHelper.GenerateHelmFlagsFromCobraFlags(flagset)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"postgres": map[string]interface{}{
+			"host": "postgres.example.com",
+		},
+		"cim": map[string]interface{}{
+			"service": map[string]interface{}{
+				"type": "NodePort",
+			},
+		},
+		"scanService": map[string]interface{}{
+			"enabled": false,
+		},
+	}, args)
+}
+
+func TestCheckValuesFromFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		flags map[string]string
+		valid bool
+	}{
+		{flags: map[string]string{"cim-replicas": "2"}, valid: true},
+		{flags: map[string]string{"cim-replicas": "0"}, valid: false},
+		{flags: map[string]string{"postgres-ssl-mode": "require"}, valid: true},
+		{flags: map[string]string{"postgres-ssl-mode": "always"}, valid: false},
+		{flags: map[string]string{"cim-size": "big"}, valid: false},
+		{flags: map[string]string{"expose-ui": "OPENSHIFT"}, valid: false},
+		{flags: map[string]string{"enable-ingress": "true"}, valid: false},
+		{flags: map[string]string{"enable-ingress": "true", "ingress-host": "coverity.example.com"}, valid: true},
+	}
+
+	for _, test := range tests {
+		coverityCobraHelper := NewHelmValuesFromCobraFlags()
+		cmd := &cobra.Command{}
+		coverityCobraHelper.AddCobraFlagsToCommand(cmd, true)
+		for name, value := range test.flags {
+			assert.NoError(cmd.Flags().Set(name, value))
+		}
+		err := coverityCobraHelper.CheckValuesFromFlags(cmd.Flags())
+		assert.Equal(test.valid, err == nil, "flags %+v: %+v", test.flags, err)
+	}
+}
+
+func TestLicenseFileFlag(t *testing.T) {
+	assert := assert.New(t)
+
+	licenseFile, err := ioutil.TempFile("", "license")
+	assert.NoError(err)
+	defer os.Remove(licenseFile.Name())
+	_, err = licenseFile.WriteString("license data")
+	assert.NoError(err)
+	licenseFile.Close()
+
+	coverityCobraHelper := &HelmValuesFromCobraFlags{
+		args:     map[string]interface{}{},
+		flagTree: FlagTree{LicenseFilePath: licenseFile.Name()},
+	}
+	coverityCobraHelper.AddHelmValueByCobraFlag(&pflag.Flag{Changed: true, Name: "license-file-path"})
+	assert.Equal(map[string]interface{}{
+		"license": map[string]interface{}{
+			"data": "license data",
+		},
+	}, coverityCobraHelper.GetArgs())
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -118,7 +117,7 @@ func (c *ClusterRole) list() (interface{}, error) {
 
 // delete deletes the cluster role
 func (c *ClusterRole) delete(name string) error {
-	log.Infof("deleting the cluster role: %s", name)
+	c.config.logger.Infof("deleting the cluster role: %s", name)
 	return util.DeleteClusterRole(c.config.kubeClient, name)
 }
 
@@ -143,7 +142,7 @@ func (c *ClusterRole) patch(cr interface{}, isPatched bool) (bool, error) {
 	oldclusterRole := c.oldClusterRoles[clusterRoleName]
 	newClusterRole := c.newClusterRoles[clusterRoleName]
 	if !reflect.DeepEqual(sortPolicyRule(oldclusterRole.Rules), sortPolicyRule(newClusterRole.Rules)) && !c.config.dryRun {
-		log.Infof("updating the cluster role %s for %s namespace", clusterRoleName, c.config.namespace)
+		c.config.logger.Infof("updating the cluster role %s for %s namespace", clusterRoleName, c.config.namespace)
 		getCr, err := c.get(clusterRoleName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the cluster role %s for namespace %s", clusterRoleName, c.config.namespace)
@@ -29,7 +29,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -120,7 +119,7 @@ func (c *ClusterRoleBinding) list() (interface{}, error) {
 
 // delete deletes the cluster role binding
 func (c *ClusterRoleBinding) delete(name string) error {
-	log.Infof("deleting the cluster role binding: %s", name)
+	c.config.logger.Infof("deleting the cluster role binding: %s", name)
 	return util.DeleteClusterRoleBinding(c.config.kubeClient, name)
 }
 
@@ -180,7 +179,7 @@ func (c *ClusterRoleBinding) patch(crb interface{}, isPatched bool) (bool, error
 		}
 	}
 	if isChanged {
-		log.Infof("updating the cluster role binding %s for %s namespace", clusterRoleBindingName, c.config.namespace)
+		c.config.logger.Infof("updating the cluster role binding %s for %s namespace", clusterRoleBindingName, c.config.namespace)
 		getCrb, err := c.get(clusterRoleBindingName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the cluster role binding %s for namespace %s", clusterRoleBindingName, c.config.namespace)
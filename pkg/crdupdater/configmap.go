@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -120,7 +119,7 @@ func (c *ConfigMap) list() (interface{}, error) {
 
 // delete deletes the config map
 func (c *ConfigMap) delete(name string) error {
-	log.Infof("deleting the config map %s in %s namespace", name, c.config.namespace)
+	c.config.logger.Infof("deleting the config map %s in %s namespace", name, c.config.namespace)
 	return util.DeleteConfigMap(c.config.kubeClient, c.config.namespace, name)
 }
 
@@ -146,7 +145,7 @@ func (c *ConfigMap) patch(cm interface{}, isPatched bool) (bool, error) {
 	newConfigMap := c.newConfigMaps[configMapName]
 
 	if (!reflect.DeepEqual(newConfigMap.Data, oldConfigMap.Data) || !reflect.DeepEqual(newConfigMap.BinaryData, oldConfigMap.BinaryData)) && !c.config.dryRun {
-		log.Infof("updating the config map %s in %s namespace", configMapName, c.config.namespace)
+		c.config.logger.Infof("updating the config map %s in %s namespace", configMapName, c.config.namespace)
 		getCm, err := c.get(configMapName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the config map %s in namespace %s", configMapName, c.config.namespace)
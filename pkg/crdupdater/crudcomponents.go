@@ -27,6 +27,7 @@ import (
 	horizonapi "github.com/blackducksoftware/horizon/pkg/api"
 	"github.com/blackducksoftware/synopsysctl/pkg/api"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -45,6 +46,7 @@ type CommonConfig struct {
 	controllers               map[string]horizonapi.DeployerControllerInterface
 	isClusterLevelPermEnabled bool // for synopsysctl, it will be true because user might have cluster admin like privilege to add/delete crd, cluster role and role bindings.
 	// for others, it will be based on the pod's service account
+	logger *log.Entry
 }
 
 // NewCRUDComponents returns the common configuration which will be used to add, patch or remove the components
@@ -62,9 +64,15 @@ func NewCRUDComponents(kubeConfig *rest.Config, kubeClient *kubernetes.Clientset
 		expectedLabels:            getLabelsMap(labelSelector),
 		controllers:               make(map[string]horizonapi.DeployerControllerInterface, 0),
 		isClusterLevelPermEnabled: isClusterLevelPermEnabled,
+		logger:                    util.NewLogger("", "", namespace),
 	}
 }
 
+// SetLogger sets the logger of the updaters, e.g. with the product and the name of the instance
+func (c *CommonConfig) SetLogger(logger *log.Entry) {
+	c.logger = logger
+}
+
 // AddController will add the controller to the updater
 func (c *CommonConfig) AddController(name string, controller horizonapi.DeployerControllerInterface) {
 	c.controllers[name] = controller
@@ -75,6 +83,7 @@ func (c *CommonConfig) CRUDComponents() (bool, []error) {
 	// log.Debugf("expected labels: %+v", c.expectedLabels)
 	var errors []error
 	updater := NewUpdater(c.dryRun, c.isPatched)
+	updater.SetLogger(c.logger)
 
 	// namespace
 	namespaces, err := NewNamespace(c)
@@ -84,6 +93,9 @@ func (c *CommonConfig) CRUDComponents() (bool, []error) {
 		updater.AddUpdater(namespaces)
 	}
 
+	// the configuration, RBAC and storage of the instance are updated in parallel once the namespace exists
+	updater.NextStage()
+
 	// service account
 	serviceAccounts, err := NewServiceAccount(c, c.components.ServiceAccounts)
 	if err != nil {
@@ -152,6 +164,8 @@ func (c *CommonConfig) CRUDComponents() (bool, []error) {
 		updater.AddUpdater(pvcs)
 	}
 
+	updater.NextStage()
+
 	// service
 	services, err := NewService(c, c.components.Services)
 	if err != nil {
@@ -160,6 +174,10 @@ func (c *CommonConfig) CRUDComponents() (bool, []error) {
 		updater.AddUpdater(services)
 	}
 
+	// the workloads are updated in parallel once their configuration and services exist, so that a changed
+	// config map or secret still restarts them
+	updater.NextStage()
+
 	// replication controller
 	rcs, err := NewReplicationController(c, c.components.ReplicationControllers)
 	if err != nil {
@@ -184,7 +202,7 @@ func (c *CommonConfig) CRUDComponents() (bool, []error) {
 		updater.AddUpdater(routes)
 	}
 
-	// execute updates for all added components
+	// execute updates for all added components, stage by stage
 	isPatched, err := updater.Update()
 	if err != nil {
 		errors = append(errors, err)
@@ -26,7 +26,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 )
@@ -123,7 +122,7 @@ func (c *CustomResourceDefinition) list() (interface{}, error) {
 
 // delete deletes the custom resource defintion
 func (c *CustomResourceDefinition) delete(name string) error {
-	log.Infof("deleting the custom resource definition: %s", name)
+	c.config.logger.Infof("deleting the custom resource definition: %s", name)
 	return util.DeleteCustomResourceDefinition(c.apiExtensionClient, name)
 }
 
@@ -148,7 +147,7 @@ func (c *CustomResourceDefinition) patch(cr interface{}, isPatched bool) (bool,
 	oldCrd := c.oldCustomResourceDefinitions[crdName]
 	newCrd := c.newCustomResourceDefinitions[crdName]
 	if oldCrd.Spec.Scope != newCrd.Spec.Scope {
-		log.Warnf("updating the %s custom resource definition scope is not supported... please contact the support team to handle it...", crdName)
+		c.config.logger.Warnf("updating the %s custom resource definition scope is not supported... please contact the support team to handle it...", crdName)
 	}
 	return false, nil
 }
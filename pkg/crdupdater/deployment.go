@@ -29,7 +29,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -119,7 +118,7 @@ func (d *Deployment) list() (interface{}, error) {
 
 // delete deletes the deployment
 func (d *Deployment) delete(name string) error {
-	log.Infof("deleting the deployment %s in %s namespace", name, d.config.namespace)
+	d.config.logger.Infof("deleting the deployment %s in %s namespace", name, d.config.namespace)
 	return util.DeleteDeployment(d.config.kubeClient, d.config.namespace, name)
 }
 
@@ -151,7 +150,7 @@ func (d *Deployment) patch(rc interface{}, isPatched bool) (bool, error) {
 	// check isPatched, why?
 	// if there is any configuration change, irrespective of comparing any changes, patch the deployment
 	if isPatched && !d.config.dryRun {
-		log.Infof("updating the deployment %s in %s namespace", deployment.GetName(), d.config.namespace)
+		d.config.logger.Infof("updating the deployment %s in %s namespace", deployment.GetName(), d.config.namespace)
 		err := util.PatchDeployment(d.config.kubeClient, d.oldDeployments[deployment.GetName()], *d.newDeployments[deployment.GetName()])
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to patch deployment %s in namespace %s", deployment.GetName(), d.config.namespace)
@@ -198,7 +197,7 @@ func (d *Deployment) patch(rc interface{}, isPatched bool) (bool, error) {
 
 	// if there is any change from the above step, patch the deployment
 	if isChanged {
-		log.Infof("updating the deployment %s in %s namespace", deployment.GetName(), d.config.namespace)
+		d.config.logger.Infof("updating the deployment %s in %s namespace", deployment.GetName(), d.config.namespace)
 		err := util.PatchDeployment(d.config.kubeClient, d.oldDeployments[deployment.GetName()], *d.newDeployments[deployment.GetName()])
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to patch rc %s to kube in namespace %s", deployment.GetName(), d.config.namespace)
@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -76,7 +75,7 @@ func (n *Namespace) add(isPatched bool) (bool, error) {
 		if len(app) > 0 && len(name) > 0 && len(n.config.version) > 0 {
 			labels[fmt.Sprintf("synopsys.com/%s.%s", app, name)] = n.config.version
 		} else if len(n.config.version) > 0 {
-			log.Errorf("unable to add the Synopsys label because app: %s, name: %s, version: %s is missing", app, name, n.config.namespace)
+			n.config.logger.Errorf("unable to add the Synopsys label because app: %s, name: %s, version: %s is missing", app, name, n.config.namespace)
 		}
 		namespace.AddLabels(labels)
 		n.deployer.Deployer.AddComponent(horizonapi.NamespaceComponent, namespace)
@@ -122,7 +121,7 @@ func (n *Namespace) patch(ns interface{}, isPatched bool) (bool, error) {
 	}
 	if val, ok := namespace.Labels[fmt.Sprintf("synopsys.com/%s.%s", app, name)]; !ok || val != n.config.version {
 		if len(app) > 0 && len(name) > 0 && len(n.config.version) > 0 {
-			log.Debugf("patch namespace for synopsys label in namespace '%s'", namespace.Name)
+			n.config.logger.Debugf("patch namespace for synopsys label in namespace '%s'", namespace.Name)
 
 			getN, err := n.get(namespace.GetName())
 			if err != nil {
@@ -137,7 +136,7 @@ func (n *Namespace) patch(ns interface{}, isPatched bool) (bool, error) {
 				return false, fmt.Errorf("unable to update namespace %s due to %+v", namespace.GetName(), err)
 			}
 		} else if len(n.config.version) > 0 {
-			log.Errorf("unable to update the Synopsys label because app: %s, name: %s, version: %s is missing", app, name, n.config.namespace)
+			n.config.logger.Errorf("unable to update the Synopsys label because app: %s, name: %s, version: %s is missing", app, name, n.config.namespace)
 		}
 	}
 	return false, nil
@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -118,7 +117,7 @@ func (r *PersistentVolumeClaim) list() (interface{}, error) {
 
 // delete deletes the persistent volume claim
 func (r *PersistentVolumeClaim) delete(name string) error {
-	log.Infof("deleting the persistent volume claim %s in %s namespace", name, r.config.namespace)
+	r.config.logger.Infof("deleting the persistent volume claim %s in %s namespace", name, r.config.namespace)
 	return util.DeletePVC(r.config.kubeClient, r.config.namespace, name)
 }
 
@@ -146,7 +145,7 @@ func (r *PersistentVolumeClaim) patch(rc interface{}, isPatched bool) (bool, err
 	if (!reflect.DeepEqual(oldPersistentVolumeClaim.Spec.StorageClassName, newPersistentVolumeClaim.Spec.StorageClassName) ||
 		!reflect.DeepEqual(oldPersistentVolumeClaim.Spec.AccessModes, newPersistentVolumeClaim.Spec.AccessModes) ||
 		!reflect.DeepEqual(oldPersistentVolumeClaim.Spec.Resources, newPersistentVolumeClaim.Spec.Resources)) && !r.config.dryRun {
-		log.Infof("updating the config map %s in %s namespace", persistentVolumeClaimName, r.config.namespace)
+		r.config.logger.Infof("updating the config map %s in %s namespace", persistentVolumeClaimName, r.config.namespace)
 		getPvc, err := r.get(persistentVolumeClaimName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the config map %s in namespace %s", persistentVolumeClaimName, r.config.namespace)
@@ -29,7 +29,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -119,7 +118,7 @@ func (r *ReplicationController) list() (interface{}, error) {
 
 // delete deletes the replication controller
 func (r *ReplicationController) delete(name string) error {
-	log.Infof("deleting the replication controller %s in %s namespace", name, r.config.namespace)
+	r.config.logger.Infof("deleting the replication controller %s in %s namespace", name, r.config.namespace)
 	return util.DeleteReplicationController(r.config.kubeClient, r.config.namespace, name)
 }
 
@@ -202,7 +201,7 @@ func (r *ReplicationController) patch(rc interface{}, isPatched bool) (bool, err
 }
 
 func (r *ReplicationController) patchRC(old corev1.ReplicationController, new corev1.ReplicationController, isUpdateReplica bool) (bool, error) {
-	log.Infof("updating the replication controller %s in %s namespace", old.GetName(), r.config.namespace)
+	r.config.logger.Infof("updating the replication controller %s in %s namespace", old.GetName(), r.config.namespace)
 	err := util.PatchReplicationController(r.config.kubeClient, old, new, isUpdateReplica)
 	if err != nil {
 		return false, errors.Annotatef(err, "unable to patch replication controller %s in namespace %s", old.Name, r.config.namespace)
@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -118,7 +117,7 @@ func (c *Role) list() (interface{}, error) {
 
 // delete deletes the role
 func (c *Role) delete(name string) error {
-	log.Infof("deleting the role: %s", name)
+	c.config.logger.Infof("deleting the role: %s", name)
 	return util.DeleteRole(c.config.kubeClient, c.config.namespace, name)
 }
 
@@ -143,7 +142,7 @@ func (c *Role) patch(cr interface{}, isPatched bool) (bool, error) {
 	oldrole := c.oldRoles[roleName]
 	newRole := c.newRoles[roleName]
 	if !reflect.DeepEqual(sortPolicyRule(oldrole.Rules), sortPolicyRule(newRole.Rules)) && !c.config.dryRun {
-		log.Infof("updating the role %s for %s namespace", roleName, c.config.namespace)
+		c.config.logger.Infof("updating the role %s for %s namespace", roleName, c.config.namespace)
 		getR, err := c.get(roleName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the role %s for namespace %s", roleName, c.config.namespace)
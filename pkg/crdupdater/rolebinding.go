@@ -29,7 +29,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -120,7 +119,7 @@ func (c *RoleBinding) list() (interface{}, error) {
 
 // delete deletes the role binding
 func (c *RoleBinding) delete(name string) error {
-	log.Infof("deleting the role binding: %s", name)
+	c.config.logger.Infof("deleting the role binding: %s", name)
 	return util.DeleteRoleBinding(c.config.kubeClient, c.config.namespace, name)
 }
 
@@ -157,7 +156,7 @@ func (c *RoleBinding) patch(crb interface{}, isPatched bool) (bool, error) {
 		}
 	}
 	if isChanged {
-		log.Infof("updating the role binding %s for %s namespace", roleBindingName, c.config.namespace)
+		c.config.logger.Infof("updating the role binding %s for %s namespace", roleBindingName, c.config.namespace)
 		getRb, err := c.get(roleBindingName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the role binding %s for namespace %s", roleBindingName, c.config.namespace)
@@ -27,7 +27,6 @@ import (
 	"github.com/juju/errors"
 	routev1 "github.com/openshift/api/route/v1"
 	routeclient "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
-	log "github.com/sirupsen/logrus"
 )
 
 // Route stores the configuration to add or delete the route
@@ -92,7 +91,7 @@ func (c *Route) buildNewAndOldObject() error {
 func (c *Route) add(isPatched bool) (bool, error) {
 	for _, route := range c.routes {
 		if _, ok := c.oldRoutes[route.Name]; !ok && !c.config.dryRun {
-			log.Infof("creating Route %s", route.Name)
+			c.config.logger.Infof("creating Route %s", route.Name)
 			_, err := util.CreateRoute(c.routeClient, c.config.namespace, c.newRoutes[route.Name])
 			if err != nil {
 				return false, errors.Annotatef(err, "unable to deploy route in %s", c.config.namespace)
@@ -114,7 +113,7 @@ func (c *Route) list() (interface{}, error) {
 
 // delete deletes the route
 func (c *Route) delete(name string) error {
-	log.Infof("deleting the route: %s", name)
+	c.config.logger.Infof("deleting the route: %s", name)
 	return util.DeleteRoute(c.routeClient, c.config.namespace, name)
 }
 
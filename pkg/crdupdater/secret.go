@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -120,7 +119,7 @@ func (s *Secret) list() (interface{}, error) {
 
 // delete deletes the secret
 func (s *Secret) delete(name string) error {
-	log.Infof("deleting the secret %s in %s namespace", name, s.config.namespace)
+	s.config.logger.Infof("deleting the secret %s in %s namespace", name, s.config.namespace)
 	return util.DeleteSecret(s.config.kubeClient, s.config.namespace, name)
 }
 
@@ -145,7 +144,7 @@ func (s *Secret) patch(i interface{}, isPatched bool) (bool, error) {
 	oldSecret := s.oldSecrets[secretName]
 	newSecret := s.newSecrets[secretName]
 	if (!reflect.DeepEqual(newSecret.Data, oldSecret.Data) || !reflect.DeepEqual(newSecret.StringData, oldSecret.StringData)) && !s.config.dryRun {
-		log.Infof("updating the secret %s in %s namespace", secretName, s.config.namespace)
+		s.config.logger.Infof("updating the secret %s in %s namespace", secretName, s.config.namespace)
 		srt, err := s.get(secretName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the secret %s in namespace %s", secretName, s.config.namespace)
@@ -28,7 +28,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -117,7 +116,7 @@ func (s *Service) list() (interface{}, error) {
 
 // delete deletes the service
 func (s *Service) delete(name string) error {
-	log.Infof("deleting the service %s in %s namespace", name, s.config.namespace)
+	s.config.logger.Infof("deleting the service %s in %s namespace", name, s.config.namespace)
 	return util.DeleteService(s.config.kubeClient, s.config.namespace, name)
 }
 
@@ -144,7 +143,7 @@ func (s *Service) patch(svc interface{}, isPatched bool) (bool, error) {
 	if (!reflect.DeepEqual(sortPorts(newService.Spec.Ports), sortPorts(oldService.Spec.Ports)) ||
 		!reflect.DeepEqual(newService.Spec.Selector, oldService.Spec.Selector) ||
 		!reflect.DeepEqual(newService.Spec.Type, oldService.Spec.Type)) && !s.config.dryRun {
-		log.Infof("updating the service %s in %s namespace", serviceName, s.config.namespace)
+		s.config.logger.Infof("updating the service %s in %s namespace", serviceName, s.config.namespace)
 		getSvc, err := s.get(serviceName)
 		if err != nil {
 			return false, errors.Annotatef(err, "unable to get the service %s in namespace %s", serviceName, s.config.namespace)
@@ -26,7 +26,6 @@ import (
 	"github.com/blackducksoftware/horizon/pkg/components"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -110,7 +109,7 @@ func (s *ServiceAccount) list() (interface{}, error) {
 
 // delete deletes the service account
 func (s *ServiceAccount) delete(name string) error {
-	log.Infof("deleting the service account %s in %s namespace", name, s.config.namespace)
+	s.config.logger.Infof("deleting the service account %s in %s namespace", name, s.config.namespace)
 	return util.DeleteServiceAccount(s.config.kubeClient, s.config.namespace, name)
 }
 
@@ -22,7 +22,12 @@ under the License.
 package crdupdater
 
 import (
+	"fmt"
+	"sync"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/juju/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // UpdateComponents consist of methods to add, patch or remove the components for update events
@@ -37,50 +42,95 @@ type UpdateComponents interface {
 }
 
 // Updater handles in updating the components
+// The updaters are grouped in stages, the updaters of a stage run in parallel once all updaters of the
+// previous stages have finished
 type Updater struct {
-	updaters  []UpdateComponents
+	stages    [][]UpdateComponents
 	dryRun    bool
 	isPatched bool
+	logger    *log.Entry
 }
 
 // NewUpdater will create the specification that is used for updating the components
 func NewUpdater(dryRun bool, isPatched bool) *Updater {
 	updater := Updater{
-		updaters:  make([]UpdateComponents, 0),
+		stages:    [][]UpdateComponents{{}},
 		dryRun:    dryRun,
 		isPatched: isPatched,
+		logger:    util.NewLogger("", "", ""),
 	}
 	return &updater
 }
 
-// AddUpdater will add the updater to the list
+// SetLogger sets the logger of the stages
+func (u *Updater) SetLogger(logger *log.Entry) {
+	u.logger = logger
+}
+
+// AddUpdater will add the updater to the current stage
 func (u *Updater) AddUpdater(updater UpdateComponents) {
 	if updater != nil {
-		u.updaters = append(u.updaters, updater)
+		u.stages[len(u.stages)-1] = append(u.stages[len(u.stages)-1], updater)
+	}
+}
+
+// NextStage will start a new stage, the updaters that are added afterwards depend on the updaters of the previous stages
+func (u *Updater) NextStage() {
+	if len(u.stages[len(u.stages)-1]) > 0 {
+		u.stages = append(u.stages, []UpdateComponents{})
 	}
 }
 
 // Update add or remove the components
 func (u *Updater) Update() (bool, error) {
 	isPatched := false
-	for _, updater := range u.updaters {
-		if !u.dryRun {
-			err := updater.buildNewAndOldObject()
-			if err != nil {
-				return false, errors.Annotatef(err, "build components:")
+	for stageIndex, stage := range u.stages {
+		stageLogger := util.WithStep(u.logger, fmt.Sprintf("stage-%d", stageIndex+1))
+		stageLogger.Debugf("updating %d component type(s) in parallel", len(stage))
+		results := make([]updateResult, len(stage))
+		var wg sync.WaitGroup
+		for i, updater := range stage {
+			wg.Add(1)
+			go func(i int, updater UpdateComponents) {
+				defer wg.Done()
+				results[i] = u.update(updater, isPatched)
+			}(i, updater)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			if result.err != nil {
+				return false, result.err
 			}
+			isPatched = isPatched || u.isPatched || result.isUpdated
 		}
-		isUpdated, err := updater.add(isPatched)
-		isPatched = isPatched || u.isPatched || isUpdated
+	}
+	return isPatched, nil
+}
+
+// updateResult is the result of a single updater
+type updateResult struct {
+	isUpdated bool
+	err       error
+}
+
+// update adds, patches and removes the components of a single updater
+func (u *Updater) update(updater UpdateComponents, isPatched bool) updateResult {
+	if !u.dryRun {
+		err := updater.buildNewAndOldObject()
 		if err != nil {
-			return false, errors.Annotatef(err, "add/patch components:")
+			return updateResult{err: errors.Annotatef(err, "build components:")}
 		}
-		if !u.dryRun {
-			err = updater.remove()
-			if err != nil {
-				return false, errors.Annotatef(err, "remove components:")
-			}
+	}
+	isUpdated, err := updater.add(isPatched || u.isPatched)
+	if err != nil {
+		return updateResult{err: errors.Annotatef(err, "add/patch components:")}
+	}
+	if !u.dryRun {
+		err = updater.remove()
+		if err != nil {
+			return updateResult{err: errors.Annotatef(err, "remove components:")}
 		}
 	}
-	return isPatched, nil
+	return updateResult{isUpdated: isUpdated}
 }
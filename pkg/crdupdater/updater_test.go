@@ -21,10 +21,70 @@ under the License.
 package crdupdater
 
 import (
+	"sync"
 	"testing"
 	// "github.com/blackducksoftware/horizon/pkg/components"
 )
 
+// fakeUpdater records the order in which the updaters were added
+type fakeUpdater struct {
+	name      string
+	isUpdated bool
+	order     *[]string
+	patched   map[string]bool
+	lock      *sync.Mutex
+}
+
+func (f *fakeUpdater) buildNewAndOldObject() error { return nil }
+func (f *fakeUpdater) add(isPatched bool) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	*f.order = append(*f.order, f.name)
+	f.patched[f.name] = isPatched
+	return f.isUpdated, nil
+}
+func (f *fakeUpdater) get(name string) (interface{}, error)  { return nil, nil }
+func (f *fakeUpdater) list() (interface{}, error)            { return nil, nil }
+func (f *fakeUpdater) delete(name string) error              { return nil }
+func (f *fakeUpdater) remove() error                         { return nil }
+func (f *fakeUpdater) patch(interface{}, bool) (bool, error) { return false, nil }
+
+// TestUpdaterStages will test that the stages of the updater run in order
+func TestUpdaterStages(t *testing.T) {
+	order := []string{}
+	patched := map[string]bool{}
+	lock := &sync.Mutex{}
+	newFake := func(name string, isUpdated bool) *fakeUpdater {
+		return &fakeUpdater{name: name, isUpdated: isUpdated, order: &order, patched: patched, lock: lock}
+	}
+
+	updater := NewUpdater(true, false)
+	updater.AddUpdater(newFake("namespace", false))
+	updater.NextStage()
+	updater.AddUpdater(newFake("configmap", true))
+	updater.AddUpdater(newFake("secret", false))
+	updater.NextStage()
+	updater.NextStage()
+	updater.AddUpdater(newFake("deployment", false))
+
+	isPatched, err := updater.Update()
+	if err != nil {
+		t.Fatalf("unable to update due to %+v", err)
+	}
+	if !isPatched {
+		t.Errorf("expected the components to be patched")
+	}
+	if len(updater.stages) != 3 {
+		t.Errorf("expected 3 stages but got %d", len(updater.stages))
+	}
+	if len(order) != 4 || order[0] != "namespace" || order[3] != "deployment" {
+		t.Errorf("unexpected update order %+v", order)
+	}
+	if patched["secret"] || !patched["deployment"] {
+		t.Errorf("the later stages should see the patches of the previous stages: %+v", patched)
+	}
+}
+
 // TestUpdater will test the updater
 func TestUpdater(t *testing.T) {
 	// kubeConfig, err := GetKubeConfig("", false)
@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package datamover
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CloudSnapshotProviderName ...
+const CloudSnapshotProviderName = "cloud-snapshot"
+
+const (
+	cloudAWS = "aws"
+	cloudGCE = "gce"
+
+	awsEBSCSIDriver = "ebs.csi.aws.com"
+	gcePDCSIDriver  = "pd.csi.storage.gke.io"
+)
+
+// zoneLabels are the labels of a persistent volume that contain its zone
+var zoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+// runCommand runs a command line tool of a cloud provider and returns its trimmed output
+var runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("'%s %s' failed due to %+v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// cloudDisk is the disk of a cloud provider that backs a persistent volume
+type cloudDisk struct {
+	cloud string
+	id    string
+	zone  string
+	// project of a GCE disk that is managed by the CSI driver
+	project string
+}
+
+// cloudSnapshotProvider snapshots the cloud disk of the source claim with the aws or gcloud command line tool,
+// creates a new disk from the snapshot and binds it to the target claim with a new persistent volume. The target
+// cluster must be able to attach disks of the zone of the source disk
+type cloudSnapshotProvider struct{}
+
+func init() {
+	Register(CloudSnapshotProviderName, &cloudSnapshotProvider{})
+}
+
+// Move ...
+func (p *cloudSnapshotProvider) Move(source Volume, target Volume, opts Options) error {
+	sourceClaim, err := util.GetPVC(source.KubeClient, source.Namespace, source.ClaimName)
+	if err != nil {
+		return fmt.Errorf("unable to get persistent volume claim '%s' due to %+v", source, err)
+	}
+	if len(sourceClaim.Spec.VolumeName) == 0 {
+		return fmt.Errorf("persistent volume claim '%s' is not bound", source)
+	}
+	if _, err := util.GetPVC(target.KubeClient, target.Namespace, target.ClaimName); err == nil {
+		return fmt.Errorf("persistent volume claim '%s' already exists, a snapshot can only be restored into a new claim", target)
+	}
+	sourcePV, err := source.KubeClient.CoreV1().PersistentVolumes().Get(sourceClaim.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get persistent volume '%s' due to %+v", sourceClaim.Spec.VolumeName, err)
+	}
+	disk, err := getCloudDisk(sourcePV)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	newDiskName := fmt.Sprintf("%s-%s-%d", target.Namespace, target.ClaimName, time.Now().Unix())
+	log.Infof("creating a snapshot of %s disk '%s'", disk.cloud, disk.id)
+	newDiskID, err := copyCloudDisk(ctx, disk, newDiskName)
+	if err != nil {
+		return err
+	}
+
+	pv := newPersistentVolumeForDisk(sourcePV, disk, newDiskID, newDiskName, target)
+	if _, err := target.KubeClient.CoreV1().PersistentVolumes().Create(pv); err != nil {
+		return fmt.Errorf("unable to create persistent volume '%s' for %s disk '%s' due to %+v", pv.Name, disk.cloud, newDiskID, err)
+	}
+	claim := newTargetClaim(sourceClaim, target, "")
+	claim.Spec.StorageClassName = &pv.Spec.StorageClassName
+	claim.Spec.VolumeName = pv.Name
+	if _, err := target.KubeClient.CoreV1().PersistentVolumeClaims(target.Namespace).Create(claim); err != nil {
+		return fmt.Errorf("unable to create persistent volume claim '%s' due to %+v", target, err)
+	}
+	return nil
+}
+
+// getCloudDisk returns the cloud disk of a persistent volume
+func getCloudDisk(pv *corev1.PersistentVolume) (cloudDisk, error) {
+	disk := cloudDisk{zone: getPersistentVolumeZone(pv)}
+	switch {
+	case pv.Spec.AWSElasticBlockStore != nil:
+		// the volume ID is either vol-123 or aws://<zone>/vol-123
+		disk.cloud = cloudAWS
+		volumeID := strings.TrimPrefix(pv.Spec.AWSElasticBlockStore.VolumeID, "aws://")
+		if parts := strings.Split(volumeID, "/"); len(parts) == 2 {
+			disk.zone, volumeID = parts[0], parts[1]
+		}
+		disk.id = volumeID
+	case pv.Spec.GCEPersistentDisk != nil:
+		disk.cloud = cloudGCE
+		disk.id = pv.Spec.GCEPersistentDisk.PDName
+	case pv.Spec.CSI != nil && pv.Spec.CSI.Driver == awsEBSCSIDriver:
+		disk.cloud = cloudAWS
+		disk.id = pv.Spec.CSI.VolumeHandle
+	case pv.Spec.CSI != nil && pv.Spec.CSI.Driver == gcePDCSIDriver:
+		// the volume handle is projects/<project>/zones/<zone>/disks/<name>
+		disk.cloud = cloudGCE
+		parts := strings.Split(pv.Spec.CSI.VolumeHandle, "/")
+		if len(parts) != 6 {
+			return disk, fmt.Errorf("unable to parse the GCE disk '%s' of persistent volume '%s'", pv.Spec.CSI.VolumeHandle, pv.Name)
+		}
+		disk.project, disk.zone, disk.id = parts[1], parts[3], parts[5]
+	default:
+		return disk, fmt.Errorf("persistent volume '%s' is not backed by an AWS EBS or GCE persistent disk, use %s instead", pv.Name, RsyncProviderName)
+	}
+	if len(disk.zone) == 0 {
+		return disk, fmt.Errorf("unable to find the zone of persistent volume '%s'", pv.Name)
+	}
+	return disk, nil
+}
+
+// getPersistentVolumeZone returns the zone of a persistent volume from its labels or its node affinity
+func getPersistentVolumeZone(pv *corev1.PersistentVolume) string {
+	for _, label := range zoneLabels {
+		if zone, ok := pv.Labels[label]; ok {
+			return zone
+		}
+	}
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+			for _, expression := range term.MatchExpressions {
+				if strings.HasSuffix(expression.Key, "/zone") && len(expression.Values) > 0 {
+					return expression.Values[0]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// copyCloudDisk creates a snapshot of the disk and a new disk from the snapshot, and returns the ID of the new disk
+func copyCloudDisk(ctx context.Context, disk cloudDisk, newDiskName string) (string, error) {
+	switch disk.cloud {
+	case cloudAWS:
+		snapshotID, err := runCommand(ctx, "aws", "ec2", "create-snapshot", "--volume-id", disk.id, "--description", newDiskName, "--query", "SnapshotId", "--output", "text")
+		if err != nil {
+			return "", err
+		}
+		if _, err := runCommand(ctx, "aws", "ec2", "wait", "snapshot-completed", "--snapshot-ids", snapshotID); err != nil {
+			return "", err
+		}
+		volumeID, err := runCommand(ctx, "aws", "ec2", "create-volume", "--snapshot-id", snapshotID, "--availability-zone", disk.zone, "--query", "VolumeId", "--output", "text")
+		if err != nil {
+			return "", err
+		}
+		if _, err := runCommand(ctx, "aws", "ec2", "wait", "volume-available", "--volume-ids", volumeID); err != nil {
+			return "", err
+		}
+		return volumeID, nil
+	case cloudGCE:
+		projectArgs := []string{}
+		if len(disk.project) > 0 {
+			projectArgs = []string{"--project", disk.project}
+		}
+		args := append([]string{"compute", "disks", "snapshot", disk.id, "--zone", disk.zone, "--snapshot-names", newDiskName}, projectArgs...)
+		if _, err := runCommand(ctx, "gcloud", args...); err != nil {
+			return "", err
+		}
+		args = append([]string{"compute", "disks", "create", newDiskName, "--zone", disk.zone, "--source-snapshot", newDiskName}, projectArgs...)
+		if _, err := runCommand(ctx, "gcloud", args...); err != nil {
+			return "", err
+		}
+		return newDiskName, nil
+	}
+	return "", fmt.Errorf("unsupported cloud '%s'", disk.cloud)
+}
+
+// newPersistentVolumeForDisk returns a persistent volume like the source volume for the new disk, that is reserved
+// for the target claim
+func newPersistentVolumeForDisk(sourcePV *corev1.PersistentVolume, disk cloudDisk, newDiskID string, name string, target Volume) *corev1.PersistentVolume {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: sourcePV.Labels},
+		Spec:       *sourcePV.Spec.DeepCopy(),
+	}
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: target.Namespace, Name: target.ClaimName}
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	switch {
+	case pv.Spec.AWSElasticBlockStore != nil:
+		pv.Spec.AWSElasticBlockStore.VolumeID = fmt.Sprintf("aws://%s/%s", disk.zone, newDiskID)
+	case pv.Spec.GCEPersistentDisk != nil:
+		pv.Spec.GCEPersistentDisk.PDName = newDiskID
+	case pv.Spec.CSI != nil && pv.Spec.CSI.Driver == gcePDCSIDriver:
+		pv.Spec.CSI.VolumeHandle = fmt.Sprintf("projects/%s/zones/%s/disks/%s", disk.project, disk.zone, newDiskID)
+	case pv.Spec.CSI != nil:
+		pv.Spec.CSI.VolumeHandle = newDiskID
+	}
+	return pv
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package datamover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// CSISnapshotProviderName ...
+const CSISnapshotProviderName = "csi-snapshot"
+
+// volumeSnapshotGroup is the API group of the CSI volume snapshots
+const volumeSnapshotGroup = "snapshot.storage.k8s.io"
+
+var volumeSnapshotResource = schema.GroupVersionResource{Group: volumeSnapshotGroup, Version: "v1beta1", Resource: "volumesnapshots"}
+
+// csiSnapshotProvider takes a CSI volume snapshot of the source claim and restores it into a new target claim.
+// Kubernetes only restores snapshots into claims of the same namespace
+type csiSnapshotProvider struct{}
+
+func init() {
+	Register(CSISnapshotProviderName, &csiSnapshotProvider{})
+}
+
+// Move ...
+func (p *csiSnapshotProvider) Move(source Volume, target Volume, opts Options) error {
+	if !isSameCluster(source, target) || source.Namespace != target.Namespace {
+		return fmt.Errorf("CSI snapshots can only be restored in the namespace of the snapshot, use %s to move the data to another namespace or cluster", RsyncProviderName)
+	}
+	if source.RestConfig == nil {
+		return fmt.Errorf("the rest config of the cluster is required to take a CSI snapshot")
+	}
+	sourceClaim, err := util.GetPVC(source.KubeClient, source.Namespace, source.ClaimName)
+	if err != nil {
+		return fmt.Errorf("unable to get persistent volume claim '%s' due to %+v", source, err)
+	}
+	if _, err := util.GetPVC(target.KubeClient, target.Namespace, target.ClaimName); err == nil {
+		return fmt.Errorf("persistent volume claim '%s' already exists, a snapshot can only be restored into a new claim", target)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(source.RestConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	snapshots := dynamicClient.Resource(volumeSnapshotResource).Namespace(source.Namespace)
+
+	snapshotName := fmt.Sprintf("%s-%d", source.ClaimName, time.Now().Unix())
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": source.ClaimName},
+	}
+	if len(opts.SnapshotClass) > 0 {
+		spec["volumeSnapshotClassName"] = opts.SnapshotClass
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": volumeSnapshotResource.GroupVersion().String(),
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]interface{}{"name": snapshotName, "namespace": source.Namespace},
+		"spec":       spec,
+	}}
+	if _, err := snapshots.Create(snapshot, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("unable to create volume snapshot '%s' in namespace '%s' due to %+v", snapshotName, source.Namespace, err)
+	}
+
+	err = wait.PollImmediate(5*time.Second, opts.Timeout, func() (bool, error) {
+		snapshot, err := snapshots.Get(snapshotName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+			return false, fmt.Errorf("volume snapshot '%s' failed: %s", snapshotName, message)
+		}
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		return ready, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("volume snapshot '%s' in namespace '%s' wasn't ready within %s", snapshotName, source.Namespace, opts.Timeout))
+	} else if err != nil {
+		return err
+	}
+
+	apiGroup := volumeSnapshotGroup
+	claim := newTargetClaim(sourceClaim, target, opts.StorageClass)
+	claim.Spec.DataSource = &corev1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeSnapshot", Name: snapshotName}
+	if _, err := target.KubeClient.CoreV1().PersistentVolumeClaims(target.Namespace).Create(claim); err != nil {
+		return fmt.Errorf("unable to create persistent volume claim '%s' from volume snapshot '%s' due to %+v", target, snapshotName, err)
+	}
+	log.Infof("restored volume snapshot '%s' into persistent volume claim '%s', delete the snapshot once the claim is bound", snapshotName, target)
+	return nil
+}
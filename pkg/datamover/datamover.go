@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package datamover moves the data of persistent volume claims between namespaces and clusters
+package datamover
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Volume is a persistent volume claim in a cluster
+type Volume struct {
+	Namespace  string
+	ClaimName  string
+	KubeClient *kubernetes.Clientset
+	RestConfig *rest.Config
+}
+
+// String ...
+func (v Volume) String() string {
+	return fmt.Sprintf("%s/%s", v.Namespace, v.ClaimName)
+}
+
+// Options configure how the data is moved
+type Options struct {
+	// Timeout is the maximum time to move the data
+	Timeout time.Duration
+	// Image is the image of the pods that copy the data, if the provider uses pods
+	Image string
+	// StorageClass of the target claim if it is created, the storage class of the source claim is used if empty
+	StorageClass string
+	// SnapshotClass of the volume snapshots, the default snapshot class is used if empty
+	SnapshotClass string
+}
+
+// Provider moves the data of a source claim into a target claim
+type Provider interface {
+	// Move copies the data of the source claim into the target claim
+	Move(source Volume, target Volume, opts Options) error
+}
+
+// providers are the registered data movers by name
+var providers = map[string]Provider{}
+
+// Register adds a data mover, it replaces a data mover with the same name
+func Register(name string, provider Provider) {
+	providers[strings.ToLower(name)] = provider
+}
+
+// GetProvider returns the data mover with the name
+func GetProvider(name string) (Provider, error) {
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown data mover '%s', it must be one of %s", name, strings.Join(GetProviderNames(), ", "))
+	}
+	return provider, nil
+}
+
+// GetProviderNames returns the names of the registered data movers
+func GetProviderNames() []string {
+	names := []string{}
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Move copies the data of the source claim into the target claim with the data mover of the name
+func Move(providerName string, source Volume, target Volume, opts Options) error {
+	provider, err := GetProvider(providerName)
+	if err != nil {
+		return err
+	}
+	log.Infof("moving the data of persistent volume claim '%s' to '%s' with %s", source, target, providerName)
+	if err := provider.Move(source, target, opts); err != nil {
+		return fmt.Errorf("failed to move the data of persistent volume claim '%s' to '%s': %w", source, target, err)
+	}
+	return nil
+}
+
+// isSameCluster returns true if both volumes are in the same cluster
+func isSameCluster(source Volume, target Volume) bool {
+	if source.RestConfig == nil || target.RestConfig == nil {
+		return source.KubeClient == target.KubeClient
+	}
+	return source.RestConfig.Host == target.RestConfig.Host
+}
+
+// newTargetClaim returns a claim for the target volume with the size and access modes of the source claim
+func newTargetClaim(sourceClaim *corev1.PersistentVolumeClaim, target Volume, storageClass string) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        target.ClaimName,
+			Namespace:   target.Namespace,
+			Labels:      sourceClaim.Labels,
+			Annotations: map[string]string{},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      sourceClaim.Spec.AccessModes,
+			Resources:        sourceClaim.Spec.Resources,
+			StorageClassName: sourceClaim.Spec.StorageClassName,
+			VolumeMode:       sourceClaim.Spec.VolumeMode,
+		},
+	}
+	if len(storageClass) > 0 {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	return claim
+}
+
+// getOrCreateTargetClaim returns the target claim, it is created like the source claim if it doesn't exist
+func getOrCreateTargetClaim(sourceClaim *corev1.PersistentVolumeClaim, target Volume, storageClass string) (*corev1.PersistentVolumeClaim, error) {
+	claim, err := util.GetPVC(target.KubeClient, target.Namespace, target.ClaimName)
+	if err == nil {
+		return claim, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get persistent volume claim '%s' due to %+v", target, err)
+	}
+	claim, err = target.KubeClient.CoreV1().PersistentVolumeClaims(target.Namespace).Create(newTargetClaim(sourceClaim, target, storageClass))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create persistent volume claim '%s' due to %+v", target, err)
+	}
+	return claim, nil
+}
+
+// waitForJob waits until the job succeeded or failed
+func waitForJob(clientset *kubernetes.Clientset, namespace string, name string, timeout time.Duration) error {
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		job, err := clientset.BatchV1().Jobs(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if job.Status.Failed > 0 {
+			return false, fmt.Errorf("job '%s' in namespace '%s' failed, see the logs of its pod for details", name, namespace)
+		}
+		return job.Status.Succeeded > 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("job '%s' in namespace '%s' didn't complete within %s", name, namespace, timeout))
+	}
+	return err
+}
+
+// deleteJob deletes a job and its pods
+func deleteJob(clientset *kubernetes.Clientset, namespace string, name string) {
+	propagation := metav1.DeletePropagationBackground
+	if err := clientset.BatchV1().Jobs(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !k8serrors.IsNotFound(err) {
+		log.Warnf("unable to delete job '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+}
+
+// newJob returns a job that runs a shell command once with the claim mounted at /data
+func newJob(namespace string, name string, image string, command string, claimName string, readOnly bool) *batchv1.Job {
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "synopsysctl", "component": "datamover"},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "synopsysctl", "component": "datamover", "job": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:         "datamover",
+							Image:        image,
+							Command:      []string{"/bin/sh", "-c", command},
+							VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data", ReadOnly: readOnly}},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName, ReadOnly: readOnly},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
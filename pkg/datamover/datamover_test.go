@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package datamover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetProvider(t *testing.T) {
+	assert.Equal(t, []string{CloudSnapshotProviderName, CSISnapshotProviderName, RsyncProviderName}, GetProviderNames())
+	provider, err := GetProvider("RSYNC")
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+	_, err = GetProvider("ftp")
+	assert.EqualError(t, err, "unknown data mover 'ftp', it must be one of cloud-snapshot, csi-snapshot, rsync")
+}
+
+func TestGetCloudDisk(t *testing.T) {
+	tests := []struct {
+		description string
+		pv          *corev1.PersistentVolume
+		disk        cloudDisk
+		err         bool
+	}{
+		{
+			description: "in-tree EBS volume with zone",
+			pv: &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{VolumeID: "aws://us-east-1a/vol-0123"},
+			}}},
+			disk: cloudDisk{cloud: cloudAWS, id: "vol-0123", zone: "us-east-1a"},
+		},
+		{
+			description: "in-tree GCE disk with zone label",
+			pv: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"failure-domain.beta.kubernetes.io/zone": "us-central1-a"}},
+				Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+					GCEPersistentDisk: &corev1.GCEPersistentDiskVolumeSource{PDName: "pvc-123"},
+				}},
+			},
+			disk: cloudDisk{cloud: cloudGCE, id: "pvc-123", zone: "us-central1-a"},
+		},
+		{
+			description: "GCE CSI disk",
+			pv: &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: gcePDCSIDriver, VolumeHandle: "projects/synopsys/zones/europe-west1-b/disks/pvc-456"},
+			}}},
+			disk: cloudDisk{cloud: cloudGCE, id: "pvc-456", zone: "europe-west1-b", project: "synopsys"},
+		},
+		{
+			description: "EBS CSI volume without zone",
+			pv: &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: awsEBSCSIDriver, VolumeHandle: "vol-0456"},
+			}}},
+			err: true,
+		},
+		{
+			description: "NFS volume",
+			pv: &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/data"},
+			}}},
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		disk, err := getCloudDisk(test.pv)
+		if test.err {
+			assert.Error(t, err, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.disk, disk, test.description)
+	}
+}
+
+func TestNewPersistentVolumeForDisk(t *testing.T) {
+	sourcePV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-old"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{VolumeID: "aws://us-east-1a/vol-0123"},
+			},
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "old", Name: "data"},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+		},
+	}
+	pv := newPersistentVolumeForDisk(sourcePV, cloudDisk{cloud: cloudAWS, id: "vol-0123", zone: "us-east-1a"}, "vol-0789", "new-data", Volume{Namespace: "new", ClaimName: "data"})
+	assert.Equal(t, "aws://us-east-1a/vol-0789", pv.Spec.AWSElasticBlockStore.VolumeID)
+	assert.Equal(t, "new", pv.Spec.ClaimRef.Namespace)
+	assert.Equal(t, corev1.PersistentVolumeReclaimRetain, pv.Spec.PersistentVolumeReclaimPolicy)
+	assert.Equal(t, "aws://us-east-1a/vol-0123", sourcePV.Spec.AWSElasticBlockStore.VolumeID)
+}
+
+func TestRsyncClientCommand(t *testing.T) {
+	command := rsyncClientCommand("data-datamover-source.bd.svc.cluster.local")
+	assert.True(t, strings.HasSuffix(command, "rsync -a --delete rsync://synopsysctl@data-datamover-source.bd.svc.cluster.local:873/data/ /data/"))
+}
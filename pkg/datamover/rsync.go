@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package datamover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RsyncProviderName ...
+const RsyncProviderName = "rsync"
+
+// rsyncPort is the port of the rsync daemon
+const rsyncPort = 873
+
+// rsyncUser is the user that the rsync client authenticates as
+const rsyncUser = "synopsysctl"
+
+// rsyncProvider copies the files of the source claim with an rsync daemon job in the source namespace and an
+// rsync client job in the target namespace. The daemon is exposed with a load balancer if the target is in
+// another cluster
+type rsyncProvider struct{}
+
+func init() {
+	Register(RsyncProviderName, &rsyncProvider{})
+}
+
+// Move ...
+func (p *rsyncProvider) Move(source Volume, target Volume, opts Options) error {
+	image := opts.Image
+	if len(image) == 0 {
+		image = globals.DefaultRsyncImage
+	}
+	sourceClaim, err := util.GetPVC(source.KubeClient, source.Namespace, source.ClaimName)
+	if err != nil {
+		return fmt.Errorf("unable to get persistent volume claim '%s' due to %+v", source, err)
+	}
+	if _, err := getOrCreateTargetClaim(sourceClaim, target, opts.StorageClass); err != nil {
+		return err
+	}
+	password, err := util.GetRandomString(32)
+	if err != nil {
+		return fmt.Errorf("unable to generate the rsync password due to %+v", err)
+	}
+
+	// start the rsync daemon next to the source claim
+	daemonName := fmt.Sprintf("%s-datamover-source", source.ClaimName)
+	daemonJob := newJob(source.Namespace, daemonName, image, rsyncDaemonCommand(), source.ClaimName, true)
+	daemonJob.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "RSYNC_PASSWORD", Value: password}}
+	daemonJob.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "rsync", ContainerPort: rsyncPort}}
+	if _, err := source.KubeClient.BatchV1().Jobs(source.Namespace).Create(daemonJob); err != nil {
+		return fmt.Errorf("unable to create the rsync daemon job in namespace '%s' due to %+v", source.Namespace, err)
+	}
+	defer deleteJob(source.KubeClient, source.Namespace, daemonName)
+
+	serviceType := corev1.ServiceTypeClusterIP
+	if !isSameCluster(source, target) {
+		log.Warnf("the rsync daemon of persistent volume claim '%s' is exposed with a load balancer while the data is moved", source)
+		serviceType = corev1.ServiceTypeLoadBalancer
+	}
+	_, err = source.KubeClient.CoreV1().Services(source.Namespace).Create(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: daemonName, Namespace: source.Namespace, Labels: daemonJob.Labels},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: map[string]string{"job": daemonName},
+			Ports:    []corev1.ServicePort{{Name: "rsync", Port: rsyncPort, TargetPort: intstr.FromInt(rsyncPort)}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the rsync daemon service in namespace '%s' due to %+v", source.Namespace, err)
+	}
+	defer func() {
+		if err := util.DeleteService(source.KubeClient, source.Namespace, daemonName); err != nil && !k8serrors.IsNotFound(err) {
+			log.Warnf("unable to delete service '%s' in namespace '%s' due to %+v", daemonName, source.Namespace, err)
+		}
+	}()
+
+	host := fmt.Sprintf("%s.%s.svc.cluster.local", daemonName, source.Namespace)
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		if host, err = waitForLoadBalancerHost(source, daemonName, opts.Timeout); err != nil {
+			return err
+		}
+	}
+
+	// copy the files into the target claim
+	clientName := fmt.Sprintf("%s-datamover-target", target.ClaimName)
+	clientJob := newJob(target.Namespace, clientName, image, rsyncClientCommand(host), target.ClaimName, false)
+	clientJob.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "RSYNC_PASSWORD", Value: password}}
+	if _, err := target.KubeClient.BatchV1().Jobs(target.Namespace).Create(clientJob); err != nil {
+		return fmt.Errorf("unable to create the rsync job in namespace '%s' due to %+v", target.Namespace, err)
+	}
+	defer deleteJob(target.KubeClient, target.Namespace, clientName)
+	return waitForJob(target.KubeClient, target.Namespace, clientName, opts.Timeout)
+}
+
+// rsyncDaemonCommand returns the command that serves /data read-only to the rsync user
+func rsyncDaemonCommand() string {
+	return fmt.Sprintf("echo \"%s:$RSYNC_PASSWORD\" > /tmp/rsyncd.secrets && chmod 600 /tmp/rsyncd.secrets && "+
+		"printf '[data]\\n path = /data\\n read only = true\\n uid = root\\n gid = root\\n auth users = %s\\n secrets file = /tmp/rsyncd.secrets\\n' > /tmp/rsyncd.conf && "+
+		"exec rsync --daemon --no-detach --port=%d --config=/tmp/rsyncd.conf", rsyncUser, rsyncUser, rsyncPort)
+}
+
+// rsyncClientCommand returns the command that waits for the rsync daemon and copies its files into /data
+func rsyncClientCommand(host string) string {
+	url := fmt.Sprintf("rsync://%s@%s:%d/data/", rsyncUser, host, rsyncPort)
+	return fmt.Sprintf("until rsync %s > /dev/null 2>&1; do sleep 5; done && rsync -a --delete %s /data/", url, url)
+}
+
+// waitForLoadBalancerHost waits until the load balancer of the service has an address
+func waitForLoadBalancerHost(volume Volume, serviceName string, timeout time.Duration) (string, error) {
+	host := ""
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		service, err := util.GetService(volume.KubeClient, volume.Namespace, serviceName)
+		if err != nil {
+			return false, err
+		}
+		if hosts := util.GetLoadBalancerHosts(service); len(hosts) > 0 {
+			host = hosts[0]
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("the load balancer of service '%s' in namespace '%s' has no address due to %+v", serviceName, volume.Namespace, err)
+	}
+	return host, nil
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package flags declares the synopsysctl flags that configure the Helm chart of a product. Each flag is bound to a field of
+// the product's FlagTree and knows its kind, the Helm value it sets and the product versions that support it, so the
+// products share the registration, validation, redaction and values mapping of their flags
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Kind is the type of the value that a flag sets in the Helm values
+type Kind int
+
+const (
+	// String flags set the value of their field as is, e.g. a string, a number or a list
+	String Kind = iota
+	// Bool flags set a bool, string fields are parsed so that the [true|false] flags set a bool too
+	Bool
+	// Quantity flags set a Kubernetes resource quantity such as 150Gi
+	Quantity
+	// Enum flags set one of the Values of the flag, the values are case-insensitive
+	Enum
+	// FileSecret flags take the path of a file and set the content of the file, which is never logged
+	FileSecret
+)
+
+// redacted replaces the value of a secret flag in the logs
+const redacted = "<redacted>"
+
+// EnumValuesAnnotation is the annotation of the cobra flags of the Enum flags with their allowed values, e.g. for the
+// completion of their values
+const EnumValuesAnnotation = "synopsys.com/enum-values"
+
+// ExposeServiceValues are the values of the flags that expose a service
+var ExposeServiceValues = []string{util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE}
+
+// ExposeServiceMapping maps the values of the flags that expose a service to the service types of the charts
+var ExposeServiceMapping = map[string]interface{}{
+	util.NODEPORT:     "NodePort",
+	util.LOADBALANCER: "LoadBalancer",
+	util.OPENSHIFT:    "OpenShift",
+	util.NONE:         "None",
+}
+
+// Flag is a synopsysctl flag that configures the Helm chart of a product
+type Flag struct {
+	// Name of the flag
+	Name string
+	// Field is the name of the field of the FlagTree that the flag is bound to
+	Field string
+	Kind  Kind
+	Usage string
+	// Path is the Helm value that the flag sets, flags without a path are handled by the product
+	Path []string
+	// Invert sets the negated value of a Bool flag, e.g. for --disable-... flags
+	Invert bool
+	// Values are the allowed values of an Enum flag
+	Values []string
+	// Mapping maps the upper case values of an Enum flag to the Helm value, if it's nil the value is set as is
+	Mapping map[string]interface{}
+	// Repeatable list flags take one item per flag instead of a comma separated list
+	Repeatable bool
+	// CreateOnly flags are only added to the create commands
+	CreateOnly bool
+	Hidden     bool
+	// MinVersion is the first product version whose chart supports the flag
+	MinVersion string
+	// Secret flags are redacted in the logs
+	Secret bool
+}
+
+// IsSecret returns true if the value of the flag must not be logged
+func (f *Flag) IsSecret() bool {
+	return f.Secret || f.Kind == FileSecret
+}
+
+// Set is the ordered set of the flags of a product
+type Set struct {
+	product string
+	flags   []*Flag
+	byName  map[string]*Flag
+}
+
+// NewSet returns the set of flags of a product, the flags are added to the commands in the given order
+func NewSet(product string, flags []Flag) *Set {
+	s := &Set{product: product, byName: map[string]*Flag{}}
+	for i := range flags {
+		f := &flags[i]
+		if _, ok := s.byName[f.Name]; ok {
+			panic(fmt.Sprintf("flag '%s' of %s is declared more than once", f.Name, product))
+		}
+		s.flags = append(s.flags, f)
+		s.byName[f.Name] = f
+	}
+	return s
+}
+
+// Lookup returns the flag with the name, or nil if the set doesn't contain it
+func (s *Set) Lookup(name string) *Flag {
+	return s.byName[name]
+}
+
+// AddToCommand adds the flags to the command and binds them to the fields of tree, a pointer to the product's FlagTree.
+// defaults is a pointer to the FlagTree with the default values of the flags
+func (s *Set) AddToCommand(cmd *cobra.Command, tree interface{}, defaults interface{}, isCreateCmd bool) {
+	treeValue := reflect.ValueOf(tree).Elem()
+	defaultsValue := reflect.ValueOf(defaults).Elem()
+	for _, f := range s.flags {
+		if f.CreateOnly && !isCreateCmd {
+			continue
+		}
+		field := treeValue.FieldByName(f.Field)
+		if !field.IsValid() {
+			panic(fmt.Sprintf("flag '%s' of %s is bound to the unknown field '%s'", f.Name, s.product, f.Field))
+		}
+		defaultValue := defaultsValue.FieldByName(f.Field).Interface()
+		switch p := field.Addr().Interface().(type) {
+		case *string:
+			cmd.Flags().StringVar(p, f.Name, defaultValue.(string), f.Usage)
+		case *bool:
+			cmd.Flags().BoolVar(p, f.Name, defaultValue.(bool), f.Usage)
+		case *int:
+			cmd.Flags().IntVar(p, f.Name, defaultValue.(int), f.Usage)
+		case *int32:
+			cmd.Flags().Int32Var(p, f.Name, defaultValue.(int32), f.Usage)
+		case *int64:
+			cmd.Flags().Int64Var(p, f.Name, defaultValue.(int64), f.Usage)
+		case *[]int:
+			cmd.Flags().IntSliceVar(p, f.Name, defaultValue.([]int), f.Usage)
+		case *[]string:
+			if f.Repeatable {
+				cmd.Flags().StringArrayVar(p, f.Name, defaultValue.([]string), f.Usage)
+			} else {
+				cmd.Flags().StringSliceVar(p, f.Name, defaultValue.([]string), f.Usage)
+			}
+		default:
+			panic(fmt.Sprintf("flag '%s' of %s is bound to field '%s' of unsupported type %s", f.Name, s.product, f.Field, field.Type()))
+		}
+		if f.Kind == Enum && len(f.Values) > 0 {
+			cmd.Flags().SetAnnotation(f.Name, EnumValuesAnnotation, f.Values)
+		}
+		if f.Hidden {
+			cmd.Flags().MarkHidden(f.Name)
+		}
+	}
+}
+
+// Validate returns an error if the value of a changed Enum or Quantity flag is invalid. The files of FileSecret flags
+// are read once when the Helm value is set since they can be read from stdin
+func (s *Set) Validate(flagset *pflag.FlagSet) error {
+	for _, f := range s.flags {
+		flag := flagset.Lookup(f.Name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		value := flag.Value.String()
+		switch f.Kind {
+		case Enum:
+			if !containsFold(f.Values, value) {
+				return fmt.Errorf("--%s must be one of '%s', but got '%s'", f.Name, strings.Join(f.Values, "', '"), value)
+			}
+		case Quantity:
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return fmt.Errorf("--%s must be a quantity such as 10Gi, but got '%s'", f.Name, value)
+			}
+		}
+	}
+	return nil
+}
+
+// SetHelmValue sets the Helm value of the flag from the field of tree, a pointer to the product's FlagTree. It returns
+// false if the flag doesn't have a Helm value and must be handled by the product
+func (s *Set) SetHelmValue(helmValues map[string]interface{}, tree interface{}, name string) (bool, error) {
+	f, ok := s.byName[name]
+	if !ok || len(f.Path) == 0 {
+		return false, nil
+	}
+	value, err := f.helmValue(reflect.ValueOf(tree).Elem().FieldByName(f.Field).Interface())
+	if err != nil {
+		return true, err
+	}
+	util.SetHelmValueInMap(helmValues, f.Path, value)
+	if f.IsSecret() {
+		log.Debugf("flag '%s': setting '%s' to %s", f.Name, strings.Join(f.Path, "."), redacted)
+	} else {
+		log.Debugf("flag '%s': setting '%s' to %v", f.Name, strings.Join(f.Path, "."), value)
+	}
+	return true, nil
+}
+
+// helmValue returns the Helm value of the flag from the value of its field
+func (f *Flag) helmValue(fieldValue interface{}) (interface{}, error) {
+	switch f.Kind {
+	case Bool:
+		value := false
+		switch v := fieldValue.(type) {
+		case bool:
+			value = v
+		case string:
+			value = strings.EqualFold(v, "true")
+		default:
+			return nil, fmt.Errorf("flag '%s' is not bound to a bool or string field", f.Name)
+		}
+		if f.Invert {
+			return !value, nil
+		}
+		return value, nil
+	case Enum:
+		if f.Mapping == nil {
+			return fieldValue, nil
+		}
+		value, ok := f.Mapping[strings.ToUpper(fmt.Sprintf("%v", fieldValue))]
+		if !ok {
+			return nil, fmt.Errorf("--%s must be one of '%s', but got '%v'", f.Name, strings.Join(f.Values, "', '"), fieldValue)
+		}
+		return value, nil
+	case FileSecret:
+		data, err := util.ReadFileData(fmt.Sprintf("%v", fieldValue))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file of --%s due to %+v", f.Name, err)
+		}
+		return data, nil
+	}
+	return fieldValue, nil
+}
+
+// GetUnsupportedByVersion returns the flags that the chart of the product version doesn't support
+func (s *Set) GetUnsupportedByVersion(version string) []string {
+	unsupported := []string{}
+	for _, f := range s.flags {
+		if len(f.MinVersion) > 0 && util.CompareVersions(version, f.MinVersion) < 0 {
+			unsupported = append(unsupported, f.Name)
+		}
+	}
+	return unsupported
+}
+
+// VerifyVersion returns an error if a changed flag is not supported by the chart of the product version
+func (s *Set) VerifyVersion(flagset *pflag.FlagSet, version string) error {
+	for _, f := range s.flags {
+		if len(f.MinVersion) == 0 || util.CompareVersions(version, f.MinVersion) >= 0 {
+			continue
+		}
+		if flag := flagset.Lookup(f.Name); flag == nil || !flag.Changed {
+			continue
+		}
+		// name all the flags of the version, they are usually added together
+		names := []string{}
+		for _, other := range s.flags {
+			if other.MinVersion == f.MinVersion {
+				names = append(names, other.Name)
+			}
+		}
+		return fmt.Errorf("--%s is not supported in %s versions before %s", strings.Join(names, " or --"), s.product, f.MinVersion)
+	}
+	return nil
+}
+
+// Redacted returns the changed flags of the set as --name=value, the values of secret flags are redacted
+func (s *Set) Redacted(flagset *pflag.FlagSet) []string {
+	changed := []string{}
+	for _, f := range s.flags {
+		flag := flagset.Lookup(f.Name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		value := flag.Value.String()
+		if f.Secret {
+			value = redacted
+		}
+		changed = append(changed, fmt.Sprintf("--%s=%s", f.Name, value))
+	}
+	return changed
+}
+
+// containsFold returns true if the values contain the value, ignoring the case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package flags
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFlagTree struct {
+	Version      string
+	Standalone   string
+	Metrics      bool
+	Logging      bool
+	Replicas     int
+	Port         int32
+	ClaimSize    string
+	Expose       string
+	Mode         string
+	Password     string
+	CertFilePath string
+	PullSecrets  []string
+	Volumes      []string
+}
+
+var testFlags = NewSet("Test", []Flag{
+	{Name: "version", Field: "Version", Usage: "Version", Path: []string{"imageTag"}},
+	{Name: "standalone", Field: "Standalone", Kind: Bool, Usage: "Standalone [true|false]", Path: []string{"enableStandalone"}},
+	{Name: "enable-metrics", Field: "Metrics", Kind: Bool, Usage: "Metrics", Path: []string{"metrics", "enabled"}, MinVersion: "2.0.0"},
+	{Name: "disable-logging", Field: "Logging", Kind: Bool, Usage: "Logging", Path: []string{"logging", "enabled"}, Invert: true},
+	{Name: "replicas", Field: "Replicas", Usage: "Replicas", Path: []string{"replicas"}, MinVersion: "2.0.0"},
+	{Name: "port", Field: "Port", Usage: "Port", Path: []string{"port"}, Hidden: true},
+	{Name: "claim-size", Field: "ClaimSize", Kind: Quantity, Usage: "Claim size", Path: []string{"claimSize"}, CreateOnly: true},
+	{Name: "expose", Field: "Expose", Kind: Enum, Usage: "Expose", Path: []string{"expose"}, Values: ExposeServiceValues, Mapping: ExposeServiceMapping},
+	{Name: "mode", Field: "Mode", Kind: Enum, Usage: "Mode", Path: []string{"mode"}, Values: []string{"standalone", "distributed"}, MinVersion: "3.0.0"},
+	{Name: "password", Field: "Password", Usage: "Password", Path: []string{"password"}, Secret: true},
+	{Name: "cert-file-path", Field: "CertFilePath", Kind: FileSecret, Usage: "Certificate", Path: []string{"certificate"}},
+	{Name: "pull-secret-name", Field: "PullSecrets", Usage: "Pull secrets", Path: []string{"imagePullSecrets"}},
+	{Name: "extra-volume", Field: "Volumes", Usage: "Extra volumes", Repeatable: true},
+})
+
+func TestAddToCommand(t *testing.T) {
+	assert := assert.New(t)
+	tree := &testFlagTree{}
+	cmd := &cobra.Command{}
+	testFlags.AddToCommand(cmd, tree, &testFlagTree{Version: "1.0.0", Port: 8443}, true)
+
+	assert.Equal("1.0.0", cmd.Flags().Lookup("version").DefValue)
+	assert.True(cmd.Flags().Lookup("port").Hidden)
+	assert.Equal("stringArray", cmd.Flags().Lookup("extra-volume").Value.Type())
+	assert.Equal("stringSlice", cmd.Flags().Lookup("pull-secret-name").Value.Type())
+	assert.Equal(ExposeServiceValues, cmd.Flags().Lookup("expose").Annotations[EnumValuesAnnotation])
+	assert.Nil(cmd.Flags().Lookup("version").Annotations[EnumValuesAnnotation])
+
+	assert.NoError(cmd.Flags().Parse([]string{"--replicas", "3", "--port", "9000", "--extra-volume", "a,b", "--extra-volume", "c"}))
+	assert.Equal(3, tree.Replicas)
+	assert.Equal(int32(9000), tree.Port)
+	assert.Equal([]string{"a,b", "c"}, tree.Volumes)
+
+	updateCmd := &cobra.Command{}
+	testFlags.AddToCommand(updateCmd, &testFlagTree{}, &testFlagTree{}, false)
+	assert.Nil(updateCmd.Flags().Lookup("claim-size"))
+}
+
+func TestAddToCommandUnknownField(t *testing.T) {
+	set := NewSet("Test", []Flag{{Name: "unknown", Field: "Unknown"}})
+	assert.Panics(t, func() { set.AddToCommand(&cobra.Command{}, &testFlagTree{}, &testFlagTree{}, true) })
+}
+
+func TestNewSetDuplicateFlag(t *testing.T) {
+	assert.Panics(t, func() { NewSet("Test", []Flag{{Name: "version"}, {Name: "version"}}) })
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	var tests = []struct {
+		args        []string
+		expectedErr string
+	}{
+		{args: []string{"--expose", "nodeport", "--mode", "Distributed", "--claim-size", "150Gi"}},
+		{args: []string{"--expose", "ingress"}, expectedErr: "--expose must be one of 'NODEPORT', 'LOADBALANCER', 'OPENSHIFT', 'NONE', but got 'ingress'"},
+		{args: []string{"--claim-size", "large"}, expectedErr: "--claim-size must be a quantity such as 10Gi, but got 'large'"},
+	}
+
+	for _, test := range tests {
+		cmd := &cobra.Command{}
+		testFlags.AddToCommand(cmd, &testFlagTree{}, &testFlagTree{}, true)
+		assert.NoError(cmd.Flags().Parse(test.args))
+		err := testFlags.Validate(cmd.Flags())
+		if len(test.expectedErr) > 0 {
+			assert.EqualError(err, test.expectedErr)
+		} else {
+			assert.NoError(err)
+		}
+	}
+}
+
+func TestSetHelmValue(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "flags")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	certFilePath := filepath.Join(dir, "cert.pem")
+	assert.NoError(ioutil.WriteFile(certFilePath, []byte("CERTIFICATE"), 0600))
+
+	var tests = []struct {
+		flagName    string
+		tree        testFlagTree
+		changedArgs map[string]interface{}
+	}{
+		{flagName: "version", tree: testFlagTree{Version: "1.0.0"}, changedArgs: map[string]interface{}{"imageTag": "1.0.0"}},
+		{flagName: "standalone", tree: testFlagTree{Standalone: "TRUE"}, changedArgs: map[string]interface{}{"enableStandalone": true}},
+		{flagName: "enable-metrics", tree: testFlagTree{Metrics: true}, changedArgs: map[string]interface{}{"metrics": map[string]interface{}{"enabled": true}}},
+		{flagName: "disable-logging", tree: testFlagTree{Logging: true}, changedArgs: map[string]interface{}{"logging": map[string]interface{}{"enabled": false}}},
+		{flagName: "port", tree: testFlagTree{Port: 9000}, changedArgs: map[string]interface{}{"port": int32(9000)}},
+		{flagName: "expose", tree: testFlagTree{Expose: "loadbalancer"}, changedArgs: map[string]interface{}{"expose": "LoadBalancer"}},
+		{flagName: "mode", tree: testFlagTree{Mode: "distributed"}, changedArgs: map[string]interface{}{"mode": "distributed"}},
+		{flagName: "cert-file-path", tree: testFlagTree{CertFilePath: certFilePath}, changedArgs: map[string]interface{}{"certificate": "CERTIFICATE"}},
+		{flagName: "pull-secret-name", tree: testFlagTree{PullSecrets: []string{"a", "b"}}, changedArgs: map[string]interface{}{"imagePullSecrets": []string{"a", "b"}}},
+	}
+
+	for _, test := range tests {
+		args := map[string]interface{}{}
+		found, err := testFlags.SetHelmValue(args, &test.tree, test.flagName)
+		assert.NoError(err)
+		assert.True(found)
+		assert.Equal(test.changedArgs, args, "flag '%s'", test.flagName)
+	}
+
+	// flags without a path are handled by the product
+	args := map[string]interface{}{}
+	found, err := testFlags.SetHelmValue(args, &testFlagTree{Volumes: []string{"a"}}, "extra-volume")
+	assert.NoError(err)
+	assert.False(found)
+	found, err = testFlags.SetHelmValue(args, &testFlagTree{}, "bad-flag")
+	assert.NoError(err)
+	assert.False(found)
+	assert.Equal(map[string]interface{}{}, args)
+
+	_, err = testFlags.SetHelmValue(args, &testFlagTree{CertFilePath: filepath.Join(dir, "missing.pem")}, "cert-file-path")
+	assert.Error(err)
+}
+
+func TestVersionAvailability(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"enable-metrics", "replicas", "mode"}, testFlags.GetUnsupportedByVersion("1.5.0"))
+	assert.Equal([]string{"mode"}, testFlags.GetUnsupportedByVersion("2.0.0"))
+	assert.Equal([]string{}, testFlags.GetUnsupportedByVersion("3.0.0"))
+
+	cmd := &cobra.Command{}
+	testFlags.AddToCommand(cmd, &testFlagTree{}, &testFlagTree{}, true)
+	assert.NoError(cmd.Flags().Parse([]string{"--replicas", "2", "--version", "1.5.0"}))
+	assert.EqualError(testFlags.VerifyVersion(cmd.Flags(), "1.5.0"), "--enable-metrics or --replicas is not supported in Test versions before 2.0.0")
+	assert.NoError(testFlags.VerifyVersion(cmd.Flags(), "2.0.0"))
+}
+
+func TestRedacted(t *testing.T) {
+	assert := assert.New(t)
+	cmd := &cobra.Command{}
+	testFlags.AddToCommand(cmd, &testFlagTree{}, &testFlagTree{}, true)
+	assert.NoError(cmd.Flags().Parse([]string{"--password", "secret", "--version", "1.0.0"}))
+	assert.Equal([]string{"--version=1.0.0", "--password=<redacted>"}, testFlags.Redacted(cmd.Flags()))
+}
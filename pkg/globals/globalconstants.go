@@ -24,6 +24,21 @@ package globals
 // DefaultBusyBoxImage ...
 var DefaultBusyBoxImage = "docker.io/busybox:1.28"
 
+// DefaultKubectlImage ...
+var DefaultKubectlImage = "docker.io/bitnami/kubectl:1.17"
+
+// DefaultSynopsysctlImage ...
+var DefaultSynopsysctlImage = "docker.io/blackducksoftware/synopsysctl:latest"
+
+// DefaultPostgresClientImage ...
+var DefaultPostgresClientImage = "registry.access.redhat.com/rhscl/postgresql-96-rhel7:1"
+
+// DefaultRsyncImage ...
+var DefaultRsyncImage = "docker.io/instrumentisto/rsync-ssh:latest"
+
+// DefaultFioImage ...
+var DefaultFioImage = "docker.io/ljishen/fio:latest"
+
 // AllNamespacesFlag ...
 const AllNamespacesFlag string = "--all-namespaces"
 
@@ -81,7 +81,41 @@ var BDBAChartName = "bdba"
 // BDBAChartRepository ...
 var BDBAChartRepository = ""
 
+/* Coverity Helm Chart Constants */
+
+// CoverityName ...
+var CoverityName = "coverity"
+
+// CoverityVersion ...
+var CoverityVersion = ""
+
+// CoverityChartName ...
+var CoverityChartName = "cnc"
+
+// CoverityChartRepository ...
+var CoverityChartRepository = ""
+
+/* Polaris Helm Chart Constants */
+
+// PolarisName ...
+var PolarisName = "polaris"
+
+// PolarisVersion ...
+var PolarisVersion = ""
+
+// PolarisChartName ...
+var PolarisChartName = "polaris"
+
+// PolarisChartRepository ...
+var PolarisChartRepository = ""
+
 func init() {
+	LoadChartRepository()
+}
+
+// LoadChartRepository loads the chart URLs of the index of the chart repository, and sets the chart location and the
+// version of each product to its latest chart. It is called again once the credentials of the repository are set
+func LoadChartRepository() {
 	IndexChartURLs, _ = util.GetChartURLs(BaseChartRepository, "")
 
 	// Alert
@@ -99,6 +133,16 @@ func init() {
 	BDBAPackageNameSlice := util.ParsePackageName(BDBAChartRepository)
 	BDBAVersion = BDBAPackageNameSlice[1]
 
+	// Coverity (aka Coverity on Kubernetes)
+	CoverityChartRepository, _ = util.GetLatestChartURLForApp(IndexChartURLs, CoverityChartName)
+	coverityPackageNameSlice := util.ParsePackageName(CoverityChartRepository)
+	CoverityVersion = coverityPackageNameSlice[1]
+
+	// Polaris (with Polaris Reporting)
+	PolarisChartRepository, _ = util.GetLatestChartURLForApp(IndexChartURLs, PolarisChartName)
+	polarisPackageNameSlice := util.ParsePackageName(PolarisChartRepository)
+	PolarisVersion = polarisPackageNameSlice[1]
+
 	// OpsSight (aka Black Duck Connector)
 	OpsSightChartRepository, _ = util.GetLatestChartURLForApp(IndexChartURLs, OpsSightChartName)
 	OpsSightPackageNameSlice := util.ParsePackageName(OpsSightChartRepository)
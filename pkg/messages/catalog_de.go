@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package messages
+
+// german is the German catalog
+var german = map[ID]string{
+	MustSpecifySubCommand:          "ein Unterbefehl muss angegeben werden",
+	ArgumentCount:                  "dieser Befehl erwartet {{.Count}} {{if eq .Count 1}}Argument{{else}}Argumente{{end}}, erhalten: {{.Args}}",
+	ArgumentCountUpTo:              "dieser Befehl erwartet höchstens {{.Count}} {{if eq .Count 1}}Argument{{else}}Argumente{{end}}, erhalten: {{.Args}}",
+	ConfirmPrompt:                  "{{.Description}}. Fortfahren? [y/N]: ",
+	ActionNotConfirmed:             "die Aktion wurde nicht bestätigt, mit --yes wird die Bestätigung übersprungen",
+	ConfirmationReadFailed:         "die Bestätigung konnte nicht gelesen werden: {{.Error}}",
+	IgnoredHelmValue:               "der Wert '{{.Path}}' wird vom Chart nicht verwendet und ignoriert",
+	IgnoredHelmValueSuggestion:     "der Wert '{{.Path}}' wird vom Chart nicht verwendet und ignoriert, meinten Sie '{{.Suggestion}}'?",
+	RemediationNone:                "keine",
+	RemediationReviewFindings:      "prüfen Sie die Befunde vor dem Upgrade des Clusters",
+	RemediationNoCompatibleVersion: "keine veröffentlichte Version ist mit Kubernetes {{.KubeVersion}} kompatibel, behalten Sie die Cluster-Version bei oder wenden Sie sich an den Support",
+	RemediationUpgradeFirst:        "aktualisieren Sie vor dem Upgrade des Clusters auf {{.Version}} oder neuer",
+	InvalidFailOn:                  "--fail-on muss 'error' oder 'warning' sein, erhalten: '{{.Value}}'",
+	InvalidClusterType:             "--cluster-type muss '{{.Kubernetes}}' oder '{{.OpenShift}}' sein, erhalten: '{{.Value}}'",
+	CommandFailed:                  "synopsysctl ist fehlgeschlagen: {{.Error}}",
+}
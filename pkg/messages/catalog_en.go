@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package messages
+
+// IDs of the messages of the catalog
+const (
+	// MustSpecifySubCommand is returned by the commands that only group sub-commands
+	MustSpecifySubCommand ID = "MustSpecifySubCommand"
+	// ArgumentCount is returned if a command gets the wrong number of arguments, with the Count and the Args
+	ArgumentCount ID = "ArgumentCount"
+	// ArgumentCountUpTo is returned if a command gets too many optional arguments, with the Count and the Args
+	ArgumentCountUpTo ID = "ArgumentCountUpTo"
+	// ConfirmPrompt asks to confirm the Description of a destructive action
+	ConfirmPrompt ID = "ConfirmPrompt"
+	// ActionNotConfirmed is returned if a destructive action is not confirmed
+	ActionNotConfirmed ID = "ActionNotConfirmed"
+	// ConfirmationReadFailed is returned if the confirmation can't be read, with the Error
+	ConfirmationReadFailed ID = "ConfirmationReadFailed"
+	// IgnoredHelmValue warns about the Path of a value that the chart doesn't use
+	IgnoredHelmValue ID = "IgnoredHelmValue"
+	// IgnoredHelmValueSuggestion warns about the Path of a value that the chart doesn't use, with the Suggestion of a similar value
+	IgnoredHelmValueSuggestion ID = "IgnoredHelmValueSuggestion"
+	// RemediationNone is the remediation of an instance that isn't affected by a cluster upgrade
+	RemediationNone ID = "RemediationNone"
+	// RemediationReviewFindings is the remediation of an instance that has findings of a cluster upgrade
+	RemediationReviewFindings ID = "RemediationReviewFindings"
+	// RemediationNoCompatibleVersion is the remediation of an instance without a version that supports the KubeVersion
+	RemediationNoCompatibleVersion ID = "RemediationNoCompatibleVersion"
+	// RemediationUpgradeFirst is the remediation of an instance that must be upgraded to the Version first
+	RemediationUpgradeFirst ID = "RemediationUpgradeFirst"
+	// InvalidFailOn is returned for an invalid Value of --fail-on
+	InvalidFailOn ID = "InvalidFailOn"
+	// InvalidClusterType is returned for an invalid Value of --cluster-type, with the Kubernetes and OpenShift types
+	InvalidClusterType ID = "InvalidClusterType"
+	// CommandFailed is logged with the Error of a failed command
+	CommandFailed ID = "CommandFailed"
+)
+
+// english is the complete catalog, the other languages fall back to it
+var english = map[ID]string{
+	MustSpecifySubCommand:          "must specify a sub-command",
+	ArgumentCount:                  "this command takes {{.Count}} {{if eq .Count 1}}argument{{else}}arguments{{end}}, but got {{.Args}}",
+	ArgumentCountUpTo:              "this command takes up to {{.Count}} {{if eq .Count 1}}argument{{else}}arguments{{end}}, but got {{.Args}}",
+	ConfirmPrompt:                  "{{.Description}}. Continue? [y/N]: ",
+	ActionNotConfirmed:             "the action was not confirmed, use --yes to skip the confirmation",
+	ConfirmationReadFailed:         "unable to read the confirmation due to {{.Error}}",
+	IgnoredHelmValue:               "value '{{.Path}}' is not used by the chart and will be ignored",
+	IgnoredHelmValueSuggestion:     "value '{{.Path}}' is not used by the chart and will be ignored, did you mean '{{.Suggestion}}'?",
+	RemediationNone:                "none",
+	RemediationReviewFindings:      "review the findings before upgrading the cluster",
+	RemediationNoCompatibleVersion: "no released version is compatible with Kubernetes {{.KubeVersion}}, keep the cluster version or contact support",
+	RemediationUpgradeFirst:        "upgrade to {{.Version}} or later before upgrading the cluster",
+	InvalidFailOn:                  "--fail-on must be 'error' or 'warning', got '{{.Value}}'",
+	InvalidClusterType:             "--cluster-type must be '{{.Kubernetes}}' or '{{.OpenShift}}', got '{{.Value}}'",
+	CommandFailed:                  "synopsyctl failed: {{.Error}}",
+}
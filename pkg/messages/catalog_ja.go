@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package messages
+
+// japanese is the Japanese catalog
+var japanese = map[ID]string{
+	MustSpecifySubCommand:          "サブコマンドを指定してください",
+	ArgumentCount:                  "このコマンドの引数は {{.Count}} 個ですが、{{.Args}} が指定されました",
+	ArgumentCountUpTo:              "このコマンドの引数は最大 {{.Count}} 個ですが、{{.Args}} が指定されました",
+	ConfirmPrompt:                  "{{.Description}}。続行しますか? [y/N]: ",
+	ActionNotConfirmed:             "操作が確認されませんでした。確認を省略するには --yes を指定してください",
+	ConfirmationReadFailed:         "確認の入力を読み取れませんでした: {{.Error}}",
+	IgnoredHelmValue:               "値 '{{.Path}}' はチャートで使用されないため無視されます",
+	IgnoredHelmValueSuggestion:     "値 '{{.Path}}' はチャートで使用されないため無視されます。'{{.Suggestion}}' ではありませんか?",
+	RemediationNone:                "なし",
+	RemediationReviewFindings:      "クラスターをアップグレードする前に検出結果を確認してください",
+	RemediationNoCompatibleVersion: "Kubernetes {{.KubeVersion}} に対応するリリース済みのバージョンはありません。クラスターのバージョンを維持するか、サポートにお問い合わせください",
+	RemediationUpgradeFirst:        "クラスターをアップグレードする前に {{.Version}} 以降にアップグレードしてください",
+	InvalidFailOn:                  "--fail-on には 'error' または 'warning' を指定してください (指定値: '{{.Value}}')",
+	InvalidClusterType:             "--cluster-type には '{{.Kubernetes}}' または '{{.OpenShift}}' を指定してください (指定値: '{{.Value}}')",
+	CommandFailed:                  "synopsysctl が失敗しました: {{.Error}}",
+}
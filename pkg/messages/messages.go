@@ -0,0 +1,133 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package messages contains the catalog of the user facing messages of synopsysctl. The messages are text/template
+// templates that are selected by the language of the user, English is complete and the other languages fall back to it
+package messages
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Languages of the catalog
+const (
+	English  = "en"
+	Japanese = "ja"
+	German   = "de"
+)
+
+// LanguageEnv selects the language of the messages if the --lang flag is not set, the locale environs are used otherwise
+const LanguageEnv = "SYNOPSYSCTL_LANG"
+
+// ID identifies a message of the catalog
+type ID string
+
+// Args are the values of the fields of a message template
+type Args map[string]interface{}
+
+// catalogs contains the message templates of each language
+var catalogs = map[string]map[ID]string{
+	English:  english,
+	Japanese: japanese,
+	German:   german,
+}
+
+// language is the language of the messages
+var language = English
+
+// Languages returns the languages of the catalog
+func Languages() []string {
+	languages := []string{}
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Language returns the language of the messages
+func Language() string {
+	return language
+}
+
+// SetLanguage sets the language of the messages, or the language of the environment if it is empty
+func SetLanguage(lang string) error {
+	if len(lang) == 0 {
+		language = DetectLanguage()
+		return nil
+	}
+	lang = strings.ToLower(lang)
+	if _, ok := catalogs[lang]; !ok {
+		return fmt.Errorf("--lang must be one of '%s', got '%s'", strings.Join(Languages(), "|"), lang)
+	}
+	language = lang
+	return nil
+}
+
+// DetectLanguage returns the language of the SYNOPSYSCTL_LANG environ or of the locale, e.g. 'ja' for LANG=ja_JP.UTF-8,
+// and English if the language isn't in the catalog
+func DetectLanguage() string {
+	for _, env := range []string{LanguageEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if len(value) == 0 {
+			continue
+		}
+		fields := strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+		if len(fields) == 0 {
+			continue
+		}
+		if lang := strings.ToLower(fields[0]); len(catalogs[lang]) > 0 {
+			return lang
+		}
+		return English
+	}
+	return English
+}
+
+// Get returns the message in the language of the user with the fields of its template set from the args
+func Get(id ID, args Args) string {
+	text, ok := catalogs[language][id]
+	if !ok {
+		if text, ok = english[id]; !ok {
+			return string(id)
+		}
+	}
+	tmpl, err := template.New(string(id)).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return text
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, args); err != nil {
+		return text
+	}
+	return message.String()
+}
+
+// Error returns the message in the language of the user as an error
+func Error(id ID, args Args) error {
+	return errors.New(Get(id, args))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package messages
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	assert := assert.New(t)
+	for lang, catalog := range catalogs {
+		for id := range english {
+			_, ok := catalog[id]
+			assert.True(ok, "message '%s' is missing in the '%s' catalog", id, lang)
+		}
+		assert.Equal(len(english), len(catalog), "the '%s' catalog has unknown messages", lang)
+	}
+}
+
+func TestGet(t *testing.T) {
+	assert := assert.New(t)
+	defer SetLanguage(English)
+
+	assert.NoError(SetLanguage(English))
+	assert.Equal("this command takes 1 argument, but got [a b]", Get(ArgumentCount, Args{"Count": 1, "Args": []string{"a", "b"}}))
+	assert.Equal("this command takes 0 arguments, but got [a]", Get(ArgumentCount, Args{"Count": 0, "Args": []string{"a"}}))
+	assert.Equal("must specify a sub-command", Error(MustSpecifySubCommand, nil).Error())
+
+	assert.NoError(SetLanguage("DE"))
+	assert.Equal(German, Language())
+	assert.Equal("dieser Befehl erwartet 2 Argumente, erhalten: [a]", Get(ArgumentCount, Args{"Count": 2, "Args": []string{"a"}}))
+
+	assert.NoError(SetLanguage(Japanese))
+	assert.Equal("なし", Get(RemediationNone, nil))
+	assert.Equal("unknown-message", Get(ID("unknown-message"), nil))
+
+	assert.Error(SetLanguage("fr"))
+	assert.Equal(Japanese, Language())
+}
+
+func TestDetectLanguage(t *testing.T) {
+	assert := assert.New(t)
+	for _, env := range []string{LanguageEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, value)
+		} else {
+			defer os.Unsetenv(env)
+		}
+		os.Unsetenv(env)
+	}
+
+	assert.Equal(English, DetectLanguage())
+	os.Setenv("LANG", "ja_JP.UTF-8")
+	assert.Equal(Japanese, DetectLanguage())
+	os.Setenv("LC_ALL", "fr_FR.UTF-8")
+	assert.Equal(English, DetectLanguage())
+	os.Setenv(LanguageEnv, "de")
+	assert.Equal(German, DetectLanguage())
+}
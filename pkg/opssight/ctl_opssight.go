@@ -23,12 +23,11 @@ package opssight
 
 import (
 	"encoding/json"
-	"fmt"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	opssightapi "github.com/blackducksoftware/synopsysctl/pkg/api/opssight/v1"
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
@@ -156,115 +155,87 @@ func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
 	}
 }
 
-// AddCobraFlagsToCommand adds flags for the Opssight helm chart to the cmd
-func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
-	// [DEV NOTE:] please organize flags in order of importance
-	cmd.Flags().SortFlags = false
-
-	defaults := &FlagTree{}
-	if isCreateCmd {
-		defaults = GetDefaultFlagTree()
-	}
-
+// opssightFlags are the flags of the OpsSight Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var opssightFlags = flags.NewSet("OpsSight", []flags.Flag{
 	// Version
-	cmd.Flags().StringVar(&ctl.flagTree.Version, "version", defaults.Version, "Version of the OpsSight instance\n")
+	{Name: "version", Field: "Version", Usage: "Version of the OpsSight instance\n", Path: []string{"imageTag"}},
 
 	// Memory
-	cmd.Flags().StringVar(&ctl.flagTree.DeploymentResourcesFilePath, "deployment-resources-file-path", defaults.DeploymentResourcesFilePath, "Absolute path to a file containing a list of deployment Resources json structs\n")
+	{Name: "deployment-resources-file-path", Field: "DeploymentResourcesFilePath", Usage: "Absolute path to a file containing a list of deployment Resources json structs\n"},
 
 	// Registry Configuration
-	// cmd.Flags().StringVar(&ctl.flagTree.IsUpstream, "is-upstream", defaults.IsUpstream, "If true, Upstream images and names will be used [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.Registry, "registry", defaults.Registry, "Name of the registry to use for images e.g. docker.io/blackducksoftware")
-	cmd.Flags().StringSliceVar(&ctl.flagTree.PullSecrets, "pull-secret-name", defaults.PullSecrets, "Only if the registry requires authentication\n")
-	// cmd.Flags().StringSliceVar(&ctl.flagTree.ImageRegistries, "image-registries", defaults.ImageRegistries, "List of image registries")
+	{Name: "registry", Field: "Registry", Usage: "Name of the registry to use for images e.g. docker.io/blackducksoftware", Path: []string{"registry"}},
+	{Name: "pull-secret-name", Field: "PullSecrets", Usage: "Only if the registry requires authentication\n", Path: []string{"imagePullSecrets"}},
 
-	cmd.Flags().StringVar(&ctl.flagTree.LogLevel, "log-level", defaults.LogLevel, "Log level of Opssight\n")
+	{Name: "log-level", Field: "LogLevel", Usage: "Log level of Opssight\n", Path: []string{"logLevel"}},
 
 	// Black Duck Configuration
-	if isCreateCmd {
-		// During create users can specify files, otherwise they need to use commands like "./synopsysctl update opssight externalhost"
-		cmd.Flags().StringVar(&ctl.flagTree.BlackduckExternalHostsFilePath, "blackduck-external-hosts-file-path", defaults.BlackduckExternalHostsFilePath, "Absolute path to a file containing a list of Black Duck External Hosts")
-		cmd.Flags().StringVar(&ctl.flagTree.BlackduckSecuredRegistriesFilePath, "blackduck-secured-registries-file-path", defaults.BlackduckSecuredRegistriesFilePath, "Absolute path to a file containing a list of Black Duck Secured Registries")
-	}
-	cmd.Flags().StringVar(&ctl.flagTree.BlackduckTLSVerification, "blackduck-TLS-verification", defaults.BlackduckTLSVerification, "If true, Opssight performs TLS Verification for Black Duck [true|false]\n")
-	// cmd.Flags().IntVar(&ctl.flagTree.BlackduckInitialCount, "blackduck-initial-count", defaults.BlackduckInitialCount, "Initial number of Black Duck instances to create")
-	// cmd.Flags().IntVar(&ctl.flagTree.BlackduckMaxCount, "blackduck-max-count", defaults.BlackduckMaxCount, "Maximum number of Black Duck instances that can be created")
-	// cmd.Flags().StringVar(&ctl.flagTree.BlackduckType, "blackduck-type", defaults.BlackduckType, "Type of Black Duck")
-	// cmd.Flags().StringVar(&ctl.flagTree.BlackduckPassword, "blackduck-password", defaults.BlackduckPassword, "Password to use for all internal Blackduck 'sysadmin' account")
+	// During create users can specify files, otherwise they need to use commands like "./synopsysctl update opssight externalhost"
+	{Name: "blackduck-external-hosts-file-path", Field: "BlackduckExternalHostsFilePath", Usage: "Absolute path to a file containing a list of Black Duck External Hosts", CreateOnly: true},
+	{Name: "blackduck-secured-registries-file-path", Field: "BlackduckSecuredRegistriesFilePath", Usage: "Absolute path to a file containing a list of Black Duck Secured Registries", CreateOnly: true},
+	{Name: "blackduck-TLS-verification", Field: "BlackduckTLSVerification", Kind: flags.Bool, Usage: "If true, Opssight performs TLS Verification for Black Duck [true|false]\n", Path: []string{"blackduck", "tlsVerification"}},
 
 	// Metrics
-	cmd.Flags().StringVar(&ctl.flagTree.EnableMetrics, "enable-metrics", defaults.EnableMetrics, "If true, Opssight records Prometheus Metrics [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PrometheusExpose, "expose-metrics", defaults.PrometheusExpose, "Type of service of Opssight's Prometheus Metrics [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n")
+	{Name: "enable-metrics", Field: "EnableMetrics", Kind: flags.Bool, Usage: "If true, Opssight records Prometheus Metrics [true|false]", Path: []string{"prometheus", "enabled"}},
+	{Name: "expose-metrics", Field: "PrometheusExpose", Kind: flags.Enum, Usage: "Type of service of Opssight's Prometheus Metrics [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n", Path: []string{"prometheus", "expose"}, Values: flags.ExposeServiceValues, Mapping: flags.ExposeServiceMapping},
 
 	// Core
-	cmd.Flags().StringVar(&ctl.flagTree.PerceptorExpose, "opssight-core-expose", defaults.PerceptorExpose, "Type of service for Opssight's core model [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceptorCheckForStalledScansPauseHours, "opssight-core-check-scan-hours", defaults.PerceptorCheckForStalledScansPauseHours, "Hours Opssight's Core waits between checking for scans")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceptorStalledScanClientTimeoutHours, "opssight-core-scan-client-timeout-hours", defaults.PerceptorStalledScanClientTimeoutHours, "Hours until Opssight's Core stops checking for scans")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceptorModelMetricsPauseSeconds, "opssight-core-metrics-pause-seconds", defaults.PerceptorModelMetricsPauseSeconds, "Core metrics pause in seconds")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceptorUnknownImagePauseMilliseconds, "opssight-core-unknown-image-pause-milliseconds", defaults.PerceptorUnknownImagePauseMilliseconds, "Opssight Core's unknown image pause in milliseconds")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceptorClientTimeoutMilliseconds, "opssight-core-client-timeout-milliseconds", defaults.PerceptorClientTimeoutMilliseconds, "Seconds for Opssight Core's timeout for Black Duck Scan Client\n")
+	{Name: "opssight-core-expose", Field: "PerceptorExpose", Kind: flags.Enum, Usage: "Type of service for Opssight's core model [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]", Path: []string{"core", "expose"}, Values: flags.ExposeServiceValues, Mapping: flags.ExposeServiceMapping},
+	{Name: "opssight-core-check-scan-hours", Field: "PerceptorCheckForStalledScansPauseHours", Usage: "Hours Opssight's Core waits between checking for scans", Path: []string{"core", "checkForStalledScansPauseHours"}},
+	{Name: "opssight-core-scan-client-timeout-hours", Field: "PerceptorStalledScanClientTimeoutHours", Usage: "Hours until Opssight's Core stops checking for scans", Path: []string{"core", "stalledScanClientTimeoutHours"}},
+	{Name: "opssight-core-metrics-pause-seconds", Field: "PerceptorModelMetricsPauseSeconds", Usage: "Core metrics pause in seconds", Path: []string{"core", "modelMetricsPauseSeconds"}},
+	{Name: "opssight-core-unknown-image-pause-milliseconds", Field: "PerceptorUnknownImagePauseMilliseconds", Usage: "Opssight Core's unknown image pause in milliseconds", Path: []string{"core", "unknownImagePauseMilliseconds"}},
+	{Name: "opssight-core-client-timeout-milliseconds", Field: "PerceptorClientTimeoutMilliseconds", Usage: "Seconds for Opssight Core's timeout for Black Duck Scan Client\n", Path: []string{"core", "clientTimeoutMilliseconds"}},
 
 	// Processor
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverTLSCertificatePath, "processor-TLS-certificate-path", defaults.PerceiverTLSCertificatePath, "Accepts certificate file to start webhook receiver with TLS enabled, works in conjunction with Quay and Artifactory processors")
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverTLSKeyPath, "processor-TLS-key-path", defaults.PerceiverTLSKeyPath, "Accepts key file to sign the TLS certificate, works in conjunction with Quay and Artifactory processors")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceiverAnnotationIntervalSeconds, "processor-annotation-interval-seconds", defaults.PerceiverAnnotationIntervalSeconds, "Refresh interval to get latest scan results and apply to Pods and Images")
-	cmd.Flags().IntVar(&ctl.flagTree.PerceiverDumpIntervalMinutes, "processor-dump-interval-minutes", defaults.PerceiverDumpIntervalMinutes, "Minutes Image Processor and Pod Processor wait between creating dumps of data/metrics\n")
+	{Name: "processor-TLS-certificate-path", Field: "PerceiverTLSCertificatePath", Kind: flags.FileSecret, Usage: "Accepts certificate file to start webhook receiver with TLS enabled, works in conjunction with Quay and Artifactory processors", Path: []string{"processor", "certificate"}},
+	{Name: "processor-TLS-key-path", Field: "PerceiverTLSKeyPath", Kind: flags.FileSecret, Usage: "Accepts key file to sign the TLS certificate, works in conjunction with Quay and Artifactory processors", Path: []string{"processor", "certificateKey"}},
+	{Name: "processor-annotation-interval-seconds", Field: "PerceiverAnnotationIntervalSeconds", Usage: "Refresh interval to get latest scan results and apply to Pods and Images", Path: []string{"processor", "annotationIntervalSeconds"}},
+	{Name: "processor-dump-interval-minutes", Field: "PerceiverDumpIntervalMinutes", Usage: "Minutes Image Processor and Pod Processor wait between creating dumps of data/metrics\n", Path: []string{"processor", "dumpIntervalMinutes"}},
 
 	// Pod Processor
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverEnablePodPerceiver, "enable-pod-processor", defaults.PerceiverEnablePodPerceiver, "If true, Pod Processor discovers pods for scanning [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverPodPerceiverNamespaceFilter, "pod-processor-namespace-filter", defaults.PerceiverPodPerceiverNamespaceFilter, "Pod Processor's filter to scan pods by their namespace\n")
+	{Name: "enable-pod-processor", Field: "PerceiverEnablePodPerceiver", Kind: flags.Bool, Usage: "If true, Pod Processor discovers pods for scanning [true|false]", Path: []string{"podProcessor", "enabled"}},
+	{Name: "pod-processor-namespace-filter", Field: "PerceiverPodPerceiverNamespaceFilter", Usage: "Pod Processor's filter to scan pods by their namespace\n", Path: []string{"podProcessor", "nameSpaceFilter"}},
 
 	// Scanner
-	cmd.Flags().IntVar(&ctl.flagTree.ScannerPodScannerClientTimeoutSeconds, "scanner-client-timeout-seconds", defaults.ScannerPodScannerClientTimeoutSeconds, "Seconds before Scanner times out for Black Duck's Scan Client")
-	cmd.Flags().IntVar(&ctl.flagTree.ScannerPodReplicaCount, "scannerpod-replica-count", defaults.ScannerPodReplicaCount, "Number of Containers for scanning")
-	cmd.Flags().StringVar(&ctl.flagTree.ScannerPodImageDirectory, "scannerpod-image-directory", defaults.ScannerPodImageDirectory, "Directory in Scanner's pod where images are stored for scanning\n")
+	{Name: "scanner-client-timeout-seconds", Field: "ScannerPodScannerClientTimeoutSeconds", Usage: "Seconds before Scanner times out for Black Duck's Scan Client", Path: []string{"scanner", "blackDuckClientTimeoutSeconds"}},
+	{Name: "scannerpod-replica-count", Field: "ScannerPodReplicaCount", Usage: "Number of Containers for scanning", Path: []string{"scanner", "replicas"}},
+	{Name: "scannerpod-image-directory", Field: "ScannerPodImageDirectory", Usage: "Directory in Scanner's pod where images are stored for scanning\n", Path: []string{"scanner", "imageDirectory"}},
 
 	// Image Getter
-	// cmd.Flags().StringVar(&ctl.flagTree.ScannerPodImageFacadeInternalRegistriesFilePath, "image-getter-secure-registries-file-path", defaults.ScannerPodImageFacadeInternalRegistriesFilePath, "Absolute path to a file for secure docker registries credentials to pull the images for scan")
-	cmd.Flags().StringVar(&ctl.flagTree.ScannerPodImageFacadeImagePullerType, "image-getter-image-puller-type", defaults.ScannerPodImageFacadeImagePullerType, "Type of Image Getter's Image Puller [docker|skopeo]\n")
+	{Name: "image-getter-image-puller-type", Field: "ScannerPodImageFacadeImagePullerType", Kind: flags.Enum, Usage: "Type of Image Getter's Image Puller [docker|skopeo]\n", Path: []string{"imageGetter", "imagePullerType"}, Values: []string{"docker", "skopeo"}},
 
 	// Image Processor
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverEnableImagePerceiver, "enable-image-processor", defaults.PerceiverEnableImagePerceiver, "If true, Image Processor discovers images for scanning [true|false]\n")
+	{Name: "enable-image-processor", Field: "PerceiverEnableImagePerceiver", Kind: flags.Bool, Usage: "If true, Image Processor discovers images for scanning [true|false]\n", Path: []string{"imageProcessor", "enabled"}},
 
 	// Quay Processor
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverEnableQuayPerceiver, "enable-quay-processor", defaults.PerceiverEnableQuayPerceiver, "If true, Quay Processor discovers quay images for scanning [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverQuayExpose, "expose-quay-processor", defaults.PerceiverQuayExpose, "Type of service for Quay processor [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n")
+	{Name: "enable-quay-processor", Field: "PerceiverEnableQuayPerceiver", Kind: flags.Bool, Usage: "If true, Quay Processor discovers quay images for scanning [true|false]", Path: []string{"quayProcessor", "enabled"}},
+	{Name: "expose-quay-processor", Field: "PerceiverQuayExpose", Kind: flags.Enum, Usage: "Type of service for Quay processor [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n", Path: []string{"quayProcessor", "expose"}, Values: flags.ExposeServiceValues, Mapping: flags.ExposeServiceMapping},
 
 	// Artifactory Processor
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverEnableArtifactoryPerceiver, "enable-artifactory-processor", defaults.PerceiverEnableArtifactoryPerceiver, "If true, Artifactory Processor discovers artifactory images for scanning [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverEnableArtifactoryPerceiverDumper, "enable-artifactory-processor-dumper", defaults.PerceiverEnableArtifactoryPerceiverDumper, "If true, Artifactory Processor dumps all docker images in an artifactory instance for scanning [true|false]")
-	cmd.Flags().StringVar(&ctl.flagTree.PerceiverArtifactoryExpose, "expose-artifactory-processor", defaults.PerceiverArtifactoryExpose, "Type of service for Artifactory processor [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
+	{Name: "enable-artifactory-processor", Field: "PerceiverEnableArtifactoryPerceiver", Kind: flags.Bool, Usage: "If true, Artifactory Processor discovers artifactory images for scanning [true|false]", Path: []string{"artifactoryProcessor", "enabled"}},
+	{Name: "enable-artifactory-processor-dumper", Field: "PerceiverEnableArtifactoryPerceiverDumper", Kind: flags.Bool, Usage: "If true, Artifactory Processor dumps all docker images in an artifactory instance for scanning [true|false]", Path: []string{"artifactoryProcessor", "dumper"}},
+	{Name: "expose-artifactory-processor", Field: "PerceiverArtifactoryExpose", Kind: flags.Enum, Usage: "Type of service for Artifactory processor [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]", Path: []string{"artifactoryProcessor", "expose"}, Values: flags.ExposeServiceValues, Mapping: flags.ExposeServiceMapping},
+})
+
+// AddCobraFlagsToCommand adds flags for the Opssight helm chart to the cmd
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
+	}
+
+	opssightFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
 }
 
 // CheckValuesFromFlags returns an error if a value stored in the struct will not be able to be
 // used in the opssightSpec
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
-	if FlagWasSet(flagset, "opssight-core-expose") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.PerceptorExpose)
-		if !isValid {
-			return fmt.Errorf("opssight core expose must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
-		}
-	}
-	if FlagWasSet(flagset, "expose-metrics") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.PrometheusExpose)
-		if !isValid {
-			return fmt.Errorf("expose metrics must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
-		}
-	}
-	if FlagWasSet(flagset, "expose-artifactory-processor") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.PerceiverArtifactoryExpose)
-		if !isValid {
-			return fmt.Errorf("expose metrics must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
-		}
-	}
-	if FlagWasSet(flagset, "expose-quay-processor") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.PerceiverQuayExpose)
-		if !isValid {
-			return fmt.Errorf("expose metrics must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
-		}
-	}
 	// TODO - add check for log level format
-	return nil
+	return opssightFlags.Validate(flagset)
 }
 
 // FlagWasSet returns true if a flag was changed and it exists, otherwise it returns false
@@ -287,21 +258,13 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 		if f.Changed {
 			log.Debugf("flag '%s': CHANGED", f.Name)
 			switch f.Name {
-			case "version":
-				util.SetHelmValueInMap(ctl.args, []string{"imageTag"}, ctl.flagTree.Version)
 			case "deployment-resources-file-path":
 				util.GetDeploymentResources(ctl.flagTree.DeploymentResourcesFilePath, ctl.args, "heapMaxMemory") // OpsSight doens't currently use heapMaxMemory
 			// case "is-upstream":
 			// 	isUpstream := strings.ToUpper(ctl.flagTree.IsUpstream) == "TRUE"
 			// 	util.SetHelmValueInMap(ctl.args, []string{"isUpstream"}, isUpstream)
-			case "registry":
-				util.SetHelmValueInMap(ctl.args, []string{"registry"}, ctl.flagTree.Registry)
-			case "pull-secret-name":
-				util.SetHelmValueInMap(ctl.args, []string{"imagePullSecrets"}, ctl.flagTree.PullSecrets)
 			// case "image-registries":
 			// 	util.SetHelmValueInMap(ctl.args, []string{"imageRegistries"}, ctl.flagTree.ImageRegistries)
-			case "log-level":
-				util.SetHelmValueInMap(ctl.args, []string{"logLevel"}, ctl.flagTree.LogLevel)
 			case "blackduck-external-hosts-file-path":
 				data, err := util.ReadFileData(ctl.flagTree.BlackduckExternalHostsFilePath)
 				if err != nil {
@@ -354,9 +317,6 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 					currSRs = append(currSRs, reg)
 				}
 				util.SetHelmValueInMap(ctl.args, []string{"securedRegistries"}, currSRs)
-			case "blackduck-TLS-verification":
-				enableTLSVerification := strings.ToUpper(ctl.flagTree.BlackduckTLSVerification) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"blackduck", "tlsVerification"}, enableTLSVerification)
 			// case "blackduck-initial-count":
 			// 	util.SetHelmValueInMap(ctl.args, []string{"blackduck", "initialCount"}, ctl.flagTree.BlackduckInitialCount)
 			// case "blackduck-max-count":
@@ -365,68 +325,6 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 			// 	util.SetHelmValueInMap(ctl.args, []string{"blackduck", "blackduckSpec", "type"}, ctl.flagTree.BlackduckType)
 			// case "blackduck-password":
 			// 	util.SetHelmValueInMap(ctl.args, []string{"blackduck", "blackduckPassword"}, crddefaults.Base64Encode([]byte(ctl.flagTree.BlackduckPassword)))
-			case "enable-metrics":
-				enableMetrics := strings.ToUpper(ctl.flagTree.EnableMetrics) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"prometheus", "enabled"}, enableMetrics)
-			case "expose-metrics":
-				switch strings.ToUpper(ctl.flagTree.PrometheusExpose) {
-				case util.NODEPORT:
-					util.SetHelmValueInMap(ctl.args, []string{"prometheus", "expose"}, "NodePort")
-				case util.LOADBALANCER:
-					util.SetHelmValueInMap(ctl.args, []string{"prometheus", "expose"}, "LoadBalancer")
-				case util.OPENSHIFT:
-					util.SetHelmValueInMap(ctl.args, []string{"prometheus", "expose"}, "OpenShift")
-				default:
-					util.SetHelmValueInMap(ctl.args, []string{"prometheus", "expose"}, "None")
-				}
-			case "opssight-core-expose":
-				switch strings.ToUpper(ctl.flagTree.PerceptorExpose) {
-				case util.NODEPORT:
-					util.SetHelmValueInMap(ctl.args, []string{"core", "expose"}, "NodePort")
-				case util.LOADBALANCER:
-					util.SetHelmValueInMap(ctl.args, []string{"core", "expose"}, "LoadBalancer")
-				case util.OPENSHIFT:
-					util.SetHelmValueInMap(ctl.args, []string{"core", "expose"}, "OpenShift")
-				default:
-					util.SetHelmValueInMap(ctl.args, []string{"core", "expose"}, "None")
-				}
-			case "opssight-core-check-scan-hours":
-				util.SetHelmValueInMap(ctl.args, []string{"core", "checkForStalledScansPauseHours"}, ctl.flagTree.PerceptorCheckForStalledScansPauseHours)
-			case "opssight-core-scan-client-timeout-hours":
-				util.SetHelmValueInMap(ctl.args, []string{"core", "stalledScanClientTimeoutHours"}, ctl.flagTree.PerceptorStalledScanClientTimeoutHours)
-			case "opssight-core-metrics-pause-seconds":
-				util.SetHelmValueInMap(ctl.args, []string{"core", "modelMetricsPauseSeconds"}, ctl.flagTree.PerceptorModelMetricsPauseSeconds)
-			case "opssight-core-unknown-image-pause-milliseconds":
-				util.SetHelmValueInMap(ctl.args, []string{"core", "unknownImagePauseMilliseconds"}, ctl.flagTree.PerceptorUnknownImagePauseMilliseconds)
-			case "opssight-core-client-timeout-milliseconds":
-				util.SetHelmValueInMap(ctl.args, []string{"core", "clientTimeoutMilliseconds"}, ctl.flagTree.PerceptorClientTimeoutMilliseconds)
-			case "processor-TLS-certificate-path":
-				data, err := util.ReadFileData(ctl.flagTree.PerceiverTLSCertificatePath)
-				if err != nil {
-					log.Errorf("failed to read certificate file: %+v", err)
-				}
-				util.SetHelmValueInMap(ctl.args, []string{"processor", "certificate"}, data)
-			case "processor-TLS-key-path":
-				data, err := util.ReadFileData(ctl.flagTree.PerceiverTLSKeyPath)
-				if err != nil {
-					log.Errorf("failed to read certificate file: %+v", err)
-				}
-				util.SetHelmValueInMap(ctl.args, []string{"processor", "certificateKey"}, data)
-			case "processor-annotation-interval-seconds":
-				util.SetHelmValueInMap(ctl.args, []string{"processor", "annotationIntervalSeconds"}, ctl.flagTree.PerceiverAnnotationIntervalSeconds)
-			case "processor-dump-interval-minutes":
-				util.SetHelmValueInMap(ctl.args, []string{"processor", "dumpIntervalMinutes"}, ctl.flagTree.PerceiverDumpIntervalMinutes)
-			case "enable-pod-processor":
-				enablePodPerceiver := strings.ToUpper(ctl.flagTree.PerceiverEnablePodPerceiver) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"podProcessor", "enabled"}, enablePodPerceiver)
-			case "pod-processor-namespace-filter":
-				util.SetHelmValueInMap(ctl.args, []string{"podProcessor", "nameSpaceFilter"}, ctl.flagTree.PerceiverPodPerceiverNamespaceFilter)
-			case "scanner-client-timeout-seconds":
-				util.SetHelmValueInMap(ctl.args, []string{"scanner", "blackDuckClientTimeoutSeconds"}, ctl.flagTree.ScannerPodScannerClientTimeoutSeconds)
-			case "scannerpod-replica-count":
-				util.SetHelmValueInMap(ctl.args, []string{"scanner", "replicas"}, ctl.flagTree.ScannerPodReplicaCount)
-			case "scannerpod-image-directory":
-				util.SetHelmValueInMap(ctl.args, []string{"scanner", "imageDirectory"}, ctl.flagTree.ScannerPodImageDirectory)
 			// case "image-getter-secure-registries-file-path":
 			// 	data, err := util.ReadFileData(ctl.flagTree.ScannerPodImageFacadeInternalRegistriesFilePath)
 			// 	if err != nil {
@@ -438,44 +336,13 @@ func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pf
 			// 		log.Fatalf("failed to unmarshal internal registries: %+v", err)
 			// 	}
 			// 	util.SetHelmValueInMap(ctl.args, []string{"imageGetter"}, registryStructs)
-			case "image-getter-image-puller-type":
-				util.SetHelmValueInMap(ctl.args, []string{"imageGetter", "imagePullerType"}, ctl.flagTree.ScannerPodImageFacadeImagePullerType)
-			case "enable-image-processor":
-				enableImagePerceiver := strings.ToUpper(ctl.flagTree.PerceiverEnableImagePerceiver) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"imageProcessor", "enabled"}, enableImagePerceiver)
-			case "enable-quay-processor":
-				enableQuayPerceiver := strings.ToUpper(ctl.flagTree.PerceiverEnableQuayPerceiver) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"quayProcessor", "enabled"}, enableQuayPerceiver)
-			case "expose-quay-processor":
-				switch strings.ToUpper(ctl.flagTree.PerceiverQuayExpose) {
-				case util.NODEPORT:
-					util.SetHelmValueInMap(ctl.args, []string{"quayProcessor", "expose"}, "NodePort")
-				case util.LOADBALANCER:
-					util.SetHelmValueInMap(ctl.args, []string{"quayProcessor", "expose"}, "LoadBalancer")
-				case util.OPENSHIFT:
-					util.SetHelmValueInMap(ctl.args, []string{"quayProcessor", "expose"}, "OpenShift")
-				default:
-					util.SetHelmValueInMap(ctl.args, []string{"quayProcessor", "expose"}, "None")
-				}
-			case "enable-artifactory-processor":
-				enableArtifactoryPerceiver := strings.ToUpper(ctl.flagTree.PerceiverEnableArtifactoryPerceiver) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "enabled"}, enableArtifactoryPerceiver)
-			case "enable-artifactory-processor-dumper":
-				enableArtifactoryPerceiverDumper := strings.ToUpper(ctl.flagTree.PerceiverEnableArtifactoryPerceiverDumper) == "TRUE"
-				util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "dumper"}, enableArtifactoryPerceiverDumper)
-			case "expose-artifactory-processor":
-				switch strings.ToUpper(ctl.flagTree.PerceiverArtifactoryExpose) {
-				case util.NODEPORT:
-					util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "expose"}, "NodePort")
-				case util.LOADBALANCER:
-					util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "expose"}, "LoadBalancer")
-				case util.OPENSHIFT:
-					util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "expose"}, "OpenShift")
-				default:
-					util.SetHelmValueInMap(ctl.args, []string{"artifactoryProcessor", "expose"}, "None")
-				}
 			default:
-				log.Debugf("flag '%s': NOT FOUND", f.Name)
+				if found, err := opssightFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+					log.Errorf("%+v", err)
+					isErrorExist = true
+				} else if !found {
+					log.Debugf("flag '%s': NOT FOUND", f.Name)
+				}
 			}
 		} else {
 			log.Debugf("flag '%s': UNCHANGED", f.Name)
@@ -0,0 +1,273 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package polaris
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// MinVersion is the first Polaris version whose chart synopsysctl can create
+const MinVersion = "2020.03"
+
+// HelmValuesFromCobraFlags is a type for converting synopsysctl flags
+// to Helm Chart fields and values
+// args: map of helm chart field to value
+type HelmValuesFromCobraFlags struct {
+	args     map[string]interface{}
+	flagTree FlagTree
+}
+
+// FlagTree is a set of fields needed to configure the Polaris Helm Chart
+type FlagTree struct {
+	Version string `json:"version"`
+
+	// Images
+	ImageRegistry   string `json:"imageRegistry"`
+	ImagePullSecret string `json:"imagePullSecret"`
+
+	// Environment
+	FQDN            string `json:"fqdn"`
+	LicenseFilePath string `json:"licenseFilePath"`
+
+	// Organization
+	OrganizationName       string `json:"organizationName"`
+	OrganizationAdmin      string `json:"organizationAdmin"`
+	OrganizationAdminEmail string `json:"organizationAdminEmail"`
+
+	// SMTP
+	SMTPHost        string `json:"smtpHost"`
+	SMTPPort        int    `json:"smtpPort"`
+	SMTPUsername    string `json:"smtpUsername"`
+	SMTPPassword    string `json:"smtpPassword"`
+	SMTPSenderEmail string `json:"smtpSenderEmail"`
+
+	// PostgreSQL
+	PostgresHost     string `json:"postgresHost"`
+	PostgresPort     int    `json:"postgresPort"`
+	PostgresUsername string `json:"postgresUsername"`
+	PostgresPassword string `json:"postgresPassword"`
+	PostgresSSLMode  string `json:"postgresSSLMode"`
+	PostgresSize     string `json:"postgresSize"`
+
+	// Storage
+	StorageClass     string `json:"storageClass"`
+	UploadServerSize string `json:"uploadServerSize"`
+	EventstoreSize   string `json:"eventstoreSize"`
+	MongoDBSize      string `json:"mongoDBSize"`
+
+	// Expose
+	ExposeService string `json:"exposeService"`
+
+	// Polaris Reporting
+	ReportingEnabled     bool   `json:"reportingEnabled"`
+	ReportingStorageSize string `json:"reportingStorageSize"`
+}
+
+// DefaultFlagTree ...
+// [Dev Note]: These should match the Helm Chart's Values.yaml
+var DefaultFlagTree = FlagTree{
+	// Version
+	Version: globals.PolarisVersion,
+	// SMTP
+	SMTPPort: 2525,
+	// PostgreSQL
+	PostgresPort:     5432,
+	PostgresUsername: "postgres",
+	PostgresSSLMode:  "disable",
+	PostgresSize:     "50Gi",
+	// Storage
+	UploadServerSize: "100Gi",
+	EventstoreSize:   "50Gi",
+	MongoDBSize:      "20Gi",
+	// Expose
+	ExposeService: util.NONE,
+	// Polaris Reporting
+	ReportingEnabled:     false,
+	ReportingStorageSize: "5Gi",
+}
+
+// GetDefaultFlagTree ...
+func GetDefaultFlagTree() *FlagTree {
+	return &DefaultFlagTree
+}
+
+// NewHelmValuesFromCobraFlags returns an initialized HelmValuesFromCobraFlags
+func NewHelmValuesFromCobraFlags() *HelmValuesFromCobraFlags {
+	return &HelmValuesFromCobraFlags{
+		args:     make(map[string]interface{}, 0),
+		flagTree: FlagTree{},
+	}
+}
+
+// GetArgs returns the map of helm chart fields to values
+func (ctl *HelmValuesFromCobraFlags) GetArgs() map[string]interface{} {
+	return ctl.args
+}
+
+// SetArgs set the map to values
+func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
+	for key, value := range args {
+		ctl.args[key] = value
+	}
+}
+
+// polarisFlags are the flags of the Polaris Helm chart
+// [DEV NOTE:] please organize flags in order of importance
+var polarisFlags = flags.NewSet("Polaris", []flags.Flag{
+	{Name: "version", Field: "Version", Usage: "Version of Polaris you want to install\n"},
+
+	// Images
+	{Name: "image-registry", Field: "ImageRegistry", Usage: "Registry of the Polaris images, e.g. a mirror for an air gapped cluster", Path: []string{"imageRegistry"}},
+	{Name: "image-pull-secret", Field: "ImagePullSecret", Usage: "Secret to pull the Polaris images\n", Path: []string{"imagePullSecret"}},
+
+	// Environment
+	{Name: "fqdn", Field: "FQDN", Usage: "Fully qualified domain name of the Polaris user interface", Path: []string{"environment", "fqdn"}},
+	{Name: "license-file-path", Field: "LicenseFilePath", Kind: flags.FileSecret, Usage: "Path to the Polaris license file\n", Path: []string{"license", "data"}},
+
+	// Organization
+	{Name: "organization-name", Field: "OrganizationName", Usage: "Name of the organization of the Polaris users", Path: []string{"organization", "name"}, CreateOnly: true},
+	{Name: "organization-admin-name", Field: "OrganizationAdmin", Usage: "Name of the administrator of the organization", Path: []string{"organization", "admin", "name"}, CreateOnly: true},
+	{Name: "organization-admin-email", Field: "OrganizationAdminEmail", Usage: "Email address of the administrator of the organization\n", Path: []string{"organization", "admin", "email"}, CreateOnly: true},
+
+	// SMTP
+	{Name: "smtp-host", Field: "SMTPHost", Usage: "Host of the SMTP server that sends the emails of Polaris", Path: []string{"smtp", "host"}},
+	{Name: "smtp-port", Field: "SMTPPort", Usage: "Port of the SMTP server", Path: []string{"smtp", "port"}},
+	{Name: "smtp-username", Field: "SMTPUsername", Usage: "Username of the SMTP server", Path: []string{"smtp", "username"}},
+	{Name: "smtp-password", Field: "SMTPPassword", Usage: "Password of the SMTP server", Path: []string{"smtp", "password"}, Secret: true},
+	{Name: "smtp-sender-email", Field: "SMTPSenderEmail", Usage: "Email address that sends the emails of Polaris\n", Path: []string{"smtp", "senderEmail"}},
+
+	// PostgreSQL
+	{Name: "postgres-host", Field: "PostgresHost", Usage: "Hostname of an external PostgreSQL database (default the PostgreSQL database of the chart)", Path: []string{"postgres", "host"}},
+	{Name: "postgres-port", Field: "PostgresPort", Usage: "Port of the PostgreSQL database", Path: []string{"postgres", "port"}},
+	{Name: "postgres-username", Field: "PostgresUsername", Usage: "User of the PostgreSQL database", Path: []string{"postgres", "username"}},
+	{Name: "postgres-password", Field: "PostgresPassword", Usage: "Password of the PostgreSQL user", Path: []string{"postgres", "password"}, Secret: true},
+	{Name: "postgres-ssl-mode", Field: "PostgresSSLMode", Kind: flags.Enum, Usage: "PostgreSQL SSL mode [disable|require|verify-ca|verify-full]", Path: []string{"postgres", "sslMode"}, Values: []string{"disable", "require", "verify-ca", "verify-full"}},
+	{Name: "postgres-size", Field: "PostgresSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of the PostgreSQL database of the chart\n", Path: []string{"postgres", "size"}},
+
+	// Storage
+	{Name: "storage-class", Field: "StorageClass", Usage: "Storage class of the persistent volume claims", Path: []string{"storageClass"}},
+	{Name: "uploadserver-size", Field: "UploadServerSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of the upload server", Path: []string{"uploadServer", "size"}},
+	{Name: "eventstore-size", Field: "EventstoreSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of the event store", Path: []string{"eventstore", "size"}},
+	{Name: "mongodb-size", Field: "MongoDBSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of MongoDB\n", Path: []string{"mongodb", "size"}},
+
+	// Expose
+	{Name: "expose-ui", Field: "ExposeService", Kind: flags.Enum, Usage: "Service type of Polaris' user interface, the chart's ingress serves the FQDN otherwise [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]\n", Values: flags.ExposeServiceValues},
+
+	// Polaris Reporting
+	{Name: "enable-reporting", Field: "ReportingEnabled", Kind: flags.Bool, Usage: "Enable Polaris Reporting", Path: []string{"reporting", "enabled"}, MinVersion: "2020.04"},
+	{Name: "reporting-storage-size", Field: "ReportingStorageSize", Kind: flags.Quantity, Usage: "Persistent volume claim size of the reports of Polaris Reporting", Path: []string{"reporting", "size"}, MinVersion: "2020.04"},
+})
+
+// AddCobraFlagsToCommand adds flags for the Polaris helm chart to the cmd
+func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command, isCreateCmd bool) {
+	cmd.Flags().SortFlags = false
+
+	defaults := &FlagTree{}
+	if isCreateCmd {
+		defaults = GetDefaultFlagTree()
+	}
+
+	polarisFlags.AddToCommand(cmd, &ctl.flagTree, defaults, isCreateCmd)
+}
+
+// CheckValuesFromFlags returns an error if a value set by a flag is invalid
+func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if err := polarisFlags.Validate(flagset); err != nil {
+		return err
+	}
+	if flagWasSet(flagset, "fqdn") && len(ctl.flagTree.FQDN) == 0 {
+		return fmt.Errorf("--fqdn must not be empty")
+	}
+	if flagWasSet(flagset, "reporting-storage-size") && !ctl.isReportingEnabled(flagset) {
+		return fmt.Errorf("reporting-storage-size requires enable-reporting")
+	}
+	return nil
+}
+
+// isReportingEnabled returns true if Polaris Reporting is enabled by the flag, or otherwise by the values of the release
+func (ctl *HelmValuesFromCobraFlags) isReportingEnabled(flagset *pflag.FlagSet) bool {
+	if flagWasSet(flagset, "enable-reporting") {
+		return ctl.flagTree.ReportingEnabled
+	}
+	enabled, _ := util.GetHelmValueFromMap(ctl.args, []string{"reporting", "enabled"}).(bool)
+	return enabled
+}
+
+// GetFlagsUnsupportedByVersion returns the flags that the chart of the Polaris version doesn't support
+func GetFlagsUnsupportedByVersion(version string) []string {
+	return polarisFlags.GetUnsupportedByVersion(version)
+}
+
+// VerifyChartVersionSupportsChangedFlags returns an error if a changed flag is not supported by the chart of the version
+func (ctl *HelmValuesFromCobraFlags) VerifyChartVersionSupportsChangedFlags(flagset *pflag.FlagSet, version string) error {
+	return polarisFlags.VerifyVersion(flagset, version)
+}
+
+// flagWasSet returns true if a flag was changed and it exists, otherwise it returns false
+func flagWasSet(flagset *pflag.FlagSet, flagName string) bool {
+	return flagset.Lookup(flagName) != nil && flagset.Lookup(flagName).Changed
+}
+
+// GenerateHelmFlagsFromCobraFlags checks each flag in synopsysctl and updates the map to
+// contain the corresponding helm chart field and value
+func (ctl *HelmValuesFromCobraFlags) GenerateHelmFlagsFromCobraFlags(flagset *pflag.FlagSet) (map[string]interface{}, error) {
+	err := ctl.CheckValuesFromFlags(flagset)
+	if err != nil {
+		return nil, err
+	}
+	flagset.VisitAll(ctl.AddHelmValueByCobraFlag)
+
+	return ctl.args, nil
+}
+
+// AddHelmValueByCobraFlag adds the helm chart field and value based on the flag set
+// in synopsysctl
+func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
+	if f.Changed {
+		log.Debugf("flag '%s': CHANGED", f.Name)
+		switch f.Name {
+		case "expose-ui":
+			// the exposed service of the chart is set by two values, the service type is kept when it's not exposed
+			serviceType := flags.ExposeServiceMapping[strings.ToUpper(ctl.flagTree.ExposeService)]
+			util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, serviceType != "None")
+			if serviceType != "None" {
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, serviceType)
+			}
+		default:
+			if found, err := polarisFlags.SetHelmValue(ctl.args, &ctl.flagTree, f.Name); err != nil {
+				log.Fatalf("%+v", err)
+			} else if !found {
+				log.Debugf("flag '%s': NOT FOUND", f.Name)
+			}
+		}
+	} else {
+		log.Debugf("flag '%s': UNCHANGED", f.Name)
+	}
+}
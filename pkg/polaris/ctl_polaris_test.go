@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package polaris
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHelmValuesFromCobraFlags(t *testing.T) {
+	assert := assert.New(t)
+	polarisCobraHelper := NewHelmValuesFromCobraFlags()
+	assert.Equal(&HelmValuesFromCobraFlags{
+		args:     map[string]interface{}{},
+		flagTree: FlagTree{},
+	}, polarisCobraHelper)
+}
+
+func TestGenerateHelmFlagsFromCobraFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	polarisCobraHelper := NewHelmValuesFromCobraFlags()
+	cmd := &cobra.Command{}
+	polarisCobraHelper.AddCobraFlagsToCommand(cmd, true)
+	flagset := cmd.Flags()
+	assert.NoError(flagset.Set("fqdn", "polaris.example.com"))
+	assert.NoError(flagset.Set("expose-ui", "loadbalancer"))
+	assert.NoError(flagset.Set("enable-reporting", "true"))
+
+	args, err := polarisCobraHelper.GenerateHelmFlagsFromCobraFlags(flagset)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"environment": map[string]interface{}{
+			"fqdn": "polaris.example.com",
+		},
+		"exposeui":           true,
+		"exposedServiceType": "LoadBalancer",
+		"reporting": map[string]interface{}{
+			"enabled": true,
+		},
+	}, args)
+}
+
+func TestExposeUINone(t *testing.T) {
+	assert := assert.New(t)
+
+	polarisCobraHelper := NewHelmValuesFromCobraFlags()
+	polarisCobraHelper.SetArgs(map[string]interface{}{"exposeui": true, "exposedServiceType": "NodePort"})
+	cmd := &cobra.Command{}
+	polarisCobraHelper.AddCobraFlagsToCommand(cmd, false)
+	assert.NoError(cmd.Flags().Set("expose-ui", "NONE"))
+
+	args, err := polarisCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+	assert.NoError(err)
+	assert.Equal(false, args["exposeui"])
+	assert.Equal("NodePort", args["exposedServiceType"])
+}
+
+func TestCheckValuesFromFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		flags  map[string]string
+		values map[string]interface{}
+		valid  bool
+	}{
+		{flags: map[string]string{"fqdn": "polaris.example.com"}, valid: true},
+		{flags: map[string]string{"fqdn": ""}, valid: false},
+		{flags: map[string]string{"expose-ui": "nodeport"}, valid: true},
+		{flags: map[string]string{"expose-ui": "ROUTE"}, valid: false},
+		{flags: map[string]string{"postgres-ssl-mode": "require"}, valid: true},
+		{flags: map[string]string{"postgres-ssl-mode": "always"}, valid: false},
+		{flags: map[string]string{"mongodb-size": "big"}, valid: false},
+		{flags: map[string]string{"enable-reporting": "false", "reporting-storage-size": "10Gi"}, valid: false},
+		{flags: map[string]string{"enable-reporting": "true", "reporting-storage-size": "10Gi"}, valid: true},
+		{flags: map[string]string{"reporting-storage-size": "10Gi"}, valid: false},
+		{flags: map[string]string{"reporting-storage-size": "10Gi"}, values: map[string]interface{}{"reporting": map[string]interface{}{"enabled": false}}, valid: false},
+		{flags: map[string]string{"reporting-storage-size": "10Gi"}, values: map[string]interface{}{"reporting": map[string]interface{}{"enabled": true}}, valid: true},
+		{flags: map[string]string{"enable-reporting": "false", "reporting-storage-size": "10Gi"}, values: map[string]interface{}{"reporting": map[string]interface{}{"enabled": true}}, valid: false},
+	}
+
+	for _, test := range tests {
+		polarisCobraHelper := NewHelmValuesFromCobraFlags()
+		polarisCobraHelper.SetArgs(test.values)
+		cmd := &cobra.Command{}
+		polarisCobraHelper.AddCobraFlagsToCommand(cmd, true)
+		for name, value := range test.flags {
+			assert.NoError(cmd.Flags().Set(name, value))
+		}
+		err := polarisCobraHelper.CheckValuesFromFlags(cmd.Flags())
+		assert.Equal(test.valid, err == nil, "flags %+v: %+v", test.flags, err)
+	}
+}
+
+func TestVersionGating(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"enable-reporting", "reporting-storage-size"}, GetFlagsUnsupportedByVersion("2020.03"))
+	assert.Empty(GetFlagsUnsupportedByVersion("2020.04"))
+
+	polarisCobraHelper := NewHelmValuesFromCobraFlags()
+	cmd := &cobra.Command{}
+	polarisCobraHelper.AddCobraFlagsToCommand(cmd, true)
+	assert.NoError(polarisCobraHelper.VerifyChartVersionSupportsChangedFlags(cmd.Flags(), "2020.03"))
+	assert.NoError(cmd.Flags().Set("enable-reporting", "true"))
+	assert.Error(polarisCobraHelper.VerifyChartVersionSupportsChangedFlags(cmd.Flags(), "2020.03"))
+	assert.NoError(polarisCobraHelper.VerifyChartVersionSupportsChangedFlags(cmd.Flags(), "2020.06"))
+}
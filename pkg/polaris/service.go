@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package polaris
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/api"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// GetExposedResourceName returns the name of the service or route that exposes the Polaris user interface
+func GetExposedResourceName(releaseName string) string {
+	return fmt.Sprintf("%s-exposed", releaseName)
+}
+
+// GetExposure returns the exposed service and route of the Polaris user interface
+func GetExposure(namespace string, releaseName string) util.Exposure {
+	return util.Exposure{
+		App:         globals.PolarisName,
+		Namespace:   namespace,
+		Name:        releaseName,
+		ServiceName: GetExposedResourceName(releaseName),
+		RouteName:   GetExposedResourceName(releaseName),
+	}
+}
+
+// CRUDServiceOrRoute creates, updates or deletes the service or the OpenShift route that exposes the Polaris user
+// interface based on the Helm values of the instance. The route serves the FQDN of the instance
+func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, releaseName string, helmValues map[string]interface{}) error {
+	exposed, _ := util.GetHelmValueFromMap(helmValues, []string{"exposeui"}).(bool)
+	serviceType, _ := util.GetHelmValueFromMap(helmValues, []string{"exposedServiceType"}).(string)
+	fqdn, _ := util.GetHelmValueFromMap(helmValues, []string{"environment", "fqdn"}).(string)
+	if !exposed {
+		return DeleteServiceOrRoute(restConfig, kubeClient, namespace, releaseName)
+	}
+	if err := util.ReconcileExposure(restConfig, kubeClient, GetExposure(namespace, releaseName)); err != nil {
+		return err
+	}
+
+	webUI, err := getWebUIService(kubeClient, namespace, releaseName)
+	if err != nil {
+		return err
+	}
+	name := GetExposedResourceName(releaseName)
+	labels := map[string]string{"app": globals.PolarisName, "name": releaseName, "component": "exposed"}
+
+	switch serviceType {
+	case "NodePort", "LoadBalancer":
+		svc := util.GetKubeService(namespace, name, labels, webUI.Spec.Selector, webUI.Spec.Ports[0].Port, webUI.Spec.Ports[0].TargetPort.String(), corev1.ServiceType(serviceType))
+		existing, err := util.GetService(kubeClient, namespace, name)
+		if err != nil {
+			if _, err := util.CreateKubeService(kubeClient, namespace, svc); err != nil {
+				return fmt.Errorf("unable to create the Polaris exposed service due to %+v", err)
+			}
+		} else if !strings.EqualFold(string(existing.Spec.Type), serviceType) {
+			// the type of a service can't be changed in place if node ports were allocated
+			if err := util.DeleteService(kubeClient, namespace, name); err != nil {
+				return fmt.Errorf("unable to delete the Polaris exposed service due to %+v", err)
+			}
+			if _, err := util.CreateKubeService(kubeClient, namespace, svc); err != nil {
+				return fmt.Errorf("unable to create the Polaris exposed service due to %+v", err)
+			}
+		}
+		// a route of a previous exposure would still serve the FQDN
+		if util.IsOpenshift(kubeClient) {
+			routeClient := util.GetRouteClient(restConfig, kubeClient, namespace)
+			if _, err := util.GetRoute(routeClient, namespace, name); err == nil {
+				if err := util.DeleteRoute(routeClient, namespace, name); err != nil {
+					return fmt.Errorf("unable to delete the Polaris route due to %+v", err)
+				}
+			}
+		}
+	case "OpenShift":
+		if !util.IsOpenshift(kubeClient) {
+			return fmt.Errorf("the Polaris user interface can only be exposed with a route on OpenShift")
+		}
+		if _, err := util.GetService(kubeClient, namespace, name); err == nil {
+			if err := util.DeleteService(kubeClient, namespace, name); err != nil {
+				return fmt.Errorf("unable to delete the Polaris exposed service due to %+v", err)
+			}
+		}
+		routeClient := util.GetRouteClient(restConfig, kubeClient, namespace)
+		route := util.GetRouteComponent(&api.Route{
+			Namespace:   namespace,
+			Name:        name,
+			Kind:        "Service",
+			ServiceName: webUI.Name,
+			PortName:    webUI.Spec.Ports[0].Name,
+		}, labels)
+		route.Spec.Host = fqdn
+		if existing, err := util.GetRoute(routeClient, namespace, name); err == nil {
+			existing.Spec.Host = route.Spec.Host
+			existing.Spec.To = route.Spec.To
+			existing.Spec.Port = route.Spec.Port
+			if _, err := util.UpdateRoute(routeClient, namespace, existing); err != nil {
+				return fmt.Errorf("unable to update the Polaris route due to %+v", err)
+			}
+		} else if _, err := util.CreateRoute(routeClient, namespace, route); err != nil {
+			return fmt.Errorf("unable to create the Polaris route due to %+v", err)
+		}
+	}
+	return nil
+}
+
+// DeleteServiceOrRoute deletes the service and the OpenShift route that expose the Polaris user interface
+func DeleteServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, releaseName string) error {
+	return util.DeleteExposure(restConfig, kubeClient, GetExposure(namespace, releaseName))
+}
+
+// getWebUIService returns the service of the Polaris web user interface created by the chart
+func getWebUIService(kubeClient *kubernetes.Clientset, namespace string, releaseName string) (*corev1.Service, error) {
+	services, err := util.ListServices(kubeClient, namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the services in namespace '%s' due to %+v", namespace, err)
+	}
+	for i, svc := range services.Items {
+		if strings.HasPrefix(svc.Name, releaseName) && strings.Contains(svc.Name, "web-ui") && len(svc.Spec.Ports) > 0 {
+			return &services.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find the Polaris web user interface service in namespace '%s'", namespace)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// UpgradeImpact is the impact of a Kubernetes upgrade on an instance
+type UpgradeImpact struct {
+	App            string             `json:"app"`
+	Name           string             `json:"name"`
+	Namespace      string             `json:"namespace"`
+	ChartVersion   string             `json:"chartVersion"`
+	Findings       []util.LintFinding `json:"findings"`
+	MinimumVersion string             `json:"minimumVersion,omitempty"`
+	Remediation    string             `json:"remediation"`
+}
+
+// MinimumChartFunc returns the URL of the lowest chart version of an instance that is compatible with the Kubernetes version
+type MinimumChartFunc func(app string, rel *release.Release) (string, error)
+
+// AnalyzeClusterUpgrade checks the objects of the instances in the namespace, or in all namespaces if the namespace is
+// empty, for problems on the Kubernetes version and finds the product version to upgrade the broken instances to. The
+// minimum chart function is skipped if it is nil
+func AnalyzeClusterUpgrade(namespace string, kubeVersion string, clients Clients, minimumChart MinimumChartFunc) ([]UpgradeImpact, error) {
+	releases, err := util.ListReleasesWithHelm3(namespace, clients.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	apps := []string{}
+	for app := range chartNames {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	impacts := []UpgradeImpact{}
+	for _, rel := range releases {
+		for _, app := range apps {
+			name, ok := InstanceName(app, rel)
+			if !ok {
+				continue
+			}
+			impact, err := analyzeReleaseUpgrade(app, name, rel, kubeVersion, minimumChart)
+			if err != nil {
+				return nil, err
+			}
+			impacts = append(impacts, impact)
+		}
+	}
+	sort.Slice(impacts, func(i, j int) bool {
+		if impacts[i].Namespace != impacts[j].Namespace {
+			return impacts[i].Namespace < impacts[j].Namespace
+		}
+		return impacts[i].Name < impacts[j].Name
+	})
+	return impacts, nil
+}
+
+// analyzeReleaseUpgrade returns the impact of the Kubernetes version on the instance deployed by the release
+func analyzeReleaseUpgrade(app string, name string, rel *release.Release, kubeVersion string, minimumChart MinimumChartFunc) (UpgradeImpact, error) {
+	impact := UpgradeImpact{App: app, Name: name, Namespace: rel.Namespace, Remediation: messages.Get(messages.RemediationNone, nil)}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		impact.ChartVersion = rel.Chart.Metadata.Version
+	}
+	findings, err := util.CheckClusterUpgrade(rel.Manifest, kubeVersion)
+	if err != nil {
+		return impact, fmt.Errorf("unable to check %s '%s' in namespace '%s' due to %+v", app, name, rel.Namespace, err)
+	}
+	impact.Findings = findings
+	if len(findings) == 0 {
+		return impact, nil
+	}
+
+	impact.Remediation = messages.Get(messages.RemediationReviewFindings, nil)
+	if minimumChart == nil {
+		return impact, nil
+	}
+	chartURL, err := minimumChart(app, rel)
+	if err != nil {
+		return impact, fmt.Errorf("unable to find a compatible version of %s '%s' in namespace '%s' due to %+v", app, name, rel.Namespace, err)
+	}
+	if len(chartURL) == 0 {
+		impact.Remediation = messages.Get(messages.RemediationNoCompatibleVersion, messages.Args{"KubeVersion": kubeVersion})
+		return impact, nil
+	}
+	impact.MinimumVersion = util.ParsePackageName(chartURL)[1]
+	impact.Remediation = messages.Get(messages.RemediationUpgradeFirst, messages.Args{"Version": impact.MinimumVersion})
+	return impact, nil
+}
@@ -0,0 +1,279 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Description is the configuration of an instance gathered from its Helm release and its objects
+type Description struct {
+	App       string                 `json:"app"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Version   string                 `json:"version"`
+	Release   ReleaseDescription     `json:"release"`
+	Values    map[string]interface{} `json:"values"`
+	// Secrets are the names of the secrets that synopsysctl created for the instance, their values are never described
+	Secrets   []string              `json:"secrets"`
+	Endpoints []EndpointDescription `json:"endpoints"`
+	Volumes   []Volume              `json:"volumes"`
+}
+
+// EndpointDescription is an endpoint of an instance with the URL of its user interface
+type EndpointDescription struct {
+	Endpoint
+	URL string `json:"url"`
+}
+
+// ReleaseDescription is the Helm release of an instance and the metadata of its chart
+type ReleaseDescription struct {
+	Name         string `json:"name"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+	Updated      string `json:"updated"`
+	Description  string `json:"description"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+	AppVersion   string `json:"appVersion"`
+}
+
+// Volume is a persistent volume claim of an instance and its usage
+type Volume struct {
+	Name         string   `json:"name"`
+	Status       string   `json:"status"`
+	StorageClass string   `json:"storageClass"`
+	Capacity     string   `json:"capacity"`
+	AccessModes  []string `json:"accessModes"`
+	MountedBy    []string `json:"mountedBy"`
+	// UsedBytes and CapacityBytes are reported by the kubelet of a node that mounts the volume, they are unknown if the
+	// volume isn't mounted or the user isn't allowed to proxy to the nodes
+	UsedBytes     *int64 `json:"usedBytes,omitempty"`
+	CapacityBytes *int64 `json:"capacityBytes,omitempty"`
+}
+
+// Usage returns the used bytes of the volume and the percentage of its capacity, or unknown
+func (v Volume) Usage() string {
+	if v.UsedBytes == nil {
+		return "unknown"
+	}
+	if v.CapacityBytes == nil || *v.CapacityBytes == 0 {
+		return FormatBytes(*v.UsedBytes)
+	}
+	return fmt.Sprintf("%s (%.0f%%)", FormatBytes(*v.UsedBytes), float64(*v.UsedBytes)*100/float64(*v.CapacityBytes))
+}
+
+// VolumeStats is the usage of a volume reported by the kubelet
+type VolumeStats struct {
+	UsedBytes     *int64
+	CapacityBytes *int64
+}
+
+// kubeletStatsSummary is the part of the summary of the kubelet stats API with the usage of the volumes of the pods
+type kubeletStatsSummary struct {
+	Pods []struct {
+		Volumes []struct {
+			PVCRef *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+			UsedBytes     *int64 `json:"usedBytes"`
+			CapacityBytes *int64 `json:"capacityBytes"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// ParseVolumeStats returns the usage of the persistent volume claims of the namespace from the summary of the kubelet
+// stats API, by name of the claim
+func ParseVolumeStats(data []byte, namespace string) (map[string]VolumeStats, error) {
+	summary := kubeletStatsSummary{}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("unable to parse the stats summary due to %+v", err)
+	}
+	stats := map[string]VolumeStats{}
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace {
+				continue
+			}
+			stats[volume.PVCRef.Name] = VolumeStats{UsedBytes: volume.UsedBytes, CapacityBytes: volume.CapacityBytes}
+		}
+	}
+	return stats, nil
+}
+
+// FormatBytes returns a number of bytes with a binary unit, e.g. 1.5Gi
+func FormatBytes(bytes int64) string {
+	units := []string{"Ki", "Mi", "Gi", "Ti", "Pi"}
+	if bytes < 1024 {
+		return fmt.Sprintf("%d", bytes)
+	}
+	value := float64(bytes) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f%s", value, units[unit])
+}
+
+// EndpointURL returns the URL of the user interface of an endpoint
+func EndpointURL(endpoint Endpoint) string {
+	if strings.HasPrefix(endpoint.Address, "<") {
+		return endpoint.Address
+	}
+	return fmt.Sprintf("https://%s", endpoint.Address)
+}
+
+// Describe returns the configuration of an instance: its Helm release and values, the names of the secrets created by
+// synopsysctl, its endpoints and its volumes. The values of the secret keys are redacted and all the values of the chart
+// are returned if allValues is true, otherwise only the values that were set
+func Describe(product Product, clients Clients, allValues bool) (*Description, error) {
+	base, ok := product.(*instance)
+	if !ok {
+		return nil, fmt.Errorf("unable to describe %s '%s'", product.App(), product.Name())
+	}
+	rel, err := util.GetWithHelm3(base.releaseName, base.namespace, clients.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find %s '%s' in namespace '%s'", base.app, base.name, base.namespace)
+	}
+	description := &Description{App: base.app, Name: base.name, Namespace: base.namespace, Secrets: []string{}, Endpoints: []EndpointDescription{}, Volumes: []Volume{}}
+	summary := summarizeRelease(base.app, base.name, rel, base.versionKey)
+	description.Version = summary.AppVersion
+	description.Release = ReleaseDescription{Name: rel.Name, Revision: rel.Version, Status: summary.Status, Updated: summary.Updated, ChartVersion: summary.ChartVersion}
+	if rel.Info != nil {
+		description.Release.Description = rel.Info.Description
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		description.Release.Chart = rel.Chart.Metadata.Name
+		description.Release.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	values := rel.Config
+	if allValues {
+		values = util.GetReleaseValues(rel)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	description.Values = util.RedactHelmValues(values)
+
+	for _, secretName := range util.GetSecretNamesFromHelmValues(rel.Config) {
+		secret, err := util.GetSecret(clients.KubeClient, base.namespace, secretName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				log.Warnf("secret '%s' is referenced by %s '%s' but does not exist", secretName, base.app, base.name)
+				continue
+			}
+			return nil, fmt.Errorf("unable to get secret '%s' in namespace '%s' due to %+v", secretName, base.namespace, err)
+		}
+		// the secrets of the chart are managed by Helm
+		if secret.Labels["app.kubernetes.io/managed-by"] != "Helm" {
+			description.Secrets = append(description.Secrets, secretName)
+		}
+	}
+
+	endpoints, err := product.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		description.Endpoints = append(description.Endpoints, EndpointDescription{Endpoint: endpoint, URL: EndpointURL(endpoint)})
+	}
+	if description.Volumes, err = describeVolumes(base, clients); err != nil {
+		return nil, err
+	}
+	return description, nil
+}
+
+// describeVolumes returns the persistent volume claims of an instance with their usage reported by the kubelets of the
+// nodes that mount them
+func describeVolumes(base *instance, clients Clients) ([]Volume, error) {
+	pvcs, err := clients.KubeClient.CoreV1().PersistentVolumeClaims(base.namespace).List(metav1.ListOptions{LabelSelector: base.labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the persistent volume claims of %s '%s' due to %+v", base.app, base.name, err)
+	}
+	pods, err := util.ListPodsWithLabels(clients.KubeClient, base.namespace, base.labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of %s '%s' due to %+v", base.app, base.name, err)
+	}
+	mountedBy := map[string][]string{}
+	nodes := map[string]bool{}
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				mountedBy[volume.PersistentVolumeClaim.ClaimName] = append(mountedBy[volume.PersistentVolumeClaim.ClaimName], pod.Name)
+				if pod.Status.Phase == corev1.PodRunning && len(pod.Spec.NodeName) > 0 {
+					nodes[pod.Spec.NodeName] = true
+				}
+			}
+		}
+	}
+
+	// users may not be allowed to proxy to the nodes, the usage of the volumes is optional
+	stats := map[string]VolumeStats{}
+	for node := range nodes {
+		data, err := clients.KubeClient.CoreV1().RESTClient().Get().Resource("nodes").Name(node).SubResource("proxy").Suffix("stats/summary").DoRaw()
+		if err != nil {
+			log.Debugf("unable to get the stats summary of node '%s' due to %+v", node, err)
+			continue
+		}
+		nodeStats, err := ParseVolumeStats(data, base.namespace)
+		if err != nil {
+			log.Debugf("%+v", err)
+			continue
+		}
+		for name, volumeStats := range nodeStats {
+			stats[name] = volumeStats
+		}
+	}
+
+	volumes := []Volume{}
+	for _, pvc := range pvcs.Items {
+		volume := Volume{Name: pvc.Name, Status: string(pvc.Status.Phase), AccessModes: []string{}, MountedBy: mountedBy[pvc.Name]}
+		if pvc.Spec.StorageClassName != nil {
+			volume.StorageClass = *pvc.Spec.StorageClassName
+		}
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			volume.Capacity = capacity.String()
+		} else if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			volume.Capacity = request.String()
+		}
+		for _, mode := range pvc.Spec.AccessModes {
+			volume.AccessModes = append(volume.AccessModes, string(mode))
+		}
+		if volumeStats, ok := stats[pvc.Name]; ok {
+			volume.UsedBytes, volume.CapacityBytes = volumeStats.UsedBytes, volumeStats.CapacityBytes
+		}
+		volumes = append(volumes, volume)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, nil
+}
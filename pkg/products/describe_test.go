@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolumeStats(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte(`{"node": {"nodeName": "node-1"}, "pods": [
+		{"podRef": {"name": "bd-blackduck-postgres-0"}, "volume": [
+			{"name": "postgres-persistent-vol", "pvcRef": {"name": "bd-blackduck-postgres", "namespace": "bd"}, "usedBytes": 1073741824, "capacityBytes": 4294967296},
+			{"name": "default-token", "usedBytes": 12288}
+		]},
+		{"podRef": {"name": "other"}, "volume": [
+			{"name": "data", "pvcRef": {"name": "other-data", "namespace": "other"}, "usedBytes": 1024}
+		]}
+	]}`)
+	stats, err := ParseVolumeStats(data, "bd")
+	assert.Nil(err)
+	assert.Len(stats, 1)
+	assert.Equal(int64(1073741824), *stats["bd-blackduck-postgres"].UsedBytes)
+
+	used, capacity := int64(1073741824), int64(4294967296)
+	volume := Volume{UsedBytes: &used, CapacityBytes: &capacity}
+	assert.Equal("1.0Gi (25%)", volume.Usage())
+	assert.Equal("unknown", Volume{}.Usage())
+
+	_, err = ParseVolumeStats([]byte("not json"), "bd")
+	assert.NotNil(err)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("512", FormatBytes(512))
+	assert.Equal("1.5Ki", FormatBytes(1536))
+	assert.Equal("10.0Gi", FormatBytes(10*1024*1024*1024))
+}
+
+func TestEndpointURL(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("https://bd.example.com", EndpointURL(Endpoint{Type: "Ingress", Address: "bd.example.com"}))
+	assert.Equal("https://10.0.0.1:443", EndpointURL(Endpoint{Type: "LoadBalancer", Address: "10.0.0.1:443"}))
+	assert.Equal("<pending>", EndpointURL(Endpoint{Type: "LoadBalancer", Address: "<pending>"}))
+}
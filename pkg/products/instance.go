@@ -0,0 +1,224 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// restHealthCheck is a health endpoint of the product, called through the service proxy of the Kubernetes API server
+type restHealthCheck struct {
+	service string
+	port    string
+	scheme  string
+	path    string
+}
+
+// instance implements Product for the Helm based products, which only differ in the release name, the labels and the health endpoint
+type instance struct {
+	clients       Clients
+	app           string
+	name          string
+	namespace     string
+	releaseName   string
+	versionKey    []string
+	labelSelector string
+	healthCheck   *restHealthCheck
+}
+
+// App returns the name of the product
+func (i *instance) App() string {
+	return i.app
+}
+
+// Name returns the name of the instance
+func (i *instance) Name() string {
+	return i.name
+}
+
+// Namespace returns the namespace of the instance
+func (i *instance) Namespace() string {
+	return i.namespace
+}
+
+// LabelSelector returns the label selector of the resources of the instance
+func (i *instance) LabelSelector() string {
+	return i.labelSelector
+}
+
+// ReleaseName returns the name of the Helm release of the instance
+func (i *instance) ReleaseName() string {
+	return i.releaseName
+}
+
+// Version returns the version set in the Helm release, or the app version of the chart
+func (i *instance) Version() (string, error) {
+	release, err := util.GetWithHelm3(i.releaseName, i.namespace, i.clients.KubeConfig)
+	if err != nil {
+		return "", fmt.Errorf("couldn't find %s '%s' in namespace '%s'", i.app, i.name, i.namespace)
+	}
+	if version, ok := util.GetValueFromRelease(release, i.versionKey).(string); ok && len(version) > 0 {
+		return version, nil
+	}
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		return release.Chart.Metadata.AppVersion, nil
+	}
+	return "", fmt.Errorf("unable to find the version of %s '%s' in namespace '%s'", i.app, i.name, i.namespace)
+}
+
+// Components returns the deployments and stateful sets of the instance
+func (i *instance) Components() ([]Component, error) {
+	components := []Component{}
+	pods, err := util.ListPodsWithLabels(i.clients.KubeClient, i.namespace, i.labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of %s '%s' due to %+v", i.app, i.name, err)
+	}
+	deployments, err := util.ListDeployments(i.clients.KubeClient, i.namespace, i.labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the deployments of %s '%s' due to %+v", i.app, i.name, err)
+	}
+	for _, deployment := range deployments.Items {
+		replicas := 1
+		if deployment.Spec.Replicas != nil {
+			replicas = int(*deployment.Spec.Replicas)
+		}
+		component := Component{Name: deployment.Name, Kind: "Deployment", Replicas: replicas, ReadyReplicas: int(deployment.Status.ReadyReplicas), Restarts: countRestarts(pods.Items, deployment.Spec.Selector)}
+		if deployment.Status.ObservedGeneration >= deployment.Generation {
+			component.UpdatedReplicas = int(deployment.Status.UpdatedReplicas)
+		}
+		components = append(components, component)
+	}
+	statefulSets, err := i.clients.KubeClient.AppsV1().StatefulSets(i.namespace).List(metav1.ListOptions{LabelSelector: i.labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the stateful sets of %s '%s' due to %+v", i.app, i.name, err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		replicas := 1
+		if statefulSet.Spec.Replicas != nil {
+			replicas = int(*statefulSet.Spec.Replicas)
+		}
+		component := Component{Name: statefulSet.Name, Kind: "StatefulSet", Replicas: replicas, ReadyReplicas: int(statefulSet.Status.ReadyReplicas), Restarts: countRestarts(pods.Items, statefulSet.Spec.Selector)}
+		if statefulSet.Status.ObservedGeneration >= statefulSet.Generation {
+			component.UpdatedReplicas = int(statefulSet.Status.UpdatedReplicas)
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// countRestarts returns the sum of the restarts of the containers of the pods matched by the selector of a component
+func countRestarts(pods []corev1.Pod, labelSelector *metav1.LabelSelector) int {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil || selector.Empty() {
+		return 0
+	}
+	restarts := 0
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += int(status.RestartCount)
+		}
+	}
+	return restarts
+}
+
+// Endpoints returns the load balancer and node port services, the ingresses and the OpenShift routes of the instance
+func (i *instance) Endpoints() ([]Endpoint, error) {
+	endpoints := []Endpoint{}
+	services, err := util.ListServices(i.clients.KubeClient, i.namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the services of %s '%s' due to %+v", i.app, i.name, err)
+	}
+	for _, svc := range services.Items {
+		if !strings.HasPrefix(svc.Name, i.releaseName) || len(svc.Spec.Ports) == 0 {
+			continue
+		}
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort {
+			continue
+		}
+		addresses, err := util.GetServiceEndpoints(i.clients.KubeClient, &svc)
+		if err != nil {
+			addresses = []string{"<pending>"}
+		}
+		for _, address := range addresses {
+			endpoints = append(endpoints, Endpoint{Name: svc.Name, Type: string(svc.Spec.Type), Address: address})
+		}
+	}
+	ingresses, err := i.clients.KubeClient.ExtensionsV1beta1().Ingresses(i.namespace).List(metav1.ListOptions{})
+	if err == nil {
+		for _, ingress := range ingresses.Items {
+			if !strings.HasPrefix(ingress.Name, i.releaseName) {
+				continue
+			}
+			for _, rule := range ingress.Spec.Rules {
+				endpoints = append(endpoints, Endpoint{Name: ingress.Name, Type: "Ingress", Address: rule.Host})
+			}
+		}
+	}
+	if util.IsOpenshift(i.clients.KubeClient) {
+		routeClient := util.GetRouteClient(i.clients.RestConfig, i.clients.KubeClient, i.namespace)
+		if routes, err := util.ListRoutes(routeClient, i.namespace, ""); err == nil {
+			for _, route := range routes.Items {
+				if strings.HasPrefix(route.Name, i.releaseName) {
+					endpoints = append(endpoints, Endpoint{Name: route.Name, Type: "Route", Address: route.Spec.Host})
+				}
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// HealthCheck checks the readiness of the components and calls the health endpoint of the product if it has one
+func (i *instance) HealthCheck() (*Health, error) {
+	components, err := i.Components()
+	if err != nil {
+		return nil, err
+	}
+	health := EvaluateHealth(components)
+	if i.healthCheck == nil || !health.Healthy {
+		return health, nil
+	}
+	if _, err := proxyGet(i.clients, i.namespace, i.healthCheck.scheme, i.healthCheck.service, i.healthCheck.port, i.healthCheck.path); err != nil {
+		health.Healthy = false
+		health.Findings = append(health.Findings, fmt.Sprintf("health endpoint '%s' of service '%s' failed: %+v", i.healthCheck.path, i.healthCheck.service, err))
+	}
+	return health, nil
+}
+
+// proxyGet calls a path of a service through the service proxy of the Kubernetes API server
+func proxyGet(clients Clients, namespace string, scheme string, service string, port string, path string) ([]byte, error) {
+	return clients.KubeClient.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("services").
+		Name(fmt.Sprintf("%s:%s:%s", scheme, service, port)).
+		SubResource("proxy").
+		Suffix(path).
+		DoRaw()
+}
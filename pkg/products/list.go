@@ -0,0 +1,150 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// InstanceSummary is the overview of an instance found in the Helm releases of the cluster
+type InstanceSummary struct {
+	App          string `json:"app"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	ChartVersion string `json:"chartVersion"`
+	AppVersion   string `json:"appVersion"`
+	Expose       string `json:"expose"`
+	ReadyPods    int    `json:"readyPods"`
+	TotalPods    int    `json:"totalPods"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+	Updated      string `json:"updated"`
+}
+
+// chartNames are the names of the Helm charts of the products
+var chartNames = map[string]*string{
+	util.AlertName:     &globals.AlertChartName,
+	util.BlackDuckName: &globals.BlackDuckChartName,
+	util.OpsSightName:  &globals.OpsSightChartName,
+	globals.BDBAName:   &globals.BDBAChartName,
+}
+
+// InstanceName returns the name of the instance deployed by a release, false if the release doesn't deploy the product
+func InstanceName(app string, rel *release.Release) (string, bool) {
+	chartName, ok := chartNames[app]
+	if !ok || rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.Name != *chartName {
+		return "", false
+	}
+	if app == util.AlertName {
+		return strings.TrimSuffix(rel.Name, globals.AlertPostSuffix), true
+	}
+	return rel.Name, true
+}
+
+// ListInstances returns the instances of the product in the namespace, or in all namespaces if the namespace is empty
+func ListInstances(app string, namespace string, clients Clients) ([]InstanceSummary, error) {
+	releases, err := util.ListReleasesWithHelm3(namespace, clients.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	instances := []InstanceSummary{}
+	for _, rel := range releases {
+		name, ok := InstanceName(app, rel)
+		if !ok {
+			continue
+		}
+		product, err := New(app, name, rel.Namespace, clients)
+		if err != nil {
+			return nil, err
+		}
+		base := product.(*instance)
+		summary := summarizeRelease(app, name, rel, base.versionKey)
+		summary.ReadyPods, summary.TotalPods, err = countReadyPods(clients.KubeClient, rel.Namespace, base.labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the pods of %s '%s' in namespace '%s' due to %+v", app, name, rel.Namespace, err)
+		}
+		instances = append(instances, summary)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Namespace != instances[j].Namespace {
+			return instances[i].Namespace < instances[j].Namespace
+		}
+		return instances[i].Name < instances[j].Name
+	})
+	return instances, nil
+}
+
+// summarizeRelease returns the summary of an instance from its Helm release, without the pods
+func summarizeRelease(app string, name string, rel *release.Release, versionKey []string) InstanceSummary {
+	summary := InstanceSummary{App: app, Name: name, Namespace: rel.Namespace, Expose: "None", Revision: rel.Version}
+	if rel.Info != nil {
+		summary.Status = rel.Info.Status.String()
+		summary.Updated = rel.Info.LastDeployed.UTC().Format(time.RFC3339)
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		summary.ChartVersion = rel.Chart.Metadata.Version
+		summary.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	if version, ok := util.GetValueFromRelease(rel, versionKey).(string); ok && len(version) > 0 {
+		summary.AppVersion = version
+	}
+	exposeUI, _ := util.GetValueFromRelease(rel, []string{"exposeui"}).(bool)
+	if exposedServiceType, ok := util.GetValueFromRelease(rel, []string{"exposedServiceType"}).(string); exposeUI && ok && len(exposedServiceType) > 0 {
+		summary.Expose = exposedServiceType
+	}
+	if util.GetIngressFromHelmValues(rel.Config) != nil {
+		summary.Expose = "Ingress"
+	}
+	return summary
+}
+
+// countReadyPods returns the number of ready pods and the number of pods of an instance, the pods of completed jobs are
+// ignored
+func countReadyPods(kubeClient *kubernetes.Clientset, namespace string, labelSelector string) (int, int, error) {
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return 0, 0, err
+	}
+	ready, total := 0, 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		total++
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return ready, total, nil
+}
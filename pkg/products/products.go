@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package products provides a common view of the deployed Synopsys products, so that the commands reporting the
+// health and status of an instance don't reimplement the product specific logic
+package products
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Component is a deployment or stateful set of an instance
+type Component struct {
+	Name          string `json:"name"`
+	Kind          string `json:"kind"`
+	Replicas      int    `json:"replicas"`
+	ReadyReplicas int    `json:"readyReplicas"`
+	// UpdatedReplicas are the replicas of the latest spec, 0 until the controller observed the latest spec
+	UpdatedReplicas int `json:"updatedReplicas"`
+	// Restarts is the sum of the restarts of the containers of the pods of the component
+	Restarts int `json:"restarts"`
+}
+
+// Ready returns true if all replicas of the component are ready
+func (c Component) Ready() bool {
+	return c.ReadyReplicas >= c.Replicas
+}
+
+// RolledOut returns true if all replicas of the component run the latest spec and are ready
+func (c Component) RolledOut() bool {
+	return c.Ready() && c.UpdatedReplicas >= c.Replicas
+}
+
+// Endpoint is an address the user interface or the API of an instance can be reached at
+type Endpoint struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// Health is the result of the health check of an instance
+type Health struct {
+	Healthy  bool     `json:"healthy"`
+	Findings []string `json:"findings"`
+}
+
+// Product is a deployed instance of a Synopsys product
+type Product interface {
+	// App returns the name of the product, e.g. blackduck
+	App() string
+	// Name returns the name of the instance
+	Name() string
+	// Namespace returns the namespace of the instance
+	Namespace() string
+	// Version returns the version of the product deployed by the instance
+	Version() (string, error)
+	// Components returns the deployments and stateful sets of the instance
+	Components() ([]Component, error)
+	// Endpoints returns the addresses the instance is exposed at
+	Endpoints() ([]Endpoint, error)
+	// HealthCheck checks that the components are ready and that the product reports itself healthy
+	HealthCheck() (*Health, error)
+	// LabelSelector returns the label selector of the pods of the instance
+	LabelSelector() string
+	// ReleaseName returns the name of the Helm release of the instance
+	ReleaseName() string
+}
+
+// Clients are the clients used to inspect the instances
+type Clients struct {
+	RestConfig *rest.Config
+	KubeClient *kubernetes.Clientset
+	KubeConfig string
+}
+
+// New returns the product of an instance. The name of a BDBA instance is ignored as there is one per namespace
+func New(app string, name string, namespace string, clients Clients) (Product, error) {
+	base := instance{clients: clients, app: app, name: name, namespace: namespace}
+	switch app {
+	case util.BlackDuckName:
+		base.releaseName = name
+		base.versionKey = []string{"imageTag"}
+		base.labelSelector = fmt.Sprintf("app=%s, name=%s", app, name)
+		base.healthCheck = &restHealthCheck{service: util.GetResourceName(name, util.BlackDuckName, "webserver"), port: "443", scheme: "https", path: "api/health-checks/liveness"}
+		return &base, nil
+	case util.AlertName:
+		base.releaseName = fmt.Sprintf("%s%s", name, globals.AlertPostSuffix)
+		base.versionKey = []string{"alert", "imageTag"}
+		base.labelSelector = fmt.Sprintf("app=%s, name=%s", app, name)
+		base.healthCheck = &restHealthCheck{service: util.GetResourceName(name, util.AlertName, ""), port: "8443", scheme: "https", path: "alert/api/about"}
+		return &base, nil
+	case util.OpsSightName:
+		base.releaseName = name
+		base.versionKey = []string{"imageTag"}
+		base.labelSelector = fmt.Sprintf("app=%s, name=%s", app, name)
+		return &base, nil
+	case globals.BDBAName:
+		base.name = globals.BDBAName
+		base.releaseName = globals.BDBAName
+		base.versionKey = []string{"version"}
+		base.labelSelector = fmt.Sprintf("app.kubernetes.io/instance=%s", globals.BDBAName)
+		return &base, nil
+	}
+	return nil, fmt.Errorf("unknown product '%s'", app)
+}
+
+// GetPendingRollouts returns the components that aren't rolled out, sorted by name
+func GetPendingRollouts(components []Component) []string {
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	pending := []string{}
+	for _, component := range components {
+		if !component.RolledOut() {
+			pending = append(pending, fmt.Sprintf("%s '%s' has %d of %d replicas ready and %d updated", component.Kind, component.Name, component.ReadyReplicas, component.Replicas, component.UpdatedReplicas))
+		}
+	}
+	return pending
+}
+
+// EvaluateHealth returns the health of the components. An instance without components isn't healthy
+func EvaluateHealth(components []Component) *Health {
+	health := &Health{Healthy: len(components) > 0, Findings: []string{}}
+	if len(components) == 0 {
+		health.Findings = append(health.Findings, "no components found")
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	for _, component := range components {
+		if !component.Ready() {
+			health.Healthy = false
+			health.Findings = append(health.Findings, fmt.Sprintf("%s '%s' has %d of %d replicas ready", component.Kind, component.Name, component.ReadyReplicas, component.Replicas))
+		}
+	}
+	return health
+}
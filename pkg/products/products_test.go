@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestEvaluateHealth(t *testing.T) {
+	assert := assert.New(t)
+
+	health := EvaluateHealth([]Component{
+		{Name: "bd-blackduck-webapp", Kind: "Deployment", Replicas: 1, ReadyReplicas: 1},
+		{Name: "bd-blackduck-jobrunner", Kind: "Deployment", Replicas: 2, ReadyReplicas: 1},
+	})
+	assert.False(health.Healthy)
+	assert.Equal([]string{"Deployment 'bd-blackduck-jobrunner' has 1 of 2 replicas ready"}, health.Findings)
+
+	health = EvaluateHealth([]Component{{Name: "alert", Kind: "Deployment", Replicas: 1, ReadyReplicas: 1}})
+	assert.True(health.Healthy)
+	assert.Empty(health.Findings)
+
+	health = EvaluateHealth([]Component{})
+	assert.False(health.Healthy)
+}
+
+func TestGetPendingRollouts(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{
+		"Deployment 'bd-blackduck-jobrunner' has 2 of 2 replicas ready and 1 updated",
+		"StatefulSet 'bd-blackduck-postgres' has 0 of 1 replicas ready and 1 updated",
+	}, GetPendingRollouts([]Component{
+		{Name: "bd-blackduck-webapp", Kind: "Deployment", Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1},
+		{Name: "bd-blackduck-postgres", Kind: "StatefulSet", Replicas: 1, ReadyReplicas: 0, UpdatedReplicas: 1},
+		{Name: "bd-blackduck-jobrunner", Kind: "Deployment", Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 1},
+	}))
+	assert.Empty(GetPendingRollouts([]Component{{Name: "alert", Kind: "Deployment", Replicas: 0}}))
+}
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+
+	product, err := New("alert", "al", "ns", Clients{})
+	assert.NoError(err)
+	assert.Equal("al-alert", product.(*instance).releaseName)
+	assert.Equal("al-alert", product.ReleaseName())
+
+	product, err = New("bdba", "ignored", "ns", Clients{})
+	assert.NoError(err)
+	assert.Equal("bdba", product.Name())
+
+	_, err = New("unknown", "name", "ns", Clients{})
+	assert.Error(err)
+}
+
+func TestInstanceName(t *testing.T) {
+	assert := assert.New(t)
+
+	alertRelease := &release.Release{Name: "al-alert", Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "synopsys-alert"}}}
+	name, ok := InstanceName("alert", alertRelease)
+	assert.True(ok)
+	assert.Equal("al", name)
+
+	_, ok = InstanceName("blackduck", alertRelease)
+	assert.False(ok)
+
+	_, ok = InstanceName("blackduck", &release.Release{Name: "other"})
+	assert.False(ok)
+}
+
+func TestSummarizeRelease(t *testing.T) {
+	assert := assert.New(t)
+
+	rel := &release.Release{
+		Name:      "bd",
+		Namespace: "ns",
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "blackduck", Version: "2020.6.0", AppVersion: "2020.6.0"},
+			Values:   map[string]interface{}{"exposeui": false, "exposedServiceType": "NodePort"},
+		},
+		Config: map[string]interface{}{"imageTag": "2020.6.1"},
+	}
+	assert.Equal(InstanceSummary{App: "blackduck", Name: "bd", Namespace: "ns", ChartVersion: "2020.6.0", AppVersion: "2020.6.1", Expose: "None"}, summarizeRelease("blackduck", "bd", rel, []string{"imageTag"}))
+
+	rel.Config["exposeui"] = true
+	assert.Equal("NodePort", summarizeRelease("blackduck", "bd", rel, []string{"imageTag"}).Expose)
+}
+
+func TestAnalyzeReleaseUpgrade(t *testing.T) {
+	assert := assert.New(t)
+
+	rel := &release.Release{
+		Name:      "bd",
+		Namespace: "ns",
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "blackduck", Version: "2020.4.0"}},
+		Manifest:  "apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: bd-cleanup\n",
+	}
+
+	impact, err := analyzeReleaseUpgrade("blackduck", "bd", rel, "1.24", nil)
+	assert.NoError(err)
+	assert.Empty(impact.Findings)
+	assert.Equal("none", impact.Remediation)
+
+	minimumChart := func(app string, rel *release.Release) (string, error) {
+		return "https://repo/blackduck-2020.10.0.tgz", nil
+	}
+	impact, err = analyzeReleaseUpgrade("blackduck", "bd", rel, "1.25", minimumChart)
+	assert.NoError(err)
+	assert.Len(impact.Findings, 1)
+	assert.Equal("2020.4.0", impact.ChartVersion)
+	assert.Equal("2020.10.0", impact.MinimumVersion)
+	assert.Equal("upgrade to 2020.10.0 or later before upgrading the cluster", impact.Remediation)
+
+	noChart := func(app string, rel *release.Release) (string, error) { return "", nil }
+	impact, err = analyzeReleaseUpgrade("blackduck", "bd", rel, "1.25", noChart)
+	assert.NoError(err)
+	assert.Empty(impact.MinimumVersion)
+	assert.Contains(impact.Remediation, "no released version")
+
+	failing := func(app string, rel *release.Release) (string, error) { return "", fmt.Errorf("offline") }
+	_, err = analyzeReleaseUpgrade("blackduck", "bd", rel, "1.25", failing)
+	assert.Error(err)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Statuses of the verification checks
+const (
+	VerificationPass = "pass"
+	VerificationFail = "fail"
+	VerificationSkip = "skip"
+)
+
+// VerificationResult is the result of a post-install verification check
+type VerificationResult struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewVerificationResult returns the result of a check with a formatted message
+func NewVerificationResult(check string, status string, format string, args ...interface{}) VerificationResult {
+	return VerificationResult{Check: check, Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// VerificationReport is the result of the post-install verification of an instance, it passes if none of its checks
+// failed
+type VerificationReport struct {
+	Product    string               `json:"product"`
+	Name       string               `json:"name"`
+	Namespace  string               `json:"namespace"`
+	Version    string               `json:"version"`
+	VerifiedAt time.Time            `json:"verifiedAt"`
+	Passed     bool                 `json:"passed"`
+	Results    []VerificationResult `json:"results"`
+}
+
+// Failed returns the results of the checks that failed
+func (r *VerificationReport) Failed() []VerificationResult {
+	failed := []VerificationResult{}
+	for _, result := range r.Results {
+		if result.Status == VerificationFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// VerificationTarget is the instance the checks of a verification run against
+type VerificationTarget struct {
+	Product Product
+	Clients Clients
+	// Version is the version of the product the Helm release of the instance deploys
+	Version string
+}
+
+// ProxyGet calls a path of a service of the instance through the service proxy of the Kubernetes API server, so that
+// the instance doesn't have to be exposed
+func (t *VerificationTarget) ProxyGet(scheme string, service string, port string, path string) ([]byte, error) {
+	return proxyGet(t.Clients, t.Product.Namespace(), scheme, service, port, path)
+}
+
+// VerificationCheck is a post-install check that a product contributes to the verification of its instances
+type VerificationCheck struct {
+	Name string
+	Run  func(target *VerificationTarget) VerificationResult
+}
+
+// ComponentsReadyCheck checks that all deployments and stateful sets of the instance are ready, it runs first for all
+// products since the checks of the products need a running instance
+var ComponentsReadyCheck = VerificationCheck{
+	Name: "components-ready",
+	Run: func(target *VerificationTarget) VerificationResult {
+		components, err := target.Product.Components()
+		if err != nil {
+			return NewVerificationResult("components-ready", VerificationFail, "%+v", err)
+		}
+		health := EvaluateHealth(components)
+		if !health.Healthy {
+			return NewVerificationResult("components-ready", VerificationFail, "%s", strings.Join(health.Findings, ", "))
+		}
+		return NewVerificationResult("components-ready", VerificationPass, "%d component(s) are ready", len(components))
+	},
+}
+
+// RunVerificationChecks runs the checks against the instance. The checks of the product are skipped if its components
+// aren't ready
+func RunVerificationChecks(target *VerificationTarget, checks []VerificationCheck) *VerificationReport {
+	report := &VerificationReport{
+		Product:    target.Product.App(),
+		Name:       target.Product.Name(),
+		Namespace:  target.Product.Namespace(),
+		Version:    target.Version,
+		VerifiedAt: time.Now(),
+		Passed:     true,
+		Results:    []VerificationResult{},
+	}
+	ready := ComponentsReadyCheck.Run(target)
+	report.Results = append(report.Results, ready)
+	for _, check := range checks {
+		result := NewVerificationResult(check.Name, VerificationSkip, "the components of the instance aren't ready")
+		if ready.Status == VerificationPass {
+			result = check.Run(target)
+			result.Check = check.Name
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Passed = len(report.Failed()) == 0
+	return report
+}
+
+// ParseReportedVersion returns the version in the JSON response of a version or about endpoint of a product
+func ParseReportedVersion(body []byte) (string, error) {
+	response := struct {
+		Version string `json:"version"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("unable to parse the version of the response due to %+v", err)
+	}
+	if len(response.Version) == 0 {
+		return "", fmt.Errorf("the response has no version")
+	}
+	return response.Version, nil
+}
+
+// CheckReportedVersion compares the version an instance reports with the version of its Helm release
+func CheckReportedVersion(check string, reported string, expected string) VerificationResult {
+	if len(expected) == 0 {
+		return NewVerificationResult(check, VerificationSkip, "the release doesn't set the version, the instance reports '%s'", reported)
+	}
+	if strings.TrimPrefix(reported, "v") != strings.TrimPrefix(expected, "v") {
+		return NewVerificationResult(check, VerificationFail, "the instance reports version '%s' but the release deploys '%s'", reported, expected)
+	}
+	return NewVerificationResult(check, VerificationPass, "the instance reports version '%s'", reported)
+}
+
+// NewServiceEndpointsCheck returns a check that a service of the instance has ready endpoints, e.g. that a scan
+// container registered with the service
+func NewServiceEndpointsCheck(check string, service string) VerificationCheck {
+	return VerificationCheck{
+		Name: check,
+		Run: func(target *VerificationTarget) VerificationResult {
+			endpoints, err := target.Clients.KubeClient.CoreV1().Endpoints(target.Product.Namespace()).Get(service, metav1.GetOptions{})
+			if err != nil {
+				return NewVerificationResult(check, VerificationFail, "unable to get the endpoints of service '%s' due to %+v", service, err)
+			}
+			ready := 0
+			for _, subset := range endpoints.Subsets {
+				ready += len(subset.Addresses)
+			}
+			if ready == 0 {
+				return NewVerificationResult(check, VerificationFail, "service '%s' has no ready endpoints", service)
+			}
+			return NewVerificationResult(check, VerificationPass, "service '%s' has %d ready endpoint(s)", service, ready)
+		},
+	}
+}
+
+// NewPageCheck returns a check that a page of a service of the instance responds, e.g. the login page
+func NewPageCheck(check string, scheme string, service string, port string, path string) VerificationCheck {
+	return VerificationCheck{
+		Name: check,
+		Run: func(target *VerificationTarget) VerificationResult {
+			if _, err := target.ProxyGet(scheme, service, port, path); err != nil {
+				return NewVerificationResult(check, VerificationFail, "page '/%s' of service '%s' is not reachable: %+v", path, service, err)
+			}
+			return NewVerificationResult(check, VerificationPass, "page '/%s' of service '%s' is reachable", path, service)
+		},
+	}
+}
+
+// NewVersionCheck returns a check that the version reported by an endpoint of a service matches the release
+func NewVersionCheck(check string, scheme string, service string, port string, path string) VerificationCheck {
+	return VerificationCheck{
+		Name: check,
+		Run: func(target *VerificationTarget) VerificationResult {
+			body, err := target.ProxyGet(scheme, service, port, path)
+			if err != nil {
+				return NewVerificationResult(check, VerificationFail, "endpoint '/%s' of service '%s' failed: %+v", path, service, err)
+			}
+			reported, err := ParseReportedVersion(body)
+			if err != nil {
+				return NewVerificationResult(check, VerificationFail, "%+v", err)
+			}
+			return CheckReportedVersion(check, reported, target.Version)
+		},
+	}
+}
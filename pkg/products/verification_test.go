@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package products
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProduct is a product with fixed components
+type fakeProduct struct {
+	instance
+	components []Component
+}
+
+func (p *fakeProduct) Components() ([]Component, error) {
+	return p.components, nil
+}
+
+func TestRunVerificationChecks(t *testing.T) {
+	assert := assert.New(t)
+
+	product := &fakeProduct{instance: instance{app: "blackduck", name: "bd", namespace: "ns"}, components: []Component{{Name: "webserver", Kind: "Deployment", Replicas: 1, ReadyReplicas: 1}}}
+	checks := []VerificationCheck{
+		{Name: "login-page", Run: func(target *VerificationTarget) VerificationResult {
+			return NewVerificationResult("", VerificationPass, "reachable")
+		}},
+		{Name: "api-version", Run: func(target *VerificationTarget) VerificationResult {
+			return CheckReportedVersion("api-version", "2020.6.0", target.Version)
+		}},
+	}
+
+	report := RunVerificationChecks(&VerificationTarget{Product: product, Version: "2020.6.0"}, checks)
+	assert.True(report.Passed)
+	assert.Equal("blackduck", report.Product)
+	assert.Equal("bd", report.Name)
+	assert.Equal([]string{"components-ready", "login-page", "api-version"}, []string{report.Results[0].Check, report.Results[1].Check, report.Results[2].Check})
+	assert.Empty(report.Failed())
+
+	report = RunVerificationChecks(&VerificationTarget{Product: product, Version: "2020.8.0"}, checks)
+	assert.False(report.Passed)
+	assert.Len(report.Failed(), 1)
+	assert.Equal("api-version", report.Failed()[0].Check)
+
+	// the checks of the product are skipped until the components are ready
+	product.components[0].ReadyReplicas = 0
+	report = RunVerificationChecks(&VerificationTarget{Product: product, Version: "2020.6.0"}, checks)
+	assert.False(report.Passed)
+	assert.Equal(VerificationFail, report.Results[0].Status)
+	assert.Equal(VerificationSkip, report.Results[1].Status)
+	assert.Equal(VerificationSkip, report.Results[2].Status)
+}
+
+func TestParseReportedVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	version, err := ParseReportedVersion([]byte(`{"version":"2020.6.0"}`))
+	assert.NoError(err)
+	assert.Equal("2020.6.0", version)
+
+	_, err = ParseReportedVersion([]byte(`{}`))
+	assert.Error(err)
+	_, err = ParseReportedVersion([]byte(`<html>`))
+	assert.Error(err)
+}
+
+func TestCheckReportedVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(VerificationPass, CheckReportedVersion("api-version", "5.3.1", "5.3.1").Status)
+	assert.Equal(VerificationPass, CheckReportedVersion("api-version", "v5.3.1", "5.3.1").Status)
+	assert.Equal(VerificationFail, CheckReportedVersion("api-version", "5.3.0", "5.3.1").Status)
+	assert.Equal(VerificationSkip, CheckReportedVersion("api-version", "5.3.0", "").Status)
+}
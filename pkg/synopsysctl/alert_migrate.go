@@ -103,7 +103,7 @@ func migrateAlert(alert *v1.Alert, helmReleaseName string, operatorNamespace str
 		}
 	}
 
-	// Update exposed Services for Alert
+	// Update exposed Services for Alert, the exposed service and route of the operator are relabeled with the release name
 	err = alertctl.CRUDServiceOrRoute(restconfig, kubeClient, namespace, alert.Name, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], updateService)
 	if err != nil {
 		return fmt.Errorf("failed to update Alert's exposed service %+v", err)
@@ -152,7 +152,7 @@ func migrateAlert(alert *v1.Alert, helmReleaseName string, operatorNamespace str
 	// Verify Alert can be created with Dry-Run before creating resources
 	err = util.CreateWithHelm3(helmReleaseName, alert.Spec.Namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath, true)
 	if err != nil {
-		return fmt.Errorf("failed to update Alert resources dry-run: %+v", err)
+		return fmt.Errorf("failed to update Alert resources dry-run: %w", err)
 	}
 
 	// Update the Secrets
@@ -183,38 +183,11 @@ func migrateAlert(alert *v1.Alert, helmReleaseName string, operatorNamespace str
 		}
 	}
 
-	// If it exists, rename the old exposed service/route to use the new Alert's release name
-	isOpenShift := util.IsOpenshift(kubeClient)
-	svc, err := util.GetService(kubeClient, namespace, fmt.Sprintf("%s-exposed", helmReleaseName))
-	if err == nil {
-		svc.Kind = "Service"
-		svc.APIVersion = "v1"
-		svc.Labels = util.InitLabels(svc.Labels)
-		svc.Labels["name"] = helmReleaseName
-		svc.Spec.Selector = util.InitLabels(svc.Spec.Selector)
-		svc.Spec.Selector["name"] = helmReleaseName
-		if _, err = kubeClient.CoreV1().Services(namespace).Update(svc); err != nil {
-			return fmt.Errorf("failed to update Alert's exposed service due to %s", err)
-		}
-	} else if isOpenShift {
-		routeClient := util.GetRouteClient(restconfig, kubeClient, namespace)
-		route, err := util.GetRoute(routeClient, namespace, fmt.Sprintf("%s-exposed", helmReleaseName))
-		if err == nil {
-			route.Kind = "Route"
-			route.APIVersion = "v1"
-			route.Labels = util.InitLabels(route.Labels)
-			route.Labels["name"] = helmReleaseName
-			if _, err = routeClient.Routes(namespace).Update(route); err != nil {
-				return fmt.Errorf("failed to update Alert's route due to %s", err)
-			}
-		}
-	}
-
 	// Deploy new Resources
 	err = util.CreateWithHelm3(helmReleaseName, alert.Spec.Namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath, false)
 	if err != nil {
 		cleanErrorMsg := strings.Replace(err.Error(), helmReleaseName, alert.Name, 0)
-		return fmt.Errorf("failed to update Alert resources: %+v", cleanErrorMsg)
+		return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to update Alert resources: %+v", cleanErrorMsg))
 	}
 
 	log.Info("deleting Alert custom resource")
@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// cloneDBFrom is the NAMESPACE/NAME of the Black Duck instance whose databases are cloned into a new instance
+var cloneDBFrom = ""
+
+// cloneDBTimeout is the maximum time the databases may take to be cloned
+var cloneDBTimeout = 2 * time.Hour
+
+// parseCloneSource returns the namespace and name of the Black Duck instance in --clone-db-from
+func parseCloneSource(source string) (string, string, error) {
+	parts := strings.Split(source, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", util.ValidationError("--clone-db-from must be in the format NAMESPACE/NAME, but got '%s'", source)
+	}
+	return parts[0], parts[1], nil
+}
+
+// verifyCloneSource checks that the Black Duck instance to clone the databases from exists and isn't newer than the
+// version of the new instance
+func verifyCloneSource(source string, version string) error {
+	sourceNamespace, sourceName, err := parseCloneSource(source)
+	if err != nil {
+		return err
+	}
+	sourceRelease, err := util.GetWithHelm3(sourceName, sourceNamespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' to clone the databases from", sourceName, sourceNamespace)
+	}
+	sourceVersion, _ := util.GetValueFromRelease(sourceRelease, []string{"imageTag"}).(string)
+	if len(sourceVersion) > 0 && util.CompareVersions(sourceVersion, version) > 0 {
+		return fmt.Errorf("the databases of Black Duck %s can't be cloned into the older version %s", sourceVersion, version)
+	}
+	return nil
+}
+
+// getBlackDuckPostgresEndpoint returns the Postgres of a Black Duck release and the credentials of its admin user
+func getBlackDuckPostgresEndpoint(rel *release.Release) (bdutil.PostgresEndpoint, error) {
+	endpoint := getBlackDuckPostgresServer(rel)
+	_, adminPassword, err := bdutil.GetHubDBPasswordWithRetry(kubeClient, rel.Namespace, rel.Name, bdutil.DefaultDBPasswordOptions)
+	if err != nil {
+		return endpoint, err
+	}
+	endpoint.Password = adminPassword
+	return endpoint, nil
+}
+
+// getBlackDuckPostgresServer returns the host, port and admin user of the Postgres of a Black Duck release, the
+// internal Postgres unless the release uses an external one
+func getBlackDuckPostgresServer(rel *release.Release) bdutil.PostgresEndpoint {
+	endpoint := bdutil.PostgresEndpoint{
+		Host: fmt.Sprintf("%s.%s.svc.cluster.local", util.GetResourceName(rel.Name, util.BlackDuckName, "postgres"), rel.Namespace),
+		Port: 5432,
+		User: "blackduck",
+	}
+	if isExternal, _ := util.GetValueFromRelease(rel, []string{"postgres", "isExternal"}).(bool); isExternal {
+		endpoint.Host, _ = util.GetValueFromRelease(rel, []string{"postgres", "host"}).(string)
+	}
+	switch port := util.GetValueFromRelease(rel, []string{"postgres", "port"}).(type) {
+	case int:
+		endpoint.Port = port
+	case float64:
+		endpoint.Port = int(port)
+	}
+	if user, ok := util.GetValueFromRelease(rel, []string{"postgres", "adminUserName"}).(string); ok && len(user) > 0 {
+		endpoint.User = user
+	}
+	return endpoint
+}
+
+// cloneBlackDuckDatabases restores the databases of the source instance into a newly created instance. The other
+// components of the new instance are scaled down while the databases are restored
+func cloneBlackDuckDatabases(source string, name string, namespace string) error {
+	sourceNamespace, sourceName, err := parseCloneSource(source)
+	if err != nil {
+		return err
+	}
+	sourceRelease, err := util.GetWithHelm3(sourceName, sourceNamespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' to clone the databases from", sourceName, sourceNamespace)
+	}
+	targetRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' to clone the databases into", name, namespace)
+	}
+	sourceEndpoint, err := getBlackDuckPostgresEndpoint(sourceRelease)
+	if err != nil {
+		return err
+	}
+	targetEndpoint, err := getBlackDuckPostgresEndpoint(targetRelease)
+	if err != nil {
+		return err
+	}
+
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+
+	// stop the components that use the database, the Postgres of the instance keeps running
+	labelSelector := fmt.Sprintf("app=%s, name=%s, component!=postgres", util.BlackDuckName, name)
+	deployments, err := util.ListDeployments(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list the deployments of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	replicas := map[string]*int32{}
+	stopped := []*appsv1.Deployment{}
+	zero := int32(0)
+	var cloneErr error
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		replicas[deployment.Name] = deployment.Spec.Replicas
+		stoppedDeployment, err := util.PatchDeploymentForReplicas(kubeClient, deployment, &zero)
+		if err != nil {
+			cloneErr = fmt.Errorf("couldn't scale down deployment '%s' in namespace '%s' due to %+v", deployment.Name, namespace, err)
+			break
+		}
+		stopped = append(stopped, stoppedDeployment)
+	}
+
+	if cloneErr == nil {
+		util.WithStep(logger, "clone").Infof("cloning the databases of Black Duck '%s' in namespace '%s', this may take a while", sourceName, sourceNamespace)
+		cloneErr = bdutil.CloneDatabaseJob(kubeClient, namespace, name, globals.DefaultPostgresClientImage, sourceEndpoint, targetEndpoint, cloneDBTimeout)
+	}
+
+	// start the components again even if the clone failed
+	for _, deployment := range stopped {
+		if _, err := util.PatchDeploymentForReplicas(kubeClient, deployment, replicas[deployment.Name]); err != nil {
+			util.WithStep(logger, "scale-up").Errorf("couldn't scale up deployment '%s' in namespace '%s' due to %+v", deployment.Name, namespace, err)
+		}
+	}
+	if cloneErr != nil {
+		return fmt.Errorf("failed to clone the databases of Black Duck '%s' in namespace '%s': %w", sourceName, sourceNamespace, cloneErr)
+	}
+	util.WithStep(logger, "clone").Infof("cloned the databases of Black Duck '%s' in namespace '%s'", sourceName, sourceNamespace)
+	return nil
+}
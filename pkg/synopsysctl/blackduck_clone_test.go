@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestParseCloneSource(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		source    string
+		namespace string
+		name      string
+		hasError  bool
+	}{
+		{source: "ns/bd", namespace: "ns", name: "bd"},
+		{source: "bd", hasError: true},
+		{source: "ns/", hasError: true},
+		{source: "/bd", hasError: true},
+		{source: "ns/bd/extra", hasError: true},
+		{source: "", hasError: true},
+	}
+
+	for _, test := range tests {
+		namespace, name, err := parseCloneSource(test.source)
+		if test.hasError {
+			assert.Error(err, "source '%s'", test.source)
+			continue
+		}
+		assert.NoError(err, "source '%s'", test.source)
+		assert.Equal(test.namespace, namespace)
+		assert.Equal(test.name, name)
+	}
+}
+
+func TestGetBlackDuckPostgresServer(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		description string
+		config      map[string]interface{}
+		expected    bdutil.PostgresEndpoint
+	}{
+		{
+			description: "internal postgres",
+			config:      map[string]interface{}{},
+			expected:    bdutil.PostgresEndpoint{Host: "bd-blackduck-postgres.ns.svc.cluster.local", Port: 5432, User: "blackduck"},
+		},
+		{
+			description: "internal postgres with another admin user",
+			config:      map[string]interface{}{"postgres": map[string]interface{}{"adminUserName": "postgres"}},
+			expected:    bdutil.PostgresEndpoint{Host: "bd-blackduck-postgres.ns.svc.cluster.local", Port: 5432, User: "postgres"},
+		},
+		{
+			description: "external postgres with the port of a JSON number",
+			config:      map[string]interface{}{"postgres": map[string]interface{}{"isExternal": true, "host": "db.example.com", "port": float64(6543), "adminUserName": "admin"}},
+			expected:    bdutil.PostgresEndpoint{Host: "db.example.com", Port: 6543, User: "admin"},
+		},
+		{
+			description: "external postgres with the port of an integer",
+			config:      map[string]interface{}{"postgres": map[string]interface{}{"isExternal": true, "host": "db.example.com", "port": 6543}},
+			expected:    bdutil.PostgresEndpoint{Host: "db.example.com", Port: 6543, User: "blackduck"},
+		},
+	}
+
+	for _, test := range tests {
+		rel := &release.Release{Name: "bd", Namespace: "ns", Chart: &chart.Chart{Values: map[string]interface{}{}}, Config: test.config}
+		assert.Equal(test.expected, getBlackDuckPostgresServer(rel), test.description)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/datamover"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Move Command Options and Defaults
+var moveToContext = ""
+var moveToKubeConfigPath = ""
+var moveToNamespace = ""
+var moveBackupDir = ""
+var moveDecommissionSource = false
+
+// moveBlackDuckToCluster moves a Black Duck instance to the namespace of the cluster of the target context. The
+// databases of the source are backed up while its components are stopped, its secrets and volumes are copied, the
+// chart is installed on the target with the values of the source, the databases are restored into it and the target
+// is verified. The source is deleted with decommission, and started again otherwise
+func moveBlackDuckToCluster(flags *pflag.FlagSet, name string, namespace string, targetContext string, targetNamespace string) error {
+	dir, err := ioutil.TempDir("", "synopsysctl-move-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	kubeconfig := moveToKubeConfigPath
+	if len(kubeconfig) == 0 {
+		kubeconfig = kubeConfigPath
+	}
+	// the Helm helpers only take the path of a kubeconfig, so the target context is written to its own kubeconfig
+	targetKubeconfig, err := util.WriteKubeconfigForContext(kubeconfig, targetContext, dir)
+	if err != nil {
+		return err
+	}
+
+	rel, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	version, _ := util.GetValueFromRelease(rel, []string{"imageTag"}).(string)
+	if err := UpdateHelmChartLocation(flags, globals.BlackDuckChartName, version, &globals.BlackDuckChartRepository); err != nil {
+		return fmt.Errorf("failed to set the app resources location due to %+v", err)
+	}
+	helmValuesMap := rel.Config
+
+	decommission := "started again"
+	if moveDecommissionSource {
+		decommission = "deleted with its volumes"
+	}
+	if err := confirmDestructiveAction(fmt.Sprintf("this will stop Black Duck '%s' in namespace '%s', move it to namespace '%s' of context '%s', and the source will be %s", name, namespace, targetNamespace, targetContext, decommission)); err != nil {
+		return err
+	}
+	// the steps of the move don't ask again
+	assumeYes = true
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+
+	instanceSecrets, err := getInstanceSecrets(util.BlackDuckName, name, namespace)
+	if err != nil {
+		return err
+	}
+	secrets := []*corev1.Secret{}
+	for _, instanceSecret := range instanceSecrets {
+		secrets = append(secrets, instanceSecret.Secret)
+	}
+	secrets = util.GetSecretsForCluster(secrets, targetNamespace)
+
+	// the databases are restored from the backup, the other volumes are copied
+	claims, err := util.ListPVCs(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name))
+	if err != nil {
+		return fmt.Errorf("couldn't list the persistent volume claims of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	claimNames := []string{}
+	for _, claim := range claims.Items {
+		if claim.Name != util.GetResourceName(name, util.BlackDuckName, "postgres") {
+			claimNames = append(claimNames, claim.Name)
+		}
+	}
+
+	if util.ActivePlan != nil {
+		return planBlackDuckMove(kubeClient, name, namespace, targetNamespace, secrets)
+	}
+
+	// every component but postgres is stopped so that the data doesn't change while it is moved
+	util.EmitProgress(util.ProgressPhaseMigrate, "stop", 0, "stopping the components of Black Duck '%s'", name)
+	startSource, err := stopDeployments(logger, namespace, fmt.Sprintf("app=%s, name=%s, component!=postgres", util.BlackDuckName, name))
+	if err != nil {
+		startSource()
+		return err
+	}
+	source := datamover.Volume{Namespace: namespace, KubeClient: kubeClient, RestConfig: restconfig}
+	for _, claimName := range claimNames {
+		if err := waitForPodsToReleasePVC(namespace, claimName, migrateTimeout); err != nil {
+			startSource()
+			return err
+		}
+	}
+
+	backupDir := moveBackupDir
+	if len(backupDir) == 0 {
+		backupDir = dir
+	}
+	util.EmitProgress(util.ProgressPhaseMigrate, "backup", 10, "backing up the databases of Black Duck '%s' to '%s'", name, backupDir)
+	backupName, err := backupBlackDuck(name, namespace, util.BackupDestination{Kind: util.BackupDestinationDirectory, Location: backupDir})
+	if err != nil {
+		startSource()
+		return err
+	}
+
+	restoreSource, err := useKubeconfig(targetKubeconfig)
+	if err != nil {
+		restoreSource()
+		startSource()
+		return err
+	}
+	err = installBlackDuckOnCluster(name, targetNamespace, helmValuesMap, secrets, source, claimNames, filepath.Join(backupDir, backupName))
+	restoreSource()
+	if err != nil {
+		startSource()
+		if len(moveBackupDir) > 0 {
+			return fmt.Errorf("failed to move Black Duck '%s' to context '%s', the source was started again and its backup kept in '%s': %w", name, targetContext, filepath.Join(backupDir, backupName), err)
+		}
+		return fmt.Errorf("failed to move Black Duck '%s' to context '%s', the source was started again: %w", name, targetContext, err)
+	}
+
+	if !moveDecommissionSource {
+		startSource()
+		util.WithStep(logger, "decommission").Warnf("Black Duck '%s' now runs in both clusters, delete the source in namespace '%s' once the clients use the target", name, namespace)
+		util.EmitProgress(util.ProgressPhaseMigrate, "done", 100, "moved Black Duck '%s' to namespace '%s' of context '%s'", name, targetNamespace, targetContext)
+		return nil
+	}
+	util.EmitProgress(util.ProgressPhaseMigrate, "decommission", 95, "deleting Black Duck '%s' in namespace '%s' of the source cluster", name, namespace)
+	err = deleteBlackDuck(name, namespace)
+	if err == nil {
+		err = deletePVCs(namespace, fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name))
+	}
+	if err != nil {
+		return util.WithExitCode(util.ExitCodePartialSuccess, fmt.Errorf("moved Black Duck '%s' to context '%s' but couldn't delete the source: %w", name, targetContext, err))
+	}
+	util.EmitProgress(util.ProgressPhaseMigrate, "done", 100, "moved Black Duck '%s' to namespace '%s' of context '%s'", name, targetNamespace, targetContext)
+	return nil
+}
+
+// planBlackDuckMove records the changes of the move in the active plan without making them. The source is only read,
+// the namespace, secrets and release of the target are recorded from the values of the source
+func planBlackDuckMove(kubeClient kubernetes.Interface, name string, namespace string, targetNamespace string, secrets []*corev1.Secret) error {
+	deployments, err := util.ListDeployments(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s, component!=postgres", util.BlackDuckName, name))
+	if err != nil {
+		return fmt.Errorf("couldn't list the deployments of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	for _, deployment := range deployments.Items {
+		skipForPlan(util.PlanActionUpdate, "Deployment", deployment.Name, namespace)
+	}
+	skipForPlan(util.PlanActionApply, "Namespace", targetNamespace, "")
+	for _, secret := range secrets {
+		skipForPlan(util.PlanActionApply, "Secret", secret.Name, targetNamespace)
+	}
+	util.ActivePlan.AddHelmOperation(util.PlanHelmInstall, name, targetNamespace, globals.BlackDuckChartRepository)
+	if moveDecommissionSource {
+		util.ActivePlan.AddHelmOperation(util.PlanHelmUninstall, name, namespace, globals.BlackDuckChartRepository)
+		claims, err := util.ListPVCs(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name))
+		if err != nil {
+			return fmt.Errorf("couldn't list the persistent volume claims of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		for _, claim := range claims.Items {
+			skipForPlan(util.PlanActionDelete, "PersistentVolumeClaim", claim.Name, namespace)
+		}
+	}
+	return util.ErrPlanComplete
+}
+
+// installBlackDuckOnCluster installs the chart with the values of the source instance on the cluster of the global
+// clients, copies the volumes of the source into its claims, restores the databases from the backup directory and
+// verifies the instance
+func installBlackDuckOnCluster(name string, namespace string, helmValuesMap map[string]interface{}, secrets []*corev1.Secret, source datamover.Volume, claimNames []string, backupDir string) error {
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+	if _, err := util.GetNamespace(kubeClient, namespace); k8serrors.IsNotFound(err) {
+		if _, err := util.CreateNamespace(kubeClient, namespace); err != nil {
+			return fmt.Errorf("unable to create namespace '%s' due to %+v", namespace, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unable to get namespace '%s' due to %+v", namespace, err)
+	}
+	if existing, err := util.GetWithHelm3(name, namespace, kubeConfigPath); err == nil && existing != nil {
+		return util.ValidationError("release '%s' already exists in namespace '%s' of the target cluster", name, namespace)
+	}
+
+	util.EmitProgress(util.ProgressPhaseMigrate, "secrets", 30, "creating %d secrets of Black Duck '%s'", len(secrets), name)
+	for _, secret := range secrets {
+		existing, err := util.GetSecret(kubeClient, namespace, secret.Name)
+		switch {
+		case k8serrors.IsNotFound(err):
+			_, err = kubeClient.CoreV1().Secrets(namespace).Create(secret)
+		case err == nil:
+			existing.Labels = secret.Labels
+			existing.Data = secret.Data
+			_, err = util.UpdateSecret(kubeClient, namespace, existing)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to create secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+		}
+	}
+
+	// the source may run on the other kind of cluster
+	util.SetHelmValueInMap(helmValuesMap, []string{"isKubernetes"}, !util.IsOpenshift(kubeClient))
+	util.EmitProgress(util.ProgressPhaseMigrate, "install", 40, "installing Black Duck '%s' in namespace '%s'", name, namespace)
+	if err := util.CreateWithHelm3(name, namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, true); err != nil {
+		return fmt.Errorf("failed to create Blackduck resources: %w", err)
+	}
+	if err := util.CreateWithHelm3(name, namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, false); err != nil {
+		return fmt.Errorf("failed to create Blackduck resources: %w", err)
+	}
+	if err := blackduck.CRUDServiceOrRoute(restconfig, kubeClient, namespace, name, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], true); err != nil {
+		return err
+	}
+	if err := applyExposureIngress(util.BlackDuckName, name, namespace, helmValuesMap); err != nil {
+		return err
+	}
+
+	if len(claimNames) > 0 {
+		startStopped, err := stopDeployments(logger, namespace, fmt.Sprintf("app=%s, name=%s, component!=postgres", util.BlackDuckName, name))
+		if err != nil {
+			startStopped()
+			return err
+		}
+		for i, claimName := range claimNames {
+			if err := waitForPodsToReleasePVC(namespace, claimName, migrateTimeout); err != nil {
+				startStopped()
+				return err
+			}
+			util.EmitProgress(util.ProgressPhaseMigrate, "volumes", 50+10*i/len(claimNames), "copying the data of persistent volume claim '%s'", claimName)
+			source.ClaimName = claimName
+			target := datamover.Volume{Namespace: namespace, ClaimName: claimName, KubeClient: kubeClient, RestConfig: restconfig}
+			if err := datamover.Move(migrateVolumeDataMover, source, target, migrateVolumeOptions); err != nil {
+				startStopped()
+				return err
+			}
+		}
+		startStopped()
+	}
+
+	// the new instance created the tables of its databases when it started
+	util.EmitProgress(util.ProgressPhaseMigrate, "restore", 60, "restoring the databases of Black Duck '%s' from '%s'", name, backupDir)
+	restoreForce = true
+	if err := restoreBlackDuck(name, namespace, util.BackupDestination{Kind: util.BackupDestinationDirectory, Location: backupDir}); err != nil {
+		return err
+	}
+
+	// the move is only complete once the target is ready and passes the post-install checks
+	util.EmitProgress(util.ProgressPhaseMigrate, "verify", 90, "verifying Black Duck '%s' in namespace '%s'", name, namespace)
+	waitForReady, verifyAfterWait = true, true
+	return waitForInstanceReady(util.BlackDuckName, name, namespace)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPlanBlackDuckMove(t *testing.T) {
+	assert := assert.New(t)
+	defer func(decommission bool) {
+		util.ActivePlan = nil
+		moveDecommissionSource = decommission
+	}(moveDecommissionSource)
+
+	labels := map[string]string{"app": util.BlackDuckName, "name": "hub"}
+	webappLabels := map[string]string{"app": util.BlackDuckName, "name": "hub", "component": "webapp"}
+	postgresLabels := map[string]string{"app": util.BlackDuckName, "name": "hub", "component": "postgres"}
+	secrets := []*corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "hub-blackduck-db-creds"}}}
+
+	tests := []struct {
+		decommission bool
+		helmOps      []string
+		claims       int
+	}{
+		{decommission: false, helmOps: []string{util.PlanHelmInstall}, claims: 0},
+		{decommission: true, helmOps: []string{util.PlanHelmInstall, util.PlanHelmUninstall}, claims: 1},
+	}
+
+	for _, test := range tests {
+		kubeClient := fake.NewSimpleClientset(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "hub-blackduck-webapp", Namespace: "source", Labels: webappLabels}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "hub-blackduck-postgres", Namespace: "source", Labels: postgresLabels}},
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "hub-blackduck-webapp", Namespace: "source", Labels: labels}},
+		)
+		moveDecommissionSource = test.decommission
+		plan := util.StartPlan("synopsysctl migrate blackduck")
+
+		assert.Equal(util.ErrPlanComplete, planBlackDuckMove(kubeClient, "hub", "source", "target", secrets))
+		for _, action := range kubeClient.Actions() {
+			assert.Equal("list", action.GetVerb())
+		}
+		assert.True(plan.Complete)
+
+		helmOps := []string{}
+		for _, operation := range plan.HelmOperations {
+			helmOps = append(helmOps, operation.Operation)
+		}
+		assert.Equal(test.helmOps, helmOps)
+		assert.Equal("target", plan.HelmOperations[0].Namespace)
+		assert.Equal([]util.PlanResource{{Action: util.PlanActionApply, Kind: "Secret", Name: "hub-blackduck-db-creds", Namespace: "target"}}, plan.Secrets)
+
+		// only the components other than postgres are stopped
+		assert.Contains(plan.Resources, util.PlanResource{Action: util.PlanActionUpdate, Kind: "Deployment", Name: "hub-blackduck-webapp", Namespace: "source"})
+		assert.NotContains(plan.Resources, util.PlanResource{Action: util.PlanActionUpdate, Kind: "Deployment", Name: "hub-blackduck-postgres", Namespace: "source"})
+		assert.Contains(plan.Resources, util.PlanResource{Action: util.PlanActionApply, Kind: "Namespace", Name: "target"})
+
+		claims := 0
+		for _, resource := range plan.Resources {
+			if resource.Kind == "PersistentVolumeClaim" && resource.Action == util.PlanActionDelete {
+				claims++
+			}
+		}
+		assert.Equal(test.claims, claims)
+	}
+}
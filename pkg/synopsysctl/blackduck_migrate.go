@@ -27,6 +27,7 @@ import (
 
 	v1 "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
 	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/imdario/mergo"
@@ -37,6 +38,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// migrateDBPasswordOptions configures how the database passwords of an instance of the operator are retrieved if its
+// spec doesn't have them
+var migrateDBPasswordOptions = bdutil.DefaultDBPasswordOptions
+
 // migrate migrates from synopsys operator to Helm based deployment
 func migrate(bd *v1.Blackduck, operatorNamespace string, crdNamespace string, flags *pflag.FlagSet) error {
 	// TODO ensure operator is installed and running a recent version that doesn't require additional migration
@@ -56,6 +61,12 @@ func migrate(bd *v1.Blackduck, operatorNamespace string, crdNamespace string, fl
 		return err
 	}
 
+	// the passwords of the flags are used instead of those of the operator's secret
+	if flags.Lookup("admin-password").Changed && flags.Lookup("user-password").Changed {
+		migrateDBPasswordOptions.AdminPassword = flags.Lookup("admin-password").Value.String()
+		migrateDBPasswordOptions.UserPassword = flags.Lookup("user-password").Value.String()
+	}
+
 	// Generate Helm configuration
 	helmValuesMap, err := blackDuckV1ToHelm(bd, operatorNamespace)
 	if err != nil {
@@ -141,7 +152,7 @@ func migrate(bd *v1.Blackduck, operatorNamespace string, crdNamespace string, fl
 
 	err = util.CreateWithHelm3(bd.Name, bd.Spec.Namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, true, extraFiles...)
 	if err != nil {
-		return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+		return fmt.Errorf("failed to create Blackduck resources: %w", err)
 	}
 
 	// Update Security Context Permissions
@@ -156,7 +167,7 @@ func migrate(bd *v1.Blackduck, operatorNamespace string, crdNamespace string, fl
 	// Deploy Resources
 	err = util.CreateWithHelm3(bd.Name, bd.Spec.Namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, false, extraFiles...)
 	if err != nil {
-		return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+		return fmt.Errorf("failed to create Blackduck resources: %w", err)
 	}
 
 	log.Info("removing Black Duck custom resource")
@@ -321,6 +332,13 @@ func blackDuckV1ToHelm(bd *v1.Blackduck, operatorNamespace string) (map[string]i
 		if err != nil {
 			return nil, err
 		}
+		// the operator keeps the passwords that it generated in its secret
+		if len(adminPassword) == 0 || len(userPassword) == 0 {
+			userPassword, adminPassword, err = bdutil.GetHubDBPasswordWithRetry(kubeClient, bd.Spec.Namespace, bd.Name, migrateDBPasswordOptions)
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		if bd.Spec.PersistentStorage {
 			util.SetHelmValueInMap(helmConfig, []string{"postgres", "adminUserName"}, "blackduck")
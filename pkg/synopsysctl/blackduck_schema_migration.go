@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// blackDuckMigrationStatusPeriod is the period of printing the progress of the migrations when the logs are quiet
+var blackDuckMigrationStatusPeriod = 30 * time.Second
+
+// followBlackDuckMigrations tails the logs of the pod running the database migrations of Black Duck after an upgrade
+// and prints their progress until they complete, the pod becomes ready or the timeout expires
+func followBlackDuckMigrations(name string, namespace string, since time.Time, timeout time.Duration) error {
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+	timeoutErr := util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("database migrations of Black Duck '%s' in namespace '%s' did not complete within %s, use --migration-timeout to wait longer", name, namespace, timeout))
+
+	log.Infof("waiting for the database migrations of Black Duck '%s' to start...", name)
+	var pod *corev1.Pod
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for pod == nil {
+		select {
+		case <-timeoutTimer.C:
+			return timeoutErr
+		case <-ticker.C:
+			var err error
+			if pod, err = getBlackDuckMigrationPod(name, namespace, since); err != nil {
+				return err
+			}
+		}
+	}
+
+	container := pod.Spec.Containers[0].Name
+	stream, err := kubeClient.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container, Follow: true}).Stream()
+	if err != nil {
+		return fmt.Errorf("unable to follow the logs of pod '%s' due to %+v", pod.Name, err)
+	}
+	defer stream.Close()
+	lines := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("following the database migrations in pod '%s'", pod.Name)
+	progress := util.NewMigrationProgress(time.Now())
+	statusTicker := time.NewTicker(blackDuckMigrationStatusPeriod)
+	defer statusTicker.Stop()
+	for {
+		select {
+		case <-timeoutTimer.C:
+			return timeoutErr
+		case line, ok := <-lines:
+			if !ok {
+				// the logs end when the container restarts
+				return fmt.Errorf("the logs of pod '%s' ended before the database migrations completed: %s", pod.Name, progress.Status(time.Now()))
+			}
+			if !progress.ParseLine(line) {
+				continue
+			}
+			log.Infof("%s", progress.Status(time.Now()))
+			util.EmitProgress(util.ProgressPhaseMigrate, progress.LastStep, progress.Percent(), "%s", progress.Status(time.Now()))
+			if len(progress.Failure) > 0 {
+				return fmt.Errorf("database migrations of Black Duck '%s' in namespace '%s' failed: %s", name, namespace, progress.Failure)
+			}
+			if progress.Done {
+				return nil
+			}
+		case <-statusTicker.C:
+			// the migrations run before the pod becomes ready
+			current, err := util.GetPod(kubeClient, namespace, pod.Name)
+			if err == nil && isPodReady(current) {
+				log.Infof("pod '%s' is ready, the database migrations completed", pod.Name)
+				return nil
+			}
+			// periodic status lines only clutter CI logs
+			if util.ShowProgress() {
+				log.Infof("%s", progress.Status(time.Now()))
+			}
+		}
+	}
+}
+
+// getBlackDuckMigrationPod returns the pod of a migration job of the instance, or the webapp pod created after the upgrade
+// started if there is no such job. It returns nil until the pod has a started container
+func getBlackDuckMigrationPod(name string, namespace string, since time.Time) (*corev1.Pod, error) {
+	pods, err := util.ListPodsWithLabels(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	var webappPod *corev1.Pod
+	for i, pod := range pods.Items {
+		if pod.CreationTimestamp.Time.Before(since.Add(-time.Second)) || !isPodStarted(&pod) {
+			continue
+		}
+		for _, owner := range pod.OwnerReferences {
+			if owner.Kind == "Job" && strings.Contains(owner.Name, "migrat") {
+				return &pods.Items[i], nil
+			}
+		}
+		if pod.Labels["component"] == "webapp" {
+			webappPod = &pods.Items[i]
+		}
+	}
+	return webappPod, nil
+}
+
+// isPodStarted returns true if a container of the pod is running or terminated, so that its logs can be read
+func isPodStarted(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil || status.State.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodReady returns true if the pod has the Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getBlackDuckSizeValues returns the file in the chart that defines the resources of a built-in size, or the
+// Helm values of a custom size from --size-file-path or --size-configmap
+func getBlackDuckSizeValues(flagset *pflag.FlagSet, namespace string, size string) (string, map[string]interface{}, error) {
+	if len(size) == 0 {
+		return "", nil, nil
+	}
+	if blackduck.IsBuiltInSize(size) {
+		return fmt.Sprintf("%s.yaml", strings.ToLower(size)), nil, nil
+	}
+
+	sizes := blackduck.NewSizeRegistry()
+	if filePath := flagset.Lookup("size-file-path"); filePath != nil && len(filePath.Value.String()) > 0 {
+		if err := sizes.AddSizesFromFile(filePath.Value.String()); err != nil {
+			return "", nil, err
+		}
+	}
+	if cmName := flagset.Lookup("size-configmap"); cmName != nil && len(cmName.Value.String()) > 0 {
+		if kubeClient == nil {
+			return "", nil, fmt.Errorf("--size-configmap requires access to a cluster, use --size-file-path instead")
+		}
+		cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(cmName.Value.String(), metav1.GetOptions{})
+		if err != nil {
+			return "", nil, fmt.Errorf("couldn't get the sizes config map '%s' in namespace '%s' due to %+v", cmName.Value.String(), namespace, err)
+		}
+		if err := sizes.AddSizesFromConfigMap(cm); err != nil {
+			return "", nil, err
+		}
+	}
+	values, ok := sizes.GetSize(size)
+	if !ok {
+		return "", nil, fmt.Errorf("size '%s' is not a built-in size and isn't defined by --size-file-path or --size-configmap, the custom sizes are %+v", size, sizes.GetSizeNames())
+	}
+	return "", values, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"time"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// postUpgradeCheckSQLFile is the file of SQL checks that are run against the database after an update
+var postUpgradeCheckSQLFile = ""
+
+// postUpgradeCheckDatabase is the database the SQL checks are run against
+var postUpgradeCheckDatabase = "bds_hub"
+
+// postUpgradeCheckTimeout is the maximum time the SQL checks may take
+var postUpgradeCheckTimeout = 10 * time.Minute
+
+// addPostUpgradeCheckFlags adds the flags of the post-upgrade SQL checks to an update command
+func addPostUpgradeCheckFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&postUpgradeCheckSQLFile, "post-upgrade-check-sql", postUpgradeCheckSQLFile, "Path to a file of SQL checks run with read-only credentials after the update, the instance is rolled back if a check raises an error")
+	cmd.Flags().StringVar(&postUpgradeCheckDatabase, "post-upgrade-check-database", postUpgradeCheckDatabase, "Database the post-upgrade SQL checks are run against")
+	cmd.Flags().DurationVar(&postUpgradeCheckTimeout, "post-upgrade-check-timeout", postUpgradeCheckTimeout, "Maximum time the post-upgrade SQL checks may take")
+}
+
+// readPostUpgradeCheckSQL returns the SQL checks of --post-upgrade-check-sql, read before the update so that an
+// invalid path doesn't fail the update after it is applied
+func readPostUpgradeCheckSQL() (string, error) {
+	if len(postUpgradeCheckSQLFile) == 0 {
+		return "", nil
+	}
+	sql, err := util.ReadFileData(postUpgradeCheckSQLFile)
+	if err != nil {
+		return "", util.ValidationError("failed to read the post-upgrade checks: %+v", err)
+	}
+	return sql, nil
+}
+
+// getBlackDuckPostgresUserName returns the non-admin user of the Postgres of a Black Duck release
+func getBlackDuckPostgresUserName(rel *release.Release) string {
+	if user, ok := util.GetValueFromRelease(rel, []string{"postgres", "userUserName"}).(string); ok && len(user) > 0 {
+		return user
+	}
+	return "blackduck_user"
+}
+
+// runBlackDuckPostUpgradeCheck runs the SQL checks against the database of the updated instance as its non-admin
+// user in read-only transactions. If a check fails, the instance is rolled back to the previous revision
+func runBlackDuckPostUpgradeCheck(name string, namespace string, sql string) error {
+	if len(sql) == 0 || util.ActivePlan != nil {
+		return nil
+	}
+	rel, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' to check", name, namespace)
+	}
+	endpoint, err := getBlackDuckPostgresEndpoint(rel)
+	if err != nil {
+		return err
+	}
+	endpoint.User = getBlackDuckPostgresUserName(rel)
+	endpoint.Password, _, err = bdutil.GetHubDBPasswordWithRetry(kubeClient, namespace, name, bdutil.DefaultDBPasswordOptions)
+	if err != nil {
+		return err
+	}
+
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+	util.WithStep(logger, "post-upgrade-check").Infof("running the post-upgrade checks of '%s' against database '%s'", postUpgradeCheckSQLFile, postUpgradeCheckDatabase)
+	checkErr := bdutil.PostUpgradeCheckJob(kubeClient, namespace, name, globals.DefaultPostgresClientImage, endpoint, postUpgradeCheckDatabase, sql, postUpgradeCheckTimeout)
+	if checkErr == nil {
+		util.WithStep(logger, "post-upgrade-check").Infof("the post-upgrade checks passed")
+		return nil
+	}
+
+	util.WithStep(logger, "post-upgrade-check").Errorf("the post-upgrade checks failed, rolling back: %+v", checkErr)
+	log.Warnf("the rollback restores the previous revision of the release, the database changes of the upgrade are not reverted")
+	if err := rollbackRelease(util.BlackDuckName, name, name, namespace); err != nil {
+		return fmt.Errorf("the post-upgrade checks failed (%+v) and the rollback failed: %w", checkErr, err)
+	}
+	return fmt.Errorf("the post-upgrade checks failed and Black Duck '%s' was rolled back: %w", name, checkErr)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReadPostUpgradeCheckSQL(t *testing.T) {
+	assert := assert.New(t)
+	defer func(value string) { postUpgradeCheckSQLFile = value }(postUpgradeCheckSQLFile)
+
+	dir, err := ioutil.TempDir("", "post-upgrade-check")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	sqlFile := filepath.Join(dir, "checks.sql")
+	assert.NoError(ioutil.WriteFile(sqlFile, []byte("SELECT 1/(SELECT count(*) FROM st.project);\n"), 0600))
+
+	// no checks without the flag
+	postUpgradeCheckSQLFile = ""
+	sql, err := readPostUpgradeCheckSQL()
+	assert.NoError(err)
+	assert.Equal("", sql)
+
+	postUpgradeCheckSQLFile = sqlFile
+	sql, err = readPostUpgradeCheckSQL()
+	assert.NoError(err)
+	assert.Equal("SELECT 1/(SELECT count(*) FROM st.project);\n", sql)
+
+	// a missing file fails the validation before the update
+	postUpgradeCheckSQLFile = filepath.Join(dir, "missing.sql")
+	_, err = readPostUpgradeCheckSQL()
+	assert.Error(err)
+	assert.Equal(util.ExitCodeValidation, util.ExitCode(err))
+}
+
+func TestGetBlackDuckPostgresUserName(t *testing.T) {
+	assert := assert.New(t)
+
+	rel := &release.Release{Name: "bd", Namespace: "ns", Chart: &chart.Chart{Values: map[string]interface{}{}}, Config: map[string]interface{}{}}
+	assert.Equal("blackduck_user", getBlackDuckPostgresUserName(rel))
+
+	rel.Config = map[string]interface{}{"postgres": map[string]interface{}{"userUserName": "reader"}}
+	assert.Equal("reader", getBlackDuckPostgresUserName(rel))
+
+	rel.Config = map[string]interface{}{"postgres": map[string]interface{}{"userUserName": ""}}
+	assert.Equal("blackduck_user", getBlackDuckPostgresUserName(rel))
+}
@@ -0,0 +1,404 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// interactiveCreate is set by the --interactive flag of create blackduck
+var interactiveCreate = false
+
+// wizardPrompter asks the questions of the wizard, it is shared by the steps of the command so that no buffered answer
+// is lost
+var wizardPrompter = util.NewPrompter(os.Stdin, os.Stderr)
+
+// blackDuckWizardName is the name of the instance that was asked by the wizard when it wasn't given as an argument
+var blackDuckWizardName = ""
+
+// blackDuckWizardCommandLineFlags are the flags of the wizard that are not Helm values, they must be repeated on the
+// command line when the values file written by the wizard is used
+var blackDuckWizardCommandLineFlags = []string{"certificate-file-path", "certificate-key-file-path", "certificate-issuer"}
+
+// addInteractiveFlag adds the --interactive flag to a create command
+func addInteractiveFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&interactiveCreate, "interactive", interactiveCreate, "If true, ask for the settings of the instance instead of requiring the flags, the flags that are set are not asked")
+}
+
+// allowInteractiveArgs drops the requirement of the NAME argument and of the --namespace flag of a create command in
+// the interactive mode since the wizard asks for them
+func allowInteractiveArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		cmd.Help()
+		return fmt.Errorf("this command takes at most 1 argument in the interactive mode, but got %+v", args)
+	}
+	if flag := cmd.Flags().Lookup("namespace"); flag != nil {
+		delete(flag.Annotations, cobra.BashCompOneRequiredFlag)
+	}
+	return nil
+}
+
+// getWizardArgs returns the arguments of a create command with the name asked by the wizard
+func getWizardArgs(args []string) []string {
+	if len(args) == 0 && len(blackDuckWizardName) > 0 {
+		return []string{blackDuckWizardName}
+	}
+	return args
+}
+
+// setWizardFlag sets a flag to the answer of the wizard
+func setWizardFlag(flags *pflag.FlagSet, name string, value string) error {
+	if err := flags.Set(name, value); err != nil {
+		return fmt.Errorf("failed to set --%s to '%s' due to %+v", name, value, err)
+	}
+	return nil
+}
+
+// validateDNSLabel returns an error if the answer is not a valid name of a namespace or an instance
+func validateDNSLabel(answer string) error {
+	if errs := validation.IsDNS1123Label(answer); len(errs) > 0 {
+		return fmt.Errorf("'%s' is not a valid name: %s", answer, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateAbsoluteFile returns an error if the answer is not the absolute path of an existing file
+func validateAbsoluteFile(answer string) error {
+	if !filepath.IsAbs(answer) {
+		return fmt.Errorf("'%s' is not an absolute path", answer)
+	}
+	if info, err := os.Stat(answer); err != nil || info.IsDir() {
+		return fmt.Errorf("'%s' is not a file", answer)
+	}
+	return nil
+}
+
+// validateNotEmpty returns an error if the answer is empty
+func validateNotEmpty(answer string) error {
+	if len(answer) == 0 {
+		return fmt.Errorf("an answer is required")
+	}
+	return nil
+}
+
+// runBlackDuckWizard asks for the namespace, version, size, storage, exposure, certificates and external database
+// of a new Black Duck instance and sets the flags of the answers, the flags that were set on the command line are not
+// asked
+func runBlackDuckWizard(cmd *cobra.Command, args []string, prompter *util.Prompter) error {
+	flags := cmd.Flags()
+	fmt.Fprintf(util.MessageWriter(), "Creating a Black Duck instance, press enter to accept the [default] answers\n\n")
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		answer, err := prompter.Ask("Name of the instance", "blackduck", validateDNSLabel)
+		if err != nil {
+			return err
+		}
+		name = answer
+		blackDuckWizardName = answer
+	}
+
+	if !flags.Changed("namespace") {
+		answer, err := prompter.Ask("Namespace", name, func(answer string) error {
+			if err := validateDNSLabel(answer); err != nil {
+				return err
+			}
+			if util.ReleaseExists(name, answer, kubeConfigPath) {
+				return fmt.Errorf("an instance '%s' already exists in the namespace '%s'", name, answer)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, "namespace", answer); err != nil {
+			return err
+		}
+	}
+
+	if !flags.Changed("version") {
+		answer, err := prompter.Ask("Version of Black Duck", globals.BlackDuckVersion, func(answer string) error {
+			ok, err := util.IsVersionGreaterThanOrEqualTo(answer, 2020, time.April, 0)
+			if err != nil {
+				return fmt.Errorf("'%s' is not a version of Black Duck, e.g. %s", answer, globals.BlackDuckVersion)
+			}
+			if !ok {
+				return fmt.Errorf("creation of Black Duck instance is only suported for version 2020.4.0 and above")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, "version", answer); err != nil {
+			return err
+		}
+	}
+
+	if !flags.Changed("size") {
+		answer, err := prompter.Choose("Size of the instance", blackduck.GetBuiltInSizes(), "small")
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, "size", answer); err != nil {
+			return err
+		}
+	}
+
+	if err := askBlackDuckWizardStorage(flags, prompter); err != nil {
+		return err
+	}
+	if err := askBlackDuckWizardExposure(flags, prompter); err != nil {
+		return err
+	}
+	if err := askBlackDuckWizardCertificate(flags, prompter); err != nil {
+		return err
+	}
+	return askBlackDuckWizardExternalPostgres(flags, prompter)
+}
+
+// askBlackDuckWizardStorage asks whether the instance has persistent storage and which storage class its volumes use
+func askBlackDuckWizardStorage(flags *pflag.FlagSet, prompter *util.Prompter) error {
+	persistent := true
+	if !flags.Changed("persistent-storage") {
+		answer, err := prompter.Confirm("Use persistent storage", true)
+		if err != nil {
+			return err
+		}
+		persistent = answer
+		if err := setWizardFlag(flags, "persistent-storage", strconv.FormatBool(answer)); err != nil {
+			return err
+		}
+	} else {
+		persistent, _ = flags.GetBool("persistent-storage")
+	}
+	if !persistent || flags.Changed("pvc-storage-class") {
+		return nil
+	}
+
+	// users may not be allowed to list storage classes, the name of the storage class is asked instead
+	storageClasses, err := kubeClient.StorageV1().StorageClasses().List(metav1.ListOptions{})
+	if err != nil || len(storageClasses.Items) == 0 {
+		answer, err := prompter.Ask("Storage class of the volumes (empty for the default storage class)", "", nil)
+		if err != nil || len(answer) == 0 {
+			return err
+		}
+		return setWizardFlag(flags, "pvc-storage-class", answer)
+	}
+	names := []string{}
+	defaultName := ""
+	for _, storageClass := range storageClasses.Items {
+		names = append(names, storageClass.Name)
+		if util.IsDefaultStorageClass(storageClass.Annotations) {
+			defaultName = storageClass.Name
+		}
+	}
+	if len(defaultName) == 0 {
+		defaultName = names[0]
+	}
+	answer, err := prompter.Choose("Storage class of the volumes", names, defaultName)
+	if err != nil {
+		return err
+	}
+	return setWizardFlag(flags, "pvc-storage-class", answer)
+}
+
+// askBlackDuckWizardExposure asks how the user interface is exposed and the host of the ingress
+func askBlackDuckWizardExposure(flags *pflag.FlagSet, prompter *util.Prompter) error {
+	if !flags.Changed("expose-ui") {
+		choices := []string{util.NODEPORT, util.LOADBALANCER, util.INGRESS, util.NONE}
+		defaultChoice := util.NODEPORT
+		if util.IsOpenshift(kubeClient) {
+			choices = append([]string{util.OPENSHIFT}, choices...)
+			defaultChoice = util.OPENSHIFT
+		}
+		answer, err := prompter.Choose("Exposure of the user interface", choices, defaultChoice)
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, "expose-ui", answer); err != nil {
+			return err
+		}
+	}
+	exposure, _ := flags.GetString("expose-ui")
+	if !strings.EqualFold(exposure, util.INGRESS) || flags.Changed("ingress-host") {
+		return nil
+	}
+	answer, err := prompter.Ask("Host of the ingress", "", func(answer string) error {
+		if errs := validation.IsDNS1123Subdomain(answer); len(errs) > 0 {
+			return fmt.Errorf("'%s' is not a valid host: %s", answer, strings.Join(errs, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return setWizardFlag(flags, "ingress-host", answer)
+}
+
+// askBlackDuckWizardCertificate asks for the certificate of the webserver, either a self-signed certificate, the
+// files of a certificate or a cert-manager issuer
+func askBlackDuckWizardCertificate(flags *pflag.FlagSet, prompter *util.Prompter) error {
+	if flags.Changed("certificate-file-path") || flags.Changed("certificate-issuer") {
+		return nil
+	}
+	selfSigned, files, issuer := "self-signed", "certificate files", "cert-manager issuer"
+	answer, err := prompter.Choose("Certificate of the webserver", []string{selfSigned, files, issuer}, selfSigned)
+	if err != nil {
+		return err
+	}
+	switch answer {
+	case files:
+		certificate, err := prompter.Ask("Absolute path of the certificate file", "", validateAbsoluteFile)
+		if err != nil {
+			return err
+		}
+		key, err := prompter.Ask("Absolute path of the certificate key file", "", validateAbsoluteFile)
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, "certificate-file-path", certificate); err != nil {
+			return err
+		}
+		return setWizardFlag(flags, "certificate-key-file-path", key)
+	case issuer:
+		answer, err := prompter.Ask("Issuer of the certificate ([Issuer/|ClusterIssuer/]NAME)", "", func(answer string) error {
+			_, err := util.ParseCertificateIssuer(answer)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return setWizardFlag(flags, "certificate-issuer", answer)
+	}
+	return nil
+}
+
+// askBlackDuckWizardExternalPostgres asks whether the instance uses an external Postgres and how to connect to it
+func askBlackDuckWizardExternalPostgres(flags *pflag.FlagSet, prompter *util.Prompter) error {
+	if flags.Changed("external-postgres-host") {
+		return nil
+	}
+	external, err := prompter.Confirm("Use an external Postgres database", false)
+	if err != nil || !external {
+		return err
+	}
+	questions := []struct {
+		flag          string
+		question      string
+		defaultAnswer string
+		validate      func(string) error
+	}{
+		{flag: "external-postgres-host", question: "Host of the external Postgres", validate: validateNotEmpty},
+		{flag: "external-postgres-port", question: "Port of the external Postgres", defaultAnswer: "5432", validate: func(answer string) error {
+			if port, err := strconv.Atoi(answer); err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("'%s' is not a port", answer)
+			}
+			return nil
+		}},
+		{flag: "external-postgres-admin", question: "Name of the 'admin' user", defaultAnswer: "blackduck", validate: validateNotEmpty},
+		{flag: "external-postgres-admin-password", question: "Password of the 'admin' user (visible while typing)", validate: validateNotEmpty},
+		{flag: "external-postgres-user", question: "Name of the 'user' user", defaultAnswer: "blackduck_user", validate: validateNotEmpty},
+		{flag: "external-postgres-user-password", question: "Password of the 'user' user (visible while typing)", validate: validateNotEmpty},
+	}
+	for _, q := range questions {
+		if flags.Changed(q.flag) {
+			continue
+		}
+		answer, err := prompter.Ask(q.question, q.defaultAnswer, q.validate)
+		if err != nil {
+			return err
+		}
+		if err := setWizardFlag(flags, q.flag, answer); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("external-postgres-ssl") {
+		return nil
+	}
+	ssl, err := prompter.Confirm("Connect to the external Postgres with SSL", true)
+	if err != nil {
+		return err
+	}
+	return setWizardFlag(flags, "external-postgres-ssl", strconv.FormatBool(ssl))
+}
+
+// reviewBlackDuckWizardValues shows the Helm values of the answers of the wizard and asks whether to install the
+// instance or to write the values to a file. It returns true if the instance is installed
+func reviewBlackDuckWizardValues(flags *pflag.FlagSet, prompter *util.Prompter, name string, helmValues map[string]interface{}) (bool, error) {
+	data, err := yaml.Marshal(util.RedactHelmValues(helmValues))
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal the Helm values due to %+v", err)
+	}
+	fmt.Fprintf(os.Stderr, "\nHelm values of the instance:\n\n%s\n", string(data))
+
+	install, write, cancel := "install the instance", "write the values to a file", "cancel"
+	answer, err := prompter.Choose("Next step", []string{install, write, cancel}, install)
+	if err != nil {
+		return false, err
+	}
+	switch answer {
+	case install:
+		return true, nil
+	case cancel:
+		return false, util.ValidationError("creation of the Black Duck instance was cancelled")
+	}
+
+	path, err := prompter.Ask("Path of the values file", fmt.Sprintf("%s-values.yaml", name), validateNotEmpty)
+	if err != nil {
+		return false, err
+	}
+	// the values file contains the passwords of the external Postgres
+	data, err = yaml.Marshal(helmValues)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal the Helm values due to %+v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return false, fmt.Errorf("failed to write the values file '%s' due to %+v", path, err)
+	}
+	command := []string{"synopsysctl", "create", "blackduck", name, "-n", namespace, "-f", path}
+	for _, flag := range blackDuckWizardCommandLineFlags {
+		if flags.Changed(flag) {
+			value, _ := flags.GetString(flag)
+			command = append(command, fmt.Sprintf("--%s", flag), value)
+		}
+	}
+	fmt.Fprintf(util.MessageWriter(), "\nWrote the values to '%s', create the instance with:\n  %s\n", path, strings.Join(command, " "))
+	return false, nil
+}
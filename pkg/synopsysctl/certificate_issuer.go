@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// certificateIssuerTimeout is the maximum time to wait for cert-manager to issue the webserver certificate
+var certificateIssuerTimeout = 5 * time.Minute
+
+// getCertificateSecretValueAndHostEnviron returns the Helm value of the webserver certificate secret of the chart of the
+// app and the environ of the host of the webserver
+func getCertificateSecretValueAndHostEnviron(app string) (string, string) {
+	if app == util.BlackDuckName {
+		return "tlsCertSecretName", "PUBLIC_HUB_WEBSERVER_HOST"
+	}
+	return "webserverCustomCertificatesSecretName", "ALERT_HOSTNAME"
+}
+
+// configureCertificateIssuer stores the cert-manager issuer of --certificate-issuer in the Helm values and points the
+// chart at the secret it issues the certificate into. The issuer of a previous create or update is kept until
+// --certificate-file-path or an empty --certificate-issuer replaces it. It returns the certificate to apply, or nil if the
+// instance doesn't use an issuer
+func configureCertificateIssuer(flags *pflag.FlagSet, app string, name string, namespace string, helmValues map[string]interface{}) (*unstructured.Unstructured, error) {
+	secretValue, hostEnviron := getCertificateSecretValueAndHostEnviron(app)
+	current := util.GetCertificateIssuerFromHelmValues(helmValues)
+	if flag := flags.Lookup("certificate-issuer"); flag != nil && flag.Changed {
+		if len(flag.Value.String()) == 0 {
+			if current != nil && helmValues[secretValue] == current.SecretName {
+				delete(helmValues, secretValue)
+			}
+			util.SetCertificateIssuerInHelmValues(helmValues, nil)
+			return nil, nil
+		}
+		issuer, err := util.ParseCertificateIssuer(flag.Value.String())
+		if err != nil {
+			return nil, err
+		}
+		issuer.SecretName = util.GetCertificateIssuerSecretName(name, app)
+		util.SetCertificateIssuerInHelmValues(helmValues, issuer)
+	} else if flag := flags.Lookup("certificate-file-path"); flag != nil && flag.Changed {
+		util.SetCertificateIssuerInHelmValues(helmValues, nil)
+		return nil, nil
+	}
+
+	issuer := util.GetCertificateIssuerFromHelmValues(helmValues)
+	if issuer == nil {
+		return nil, nil
+	}
+	host, _ := util.GetHelmValueFromMap(helmValues, []string{"environs", hostEnviron}).(string)
+	if len(host) == 0 {
+		return nil, util.ValidationError("--certificate-issuer requires the host of the certificate in the %s environ", hostEnviron)
+	}
+	util.SetHelmValueInMap(helmValues, []string{secretValue}, issuer.SecretName)
+	labels := map[string]string{"app": app, "name": name}
+	return util.NewCertManagerCertificate(namespace, labels, *issuer, []string{host}), nil
+}
+
+// applyCertificateIssuer applies the cert-manager certificate and waits until its secret is issued, so that the pods
+// of the webserver can mount it
+func applyCertificateIssuer(certificate *unstructured.Unstructured) error {
+	if certificate == nil || skipForPlan(util.PlanActionApply, "Certificate", certificate.GetName(), certificate.GetNamespace()) {
+		return nil
+	}
+	log.Infof("requesting the webserver certificate '%s' from cert-manager...", certificate.GetName())
+	if err := util.ApplyCertManagerCertificate(restconfig, certificate); err != nil {
+		return err
+	}
+	if err := util.WaitForCertificateSecret(kubeClient, certificate.GetNamespace(), certificate.GetName(), certificateIssuerTimeout); err != nil {
+		return err
+	}
+	log.Infof("cert-manager issued the webserver certificate into secret '%s'", certificate.GetName())
+	return nil
+}
+
+// deleteCertificateIssuer deletes the cert-manager certificate and its secret if the Helm values use an issuer
+func deleteCertificateIssuer(namespace string, helmValues map[string]interface{}) error {
+	issuer := util.GetCertificateIssuerFromHelmValues(helmValues)
+	if issuer == nil || skipForPlan(util.PlanActionDelete, "Certificate", issuer.SecretName, namespace) {
+		return nil
+	}
+	return util.DeleteCertManagerCertificate(restconfig, kubeClient, namespace, *issuer)
+}
+
+// addCertificateIssuerToDeleteReport adds the cert-manager certificate and its secret to the delete report if the Helm
+// values use an issuer
+func addCertificateIssuerToDeleteReport(report *util.DeleteReport, namespace string, helmValues map[string]interface{}) error {
+	issuer := util.GetCertificateIssuerFromHelmValues(helmValues)
+	if issuer == nil {
+		return nil
+	}
+	report.AddObject("Certificate", issuer.SecretName, namespace, util.DeleteSourceSecret)
+	return report.AddSecrets(kubeClient, namespace, issuer.SecretName)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Airgap Command Options and Defaults
+var airgapVersion = ""
+var airgapDirectory = "."
+var airgapSaveImages = false
+
+// Files written by 'airgap export' next to the chart archive
+const (
+	airgapImagesFileName  = "images.txt"
+	airgapArchiveFileName = "images.tar"
+)
+
+// airgapCmd prepares the installation of products in clusters without internet access
+var airgapCmd = &cobra.Command{
+	Use:   "airgap",
+	Short: "Prepare the installation of a Synopsys resource in a cluster without internet access",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// airgapExportCmd downloads the chart of a product and lists, and optionally saves, its images
+var airgapExportCmd = &cobra.Command{
+	Use:           "export PRODUCT [--version VERSION]",
+	Example:       "synopsysctl airgap export blackduck --version 2020.6.0 --directory bd-2020.6.0 --save-images\nsynopsysctl --offline create blackduck <name> -n <namespace> --app-resources-path bd-2020.6.0/blackduck-2020.6.0.tgz",
+	Short:         "Download the chart and list the images of a product for a cluster without internet access",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		// accept the product aliases, e.g. 'airgap export bd'
+		if product := getProductName(args[0]); len(product) > 0 {
+			args[0] = product
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if util.Offline {
+			return util.ValidationError("'airgap export' downloads the chart and images and can't run with --offline")
+		}
+		chart, err := getLintChart(args[0])
+		if err != nil {
+			return err
+		}
+		if err := UpdateHelmChartLocation(cmd.Flags(), chart.chartName, airgapVersion, chart.chartRepository); err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		chartPath, err := util.DownloadChart(*chart.chartRepository, airgapDirectory)
+		if err != nil {
+			return err
+		}
+		log.Infof("downloaded the %s chart to '%s'", args[0], chartPath)
+
+		images, err := getChartImages(args[0], chart, chartPath)
+		if err != nil {
+			return err
+		}
+		imagesPath := filepath.Join(airgapDirectory, airgapImagesFileName)
+		if err := ioutil.WriteFile(imagesPath, []byte(strings.Join(images, "\n")+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write the images to '%s' due to %+v", imagesPath, err)
+		}
+		log.Infof("wrote the %d images of %s to '%s'", len(images), args[0], imagesPath)
+
+		if !airgapSaveImages {
+			return nil
+		}
+		archivePath := filepath.Join(airgapDirectory, airgapArchiveFileName)
+		archive, err := os.Create(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to create '%s' due to %+v", archivePath, err)
+		}
+		defer archive.Close()
+		log.Infof("saving the %d images of %s to '%s', this may take a while", len(images), args[0], archivePath)
+		if err := util.SaveImages(images, archive); err != nil {
+			return fmt.Errorf("failed to save the images to '%s' due to %+v", archivePath, err)
+		}
+		log.Infof("saved the images, load them with 'docker load -i %s' and push them to the registry of the cluster", archivePath)
+		return nil
+	},
+}
+
+// getChartImages returns the images of the default configuration of the product rendered by the chart
+func getChartImages(app string, chart *lintChart, chartURL string) ([]string, error) {
+	manifests, err := util.RenderWithHelm3(app, "default", chartURL, chart.values, chart.extraFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the %s resources due to %+v", app, err)
+	}
+	return util.GetManifestImages(manifests)
+}
+
+func init() {
+	rootCmd.AddCommand(airgapCmd)
+
+	airgapExportCmd.Flags().StringVar(&airgapVersion, "version", airgapVersion, "Version of the product (default latest)")
+	airgapExportCmd.Flags().StringVar(&airgapDirectory, "directory", airgapDirectory, "Directory to write the chart archive, the image list and the images to")
+	airgapExportCmd.Flags().BoolVar(&airgapSaveImages, "save-images", airgapSaveImages, fmt.Sprintf("If true, pull the images and save them to %s in the format of 'docker save'", airgapArchiveFileName))
+	addChartLocationPathFlag(airgapExportCmd)
+	airgapCmd.AddCommand(airgapExportCmd)
+}
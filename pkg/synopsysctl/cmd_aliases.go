@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// productAliases are the short forms of the product names
+var productAliases = map[string][]string{
+	util.BlackDuckName: {"bd"},
+	util.OpsSightName:  {"ops"},
+}
+
+// getProductName returns the product name of a product name or alias, or an empty string
+func getProductName(nameOrAlias string) string {
+	for product, aliases := range productAliases {
+		if nameOrAlias == product {
+			return product
+		}
+		for _, alias := range aliases {
+			if nameOrAlias == alias {
+				return product
+			}
+		}
+	}
+	return ""
+}
+
+// addProductAliases adds the product aliases to the product sub-commands of all commands, e.g. 'create bd'
+func addProductAliases(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		if aliases, ok := productAliases[subCmd.Name()]; ok && cmd != rootCmd {
+			subCmd.Aliases = append(subCmd.Aliases, aliases...)
+		}
+		addProductAliases(subCmd)
+	}
+}
+
+// skipLeadingFlags returns the index of the first argument that is neither a flag nor the value of a flag, e.g. 2 for
+// '--kubeconfig FILE bd create'. The values of the flags are found by the flags of the flag set
+func skipLeadingFlags(args []string, flags *pflag.FlagSet) int {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") && args[i] != "--" {
+		arg := args[i]
+		i++
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *pflag.Flag
+		if strings.HasPrefix(arg, "--") {
+			flag = flags.Lookup(arg[2:])
+		} else if len(arg) == 2 {
+			flag = flags.ShorthandLookup(arg[1:])
+		}
+		// the flags without a value, e.g. --quiet, have a default for their missing value
+		if flag != nil && len(flag.NoOptDefVal) == 0 && i < len(args) {
+			i++
+		}
+	}
+	return i
+}
+
+// reorderNounFirstArgs swaps a leading product and verb, e.g. 'bd create NAME' becomes 'create bd NAME',
+// if the verb command has a sub-command for the product. The global flags before the product are kept in place
+func reorderNounFirstArgs(args []string) []string {
+	start := skipLeadingFlags(args, rootCmd.PersistentFlags())
+	if len(args)-start < 2 {
+		return args
+	}
+	product := getProductName(args[start])
+	if len(product) == 0 {
+		return args
+	}
+	verb := args[start+1]
+	for _, verbCmd := range rootCmd.Commands() {
+		if verbCmd.Name() != verb && !verbCmd.HasAlias(verb) {
+			continue
+		}
+		for _, productCmd := range verbCmd.Commands() {
+			if productCmd.Name() == product {
+				reordered := append([]string{}, args[:start]...)
+				reordered = append(reordered, verb, args[start])
+				return append(reordered, args[start+2:]...)
+			}
+		}
+	}
+	return args
+}
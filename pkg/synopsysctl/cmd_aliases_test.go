@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorderNounFirstArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{"bd", "create", "bd1"}, expected: []string{"create", "bd", "bd1"}},
+		{args: []string{"blackduck", "delete", "bd1", "-n", "ns"}, expected: []string{"delete", "blackduck", "bd1", "-n", "ns"}},
+		{args: []string{"ops", "delete", "ops1"}, expected: []string{"delete", "ops", "ops1"}},
+		// the global flags before the product are kept in place
+		{args: []string{"--kubeconfig", "k", "bd", "create", "bd1"}, expected: []string{"--kubeconfig", "k", "create", "bd", "bd1"}},
+		{args: []string{"--kubeconfig=k", "bd", "create", "bd1"}, expected: []string{"--kubeconfig=k", "create", "bd", "bd1"}},
+		{args: []string{"-v", "debug", "--quiet", "bd", "create", "bd1"}, expected: []string{"-v", "debug", "--quiet", "create", "bd", "bd1"}},
+		{args: []string{"--insecure-skip-tls-verify", "bd", "create", "bd1"}, expected: []string{"--insecure-skip-tls-verify", "create", "bd", "bd1"}},
+		// verb first, unknown verbs and products, and incomplete commands aren't changed
+		{args: []string{"create", "bd", "bd1"}, expected: []string{"create", "bd", "bd1"}},
+		{args: []string{"--kubeconfig", "k", "create", "bd", "bd1"}, expected: []string{"--kubeconfig", "k", "create", "bd", "bd1"}},
+		{args: []string{"bd", "frobnicate", "bd1"}, expected: []string{"bd", "frobnicate", "bd1"}},
+		{args: []string{"cluster", "create"}, expected: []string{"cluster", "create"}},
+		{args: []string{"bd"}, expected: []string{"bd"}},
+		{args: []string{"--kubeconfig", "bd", "create"}, expected: []string{"--kubeconfig", "bd", "create"}},
+		{args: []string{"--", "bd", "create"}, expected: []string{"--", "bd", "create"}},
+		{args: []string{}, expected: []string{}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, reorderNounFirstArgs(test.args), "args %+v", test.args)
+	}
+}
+
+func TestSkipLeadingFlags(t *testing.T) {
+	assert := assert.New(t)
+	flags := rootCmd.PersistentFlags()
+
+	assert.Equal(0, skipLeadingFlags([]string{"bd", "create"}, flags))
+	assert.Equal(2, skipLeadingFlags([]string{"--kubeconfig", "k", "bd"}, flags))
+	assert.Equal(1, skipLeadingFlags([]string{"--kubeconfig=k", "bd"}, flags))
+	assert.Equal(1, skipLeadingFlags([]string{"--quiet", "bd"}, flags))
+	assert.Equal(2, skipLeadingFlags([]string{"-v", "debug", "bd"}, flags))
+	assert.Equal(1, skipLeadingFlags([]string{"--unknown", "bd"}, flags))
+	assert.Equal(2, skipLeadingFlags([]string{"--kubeconfig", "k"}, flags))
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Files of an exported configuration
+const (
+	appConfigValuesFile   = "values.yaml"
+	appConfigSettingsFile = "app-config.yaml"
+)
+
+// Flags of the app-config commands
+var appConfigBlackDuckURL = ""
+var appConfigAPIToken = ""
+var appConfigDirectory = "."
+var appConfigDryRun = false
+
+// exportCmd exports the configuration of a Synopsys resource
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the configuration of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// exportAppConfigCmd exports the configuration of an instance, including the in-app settings
+var exportAppConfigCmd = &cobra.Command{
+	Use:   "app-config",
+	Short: "Export the Helm values and the in-app settings of an instance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// exportAppConfigBlackDuckCmd exports the Helm values and the in-app settings of a Black Duck instance into a directory
+var exportAppConfigBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --blackduck-url URL",
+	Example:       "synopsysctl export app-config blackduck <name> -n <namespace> --blackduck-url https://blackduck.example.com --directory <directory>",
+	Short:         "Export the Helm values and the policies, user groups and integrations of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAppConfigClient()
+		if err != nil {
+			return err
+		}
+		instance, err := products.New(util.BlackDuckName, args[0], namespace, getProductClients())
+		if err != nil {
+			return err
+		}
+		release, err := util.GetWithHelm3(instance.ReleaseName(), namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", args[0], namespace, err)
+		}
+		config, err := blackduck.ExportAppConfig(client)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(appConfigDirectory, 0755); err != nil {
+			return fmt.Errorf("unable to create the directory '%s' due to %+v", appConfigDirectory, err)
+		}
+		// the values and the settings can hold credentials
+		if err := writeAppConfigFile(appConfigValuesFile, release.Config); err != nil {
+			return err
+		}
+		if err := writeAppConfigFile(appConfigSettingsFile, config); err != nil {
+			return err
+		}
+		for _, resource := range blackduck.AppConfigResources {
+			log.Infof("exported %d %s", len(config.Settings[resource.Kind]), resource.Kind)
+		}
+		log.Infof("exported the configuration of Black Duck '%s' in namespace '%s' to '%s', create the instance with '%s' and import its settings with 'synopsysctl import app-config blackduck'",
+			args[0], namespace, appConfigDirectory, fmt.Sprintf("synopsysctl create blackduck NAME -n NAMESPACE -f %s", filepath.Join(appConfigDirectory, appConfigValuesFile)))
+		return nil
+	},
+}
+
+// importCmd imports the configuration of a Synopsys resource
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the configuration of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// importAppConfigCmd imports the in-app settings of an instance
+var importAppConfigCmd = &cobra.Command{
+	Use:   "app-config",
+	Short: "Import the in-app settings of an instance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// importAppConfigBlackDuckCmd creates or updates the in-app settings of a Black Duck instance from an exported configuration
+var importAppConfigBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --blackduck-url URL",
+	Example:       "synopsysctl import app-config blackduck <name> -n <namespace> --blackduck-url https://blackduck.example.com --directory <directory>\nsynopsysctl import app-config blackduck <name> -n <namespace> --blackduck-url https://blackduck.example.com --dry-run",
+	Short:         "Import the policies, user groups and integrations of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAppConfigClient()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(appConfigDirectory, appConfigSettingsFile)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read the exported settings '%s' due to %+v", path, err)
+		}
+		config := &blackduck.AppConfig{}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return util.ValidationError("'%s' is not an exported configuration: %+v", path, err)
+		}
+		instance, err := products.New(util.BlackDuckName, args[0], namespace, getProductClients())
+		if err != nil {
+			return err
+		}
+		if _, err := util.GetWithHelm3(instance.ReleaseName(), namespace, kubeConfigPath); err != nil {
+			return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", args[0], namespace, err)
+		}
+
+		changes, err := blackduck.ImportAppConfig(client, config, appConfigDryRun)
+		table := util.NewTable(util.TableColumn{Name: "KIND"}, util.TableColumn{Name: "NAME"}, util.TableColumn{Name: "ACTION"})
+		for _, change := range changes {
+			table.AddRow(change.Kind, change.Name, change.Action)
+		}
+		if printErr := table.Print(os.Stdout, util.TableOptions{}); printErr != nil {
+			return printErr
+		}
+		if err != nil {
+			return err
+		}
+		if appConfigDryRun {
+			log.Infof("dry run, %d setting(s) of Black Duck '%s' in namespace '%s' would be changed", len(changes), args[0], namespace)
+			return nil
+		}
+		log.Infof("imported %d setting(s) into Black Duck '%s' in namespace '%s'", len(changes), args[0], namespace)
+		return nil
+	},
+}
+
+// getAppConfigClient returns the client of the Black Duck API set by the flags
+func getAppConfigClient() (*util.ProductAPIClient, error) {
+	if len(appConfigBlackDuckURL) == 0 {
+		return nil, util.ValidationError("--blackduck-url must be set")
+	}
+	apiToken := appConfigAPIToken
+	if len(apiToken) == 0 {
+		apiToken = os.Getenv("BLACKDUCK_API_TOKEN")
+	}
+	if len(apiToken) == 0 {
+		return nil, util.ValidationError("--api-token or $BLACKDUCK_API_TOKEN must be set")
+	}
+	return blackduck.NewAppConfigClient(appConfigBlackDuckURL, apiToken), nil
+}
+
+// writeAppConfigFile writes a file of the exported configuration, readable by the user only
+func writeAppConfigFile(name string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("unable to marshal '%s' due to %+v", name, err)
+	}
+	path := filepath.Join(appConfigDirectory, name)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("unable to write '%s' due to %+v", path, err)
+	}
+	return nil
+}
+
+// addAppConfigFlags adds the flags of the Black Duck API and the directory of the configuration to an app-config command
+func addAppConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(cmd.Flags(), "namespace")
+	cmd.Flags().StringVar(&appConfigBlackDuckURL, "blackduck-url", appConfigBlackDuckURL, "URL of the Black Duck API, e.g. https://blackduck.example.com")
+	cmd.Flags().StringVar(&appConfigAPIToken, "api-token", appConfigAPIToken, "Black Duck API token of a system administrator [default: $BLACKDUCK_API_TOKEN]")
+	cmd.Flags().StringVarP(&appConfigDirectory, "directory", "d", appConfigDirectory, fmt.Sprintf("Directory of the exported '%s' and '%s'", appConfigValuesFile, appConfigSettingsFile))
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportAppConfigCmd)
+	addAppConfigFlags(exportAppConfigBlackDuckCmd)
+	exportAppConfigCmd.AddCommand(exportAppConfigBlackDuckCmd)
+
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importAppConfigCmd)
+	addAppConfigFlags(importAppConfigBlackDuckCmd)
+	importAppConfigBlackDuckCmd.Flags().BoolVar(&appConfigDryRun, "dry-run", appConfigDryRun, "Print the settings that would be created or updated without changing them")
+	importAppConfigCmd.AddCommand(importAppConfigBlackDuckCmd)
+}
@@ -0,0 +1,294 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Backup Command Options and Defaults
+var backupOutput = ""
+var backupTimeout = time.Hour
+var backupImage = globals.DefaultPostgresClientImage
+var backupRetention = 0
+
+// backupStoppedComponents are the Black Duck components that are stopped while the databases are dumped, so that no
+// scan or job changes the databases during the backup
+var backupStoppedComponents = []string{"jobrunner", "scan"}
+
+// backupCmd backs up the data of an instance
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the data of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// backupBlackDuckCmd dumps the databases of a Black Duck instance
+var backupBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --output DIRECTORY|pvc:CLAIM|s3://BUCKET[/PREFIX]",
+	Example:       "synopsysctl backup blackduck <name> -n <namespace> --output ./backups\nsynopsysctl backup blackduck <name> -n <namespace> --output pvc:<claim>\nsynopsysctl backup blackduck <name> -n <namespace> --output s3://<bucket>/<prefix>",
+	Short:         "Back up the databases of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		destination, err := util.ParseBackupDestination(backupOutput)
+		if err != nil {
+			return err
+		}
+		if backupRetention < 0 {
+			return util.ValidationError("--retention must not be negative, got %d", backupRetention)
+		}
+		_, err = backupBlackDuck(args[0], namespace, destination)
+		return err
+	},
+}
+
+// backupBlackDuck dumps the databases of the instance with a job while its jobrunner and scan components are stopped,
+// and copies the dumps to the destination. It returns the name of the backup
+func backupBlackDuck(name string, namespace string, destination util.BackupDestination) (string, error) {
+	backupName := util.GetBackupName(util.BlackDuckName, name, time.Now())
+	var bucket util.S3Bucket
+	switch destination.Kind {
+	case util.BackupDestinationDirectory:
+		if err := os.MkdirAll(filepath.Join(destination.Location, backupName), 0755); err != nil {
+			return "", fmt.Errorf("unable to create the backup directory due to %+v", err)
+		}
+	case util.BackupDestinationPVC:
+		if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(destination.Location, metav1.GetOptions{}); err != nil {
+			return "", fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", destination.Location, namespace, err)
+		}
+	case util.BackupDestinationS3:
+		bucket = util.GetS3BucketFromEnv(destination.Location)
+		if err := util.CheckS3Bucket(bucket); err != nil {
+			return "", err
+		}
+	}
+
+	rel, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	endpoint, err := getBlackDuckPostgresEndpoint(rel)
+	if err != nil {
+		return "", err
+	}
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+
+	// stop the components that change the database outside of user requests
+	labelSelector := fmt.Sprintf("app=%s, name=%s, component in (%s)", util.BlackDuckName, name, strings.Join(backupStoppedComponents, ", "))
+	startStopped, err := stopDeployments(logger, namespace, labelSelector)
+	defer startStopped()
+	if err != nil {
+		return "", err
+	}
+
+	claim := ""
+	if destination.Kind == util.BackupDestinationPVC {
+		claim = destination.Location
+	}
+	util.WithStep(logger, "dump").Infof("dumping the databases of Black Duck '%s', this may take a while", name)
+	pod, err := bdutil.BackupDatabaseJob(kubeClient, namespace, name, backupImage, endpoint, claim, backupName, backupRetention, backupTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to back up the databases of Black Duck '%s' in namespace '%s': %w", name, namespace, err)
+	}
+	// the dumps are complete, the components can run again while the dumps are copied
+	startStopped()
+
+	if pod != nil {
+		if err := collectBlackDuckBackup(pod, backupName, destination, bucket); err != nil {
+			propagation := metav1.DeletePropagationBackground
+			jobName := util.GetResourceName(name, util.BlackDuckName, bdutil.BackupJobComponent)
+			if err := kubeClient.BatchV1().Jobs(namespace).Delete(jobName, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+				util.WithStep(logger, "cleanup").Warnf("unable to delete the backup job '%s' due to %+v", jobName, err)
+			}
+			return "", fmt.Errorf("failed to copy the backup of Black Duck '%s' in namespace '%s': %w", name, namespace, err)
+		}
+	}
+	util.WithStep(logger, "dump").Infof("backed up the databases of Black Duck '%s' to '%s/%s'", name, strings.TrimSuffix(destination.String(), "/"), backupName)
+
+	// the backup job prunes the backups of a claim
+	if backupRetention > 0 && destination.Kind != util.BackupDestinationPVC {
+		if err := pruneBlackDuckBackups(logger, name, destination, bucket); err != nil {
+			return "", fmt.Errorf("failed to delete the expired backups of Black Duck '%s': %w", name, err)
+		}
+	}
+	return backupName, nil
+}
+
+// pruneBlackDuckBackups deletes the backups of the instance in the directory or the S3 bucket of the destination that
+// are older than the newest --retention backups
+func pruneBlackDuckBackups(logger *log.Entry, name string, destination util.BackupDestination, bucket util.S3Bucket) error {
+	if destination.Kind == util.BackupDestinationDirectory {
+		files, err := ioutil.ReadDir(destination.Location)
+		if err != nil {
+			return err
+		}
+		names := []string{}
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		for _, expired := range util.GetExpiredBackups(names, util.BlackDuckName, name, backupRetention) {
+			util.WithStep(logger, "prune").Infof("deleting expired backup '%s'", filepath.Join(destination.Location, expired))
+			if err := os.RemoveAll(filepath.Join(destination.Location, expired)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	prefix := ""
+	if len(destination.Prefix) > 0 {
+		prefix = destination.Prefix + "/"
+	}
+	_, prefixes, err := util.ListS3Objects(bucket, prefix, "/")
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for _, p := range prefixes {
+		names = append(names, path.Base(p))
+	}
+	for _, expired := range util.GetExpiredBackups(names, util.BlackDuckName, name, backupRetention) {
+		util.WithStep(logger, "prune").Infof("deleting expired backup '%s%s' of S3 bucket '%s'", prefix, expired, bucket.Bucket)
+		keys, _, err := util.ListS3Objects(bucket, prefix+expired+"/", "")
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := util.DeleteS3Object(bucket, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stopDeployments scales the deployments with the labels down to zero replicas. The returned function scales the
+// stopped deployments up to their replicas again, it does nothing when it is called again. It must be called even if
+// an error is returned, to restart the deployments stopped before the error
+func stopDeployments(logger *log.Entry, namespace string, labelSelector string) (func(), error) {
+	replicas := map[string]*int32{}
+	stopped := []*appsv1.Deployment{}
+	start := func() {
+		for _, deployment := range stopped {
+			if _, err := util.PatchDeploymentForReplicas(kubeClient, deployment, replicas[deployment.Name]); err != nil {
+				util.WithStep(logger, "scale-up").Errorf("couldn't scale up deployment '%s' in namespace '%s' due to %+v", deployment.Name, namespace, err)
+			}
+		}
+		stopped = nil
+	}
+	deployments, err := util.ListDeployments(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return start, fmt.Errorf("couldn't list the deployments '%s' in namespace '%s' due to %+v", labelSelector, namespace, err)
+	}
+	zero := int32(0)
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		replicas[deployment.Name] = deployment.Spec.Replicas
+		stoppedDeployment, err := util.PatchDeploymentForReplicas(kubeClient, deployment, &zero)
+		if err != nil {
+			return start, fmt.Errorf("couldn't scale down deployment '%s' in namespace '%s' due to %+v", deployment.Name, namespace, err)
+		}
+		stopped = append(stopped, stoppedDeployment)
+	}
+	return start, nil
+}
+
+// collectBlackDuckBackup copies the dumps out of the pod of the backup job to the directory or the S3 bucket of the
+// destination, and lets the job complete
+func collectBlackDuckBackup(pod *corev1.Pod, backupName string, destination util.BackupDestination, bucket util.S3Bucket) error {
+	dir := filepath.Join(destination.Location, backupName)
+	if destination.Kind == util.BackupDestinationS3 {
+		tempDir, err := ioutil.TempDir("", "synopsysctl-backup-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+		dir = tempDir
+	}
+	for _, database := range bdutil.BlackDuckDatabases {
+		fileName := fmt.Sprintf("%s.dump", database)
+		if err := copyBackupFile(pod, path.Join(bdutil.BackupMountPath, fileName), filepath.Join(dir, fileName)); err != nil {
+			return err
+		}
+		if destination.Kind == util.BackupDestinationS3 {
+			key := path.Join(destination.Prefix, backupName, fileName)
+			log.Infof("uploading '%s' to S3 bucket '%s'", key, bucket.Bucket)
+			if err := util.PutS3Object(bucket, key, filepath.Join(dir, fileName)); err != nil {
+				return err
+			}
+		}
+	}
+	return util.StreamContainerCommand(restconfig, kubeClient, pod, []string{"touch", path.Join(bdutil.BackupMountPath, bdutil.BackupCollectedFile)}, nil, ioutil.Discard)
+}
+
+// copyBackupFile copies a file of the pod of the backup job to a local file
+func copyBackupFile(pod *corev1.Pod, source string, target string) error {
+	log.Infof("copying '%s' to '%s'", source, target)
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := util.StreamContainerCommand(restconfig, kubeClient, pod, []string{"cat", source}, nil, f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(backupBlackDuckCmd.PersistentFlags(), "namespace")
+	backupBlackDuckCmd.Flags().StringVar(&backupOutput, "output", backupOutput, "Destination of the backup, a local directory, pvc:CLAIM for a persistent volume claim in the namespace, or s3://BUCKET[/PREFIX] with the credentials of the environment")
+	cobra.MarkFlagRequired(backupBlackDuckCmd.Flags(), "output")
+	backupBlackDuckCmd.Flags().DurationVar(&backupTimeout, "timeout", backupTimeout, "Maximum time to dump and copy the databases")
+	backupBlackDuckCmd.Flags().StringVar(&backupImage, "image", backupImage, "Postgres client image of the backup job, its pg_dump must not be older than the Postgres server")
+	backupBlackDuckCmd.Flags().IntVar(&backupRetention, "retention", backupRetention, "Number of backups of the instance to keep in the destination, older backups are deleted after a successful backup (0 keeps all backups)")
+	backupCmd.AddCommand(backupBlackDuckCmd)
+}
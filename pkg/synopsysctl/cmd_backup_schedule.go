@@ -0,0 +1,234 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scheduledBackupComponent is the component label of the scheduled backup tasks, and the suffix of their names
+const scheduledBackupComponent = "scheduled-backup"
+
+// Backup Schedule Command Options and Defaults
+var backupScheduleCron = ""
+var backupScheduleRetention = 7
+var backupScheduleS3CredentialsSecret = ""
+
+// backupScheduleCmd manages the backups that run in the cluster on a schedule
+var backupScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage the backups that synopsysctl runs in the cluster on a schedule",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// backupScheduleBlackDuckCmd creates or updates a CronJob running 'backup blackduck' in the namespace of the instance
+var backupScheduleBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --cron SCHEDULE --output pvc:CLAIM|s3://BUCKET[/PREFIX]",
+	Example:       "synopsysctl backup schedule blackduck <name> -n <namespace> --cron \"0 2 * * *\" --retention 7 --output pvc:<claim>\nsynopsysctl backup schedule blackduck <name> -n <namespace> --cron \"0 2 * * *\" --output s3://<bucket>/<prefix> --s3-credentials-secret <secret>",
+	Short:         "Schedule backups of the databases of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return scheduleBlackDuckBackup(args[0], namespace)
+	},
+}
+
+// backupScheduleListCmd lists the scheduled backups
+var backupScheduleListCmd = &cobra.Command{
+	Use:           "list -n NAMESPACE",
+	Example:       "synopsysctl backup schedule list -n <namespace>",
+	Short:         "List the scheduled backups",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		labelSelector := fmt.Sprintf("%s, component=%s", scheduledTaskLabel, scheduledBackupComponent)
+		cronJobs, err := kubeClient.BatchV1beta1().CronJobs(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return fmt.Errorf("unable to list the scheduled backups in namespace '%s' due to %+v", namespace, err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tAPP\tSCHEDULE\tOUTPUT\tRETENTION\tSUSPENDED\tLAST RUN")
+		for _, cronJob := range cronJobs.Items {
+			lastRun := "<never>"
+			if cronJob.Status.LastScheduleTime != nil {
+				lastRun = cronJob.Status.LastScheduleTime.Format("2006-01-02 15:04:05")
+			}
+			suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+			output, retention := "", ""
+			if containers := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers; len(containers) > 0 {
+				output = getArgValue(containers[0].Args, "--output")
+				retention = getArgValue(containers[0].Args, "--retention")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", cronJob.Labels["name"], cronJob.Labels["app"], cronJob.Spec.Schedule, output, retention, suspended, lastRun)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// backupScheduleDeleteCmd deletes the scheduled backups of an instance, the backups are kept
+var backupScheduleDeleteCmd = &cobra.Command{
+	Use:           "delete NAME -n NAMESPACE",
+	Example:       "synopsysctl backup schedule delete <name> -n <namespace>",
+	Short:         "Delete the scheduled backups of a Black Duck instance, the existing backups are kept",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deleteScheduledTask(kubeClient, util.GetResourceName(args[0], util.BlackDuckName, scheduledBackupComponent), namespace)
+	},
+}
+
+// scheduleBlackDuckBackup creates or updates the service account, the RBAC and the CronJob that run the backup of the
+// instance with the synopsysctl image
+func scheduleBlackDuckBackup(name string, namespace string) error {
+	destination, err := util.ParseBackupDestination(backupOutput)
+	if err != nil {
+		return err
+	}
+	if destination.Kind == util.BackupDestinationDirectory {
+		return util.ValidationError("the output of scheduled backups must be pvc:CLAIM or s3://BUCKET[/PREFIX], the directories of the CronJob are lost after every run")
+	}
+	if backupScheduleRetention < 0 {
+		return util.ValidationError("--retention must not be negative, got %d", backupScheduleRetention)
+	}
+	if _, err := util.GetWithHelm3(name, namespace, kubeConfigPath); err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	if destination.Kind == util.BackupDestinationPVC {
+		if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(destination.Location, metav1.GetOptions{}); err != nil {
+			return fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", destination.Location, namespace, err)
+		}
+	}
+	if destination.Kind == util.BackupDestinationS3 && len(backupScheduleS3CredentialsSecret) == 0 {
+		log.Warnf("no --s3-credentials-secret, the scheduled backups use the credentials of the service account of the pod")
+	}
+
+	taskName := util.GetResourceName(name, util.BlackDuckName, scheduledBackupComponent)
+	labels := map[string]string{"app": util.BlackDuckName, "name": name, "component": scheduledBackupComponent, scheduledTaskLabel: taskName}
+
+	// backup blackduck reads the release, scales the jobrunner and scan deployments, and runs and copies the dumps of
+	// the backup job
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/exec"}, Verbs: []string{"create"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "patch", "update"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "create", "delete"}},
+	}
+	if err := createScheduledTaskServiceAccount(kubeClient, taskName, namespace, labels, rules); err != nil {
+		return err
+	}
+
+	container := corev1.Container{
+		Name:  "backup",
+		Image: scheduleImage,
+		Args: []string{"backup", "blackduck", name, "-n", namespace, "--output", destination.String(),
+			"--retention", strconv.Itoa(backupScheduleRetention), "--image", backupImage, "--timeout", backupTimeout.String()},
+	}
+	if len(backupScheduleS3CredentialsSecret) > 0 {
+		container.EnvFrom = []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: backupScheduleS3CredentialsSecret}}},
+		}
+	}
+	podSpec := corev1.PodSpec{
+		ServiceAccountName: taskName,
+		Containers:         []corev1.Container{container},
+		// a failed backup is retried by the next schedule, retrying it immediately would stop the components again
+		RestartPolicy: corev1.RestartPolicyNever,
+	}
+	if err := createScheduledTask(kubeClient, taskName, namespace, backupScheduleCron, labels, podSpec); err != nil {
+		return err
+	}
+	log.Infof("scheduled backups of Black Duck '%s' in namespace '%s' at '%s' to '%s', keeping the last %d backups", name, namespace, backupScheduleCron, destination, backupScheduleRetention)
+	return nil
+}
+
+// addBackupScheduleFlags adds the flags of the scheduled Black Duck backups to 'backup schedule blackduck' and
+// 'schedule backup blackduck', which create the same CronJob
+func addBackupScheduleFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&backupScheduleCron, "cron", backupScheduleCron, "Schedule of the backups in cron format, e.g. \"0 2 * * *\"")
+	cobra.MarkFlagRequired(cmd.Flags(), "cron")
+	cmd.Flags().StringVar(&backupOutput, "output", backupOutput, "Destination of the backups, pvc:CLAIM for a persistent volume claim in the namespace, or s3://BUCKET[/PREFIX]")
+	cobra.MarkFlagRequired(cmd.Flags(), "output")
+	cmd.Flags().IntVar(&backupScheduleRetention, "retention", backupScheduleRetention, "Number of backups of the instance to keep in the destination (0 keeps all backups)")
+	cmd.Flags().StringVar(&backupScheduleS3CredentialsSecret, "s3-credentials-secret", backupScheduleS3CredentialsSecret, "Secret in the namespace with the AWS_* environs of the S3 credentials, region and endpoint")
+	cmd.Flags().StringVar(&scheduleImage, "synopsysctl-image", scheduleImage, "Image of synopsysctl that runs the backups")
+	cmd.Flags().StringVar(&backupImage, "image", backupImage, "Postgres client image of the backup job, its pg_dump must not be older than the Postgres server")
+	cmd.Flags().DurationVar(&backupTimeout, "timeout", backupTimeout, "Maximum time of a backup to dump and copy the databases")
+}
+
+// getArgValue returns the value following the flag in the arguments of a command, or an empty string
+func getArgValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func init() {
+	backupScheduleCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(backupScheduleCmd.PersistentFlags(), "namespace")
+
+	addBackupScheduleFlags(backupScheduleBlackDuckCmd)
+	backupScheduleCmd.AddCommand(backupScheduleBlackDuckCmd)
+	backupScheduleCmd.AddCommand(backupScheduleListCmd)
+	backupScheduleCmd.AddCommand(backupScheduleDeleteCmd)
+	backupCmd.AddCommand(backupScheduleCmd)
+}
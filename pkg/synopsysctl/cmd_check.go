@@ -0,0 +1,322 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Check Command Options and Defaults
+var checkKubeVersion = ""
+var checkOutputFormat = "table"
+var checkSkipRemediation = false
+var checkCertificatesWithin = util.CertificateExpiryWarningPeriod
+var checkWebhookURL = ""
+var checkWebhookFormat = util.CertificateWebhookFormatJSON
+var checkStorageClass = ""
+var checkRegistry = "docker.io/blackducksoftware"
+var checkInstanceName = ""
+var checkBlackDuckSize = "small"
+
+// checkCmd checks the Synopsys resources in the cluster
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the Synopsys resources in your cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// checkClusterUpgradeCmd reports the instances that break on a Kubernetes version and the product versions that fix them
+var checkClusterUpgradeCmd = &cobra.Command{
+	Use:           "cluster-upgrade --to VERSION",
+	Example:       "synopsysctl check cluster-upgrade --to 1.29\nsynopsysctl check cluster-upgrade --to 1.25 -n <namespace> --output json",
+	Short:         "Check the instances for removed APIs, PodSecurityPolicies and Ingress classes that break on a Kubernetes version",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(checkOutputFormat)
+		if format != "table" && format != "json" && format != "yaml" {
+			return util.ValidationError("output format must be 'table', 'json' or 'yaml', got '%s'", checkOutputFormat)
+		}
+		var minimumChart products.MinimumChartFunc
+		if !checkSkipRemediation {
+			minimumChart = getMinimumCompatibleChart(checkKubeVersion)
+		}
+		impacts, err := products.AnalyzeClusterUpgrade(namespace, checkKubeVersion, getProductClients(), minimumChart)
+		if err != nil {
+			return err
+		}
+
+		broken := 0
+		for _, impact := range impacts {
+			if len(impact.Findings) > 0 {
+				broken++
+			}
+		}
+		if format == "table" {
+			printUpgradeImpacts(impacts)
+		} else if _, err := PrintComponent(impacts, format); err != nil {
+			return err
+		}
+		if broken > 0 {
+			return fmt.Errorf("%d of %d instance(s) need changes before upgrading the cluster to Kubernetes %s", broken, len(impacts), checkKubeVersion)
+		}
+		return nil
+	},
+}
+
+// checkCertificatesCmd reports the certificates of the instances that expired or expire soon and optionally sends them to a webhook
+var checkCertificatesCmd = &cobra.Command{
+	Use:           "certificates",
+	Example:       "synopsysctl check certificates\nsynopsysctl check certificates -n <namespace> --within 168h\nsynopsysctl check certificates --webhook-url <url> --webhook-format slack",
+	Short:         "Check the certificates in the secrets of the Alert and Black Duck instances for their expiry",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(checkOutputFormat)
+		if format != "table" && format != "json" && format != "yaml" {
+			return util.ValidationError("output format must be 'table', 'json' or 'yaml', got '%s'", checkOutputFormat)
+		}
+		if len(checkWebhookURL) > 0 {
+			if err := util.ValidateCertificateWebhookFormat(checkWebhookFormat); err != nil {
+				return err
+			}
+		}
+		expiries, err := getCertificateExpiries(namespace, checkCertificatesWithin)
+		if err != nil {
+			return err
+		}
+		if format == "table" {
+			printCertificateExpiries(expiries)
+		} else if _, err := PrintComponent(expiries, format); err != nil {
+			return err
+		}
+		if len(expiries) == 0 {
+			return nil
+		}
+		// when a webhook is configured, the notification is the result; failing would make a scheduled job retry and notify again
+		if len(checkWebhookURL) > 0 {
+			if err := util.NotifyCertificateExpiry(checkWebhookURL, checkWebhookFormat, expiries, checkCertificatesWithin); err != nil {
+				return err
+			}
+			log.Infof("sent %d certificate expiry notification(s) to the webhook", len(expiries))
+			return nil
+		}
+		return fmt.Errorf("%d certificate(s) expired or expire within %s", len(expiries), checkCertificatesWithin)
+	},
+}
+
+// newCheckPreflightCmd returns the command that validates the cluster before an instance of a product is created
+func newCheckPreflightCmd(app string, product string) *cobra.Command {
+	return &cobra.Command{
+		Use:           fmt.Sprintf("%s -n NAMESPACE", app),
+		Example:       fmt.Sprintf("synopsysctl check %s -n <namespace>\nsynopsysctl check %s -n <namespace> --name <name>\nsynopsysctl check %s -n <namespace> --pvc-storage-class <class> --output json", app, app, app),
+		Short:         fmt.Sprintf("Check that the cluster meets the requirements of a %s instance before creating it", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := strings.ToLower(checkOutputFormat)
+			if format != "table" && format != "json" && format != "yaml" {
+				return util.ValidationError("output format must be 'table', 'json' or 'yaml', got '%s'", checkOutputFormat)
+			}
+			report := runPreflightChecks(cmd.Flags(), app, namespace, checkInstanceName, checkStorageClass, checkRegistry)
+			if format == "table" {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintln(w, "CHECK\tSTATUS\tMESSAGE")
+				for _, result := range report.Results {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, strings.ToUpper(result.Status), result.Message)
+				}
+				w.Flush()
+			} else if _, err := PrintComponent(report, format); err != nil {
+				return err
+			}
+			if report.Status == util.PreflightFail {
+				return fmt.Errorf("%d of %d preflight check(s) failed for %s in namespace '%s'", report.Count(util.PreflightFail), len(report.Results), product, namespace)
+			}
+			if report.Status == util.PreflightWarn {
+				log.Warnf("%d preflight check(s) passed with warnings for %s in namespace '%s'", report.Count(util.PreflightWarn), product, namespace)
+			}
+			return nil
+		},
+	}
+}
+
+// getCertificateExpiries returns the secrets of the Alert and Black Duck instances with certificates that expire within the duration
+func getCertificateExpiries(searchNamespace string, within time.Duration) ([]util.CertificateExpiry, error) {
+	now := time.Now()
+	expiries := []util.CertificateExpiry{}
+	for _, app := range []string{util.AlertName, util.BlackDuckName} {
+		instances, err := listFleetInstances(app, searchNamespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			secrets, err := getInstanceSecrets(instance.App, instance.Name, instance.Namespace)
+			if err != nil {
+				log.Warnf("unable to check the certificates of %s '%s' in namespace '%s': %+v", instance.App, instance.Name, instance.Namespace, err)
+				continue
+			}
+			for _, secret := range secrets {
+				if expiry, found := util.GetCertificateExpiry(instance.App, instance.Name, instance.Namespace, secret.Secret.Name, secret.Secret.Data, within, now); found {
+					expiries = append(expiries, expiry)
+				}
+			}
+		}
+	}
+	util.SortCertificateExpiries(expiries)
+	return expiries, nil
+}
+
+// printCertificateExpiries prints the certificates that expired or expire soon, the earliest first
+func printCertificateExpiries(expiries []util.CertificateExpiry) {
+	if len(expiries) == 0 {
+		log.Infof("no certificates expire within %s", checkCertificatesWithin)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PRODUCT\tNAME\tNAMESPACE\tSECRET\tEXPIRY\tEXPIRED")
+	for _, expiry := range expiries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n", expiry.App, expiry.Name, expiry.Namespace, expiry.Secret, expiry.Expiry.Format("2006-01-02 15:04:05"), expiry.Expired)
+	}
+	w.Flush()
+}
+
+// getMinimumCompatibleChart returns a function that renders the newer charts of an instance with its values to find the
+// lowest version that is compatible with the Kubernetes version
+func getMinimumCompatibleChart(kubeVersion string) products.MinimumChartFunc {
+	return func(app string, rel *release.Release) (string, error) {
+		if rel.Chart == nil || rel.Chart.Metadata == nil {
+			return "", nil
+		}
+		log.Debugf("looking for a version of %s '%s' that is compatible with Kubernetes %s", app, rel.Name, kubeVersion)
+		return util.MinimumCompatibleChartURL(globals.IndexChartURLs, rel.Chart.Metadata.Name, rel.Chart.Metadata.Version, kubeVersion, func(chartURL string) (string, error) {
+			actionConfig, err := util.CreateHelmActionConfiguration(kubeConfigPath, "", rel.Namespace)
+			if err != nil {
+				return "", err
+			}
+			chart, err := util.LoadChart(chartURL, actionConfig)
+			if err != nil {
+				return "", err
+			}
+			return util.RenderManifests(rel.Name, rel.Namespace, chart, rel.Config, actionConfig)
+		})
+	}
+}
+
+// printUpgradeImpacts prints the remediation of each instance followed by the findings
+func printUpgradeImpacts(impacts []products.UpgradeImpact) {
+	if len(impacts) == 0 {
+		log.Infof("no instances found")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PRODUCT\tNAME\tNAMESPACE\tCHART VERSION\tFINDINGS\tREMEDIATION")
+	for _, impact := range impacts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", impact.App, impact.Name, impact.Namespace, impact.ChartVersion, len(impact.Findings), impact.Remediation)
+	}
+	w.Flush()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	header := false
+	for _, impact := range impacts {
+		for _, finding := range impact.Findings {
+			if !header {
+				fmt.Println()
+				fmt.Fprintln(w, "INSTANCE\tRULE\tKIND\tNAME\tMESSAGE")
+				header = true
+			}
+			fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\n", impact.Namespace, impact.Name, finding.Rule, finding.Kind, finding.Name, finding.Message)
+		}
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkClusterUpgradeCmd.Flags().StringVar(&checkKubeVersion, "to", checkKubeVersion, "Kubernetes version that the cluster will be upgraded to, e.g. 1.29")
+	cobra.MarkFlagRequired(checkClusterUpgradeCmd.Flags(), "to")
+	checkClusterUpgradeCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instances (default all namespaces)")
+	checkClusterUpgradeCmd.Flags().StringVarP(&checkOutputFormat, "output", "o", checkOutputFormat, "Output format [table|json|yaml]")
+	checkClusterUpgradeCmd.Flags().BoolVar(&checkSkipRemediation, "skip-remediation", checkSkipRemediation, "If true, don't render the newer charts to find the minimum compatible version")
+	checkCmd.AddCommand(checkClusterUpgradeCmd)
+
+	checkCertificatesCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instances (default all namespaces)")
+	checkCertificatesCmd.Flags().DurationVar(&checkCertificatesWithin, "within", checkCertificatesWithin, "Report the certificates that expire within this duration")
+	checkCertificatesCmd.Flags().StringVarP(&checkOutputFormat, "output", "o", checkOutputFormat, "Output format [table|json|yaml]")
+	checkCertificatesCmd.Flags().StringVar(&checkWebhookURL, "webhook-url", checkWebhookURL, "URL of a webhook that the expiring certificates are posted to")
+	checkCertificatesCmd.Flags().StringVar(&checkWebhookFormat, "webhook-format", checkWebhookFormat, "Format of the webhook request [json|slack]")
+	checkCmd.AddCommand(checkCertificatesCmd)
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+		{app: globals.BDBAName, name: "BDBA"},
+	} {
+		preflightCmd := newCheckPreflightCmd(product.app, product.name)
+		preflightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+		cobra.MarkFlagRequired(preflightCmd.Flags(), "namespace")
+		preflightCmd.Flags().StringVarP(&checkOutputFormat, "output", "o", checkOutputFormat, "Output format [table|json|yaml]")
+		preflightCmd.Flags().StringVar(&checkStorageClass, "pvc-storage-class", checkStorageClass, "Storage class of the instance's volumes (default the default storage class)")
+		preflightCmd.Flags().StringVar(&checkRegistry, "registry", checkRegistry, "Registry of the images of the instance")
+		preflightCmd.Flags().StringVar(&checkInstanceName, "name", checkInstanceName, "Name of the instance, to check that it doesn't collide with the releases and resources of other instances in the namespace")
+		if product.app == util.BlackDuckName {
+			preflightCmd.Flags().StringVar(&checkBlackDuckSize, "size", checkBlackDuckSize, "Size of the Black Duck instance whose requests are compared with the available resources")
+		}
+		checkCmd.AddCommand(preflightCmd)
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Collect Command Options and Defaults
+var collectOutputFile = ""
+var collectLogsSince time.Duration
+
+// collectCmd collects diagnostics bundles
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Collect a diagnostics bundle of a Synopsys resource for support",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// newCollectCmd returns the collect command of a product
+func newCollectCmd(app string, product string) *cobra.Command {
+	use, example, argCount := instanceUsage(app)
+	return &cobra.Command{
+		Use:           use,
+		Example:       fmt.Sprintf("synopsysctl collect %s\nsynopsysctl collect %s --output bundle.tar.gz --since 24h", example, example),
+		Short:         fmt.Sprintf("Collect the logs, events, objects, configuration and Helm release of a %s instance into an archive", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != argCount {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": argCount, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := globals.BDBAName
+			if len(args) > 0 {
+				name = args[0]
+			}
+			instance, err := products.New(app, name, namespace, getProductClients())
+			if err != nil {
+				return err
+			}
+			root := fmt.Sprintf("%s-%s-%s", app, name, time.Now().Format("20060102-150405"))
+			outputFile := collectOutputFile
+			if len(outputFile) == 0 {
+				outputFile = root + ".tar.gz"
+			}
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("unable to create the bundle '%s' due to %+v", outputFile, err)
+			}
+			defer f.Close()
+
+			bundle := util.NewBundleWriter(f, root)
+			collectErrors := collectInstanceBundle(bundle, instance)
+			if len(collectErrors) > 0 {
+				if err := bundle.AddFile("errors.txt", []byte(strings.Join(collectErrors, "\n")+"\n")); err != nil {
+					return err
+				}
+			}
+			if err := bundle.Close(); err != nil {
+				return fmt.Errorf("unable to write the bundle '%s' due to %+v", outputFile, err)
+			}
+			if len(collectErrors) > 0 {
+				log.Warnf("%d part(s) of the bundle could not be collected, see errors.txt in the bundle", len(collectErrors))
+			}
+			log.Infof("diagnostics bundle of %s '%s' in namespace '%s' written to '%s', the values of secrets are redacted", product, name, namespace, outputFile)
+			return nil
+		},
+	}
+}
+
+// collectInstanceBundle adds the diagnostics of the instance to the bundle. A part that can't be collected doesn't stop
+// the collection, its error is returned instead
+func collectInstanceBundle(bundle *util.BundleWriter, instance products.Product) []string {
+	collectErrors := []string{}
+	fail := func(format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		log.Warnf("%s", message)
+		collectErrors = append(collectErrors, message)
+	}
+	add := func(name string, data []byte) {
+		if err := bundle.AddFile(name, data); err != nil {
+			fail("%+v", err)
+		}
+	}
+	addYAML := func(name string, v interface{}) {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			fail("unable to marshal '%s' due to %+v", name, err)
+			return
+		}
+		add(name, data)
+	}
+	ns := instance.Namespace()
+	selector := instance.LabelSelector()
+	options := metav1.ListOptions{LabelSelector: selector}
+
+	// versions
+	version := &bytes.Buffer{}
+	fmt.Fprintf(version, "synopsysctl: %s\n", rootCmd.Version)
+	if info, err := util.GetClusterInfo(kubeClient); err == nil {
+		fmt.Fprintf(version, "kubernetes: %s\nopenshift: %t\n", info.ServerVersion, info.IsOpenShift)
+	}
+	productVersion, err := instance.Version()
+	if err != nil {
+		fail("%+v", err)
+	}
+	fmt.Fprintf(version, "%s: %s\ncollected at: %s\n", instance.App(), productVersion, time.Now().Format(time.RFC3339))
+	add("version.txt", version.Bytes())
+
+	// Helm release
+	log.Infof("collecting the Helm release '%s'...", instance.ReleaseName())
+	if release, err := util.GetWithHelm3(instance.ReleaseName(), ns, kubeConfigPath); err != nil {
+		fail("unable to get the Helm release '%s' due to %+v", instance.ReleaseName(), err)
+	} else {
+		addYAML("helm/values.yaml", util.RedactHelmValues(release.Config))
+		if manifest, err := util.RedactManifest(release.Manifest); err != nil {
+			fail("unable to redact the manifest of the Helm release due to %+v", err)
+		} else {
+			add("helm/manifest.yaml", []byte(manifest))
+		}
+		releaseInfo := fmt.Sprintf("name: %s\nrevision: %d\n", release.Name, release.Version)
+		if release.Info != nil {
+			releaseInfo += fmt.Sprintf("status: %s\nupdated: %s\n", release.Info.Status, release.Info.LastDeployed.Format(time.RFC3339))
+		}
+		if release.Chart != nil && release.Chart.Metadata != nil {
+			releaseInfo += fmt.Sprintf("chart: %s-%s\n", release.Chart.Metadata.Name, release.Chart.Metadata.Version)
+		}
+		add("helm/release.txt", []byte(releaseInfo))
+	}
+
+	// objects
+	log.Infof("collecting the objects...")
+	pods, err := kubeClient.CoreV1().Pods(ns).List(options)
+	if err != nil {
+		fail("unable to list the pods due to %+v", err)
+		pods = &corev1.PodList{}
+	} else {
+		addYAML("objects/pods.yaml", pods)
+	}
+	if deployments, err := kubeClient.AppsV1().Deployments(ns).List(options); err != nil {
+		fail("unable to list the deployments due to %+v", err)
+	} else {
+		addYAML("objects/deployments.yaml", deployments)
+	}
+	if statefulSets, err := kubeClient.AppsV1().StatefulSets(ns).List(options); err != nil {
+		fail("unable to list the stateful sets due to %+v", err)
+	} else {
+		addYAML("objects/statefulsets.yaml", statefulSets)
+	}
+	if services, err := kubeClient.CoreV1().Services(ns).List(options); err != nil {
+		fail("unable to list the services due to %+v", err)
+	} else {
+		addYAML("objects/services.yaml", services)
+	}
+	if pvcs, err := kubeClient.CoreV1().PersistentVolumeClaims(ns).List(options); err != nil {
+		fail("unable to list the persistent volume claims due to %+v", err)
+	} else {
+		addYAML("objects/persistentvolumeclaims.yaml", pvcs)
+	}
+	if describe, err := RunKubeCmd(restconfig, kubeClient, "describe", "pods,deployments,statefulsets,services,persistentvolumeclaims", "-n", ns, "-l", selector); err != nil {
+		fail("unable to describe the objects with kubectl, see the objects directory instead: %+v", err)
+	} else {
+		add("describe.txt", []byte(describe))
+	}
+
+	// config maps
+	if configMaps, err := kubeClient.CoreV1().ConfigMaps(ns).List(options); err != nil {
+		fail("unable to list the config maps due to %+v", err)
+	} else {
+		for _, configMap := range configMaps.Items {
+			configMap.Data = util.RedactConfigMapData(configMap.Data)
+			configMap.BinaryData = nil
+			configMap.ManagedFields = nil
+			addYAML(path.Join("configmaps", configMap.Name+".yaml"), configMap)
+		}
+	}
+
+	// events
+	if components, err := getInstanceObjectComponents(ns, selector); err != nil {
+		fail("unable to list the objects of the events due to %+v", err)
+	} else if events, err := kubeClient.CoreV1().Events(ns).List(metav1.ListOptions{}); err != nil {
+		fail("unable to list the events due to %+v", err)
+	} else {
+		out := &bytes.Buffer{}
+		printInstanceEvents(out, util.AggregateInstanceEvents(events.Items, components, time.Time{}), true)
+		add("events.txt", out.Bytes())
+	}
+
+	// logs
+	log.Infof("collecting the logs of %d pod(s)...", len(pods.Items))
+	for _, pod := range pods.Items {
+		restarted := map[string]bool{}
+		for _, status := range pod.Status.ContainerStatuses {
+			restarted[status.Name] = status.RestartCount > 0
+		}
+		for _, source := range util.GetLogSources([]corev1.Pod{pod}, "") {
+			logFile := path.Join("logs", source.Pod, source.Container+".log")
+			if data, err := getContainerLogs(ns, source, false); err != nil {
+				fail("unable to get the logs of container '%s' of pod '%s' due to %+v", source.Container, source.Pod, err)
+			} else {
+				add(logFile, data)
+			}
+			if !restarted[source.Container] {
+				continue
+			}
+			if data, err := getContainerLogs(ns, source, true); err != nil {
+				fail("unable to get the previous logs of container '%s' of pod '%s' due to %+v", source.Container, source.Pod, err)
+			} else {
+				add(path.Join("logs", source.Pod, source.Container+".previous.log"), data)
+			}
+		}
+	}
+	return collectErrors
+}
+
+// getContainerLogs returns the logs of a container, or of its previous instance if it restarted
+func getContainerLogs(namespace string, source util.LogSource, previous bool) ([]byte, error) {
+	options := &corev1.PodLogOptions{Container: source.Container, Previous: previous}
+	if collectLogsSince > 0 {
+		seconds := int64(collectLogsSince.Seconds())
+		options.SinceSeconds = &seconds
+	}
+	stream, err := kubeClient.CoreV1().Pods(namespace).GetLogs(source.Pod, options).Stream()
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return ioutil.ReadAll(stream)
+}
+
+func init() {
+	rootCmd.AddCommand(collectCmd)
+
+	collectCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(collectCmd.PersistentFlags(), "namespace")
+	collectCmd.PersistentFlags().StringVarP(&collectOutputFile, "output", "o", collectOutputFile, "File of the bundle (default <product>-<name>-<time>.tar.gz)")
+	collectCmd.PersistentFlags().DurationVar(&collectLogsSince, "since", collectLogsSince, "Only collect the logs newer than the duration, e.g. 24h (default all logs)")
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+		{app: globals.BDBAName, name: "BDBA"},
+	} {
+		collectCmd.AddCommand(newCollectCmd(product.app, product.name))
+	}
+}
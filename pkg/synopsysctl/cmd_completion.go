@@ -0,0 +1,275 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/flags"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of the flag values that are completed by the cluster or the chart repository
+const (
+	completeChartVersions  = "versions"
+	completeNamespaces     = "namespaces"
+	completeStorageClasses = "storage-classes"
+	completeSizes          = "sizes"
+)
+
+// completionFunctions are the bash functions that complete the flag values, they are called with the words of the
+// command line in scope. The flags that select the cluster, the namespace and the custom sizes are passed to
+// __complete-values
+const completionFunctions = `
+__synopsysctl_complete_words()
+{
+    COMPREPLY=( $(compgen -W "$*" -- "${cur}") )
+}
+
+__synopsysctl_complete_values()
+{
+    local args=() i
+    for ((i = 1; i < cword; i++)); do
+        case "${words[i]}" in
+            -n|--namespace|--kubeconfig|--size-file-path|--size-configmap)
+                args+=("${words[i]}" "${words[i+1]}")
+                ;;
+            --namespace=*|--kubeconfig=*|--size-file-path=*|--size-configmap=*)
+                args+=("${words[i]}")
+                ;;
+        esac
+    done
+    local out
+    out=$(${words[0]} __complete-values "$1" "${last_command}" "${args[@]}" 2>/dev/null)
+    COMPREPLY=( $(compgen -W "${out}" -- "${cur}") )
+}
+`
+
+// completionChartNames are the names of the charts of the products whose versions are completed
+var completionChartNames = map[string]*string{
+	util.AlertName:       &globals.AlertChartName,
+	util.BlackDuckName:   &globals.BlackDuckChartName,
+	util.OpsSightName:    &globals.OpsSightChartName,
+	globals.BDBAName:     &globals.BDBAChartName,
+	globals.CoverityName: &globals.CoverityChartName,
+	globals.PolarisName:  &globals.PolarisChartName,
+}
+
+// exposeUIValues are the values of the --expose-ui flags that aren't Enum flags
+var exposeUIValues = []string{util.NODEPORT, util.LOADBALANCER, util.INGRESS, util.OPENSHIFT, util.NONE}
+
+// Complete Values Command Options and Defaults
+var completeValuesNamespace = ""
+var completeValuesSizeFilePath = ""
+var completeValuesSizeConfigMap = ""
+
+// completionCmd prints the completion script of a shell
+var completionCmd = &cobra.Command{
+	Use:   "completion SHELL",
+	Short: "Print the completion script of the shell, with the completion of the flag values such as versions, namespaces, storage classes and sizes",
+	Long: `Print the bash completion script. Load it in the current shell with:
+
+  source <(synopsysctl completion bash)
+
+or in zsh with:
+
+  autoload -U +X bashcompinit && bashcompinit
+  source <(synopsysctl completion bash)`,
+	Example:       "synopsysctl completion bash > /etc/bash_completion.d/synopsysctl",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		if args[0] != "bash" {
+			return util.ValidationError("shell must be 'bash', got '%s'", args[0])
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rootCmd.GenBashCompletion(os.Stdout)
+	},
+}
+
+// completeValuesCmd prints the values of a flag for the completion script, it prints nothing if the values can't be
+// found, e.g. if the cluster isn't reachable
+var completeValuesCmd = &cobra.Command{
+	Use:           "__complete-values KIND COMMAND",
+	Hidden:        true,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args:          cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values, err := getCompletionValues(cmd, args[0], args[1])
+		if err != nil {
+			log.Debugf("unable to complete the %s due to %+v", args[0], err)
+			return nil
+		}
+		fmt.Println(strings.Join(values, "\n"))
+		return nil
+	},
+}
+
+// getCompletionValues returns the values of a kind of flag values, the command is the completed command in the format
+// of the completion script, e.g. synopsysctl_create_blackduck
+func getCompletionValues(cmd *cobra.Command, kind string, command string) ([]string, error) {
+	switch kind {
+	case completeChartVersions:
+		product := getCompletionProduct(command)
+		chartName, ok := completionChartNames[product]
+		if !ok {
+			return nil, fmt.Errorf("command '%s' has no product", command)
+		}
+		versions := util.GetAppVersionsFromChartURLs(globals.IndexChartURLs, *chartName)
+		sort.Slice(versions, func(i, j int) bool { return util.CompareVersions(versions[i], versions[j]) > 0 })
+		return versions, nil
+	case completeSizes:
+		sizes := blackduck.NewSizeRegistry()
+		if len(completeValuesSizeFilePath) > 0 {
+			if err := sizes.AddSizesFromFile(completeValuesSizeFilePath); err != nil {
+				return nil, err
+			}
+		}
+		if len(completeValuesSizeConfigMap) > 0 && len(completeValuesNamespace) > 0 && setCompletionKubeClient(cmd) == nil {
+			if cm, err := kubeClient.CoreV1().ConfigMaps(completeValuesNamespace).Get(completeValuesSizeConfigMap, metav1.GetOptions{}); err == nil {
+				sizes.AddSizesFromConfigMap(cm)
+			}
+		}
+		return append(blackduck.GetBuiltInSizes(), sizes.GetSizeNames()...), nil
+	case completeNamespaces:
+		if err := setCompletionKubeClient(cmd); err != nil {
+			return nil, err
+		}
+		namespaces, err := kubeClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for _, ns := range namespaces.Items {
+			names = append(names, ns.Name)
+		}
+		return names, nil
+	case completeStorageClasses:
+		if err := setCompletionKubeClient(cmd); err != nil {
+			return nil, err
+		}
+		storageClasses, err := kubeClient.StorageV1().StorageClasses().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for _, storageClass := range storageClasses.Items {
+			names = append(names, storageClass.Name)
+		}
+		return names, nil
+	}
+	return nil, fmt.Errorf("unknown kind '%s'", kind)
+}
+
+// getCompletionProduct returns the product of a command in the format of the completion script, e.g. blackduck for
+// synopsysctl_create_bd, or an empty string
+func getCompletionProduct(command string) string {
+	for _, name := range strings.Split(command, "_") {
+		if product := getProductName(name); len(product) > 0 {
+			return product
+		}
+		if _, ok := completionChartNames[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// setCompletionKubeClient sets the client of the cluster of the completed command, the root command doesn't set it
+// for __complete-values since most values don't need the cluster
+func setCompletionKubeClient(cmd *cobra.Command) error {
+	if kubeClient != nil {
+		return nil
+	}
+	if err := setGlobalKubeConfigPath(cmd); err != nil {
+		return err
+	}
+	if err := setGlobalRestConfig(); err != nil {
+		return err
+	}
+	return setGlobalKubeClient()
+}
+
+// setFlagCompletion sets the bash function that completes the values of a flag
+func setFlagCompletion(flag *pflag.Flag, function string) {
+	if flag.Annotations == nil {
+		flag.Annotations = map[string][]string{}
+	}
+	if _, ok := flag.Annotations[cobra.BashCompCustom]; !ok {
+		flag.Annotations[cobra.BashCompCustom] = []string{function}
+	}
+}
+
+// addFlagCompletions sets the completion of the values of the flags of all commands: the versions of the charts, the
+// namespaces, the storage classes, the sizes, the expose types and the values of the Enum flags
+func addFlagCompletions(cmd *cobra.Command) {
+	visit := func(flag *pflag.Flag) {
+		switch {
+		case flag.Name == "version":
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_values %s", completeChartVersions))
+		case flag.Name == "namespace":
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_values %s", completeNamespaces))
+		case strings.HasSuffix(flag.Name, "storage-class"):
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_values %s", completeStorageClasses))
+		case flag.Name == "size":
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_values %s", completeSizes))
+		case len(flag.Annotations[flags.EnumValuesAnnotation]) > 0:
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_words %s", strings.Join(flag.Annotations[flags.EnumValuesAnnotation], " ")))
+		case flag.Name == "expose-ui":
+			setFlagCompletion(flag, fmt.Sprintf("__synopsysctl_complete_words %s", strings.Join(exposeUIValues, " ")))
+		}
+	}
+	cmd.Flags().VisitAll(visit)
+	cmd.PersistentFlags().VisitAll(visit)
+	for _, subCmd := range cmd.Commands() {
+		addFlagCompletions(subCmd)
+	}
+}
+
+func init() {
+	rootCmd.BashCompletionFunction = completionFunctions
+	rootCmd.AddCommand(completionCmd)
+
+	completeValuesCmd.Flags().StringVarP(&completeValuesNamespace, "namespace", "n", completeValuesNamespace, "Namespace of the completed command")
+	completeValuesCmd.Flags().StringVar(&completeValuesSizeFilePath, "size-file-path", completeValuesSizeFilePath, "--size-file-path of the completed command")
+	completeValuesCmd.Flags().StringVar(&completeValuesSizeConfigMap, "size-configmap", completeValuesSizeConfigMap, "--size-configmap of the completed command")
+	rootCmd.AddCommand(completeValuesCmd)
+}
@@ -0,0 +1,243 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configLongHelp describes the format of the config file
+const configLongHelp = `The config file sets the defaults of the flags, so that the flags of an environment don't have to be typed for every
+command. A flag set on the command line overrides its default. A default in the section of a command, e.g. of the
+product, applies to the command and its sub-commands and overrides a top-level default:
+
+  kubeconfig: /home/me/.kube/production
+  namespace: synopsys
+  blackduck:
+    version: 2020.6.0
+    registry: registry.example.com/synopsys
+    app-resources-path: /charts/blackduck-2020.6.0.tgz
+  alert:
+    version: 6.0.0
+
+The config file is ~/.synopsysctl.yaml unless --config is set. The defaults can also be set by the environ, e.g.
+SYNOPSYSCTL_BLACKDUCK_VERSION`
+
+// configCmd views and edits the synopsysctl config file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and edit the synopsysctl config file of flag defaults",
+	Long:  configLongHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// configViewCmd prints the config file
+var configViewCmd = &cobra.Command{
+	Use:           "view",
+	Example:       "synopsysctl config view",
+	Short:         "Print the synopsysctl config file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := util.ReadConfigFile(configFilePath())
+		if err != nil {
+			return err
+		}
+		_, err = PrintComponent(config, "YAML")
+		return err
+	},
+}
+
+// configGetCmd prints a value of the config file
+var configGetCmd = &cobra.Command{
+	Use:           "get KEY",
+	Example:       "synopsysctl config get namespace\nsynopsysctl config get blackduck.version",
+	Short:         "Print a value of the synopsysctl config file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := util.ReadConfigFile(configFilePath())
+		if err != nil {
+			return err
+		}
+		value, ok := util.GetConfigValue(config, args[0])
+		if !ok {
+			return fmt.Errorf("'%s' is not set in the config file '%s'", args[0], configFilePath())
+		}
+		if formatted, ok := util.FormatConfigValue(value); ok {
+			fmt.Println(formatted)
+			return nil
+		}
+		_, err = PrintComponent(value, "YAML")
+		return err
+	},
+}
+
+// configSetCmd sets a value of the config file
+var configSetCmd = &cobra.Command{
+	Use:           "set KEY VALUE",
+	Example:       "synopsysctl config set kubeconfig ~/.kube/production\nsynopsysctl config set blackduck.version 2020.6.0",
+	Short:         "Set a value of the synopsysctl config file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 2, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configFilePath()
+		config, err := util.ReadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if err := util.SetConfigValue(config, args[0], args[1]); err != nil {
+			return err
+		}
+		if err := util.WriteConfigFile(path, config); err != nil {
+			return err
+		}
+		log.Infof("set '%s' in the config file '%s'", args[0], path)
+		return nil
+	},
+}
+
+// configUnsetCmd removes a value from the config file
+var configUnsetCmd = &cobra.Command{
+	Use:           "unset KEY",
+	Example:       "synopsysctl config unset blackduck.version",
+	Short:         "Remove a value from the synopsysctl config file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configFilePath()
+		config, err := util.ReadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if !util.UnsetConfigValue(config, args[0]) {
+			log.Infof("'%s' is not set in the config file '%s'", args[0], path)
+			return nil
+		}
+		if err := util.WriteConfigFile(path, config); err != nil {
+			return err
+		}
+		log.Infof("removed '%s' from the config file '%s'", args[0], path)
+		return nil
+	},
+}
+
+// configFilePath returns the path of the config file set by --config, or the default config file in the home directory
+func configFilePath() string {
+	if len(cfgFile) > 0 {
+		return cfgFile
+	}
+	if used := viper.ConfigFileUsed(); len(used) > 0 {
+		return used
+	}
+	return filepath.Join(homeDir(), util.ConfigFileName)
+}
+
+// applyConfigDefaults sets the flags of the command that aren't set on the command line to their defaults in the config
+// file, as if they were set on the command line
+func applyConfigDefaults(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed || err != nil {
+			return
+		}
+		key, value, ok := configDefault(cmd, f.Name)
+		if !ok {
+			return
+		}
+		if setErr := cmd.Flags().Set(f.Name, value); setErr != nil {
+			err = util.ValidationError("invalid default '%s' of '%s' in the config file due to %+v", value, key, setErr)
+			return
+		}
+		log.Debugf("using the default '%s' of '%s' from the config file", value, key)
+	})
+	return err
+}
+
+// configDefault returns the default of a flag of the command. The sections of the command and of its parents are
+// searched before the top-level settings, e.g. 'blackduck.version' before 'version'
+func configDefault(cmd *cobra.Command, flagName string) (string, string, bool) {
+	keys := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		keys = append(keys, fmt.Sprintf("%s.%s", c.Name(), flagName))
+	}
+	keys = append(keys, flagName)
+	for _, key := range keys {
+		if !viper.IsSet(key) {
+			continue
+		}
+		if value, ok := util.FormatConfigValue(viper.Get(key)); ok {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+}
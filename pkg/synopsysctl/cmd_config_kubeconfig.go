@@ -0,0 +1,266 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Import Kubeconfig Command Options and Defaults
+var importKubeconfigFromEKS = ""
+var importKubeconfigFromGKE = ""
+var importKubeconfigFromAKS = ""
+var importKubeconfigRegion = os.Getenv("AWS_REGION")
+var importKubeconfigProject = ""
+var importKubeconfigLocation = ""
+var importKubeconfigResourceGroup = ""
+var importKubeconfigSubscription = os.Getenv("AZURE_SUBSCRIPTION_ID")
+var importKubeconfigContextName = ""
+
+// Get Contexts Command Options and Defaults
+var getContextsOutputFormat = ""
+var getContextsNoHeaders = false
+
+// configImportKubeconfigCmd adds the context of a cluster of a managed Kubernetes service to the kubeconfig
+var configImportKubeconfigCmd = &cobra.Command{
+	Use:           "import-kubeconfig (--from-eks NAME --region REGION | --from-gke NAME --project PROJECT --location LOCATION | --from-aks NAME --resource-group GROUP --subscription ID)",
+	Example:       "synopsysctl config import-kubeconfig --from-eks <name> --region us-east-2\nsynopsysctl config import-kubeconfig --from-gke <name> --project <project> --location us-central1\nsynopsysctl config import-kubeconfig --from-aks <name> --resource-group <group> --subscription <id>",
+	Short:         "Add the context of an EKS, GKE or AKS cluster to the kubeconfig with the cloud credentials of the environment",
+	Long:          "Add the context of an EKS, GKE or AKS cluster to the kubeconfig and make it current, without the aws, gcloud or az CLIs.\nEKS uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, GKE uses GOOGLE_APPLICATION_CREDENTIALS,\nGOOGLE_OAUTH_ACCESS_TOKEN or the service account of the node, and AKS uses the service principal of AZURE_TENANT_ID,\nAZURE_CLIENT_ID and AZURE_CLIENT_SECRET. The tokens of EKS and GKE are refreshed by 'synopsysctl config get-token'.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		sources := 0
+		for _, source := range []string{importKubeconfigFromEKS, importKubeconfigFromGKE, importKubeconfigFromAKS} {
+			if len(source) > 0 {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return util.ValidationError("exactly one of --from-eks, --from-gke or --from-aks must be set")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pathOptions := clientcmd.NewDefaultPathOptions()
+		if len(kubeConfigPath) > 0 {
+			pathOptions.LoadingRules.ExplicitPath = kubeConfigPath
+		}
+		config, err := pathOptions.GetStartingConfig()
+		if err != nil {
+			return fmt.Errorf("unable to load the kubeconfig due to %+v", err)
+		}
+
+		contextName := importKubeconfigContextName
+		switch {
+		case len(importKubeconfigFromEKS) > 0:
+			if len(importKubeconfigRegion) == 0 {
+				return util.ValidationError("--region must be set for --from-eks")
+			}
+			cluster, err := util.GetEKSCluster(importKubeconfigFromEKS, importKubeconfigRegion, util.GetAWSCredentialsFromEnv())
+			if err != nil {
+				return err
+			}
+			if len(contextName) == 0 {
+				contextName = fmt.Sprintf("eks_%s_%s", importKubeconfigRegion, importKubeconfigFromEKS)
+			}
+			exec, err := newGetTokenExecConfig("--from-eks", importKubeconfigFromEKS, "--region", importKubeconfigRegion)
+			if err != nil {
+				return err
+			}
+			util.AddManagedClusterToKubeconfig(config, contextName, cluster, exec)
+		case len(importKubeconfigFromGKE) > 0:
+			if len(importKubeconfigProject) == 0 || len(importKubeconfigLocation) == 0 {
+				return util.ValidationError("--project and --location must be set for --from-gke")
+			}
+			token, err := util.GetGKEToken()
+			if err != nil {
+				return err
+			}
+			cluster, err := util.GetGKECluster(importKubeconfigProject, importKubeconfigLocation, importKubeconfigFromGKE, token)
+			if err != nil {
+				return err
+			}
+			if len(contextName) == 0 {
+				// the context name of 'gcloud container clusters get-credentials'
+				contextName = fmt.Sprintf("gke_%s_%s_%s", importKubeconfigProject, importKubeconfigLocation, importKubeconfigFromGKE)
+			}
+			exec, err := newGetTokenExecConfig("--from-gke")
+			if err != nil {
+				return err
+			}
+			util.AddManagedClusterToKubeconfig(config, contextName, cluster, exec)
+		default:
+			if len(importKubeconfigResourceGroup) == 0 || len(importKubeconfigSubscription) == 0 {
+				return util.ValidationError("--resource-group and --subscription must be set for --from-aks")
+			}
+			token, err := util.GetAzureAccessToken()
+			if err != nil {
+				return err
+			}
+			data, err := util.GetAKSKubeconfig(importKubeconfigSubscription, importKubeconfigResourceGroup, importKubeconfigFromAKS, token)
+			if err != nil {
+				return err
+			}
+			if len(contextName) == 0 {
+				// the context name of 'az aks get-credentials'
+				contextName = importKubeconfigFromAKS
+			}
+			if err := util.AddKubeconfigToKubeconfig(config, contextName, data); err != nil {
+				return fmt.Errorf("unable to add the kubeconfig of AKS cluster '%s' due to %+v", importKubeconfigFromAKS, err)
+			}
+		}
+
+		if err := clientcmd.ModifyConfig(pathOptions, *config, true); err != nil {
+			return fmt.Errorf("unable to write the kubeconfig due to %+v", err)
+		}
+		log.Infof("added context '%s' to '%s' and made it the current context", contextName, pathOptions.GetDefaultFilename())
+		return nil
+	},
+}
+
+// configGetTokenCmd prints the credential of the exec plugin of the users added by 'config import-kubeconfig'
+var configGetTokenCmd = &cobra.Command{
+	Use:           "get-token (--from-eks NAME --region REGION | --from-gke)",
+	Example:       "synopsysctl config get-token --from-eks <name> --region us-east-2",
+	Short:         "Print a token of an EKS or GKE cluster for the exec plugin of a kubeconfig user",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Hidden:        true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		var token string
+		var expiration time.Time
+		var err error
+		switch {
+		case len(importKubeconfigFromEKS) > 0:
+			token, err = util.NewEKSToken(importKubeconfigFromEKS, importKubeconfigRegion, util.GetAWSCredentialsFromEnv(), now.UTC())
+			expiration = now.Add(util.EKSTokenLifetime)
+		case cmd.Flags().Lookup("from-gke").Changed:
+			token, err = util.GetGKEToken()
+			// the access tokens of Google are valid for an hour, refresh them earlier
+			expiration = now.Add(50 * time.Minute)
+		default:
+			return util.ValidationError("--from-eks or --from-gke must be set")
+		}
+		if err != nil {
+			return err
+		}
+		credential, err := util.NewExecCredential(token, expiration)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(credential))
+		return nil
+	},
+}
+
+// configGetContextsCmd prints the contexts of the kubeconfig like 'kubectl config get-contexts'
+var configGetContextsCmd = &cobra.Command{
+	Use:           "get-contexts [NAME...]",
+	Example:       "synopsysctl config get-contexts\nKUBECONFIG=~/.kube/config:~/.kube/production synopsysctl config get-contexts -o name",
+	Short:         "Print the contexts of the kubeconfig, merged from the files of the KUBECONFIG environ like kubectl",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if getContextsOutputFormat != "" && getContextsOutputFormat != "name" {
+			return util.ValidationError("output format '%s' is not supported, it must be empty or 'name'", getContextsOutputFormat)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(kubeConfigPath) == 0 {
+			if kubeconfigEnvVal, exists := os.LookupEnv("KUBECONFIG"); exists {
+				if _, err := util.ValidateKubeconfigPaths(kubeconfigEnvVal); err != nil {
+					return err
+				}
+			}
+		}
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(util.NewKubeconfigLoadingRules(kubeConfigPath), &clientcmd.ConfigOverrides{}).RawConfig()
+		if err != nil {
+			return fmt.Errorf("unable to load the kubeconfig due to %+v", err)
+		}
+		contexts, err := util.GetKubeconfigContexts(&config, args...)
+		if err != nil {
+			return err
+		}
+		return util.PrintKubeconfigContexts(os.Stdout, contexts, getContextsOutputFormat == "name", getContextsNoHeaders)
+	},
+}
+
+// newGetTokenExecConfig returns the exec plugin of a kubeconfig user that runs 'config get-token' of this binary
+func newGetTokenExecConfig(args ...string) (*clientcmdapi.ExecConfig, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the path of synopsysctl due to %+v", err)
+	}
+	return &clientcmdapi.ExecConfig{
+		APIVersion: util.ExecCredentialAPIVersion,
+		Command:    executable,
+		Args:       append([]string{"config", "get-token"}, args...),
+	}, nil
+}
+
+func init() {
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigFromEKS, "from-eks", importKubeconfigFromEKS, "Name of the Amazon EKS cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigFromGKE, "from-gke", importKubeconfigFromGKE, "Name of the Google Kubernetes Engine cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigFromAKS, "from-aks", importKubeconfigFromAKS, "Name of the Azure Kubernetes Service cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigRegion, "region", importKubeconfigRegion, "AWS region of the EKS cluster (default $AWS_REGION)")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigProject, "project", importKubeconfigProject, "Google Cloud project of the GKE cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigLocation, "location", importKubeconfigLocation, "Region or zone of the GKE cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigResourceGroup, "resource-group", importKubeconfigResourceGroup, "Azure resource group of the AKS cluster")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigSubscription, "subscription", importKubeconfigSubscription, "Azure subscription ID of the AKS cluster (default $AZURE_SUBSCRIPTION_ID)")
+	configImportKubeconfigCmd.Flags().StringVar(&importKubeconfigContextName, "context-name", importKubeconfigContextName, "Name of the context, cluster and user in the kubeconfig (default the name of the cloud CLI)")
+	configCmd.AddCommand(configImportKubeconfigCmd)
+
+	configGetTokenCmd.Flags().StringVar(&importKubeconfigFromEKS, "from-eks", importKubeconfigFromEKS, "Name of the Amazon EKS cluster")
+	configGetTokenCmd.Flags().StringVar(&importKubeconfigRegion, "region", importKubeconfigRegion, "AWS region of the EKS cluster (default $AWS_REGION)")
+	configGetTokenCmd.Flags().Bool("from-gke", false, "Print an access token of the Google credentials for a GKE cluster")
+	configCmd.AddCommand(configGetTokenCmd)
+
+	configGetContextsCmd.Flags().StringVarP(&getContextsOutputFormat, "output", "o", getContextsOutputFormat, "Output format, name prints only the names of the contexts [name]")
+	configGetContextsCmd.Flags().BoolVar(&getContextsNoHeaders, "no-headers", getContextsNoHeaders, "If true, don't print the headers of the table")
+	configCmd.AddCommand(configGetContextsCmd)
+}
@@ -30,8 +30,12 @@ import (
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
 	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
 	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/coverity"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
 	"github.com/blackducksoftware/synopsysctl/pkg/opssight"
+	"github.com/blackducksoftware/synopsysctl/pkg/polaris"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 
 	log "github.com/sirupsen/logrus"
@@ -44,6 +48,8 @@ var createAlertCobraHelper alert.HelmValuesFromCobraFlags
 var createBlackDuckCobraHelper blackduck.HelmValuesFromCobraFlags
 var createOpsSightCobraHelper opssight.HelmValuesFromCobraFlags
 var createBDBACobraHelper bdba.HelmValuesFromCobraFlags
+var createCoverityCobraHelper coverity.HelmValuesFromCobraFlags
+var createPolarisCobraHelper polaris.HelmValuesFromCobraFlags
 
 // Default Base Specs for Create
 var baseAlertSpec string
@@ -54,12 +60,15 @@ var namespace string
 
 var alertNativePVC bool
 
+// skipS3Validation skips checking that the external S3 bucket of BDBA is accessible
+var skipS3Validation = false
+
 // createCmd creates a Synopsys resource in the cluster
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a Synopsys resource in your cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -78,7 +87,7 @@ var createAlertCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -100,6 +109,9 @@ var createAlertCmd = &cobra.Command{
 		helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
 
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createAlertCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createAlertCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -126,11 +138,23 @@ var createAlertCmd = &cobra.Command{
 		// Ensure helmValuesMap has the version set
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.AlertVersion)
 
+		// Set the minimal footprint of a trial instance
+		if pocMode {
+			if err := setPOCHelmValues(util.AlertName, helmValuesMap); err != nil {
+				return err
+			}
+		}
+
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
+		}
+
 		// Check Dry Run before deploying any resources
 		err = util.CreateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath, true)
 		if err != nil {
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg))
 		}
 
 		// Create secrets for Alert
@@ -146,31 +170,52 @@ var createAlertCmd = &cobra.Command{
 			if err != nil {
 				log.Fatalf("failed to read certificate file: %+v", err)
 			}
+			alertHostname, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"environs", "ALERT_HOSTNAME"}).(string)
+			if err := util.ValidateCertificateAndKey([]byte(certificateData), []byte(certificateKeyData), alertHostname); err != nil {
+				return fmt.Errorf("invalid Alert certificate: %+v", err)
+			}
 			customCertificateSecretName := "alert-custom-certificate"
 			customCertificateSecret := alert.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData)
 			util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
-			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create certifacte secret: %+v", err)
+			if !skipForPlan(util.PlanActionCreate, "Secret", customCertificateSecretName, namespace) {
+				if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
+					return fmt.Errorf("failed to create certifacte secret: %+v", err)
+				}
 			}
 		}
 
-		javaKeystoreFlag := cmd.Flag("java-keystore-file-path")
-		if javaKeystoreFlag.Changed {
-			javaKeystoreData, err := util.ReadFileData(javaKeystoreFlag.Value.String())
-			if err != nil {
-				log.Fatalf("failed to read Java Keystore file: %+v", err)
-			}
+		// Request the webserver certificate from cert-manager
+		certificate, err := configureCertificateIssuer(cmd.Flags(), util.AlertName, alertName, namespace, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if err := applyCertificateIssuer(certificate); err != nil {
+			return err
+		}
+
+		javaKeystoreData, ok, err := alert.GetJavaKeystoreDataFromFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		if ok {
 			javaKeystoreSecretName := "alert-java-keystore"
 			javaKeystoreSecret := alert.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData)
 			util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
-			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create javakeystore secret: %+v", err)
+			if !skipForPlan(util.PlanActionCreate, "Secret", javaKeystoreSecretName, namespace) {
+				if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
+					return fmt.Errorf("failed to create javakeystore secret: %+v", err)
+				}
 			}
 		}
 
 		// Expose Services for Alert
-		err = alert.CRUDServiceOrRoute(restconfig, kubeClient, namespace, alertName, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
-		if err != nil {
+		if !skipForPlan(util.PlanActionApply, "Service", fmt.Sprintf("%s-exposed", alertName), namespace) {
+			err = alert.CRUDServiceOrRoute(restconfig, kubeClient, namespace, alertName, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
+			if err != nil {
+				return err
+			}
+		}
+		if err := applyExposureIngress(util.AlertName, alertName, namespace, helmValuesMap); err != nil {
 			return err
 		}
 
@@ -178,7 +223,17 @@ var createAlertCmd = &cobra.Command{
 		err = util.CreateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath, false)
 		if err != nil {
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg))
+		}
+
+		if pocMode {
+			if err := labelPOCInstance(util.AlertName, alertName, namespace); err != nil {
+				return err
+			}
+		}
+
+		if err := waitForInstanceReady(util.AlertName, alertName, namespace); err != nil {
+			return err
 		}
 
 		log.Infof("Alert has been successfully Created!")
@@ -197,7 +252,7 @@ var createAlertNativeCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -218,6 +273,9 @@ var createAlertNativeCmd = &cobra.Command{
 		helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
 
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createAlertCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createAlertCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -245,6 +303,7 @@ var createAlertNativeCmd = &cobra.Command{
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.AlertVersion)
 
 		// Get secrets for Alert
+		secrets := []interface{}{}
 		certificateFlag := cmd.Flag("certificate-file-path")
 		certificateKeyFlag := cmd.Flag("certificate-key-file-path")
 		if certificateFlag.Changed && certificateKeyFlag.Changed {
@@ -257,35 +316,46 @@ var createAlertNativeCmd = &cobra.Command{
 			if err != nil {
 				log.Fatalf("failed to read certificate file: %+v", err)
 			}
+			alertHostname, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"environs", "ALERT_HOSTNAME"}).(string)
+			if err := util.ValidateCertificateAndKey([]byte(certificateData), []byte(certificateKeyData), alertHostname); err != nil {
+				return fmt.Errorf("invalid Alert certificate: %+v", err)
+			}
 			customCertificateSecretName := "alert-custom-certificate"
 			customCertificateSecret := alert.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData)
 			util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
-			fmt.Printf("---\n")
-			if _, err = PrintComponent(customCertificateSecret, "YAML"); err != nil {
-				return err
-			}
+			secrets = append(secrets, customCertificateSecret)
+		}
+		certificate, err := configureCertificateIssuer(cmd.Flags(), util.AlertName, alertName, namespace, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			secrets = append(secrets, certificate)
+		}
+		ingress, err := alert.GetIngress(namespace, alertName, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if ingress != nil {
+			secrets = append(secrets, ingress)
 		}
 
-		javaKeystoreFlag := cmd.Flag("java-keystore-file-path")
-		if javaKeystoreFlag.Changed {
-			javaKeystoreData, err := util.ReadFileData(javaKeystoreFlag.Value.String())
-			if err != nil {
-				log.Fatalf("failed to read Java Keystore file: %+v", err)
-			}
+		javaKeystoreData, ok, err := alert.GetJavaKeystoreDataFromFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		if ok {
 			javaKeystoreSecretName := "alert-java-keystore"
 			javaKeystoreSecret := alert.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData)
 			util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
-			fmt.Printf("---\n")
-			if _, err = PrintComponent(javaKeystoreSecret, "YAML"); err != nil {
-				return err
-			}
+			secrets = append(secrets, javaKeystoreSecret)
 		}
 
-		// Deploy Alert Resources
-		err = util.TemplateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap)
+		// Print Alert Resources
+		err = printNativeResources(secrets, helmReleaseName, globals.AlertChartRepository, helmValuesMap)
 		if err != nil {
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg))
 		}
 
 		return nil
@@ -299,19 +369,30 @@ Create Black Duck Commands
 // createBlackDuckCmd creates a Black Duck instance
 var createBlackDuckCmd = &cobra.Command{
 	Use:           "blackduck NAME -n NAMESPACE",
-	Example:       "synopsysctl create blackduck <name> -n <namespace>",
+	Example:       "synopsysctl create blackduck <name> -n <namespace>\nsynopsysctl create blackduck --interactive",
 	Short:         "Create a Black Duck instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
+		// The wizard asks for the name and the namespace
+		if interactiveCreate {
+			return allowInteractiveArgs(cmd, args)
+		}
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// Ask for the flags that were not set
+		if interactiveCreate {
+			if err := runBlackDuckWizard(cmd, args, wizardPrompter); err != nil {
+				return err
+			}
+		}
+
 		// Set the Global BlackDuckVersion
 		if cmd.Flags().Lookup("version").Changed {
 			globals.BlackDuckVersion = cmd.Flags().Lookup("version").Value.String()
@@ -334,9 +415,11 @@ var createBlackDuckCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return nil
+		return checkStoragePerformanceFlag()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		args = getWizardArgs(args)
+
 		// Set the Helm Chart Location
 		newChartVersion := "" // pass empty to UpdateHelmChartLocation if the default version should be used
 		if cmd.Flags().Lookup("version").Changed {
@@ -348,6 +431,9 @@ var createBlackDuckCmd = &cobra.Command{
 		}
 
 		// Create Helm Chart Values
+		if err := setValuesFromFiles(&createBlackDuckCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createBlackDuckCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -361,19 +447,50 @@ var createBlackDuckCmd = &cobra.Command{
 			util.SetHelmValueInMap(helmValuesMap, []string{"isKubernetes"}, false)
 		}
 
+		// Set the minimal footprint of a trial instance before the defaults, which would override it
+		if pocMode {
+			if err := setPOCHelmValues(util.BlackDuckName, helmValuesMap); err != nil {
+				return err
+			}
+		}
+
 		// Set Helm Chart Value - Persistent Storage to true by default (TODO: remove after changed in Helm Chart)
-		if !cmd.Flag("persistent-storage").Changed {
+		if _, ok := helmValuesMap["enablePersistentStorage"]; !ok && !cmd.Flag("persistent-storage").Changed {
 			util.SetHelmValueInMap(helmValuesMap, []string{"enablePersistentStorage"}, true)
 		}
 
 		// Set Helm Chart Value - size
 		var extraFiles []string
-		if !cmd.Flags().Lookup("size").Changed {
+		if _, ok := helmValuesMap["size"]; !ok && !cmd.Flags().Lookup("size").Changed {
 			helmValuesMap["size"] = "small"
 		}
-		size, found := helmValuesMap["size"]
-		if found && len(size.(string)) > 0 {
-			extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", strings.ToLower(size.(string))))
+
+		// Show the values of the wizard before installing them
+		if interactiveCreate {
+			install, err := reviewBlackDuckWizardValues(cmd.Flags(), wizardPrompter, args[0], helmValuesMap)
+			if err != nil || !install {
+				return err
+			}
+		}
+
+		size, _ := helmValuesMap["size"].(string)
+		sizeFile, sizeValues, err := getBlackDuckSizeValues(cmd.Flags(), namespace, size)
+		if err != nil {
+			return err
+		}
+		if len(sizeFile) > 0 {
+			extraFiles = append(extraFiles, sizeFile)
+		}
+		helmValuesMap = util.MergeMaps(sizeValues, helmValuesMap)
+
+		// Use the seeded credentials instead of generated ones
+		if err := applySeedSecretsFile(util.BlackDuckName, args[0], helmValuesMap); err != nil {
+			return err
+		}
+
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
 		}
 
 		// Create initial resources
@@ -382,27 +499,74 @@ var createBlackDuckCmd = &cobra.Command{
 			return err
 		}
 		for _, v := range secrets {
+			if skipForPlan(util.PlanActionCreate, "Secret", v.Name, namespace) {
+				continue
+			}
 			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&v); err != nil && !k8serrors.IsAlreadyExists(err) {
 				return fmt.Errorf("failed to create certifacte secret: %+v", err)
 			}
 		}
+		certificate, err := configureCertificateIssuer(cmd.Flags(), util.BlackDuckName, args[0], namespace, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if err := applyCertificateIssuer(certificate); err != nil {
+			return err
+		}
+		if err := verifyBlackDuckReportingDatabase(cmd.Flags(), args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap); err != nil {
+			return err
+		}
+		if err := verifyBlackDuckExternalPostgres(args[0], namespace, helmValuesMap); err != nil {
+			return err
+		}
+		if len(cloneDBFrom) > 0 {
+			if err := verifyCloneSource(cloneDBFrom, globals.BlackDuckVersion); err != nil {
+				return err
+			}
+		}
+		if err := verifyBlackDuckStorageAccess(args[0], namespace, helmValuesMap); err != nil {
+			return err
+		}
+		if err := verifyBlackDuckStoragePerformance(args[0], namespace, helmValuesMap); err != nil {
+			return err
+		}
 
 		// Check Dry Run before deploying any resources
 		err = util.CreateWithHelm3(args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, true, extraFiles...)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return fmt.Errorf("failed to create Blackduck resources: %w", err)
 		}
 
 		// Deploy Resources
+		planServiceOrRoute(util.GetResourceName(args[0], util.BlackDuckName, "webserver-exposed"), util.GetResourceName(args[0], util.BlackDuckName, ""), namespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
 		err = util.CreateWithHelm3(args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, false, extraFiles...)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return fmt.Errorf("failed to create Blackduck resources: %w", err)
 		}
 
 		err = blackduck.CRUDServiceOrRoute(restconfig, kubeClient, namespace, args[0], helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
 		if err != nil {
 			return err
 		}
+		if err := applyExposureIngress(util.BlackDuckName, args[0], namespace, helmValuesMap); err != nil {
+			return err
+		}
+
+		if len(cloneDBFrom) > 0 {
+			if err := cloneBlackDuckDatabases(cloneDBFrom, args[0], namespace); err != nil {
+				return err
+			}
+		}
+
+		if pocMode {
+			if err := labelPOCInstance(util.BlackDuckName, args[0], namespace); err != nil {
+				return err
+			}
+		}
+
+		if err := waitForInstanceReady(util.BlackDuckName, args[0], namespace); err != nil {
+			return err
+		}
 
 		log.Infof("Black Duck has been successfully Created!")
 		return nil
@@ -420,7 +584,7 @@ var createBlackDuckNativeCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -461,6 +625,9 @@ var createBlackDuckNativeCmd = &cobra.Command{
 		}
 
 		// Create Helm Chart Values
+		if err := setValuesFromFiles(&createBlackDuckCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createBlackDuckCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -481,18 +648,28 @@ var createBlackDuckNativeCmd = &cobra.Command{
 		}
 
 		// Set Helm Chart Value - Persistent Storage to true by default (TODO: remove after changed in Helm Chart)
-		if !cmd.Flag("persistent-storage").Changed {
+		if _, ok := helmValuesMap["enablePersistentStorage"]; !ok && !cmd.Flag("persistent-storage").Changed {
 			util.SetHelmValueInMap(helmValuesMap, []string{"enablePersistentStorage"}, true)
 		}
 
 		// Set Helm Chart Value - size
 		var extraFiles []string
-		if !cmd.Flags().Lookup("size").Changed {
+		if _, ok := helmValuesMap["size"]; !ok && !cmd.Flags().Lookup("size").Changed {
 			helmValuesMap["size"] = "small"
 		}
-		size, found := helmValuesMap["size"]
-		if found && len(size.(string)) > 0 {
-			extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", strings.ToLower(size.(string))))
+		size, _ := helmValuesMap["size"].(string)
+		sizeFile, sizeValues, err := getBlackDuckSizeValues(cmd.Flags(), namespace, size)
+		if err != nil {
+			return err
+		}
+		if len(sizeFile) > 0 {
+			extraFiles = append(extraFiles, sizeFile)
+		}
+		helmValuesMap = util.MergeMaps(sizeValues, helmValuesMap)
+
+		// Use the seeded credentials instead of generated ones
+		if err := applySeedSecretsFile(util.BlackDuckName, args[0], helmValuesMap); err != nil {
+			return err
 		}
 
 		// Create initial resources
@@ -500,15 +677,34 @@ var createBlackDuckNativeCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		for _, v := range secrets {
-			fmt.Printf("---\n")
-			PrintComponent(v, "YAML") // helm only supports yaml
+
+		// Reuse the generated credentials of an existing instance
+		if err := resolveRenderContextLookups(namespace, bdutil.DBPasswordRenderLookups(args[0]), helmValuesMap); err != nil {
+			return err
 		}
 
 		// Print the resources
-		err = util.TemplateWithHelm3(args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap, extraFiles...)
+		objects := []interface{}{}
+		for _, secret := range secrets {
+			objects = append(objects, secret)
+		}
+		certificate, err := configureCertificateIssuer(cmd.Flags(), util.BlackDuckName, args[0], namespace, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			objects = append(objects, certificate)
+		}
+		ingress, err := blackduck.GetIngress(namespace, args[0], helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return err
+		}
+		if ingress != nil {
+			objects = append(objects, ingress)
+		}
+		err = printNativeResources(objects, args[0], globals.BlackDuckChartRepository, helmValuesMap, extraFiles...)
+		if err != nil {
+			return fmt.Errorf("failed to create Blackduck resources: %w", err)
 		}
 
 		return nil
@@ -530,7 +726,7 @@ var createOpsSightCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -538,6 +734,9 @@ var createOpsSightCmd = &cobra.Command{
 		opssightName := args[0]
 
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createOpsSightCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createOpsSightCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -557,16 +756,25 @@ var createOpsSightCmd = &cobra.Command{
 		// Set the version in the Values
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.OpsSightVersion)
 
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
+		}
+
 		// Check Dry Run before deploying any resources
 		err = util.CreateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath, true)
 		if err != nil {
-			return fmt.Errorf("failed to create OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to create OpsSight resources: %w", err)
 		}
 
 		// Deploy OpsSight Resources
 		err = util.CreateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath, false)
 		if err != nil {
-			return fmt.Errorf("failed to create OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to create OpsSight resources: %w", err)
+		}
+
+		if err := waitForInstanceReady(util.OpsSightName, opssightName, namespace); err != nil {
+			return err
 		}
 
 		log.Infof("OpsSight has been successfully Created!")
@@ -585,7 +793,7 @@ var createOpsSightNativeCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -593,6 +801,9 @@ var createOpsSightNativeCmd = &cobra.Command{
 		opssightName := args[0]
 
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createOpsSightCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createOpsSightCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -613,9 +824,9 @@ var createOpsSightNativeCmd = &cobra.Command{
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.OpsSightVersion)
 
 		// Print OpsSight Resources
-		err = util.TemplateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap)
+		err = printNativeResources(nil, opssightName, globals.OpsSightChartRepository, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to generate OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to generate OpsSight resources: %w", err)
 		}
 
 		return nil
@@ -633,12 +844,15 @@ var createBDBACmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 0 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createBDBACobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createBDBACobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -658,16 +872,36 @@ var createBDBACmd = &cobra.Command{
 		// Set the version in the Values
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.BDBAVersion)
 
+		if bucket := createBDBACobraHelper.GetExternalS3Bucket(); bucket != nil && !skipS3Validation {
+			if err := util.CheckS3Bucket(*bucket); err != nil {
+				return fmt.Errorf("%+v, use --skip-s3-validation to create BDBA anyway", err)
+			}
+		}
+
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
+		}
+
 		// Check Dry Run before deploying any resources
 		err = util.CreateWithHelm3(globals.BDBAName, namespace, globals.BDBAChartRepository, helmValuesMap, kubeConfigPath, true)
 		if err != nil {
-			return fmt.Errorf("failed to create BDBA resources: %+v", err)
+			return fmt.Errorf("failed to create BDBA resources: %w", err)
 		}
 
 		// Deploy Resources
+		planServiceOrRoute(bdba.GetExposedResourceName(globals.BDBAName), bdba.GetExposedResourceName(globals.BDBAName), namespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
 		err = util.CreateWithHelm3(globals.BDBAName, namespace, globals.BDBAChartRepository, helmValuesMap, kubeConfigPath, false)
 		if err != nil {
-			return fmt.Errorf("failed to create BDBA resources: %+v", err)
+			return fmt.Errorf("failed to create BDBA resources: %w", err)
+		}
+
+		if err := bdba.CRUDServiceOrRoute(restconfig, kubeClient, namespace, globals.BDBAName, helmValuesMap); err != nil {
+			return err
+		}
+
+		if err := waitForInstanceReady(globals.BDBAName, globals.BDBAName, namespace); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Created!")
@@ -686,12 +920,15 @@ var createBDBANativeCmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 0 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createBDBACobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := createBDBACobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -712,33 +949,233 @@ var createBDBANativeCmd = &cobra.Command{
 		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.BDBAVersion)
 
 		// Print Resources
-		err = util.TemplateWithHelm3(globals.BDBAName, namespace, globals.BDBAChartRepository, helmValuesMap)
+		err = printNativeResources(nil, globals.BDBAName, globals.BDBAChartRepository, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to generate BDBA resources: %+v", err)
+			return fmt.Errorf("failed to generate BDBA resources: %w", err)
 		}
 
 		return nil
 	},
 }
 
+// createCoverityCmd creates a Coverity instance
+var createCoverityCmd = &cobra.Command{
+	Use:           "coverity NAME -n NAMESPACE",
+	Example:       "synopsysctl create coverity <name> -n <namespace> --license-file-path <path> --postgres-host <host> --postgres-password <password>",
+	Short:         "Create a Coverity instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coverityName := args[0]
+
+		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createCoverityCobraHelper); err != nil {
+			return err
+		}
+		helmValuesMap, err := createCoverityCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// Update the Helm Chart Location
+		newChartVersion := "" // pass empty to UpdateHelmChartLocation if the default version should be used
+		if cmd.Flags().Lookup("version").Changed {
+			globals.CoverityVersion = cmd.Flags().Lookup("version").Value.String()
+			newChartVersion = globals.CoverityVersion
+		}
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.CoverityChartName, newChartVersion, &globals.CoverityChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		// Set the version in the Values
+		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.CoverityVersion)
+
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
+		}
+
+		// Check Dry Run before deploying any resources
+		err = util.CreateWithHelm3(coverityName, namespace, globals.CoverityChartRepository, helmValuesMap, kubeConfigPath, true)
+		if err != nil {
+			return fmt.Errorf("failed to create Coverity resources: %w", err)
+		}
+
+		// Deploy Resources
+		err = util.CreateWithHelm3(coverityName, namespace, globals.CoverityChartRepository, helmValuesMap, kubeConfigPath, false)
+		if err != nil {
+			return fmt.Errorf("failed to create Coverity resources: %w", err)
+		}
+
+		log.Infof("Coverity has been successfully Created!")
+		return nil
+	},
+}
+
+// createCoverityNativeCmd prints Coverity resources
+var createCoverityNativeCmd = &cobra.Command{
+	Use:           "native NAME -n NAMESPACE",
+	Example:       "synopsysctl create coverity native <name> -n <namespace>",
+	Short:         "Print Kubernetes resources for creating a Coverity instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coverityName := args[0]
+
+		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createCoverityCobraHelper); err != nil {
+			return err
+		}
+		helmValuesMap, err := createCoverityCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// Update the Helm Chart Location
+		newChartVersion := "" // pass empty to UpdateHelmChartLocation if the default version should be used
+		if cmd.Flags().Lookup("version").Changed {
+			globals.CoverityVersion = cmd.Flags().Lookup("version").Value.String()
+			newChartVersion = globals.CoverityVersion
+		}
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.CoverityChartName, newChartVersion, &globals.CoverityChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		// Set the version in the Values
+		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.CoverityVersion)
+
+		// Print Resources
+		err = printNativeResources(nil, coverityName, globals.CoverityChartRepository, helmValuesMap)
+		if err != nil {
+			return fmt.Errorf("failed to generate Coverity resources: %w", err)
+		}
+
+		return nil
+	},
+}
+
+// createPolarisCmd creates a Polaris instance
+var createPolarisCmd = &cobra.Command{
+	Use:           "polaris NAME -n NAMESPACE",
+	Example:       "synopsysctl create polaris <name> -n <namespace> --fqdn <fqdn> --license-file-path <path> --smtp-host <host> --organization-name <name> --organization-admin-name <name> --organization-admin-email <email>\nsynopsysctl create polaris <name> -n <namespace> --fqdn <fqdn> --license-file-path <path> --smtp-host <host> --organization-name <name> --organization-admin-name <name> --organization-admin-email <email> --enable-reporting",
+	Short:         "Create a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// Set the Global PolarisVersion
+		if cmd.Flags().Lookup("version").Changed {
+			globals.PolarisVersion = cmd.Flags().Lookup("version").Value.String()
+		}
+
+		// Verify synopsysctl supports the version
+		if util.CompareVersions(globals.PolarisVersion, polaris.MinVersion) < 0 {
+			return fmt.Errorf("creation of Polaris instance is only supported for version %s and above", polaris.MinVersion)
+		}
+		return createPolarisCobraHelper.VerifyChartVersionSupportsChangedFlags(cmd.Flags(), globals.PolarisVersion)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		polarisName := args[0]
+
+		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&createPolarisCobraHelper); err != nil {
+			return err
+		}
+		helmValuesMap, err := createPolarisCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// Update the Helm Chart Location
+		newChartVersion := "" // pass empty to UpdateHelmChartLocation if the default version should be used
+		if cmd.Flags().Lookup("version").Changed {
+			newChartVersion = globals.PolarisVersion // note: globals.PolarisVersion is set in PreRunE
+		}
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.PolarisChartName, newChartVersion, &globals.PolarisChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		// Set the version in the Values
+		util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.PolarisVersion)
+
+		// Create the namespace as an OpenShift project
+		if err := ensureOpenShiftProject(namespace); err != nil {
+			return err
+		}
+
+		// Check Dry Run before deploying any resources
+		err = util.CreateWithHelm3(polarisName, namespace, globals.PolarisChartRepository, helmValuesMap, kubeConfigPath, true)
+		if err != nil {
+			return fmt.Errorf("failed to create Polaris resources: %w", err)
+		}
+
+		// Deploy Resources
+		planServiceOrRoute(polaris.GetExposedResourceName(polarisName), polaris.GetExposedResourceName(polarisName), namespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
+		err = util.CreateWithHelm3(polarisName, namespace, globals.PolarisChartRepository, helmValuesMap, kubeConfigPath, false)
+		if err != nil {
+			return fmt.Errorf("failed to create Polaris resources: %w", err)
+		}
+
+		if err := polaris.CRUDServiceOrRoute(restconfig, kubeClient, namespace, polarisName, helmValuesMap); err != nil {
+			return err
+		}
+
+		log.Infof("Polaris has been successfully Created!")
+		return nil
+	},
+}
+
 func init() {
 	// initialize global resource ctl structs for commands to use
 	createBlackDuckCobraHelper = *blackduck.NewHelmValuesFromCobraFlags()
 	createAlertCobraHelper = *alertctl.NewHelmValuesFromCobraFlags()
 	createOpsSightCobraHelper = *opssight.NewHelmValuesFromCobraFlags()
 	createBDBACobraHelper = *bdba.NewHelmValuesFromCobraFlags()
+	createCoverityCobraHelper = *coverity.NewHelmValuesFromCobraFlags()
+	createPolarisCobraHelper = *polaris.NewHelmValuesFromCobraFlags()
 
 	rootCmd.AddCommand(createCmd)
+	addPlanFlags(createCmd)
+	addDryRunFlag(createCmd)
 
 	// Add Alert Command
 	createAlertCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(createAlertCmd.PersistentFlags(), "namespace")
 	createAlertCobraHelper.AddCobraFlagsToCommand(createAlertCmd, true)
+	addValuesFileFlag(createAlertCmd)
+	addOpenShiftProjectFlags(createAlertCmd)
+	addWaitFlags(createAlertCmd)
 	addChartLocationPathFlag(createAlertCmd)
+	addPOCFlags(createAlertCmd)
 	createCmd.AddCommand(createAlertCmd)
 
 	createAlertCobraHelper.AddCobraFlagsToCommand(createAlertNativeCmd, true)
+	addValuesFileFlag(createAlertNativeCmd)
 	addChartLocationPathFlag(createAlertNativeCmd)
+	addNativeOutputFlags(createAlertNativeCmd)
 	createAlertCmd.AddCommand(createAlertNativeCmd)
 
 	// Add Black Duck Command
@@ -746,11 +1183,27 @@ func init() {
 	cobra.MarkFlagRequired(createBlackDuckCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(createBlackDuckCmd)
 	createBlackDuckCobraHelper.AddCobraFlagsToCommand(createBlackDuckCmd, true)
+	addValuesFileFlag(createBlackDuckCmd)
+	addOpenShiftProjectFlags(createBlackDuckCmd)
+	addWaitFlags(createBlackDuckCmd)
+	addSeedSecretsFlag(createBlackDuckCmd)
+	createBlackDuckCmd.Flags().BoolVar(&skipReportingDatabaseValidation, "skip-reporting-postgres-validation", skipReportingDatabaseValidation, "If true, do not check that the reporting Postgres is a reachable read-only replica")
+	createBlackDuckCmd.Flags().BoolVar(&skipExternalPostgresValidation, "skip-external-postgres-validation", skipExternalPostgresValidation, "If true, do not check that the external Postgres is reachable and has the databases and extensions of Black Duck")
+	createBlackDuckCmd.Flags().StringVar(&cloneDBFrom, "clone-db-from", cloneDBFrom, "NAMESPACE/NAME of a Black Duck instance whose databases are cloned into the new instance")
+	createBlackDuckCmd.Flags().DurationVar(&cloneDBTimeout, "clone-db-timeout", cloneDBTimeout, "Maximum time to wait for the databases to be cloned")
+	createBlackDuckCmd.Flags().StringVar(&verifyStoragePerformance, "verify-storage-performance", verifyStoragePerformance, "If set, benchmark a volume of the storage class before creating the instance and warn or fail if it is slower than the minimum requirements of Postgres [warn|fail]")
+	createBlackDuckCmd.Flags().BoolVar(&verifyStorageAccess, "verify-storage-access", verifyStorageAccess, "If true, run a job with the security context of Postgres that writes to a volume of the storage class before creating the instance")
+	addPOCFlags(createBlackDuckCmd)
+	addInteractiveFlag(createBlackDuckCmd)
+	setVersionAwareHelp(createBlackDuckCmd, blackduck.GetFlagsUnsupportedByVersion)
 	createCmd.AddCommand(createBlackDuckCmd)
 
 	createBlackDuckCobraHelper.AddCobraFlagsToCommand(createBlackDuckNativeCmd, true)
+	addValuesFileFlag(createBlackDuckNativeCmd)
 	addNativeFlags(createBlackDuckNativeCmd)
+	addSeedSecretsFlag(createBlackDuckNativeCmd)
 	addChartLocationPathFlag(createBlackDuckNativeCmd)
+	addNativeOutputFlags(createBlackDuckNativeCmd)
 	createBlackDuckCmd.AddCommand(createBlackDuckNativeCmd)
 
 	// Add OpsSight Command
@@ -758,21 +1211,63 @@ func init() {
 	cobra.MarkFlagRequired(createOpsSightCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(createOpsSightCmd)
 	createOpsSightCobraHelper.AddCobraFlagsToCommand(createOpsSightCmd, true)
+	addValuesFileFlag(createOpsSightCmd)
+	addOpenShiftProjectFlags(createOpsSightCmd)
+	addWaitFlags(createOpsSightCmd)
 	createCmd.AddCommand(createOpsSightCmd)
 
 	createOpsSightCobraHelper.AddCobraFlagsToCommand(createOpsSightNativeCmd, true)
+	addValuesFileFlag(createOpsSightNativeCmd)
 	addChartLocationPathFlag(createOpsSightNativeCmd)
+	addNativeOutputFlags(createOpsSightNativeCmd)
 	createOpsSightCmd.AddCommand(createOpsSightNativeCmd)
 
 	// Add BDBA commands
 	createBDBACmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(createBDBACmd.PersistentFlags(), "namespace")
 	createBDBACobraHelper.AddCobraFlagsToCommand(createBDBACmd, true)
+	addValuesFileFlag(createBDBACmd)
+	addOpenShiftProjectFlags(createBDBACmd)
+	addWaitFlags(createBDBACmd)
+	createBDBACmd.Flags().BoolVar(&skipS3Validation, "skip-s3-validation", skipS3Validation, "If true, do not check that the external S3 bucket exists and is accessible")
 	addChartLocationPathFlag(createBDBACmd)
 	createCmd.AddCommand(createBDBACmd)
 
 	createBDBACobraHelper.AddCobraFlagsToCommand(createBDBANativeCmd, true)
+	addValuesFileFlag(createBDBANativeCmd)
 	addChartLocationPathFlag(createBDBANativeCmd)
+	addNativeOutputFlags(createBDBANativeCmd)
 	createBDBACmd.AddCommand(createBDBANativeCmd)
 
+	// Add Coverity commands
+	createCoverityCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(createCoverityCmd.PersistentFlags(), "namespace")
+	createCoverityCobraHelper.AddCobraFlagsToCommand(createCoverityCmd, true)
+	addValuesFileFlag(createCoverityCmd)
+	addOpenShiftProjectFlags(createCoverityCmd)
+	addChartLocationPathFlag(createCoverityCmd)
+	for _, name := range []string{"license-file-path", "postgres-host", "postgres-password"} {
+		cobra.MarkFlagRequired(createCoverityCmd.Flags(), name)
+	}
+	createCmd.AddCommand(createCoverityCmd)
+
+	createCoverityCobraHelper.AddCobraFlagsToCommand(createCoverityNativeCmd, true)
+	addValuesFileFlag(createCoverityNativeCmd)
+	addChartLocationPathFlag(createCoverityNativeCmd)
+	addNativeOutputFlags(createCoverityNativeCmd)
+	createCoverityCmd.AddCommand(createCoverityNativeCmd)
+
+	// Add Polaris commands
+	createPolarisCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(createPolarisCmd.PersistentFlags(), "namespace")
+	createPolarisCobraHelper.AddCobraFlagsToCommand(createPolarisCmd, true)
+	addValuesFileFlag(createPolarisCmd)
+	addOpenShiftProjectFlags(createPolarisCmd)
+	addChartLocationPathFlag(createPolarisCmd)
+	for _, name := range []string{"fqdn", "license-file-path", "smtp-host", "organization-name", "organization-admin-name", "organization-admin-email"} {
+		cobra.MarkFlagRequired(createPolarisCmd.Flags(), name)
+	}
+	setVersionAwareHelp(createPolarisCmd, polaris.GetFlagsUnsupportedByVersion)
+	createCmd.AddCommand(createPolarisCmd)
+
 }
@@ -23,21 +23,32 @@ package synopsysctl
 
 import (
 	"fmt"
-	"strings"
+	"time"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/polaris"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+// deleteInstanceNamespace deletes the namespace of the instance as well
+var deleteInstanceNamespace = false
+
+// deleteNamespaceTimeout is the maximum time to wait for the namespace of the instance to terminate
+var deleteNamespaceTimeout = 5 * time.Minute
+
 // deleteCmd deletes a resource from the cluster
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Remove Synopsys resources from your cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -51,58 +62,23 @@ var deleteAlertCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", len(args))
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": len(args)})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		alertName := args[0]
-		helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
-
-		// Delete the Secrets
-		helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
-		if err != nil {
-			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to get Alert values: %+v", cleanErrorMsg)
+		if deleteReportOnly {
+			return reportDeleteAlert(cmd, args[0], namespace)
 		}
-
-		var name interface{}
-		var ok bool
-		if name, ok = helmRelease.Config["webserverCustomCertificatesSecretName"]; ok {
-			if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil {
-				return fmt.Errorf("failed to delete Alert custom certiface secret: %+v", err)
-			}
-		}
-		if name, ok = helmRelease.Config["javaKeystoreSecretName"]; ok {
-			if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil {
-				return fmt.Errorf("failed to delete Alert javaKeystore secret: %+v", err)
-			}
-		}
-
-		// Delete Alert Resources
-		err = util.DeleteWithHelm3(helmReleaseName, namespace, kubeConfigPath)
-		if err != nil {
-			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to delete Alert resources: %+v", cleanErrorMsg)
-		}
-
-		labelSelector := fmt.Sprintf("app=%s, name=%s", util.AlertName, alertName)
-		svcs, err := util.ListServices(kubeClient, namespace, labelSelector)
-		if err != nil {
-			return fmt.Errorf("failed to list services: %+v", err)
+		if err := confirmDestructiveAction(deleteDescription(fmt.Sprintf("Alert '%s'", args[0]), namespace)); err != nil {
+			return err
 		}
-		for _, svc := range svcs.Items {
-			if strings.HasSuffix(svc.Name, "-exposed") {
-				if err := util.DeleteService(kubeClient, namespace, svc.Name); !k8serrors.IsNotFound(err) {
-					return fmt.Errorf("failed to delete the Alert exposed service: %+v", err)
-				}
-			}
+		if err := deleteAlert(args[0], namespace); err != nil {
+			return err
 		}
-
-		if err = deletePVCs(namespace, labelSelector); err != nil {
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
 			return err
 		}
-
 		log.Infof("Alert has been successfully Deleted!")
 		return nil
 	},
@@ -111,63 +87,30 @@ var deleteAlertCmd = &cobra.Command{
 // deleteBlackDuckCmd deletes Black Duck instances from the cluster
 var deleteBlackDuckCmd = &cobra.Command{
 	Use:           "blackduck NAME -n NAMESPACE",
-	Example:       "synopsysctl delete blackduck <name> -n <namespace>",
+	Example:       "synopsysctl delete blackduck <name> -n <namespace>\nsynopsysctl delete blackduck <name> -n <namespace> --delete-namespace --report-only --report-format yaml --report-output-file delete-report.yaml",
 	Short:         "Delete a Black Duck instances",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := util.DeleteWithHelm3(args[0], namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to delete Blackduck resources: %+v", err)
+		if deleteReportOnly {
+			return reportDeleteBlackDuck(cmd, args[0], namespace)
 		}
-
-		// delete secret
-		secrets := []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"}
-		for _, v := range secrets {
-			if err := util.DeleteSecret(kubeClient, namespace, fmt.Sprintf("%s-%s-%s", args[0], util.BlackDuckName, v)); err != nil && !k8serrors.IsNotFound(err) {
-				return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", v, namespace, err)
-			}
-		}
-
-		labelSelector := fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, args[0])
-		// delete exposed service
-		svcs, err := util.ListServices(kubeClient, namespace, labelSelector)
-		if err != nil {
-			return fmt.Errorf("couldn't list services in namespace '%s' due to %+v", namespace, err)
-		}
-		for _, svc := range svcs.Items {
-			if strings.HasSuffix(svc.Name, "-exposed") {
-				if err := util.DeleteService(kubeClient, namespace, svc.Name); err != nil && !k8serrors.IsNotFound(err) {
-					return fmt.Errorf("couldn't delete service '%s' in namespace '%s' due to %+v", svc.Name, namespace, err)
-				}
-			}
+		if err := confirmDestructiveAction(deleteDescription(fmt.Sprintf("Black Duck '%s'", args[0]), namespace)); err != nil {
+			return err
 		}
-
-		// delete PVC
-		if err = deletePVCs(namespace, labelSelector); err != nil {
+		if err := deleteBlackDuck(args[0], namespace); err != nil {
 			return err
 		}
-
-		// delete route
-		isOpenShift := util.IsOpenshift(kubeClient)
-		if isOpenShift {
-			routeName := util.GetResourceName(args[0], util.BlackDuckName, "")
-			routeClient := util.GetRouteClient(restconfig, kubeClient, namespace)
-			if _, err = util.GetRoute(routeClient, namespace, routeName); err == nil {
-				err = util.DeleteRoute(routeClient, namespace, routeName)
-				if err != nil {
-					return fmt.Errorf("unable to delete Black Duck webserver route due to %+v", err)
-				}
-			}
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
+			return err
 		}
-
 		log.Infof("Black Duck has been successfully Deleted!")
 		return nil
 	},
@@ -183,18 +126,27 @@ var deleteOpsSightCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument but got %+v", len(args))
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": len(args)})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opssightName := args[0]
+		if deleteReportOnly {
+			return reportDeleteRelease(cmd, opssightName, namespace, nil)
+		}
+		if err := confirmDestructiveAction(deleteDescription(fmt.Sprintf("OpsSight '%s'", opssightName), namespace)); err != nil {
+			return err
+		}
 		// TODO Delete any initial resources...
 
 		// Delete Opssight Resources
 		err := util.DeleteWithHelm3(opssightName, namespace, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to delete OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to delete OpsSight resources: %w", err)
+		}
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
+			return err
 		}
 
 		log.Infof("OpsSight has been successfully Deleted!")
@@ -202,6 +154,93 @@ var deleteOpsSightCmd = &cobra.Command{
 	},
 }
 
+// deleteAlert deletes the Alert instance and the resources synopsysctl created for it
+func deleteAlert(alertName string, namespace string) error {
+	helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
+	if err := util.CheckReleaseNotFrozen(kubeClient, namespace, helmReleaseName); err != nil {
+		return err
+	}
+
+	// Delete the Secrets
+	helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
+	if err != nil {
+		cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+		return fmt.Errorf("failed to get Alert values: %+v", cleanErrorMsg)
+	}
+
+	if err := deleteCertificateIssuer(namespace, helmRelease.Config); err != nil {
+		return err
+	}
+	var name interface{}
+	var ok bool
+	if name, ok = helmRelease.Config["webserverCustomCertificatesSecretName"]; ok && !skipForPlan(util.PlanActionDelete, "Secret", name.(string), namespace) {
+		if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Alert custom certiface secret: %+v", err)
+		}
+	}
+	if name, ok = helmRelease.Config["javaKeystoreSecretName"]; ok && !skipForPlan(util.PlanActionDelete, "Secret", name.(string), namespace) {
+		if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil {
+			return fmt.Errorf("failed to delete Alert javaKeystore secret: %+v", err)
+		}
+	}
+
+	// Delete Alert Resources
+	err = util.DeleteWithHelm3(helmReleaseName, namespace, kubeConfigPath)
+	if err != nil {
+		cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+		return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to delete Alert resources: %+v", cleanErrorMsg))
+	}
+
+	if err := util.DeleteExposure(restconfig, kubeClient, alert.GetExposure(namespace, alertName)); err != nil {
+		return err
+	}
+
+	labelSelector := fmt.Sprintf("app=%s, name=%s", util.AlertName, alertName)
+
+	if err = deletePVCs(namespace, labelSelector); err != nil {
+		return err
+	}
+	return nil
+}
+
+// deleteBlackDuck deletes the Black Duck instance and the resources synopsysctl created for it
+func deleteBlackDuck(name string, namespace string) error {
+	// the release keeps the issuer of the webserver certificate
+	helmValues := map[string]interface{}{}
+	if helmRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath); err == nil {
+		helmValues = helmRelease.Config
+	}
+
+	err := util.DeleteWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to delete Blackduck resources: %w", err)
+	}
+
+	if err := deleteCertificateIssuer(namespace, helmValues); err != nil {
+		return err
+	}
+
+	// delete secret
+	secrets := []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"}
+	for _, v := range secrets {
+		if err := util.DeleteSecret(kubeClient, namespace, fmt.Sprintf("%s-%s-%s", name, util.BlackDuckName, v)); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", v, namespace, err)
+		}
+	}
+
+	// delete the PVCs and the resources of a legacy operator based instance, without deleting the other workloads
+	// and secrets in the namespace
+	if err := util.DeleteResourcesWithLabels(kubeClient, namespace, util.BlackDuckName, name); err != nil {
+		return err
+	}
+
+	// delete the exposed service and route, including those labeled with a previous name of the instance
+	if err := util.DeleteExposure(restconfig, kubeClient, blackduck.GetExposure(namespace, name)); err != nil {
+		return err
+	}
+	return nil
+}
+
 func deletePVCs(namespace string, labelSelector string) error {
 	// delete PVC's
 	pvcs, err := util.ListPVCs(kubeClient, namespace, labelSelector)
@@ -227,15 +266,27 @@ var deleteBDBACmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 0 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if deleteReportOnly {
+			return reportDeleteBDBA(cmd, namespace)
+		}
+		if err := confirmDestructiveAction(deleteDescription("BDBA", namespace)); err != nil {
+			return err
+		}
 		// Delete Resources
 		err := util.DeleteWithHelm3(globals.BDBAName, namespace, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to delete BDBA resources: %+v", err)
+			return fmt.Errorf("failed to delete BDBA resources: %w", err)
+		}
+		if err := bdba.DeleteServiceOrRoute(restconfig, kubeClient, namespace, globals.BDBAName); err != nil {
+			return err
+		}
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Deleted!")
@@ -243,10 +294,118 @@ var deleteBDBACmd = &cobra.Command{
 	},
 }
 
+// deleteCoverityCmd deletes a Coverity instance
+var deleteCoverityCmd = &cobra.Command{
+	Use:           "coverity NAME -n NAMESPACE",
+	Example:       "synopsysctl delete coverity <name> -n <namespace>",
+	Short:         "Delete a Coverity instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coverityName := args[0]
+		if deleteReportOnly {
+			return reportDeleteRelease(cmd, coverityName, namespace, nil)
+		}
+		if err := confirmDestructiveAction(deleteDescription(fmt.Sprintf("Coverity '%s'", coverityName), namespace)); err != nil {
+			return err
+		}
+		// Delete Resources
+		err := util.DeleteWithHelm3(coverityName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to delete Coverity resources: %w", err)
+		}
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
+			return err
+		}
+
+		log.Infof("Coverity has been successfully Deleted!")
+		return nil
+	},
+}
+
+// deletePolarisCmd deletes a Polaris instance
+var deletePolarisCmd = &cobra.Command{
+	Use:           "polaris NAME -n NAMESPACE",
+	Example:       "synopsysctl delete polaris <name> -n <namespace>",
+	Short:         "Delete a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		polarisName := args[0]
+		if deleteReportOnly {
+			e := polaris.GetExposure(namespace, polarisName)
+			return reportDeleteRelease(cmd, polarisName, namespace, &e)
+		}
+		if err := confirmDestructiveAction(deleteDescription(fmt.Sprintf("Polaris '%s'", polarisName), namespace)); err != nil {
+			return err
+		}
+		// Delete Resources
+		err := util.DeleteWithHelm3(polarisName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to delete Polaris resources: %w", err)
+		}
+		if err := polaris.DeleteServiceOrRoute(restconfig, kubeClient, namespace, polarisName); err != nil {
+			return err
+		}
+		if err := deleteNamespaceIfRequested(namespace); err != nil {
+			return err
+		}
+
+		log.Infof("Polaris has been successfully Deleted!")
+		return nil
+	},
+}
+
+// deleteDescription returns the description of the deletion of an instance for the confirmation prompt
+func deleteDescription(instance string, namespace string) string {
+	if deleteInstanceNamespace {
+		return fmt.Sprintf("this will delete %s and namespace '%s' with everything in it", instance, namespace)
+	}
+	return fmt.Sprintf("this will delete %s in namespace '%s'", instance, namespace)
+}
+
+// deleteNamespaceIfRequested deletes the namespace of the instance if --delete-namespace is set and waits until it
+// has terminated. An interrupt stops the wait but not the deletion of the namespace
+func deleteNamespaceIfRequested(namespace string) error {
+	if !deleteInstanceNamespace {
+		return nil
+	}
+	if err := util.DeleteNamespace(kubeClient, namespace); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("couldn't delete namespace '%s' due to %+v", namespace, err)
+	}
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+	log.Infof("waiting for namespace '%s' to terminate", namespace)
+	return util.WaitForNamespaceToTerminate(ctx, kubeClient, namespace, deleteNamespaceTimeout)
+}
+
 func init() {
 
 	//(PassCmd) deleteCmd.DisableFlagParsing = true // lets deleteCmd pass flags to kube/oc
 	rootCmd.AddCommand(deleteCmd)
+	addPlanFlags(deleteCmd)
+	addDryRunFlag(deleteCmd)
+	addConfirmationFlag(deleteCmd)
+	addDeleteReportFlags(deleteCmd)
+	deleteCmd.PersistentFlags().BoolVar(&deleteInstanceNamespace, "delete-namespace", deleteInstanceNamespace, "If true, delete the namespace of the instance and all other resources in it")
+	deleteCmd.PersistentFlags().DurationVar(&deleteNamespaceTimeout, "delete-namespace-timeout", deleteNamespaceTimeout, "Maximum time to wait for the namespace of --delete-namespace to terminate")
 
 	// Add Delete Alert Command
 	deleteAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
@@ -267,4 +426,14 @@ func init() {
 	deleteBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(deleteBDBACmd.Flags(), "namespace")
 	deleteCmd.AddCommand(deleteBDBACmd)
+
+	// Add Delete Coverity Command
+	deleteCoverityCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(deleteCoverityCmd.Flags(), "namespace")
+	deleteCmd.AddCommand(deleteCoverityCmd)
+
+	// Add Delete Polaris Command
+	deletePolarisCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(deletePolarisCmd.Flags(), "namespace")
+	deleteCmd.AddCommand(deletePolarisCmd)
 }
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Describe Command Options and Defaults
+var describeOutputFormat = "text"
+var describeAllValues = false
+
+// describeCmd prints the configuration of an instance
+var describeCmd = &cobra.Command{
+	Use:           "describe PRODUCT NAME -n NAMESPACE",
+	Example:       "synopsysctl describe blackduck <name> -n <namespace>\nsynopsysctl describe alert <name> -n <namespace> -o yaml",
+	Short:         "Describe the Helm release, values, secrets, endpoints and volumes of an Alert or Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(describeOutputFormat)
+		if format != "text" && format != "json" && format != "yaml" {
+			return util.ValidationError("output format must be 'text', 'json' or 'yaml', got '%s'", describeOutputFormat)
+		}
+		clients := getProductClients()
+		product, err := products.New(args[0], args[1], namespace, clients)
+		if err != nil {
+			return err
+		}
+		description, err := products.Describe(product, clients, describeAllValues)
+		if err != nil {
+			return err
+		}
+		if format != "text" {
+			_, err := PrintComponent(description, format)
+			return err
+		}
+		return printDescription(description)
+	},
+}
+
+// printDescription prints the description of an instance as a human-readable report
+func printDescription(description *products.Description) error {
+	fmt.Printf("Name:       %s\n", description.Name)
+	fmt.Printf("Namespace:  %s\n", description.Namespace)
+	fmt.Printf("Product:    %s\n", description.App)
+	fmt.Printf("Version:    %s\n", description.Version)
+	fmt.Printf("\nRelease:\n")
+	fmt.Printf("  Name:         %s\n", description.Release.Name)
+	fmt.Printf("  Revision:     %d\n", description.Release.Revision)
+	fmt.Printf("  Status:       %s\n", description.Release.Status)
+	fmt.Printf("  Updated:      %s\n", description.Release.Updated)
+	fmt.Printf("  Chart:        %s-%s\n", description.Release.Chart, description.Release.ChartVersion)
+	fmt.Printf("  App Version:  %s\n", description.Release.AppVersion)
+	if len(description.Release.Description) > 0 {
+		fmt.Printf("  Description:  %s\n", description.Release.Description)
+	}
+
+	fmt.Printf("\nHelm Values:\n")
+	if len(description.Values) == 0 {
+		fmt.Printf("  <none>\n")
+	} else {
+		data, err := yaml.Marshal(description.Values)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the Helm values due to %+v", err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	fmt.Printf("\nSecrets created by synopsysctl:\n")
+	if len(description.Secrets) == 0 {
+		fmt.Printf("  <none>\n")
+	}
+	for _, secret := range description.Secrets {
+		fmt.Printf("  %s\n", secret)
+	}
+
+	fmt.Printf("\nEndpoints:\n")
+	if len(description.Endpoints) == 0 {
+		fmt.Printf("  <none>\n")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tTYPE\tURL")
+		for _, endpoint := range description.Endpoints {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", endpoint.Name, endpoint.Type, endpoint.URL)
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nVolumes:\n")
+	if len(description.Volumes) == 0 {
+		fmt.Printf("  <none>\n")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tSTATUS\tSTORAGE CLASS\tCAPACITY\tUSED\tACCESS MODES\tMOUNTED BY")
+	for _, volume := range description.Volumes {
+		mountedBy := strings.Join(volume.MountedBy, ",")
+		if len(mountedBy) == 0 {
+			mountedBy = "<none>"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\n", volume.Name, volume.Status, volume.StorageClass, volume.Capacity, volume.Usage(), strings.Join(volume.AccessModes, ","), mountedBy)
+	}
+	return w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+
+	describeCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(describeCmd.Flags(), "namespace")
+	describeCmd.Flags().StringVarP(&describeOutputFormat, "output", "o", describeOutputFormat, "Output format [text|json|yaml]")
+	describeCmd.Flags().BoolVar(&describeAllValues, "all-values", describeAllValues, "If true, describe all the Helm values of the chart instead of the values that were set")
+}
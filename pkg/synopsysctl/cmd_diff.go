@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd shows the changes that an update of an instance would make
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the changes that an update of an instance would make to its resources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// diffAlertCmd shows the changes that an update of an Alert instance would make
+var diffAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE",
+	Example:       "synopsysctl diff alert <name> -n <namespace> --port 80",
+	Short:         "Show the changes that an update of an Alert instance would make",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          updateAlertCmd.Args,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(cmd, args, updateAlertCmd.RunE)
+	},
+}
+
+// diffBlackDuckCmd shows the changes that an update of a Black Duck instance would make
+var diffBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl diff blackduck <name> -n <namespace> --size medium",
+	Short:         "Show the changes that an update of a Black Duck instance would make",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          updateBlackDuckCmd.Args,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(cmd, args, updateBlackDuckCmd.RunE)
+	},
+}
+
+// diffOpsSightCmd shows the changes that an update of an OpsSight instance would make
+var diffOpsSightCmd = &cobra.Command{
+	Use:           "opssight NAME -n NAMESPACE",
+	Example:       "synopsysctl diff opssight <name> -n <namespace> --blackduck-max-count 2",
+	Short:         "Show the changes that an update of an OpsSight instance would make",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          updateOpsSightCmd.Args,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(cmd, args, updateOpsSightCmd.RunE)
+	},
+}
+
+// diffBDBACmd shows the changes that an update of a BDBA instance would make
+var diffBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl diff bdba -n <namespace> --expose-ui NODEPORT",
+	Short:         "Show the changes that an update of a BDBA instance would make",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          updateBDBACmd.Args,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(cmd, args, updateBDBACmd.RunE)
+	},
+}
+
+// runDiff runs the update command of the product in a plan that stops at its Helm operation, then compares the manifest
+// of the deployed release with the one rendered from the new flags and values
+func runDiff(cmd *cobra.Command, args []string, updateFunc func(cmd *cobra.Command, args []string) error) error {
+	plan := util.StartPlan(cmd.CommandPath())
+	plan.Diff = true
+	// the diff is printed here, not as the plan of the command
+	defer func() { util.ActivePlan = nil }()
+
+	err := updateFunc(cmd, args)
+	if !plan.Complete {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("the update doesn't change the Helm release, there is nothing to compare")
+	}
+
+	color := !util.NoColor && util.IsTerminal(os.Stdout)
+	for _, operation := range plan.HelmOperations {
+		diffs, err := util.DiffManifests(operation.CurrentManifest, operation.Manifest, operation.Namespace)
+		if err != nil {
+			return fmt.Errorf("failed to compare the manifests of release '%s' due to %+v", operation.Release, err)
+		}
+		if len(diffs) == 0 {
+			fmt.Printf("release '%s' in namespace '%s' has no changes\n", operation.Release, operation.Namespace)
+			continue
+		}
+		if err := util.PrintManifestDiff(os.Stdout, diffs, color); err != nil {
+			return err
+		}
+	}
+
+	// the manifest changes are not added in a diff, so the resources are the ones synopsysctl changes itself
+	for _, resources := range [][]util.PlanResource{plan.Secrets, plan.Resources} {
+		for _, resource := range resources {
+			fmt.Printf("%s %s %s/%s (outside of Helm)\n", resource.Action, resource.Kind, resource.Namespace, resource.Name)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffAlertCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(diffAlertCmd.PersistentFlags(), "namespace")
+	updateAlertCobraHelper.AddCobraFlagsToCommand(diffAlertCmd, false)
+	addValuesFileFlag(diffAlertCmd)
+	addChartLocationPathFlag(diffAlertCmd)
+	diffCmd.AddCommand(diffAlertCmd)
+
+	diffBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(diffBlackDuckCmd.PersistentFlags(), "namespace")
+	updateBlackDuckCobraHelper.AddCobraFlagsToCommand(diffBlackDuckCmd, false)
+	addValuesFileFlag(diffBlackDuckCmd)
+	addChartLocationPathFlag(diffBlackDuckCmd)
+	diffCmd.AddCommand(diffBlackDuckCmd)
+
+	diffOpsSightCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(diffOpsSightCmd.PersistentFlags(), "namespace")
+	updateOpsSightCobraHelper.AddCobraFlagsToCommand(diffOpsSightCmd, false)
+	addValuesFileFlag(diffOpsSightCmd)
+	addChartLocationPathFlag(diffOpsSightCmd)
+	diffCmd.AddCommand(diffOpsSightCmd)
+
+	diffBDBACmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(diffBDBACmd.PersistentFlags(), "namespace")
+	updateBDBACobraHelper.AddCobraFlagsToCommand(diffBDBACmd, false)
+	addValuesFileFlag(diffBDBACmd)
+	addChartLocationPathFlag(diffBDBACmd)
+	diffCmd.AddCommand(diffBDBACmd)
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Get Events Command Options and Defaults
+var getEventsSince time.Duration
+var getEventsWatch = false
+var getEventsOutputFormat = "table"
+
+// getEventsRefreshInterval is the minimum time between two lookups of the objects of an instance while watching, new
+// pods are only matched to the instance after a lookup
+var getEventsRefreshInterval = 5 * time.Second
+
+// getEventsCmd displays the events of the objects of an instance
+var getEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Display the events of the objects of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// newGetEventsCmd returns the get events command of a product
+func newGetEventsCmd(app string, product string) *cobra.Command {
+	use, example, argCount := instanceUsage(app)
+	return &cobra.Command{
+		Use:           use,
+		Example:       fmt.Sprintf("synopsysctl get events %s --since 1h\nsynopsysctl get events %s --watch", example, example),
+		Short:         fmt.Sprintf("Display the events of the pods, workloads, services and volumes of a %s instance sorted by time", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != argCount {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": argCount, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := strings.ToLower(getEventsOutputFormat)
+			if format != "table" && format != "json" && format != "yaml" {
+				return util.ValidationError("output format must be 'table', 'json' or 'yaml', got '%s'", getEventsOutputFormat)
+			}
+			name := globals.BDBAName
+			if len(args) > 0 {
+				name = args[0]
+			}
+			instance, err := products.New(app, name, namespace, getProductClients())
+			if err != nil {
+				return err
+			}
+			components, err := getInstanceObjectComponents(namespace, instance.LabelSelector())
+			if err != nil {
+				return fmt.Errorf("unable to list the objects of %s '%s' in namespace '%s' due to %+v", product, name, namespace, err)
+			}
+			events, err := kubeClient.CoreV1().Events(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to list the events in namespace '%s' due to %+v", namespace, err)
+			}
+			since := time.Time{}
+			if getEventsSince > 0 {
+				since = time.Now().Add(-getEventsSince)
+			}
+			instanceEvents := util.AggregateInstanceEvents(events.Items, components, since)
+			if format == "table" {
+				if len(instanceEvents) == 0 && !getEventsWatch {
+					log.Infof("no events found for %s '%s' in namespace '%s'", product, name, namespace)
+					return nil
+				}
+				printInstanceEvents(os.Stdout, instanceEvents, true)
+			} else if len(instanceEvents) > 0 || !getEventsWatch {
+				if _, err := PrintComponent(instanceEvents, format); err != nil {
+					return err
+				}
+			}
+			if !getEventsWatch {
+				return nil
+			}
+			return watchInstanceEvents(namespace, instance.LabelSelector(), components, events.ResourceVersion, format)
+		},
+	}
+}
+
+// getInstanceObjectComponents returns the components of the objects of an instance that events are reported for, keyed
+// by util.EventObjectKey
+func getInstanceObjectComponents(namespace string, labelSelector string) (map[string]string, error) {
+	components := map[string]string{}
+	add := func(kind string, meta metav1.ObjectMeta) {
+		components[util.EventObjectKey(kind, meta.Name)] = util.LabelsComponent(meta.Labels)
+	}
+	options := metav1.ListOptions{LabelSelector: labelSelector}
+
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		add("Pod", pod.ObjectMeta)
+	}
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		add("Deployment", deployment.ObjectMeta)
+	}
+	replicaSets, err := kubeClient.AppsV1().ReplicaSets(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, replicaSet := range replicaSets.Items {
+		add("ReplicaSet", replicaSet.ObjectMeta)
+	}
+	statefulSets, err := kubeClient.AppsV1().StatefulSets(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		add("StatefulSet", statefulSet.ObjectMeta)
+	}
+	jobs, err := kubeClient.BatchV1().Jobs(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Items {
+		add("Job", job.ObjectMeta)
+	}
+	services, err := kubeClient.CoreV1().Services(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services.Items {
+		add("Service", service.ObjectMeta)
+	}
+	pvcs, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcs.Items {
+		add("PersistentVolumeClaim", pvc.ObjectMeta)
+	}
+	return components, nil
+}
+
+// watchInstanceEvents prints the new events of the objects of an instance until synopsysctl is interrupted
+func watchInstanceEvents(namespace string, labelSelector string, components map[string]string, resourceVersion string, format string) error {
+	lastRefresh := time.Now()
+	for {
+		watcher, err := kubeClient.CoreV1().Events(namespace).Watch(metav1.ListOptions{ResourceVersion: resourceVersion})
+		if err != nil {
+			return fmt.Errorf("unable to watch the events in namespace '%s' due to %+v", namespace, err)
+		}
+		for result := range watcher.ResultChan() {
+			if result.Type == watch.Error {
+				// the resource version is too old, continue after the current events without printing them again
+				events, err := kubeClient.CoreV1().Events(namespace).List(metav1.ListOptions{})
+				if err != nil {
+					watcher.Stop()
+					return fmt.Errorf("unable to list the events in namespace '%s' due to %+v", namespace, err)
+				}
+				resourceVersion = events.ResourceVersion
+				break
+			}
+			event, ok := result.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			resourceVersion = event.ResourceVersion
+			if result.Type == watch.Deleted {
+				continue
+			}
+			instanceEvent, ok := util.GetInstanceEvent(*event, components)
+			if !ok && time.Since(lastRefresh) > getEventsRefreshInterval {
+				// the event may belong to an object that was created after the last lookup, e.g. a restarted pod
+				if refreshed, err := getInstanceObjectComponents(namespace, labelSelector); err == nil {
+					components = refreshed
+				} else {
+					log.Warnf("unable to refresh the objects of the instance: %+v", err)
+				}
+				lastRefresh = time.Now()
+				instanceEvent, ok = util.GetInstanceEvent(*event, components)
+			}
+			if !ok {
+				continue
+			}
+			if format == "table" {
+				printInstanceEvents(os.Stdout, []util.InstanceEvent{instanceEvent}, false)
+			} else if _, err := PrintComponent(instanceEvent, format); err != nil {
+				return err
+			}
+		}
+		watcher.Stop()
+		log.Debugf("restarting the watch of the events in namespace '%s'", namespace)
+	}
+}
+
+// printInstanceEvents prints the events as a table, the header is omitted for the events that are printed while watching
+func printInstanceEvents(out io.Writer, events []util.InstanceEvent, header bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if header {
+		fmt.Fprintln(w, "TIME\tCOMPONENT\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	}
+	for _, event := range events {
+		component := event.Component
+		if len(component) == 0 {
+			component = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s/%s\t%d\t%s\n", event.Time.Format("2006-01-02 15:04:05"), component, event.Type, event.Reason, strings.ToLower(event.Kind), event.Object, event.Count, strings.TrimSpace(event.Message))
+	}
+	w.Flush()
+}
+
+func init() {
+	getEventsCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(getEventsCmd.PersistentFlags(), "namespace")
+	getEventsCmd.PersistentFlags().DurationVar(&getEventsSince, "since", getEventsSince, "Only display the events newer than the duration, e.g. 10m or 1h (default all events)")
+	getEventsCmd.PersistentFlags().BoolVarP(&getEventsWatch, "watch", "w", getEventsWatch, "If true, keep displaying the new events until synopsysctl is interrupted")
+	getEventsCmd.PersistentFlags().StringVarP(&getEventsOutputFormat, "output", "o", getEventsOutputFormat, "Output format [table|json|yaml]")
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+		{app: globals.BDBAName, name: "BDBA"},
+	} {
+		getEventsCmd.AddCommand(newGetEventsCmd(product.app, product.name))
+	}
+	getCmd.AddCommand(getEventsCmd)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Freeze Command Options and Defaults
+var freezeReason = ""
+var freezeWebhook = false
+
+// freezeCmd freezes an instance so that synopsysctl refuses to change it
+var freezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Freeze a Synopsys resource so that synopsysctl refuses to change it, e.g. during a compliance audit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// unfreezeCmd removes the freeze of an instance
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze",
+	Short: "Unfreeze a frozen Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// frozenInstance is the release and the labels of the resources of an instance to freeze
+type frozenInstance struct {
+	name        string
+	releaseName string
+	labels      map[string]string
+}
+
+// getFrozenInstance returns the instance of the product named by the arguments, the BDBA instance is named by its
+// namespace
+func getFrozenInstance(app string, args []string) frozenInstance {
+	switch app {
+	case util.AlertName:
+		return frozenInstance{name: args[0], releaseName: fmt.Sprintf("%s%s", args[0], globals.AlertPostSuffix), labels: map[string]string{"app": app, "name": args[0]}}
+	case globals.BDBAName:
+		return frozenInstance{name: namespace, releaseName: globals.BDBAName, labels: map[string]string{"app.kubernetes.io/instance": globals.BDBAName}}
+	}
+	return frozenInstance{name: args[0], releaseName: args[0], labels: map[string]string{"app": app, "name": args[0]}}
+}
+
+// newFreezeCmd returns the freeze command of a product
+func newFreezeCmd(app string, product string) *cobra.Command {
+	use, example, argCount := instanceUsage(app)
+	return &cobra.Command{
+		Use:           use,
+		Example:       fmt.Sprintf("synopsysctl freeze %s --reason 'SOC 2 audit'\nsynopsysctl freeze %s --webhook", example, example),
+		Short:         fmt.Sprintf("Freeze a %s instance so that synopsysctl refuses to change it", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != argCount {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": argCount, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			instance := getFrozenInstance(app, args)
+			if freezeWebhook {
+				kubeVersion, _ := util.GetKubernetesVersion(kubeClient)
+				if err := util.CheckFreezeWebhookSupported(kubeVersion); err != nil {
+					return err
+				}
+			}
+			if err := util.FreezeRelease(kubeClient, namespace, instance.releaseName, freezeReason, time.Now()); err != nil {
+				return err
+			}
+			log.Infof("%s '%s' in namespace '%s' is frozen, synopsysctl refuses to change it without --override-freeze", product, instance.name, namespace)
+			if !freezeWebhook {
+				return nil
+			}
+			webhook := util.NewFreezeWebhookConfiguration(namespace, instance.releaseName, instance.labels)
+			if err := util.CreateFreezeWebhook(kubeClient, webhook); err != nil {
+				return util.WithExitCode(util.ExitCodePartialSuccess, err)
+			}
+			log.Infof("created the validating webhook configuration '%s' that rejects the updates and deletions of the resources of %s '%s', e.g. by kubectl", webhook.Name, product, instance.name)
+			return nil
+		},
+	}
+}
+
+// newUnfreezeCmd returns the unfreeze command of a product
+func newUnfreezeCmd(app string, product string) *cobra.Command {
+	use, example, argCount := instanceUsage(app)
+	return &cobra.Command{
+		Use:           use,
+		Example:       fmt.Sprintf("synopsysctl unfreeze %s", example),
+		Short:         fmt.Sprintf("Unfreeze a frozen %s instance", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != argCount {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": argCount, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			instance := getFrozenInstance(app, args)
+			// the webhook rejects the changes of synopsysctl as well, so it's removed first
+			deleted, err := util.DeleteFreezeWebhook(kubeClient, namespace, instance.releaseName)
+			if err != nil {
+				return err
+			}
+			if deleted {
+				log.Infof("deleted the validating webhook configuration '%s'", util.FreezeWebhookConfigurationName(namespace, instance.releaseName))
+			}
+			unfrozen, err := util.UnfreezeRelease(kubeClient, namespace, instance.releaseName)
+			if err != nil {
+				return err
+			}
+			if !unfrozen && !deleted {
+				log.Infof("%s '%s' in namespace '%s' is not frozen", product, instance.name, namespace)
+				return nil
+			}
+			log.Infof("%s '%s' in namespace '%s' is unfrozen", product, instance.name, namespace)
+			return nil
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(unfreezeCmd)
+
+	for _, cmd := range []*cobra.Command{freezeCmd, unfreezeCmd} {
+		cmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+		cobra.MarkFlagRequired(cmd.PersistentFlags(), "namespace")
+	}
+	freezeCmd.PersistentFlags().StringVar(&freezeReason, "reason", freezeReason, "Reason of the freeze, shown when synopsysctl refuses to change the instance")
+	freezeCmd.PersistentFlags().BoolVar(&freezeWebhook, "webhook", freezeWebhook, "If true, create a validating webhook that also rejects the updates and deletions of the resources by kubectl until the instance is unfrozen or changed with --override-freeze, requires Kubernetes 1.21 or later")
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+		{app: globals.BDBAName, name: "BDBA"},
+	} {
+		freezeCmd.AddCommand(newFreezeCmd(product.app, product.name))
+		unfreezeCmd.AddCommand(newUnfreezeCmd(product.app, product.name))
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// GC Command Options and Defaults
+var gcExpired = false
+var gcDryRun = false
+
+// pocInstance is a trial instance found in the cluster
+type pocInstance struct {
+	App       string
+	Name      string
+	Namespace string
+	Expiry    time.Time
+}
+
+// gcCmd cleans up trial instances that have expired
+var gcCmd = &cobra.Command{
+	Use:           "gc --expired",
+	Example:       "synopsysctl gc --expired\nsynopsysctl gc --expired --dry-run",
+	Short:         "Clean up Synopsys trial instances created with --poc",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !gcExpired {
+			cmd.Help()
+			return fmt.Errorf("must specify what to clean up, e.g. --expired")
+		}
+
+		instances, err := listPOCInstances()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		expiredInstances := []pocInstance{}
+		for _, instance := range instances {
+			if instance.Expiry.Before(now) {
+				expiredInstances = append(expiredInstances, instance)
+			}
+		}
+		if len(expiredInstances) == 0 {
+			log.Infof("no expired trial instances found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "PRODUCT\tNAME\tNAMESPACE\tEXPIRED")
+		for _, instance := range expiredInstances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\n", instance.App, instance.Name, instance.Namespace, now.Sub(instance.Expiry).Round(time.Minute))
+		}
+		w.Flush()
+
+		if gcDryRun {
+			log.Infof("%d trial instance(s) have expired, run without --dry-run to delete them", len(expiredInstances))
+			return nil
+		}
+		if err := confirmDestructiveAction(fmt.Sprintf("this will delete %d expired trial instance(s)", len(expiredInstances))); err != nil {
+			return err
+		}
+
+		for i, instance := range expiredInstances {
+			if err := deletePOCInstance(instance); err != nil {
+				if i > 0 {
+					return util.WithExitCode(util.ExitCodePartialSuccess, fmt.Errorf("deleted %d of %d expired trial instance(s): %w", i, len(expiredInstances), err))
+				}
+				return err
+			}
+			log.Infof("deleted expired trial %s instance '%s' in namespace '%s'", instance.App, instance.Name, instance.Namespace)
+		}
+		return nil
+	},
+}
+
+// listPOCInstances finds all trial instances in the cluster based on the labels of their deployments
+func listPOCInstances() ([]pocInstance, error) {
+	deployments, err := util.ListDeployments(kubeClient, "", fmt.Sprintf("%s=true", util.POCLabel))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list trial instances due to %+v", err)
+	}
+	instances := []pocInstance{}
+	found := map[string]bool{}
+	for _, deployment := range deployments.Items {
+		labels := deployment.GetLabels()
+		key := fmt.Sprintf("%s/%s/%s", deployment.Namespace, labels["app"], labels["name"])
+		if found[key] {
+			continue
+		}
+		expiry, _, err := util.GetPOCExpiryFromLabels(labels)
+		if err != nil {
+			log.Warnf("skipping deployment '%s' in namespace '%s': %+v", deployment.Name, deployment.Namespace, err)
+			continue
+		}
+		found[key] = true
+		instances = append(instances, pocInstance{
+			App:       labels["app"],
+			Name:      labels["name"],
+			Namespace: deployment.Namespace,
+			Expiry:    expiry,
+		})
+	}
+	return instances, nil
+}
+
+// deletePOCInstance deletes the trial instance with the delete function of its product
+func deletePOCInstance(instance pocInstance) error {
+	switch instance.App {
+	case util.AlertName:
+		return deleteAlert(instance.Name, instance.Namespace)
+	case util.BlackDuckName:
+		return deleteBlackDuck(instance.Name, instance.Namespace)
+	}
+	return fmt.Errorf("unable to delete trial instance '%s' in namespace '%s': unknown product '%s'", instance.Name, instance.Namespace, instance.App)
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().BoolVar(&gcExpired, "expired", gcExpired, "If true, delete the trial instances that have expired")
+	addConfirmationFlag(gcCmd)
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", gcDryRun, "If true, only list the trial instances that would be deleted")
+}
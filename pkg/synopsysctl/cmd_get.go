@@ -27,8 +27,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -43,6 +46,9 @@ var getSelector string
 // Get Command flag for --all-namespaces functionality
 var getAllNamespaces bool
 
+// getBDBAValues prints the values of the BDBA instance instead of listing the instances
+var getBDBAValues bool
+
 func generateKubectlGetCommand(resourceName string, args []string) []string {
 	kubectlCmd := []string{"get", resourceName}
 	if len(namespace) > 0 {
@@ -65,30 +71,88 @@ func generateKubectlGetCommand(resourceName string, args []string) []string {
 	return kubectlCmd
 }
 
+// printInstances lists the instances of a product in the namespace, or in all namespaces if no namespace is set
+func printInstances(app string) error {
+	instances, err := products.ListInstances(app, namespace, getProductClients())
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(getOutputFormat) {
+	case "", "table", "wide":
+		if len(instances) == 0 {
+			log.Infof("no %s instances found", app)
+			return nil
+		}
+		table := util.NewTable(
+			util.TableColumn{Name: "NAME"},
+			util.TableColumn{Name: "NAMESPACE"},
+			util.TableColumn{Name: "CHART VERSION"},
+			util.TableColumn{Name: "APP VERSION"},
+			util.TableColumn{Name: "EXPOSE"},
+			util.TableColumn{Name: "PODS READY"},
+			util.TableColumn{Name: "REVISION", Wide: true},
+			util.TableColumn{Name: "STATUS", Wide: true},
+			util.TableColumn{Name: "UPDATED", Wide: true},
+		)
+		for _, instance := range instances {
+			table.AddRow(instance.Name, instance.Namespace, instance.ChartVersion, instance.AppVersion, instance.Expose, fmt.Sprintf("%d/%d", instance.ReadyPods, instance.TotalPods), instance.Revision, instance.Status, instance.Updated)
+		}
+		return table.Print(os.Stdout, tableOptions(getOutputFormat))
+	case "json", "yaml":
+		_, err := PrintComponent(instances, getOutputFormat)
+		return err
+	}
+	return util.ValidationError("output format must be 'table', 'wide', 'json' or 'yaml', got '%s'", getOutputFormat)
+}
+
+// printInstanceValues prints the Helm values set for an instance
+func printInstanceValues(values map[string]interface{}) error {
+	if strings.ToLower(getOutputFormat) == "json" {
+		_, err := PrintComponent(values, "JSON")
+		return err
+	}
+	_, err := PrintComponent(values, "YAML")
+	return err
+}
+
+// requireNamespace returns an error if the namespace of the instance isn't set
+func requireNamespace() error {
+	if len(namespace) == 0 {
+		return util.ValidationError("required flag(s) \"namespace\" not set")
+	}
+	return nil
+}
+
 // getCmd lists resources in the cluster
 var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Display Synopsys resources from your cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
 // getAlertCmd display one or many Alert instances
 var getAlertCmd = &cobra.Command{
-	Use:           "alert NAME -n NAMESPACE",
-	Example:       "synopsysctl get alert <name> -n <namespace>",
+	Use:           "alert [NAME] [-n NAMESPACE]",
+	Example:       "synopsysctl get alerts\nsynopsysctl get alerts -n <namespace> -o json\nsynopsysctl get alert <name> -n <namespace>",
 	Aliases:       []string{"alerts"},
-	Short:         "Display an Alert instance",
+	Short:         "List the Alert instances or display an Alert instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		if len(args) > 1 {
+			return messages.Error(messages.ArgumentCountUpTo, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return printInstances(util.AlertName)
+		}
+		if err := requireNamespace(); err != nil {
+			return err
+		}
 		alertName := args[0]
 		helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
 		helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
@@ -96,35 +160,37 @@ var getAlertCmd = &cobra.Command{
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
 			return fmt.Errorf("failed to get values for Alert: %+v", cleanErrorMsg)
 		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return printInstanceValues(helmRelease.Config)
 	},
 }
 
 // getBlackDuckCmd display a Black Duck instances
 var getBlackDuckCmd = &cobra.Command{
-	Use:           "blackduck NAME -n NAMESPACE",
-	Example:       "synopsysctl get blackduck <name> -n <namespace>",
+	Use:           "blackduck [NAME] [-n NAMESPACE]",
+	Example:       "synopsysctl get blackducks\nsynopsysctl get blackducks -n <namespace> -o json\nsynopsysctl get blackducks -o wide --sort-by app-version --no-headers\nsynopsysctl get blackduck <name> -n <namespace>",
 	Aliases:       []string{"blackducks"},
-	Short:         "Display a Black Duck instance",
+	Short:         "List the Black Duck instances or display a Black Duck instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
+		if len(args) > 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCountUpTo, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return printInstances(util.BlackDuckName)
+		}
+		if err := requireNamespace(); err != nil {
+			return err
+		}
 		helmRelease, err := util.GetWithHelm3(args[0], namespace, kubeConfigPath)
 		if err != nil {
 			return fmt.Errorf("failed to get Blackduck values: %+v", err)
 		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return printInstanceValues(helmRelease.Config)
 	},
 }
 
@@ -138,11 +204,14 @@ var getBlackDuckRootKeyCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 2 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 2 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 2, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireNamespace(); err != nil {
+			return err
+		}
 		return getBlackDuckMasterKey(namespace, args[0], args[1])
 	},
 }
@@ -183,52 +252,60 @@ func getBlackDuckMasterKey(namespace string, name string, filePath string) error
 
 // getOpsSightCmd display an OpsSight instance
 var getOpsSightCmd = &cobra.Command{
-	Use:           "opssight NAME -n NAMESPACE",
-	Example:       "synopsysctl get opssight -n <namespace>",
+	Use:           "opssight [NAME] [-n NAMESPACE]",
+	Example:       "synopsysctl get opssights\nsynopsysctl get opssights -n <namespace> -o json\nsynopsysctl get opssight <name> -n <namespace>",
 	Aliases:       []string{"opssights"},
-	Short:         "Display an OpsSight instance",
+	Short:         "List the OpsSight instances or display an OpsSight instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
+		if len(args) > 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCountUpTo, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return printInstances(util.OpsSightName)
+		}
+		if err := requireNamespace(); err != nil {
+			return err
+		}
 		opssightName := args[0]
 		helmRelease, err := util.GetWithHelm3(opssightName, namespace, kubeConfigPath)
 		if err != nil {
 			return fmt.Errorf("failed to get OpsSight values: %+v", err)
 		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return printInstanceValues(helmRelease.Config)
 	},
 }
 
 // getBDBACmd display the BDBA instance
 var getBDBACmd = &cobra.Command{
-	Use:           "bdba -n NAMESPACE",
-	Example:       "synopsysctl get bdba -n <namespace>",
-	Short:         "Display the BDBA instance",
+	Use:           "bdba [-n NAMESPACE]",
+	Example:       "synopsysctl get bdba\nsynopsysctl get bdba -o json\nsynopsysctl get bdba -n <namespace> --values",
+	Short:         "List the BDBA instances or display the BDBA instance of a namespace",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 0 {
-			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if !getBDBAValues {
+			return printInstances(globals.BDBAName)
+		}
+		if err := requireNamespace(); err != nil {
+			return err
+		}
 		helmRelease, err := util.GetWithHelm3(globals.BDBAName, namespace, kubeConfigPath)
 		if err != nil {
 			return fmt.Errorf("failed to get BDBA values: %+v", err)
 		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return printInstanceValues(helmRelease.Config)
 	},
 }
 
@@ -237,24 +314,29 @@ func init() {
 	rootCmd.AddCommand(getCmd)
 
 	// Alert
-	getAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getAlertCmd.Flags(), "namespace")
+	getAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) (default all namespaces when listing)")
+	getAlertCmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [table|wide|json|yaml]")
+	addTableFlags(getAlertCmd, "Sort the listed instances by the column, e.g. namespace or app-version")
 	getCmd.AddCommand(getAlertCmd)
 
 	// Black Duck
-	getBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getBlackDuckCmd.PersistentFlags(), "namespace")
+	getBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) (default all namespaces when listing)")
+	getBlackDuckCmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [table|wide|json|yaml]")
+	addTableFlags(getBlackDuckCmd, "Sort the listed instances by the column, e.g. namespace or app-version")
 	getCmd.AddCommand(getBlackDuckCmd)
 
 	getBlackDuckCmd.AddCommand(getBlackDuckRootKeyCmd)
 
 	// OpsSight
-	getOpsSightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getOpsSightCmd.PersistentFlags(), "namespace")
+	getOpsSightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) (default all namespaces when listing)")
+	getOpsSightCmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [table|wide|json|yaml]")
+	addTableFlags(getOpsSightCmd, "Sort the listed instances by the column, e.g. namespace or app-version")
 	getCmd.AddCommand(getOpsSightCmd)
 
 	// BDBA
-	getBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getBDBACmd.Flags(), "namespace")
+	getBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) (default all namespaces when listing)")
+	getBDBACmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [table|wide|json|yaml]")
+	addTableFlags(getBDBACmd, "Sort the listed instances by the column, e.g. namespace or app-version")
+	getBDBACmd.Flags().BoolVar(&getBDBAValues, "values", getBDBAValues, "If true, display the values of the BDBA instance of the namespace instead of listing the instances")
 	getCmd.AddCommand(getBDBACmd)
 }
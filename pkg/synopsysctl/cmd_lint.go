@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// Lint Command Options and Defaults
+var lintVersion = ""
+var lintKubeVersion = ""
+var lintOutputFormat = "table"
+
+// lintChart is the chart of a product and the values to render it with
+type lintChart struct {
+	chartName       string
+	chartRepository *string
+	values          map[string]interface{}
+	extraFiles      []string
+}
+
+// getLintChart returns the chart of a product
+func getLintChart(app string) (*lintChart, error) {
+	switch app {
+	case util.AlertName:
+		return &lintChart{chartName: globals.AlertChartName, chartRepository: &globals.AlertChartRepository, values: map[string]interface{}{}}, nil
+	case util.BlackDuckName:
+		// the defaults of 'create blackduck'
+		values := map[string]interface{}{"isKubernetes": true, "enablePersistentStorage": true, "size": "small"}
+		return &lintChart{chartName: globals.BlackDuckChartName, chartRepository: &globals.BlackDuckChartRepository, values: values, extraFiles: []string{"small.yaml"}}, nil
+	case util.OpsSightName:
+		return &lintChart{chartName: globals.OpsSightChartName, chartRepository: &globals.OpsSightChartRepository, values: map[string]interface{}{}}, nil
+	case globals.BDBAName:
+		return &lintChart{chartName: globals.BDBAChartName, chartRepository: &globals.BDBAChartRepository, values: map[string]interface{}{}}, nil
+	}
+	return nil, fmt.Errorf("product must be '%s', '%s', '%s' or '%s', but got '%s'", util.AlertName, util.BlackDuckName, util.OpsSightName, globals.BDBAName, app)
+}
+
+// lintCmd lints the manifests rendered by the chart of a product
+var lintCmd = &cobra.Command{
+	Use:           "lint PRODUCT [--version VERSION]",
+	Example:       "synopsysctl lint blackduck --version 2020.6.0\nsynopsysctl lint alert --kube-version 1.22 --output json",
+	Short:         "Check the Kubernetes resources of a product for missing probes and limits, deprecated API versions and latest image tags",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		// accept the product aliases, e.g. 'lint bd'
+		if product := getProductName(args[0]); len(product) > 0 {
+			args[0] = product
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if lintOutputFormat != "table" && lintOutputFormat != "json" {
+			return fmt.Errorf("output must be 'table' or 'json', but got '%s'", lintOutputFormat)
+		}
+		chart, err := getLintChart(args[0])
+		if err != nil {
+			return err
+		}
+		if err := UpdateHelmChartLocation(cmd.Flags(), chart.chartName, lintVersion, chart.chartRepository); err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+		lintNamespace := namespace
+		if len(lintNamespace) == 0 {
+			lintNamespace = "default"
+		}
+		findings, err := util.LintWithHelm3(args[0], lintNamespace, *chart.chartRepository, chart.values, lintKubeVersion, chart.extraFiles...)
+		if err != nil {
+			return fmt.Errorf("failed to lint the %s resources due to %+v", args[0], err)
+		}
+
+		if lintOutputFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(findings); err != nil {
+				return err
+			}
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "RULE\tKIND\tNAME\tCONTAINER\tMESSAGE")
+			for _, finding := range findings {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", finding.Rule, finding.Kind, finding.Name, finding.Container, finding.Message)
+			}
+			w.Flush()
+		}
+
+		if len(findings) > 0 {
+			return fmt.Errorf("found %d problem(s) in the %s resources", len(findings), args[0])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVar(&lintVersion, "version", lintVersion, "Version of the product (default latest)")
+	lintCmd.Flags().StringVar(&lintKubeVersion, "kube-version", lintKubeVersion, "Kubernetes version of the target cluster, e.g. 1.17 (default report all deprecated API versions)")
+	lintCmd.Flags().StringVarP(&lintOutputFormat, "output", "o", lintOutputFormat, "Output format [table|json]")
+	lintCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace to render the resources for")
+	addChartLocationPathFlag(lintCmd)
+}
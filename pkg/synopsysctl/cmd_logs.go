@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Logs Command Options and Defaults
+var logsComponent = ""
+var logsFollow = false
+var logsSince time.Duration
+
+// logsCmd prints the logs of the components of an instance
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Print the logs of the components of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// newLogsCmd returns the logs command of a product
+func newLogsCmd(app string, product string) *cobra.Command {
+	use, example, argCount := instanceUsage(app)
+	return &cobra.Command{
+		Use:           use,
+		Example:       fmt.Sprintf("synopsysctl logs %s\nsynopsysctl logs %s --component webserver -f --since 1h", example, example),
+		Short:         fmt.Sprintf("Print the logs of the pods of a %s instance, prefixed with the pod and the container", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != argCount {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": argCount, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := globals.BDBAName
+			if len(args) > 0 {
+				name = args[0]
+			}
+			instance, err := products.New(app, name, namespace, getProductClients())
+			if err != nil {
+				return err
+			}
+			pods, err := util.ListPodsWithLabels(kubeClient, namespace, instance.LabelSelector())
+			if err != nil {
+				return fmt.Errorf("unable to list the pods of %s '%s' in namespace '%s' due to %+v", product, name, namespace, err)
+			}
+			sources := util.GetLogSources(pods.Items, logsComponent)
+			if len(sources) == 0 {
+				if len(logsComponent) > 0 {
+					return fmt.Errorf("no started pods of component '%s' found for %s '%s' in namespace '%s', the components are [%s]", logsComponent, product, name, namespace, strings.Join(util.GetPodComponents(pods.Items), "|"))
+				}
+				return fmt.Errorf("no started pods found for %s '%s' in namespace '%s'", product, name, namespace)
+			}
+			return printLogs(namespace, sources)
+		},
+	}
+}
+
+// printLogs copies the logs of the containers to the standard output. The logs are printed one container after the
+// other, or multiplexed line by line if they are followed
+func printLogs(namespace string, sources []util.LogSource) error {
+	copyLogs := func(w io.Writer, source util.LogSource) {
+		options := &corev1.PodLogOptions{Container: source.Container, Follow: logsFollow}
+		if logsSince > 0 {
+			seconds := int64(logsSince.Seconds())
+			options.SinceSeconds = &seconds
+		}
+		stream, err := kubeClient.CoreV1().Pods(namespace).GetLogs(source.Pod, options).Stream()
+		if err != nil {
+			log.Warnf("unable to get the logs of container '%s' of pod '%s' due to %+v", source.Container, source.Pod, err)
+			return
+		}
+		defer stream.Close()
+		if err := util.CopyPrefixedLines(w, stream, source.Prefix()); err != nil {
+			log.Warnf("the logs of container '%s' of pod '%s' ended due to %+v", source.Container, source.Pod, err)
+		}
+	}
+
+	if !logsFollow {
+		for _, source := range sources {
+			copyLogs(os.Stdout, source)
+		}
+		return nil
+	}
+	w := util.NewLockedWriter(os.Stdout)
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source util.LogSource) {
+			defer wg.Done()
+			copyLogs(w, source)
+		}(source)
+	}
+	wg.Wait()
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(logsCmd.PersistentFlags(), "namespace")
+	logsCmd.PersistentFlags().StringVar(&logsComponent, "component", logsComponent, "Component to print the logs of, e.g. webserver, scan or jobrunner (default all components)")
+	logsCmd.PersistentFlags().BoolVarP(&logsFollow, "follow", "f", logsFollow, "If true, stream the logs until synopsysctl is interrupted")
+	logsCmd.PersistentFlags().DurationVar(&logsSince, "since", logsSince, "Only print the logs newer than the duration, e.g. 5s, 2m or 1h (default all logs)")
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+		{app: globals.BDBAName, name: "BDBA"},
+	} {
+		logsCmd.AddCommand(newLogsCmd(product.app, product.name))
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/datamover"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Migrate Command Defaults
+var migrateAlertLegacyPVCName = ""
+var migrateAlertPVCName = ""
+var migrateTimeout = 10 * time.Minute
+var migrateVolumeDataMover = "rsync"
+var migrateVolumeTargetKubeConfigPath = ""
+var migrateVolumeOptions = datamover.Options{Timeout: 2 * time.Hour, Image: globals.DefaultRsyncImage}
+
+// migrateCmd migrates the resources of a Synopsys instance that was deployed by an older version, or moves an instance
+// to another cluster
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the resources of a Synopsys instance deployed by an older version, or move it to another cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// migrateAlertPVCCmd rebinds the data of an Alert instance from the legacy persistent volume claim to the chart's claim
+var migrateAlertPVCCmd = &cobra.Command{
+	Use:           "alert-pvc NAME -n NAMESPACE",
+	Example:       "synopsysctl migrate alert-pvc <name> -n <namespace>\nsynopsysctl migrate alert-pvc <name> -n <namespace> --legacy-pvc-name alert-pvc",
+	Short:         "Move the data of an Alert instance from the legacy persistent volume claim to the chart's claim",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alertName := args[0]
+		helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
+
+		instance, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("couldn't find instance '%s' in namespace '%s'", alertName, namespace)
+		}
+		helmValuesMap := instance.Config
+
+		// the operator migration keeps the legacy claim by setting its name in the Helm values
+		legacyPVCName := migrateAlertLegacyPVCName
+		if len(legacyPVCName) == 0 {
+			if value, ok := util.GetHelmValueFromMap(helmValuesMap, []string{"alert", "persistentVolumeClaimName"}).(string); ok {
+				legacyPVCName = value
+			}
+		}
+		if len(legacyPVCName) == 0 {
+			return fmt.Errorf("instance '%s' in namespace '%s' doesn't use a legacy persistent volume claim, use --legacy-pvc-name to set it", alertName, namespace)
+		}
+		pvcName := migrateAlertPVCName
+		if len(pvcName) == 0 {
+			pvcName = fmt.Sprintf("%s-pvc", helmReleaseName)
+		}
+		if legacyPVCName == pvcName {
+			log.Infof("Alert '%s' in namespace '%s' already uses persistent volume claim '%s'", alertName, namespace, pvcName)
+			return nil
+		}
+
+		// Update the Helm Chart Location
+		alertVersionFromRelease := util.GetValueFromRelease(instance, []string{"alert", "imageTag"}).(string)
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.AlertChartName, alertVersionFromRelease, &globals.AlertChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		if err := confirmDestructiveAction(fmt.Sprintf("this will stop Alert '%s' in namespace '%s' and move its data from persistent volume claim '%s' to '%s'", alertName, namespace, legacyPVCName, pvcName)); err != nil {
+			return err
+		}
+
+		// the claim is rebound after the Helm upgrade that stops Alert, which ends the plan
+		skipForPlan(util.PlanActionCreate, "PersistentVolumeClaim", pvcName, namespace)
+
+		log.Infof("stopping Alert '%s' in namespace '%s'", alertName, namespace)
+		status := util.GetHelmValueFromMap(helmValuesMap, []string{"status"})
+		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
+		if err := util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to stop Alert: %+v", cleanErrorMsg))
+		}
+		if err := waitForPodsToReleasePVC(namespace, legacyPVCName, migrateTimeout); err != nil {
+			return err
+		}
+
+		if err := util.RebindPersistentVolumeClaim(kubeClient, namespace, legacyPVCName, pvcName, migrateTimeout); err != nil {
+			return fmt.Errorf("failed to move the data of Alert '%s' to persistent volume claim '%s', run the command again to resume: %w", alertName, pvcName, err)
+		}
+
+		log.Infof("starting Alert '%s' in namespace '%s' with persistent volume claim '%s'", alertName, namespace, pvcName)
+		util.SetHelmValueInMap(helmValuesMap, []string{"alert", "persistentVolumeClaimName"}, pvcName)
+		if status == nil || status == "Stopped" {
+			status = "Running"
+		}
+		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, status)
+		if err := util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+			return util.WithExitCode(util.ExitCodePartialSuccess, fmt.Errorf("the data was moved to persistent volume claim '%s' but Alert failed to start: %+v", pvcName, cleanErrorMsg))
+		}
+		log.Infof("Alert '%s' in namespace '%s' has been successfully migrated to persistent volume claim '%s'", alertName, namespace, pvcName)
+		return nil
+	},
+}
+
+// migrateVolumeCmd copies the data of a persistent volume claim into a claim in another namespace or cluster
+var migrateVolumeCmd = &cobra.Command{
+	Use:           "volume SOURCE_NAMESPACE/PVC TARGET_NAMESPACE/PVC",
+	Example:       "synopsysctl migrate volume <namespace>/<pvc> <namespace>/<pvc>\nsynopsysctl migrate volume <namespace>/<pvc> <namespace>/<pvc> --target-kubeconfig <path> --data-mover cloud-snapshot",
+	Short:         "Copy the data of a persistent volume claim into a claim in another namespace or cluster",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 2, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := parseVolume(args[0])
+		if err != nil {
+			return err
+		}
+		target, err := parseVolume(args[1])
+		if err != nil {
+			return err
+		}
+		if _, err := datamover.GetProvider(migrateVolumeDataMover); err != nil {
+			return util.ValidationError("%+v", err)
+		}
+		source.KubeClient, source.RestConfig = kubeClient, restconfig
+		target.KubeClient, target.RestConfig = kubeClient, restconfig
+		if cmd.Flags().Lookup("target-kubeconfig").Changed {
+			target.RestConfig, err = GetKubeClientFromOutsideCluster(migrateVolumeTargetKubeConfigPath, insecureSkipTLSVerify)
+			if err != nil {
+				return fmt.Errorf("failed to load the target kubeconfig '%s' due to %+v", migrateVolumeTargetKubeConfigPath, err)
+			}
+			target.KubeClient, err = getKubeClient(target.RestConfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to the target cluster due to %+v", err)
+			}
+		} else if source.String() == target.String() {
+			return util.ValidationError("the source and target persistent volume claims must be different")
+		}
+
+		if err := confirmDestructiveAction(fmt.Sprintf("this will overwrite the data of persistent volume claim '%s' with the data of '%s'", target, source)); err != nil {
+			return err
+		}
+		if err := waitForPodsToReleasePVC(source.Namespace, source.ClaimName, migrateTimeout); err != nil {
+			return err
+		}
+		if err := datamover.Move(migrateVolumeDataMover, source, target, migrateVolumeOptions); err != nil {
+			return err
+		}
+		log.Infof("the data of persistent volume claim '%s' has been successfully copied to '%s'", source, target)
+		return nil
+	},
+}
+
+// migrateBlackDuckCmd moves a Black Duck instance to another cluster
+var migrateBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --to-cluster CONTEXT",
+	Example:       "synopsysctl migrate blackduck <name> -n <namespace> --to-cluster <context>\nsynopsysctl migrate blackduck <name> -n <namespace> --to-cluster <context> --to-namespace <namespace> --backup-dir ./backups --decommission-source",
+	Short:         "Move a Black Duck instance with its data to another cluster",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := datamover.GetProvider(migrateVolumeDataMover); err != nil {
+			return util.ValidationError("%+v", err)
+		}
+		targetNamespace := moveToNamespace
+		if len(targetNamespace) == 0 {
+			targetNamespace = namespace
+		}
+		return moveBlackDuckToCluster(cmd.Flags(), args[0], namespace, moveToContext, targetNamespace)
+	},
+}
+
+// parseVolume parses a NAMESPACE/PVC argument
+func parseVolume(arg string) (datamover.Volume, error) {
+	parts := strings.Split(arg, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return datamover.Volume{}, util.ValidationError("'%s' must have the format NAMESPACE/PVC", arg)
+	}
+	return datamover.Volume{Namespace: parts[0], ClaimName: parts[1]}, nil
+}
+
+// waitForPodsToReleasePVC waits until no pod in the namespace mounts the persistent volume claim
+func waitForPodsToReleasePVC(namespace string, pvcName string, timeout time.Duration) error {
+	log.Infof("waiting for the pods using persistent volume claim '%s' to terminate", pvcName)
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		pods, err := util.ListPods(kubeClient, namespace)
+		if err != nil {
+			return false, nil
+		}
+		for _, pod := range pods.Items {
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("pods in namespace '%s' still use persistent volume claim '%s': %w", namespace, pvcName, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	addConfirmationFlag(migrateCmd)
+
+	migrateAlertPVCCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(migrateAlertPVCCmd.Flags(), "namespace")
+	migrateAlertPVCCmd.Flags().StringVar(&migrateAlertLegacyPVCName, "legacy-pvc-name", migrateAlertLegacyPVCName, "Name of the legacy persistent volume claim (default the claim set in the instance's values)")
+	migrateAlertPVCCmd.Flags().StringVar(&migrateAlertPVCName, "pvc-name", migrateAlertPVCName, "Name of the new persistent volume claim (default <name>-alert-pvc)")
+	migrateAlertPVCCmd.Flags().DurationVar(&migrateTimeout, "timeout", migrateTimeout, "Time to wait for the pods to stop and the claims to be deleted or bound")
+	addChartLocationPathFlag(migrateAlertPVCCmd)
+	// the volume moves don't plan their changes, so only the migrations of the instances have --plan
+	addPlanFlags(migrateAlertPVCCmd)
+	migrateCmd.AddCommand(migrateAlertPVCCmd)
+
+	migrateVolumeCmd.Flags().StringVar(&migrateVolumeDataMover, "data-mover", migrateVolumeDataMover, fmt.Sprintf("How to copy the data [%s]", strings.Join(datamover.GetProviderNames(), "|")))
+	migrateVolumeCmd.Flags().StringVar(&migrateVolumeTargetKubeConfigPath, "target-kubeconfig", migrateVolumeTargetKubeConfigPath, "Path to the kubeconfig of the target cluster (default the current cluster)")
+	migrateVolumeCmd.Flags().StringVar(&migrateVolumeOptions.StorageClass, "storage-class", migrateVolumeOptions.StorageClass, "Storage class of the target claim if it doesn't exist (default the storage class of the source claim)")
+	migrateVolumeCmd.Flags().StringVar(&migrateVolumeOptions.SnapshotClass, "snapshot-class", migrateVolumeOptions.SnapshotClass, "Volume snapshot class used by the csi-snapshot data mover (default the cluster's default class)")
+	migrateVolumeCmd.Flags().StringVar(&migrateVolumeOptions.Image, "image", migrateVolumeOptions.Image, "Image of the pods used by the rsync data mover")
+	migrateVolumeCmd.Flags().DurationVar(&migrateVolumeOptions.Timeout, "timeout", migrateVolumeOptions.Timeout, "Time to wait for the data to be copied")
+	migrateCmd.AddCommand(migrateVolumeCmd)
+
+	migrateBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(migrateBlackDuckCmd.Flags(), "namespace")
+	migrateBlackDuckCmd.Flags().StringVar(&moveToContext, "to-cluster", moveToContext, "Context of the kubeconfig of the target cluster")
+	cobra.MarkFlagRequired(migrateBlackDuckCmd.Flags(), "to-cluster")
+	migrateBlackDuckCmd.Flags().StringVar(&moveToKubeConfigPath, "to-kubeconfig", moveToKubeConfigPath, "Path to the kubeconfig of the target context (default the kubeconfig of the source)")
+	migrateBlackDuckCmd.Flags().StringVar(&moveToNamespace, "to-namespace", moveToNamespace, "Namespace of the instance in the target cluster (default the namespace of the source)")
+	migrateBlackDuckCmd.Flags().StringVar(&moveBackupDir, "backup-dir", moveBackupDir, "Directory to keep the backup of the databases in (default a temporary directory that is removed)")
+	migrateBlackDuckCmd.Flags().BoolVar(&moveDecommissionSource, "decommission-source", moveDecommissionSource, "If true, delete the source instance and its volumes once the target passes the verification checks")
+	migrateBlackDuckCmd.Flags().StringVar(&migrateVolumeDataMover, "data-mover", migrateVolumeDataMover, fmt.Sprintf("How to copy the volumes other than the database [%s]", strings.Join(datamover.GetProviderNames(), "|")))
+	migrateBlackDuckCmd.Flags().StringVar(&migrateVolumeOptions.StorageClass, "storage-class", migrateVolumeOptions.StorageClass, "Storage class of the target claims that don't exist (default the storage class of the source claims)")
+	migrateBlackDuckCmd.Flags().DurationVar(&migrateTimeout, "timeout", migrateTimeout, "Time to wait for the pods to release the persistent volume claims")
+	addChartLocationPathFlag(migrateBlackDuckCmd)
+	addPlanFlags(migrateBlackDuckCmd)
+	migrateCmd.AddCommand(migrateBlackDuckCmd)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Mirror Images Command Options and Defaults
+var mirrorImagesVersion = ""
+var mirrorImagesRegistry = ""
+var mirrorImagesSkipVerify = false
+var mirrorImagesValuesFile = ""
+var mirrorImagesPasswordFile = ""
+
+// getMirrorRegistryValuePaths returns the paths of the Helm values of the registries of the images of a product
+func getMirrorRegistryValuePaths(app string) ([][]string, error) {
+	switch app {
+	case util.BlackDuckName:
+		// the values set by 'create blackduck --registry'
+		return [][]string{{"registry"}, {"postgres", "registry"}, {"binaryscanner", "registry"}}, nil
+	case util.AlertName, util.OpsSightName:
+		return [][]string{{"registry"}}, nil
+	}
+	return nil, fmt.Errorf("the chart of %s has no registry value", app)
+}
+
+// mirrorImagesCmd rewrites the images of a product to a private registry and verifies that they were mirrored
+var mirrorImagesCmd = &cobra.Command{
+	Use:           "mirror-images PRODUCT --registry REGISTRY [--version VERSION]",
+	Example:       "synopsysctl mirror-images blackduck --version 2020.6.0 --registry registry.example.com/synopsys\nsynopsysctl mirror-images alert --registry registry.example.com/synopsys --output-values-file mirror.yaml",
+	Short:         "Rewrite the images of a product to a private registry and verify that they exist in it",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Annotations:   map[string]string{offlineCommandAnnotation: "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		// accept the product aliases, e.g. 'mirror-images bd'
+		if product := getProductName(args[0]); len(product) > 0 {
+			args[0] = product
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(mirrorImagesPasswordFile) > 0 {
+			password, err := util.ReadFileData(mirrorImagesPasswordFile)
+			if err != nil {
+				return err
+			}
+			util.RegistryPassword = strings.TrimSpace(password)
+		}
+		chart, err := getLintChart(args[0])
+		if err != nil {
+			return err
+		}
+		if err := UpdateHelmChartLocation(cmd.Flags(), chart.chartName, mirrorImagesVersion, chart.chartRepository); err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+		images, err := getChartImages(args[0], chart, *chart.chartRepository)
+		if err != nil {
+			return err
+		}
+
+		table := util.NewTable(util.TableColumn{Name: "IMAGE"}, util.TableColumn{Name: "MIRROR"}, util.TableColumn{Name: "STATUS"})
+		missing := 0
+		for _, image := range images {
+			mirror := util.RewriteImageRegistry(image, mirrorImagesRegistry)
+			status := "not verified"
+			if !mirrorImagesSkipVerify {
+				exists, err := util.ImageExists(mirror)
+				switch {
+				case err != nil:
+					status = fmt.Sprintf("error: %+v", err)
+					missing++
+				case exists:
+					status = "found"
+				default:
+					status = "missing"
+					missing++
+				}
+			}
+			table.AddRow(image, mirror, status)
+		}
+		if err := table.Print(os.Stdout, tableOptions("")); err != nil {
+			return err
+		}
+
+		paths, err := getMirrorRegistryValuePaths(args[0])
+		if err != nil {
+			log.Warnf("%+v, set the registries of the images in the Helm values of the product", err)
+		} else {
+			values := map[string]interface{}{}
+			sets := []string{}
+			for _, path := range paths {
+				util.SetHelmValueInMap(values, path, strings.TrimSuffix(mirrorImagesRegistry, "/"))
+				sets = append(sets, fmt.Sprintf("--set %s=%s", strings.Join(path, "."), strings.TrimSuffix(mirrorImagesRegistry, "/")))
+			}
+			if len(mirrorImagesValuesFile) > 0 {
+				data, err := yaml.Marshal(values)
+				if err != nil {
+					return err
+				}
+				if err := ioutil.WriteFile(mirrorImagesValuesFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write the values file '%s' due to %+v", mirrorImagesValuesFile, err)
+				}
+				log.Infof("wrote the registry values to '%s', use it with --values", mirrorImagesValuesFile)
+			} else {
+				fmt.Printf("\nHelm values of the mirror:\n  %s\n", strings.Join(sets, " "))
+			}
+		}
+
+		if missing > 0 {
+			return fmt.Errorf("%d of %d images are missing in '%s'", missing, len(images), mirrorImagesRegistry)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorImagesCmd)
+
+	mirrorImagesCmd.Flags().StringVar(&mirrorImagesVersion, "version", mirrorImagesVersion, "Version of the product (default latest)")
+	mirrorImagesCmd.Flags().StringVar(&mirrorImagesRegistry, "registry", mirrorImagesRegistry, "Registry and repository prefix the images are mirrored to, e.g. registry.example.com/synopsys")
+	cobra.MarkFlagRequired(mirrorImagesCmd.Flags(), "registry")
+	mirrorImagesCmd.Flags().BoolVar(&mirrorImagesSkipVerify, "skip-verify", mirrorImagesSkipVerify, "If true, don't check that the rewritten images exist in the registry")
+	mirrorImagesCmd.Flags().StringVar(&mirrorImagesValuesFile, "output-values-file", mirrorImagesValuesFile, "Path of a Helm values file to write the registry values to instead of printing them")
+	mirrorImagesCmd.Flags().StringVar(&util.RegistryUsername, "registry-username", util.RegistryUsername, "Username of the registry, the images are checked anonymously if not set")
+	mirrorImagesCmd.Flags().StringVar(&mirrorImagesPasswordFile, "registry-password-file", mirrorImagesPasswordFile, "Path to a file containing the password of the registry")
+	addChartLocationPathFlag(mirrorImagesCmd)
+	addTableFlags(mirrorImagesCmd, "Sort the images by the column, e.g. status")
+}
@@ -0,0 +1,349 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Preview Command Options and Defaults
+var previewNamespace = ""
+var previewTTL = 4 * time.Hour
+var previewJanitorImage = globals.DefaultKubectlImage
+var previewJanitorSchedule = "*/10 * * * *"
+var previewDeleteWait = false
+var previewDeleteTimeout = 5 * time.Minute
+
+// previewLabel marks a namespace that was created by 'synopsysctl preview create'
+const previewLabel = "synopsys.com/preview"
+
+// previewJanitorName is the name of the resources that delete the preview namespace once it expires
+const previewJanitorName = "synopsysctl-preview-janitor"
+
+// previewOutput is printed as JSON after a preview instance was created
+type previewOutput struct {
+	Product   string `json:"product"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Expiry    string `json:"expiry"`
+	URL       string `json:"url"`
+}
+
+// previewCmd manages ephemeral preview environments
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Manage ephemeral preview instances that are deleted with their namespace once they expire",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// previewCreateCmd creates an ephemeral preview instance
+var previewCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an ephemeral preview instance in a new namespace",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// previewCreateAlertCmd creates an ephemeral Alert preview instance
+var previewCreateAlertCmd = &cobra.Command{
+	Use:           "alert NAME",
+	Example:       "synopsysctl preview create alert <name> --ttl 4h --postgres-password <password>",
+	Short:         "Create an ephemeral Alert preview instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          createAlertCmd.Args,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return createAlertCmd.PreRunE(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPreviewCreate(cmd, args, util.AlertName, createAlertCmd.RunE)
+	},
+}
+
+// previewCreateBlackDuckCmd creates an ephemeral Black Duck preview instance
+var previewCreateBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME",
+	Example:       "synopsysctl preview create blackduck <name> --ttl 4h --admin-password <password> --user-password <password> --seal-key <key>",
+	Short:         "Create an ephemeral Black Duck preview instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          createBlackDuckCmd.Args,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return createBlackDuckCmd.PreRunE(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPreviewCreate(cmd, args, util.BlackDuckName, createBlackDuckCmd.RunE)
+	},
+}
+
+// previewDeleteCmd deletes a preview namespace before it expires
+var previewDeleteCmd = &cobra.Command{
+	Use:           "delete NAMESPACE",
+	Example:       "synopsysctl preview delete <namespace>",
+	Short:         "Delete a preview namespace and everything in it",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ns, err := util.GetNamespace(kubeClient, args[0])
+		if err != nil {
+			return fmt.Errorf("unable to find namespace '%s' due to %+v", args[0], err)
+		}
+		if ns.Labels[previewLabel] != "true" {
+			return fmt.Errorf("namespace '%s' was not created by 'synopsysctl preview create'", args[0])
+		}
+		if err := confirmDestructiveAction(fmt.Sprintf("this will delete namespace '%s' and everything in it", args[0])); err != nil {
+			return err
+		}
+		if err := util.DeleteNamespace(kubeClient, args[0]); err != nil {
+			return fmt.Errorf("unable to delete namespace '%s' due to %+v", args[0], err)
+		}
+		if previewDeleteWait {
+			ctx, cancel := newInterruptibleContext()
+			defer cancel()
+			log.Infof("waiting for preview namespace '%s' to terminate", args[0])
+			if err := util.WaitForNamespaceToTerminate(ctx, kubeClient, args[0], previewDeleteTimeout); err != nil {
+				return err
+			}
+		}
+		log.Infof("preview namespace '%s' has been successfully Deleted!", args[0])
+		return nil
+	},
+}
+
+// runPreviewCreate creates the preview namespace and its janitor, then runs the create command of the product
+// in POC mode. The namespace is deleted again if the instance cannot be created
+func runPreviewCreate(cmd *cobra.Command, args []string, appName string, createFunc func(cmd *cobra.Command, args []string) error) error {
+	if previewTTL <= 0 {
+		return fmt.Errorf("--ttl must be greater than 0")
+	}
+	name := args[0]
+	namespace = getPreviewNamespace(name, previewNamespace)
+	expiry := time.Now().Add(previewTTL)
+
+	// Create the namespace that contains everything of the preview instance
+	if _, err := util.GetNamespace(kubeClient, namespace); err == nil {
+		return fmt.Errorf("namespace '%s' already exists, preview instances require a new namespace", namespace)
+	}
+	labels := util.GetPOCLabels(expiry)
+	labels[previewLabel] = "true"
+	ns, err := createNamespace(namespace, labels)
+	if err != nil {
+		return err
+	}
+
+	if err := createPreviewJanitor(kubeClient, ns, expiry); err != nil {
+		deletePreviewNamespace(namespace)
+		return err
+	}
+
+	// Deploy the instance with the minimal footprint of a trial instance
+	pocMode = true
+	pocTTL = previewTTL
+	if err := createFunc(cmd, args); err != nil {
+		deletePreviewNamespace(namespace)
+		return err
+	}
+
+	url, err := getExposedURL(appName, name, namespace)
+	if err != nil {
+		log.Warnf("unable to determine the URL of the preview instance: %+v", err)
+	}
+	output := previewOutput{
+		Product:   appName,
+		Name:      name,
+		Namespace: namespace,
+		Expiry:    expiry.UTC().Format(time.RFC3339),
+		URL:       url,
+	}
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to convert the preview output to json: %+v", err)
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// createPreviewJanitor creates a CronJob in the preview namespace that deletes the namespace once it expires.
+// The cluster scoped RBAC resources are owned by the namespace, so they are garbage collected with it
+func createPreviewJanitor(kubeClient kubernetes.Interface, ns *corev1.Namespace, expiry time.Time) error {
+	ownerReferences := []metav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       ns.Name,
+			UID:        ns.UID,
+		},
+	}
+	clusterRoleName := fmt.Sprintf("%s-%s", previewJanitorName, ns.Name)
+
+	if _, err := kubeClient.CoreV1().ServiceAccounts(ns.Name).Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: previewJanitorName, Namespace: ns.Name},
+	}); err != nil {
+		return fmt.Errorf("unable to create the preview janitor service account due to %+v", err)
+	}
+
+	if _, err := kubeClient.RbacV1().ClusterRoles().Create(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName, OwnerReferences: ownerReferences},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"namespaces"},
+				ResourceNames: []string{ns.Name},
+				Verbs:         []string{"get", "delete"},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("unable to create the preview janitor cluster role due to %+v", err)
+	}
+
+	if _, err := kubeClient.RbacV1().ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName, OwnerReferences: ownerReferences},
+		Subjects: []rbacv1.Subject{
+			{Kind: "ServiceAccount", Name: previewJanitorName, Namespace: ns.Name},
+		},
+		RoleRef: rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: clusterRoleName},
+	}); err != nil {
+		return fmt.Errorf("unable to create the preview janitor cluster role binding due to %+v", err)
+	}
+
+	if _, err := kubeClient.BatchV1beta1().CronJobs(ns.Name).Create(&batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: previewJanitorName, Namespace: ns.Name},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          previewJanitorSchedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							ServiceAccountName: previewJanitorName,
+							Containers: []corev1.Container{
+								{
+									Name:    "janitor",
+									Image:   previewJanitorImage,
+									Command: []string{"/bin/sh", "-c", getPreviewJanitorScript(ns.Name, expiry)},
+								},
+							},
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+						},
+					},
+				},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("unable to create the preview janitor cron job due to %+v", err)
+	}
+	return nil
+}
+
+// getPreviewNamespace returns the namespace of a preview instance, preview-NAME unless --namespace is set
+func getPreviewNamespace(name string, namespace string) string {
+	if len(namespace) > 0 {
+		return namespace
+	}
+	return fmt.Sprintf("preview-%s", name)
+}
+
+// getPreviewJanitorScript returns the script of the janitor that deletes the preview namespace once it expires
+func getPreviewJanitorScript(namespace string, expiry time.Time) string {
+	return fmt.Sprintf(`if [ "$(date +%%s)" -ge "%d" ]; then kubectl delete namespace %s --wait=false; fi`, expiry.Unix(), namespace)
+}
+
+// deletePreviewNamespace removes a preview namespace after a failed create
+func deletePreviewNamespace(namespace string) {
+	log.Infof("cleaning up preview namespace '%s'", namespace)
+	if err := util.DeleteNamespace(kubeClient, namespace); err != nil {
+		log.Errorf("unable to delete preview namespace '%s' due to %+v", namespace, err)
+	}
+}
+
+// getExposedURL returns the URL of the exposed user interface of an instance, or an empty string if it
+// is not exposed
+func getExposedURL(appName string, name string, namespace string) (string, error) {
+	if util.IsOpenshift(kubeClient) {
+		routeClient := util.GetRouteClient(restconfig, kubeClient, namespace)
+		if route, err := util.GetRoute(routeClient, namespace, util.GetResourceName(name, appName, "")); err == nil {
+			return fmt.Sprintf("https://%s", route.Spec.Host), nil
+		}
+	}
+
+	serviceName := util.GetResourceName(name, appName, "exposed")
+	if appName == util.BlackDuckName {
+		serviceName = util.GetResourceName(name, appName, "webserver-exposed")
+	}
+	svc, err := util.GetService(kubeClient, namespace, serviceName)
+	if err != nil {
+		return "", nil
+	}
+	endpoints, err := util.GetServiceEndpoints(kubeClient, svc)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s", endpoints[0]), nil
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+	previewCmd.AddCommand(previewCreateCmd)
+	addConfirmationFlag(previewDeleteCmd)
+	previewDeleteCmd.Flags().BoolVar(&previewDeleteWait, "wait", previewDeleteWait, "If true, wait until the namespace has terminated")
+	previewDeleteCmd.Flags().DurationVar(&previewDeleteTimeout, "timeout", previewDeleteTimeout, "Maximum time to wait for the namespace to terminate")
+	previewCmd.AddCommand(previewDeleteCmd)
+
+	for _, cmd := range []*cobra.Command{previewCreateAlertCmd, previewCreateBlackDuckCmd} {
+		cmd.Flags().StringVarP(&previewNamespace, "namespace", "n", previewNamespace, "Namespace of the preview instance (default preview-<name>)")
+		cmd.Flags().DurationVar(&previewTTL, "ttl", previewTTL, "Time until the preview namespace is deleted [e.g. 4h, 72h]")
+		cmd.Flags().StringVar(&previewJanitorImage, "janitor-image", previewJanitorImage, "Image with kubectl used by the CronJob that deletes the expired preview namespace")
+		addOpenShiftProjectFlags(cmd)
+	}
+
+	createAlertCobraHelper.AddCobraFlagsToCommand(previewCreateAlertCmd, true)
+	addChartLocationPathFlag(previewCreateAlertCmd)
+	previewCreateCmd.AddCommand(previewCreateAlertCmd)
+
+	createBlackDuckCobraHelper.AddCobraFlagsToCommand(previewCreateBlackDuckCmd, true)
+	addChartLocationPathFlag(previewCreateBlackDuckCmd)
+	previewCreateCmd.AddCommand(previewCreateBlackDuckCmd)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetPreviewNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("preview-bd", getPreviewNamespace("bd", ""))
+	assert.Equal("pr-42", getPreviewNamespace("bd", "pr-42"))
+}
+
+func TestGetPreviewJanitorScript(t *testing.T) {
+	assert := assert.New(t)
+
+	expiry := time.Unix(1600000000, 0)
+	assert.Equal(`if [ "$(date +%s)" -ge "1600000000" ]; then kubectl delete namespace preview-bd --wait=false; fi`, getPreviewJanitorScript("preview-bd", expiry))
+}
+
+func TestCreatePreviewJanitor(t *testing.T) {
+	assert := assert.New(t)
+	kubeClient := fake.NewSimpleClientset()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "preview-bd", UID: types.UID("uid")}}
+	expiry := time.Unix(1600000000, 0)
+
+	assert.NoError(createPreviewJanitor(kubeClient, ns, expiry))
+
+	_, err := kubeClient.CoreV1().ServiceAccounts("preview-bd").Get(previewJanitorName, metav1.GetOptions{})
+	assert.NoError(err)
+
+	// the cluster scoped resources are owned by the namespace and may only delete it
+	clusterRole, err := kubeClient.RbacV1().ClusterRoles().Get("synopsysctl-preview-janitor-preview-bd", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal([]metav1.OwnerReference{{APIVersion: "v1", Kind: "Namespace", Name: "preview-bd", UID: types.UID("uid")}}, clusterRole.OwnerReferences)
+	assert.Len(clusterRole.Rules, 1)
+	assert.Equal([]string{"preview-bd"}, clusterRole.Rules[0].ResourceNames)
+	clusterRoleBinding, err := kubeClient.RbacV1().ClusterRoleBindings().Get("synopsysctl-preview-janitor-preview-bd", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(clusterRole.OwnerReferences, clusterRoleBinding.OwnerReferences)
+
+	cronJob, err := kubeClient.BatchV1beta1().CronJobs("preview-bd").Get(previewJanitorName, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(previewJanitorSchedule, cronJob.Spec.Schedule)
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(previewJanitorName, podSpec.ServiceAccountName)
+	assert.Equal([]string{"/bin/sh", "-c", getPreviewJanitorScript("preview-bd", expiry)}, podSpec.Containers[0].Command)
+
+	// the janitor is only created once with the namespace
+	assert.Error(createPreviewJanitor(kubeClient, ns, expiry))
+}
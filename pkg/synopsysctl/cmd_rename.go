@@ -0,0 +1,320 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// blackDuckClaimHelmPaths are the Helm values of the persistent volume claims of the chart, by the name of the claim
+// without the name of the instance
+var blackDuckClaimHelmPaths = map[string][]string{
+	"blackduck-postgres":         {"postgres"},
+	"blackduck-authentication":   {"authentication"},
+	"blackduck-cfssl":            {"cfssl"},
+	"blackduck-registration":     {"registration"},
+	"blackduck-webapp":           {"webapp"},
+	"blackduck-logstash":         {"logstash"},
+	"blackduck-uploadcache-data": {"uploadcache"},
+}
+
+// renameCmd renames a Synopsys resource
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// renameBlackDuckCmd renames a Black Duck instance
+var renameBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME NEW_NAME -n NAMESPACE",
+	Example:       "synopsysctl rename blackduck <name> <new name> -n <namespace>",
+	Short:         "Rename a Black Duck instance, keeping its data, secrets and exposed endpoints",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 2, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := renameBlackDuck(cmd.Flags(), args[0], args[1], namespace); err != nil {
+			return err
+		}
+		log.Infof("Black Duck '%s' has been successfully renamed to '%s'", args[0], args[1])
+		return nil
+	},
+}
+
+// renameBlackDuck re-creates the Helm release of a Black Duck instance under the new name, Helm can't rename a release.
+// The persistent volumes are rebound to claims of the new name, the secrets that synopsysctl created are copied to the
+// new name and the exposure objects that the chart doesn't manage are re-labeled. The node port of the exposed service
+// is kept
+func renameBlackDuck(flags *pflag.FlagSet, name string, newName string, namespace string) error {
+	if err := validateDNSLabel(newName); err != nil {
+		return util.ValidationError("%+v", err)
+	}
+	if name == newName {
+		return util.ValidationError("the new name of Black Duck '%s' must be different", name)
+	}
+	rel, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	if existing, err := util.GetWithHelm3(newName, namespace, kubeConfigPath); err == nil {
+		if err := util.CheckReleaseNameCollision(existing, globals.BlackDuckChartName); err != nil {
+			return util.ValidationError("%+v", err)
+		}
+	}
+	version, _ := util.GetValueFromRelease(rel, []string{"imageTag"}).(string)
+	if err := UpdateHelmChartLocation(flags, globals.BlackDuckChartName, version, &globals.BlackDuckChartRepository); err != nil {
+		return fmt.Errorf("failed to set the app resources location due to %+v", err)
+	}
+	helmValuesMap := rel.Config
+	if helmValuesMap == nil {
+		helmValuesMap = map[string]interface{}{}
+	}
+	labelSelector := fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name)
+
+	// the claims of the chart are replaced by claims of the new name that the new release uses as custom claims
+	claims, err := util.ListPVCs(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list the persistent volume claims of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	claimNames := map[string]string{}
+	claimHelmPaths := map[string][]string{}
+	customClaims := []corev1.PersistentVolumeClaim{}
+	for _, claim := range claims.Items {
+		newClaimName, renamed := util.RenamedResourceName(claim.Name, name, newName)
+		if !renamed {
+			// the claim isn't named after the instance, it is only re-labeled
+			customClaims = append(customClaims, claim)
+			continue
+		}
+		path, ok := getClaimHelmPath(helmValuesMap, name, claim.Name)
+		if !ok {
+			return util.ValidationError("persistent volume claim '%s' of Black Duck '%s' isn't a claim of the chart", claim.Name, name)
+		}
+		claimNames[claim.Name] = newClaimName
+		claimHelmPaths[newClaimName] = path
+	}
+
+	if err := confirmDestructiveAction(fmt.Sprintf("this will stop Black Duck '%s' in namespace '%s' and re-create it as '%s'", name, namespace, newName)); err != nil {
+		return err
+	}
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+
+	// the pods tell which secrets the instance mounts, they are collected before it is stopped
+	instanceSecrets, err := getInstanceSecrets(util.BlackDuckName, name, namespace)
+	if err != nil {
+		return err
+	}
+	nodePort := getExposedNodePort(name, namespace, helmValuesMap)
+
+	util.EmitProgress(util.ProgressPhaseMigrate, "stop", 0, "stopping Black Duck '%s'", name)
+	startOld, err := stopDeployments(logger, namespace, labelSelector)
+	if err != nil {
+		startOld()
+		return err
+	}
+	for claimName := range claimNames {
+		if err := waitForPodsToReleasePVC(namespace, claimName, migrateTimeout); err != nil {
+			startOld()
+			return err
+		}
+	}
+
+	util.EmitProgress(util.ProgressPhaseMigrate, "volumes", 20, "moving %d persistent volume claims of Black Duck '%s'", len(claimNames), name)
+	for claimName, newClaimName := range claimNames {
+		if err := util.RebindPersistentVolumeClaim(kubeClient, namespace, claimName, newClaimName, migrateTimeout); err != nil {
+			return fmt.Errorf("failed to move persistent volume claim '%s' to '%s', Black Duck '%s' is stopped and the data is kept in the persistent volume: %w", claimName, newClaimName, name, err)
+		}
+		claim, err := util.GetPVC(kubeClient, namespace, newClaimName)
+		if err != nil {
+			return fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", newClaimName, namespace, err)
+		}
+		claim.Labels = util.RenamedLabels(claim.Labels, newName)
+		if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Update(claim); err != nil {
+			return fmt.Errorf("unable to update the labels of persistent volume claim '%s' in namespace '%s' due to %+v", newClaimName, namespace, err)
+		}
+		util.SetHelmValueInMap(helmValuesMap, append(claimHelmPaths[newClaimName], "persistentVolumeClaimName"), newClaimName)
+	}
+	for i := range customClaims {
+		claim := &customClaims[i]
+		claim.Labels = util.RenamedLabels(claim.Labels, newName)
+		if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Update(claim); err != nil {
+			return fmt.Errorf("unable to update the labels of persistent volume claim '%s' in namespace '%s' due to %+v", claim.Name, namespace, err)
+		}
+	}
+
+	util.EmitProgress(util.ProgressPhaseMigrate, "secrets", 40, "copying the secrets of Black Duck '%s'", name)
+	secrets := []*corev1.Secret{}
+	for _, instanceSecret := range instanceSecrets {
+		secrets = append(secrets, instanceSecret.Secret)
+	}
+	oldSecretNames := []string{}
+	for _, secret := range util.GetSecretsForCluster(secrets, namespace) {
+		newSecretName, renamed := util.RenamedResourceName(secret.Name, name, newName)
+		if renamed {
+			oldSecretNames = append(oldSecretNames, secret.Name)
+		} else if secret.Labels["name"] != name {
+			// the secret isn't owned by the instance, e.g. a shared pull secret
+			continue
+		}
+		secret.Name = newSecretName
+		secret.Labels = util.RenamedLabels(secret.Labels, newName)
+		existing, err := util.GetSecret(kubeClient, namespace, secret.Name)
+		switch {
+		case k8serrors.IsNotFound(err):
+			_, err = kubeClient.CoreV1().Secrets(namespace).Create(secret)
+		case err == nil:
+			existing.Labels = secret.Labels
+			existing.Data = secret.Data
+			_, err = util.UpdateSecret(kubeClient, namespace, existing)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to create secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+		}
+	}
+	util.RenameHelmValueReferences(helmValuesMap, name, newName)
+	if nodePort > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"exposedNodePort"}, fmt.Sprintf("%d", nodePort))
+	}
+
+	// the exposure objects that synopsysctl created are re-labeled so that deleting the old release keeps them
+	exposure := blackduck.GetExposure(namespace, newName)
+	exposure.PreviousNames = []string{name}
+	if err := util.ReconcileExposure(restconfig, kubeClient, exposure); err != nil {
+		return err
+	}
+
+	util.EmitProgress(util.ProgressPhaseMigrate, "install", 60, "re-creating Black Duck '%s' as '%s'", name, newName)
+	if err := util.CreateWithHelm3(newName, namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, true); err != nil {
+		return fmt.Errorf("failed to create Blackduck resources, Black Duck '%s' is stopped: %w", name, err)
+	}
+	if err := util.DeleteWithHelm3(name, namespace, kubeConfigPath); err != nil {
+		return fmt.Errorf("failed to delete Blackduck resources, Black Duck '%s' is stopped: %w", name, err)
+	}
+	if err := util.CreateWithHelm3(newName, namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath, false); err != nil {
+		return getRenameRecoveryError(name, newName, namespace, helmValuesMap, oldSecretNames, ".", err)
+	}
+	if err := blackduck.CRUDServiceOrRoute(restconfig, kubeClient, namespace, newName, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], true); err != nil {
+		return err
+	}
+	// the ingress of the new name has the host of the old ingress
+	if err := util.DeleteIngress(restconfig, namespace, util.GetResourceName(name, util.BlackDuckName, "")); err != nil {
+		return fmt.Errorf("unable to delete ingress '%s' in namespace '%s' due to %+v", util.GetResourceName(name, util.BlackDuckName, ""), namespace, err)
+	}
+	if err := applyExposureIngress(util.BlackDuckName, newName, namespace, helmValuesMap); err != nil {
+		return err
+	}
+
+	for _, secretName := range oldSecretNames {
+		if err := util.DeleteSecret(kubeClient, namespace, secretName); err != nil && !k8serrors.IsNotFound(err) {
+			return util.WithExitCode(util.ExitCodePartialSuccess, fmt.Errorf("renamed Black Duck '%s' to '%s' but couldn't delete secret '%s' due to %+v", name, newName, secretName, err))
+		}
+	}
+	if serviceType, _ := helmValuesMap["exposedServiceType"].(string); serviceType == "LoadBalancer" || serviceType == "OpenShift" {
+		util.WithStep(logger, "expose").Warnf("the address of the %s of Black Duck '%s' is assigned again, update the clients that use the old address", serviceType, newName)
+	}
+	if err := waitForInstanceReady(util.BlackDuckName, newName, namespace); err != nil {
+		return err
+	}
+	util.EmitProgress(util.ProgressPhaseMigrate, "done", 100, "renamed Black Duck '%s' to '%s'", name, newName)
+	return nil
+}
+
+// getRenameRecoveryError returns the error of a rename whose new release failed to install after the old release was
+// deleted. The rename can't be run again without the old release, so the Helm values of the new release, which use the
+// rebound claims, are written to a file in the directory and the error tells how to create the instance from them
+func getRenameRecoveryError(name string, newName string, namespace string, helmValuesMap map[string]interface{}, oldSecretNames []string, dir string, installErr error) error {
+	cleanup := ""
+	if len(oldSecretNames) > 0 {
+		cleanup = fmt.Sprintf(", then delete the secrets '%s' of the old name", strings.Join(oldSecretNames, "', '"))
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-values.yaml", newName))
+	// the values file contains the passwords of the instance
+	data, err := yaml.Marshal(helmValuesMap)
+	if err == nil {
+		err = ioutil.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Blackduck resources, Black Duck '%s' was deleted and its data is kept in the persistent volume claims of '%s', but its values couldn't be written to '%s' due to %+v: %w", name, newName, path, err, installErr)
+	}
+	return fmt.Errorf("failed to create Blackduck resources, Black Duck '%s' was deleted and its data is kept in the persistent volume claims of '%s', re-create it with 'synopsysctl create blackduck %s -n %s -f %s'%s: %w", name, newName, newName, namespace, path, cleanup, installErr)
+}
+
+// getClaimHelmPath returns the path of the Helm values of a persistent volume claim of the instance, the claim is either
+// a custom claim of the values or a claim of the chart
+func getClaimHelmPath(helmValues map[string]interface{}, name string, claimName string) ([]string, bool) {
+	for key, value := range helmValues {
+		if values, ok := value.(map[string]interface{}); ok && values["persistentVolumeClaimName"] == claimName {
+			return []string{key}, true
+		}
+	}
+	path, ok := blackDuckClaimHelmPaths[claimName[len(name)+1:]]
+	return path, ok
+}
+
+// getExposedNodePort returns the node port of the exposed service of the chart if the values don't set it, so that the
+// new release exposes the user interface on the same port
+func getExposedNodePort(name string, namespace string, helmValues map[string]interface{}) int32 {
+	if serviceType, _ := helmValues["exposedServiceType"].(string); serviceType != "NodePort" || helmValues["exposedNodePort"] != nil {
+		return 0
+	}
+	svc, err := util.GetService(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "webserver-exposed"))
+	if err != nil || svc.Labels["app.kubernetes.io/managed-by"] != "Helm" || len(svc.Spec.Ports) == 0 {
+		return 0
+	}
+	return svc.Spec.Ports[0].NodePort
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+	addConfirmationFlag(renameCmd)
+
+	renameCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(renameCmd.PersistentFlags(), "namespace")
+
+	addChartLocationPathFlag(renameBlackDuckCmd)
+	addWaitFlags(renameBlackDuckCmd)
+	renameBlackDuckCmd.Flags().DurationVar(&migrateTimeout, "rebind-timeout", migrateTimeout, "Time to wait for the pods to release the persistent volume claims and for the claims to be bound")
+	renameCmd.AddCommand(renameBlackDuckCmd)
+}
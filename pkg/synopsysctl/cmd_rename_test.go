@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetRenameRecoveryError(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "synopsysctl-rename-")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	installErr := errors.New("timed out waiting for the condition")
+	helmValuesMap := map[string]interface{}{"webapp": map[string]interface{}{"persistentVolumeClaimName": "new-blackduck-webapp"}}
+	err = getRenameRecoveryError("old", "new", "hub", helmValuesMap, []string{"old-blackduck-db-creds", "old-blackduck-webserver-certificate"}, dir, installErr)
+	assert.True(errors.Is(err, installErr))
+	path := filepath.Join(dir, "new-values.yaml")
+	assert.Contains(err.Error(), "Black Duck 'old' was deleted")
+	assert.Contains(err.Error(), "'synopsysctl create blackduck new -n hub -f "+path+"'")
+	assert.Contains(err.Error(), "delete the secrets 'old-blackduck-db-creds', 'old-blackduck-webserver-certificate' of the old name")
+
+	// the values file re-creates the instance with the rebound claims
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	values := map[string]interface{}{}
+	assert.NoError(yaml.Unmarshal(data, &values))
+	assert.Equal(helmValuesMap, values)
+	info, err := os.Stat(path)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// the error still tells what happened if the values can't be written
+	err = getRenameRecoveryError("old", "new", "hub", helmValuesMap, nil, filepath.Join(dir, "missing"), installErr)
+	assert.True(errors.Is(err, installErr))
+	assert.Contains(err.Error(), "couldn't be written")
+	assert.NotContains(err.Error(), "delete the secrets")
+}
@@ -0,0 +1,283 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Report Command Options and Defaults
+var reportFormat = "html"
+var reportOutputFile = ""
+var reportBackupMaxAge = 48 * time.Hour
+
+// fleetReport is the report of all instances in the cluster
+type fleetReport struct {
+	GeneratedAt time.Time             `json:"generatedAt"`
+	Instances   []fleetReportInstance `json:"instances"`
+}
+
+// fleetReportInstance is the report of a single instance
+type fleetReportInstance struct {
+	Product           string     `json:"product"`
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	Version           string     `json:"version"`
+	LatestVersion     string     `json:"latestVersion"`
+	Size              string     `json:"size,omitempty"`
+	Pods              int        `json:"pods"`
+	ReadyPods         int        `json:"readyPods"`
+	CPURequests       string     `json:"cpuRequests"`
+	MemoryRequests    string     `json:"memoryRequests"`
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty"`
+	LastBackup        *time.Time `json:"lastBackup,omitempty"`
+	Findings          []string   `json:"findings"`
+}
+
+// reportCmd generates reports
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports about Synopsys resources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// reportFleetCmd generates a report covering all Alert and Black Duck instances in the cluster
+var reportFleetCmd = &cobra.Command{
+	Use:           "fleet",
+	Example:       "synopsysctl report fleet --output-file fleet.html\nsynopsysctl report fleet --format json",
+	Short:         "Generate a report of the versions, sizes, resources, certificates, backups and findings of all instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch reportFormat {
+		case "html", "json":
+		case "pdf":
+			return fmt.Errorf("PDF reports are not generated directly, generate an HTML report and print it to PDF from a browser")
+		default:
+			return fmt.Errorf("format must be 'html' or 'json', but got '%s'", reportFormat)
+		}
+
+		report, err := generateFleetReport()
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if len(reportOutputFile) > 0 {
+			f, err := os.Create(reportOutputFile)
+			if err != nil {
+				return fmt.Errorf("unable to create report file '%s' due to %+v", reportOutputFile, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if reportFormat == "json" {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(report)
+		} else {
+			err = fleetReportTemplate.Execute(w, report)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to write the report due to %+v", err)
+		}
+		if len(reportOutputFile) > 0 {
+			log.Infof("fleet report of %d instance(s) written to '%s'", len(report.Instances), reportOutputFile)
+		}
+		return nil
+	},
+}
+
+// generateFleetReport collects the report of every Alert and Black Duck instance in the cluster
+func generateFleetReport() (*fleetReport, error) {
+	report := &fleetReport{GeneratedAt: time.Now(), Instances: []fleetReportInstance{}}
+	for _, product := range []struct{ app, chartName string }{
+		{app: util.AlertName, chartName: globals.AlertChartName},
+		{app: util.BlackDuckName, chartName: globals.BlackDuckChartName},
+	} {
+		instances, err := listFleetInstances(product.app, "")
+		if err != nil {
+			return nil, err
+		}
+		latestVersion, _ := util.SelectUpgradeVersion(util.UpgradePolicyLatest, "0", util.GetAppVersionsFromChartURLs(globals.IndexChartURLs, product.chartName))
+		for _, instance := range instances {
+			instanceReport, err := generateFleetReportInstance(instance, latestVersion)
+			if err != nil {
+				log.Warnf("incomplete report for %s '%s' in namespace '%s': %+v", instance.App, instance.Name, instance.Namespace, err)
+			}
+			report.Instances = append(report.Instances, *instanceReport)
+		}
+	}
+	return report, nil
+}
+
+// generateFleetReportInstance collects the report of an instance. The returned report is usable even if an error is returned
+func generateFleetReportInstance(instance fleetInstance, latestVersion string) (*fleetReportInstance, error) {
+	r := &fleetReportInstance{
+		Product:       instance.App,
+		Name:          instance.Name,
+		Namespace:     instance.Namespace,
+		Version:       instance.Version,
+		LatestVersion: latestVersion,
+		Findings:      []string{},
+	}
+	if latestVersion != "0" && util.CompareVersions(instance.Version, latestVersion) < 0 {
+		r.Findings = append(r.Findings, fmt.Sprintf("version %s is older than the latest version %s", instance.Version, latestVersion))
+	}
+
+	if instance.App == util.BlackDuckName {
+		if release, err := util.GetWithHelm3(instance.Name, instance.Namespace, kubeConfigPath); err == nil {
+			if size, ok := util.GetValueFromRelease(release, []string{"size"}).(string); ok {
+				r.Size = size
+			}
+		}
+	}
+
+	labelSelector := fmt.Sprintf("app=%s, name=%s", instance.App, instance.Name)
+	pods, err := util.ListPodsWithLabels(kubeClient, instance.Namespace, labelSelector)
+	if err != nil {
+		return r, fmt.Errorf("unable to list pods due to %+v", err)
+	}
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+	for _, pod := range pods.Items {
+		r.Pods++
+		ready := len(pod.Status.ContainerStatuses) > 0
+		for _, status := range pod.Status.ContainerStatuses {
+			ready = ready && status.Ready
+		}
+		if ready {
+			r.ReadyPods++
+		}
+		for _, container := range pod.Spec.Containers {
+			cpu.Add(*container.Resources.Requests.Cpu())
+			memory.Add(*container.Resources.Requests.Memory())
+		}
+	}
+	r.CPURequests = cpu.String()
+	r.MemoryRequests = memory.String()
+	if r.ReadyPods < r.Pods {
+		r.Findings = append(r.Findings, fmt.Sprintf("%d of %d pods are not ready", r.Pods-r.ReadyPods, r.Pods))
+	}
+
+	if secrets, err := getInstanceSecrets(instance.App, instance.Name, instance.Namespace); err == nil {
+		for _, secret := range secrets {
+			if expiry, found := util.GetEarliestCertificateExpiry(secret.Secret.Data); found && (r.CertificateExpiry == nil || expiry.Before(*r.CertificateExpiry)) {
+				expiry := expiry
+				r.CertificateExpiry = &expiry
+			}
+		}
+	}
+	if r.CertificateExpiry != nil {
+		if r.CertificateExpiry.Before(time.Now()) {
+			r.Findings = append(r.Findings, fmt.Sprintf("a certificate expired at %s", r.CertificateExpiry.Format("2006-01-02")))
+		} else if r.CertificateExpiry.Before(time.Now().Add(util.CertificateExpiryWarningPeriod)) {
+			r.Findings = append(r.Findings, fmt.Sprintf("a certificate expires soon at %s", r.CertificateExpiry.Format("2006-01-02")))
+		}
+	}
+
+	jobs, err := kubeClient.BatchV1().Jobs(instance.Namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s, %s=%s", labelSelector, scheduledTaskLabel, util.GetResourceName(instance.Name, instance.App, "backup"))})
+	if err != nil {
+		return r, fmt.Errorf("unable to list backup jobs due to %+v", err)
+	}
+	for _, job := range jobs.Items {
+		if job.Status.Succeeded > 0 && job.Status.CompletionTime != nil && (r.LastBackup == nil || job.Status.CompletionTime.Time.After(*r.LastBackup)) {
+			completion := job.Status.CompletionTime.Time
+			r.LastBackup = &completion
+		}
+	}
+	if instance.App == util.BlackDuckName {
+		if r.LastBackup == nil {
+			r.Findings = append(r.Findings, "no successful scheduled backup found, see 'synopsysctl schedule backup blackduck'")
+		} else if time.Since(*r.LastBackup) > reportBackupMaxAge {
+			r.Findings = append(r.Findings, fmt.Sprintf("the last successful backup is older than %s", reportBackupMaxAge))
+		}
+	}
+	return r, nil
+}
+
+var fleetReportTemplate = template.Must(template.New("fleet").Funcs(template.FuncMap{
+	"date": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04")
+	},
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Synopsys Fleet Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+.findings { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Synopsys Fleet Report</h1>
+<p>Generated at {{ .GeneratedAt.Format "2006-01-02 15:04 MST" }} &mdash; {{ len .Instances }} instance(s)</p>
+<table>
+<tr><th>Product</th><th>Name</th><th>Namespace</th><th>Version</th><th>Latest</th><th>Size</th><th>Pods Ready</th><th>CPU Requests</th><th>Memory Requests</th><th>Certificate Expiry</th><th>Last Backup</th><th>Findings</th></tr>
+{{- range .Instances }}
+<tr><td>{{ .Product }}</td><td>{{ .Name }}</td><td>{{ .Namespace }}</td><td>{{ .Version }}</td><td>{{ .LatestVersion }}</td><td>{{ .Size }}</td><td>{{ .ReadyPods }}/{{ .Pods }}</td><td>{{ .CPURequests }}</td><td>{{ .MemoryRequests }}</td><td>{{ date .CertificateExpiry }}</td><td>{{ date .LastBackup }}</td><td class="findings">{{ join .Findings "; " }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportFleetCmd.Flags().StringVar(&reportFormat, "format", reportFormat, "Format of the report [html|json]")
+	reportFleetCmd.Flags().StringVar(&reportOutputFile, "output-file", reportOutputFile, "Path of the file to write the report to (default standard output)")
+	reportFleetCmd.Flags().DurationVar(&reportBackupMaxAge, "backup-max-age", reportBackupMaxAge, "Age after which the last backup of an instance is reported as a finding")
+	reportCmd.AddCommand(reportFleetCmd)
+}
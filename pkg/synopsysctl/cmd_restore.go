@@ -0,0 +1,290 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Restore Command Options and Defaults
+var restoreFrom = ""
+var restoreForce = false
+var restoreTimeout = time.Hour
+var restoreImage = globals.DefaultPostgresClientImage
+var restoreMigrationTimeout = 30 * time.Minute
+
+// restoreCmd restores the data of an instance
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the data of a Synopsys resource from a backup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// restoreBlackDuckCmd restores the databases of a Black Duck instance from the dumps of 'backup blackduck'
+var restoreBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --from DIRECTORY|pvc:CLAIM/DIRECTORY|s3://BUCKET/PREFIX",
+	Example:       "synopsysctl restore blackduck <name> -n <namespace> --from ./backups/blackduck-<name>-20200101T000000Z\nsynopsysctl restore blackduck <name> -n <namespace> --from pvc:<claim>/blackduck-<name>-20200101T000000Z\nsynopsysctl restore blackduck <name> -n <namespace> --from s3://<bucket>/<prefix>/blackduck-<name>-20200101T000000Z --force",
+	Short:         "Restore the databases of a Black Duck instance from a backup",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := util.ParseBackupSource(restoreFrom)
+		if err != nil {
+			return err
+		}
+		return restoreBlackDuck(args[0], namespace, source)
+	},
+}
+
+// restoreBlackDuck stops the components of the instance, restores the dumps of the source into its databases with a
+// job, and starts the components again so that Black Duck migrates the restored databases to its version
+func restoreBlackDuck(name string, namespace string, source util.BackupDestination) error {
+	var bucket util.S3Bucket
+	switch source.Kind {
+	case util.BackupDestinationDirectory:
+		for _, database := range bdutil.BlackDuckDatabases {
+			dump := filepath.Join(source.Location, fmt.Sprintf("%s.dump", database))
+			if _, err := os.Stat(dump); err != nil {
+				return util.ValidationError("the backup directory '%s' has no dump of database '%s': %+v", source.Location, database, err)
+			}
+		}
+	case util.BackupDestinationPVC:
+		if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(source.Location, metav1.GetOptions{}); err != nil {
+			return fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", source.Location, namespace, err)
+		}
+	case util.BackupDestinationS3:
+		bucket = util.GetS3BucketFromEnv(source.Location)
+		if err := util.CheckS3Bucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	rel, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	endpoint, err := getBlackDuckPostgresEndpoint(rel)
+	if err != nil {
+		return err
+	}
+	if err := confirmDestructiveAction(fmt.Sprintf("restore the databases of Black Duck '%s' in namespace '%s' from '%s', replacing their data", name, namespace, source)); err != nil {
+		return err
+	}
+	logger := util.NewLogger(util.BlackDuckName, name, namespace)
+
+	// every component but postgres is stopped so that nothing uses the databases while they are replaced
+	labelSelector := fmt.Sprintf("app=%s, name=%s, component!=postgres", util.BlackDuckName, name)
+	util.EmitProgress(util.ProgressPhaseRestore, "stop", 0, "stopping the components of Black Duck '%s'", name)
+	startStopped, err := stopDeployments(logger, namespace, labelSelector)
+	defer startStopped()
+	if err != nil {
+		return err
+	}
+
+	claim, directory := "", ""
+	if source.Kind == util.BackupDestinationPVC {
+		claim, directory = source.Location, source.Prefix
+	}
+	job, err := bdutil.CreateRestoreDatabaseJob(kubeClient, namespace, name, restoreImage, endpoint, claim, directory, restoreForce, restoreTimeout)
+	if err != nil {
+		return err
+	}
+	if err := waitForRestoreJob(logger, job.Name, namespace, source, bucket); err != nil {
+		return fmt.Errorf("failed to restore the databases of Black Duck '%s' in namespace '%s': %w", name, namespace, err)
+	}
+	propagation := metav1.DeletePropagationBackground
+	if err := kubeClient.BatchV1().Jobs(namespace).Delete(job.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+		util.WithStep(logger, "cleanup").Warnf("unable to delete the restore job '%s' due to %+v", job.Name, err)
+	}
+
+	util.EmitProgress(util.ProgressPhaseRestore, "start", 90, "starting the components of Black Duck '%s'", name)
+	started := time.Now()
+	startStopped()
+	if restoreMigrationTimeout > 0 {
+		if err := followBlackDuckMigrations(name, namespace, started, restoreMigrationTimeout); err != nil {
+			return err
+		}
+	}
+	util.EmitProgress(util.ProgressPhaseRestore, "done", 100, "restored the databases of Black Duck '%s' from '%s'", name, source)
+	return nil
+}
+
+// waitForRestoreJob copies the dumps of a directory or S3 source into the pod of the restore job once it runs, and
+// reports the progress of the job from its logs until it completes
+func waitForRestoreJob(logger *log.Entry, jobName string, namespace string, source util.BackupDestination, bucket util.S3Bucket) error {
+	// the job reads the dumps of a claim by itself
+	copied := source.Kind == util.BackupDestinationPVC
+	restored := 0
+	timeout := time.NewTimer(restoreTimeout)
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	defer timeout.Stop()
+	for {
+		select {
+		case <-timeout.C:
+			return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("the restore job '%s' did not complete within %s, use --timeout to wait longer", jobName, restoreTimeout))
+		case <-ticker.C:
+			pods, err := util.ListPodsWithLabels(kubeClient, namespace, fmt.Sprintf("job-name=%s", jobName))
+			if err != nil {
+				return fmt.Errorf("unable to list the pods of job '%s' due to %+v", jobName, err)
+			}
+			if len(pods.Items) == 0 {
+				continue
+			}
+			pod := &pods.Items[0]
+			if pod.Status.Phase == corev1.PodPending {
+				continue
+			}
+			if !copied && pod.Status.Phase == corev1.PodRunning {
+				if err := copyRestoreDumps(pod, source, bucket); err != nil {
+					return err
+				}
+				copied = true
+			}
+			logs, err := kubeClient.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw()
+			if err != nil {
+				return fmt.Errorf("unable to get the logs of pod '%s' due to %+v", pod.Name, err)
+			}
+			output := strings.TrimSpace(string(logs))
+			if pod.Status.Phase == corev1.PodFailed {
+				return getRestoreJobError(output)
+			}
+			if done := strings.Count(output, bdutil.RestoredOutput+" "); done != restored {
+				restored = done
+				util.EmitProgress(util.ProgressPhaseRestore, "restore", 10+80*restored/len(bdutil.BlackDuckDatabases), "restored %d of %d databases", restored, len(bdutil.BlackDuckDatabases))
+			}
+			if pod.Status.Phase == corev1.PodSucceeded {
+				util.WithStep(logger, "restore").Infof("restored the databases from '%s'", source)
+				return nil
+			}
+		}
+	}
+}
+
+// copyRestoreDumps copies the dumps of a directory or S3 source into the pod of the restore job, and lets the job
+// start restoring them
+func copyRestoreDumps(pod *corev1.Pod, source util.BackupDestination, bucket util.S3Bucket) error {
+	dir := source.Location
+	if source.Kind == util.BackupDestinationS3 {
+		tempDir, err := ioutil.TempDir("", "synopsysctl-restore-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+		dir = tempDir
+	}
+	for _, database := range bdutil.BlackDuckDatabases {
+		fileName := fmt.Sprintf("%s.dump", database)
+		if source.Kind == util.BackupDestinationS3 {
+			key := path.Join(source.Prefix, fileName)
+			log.Infof("downloading '%s' from S3 bucket '%s'", key, bucket.Bucket)
+			if err := downloadS3Object(bucket, key, filepath.Join(dir, fileName)); err != nil {
+				return err
+			}
+		}
+		if err := copyRestoreFile(pod, filepath.Join(dir, fileName), path.Join(bdutil.BackupMountPath, fileName)); err != nil {
+			return err
+		}
+	}
+	util.EmitProgress(util.ProgressPhaseRestore, "copy", 10, "copied the dumps from '%s'", source)
+	return util.StreamContainerCommand(restconfig, kubeClient, pod, []string{"touch", path.Join(bdutil.BackupMountPath, bdutil.BackupCompleteFile)}, nil, ioutil.Discard)
+}
+
+// downloadS3Object downloads an object of an S3 bucket to a local file
+func downloadS3Object(bucket util.S3Bucket, key string, target string) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := util.GetS3Object(bucket, key, f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// copyRestoreFile copies a local file to the pod of the restore job
+func copyRestoreFile(pod *corev1.Pod, source string, target string) error {
+	log.Infof("copying '%s' to '%s'", source, target)
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return util.StreamContainerCommand(restconfig, kubeClient, pod, []string{"sh", "-c", fmt.Sprintf("cat > '%s'", target)}, f, ioutil.Discard)
+}
+
+// getRestoreJobError returns the error of a failed restore job from its output
+func getRestoreJobError(output string) error {
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case bdutil.RestoreMissingDumpOutput:
+			return util.ValidationError("the backup has no dump of database '%s'", parts[1])
+		case bdutil.RestoreNotEmptyOutput:
+			return util.ValidationError("the database 'bds_hub' already has %s tables, use --force to replace its data", parts[1])
+		}
+	}
+	return fmt.Errorf("the restore job failed: %s", output)
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(restoreBlackDuckCmd.PersistentFlags(), "namespace")
+	restoreBlackDuckCmd.Flags().StringVar(&restoreFrom, "from", restoreFrom, "Backup to restore, a local directory of the dumps, pvc:CLAIM/DIRECTORY for a directory of a persistent volume claim in the namespace, or s3://BUCKET/PREFIX with the credentials of the environment")
+	cobra.MarkFlagRequired(restoreBlackDuckCmd.Flags(), "from")
+	restoreBlackDuckCmd.Flags().BoolVar(&restoreForce, "force", restoreForce, "If true, replace the data of databases that already have tables")
+	restoreBlackDuckCmd.Flags().DurationVar(&restoreTimeout, "timeout", restoreTimeout, "Maximum time to copy and restore the dumps")
+	restoreBlackDuckCmd.Flags().StringVar(&restoreImage, "image", restoreImage, "Postgres client image of the restore job, its pg_restore must not be older than the pg_dump of the backup")
+	restoreBlackDuckCmd.Flags().DurationVar(&restoreMigrationTimeout, "migration-timeout", restoreMigrationTimeout, "Maximum time to follow the database migrations after the restore, 0 to not wait for them")
+	addConfirmationFlag(restoreBlackDuckCmd)
+	restoreCmd.AddCommand(restoreBlackDuckCmd)
+}
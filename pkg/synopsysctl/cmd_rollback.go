@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Rollback Command Options and Defaults
+var rollbackRevision = 0
+
+// rollbackCmd rolls a resource back to a previous revision of its Helm release
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll a Synopsys resource back to a previous revision",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// newRollbackCmd returns the rollback command of a product whose instances are identified by name
+func newRollbackCmd(app string, product string, getReleaseName func(name string) string) *cobra.Command {
+	return &cobra.Command{
+		Use:           fmt.Sprintf("%s NAME -n NAMESPACE", app),
+		Example:       fmt.Sprintf("synopsysctl rollback %s <name> -n <namespace>\nsynopsysctl rollback %s <name> -n <namespace> --revision 2", app, app),
+		Short:         fmt.Sprintf("Roll a %s instance back to a previous revision", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := rollbackRelease(app, args[0], getReleaseName(args[0]), namespace); err != nil {
+				return err
+			}
+			log.Infof("%s has been successfully rolled back!", product)
+			return nil
+		},
+	}
+}
+
+// rollbackBDBACmd rolls a BDBA instance back to a previous revision
+var rollbackBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl rollback bdba -n <namespace>\nsynopsysctl rollback bdba -n <namespace> --revision 2",
+	Short:         "Roll a BDBA instance back to a previous revision",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rollbackRelease(globals.BDBAName, globals.BDBAName, globals.BDBAName, namespace); err != nil {
+			return err
+		}
+		log.Infof("BDBA has been successfully rolled back!")
+		return nil
+	},
+}
+
+// rollbackRelease rolls the Helm release back to the revision and restores the secrets that synopsysctl created for
+// that revision. The pods that mount a restored secret are restarted to pick up its data
+func rollbackRelease(app string, name string, releaseName string, namespace string) error {
+	revision, err := util.RollbackWithHelm3(releaseName, namespace, kubeConfigPath, rollbackRevision)
+	if err != nil {
+		return fmt.Errorf("failed to roll back %s '%s' in namespace '%s': %w", app, name, namespace, err)
+	}
+	log.Infof("rolled back release '%s' in namespace '%s' to revision %d", releaseName, namespace, revision)
+
+	restored, err := util.RestoreReleaseSecrets(kubeClient, namespace, releaseName, revision)
+	if err != nil {
+		return util.WithExitCode(util.ExitCodePartialSuccess, fmt.Errorf("rolled back release '%s' but %w", releaseName, err))
+	}
+	if len(restored) == 0 {
+		return nil
+	}
+	log.Infof("restored secrets %v of revision %d", restored, revision)
+
+	// save the restored secrets for the revision that the rollback created
+	rel, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := util.SaveReleaseSecrets(kubeClient, namespace, releaseName, rel.Version, rel.Config); err != nil {
+		log.Warnf("%+v", err)
+	}
+	return restartPodsMountingSecrets(fmt.Sprintf("app=%s, name=%s", app, name), namespace, restored)
+}
+
+// restartPodsMountingSecrets deletes the pods of the label selector that use one of the secrets so that they are
+// recreated with the current data of the secrets
+func restartPodsMountingSecrets(labelSelector string, namespace string, secretNames []string) error {
+	pods, err := util.ListPodsWithLabels(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list the pods in namespace '%s' due to %+v", namespace, err)
+	}
+	secrets := map[string]bool{}
+	for _, secretName := range secretNames {
+		secrets[secretName] = true
+	}
+	for _, pod := range pods.Items {
+		for _, secretName := range getSecretNamesFromPodSpec(pod.Spec) {
+			if !secrets[secretName] {
+				continue
+			}
+			log.Infof("restarting pod '%s' to use the restored secret '%s'", pod.Name, secretName)
+			if err := util.DeletePod(kubeClient, namespace, pod.Name); err != nil {
+				return fmt.Errorf("unable to restart pod '%s' in namespace '%s' due to %+v", pod.Name, namespace, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	addPlanFlags(rollbackCmd)
+
+	rollbackCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(rollbackCmd.PersistentFlags(), "namespace")
+	rollbackCmd.PersistentFlags().IntVar(&rollbackRevision, "revision", rollbackRevision, "Revision of the release to roll back to, the previous revision if not set")
+
+	rollbackCmd.AddCommand(newRollbackCmd(util.AlertName, "Alert", func(name string) string {
+		return fmt.Sprintf("%s%s", name, globals.AlertPostSuffix)
+	}))
+	rollbackCmd.AddCommand(newRollbackCmd(util.BlackDuckName, "Black Duck", func(name string) string { return name }))
+	rollbackCmd.AddCommand(newRollbackCmd(util.OpsSightName, "OpsSight", func(name string) string { return name }))
+	rollbackCmd.AddCommand(rollbackBDBACmd)
+}
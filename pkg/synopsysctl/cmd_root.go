@@ -25,7 +25,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	homedir "github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,6 +44,18 @@ var cfgFile string
 var kubeConfigPath = ""
 var insecureSkipTLSVerify = false
 var logLevelCtl = "info"
+var quietOutput = false
+var noColorOutput = false
+var logFormat = util.LogFormatText
+var failOn = "error"
+var refreshClusterInfo = false
+var messageLanguage = ""
+var progressFormat = util.ProgressFormatNone
+var progressFD = 2
+var correlationID = ""
+
+// clusterInfoCacheTTL is how long the discovered capabilities of a cluster are cached
+var clusterInfoCacheTTL = 24 * time.Hour
 
 // synopsysctlVersion is the current version of the synopsysctl utility
 var synopsysctlVersion string
@@ -53,37 +69,66 @@ var rootCmd = &cobra.Command{
 	},
 	// This function is run before every subcommand
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyConfigDefaults(cmd); err != nil {
+			return err
+		}
 		if err := setSynopsysctlLogLevel(); err != nil {
 			return err
 		}
+		if err := util.InitCorrelationID(correlationID); err != nil {
+			return err
+		}
+		util.ConfigureOutput(quietOutput, noColorOutput)
+		if err := util.ConfigureLogFormat(logFormat); err != nil {
+			return err
+		}
+		if err := util.ConfigureProgress(progressFormat, progressFD); err != nil {
+			return err
+		}
+		if err := messages.SetLanguage(messageLanguage); err != nil {
+			return util.ValidationError("%+v", err)
+		}
+		if failOn != "" && failOn != "error" && failOn != "warning" {
+			return util.WithExitCode(util.ExitCodeValidation, messages.Error(messages.InvalidFailOn, messages.Args{"Value": failOn}))
+		}
+		util.ClusterTypeOverride = strings.ToLower(util.ClusterTypeOverride)
+		if len(util.ClusterTypeOverride) > 0 && util.ClusterTypeOverride != util.ClusterTypeKubernetes && util.ClusterTypeOverride != util.ClusterTypeOpenShift {
+			return util.WithExitCode(util.ExitCodeValidation, messages.Error(messages.InvalidClusterType, messages.Args{"Kubernetes": util.ClusterTypeKubernetes, "OpenShift": util.ClusterTypeOpenShift, "Value": util.ClusterTypeOverride}))
+		}
 
-		// Determine if synopsysctl is running in native command
-		nativeMode := strings.Contains(cmd.CommandPath(), "native")
+		// the index of the chart repository was loaded without its credentials when synopsysctl started
+		if util.IsChartRepoConfigured() && !util.Offline {
+			globals.LoadChartRepository()
+		}
+
+		if err := startPlan(cmd); err != nil {
+			return err
+		}
+
+		// Determine if synopsysctl is running in native command, or in a command that doesn't need the cluster
+		nativeMode := strings.Contains(cmd.CommandPath(), "native") || cmd.Annotations[offlineCommandAnnotation] == "true"
 
 		// Don't set cluster resources if we are in native mode (aka the command doesn't need access the cluster)
 		// This allows users to use native when not connected to a cluster
 		if !nativeMode {
 			if err := setGlobalKubeConfigPath(cmd); err != nil {
-				log.Error(err)
-				os.Exit(1)
+				return util.WithExitCode(util.ExitCodeClusterUnreachable, err)
 			}
 			if err := setGlobalRestConfig(); err != nil {
-				log.Error(err)
-				os.Exit(1)
+				return util.WithExitCode(util.ExitCodeClusterUnreachable, err)
 			}
 			if err := setGlobalKubeClient(); err != nil {
-				log.Error(err)
-				os.Exit(1)
+				return util.WithExitCode(util.ExitCodeClusterUnreachable, err)
 			}
+			setGlobalClusterInfo()
 			if err := setGlobalResourceClients(); err != nil {
-				log.Error(err)
-				os.Exit(1)
+				return util.WithExitCode(util.ExitCodeClusterUnreachable, err)
 			}
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -91,19 +136,83 @@ var rootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(version string) {
 	rootCmd.Version = version
-	if err := rootCmd.Execute(); err != nil {
-		log.Errorf("synopsyctl failed: %+v", err)
-		os.Exit(1)
+	util.SynopsysctlVersion = version
+	// support the short forms of the products and noun-first ordering, e.g. 'synopsysctl bd create'
+	addProductAliases(rootCmd)
+	// complete the flag values such as the versions, namespaces, storage classes and sizes
+	addFlagCompletions(rootCmd)
+	rootCmd.SetArgs(reorderNounFirstArgs(os.Args[1:]))
+	// invalid flags and arguments exit with the validation exit code
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return util.WithExitCode(util.ExitCodeValidation, err)
+	})
+	setValidationExitCodeForArgs(rootCmd)
+	err := rootCmd.Execute()
+	if planned, planErr := printActivePlan(err); planned {
+		// the command stopped at its first Helm operation
+		if planErr != nil {
+			log.Errorf("synopsyctl failed to print the plan: %+v", planErr)
+			os.Exit(util.ExitCode(planErr))
+		}
+		return
+	}
+	if err != nil {
+		log.Error(messages.Get(messages.CommandFailed, messages.Args{"Error": fmt.Sprintf("%+v", err)}))
+		os.Exit(util.ExitCode(err))
+	}
+	if failOn == "warning" && util.WarningCount() > 0 {
+		log.Errorf("synopsysctl logged %d warning(s) and --fail-on is set to warning", util.WarningCount())
+		os.Exit(util.ExitCodeWarning)
+	}
+}
+
+// setValidationExitCodeForArgs wraps the argument validation of all commands to exit with the validation exit code
+func setValidationExitCodeForArgs(cmd *cobra.Command) {
+	if cmd.Args != nil {
+		validateArgs := cmd.Args
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return util.WithExitCode(util.ExitCodeValidation, validateArgs(cmd, args))
+		}
+	}
+	for _, subCmd := range cmd.Commands() {
+		setValidationExitCodeForArgs(subCmd)
 	}
 }
 
 func init() {
 	//(PassCmd) rootCmd.DisableFlagParsing = true // lets rootCmd pass flags to kube/oc
 
-	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&kubeConfigPath, "kubeconfig", kubeConfigPath, "Path to a kubeconfig file with the context set to a cluster for synopsysctl to access")
+	cobra.OnInitialize(initConfig, initLanguage)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", cfgFile, "Path to the config file of the flag defaults (default ~/.synopsysctl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&kubeConfigPath, "kubeconfig", kubeConfigPath, "Path to a kubeconfig file with the context set to a cluster for synopsysctl to access (default the merged kubeconfigs of the KUBECONFIG environ, or ~/.kube/config)")
 	rootCmd.PersistentFlags().BoolVar(&insecureSkipTLSVerify, "insecure-skip-tls-verify", insecureSkipTLSVerify, "Server's certificate won't be validated. HTTPS will be less secure")
+	rootCmd.PersistentFlags().StringVar(&util.ChartRepoUsername, "chart-repo-username", util.ChartRepoUsername, "Username of the basic auth of the chart repositories, e.g. of an internal Artifactory")
+	rootCmd.PersistentFlags().StringVar(&util.ChartRepoPassword, "chart-repo-password", util.ChartRepoPassword, "Password of the basic auth of the chart repositories")
+	rootCmd.PersistentFlags().StringVar(&util.ChartRepoCAFile, "chart-repo-ca-file", util.ChartRepoCAFile, "Path to the PEM file of the CA of the certificates of the chart repositories, in addition to the system's CAs")
+	rootCmd.PersistentFlags().BoolVar(&util.ChartRepoInsecureSkipTLSVerify, "chart-repo-insecure-skip-tls-verify", util.ChartRepoInsecureSkipTLSVerify, "The certificates of the chart repositories won't be validated. HTTPS will be less secure")
+	rootCmd.PersistentFlags().BoolVar(&util.StampMetadata, "stamp-metadata", util.StampMetadata, "Annotate the objects of the installs and upgrades with the synopsysctl version, chart version, values digest and correlation ID of the command")
+	rootCmd.PersistentFlags().BoolVar(&util.OverrideFreeze, "override-freeze", util.OverrideFreeze, "Change an instance even if it is frozen by 'synopsysctl freeze', the webhook of the freeze is deleted")
+	rootCmd.PersistentFlags().BoolVar(&util.ProductAPIInsecureSkipVerify, "product-api-insecure-skip-verify", util.ProductAPIInsecureSkipVerify, "Certificates of the Black Duck, Alert and BDBA APIs won't be validated. HTTPS will be less secure")
 	rootCmd.PersistentFlags().StringVarP(&logLevelCtl, "verbose-level", "v", logLevelCtl, "Log level for synopsysctl [trace|debug|info|warn|error|fatal|panic]")
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", quietOutput, "Only print errors")
+	rootCmd.PersistentFlags().StringVar(&progressFormat, "progress", progressFormat, "If set, write machine-readable progress events of long operations with the phase, step, percent and message [json]")
+	rootCmd.PersistentFlags().IntVar(&progressFD, "progress-fd", progressFD, "File descriptor the progress events are written to, e.g. 3 with '3>progress.log' (default the standard error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat, "Format of the log messages, json keeps the product, instance, namespace and step fields filterable [text|json]")
+	rootCmd.PersistentFlags().StringVar(&correlationID, "correlation-id", correlationID, "ID of the command in its logs, plans, progress events, Kubernetes Events and Helm release descriptions, e.g. of a pipeline run (default a generated UUID)")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", failOn, "Minimum severity that makes synopsysctl exit with a non-zero code [error|warning]")
+	rootCmd.PersistentFlags().StringVar(&util.ClusterTypeOverride, "cluster-type", util.ClusterTypeOverride, "Type of the cluster if it can't be discovered, e.g. with restricted permissions [kubernetes|openshift]")
+	rootCmd.PersistentFlags().BoolVar(&util.Offline, "offline", util.Offline, "Don't download the charts, for clusters without internet access. --app-resources-path must be a local chart archive, e.g. of 'synopsysctl airgap export'")
+	rootCmd.PersistentFlags().BoolVar(&refreshClusterInfo, "refresh-cluster-info", refreshClusterInfo, "Discover the capabilities of the cluster again instead of using the cache in ~/.synopsysctl/cache")
+	rootCmd.PersistentFlags().BoolVar(&noColorOutput, "no-color", noColorOutput, "Disable colors in the output (also disabled by the NO_COLOR environ or if the output is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&messageLanguage, "lang", messageLanguage, fmt.Sprintf("Language of the messages [%s] (default from the %s environ or the locale)", strings.Join(messages.Languages(), "|"), messages.LanguageEnv))
+}
+
+// initLanguage sets the language of the messages before the arguments of the command are validated. An invalid --lang
+// falls back to the language of the environment here and is reported by the PersistentPreRunE of the root command
+func initLanguage() {
+	if err := messages.SetLanguage(messageLanguage); err != nil {
+		messages.SetLanguage("")
+	}
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -124,10 +233,13 @@ func initConfig() {
 		viper.SetConfigName(".synopsysctl")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match, e.g. SYNOPSYSCTL_BLACKDUCK_VERSION for blackduck.version
+	viper.SetEnvPrefix("synopsysctl")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		log.Infof("using config file '%s'", viper.ConfigFileUsed())
+		log.Debugf("using config file '%s'", viper.ConfigFileUsed())
 	}
 }
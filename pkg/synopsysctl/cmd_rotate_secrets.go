@@ -0,0 +1,414 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Rotate Secrets Command Options and Defaults
+var rotatePostgresPasswords = false
+var rotateEncryption = false
+var rotateWebserverCertificate = false
+var rotateCertificateFilePath = ""
+var rotateCertificateKeyFilePath = ""
+var rotateRestartTimeout = 10 * time.Minute
+
+// rotatedPasswordLength is the length of the generated passwords, salts and keys
+const rotatedPasswordLength = 32
+
+// blackDuckRestartOrder is the order in which the components of Black Duck are restarted, the services that the others
+// depend on first and the webserver last
+var blackDuckRestartOrder = []string{"cfssl", "authentication", "registration", "webapp", "bomengine", "matchengine", "jobrunner", "scan", "uploadcache", "documentation", "webserver"}
+
+// alertRestartOrder is the order in which the components of Alert are restarted
+var alertRestartOrder = []string{"cfssl", "alert"}
+
+// rotateSecretsCmd rotates the credentials and certificates of an instance
+var rotateSecretsCmd = &cobra.Command{
+	Use:   "rotate-secrets",
+	Short: "Rotate the passwords, encryption keys and certificates of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// rotateSecretsBlackDuckCmd rotates the Postgres passwords and the webserver certificate of a Black Duck instance
+var rotateSecretsBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl rotate-secrets blackduck <name> -n <namespace> --postgres-passwords\nsynopsysctl rotate-secrets blackduck <name> -n <namespace> --webserver-certificate --certificate-file-path tls.crt --certificate-key-file-path tls.key",
+	Short:         "Rotate the Postgres passwords and the webserver certificate of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !rotatePostgresPasswords && !rotateWebserverCertificate {
+			return util.ValidationError("--postgres-passwords or --webserver-certificate must be set")
+		}
+		if err := validateRotateCertificateFlags(); err != nil {
+			return err
+		}
+		if err := confirmDestructiveAction(fmt.Sprintf("this will replace the %s of Black Duck '%s' in namespace '%s' and restart its affected pods", strings.Join(getRotatedSecretsDescription(), " and "), args[0], namespace)); err != nil {
+			return err
+		}
+		return rotateBlackDuckSecrets(cmd, args[0], namespace)
+	},
+}
+
+// rotateSecretsAlertCmd rotates the encryption password and global salt and the webserver certificate of an Alert instance
+var rotateSecretsAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE",
+	Example:       "synopsysctl rotate-secrets alert <name> -n <namespace> --encryption\nsynopsysctl rotate-secrets alert <name> -n <namespace> --webserver-certificate",
+	Short:         "Rotate the encryption password and global salt and the webserver certificate of an Alert instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !rotateEncryption && !rotateWebserverCertificate {
+			return util.ValidationError("--encryption or --webserver-certificate must be set")
+		}
+		if err := validateRotateCertificateFlags(); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("this will replace the %s of Alert '%s' in namespace '%s' and restart its affected pods", strings.Join(getRotatedSecretsDescription(), " and "), args[0], namespace)
+		if rotateEncryption {
+			description += ". The settings that Alert encrypted with the previous values, e.g. the passwords of its channels, must be entered again"
+		}
+		if err := confirmDestructiveAction(description); err != nil {
+			return err
+		}
+		return rotateAlertSecrets(cmd, args[0], namespace)
+	},
+}
+
+// validateRotateCertificateFlags verifies that a certificate and its key are given together
+func validateRotateCertificateFlags() error {
+	if (len(rotateCertificateFilePath) > 0) != (len(rotateCertificateKeyFilePath) > 0) {
+		return util.ValidationError("--certificate-file-path and --certificate-key-file-path must be set together")
+	}
+	if len(rotateCertificateFilePath) > 0 && !rotateWebserverCertificate {
+		return util.ValidationError("--certificate-file-path requires --webserver-certificate")
+	}
+	return nil
+}
+
+// getRotatedSecretsDescription returns the descriptions of the secrets that the flags rotate
+func getRotatedSecretsDescription() []string {
+	descriptions := []string{}
+	if rotatePostgresPasswords {
+		descriptions = append(descriptions, "Postgres passwords")
+	}
+	if rotateEncryption {
+		descriptions = append(descriptions, "encryption password and global salt")
+	}
+	if rotateWebserverCertificate {
+		descriptions = append(descriptions, "webserver certificate")
+	}
+	return descriptions
+}
+
+// getRotatedCertificate returns the certificate and key of the files, or a new self-signed certificate for the hostname
+func getRotatedCertificate(hostname string, defaultCommonName string) ([]byte, []byte, error) {
+	if len(rotateCertificateFilePath) > 0 {
+		cert, err := util.ReadFromFile(rotateCertificateFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := util.ReadFromFile(rotateCertificateKeyFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := util.ValidateCertificateAndKey(cert, key, hostname); err != nil {
+			return nil, nil, util.ValidationError("invalid certificate: %+v", err)
+		}
+		return cert, key, nil
+	}
+	commonName := hostname
+	if len(commonName) == 0 {
+		commonName = defaultCommonName
+	}
+	cert, key, err := util.GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: commonName})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate a self-signed certificate due to %+v", err)
+	}
+	log.Infof("generated a self-signed certificate for '%s'", commonName)
+	return []byte(cert), []byte(key), nil
+}
+
+// applyRotatedSecret creates or replaces the data of a secret
+func applyRotatedSecret(secret *corev1.Secret) error {
+	current, err := util.GetSecret(kubeClient, secret.Namespace, secret.Name)
+	if k8serrors.IsNotFound(err) {
+		if _, err := kubeClient.CoreV1().Secrets(secret.Namespace).Create(secret); err != nil {
+			return fmt.Errorf("failed to create secret '%s' in namespace '%s' due to %+v", secret.Name, secret.Namespace, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get secret '%s' in namespace '%s' due to %+v", secret.Name, secret.Namespace, err)
+	}
+	current.Data = secret.Data
+	if _, err := util.UpdateSecret(kubeClient, secret.Namespace, current); err != nil {
+		return fmt.Errorf("failed to update secret '%s' in namespace '%s' due to %+v", secret.Name, secret.Namespace, err)
+	}
+	return nil
+}
+
+// rotateBlackDuckSecrets sets new Postgres passwords in the internal Postgres and replaces the webserver certificate,
+// pushes the new values into the Helm release and restarts the pods that use them
+func rotateBlackDuckSecrets(cmd *cobra.Command, name string, namespace string) error {
+	helmRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find instance %s in namespace %s", name, namespace)
+	}
+	helmValuesMap := helmRelease.Config
+	globals.BlackDuckVersion = util.GetValueFromRelease(helmRelease, []string{"imageTag"}).(string)
+	if err := UpdateHelmChartLocation(cmd.Flags(), globals.BlackDuckChartName, globals.BlackDuckVersion, &globals.BlackDuckChartRepository); err != nil {
+		return fmt.Errorf("failed to set the app resources location due to %+v", err)
+	}
+
+	rotatedSecrets := []string{}
+	if rotatePostgresPasswords {
+		secretName, err := rotateBlackDuckPostgresPasswords(name, namespace, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		rotatedSecrets = append(rotatedSecrets, secretName)
+	}
+	if rotateWebserverCertificate {
+		hostname, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"environs", "PUBLIC_HUB_WEBSERVER_HOST"}).(string)
+		cert, key, err := getRotatedCertificate(hostname, util.GetResourceName(name, util.BlackDuckName, "webserver"))
+		if err != nil {
+			return err
+		}
+		secretName := util.GetResourceName(name, util.BlackDuckName, "webserver-certificate")
+		secret, err := blackduck.GetCertificateSecret(secretName, namespace, cert, key)
+		if err != nil {
+			return err
+		}
+		if err := applyRotatedSecret(secret); err != nil {
+			return err
+		}
+		util.SetHelmValueInMap(helmValuesMap, []string{"tlsCertSecretName"}, secretName)
+		rotatedSecrets = append(rotatedSecrets, secretName)
+	}
+
+	if err := util.UpdateWithHelm3(name, namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+		return fmt.Errorf("failed to push the rotated secrets into Black Duck '%s' due to %+v", name, err)
+	}
+	if err := restartDeploymentsForRotation(util.BlackDuckName, name, namespace, blackDuckRestartOrder, func(deployment appsv1.Deployment) bool {
+		return deployment.Labels["component"] != "postgres" && usesAnySecret(deployment, rotatedSecrets)
+	}); err != nil {
+		return err
+	}
+	log.Infof("rotated the %s of Black Duck '%s' in namespace '%s'", strings.Join(getRotatedSecretsDescription(), " and "), name, namespace)
+	return nil
+}
+
+// rotateBlackDuckPostgresPasswords sets new passwords of the admin and the user in the internal Postgres, then stores
+// them in the Helm values and in the secret of the database credentials, whose name is returned
+func rotateBlackDuckPostgresPasswords(name string, namespace string, helmValuesMap map[string]interface{}) (string, error) {
+	if isExternal, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"postgres", "isExternal"}).(bool); isExternal {
+		return "", util.ValidationError("the passwords of an external Postgres are managed outside of the cluster, change them in the database and then with 'synopsysctl update blackduck %s --external-postgres-admin-password --external-postgres-user-password'", name)
+	}
+	adminRole, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"postgres", "adminUserName"}).(string)
+	if len(adminRole) == 0 {
+		adminRole = "blackduck"
+	}
+	userRole, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"postgres", "userUserName"}).(string)
+	if len(userRole) == 0 {
+		userRole = blackduck.DefaultFlagTree.ExternalPostgresUser
+	}
+	adminPassword, err := util.GetRandomString(rotatedPasswordLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the admin password due to %+v", err)
+	}
+	userPassword, err := util.GetRandomString(rotatedPasswordLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the user password due to %+v", err)
+	}
+
+	postgresPod, err := util.FilterPodByNamePrefixInNamespace(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "postgres"))
+	if err != nil {
+		return "", fmt.Errorf("unable to find the Postgres pod of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	// the statements are passed on stdin so that the passwords don't appear in the exec request
+	sql := util.PostgresAlterPasswordSQL(adminRole, adminPassword) + util.PostgresAlterPasswordSQL(userRole, userPassword)
+	var output bytes.Buffer
+	if err := util.StreamContainerCommand(restconfig, kubeClient, postgresPod, []string{"psql", "-U", "postgres", "-d", "postgres", "-v", "ON_ERROR_STOP=1"}, strings.NewReader(sql), &output); err != nil {
+		return "", fmt.Errorf("failed to set the new passwords in Postgres due to %+v", err)
+	}
+	log.Infof("set the new passwords of the roles '%s' and '%s' in Postgres", adminRole, userRole)
+
+	util.SetHelmValueInMap(helmValuesMap, []string{"postgres", "adminPassword"}, adminPassword)
+	util.SetHelmValueInMap(helmValuesMap, []string{"postgres", "userPassword"}, userPassword)
+	secretName := util.GetResourceName(name, util.BlackDuckName, "db-creds")
+	secret, err := util.GetSecret(kubeClient, namespace, secretName)
+	if err != nil {
+		return "", fmt.Errorf("unable to get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+	}
+	secret.Data["HUB_POSTGRES_ADMIN_PASSWORD_FILE"] = []byte(adminPassword)
+	secret.Data["HUB_POSTGRES_USER_PASSWORD_FILE"] = []byte(userPassword)
+	if _, err := util.UpdateSecret(kubeClient, namespace, secret); err != nil {
+		return "", fmt.Errorf("unable to update secret '%s' in namespace '%s' due to %+v, the new passwords are already set in Postgres", secretName, namespace, err)
+	}
+	return secretName, nil
+}
+
+// rotateAlertSecrets replaces the encryption password and global salt and the webserver certificate, pushes the new
+// values into the Helm release and restarts the pods that use them
+func rotateAlertSecrets(cmd *cobra.Command, alertName string, namespace string) error {
+	helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
+	helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("couldn't find instance '%s' in namespace '%s'", alertName, namespace)
+	}
+	helmValuesMap := helmRelease.Config
+	alertVersion, _ := util.GetValueFromRelease(helmRelease, []string{"alert", "imageTag"}).(string)
+	if err := UpdateHelmChartLocation(cmd.Flags(), globals.AlertChartName, alertVersion, &globals.AlertChartRepository); err != nil {
+		return fmt.Errorf("failed to set the app resources location due to %+v", err)
+	}
+
+	rotatedSecrets := []string{}
+	if rotateEncryption {
+		for _, key := range []string{"alertEncryptionPassword", "alertEncryptionGlobalSalt"} {
+			value, err := util.GetRandomString(rotatedPasswordLength)
+			if err != nil {
+				return fmt.Errorf("failed to generate the %s due to %+v", key, err)
+			}
+			util.SetHelmValueInMap(helmValuesMap, []string{key}, value)
+		}
+	}
+	if rotateWebserverCertificate {
+		hostname, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"environs", "ALERT_HOSTNAME"}).(string)
+		cert, key, err := getRotatedCertificate(hostname, helmReleaseName)
+		if err != nil {
+			return err
+		}
+		secretName, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}).(string)
+		if len(secretName) == 0 {
+			secretName = "alert-custom-certificate"
+		}
+		secret := alert.GetAlertCustomCertificateSecret(namespace, secretName, string(cert), string(key))
+		if err := applyRotatedSecret(&secret); err != nil {
+			return err
+		}
+		util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, secretName)
+		rotatedSecrets = append(rotatedSecrets, secretName)
+	}
+
+	if err := util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+		cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+		return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to push the rotated secrets into Alert: %+v", cleanErrorMsg))
+	}
+	// the chart stores the encryption values in a secret of its own, so all components except Postgres are restarted
+	if err := restartDeploymentsForRotation(util.AlertName, alertName, namespace, alertRestartOrder, func(deployment appsv1.Deployment) bool {
+		return deployment.Labels["component"] != "postgres" && (rotateEncryption || usesAnySecret(deployment, rotatedSecrets))
+	}); err != nil {
+		return err
+	}
+	log.Infof("rotated the %s of Alert '%s' in namespace '%s'", strings.Join(getRotatedSecretsDescription(), " and "), alertName, namespace)
+	return nil
+}
+
+// usesAnySecret returns true if the pod template of the deployment uses one of the secrets
+func usesAnySecret(deployment appsv1.Deployment, secretNames []string) bool {
+	for _, used := range getSecretNamesFromPodSpec(deployment.Spec.Template.Spec) {
+		for _, secretName := range secretNames {
+			if used == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// restartDeploymentsForRotation restarts the selected deployments of the instance one after the other in the order of
+// their components, each one is rolled out before the next one is restarted
+func restartDeploymentsForRotation(app string, name string, namespace string, order []string, selected func(deployment appsv1.Deployment) bool) error {
+	deployments, err := util.ListDeployments(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", app, name))
+	if err != nil {
+		return fmt.Errorf("unable to list the deployments of %s '%s' in namespace '%s' due to %+v", app, name, namespace, err)
+	}
+	affected := []appsv1.Deployment{}
+	for _, deployment := range deployments.Items {
+		if selected(deployment) {
+			affected = append(affected, deployment)
+		}
+	}
+	for _, deploymentName := range util.OrderDeploymentsForRestart(affected, order) {
+		log.Infof("restarting deployment '%s'...", deploymentName)
+		if err := util.RestartDeployment(kubeClient, namespace, deploymentName); err != nil {
+			return err
+		}
+		if err := util.WaitForDeploymentRollout(kubeClient, namespace, deploymentName, rotateRestartTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(rotateSecretsCmd)
+
+	rotateSecretsCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(rotateSecretsCmd.PersistentFlags(), "namespace")
+	rotateSecretsCmd.PersistentFlags().BoolVar(&rotateWebserverCertificate, "webserver-certificate", rotateWebserverCertificate, "If true, replace the webserver certificate with the certificate of the files or a new self-signed certificate")
+	rotateSecretsCmd.PersistentFlags().StringVar(&rotateCertificateFilePath, "certificate-file-path", rotateCertificateFilePath, "Absolute path to a file for the new webserver certificate")
+	rotateSecretsCmd.PersistentFlags().StringVar(&rotateCertificateKeyFilePath, "certificate-key-file-path", rotateCertificateKeyFilePath, "Absolute path to a file for the key of the new webserver certificate")
+	rotateSecretsCmd.PersistentFlags().DurationVar(&rotateRestartTimeout, "timeout", rotateRestartTimeout, "Maximum time to wait for each restarted deployment to be rolled out")
+	addConfirmationFlag(rotateSecretsCmd)
+
+	rotateSecretsBlackDuckCmd.Flags().BoolVar(&rotatePostgresPasswords, "postgres-passwords", rotatePostgresPasswords, "If true, replace the passwords of the admin and the user of the internal Postgres")
+	addChartLocationPathFlag(rotateSecretsBlackDuckCmd)
+	rotateSecretsCmd.AddCommand(rotateSecretsBlackDuckCmd)
+
+	rotateSecretsAlertCmd.Flags().BoolVar(&rotateEncryption, "encryption", rotateEncryption, "If true, replace the encryption password and global salt")
+	addChartLocationPathFlag(rotateSecretsAlertCmd)
+	rotateSecretsCmd.AddCommand(rotateSecretsAlertCmd)
+}
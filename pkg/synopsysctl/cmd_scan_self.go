@@ -0,0 +1,212 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Scan Self Command Options and Defaults
+var scanSelfBDBAURL = ""
+var scanSelfBDBAUsername = "admin"
+var scanSelfBDBAPasswordFile = ""
+var scanSelfBDBAGroup = ""
+var scanSelfTimeout = 30 * time.Minute
+var scanSelfOutputFormat = "table"
+var scanSelfOutputFile = ""
+
+// scanSelfReport is the security posture of the images deployed by an instance
+type scanSelfReport struct {
+	Product   string           `json:"product"`
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	BDBAURL   string           `json:"bdbaUrl"`
+	ScannedAt time.Time        `json:"scannedAt"`
+	Images    []scanSelfResult `json:"images"`
+}
+
+// scanSelfResult is the scan result of a deployed image
+type scanSelfResult struct {
+	Image  string           `json:"image"`
+	Pulled string           `json:"pulled"`
+	Error  string           `json:"error,omitempty"`
+	Result *bdba.ScanResult `json:"result,omitempty"`
+}
+
+// scanSelfCmd scans the images of an instance for vulnerabilities with BDBA
+var scanSelfCmd = &cobra.Command{
+	Use:           "scan-self PRODUCT NAME -n NAMESPACE --bdba-url URL --bdba-password-file FILE",
+	Example:       "synopsysctl scan-self blackduck <name> -n <namespace> --bdba-url https://bdba.example.com --bdba-password-file bdba-password.txt\nsynopsysctl scan-self alert <name> -n <namespace> --bdba-url https://bdba.example.com --bdba-password-file bdba-password.txt --output json --output-file alert-images.json",
+	Short:         "Scan the images deployed by an Alert or Black Duck instance for vulnerabilities with BDBA",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if scanSelfOutputFormat != "table" && scanSelfOutputFormat != "json" {
+			return fmt.Errorf("output must be 'table' or 'json', but got '%s'", scanSelfOutputFormat)
+		}
+		if len(scanSelfBDBAURL) == 0 || len(scanSelfBDBAPasswordFile) == 0 {
+			return fmt.Errorf("--bdba-url and --bdba-password-file are required")
+		}
+		password, err := util.ReadFileData(scanSelfBDBAPasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the BDBA password file: %+v", err)
+		}
+		client := bdba.NewClient(scanSelfBDBAURL, scanSelfBDBAUsername, strings.TrimSpace(password), scanSelfBDBAGroup)
+
+		report, err := scanInstanceImages(client, args[0], args[1], namespace)
+		if err != nil {
+			return err
+		}
+
+		output := os.Stdout
+		if len(scanSelfOutputFile) > 0 {
+			if output, err = os.Create(scanSelfOutputFile); err != nil {
+				return fmt.Errorf("failed to create '%s': %+v", scanSelfOutputFile, err)
+			}
+			defer output.Close()
+		}
+		if scanSelfOutputFormat == "json" {
+			encoder := json.NewEncoder(output)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			w := tabwriter.NewWriter(output, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "IMAGE\tPRODUCT ID\tVERDICT\tVULNERABILITIES\tVULNERABLE COMPONENTS")
+			for _, image := range report.Images {
+				if image.Result == nil {
+					fmt.Fprintf(w, "%s\t-\tERROR\t-\t%s\n", image.Image, image.Error)
+					continue
+				}
+				vulnerableComponents := []string{}
+				for _, component := range image.Result.Components {
+					if len(component.Vulnerabilities) > 0 {
+						vulnerableComponents = append(vulnerableComponents, fmt.Sprintf("%s %s", component.Name, component.Version))
+					}
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\n", image.Image, image.Result.ProductID, image.Result.Verdict, image.Result.VulnCount, strings.Join(vulnerableComponents, ", "))
+			}
+			w.Flush()
+		}
+
+		for _, image := range report.Images {
+			if image.Result == nil {
+				return fmt.Errorf("failed to scan some images of %s '%s' in namespace '%s'", args[0], args[1], namespace)
+			}
+		}
+		return nil
+	},
+}
+
+// scanInstanceImages uploads each image running in the pods of the instance to BDBA and waits for the scan results
+func scanInstanceImages(client *bdba.Client, app string, name string, namespace string) (*scanSelfReport, error) {
+	images, err := getInstanceImages(app, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no running pods of %s '%s' in namespace '%s'", app, name, namespace)
+	}
+	report := &scanSelfReport{Product: app, Name: name, Namespace: namespace, BDBAURL: client.URL, ScannedAt: time.Now().UTC()}
+
+	imageNames := []string{}
+	for image := range images {
+		imageNames = append(imageNames, image)
+	}
+	sort.Strings(imageNames)
+	for _, image := range imageNames {
+		result := scanSelfResult{Image: image, Pulled: images[image]}
+		log.Infof("scanning image '%s'", image)
+		if result.Result, err = scanImage(client, result.Pulled); err != nil {
+			log.Errorf("failed to scan image '%s': %+v", image, err)
+			result.Error = err.Error()
+		}
+		report.Images = append(report.Images, result)
+	}
+	return report, nil
+}
+
+// getInstanceImages returns the images of the containers of the instance, mapped to the image with the digest they run
+func getInstanceImages(app string, name string, namespace string) (map[string]string, error) {
+	pods, err := util.ListPodsWithLabels(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", app, name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of %s '%s' in namespace '%s' due to %+v", app, name, namespace, err)
+	}
+	images := map[string]string{}
+	for _, pod := range pods.Items {
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			pulled := status.Image
+			// the image id is the pulled digest, e.g. docker-pullable://docker.io/blackducksoftware/blackduck-webapp@sha256:...
+			if imageID := strings.SplitN(status.ImageID, "://", 2); len(imageID) == 2 && strings.Contains(imageID[1], "@") {
+				pulled = imageID[1]
+			}
+			images[status.Image] = pulled
+		}
+	}
+	return images, nil
+}
+
+// scanImage saves the image to a temporary archive and uploads it to BDBA
+func scanImage(client *bdba.Client, image string) (*bdba.ScanResult, error) {
+	archive, err := ioutil.TempFile("", "synopsysctl-scan-self-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	if err := util.SaveImage(image, archive); err != nil {
+		return nil, err
+	}
+	fileName := strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(util.ParseImageName(strings.Split(image, "@")[0])) + ".tar"
+	productID, err := client.Upload(fileName, archive)
+	if err != nil {
+		return nil, err
+	}
+	return client.WaitForScanResult(productID, scanSelfTimeout)
+}
+
+func init() {
+	rootCmd.AddCommand(scanSelfCmd)
+
+	scanSelfCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	scanSelfCmd.Flags().StringVar(&scanSelfBDBAURL, "bdba-url", scanSelfBDBAURL, "URL of the BDBA instance that scans the images")
+	scanSelfCmd.Flags().StringVar(&scanSelfBDBAUsername, "bdba-username", scanSelfBDBAUsername, "User name of the BDBA instance")
+	scanSelfCmd.Flags().StringVar(&scanSelfBDBAPasswordFile, "bdba-password-file", scanSelfBDBAPasswordFile, "Absolute path to a file containing the password of the BDBA user")
+	scanSelfCmd.Flags().StringVar(&scanSelfBDBAGroup, "bdba-group", scanSelfBDBAGroup, "Id of the BDBA group the scans are uploaded to (default group of the user)")
+	scanSelfCmd.Flags().DurationVar(&scanSelfTimeout, "timeout", scanSelfTimeout, "Maximum time to wait for the scan of each image")
+	scanSelfCmd.Flags().StringVarP(&scanSelfOutputFormat, "output", "o", scanSelfOutputFormat, "Output format [table|json]")
+	scanSelfCmd.Flags().StringVar(&scanSelfOutputFile, "output-file", scanSelfOutputFile, "Path of the file to write the report to (default standard output)")
+}
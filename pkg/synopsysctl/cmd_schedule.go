@@ -0,0 +1,423 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// scheduledTaskLabel is set on every resource created for a scheduled task, its value is the name of the task
+const scheduledTaskLabel = "synopsys.com/scheduled-task"
+
+// Schedule Command Options and Defaults
+var scheduleCron = ""
+var scheduleImage = globals.DefaultSynopsysctlImage
+var scheduleProfilePath = ""
+var scheduleWebhookURL = ""
+var scheduleWebhookFormat = util.CertificateWebhookFormatJSON
+var scheduleCertificatesWithin = util.CertificateExpiryWarningPeriod
+
+// scheduleCmd deploys CronJobs that run synopsysctl tasks in the cluster
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Schedule recurring Synopsys tasks to run in the cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// scheduleBackupCmd schedules backups
+var scheduleBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Schedule backups of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// scheduleBackupBlackDuckCmd schedules backups of a Black Duck instance, it is the same command as 'backup schedule blackduck'
+var scheduleBackupBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --cron SCHEDULE --output pvc:CLAIM|s3://BUCKET[/PREFIX]",
+	Example:       "synopsysctl schedule backup blackduck <name> -n <namespace> --cron \"0 2 * * *\" --retention 14 --output pvc:<claim>",
+	Short:         "Schedule backups of the databases of a Black Duck instance, the same as 'backup schedule blackduck'",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return scheduleBlackDuckBackup(args[0], namespace)
+	},
+}
+
+// scheduleUpgradeCheckCmd schedules a dry run of upgrade --auto that reports the instances that need to be upgraded
+var scheduleUpgradeCheckCmd = &cobra.Command{
+	Use:           "upgrade-check -n NAMESPACE --cron SCHEDULE",
+	Example:       "synopsysctl schedule upgrade-check -n <namespace> --cron \"0 6 * * 1\" --profile <profile file path>",
+	Short:         "Schedule a check of the instances that need to be upgraded according to the upgrade policies of a profile",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskName := "synopsysctl-upgrade-check"
+		labels := map[string]string{scheduledTaskLabel: taskName}
+
+		if len(scheduleProfilePath) == 0 {
+			return fmt.Errorf("--profile is required to declare the upgrade policies of the scheduled task")
+		}
+		profileData, err := util.ReadFromFile(scheduleProfilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read profile: %+v", err)
+		}
+		profileConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: taskName, Namespace: namespace, Labels: labels},
+			Data:       map[string]string{"profile.yaml": string(profileData)},
+		}
+		if _, err := kubeClient.CoreV1().ConfigMaps(namespace).Create(profileConfigMap); err != nil {
+			if !k8serrors.IsAlreadyExists(err) {
+				return fmt.Errorf("unable to create the profile config map '%s' due to %+v", taskName, err)
+			}
+			if _, err := kubeClient.CoreV1().ConfigMaps(namespace).Update(profileConfigMap); err != nil {
+				return fmt.Errorf("unable to update the profile config map '%s' due to %+v", taskName, err)
+			}
+		}
+
+		rules := []rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+		}
+		if err := createScheduledTaskServiceAccount(kubeClient, taskName, namespace, labels, rules); err != nil {
+			return err
+		}
+
+		podSpec := corev1.PodSpec{
+			ServiceAccountName: taskName,
+			Containers: []corev1.Container{
+				{
+					Name:  "upgrade-check",
+					Image: scheduleImage,
+					Args:  []string{"upgrade", "--auto", "--dry-run", "--profile", "/profile/profile.yaml"},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "profile", MountPath: "/profile", ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "profile",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: taskName}},
+					},
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+		}
+		if err := createScheduledTask(kubeClient, taskName, namespace, scheduleCron, labels, podSpec); err != nil {
+			return err
+		}
+		log.Infof("scheduled upgrade checks in namespace '%s' at '%s', see the results with 'kubectl logs -n %s -l %s=%s'", namespace, scheduleCron, namespace, scheduledTaskLabel, taskName)
+		return nil
+	},
+}
+
+// scheduleCertCheckCmd schedules checks of the certificate expiries that notify a webhook, so that the monitoring
+// doesn't depend on a workstation running synopsysctl
+var scheduleCertCheckCmd = &cobra.Command{
+	Use:           "cert-check -n NAMESPACE --cron SCHEDULE --webhook-url URL",
+	Example:       "synopsysctl schedule cert-check -n <namespace> --cron \"0 7 * * *\" --webhook-url <url>\nsynopsysctl schedule cert-check -n <namespace> --cron \"0 7 * * *\" --webhook-url <url> --webhook-format slack --within 336h",
+	Short:         "Schedule a check of the certificates of the instances that posts the expiring ones to a webhook",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskName := "synopsysctl-cert-check"
+		labels := map[string]string{scheduledTaskLabel: taskName}
+
+		if err := util.ValidateCertificateWebhookFormat(scheduleWebhookFormat); err != nil {
+			return err
+		}
+		if scheduleCertificatesWithin <= 0 {
+			return util.ValidationError("--within must be positive, got '%s'", scheduleCertificatesWithin)
+		}
+
+		// the webhook URL usually embeds a token, so it is kept in a secret instead of the arguments of the CronJob
+		webhookSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: taskName, Namespace: namespace, Labels: labels},
+			StringData: map[string]string{"webhook-url": scheduleWebhookURL},
+		}
+		if _, err := kubeClient.CoreV1().Secrets(namespace).Create(webhookSecret); err != nil {
+			if !k8serrors.IsAlreadyExists(err) {
+				return fmt.Errorf("unable to create the webhook secret '%s' due to %+v", taskName, err)
+			}
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Update(webhookSecret); err != nil {
+				return fmt.Errorf("unable to update the webhook secret '%s' due to %+v", taskName, err)
+			}
+		}
+
+		rules := []rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+		}
+		if err := createScheduledTaskServiceAccount(kubeClient, taskName, namespace, labels, rules); err != nil {
+			return err
+		}
+
+		podSpec := corev1.PodSpec{
+			ServiceAccountName: taskName,
+			Containers: []corev1.Container{
+				{
+					Name:  "cert-check",
+					Image: scheduleImage,
+					Args:  []string{"check", "certificates", "--within", scheduleCertificatesWithin.String(), "--webhook-url", "$(WEBHOOK_URL)", "--webhook-format", scheduleWebhookFormat},
+					Env: []corev1.EnvVar{
+						{
+							Name: "WEBHOOK_URL",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: taskName}, Key: "webhook-url"},
+							},
+						},
+					},
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+		}
+		if err := createScheduledTask(kubeClient, taskName, namespace, scheduleCron, labels, podSpec); err != nil {
+			return err
+		}
+		log.Infof("scheduled certificate checks in namespace '%s' at '%s' that notify the webhook of the certificates expiring within %s", namespace, scheduleCron, scheduleCertificatesWithin)
+		return nil
+	},
+}
+
+// scheduleListCmd lists the scheduled tasks
+var scheduleListCmd = &cobra.Command{
+	Use:           "list -n NAMESPACE",
+	Example:       "synopsysctl schedule list -n <namespace>",
+	Short:         "List the scheduled tasks",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cronJobs, err := kubeClient.BatchV1beta1().CronJobs(namespace).List(metav1.ListOptions{LabelSelector: scheduledTaskLabel})
+		if err != nil {
+			return fmt.Errorf("unable to list the scheduled tasks in namespace '%s' due to %+v", namespace, err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "TASK\tSCHEDULE\tSUSPENDED\tLAST RUN")
+		for _, cronJob := range cronJobs.Items {
+			lastRun := "<never>"
+			if cronJob.Status.LastScheduleTime != nil {
+				lastRun = cronJob.Status.LastScheduleTime.Format("2006-01-02 15:04:05")
+			}
+			suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", cronJob.Name, cronJob.Spec.Schedule, suspended, lastRun)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// scheduleDeleteCmd deletes a scheduled task
+var scheduleDeleteCmd = &cobra.Command{
+	Use:           "delete TASK -n NAMESPACE",
+	Example:       "synopsysctl schedule delete <task> -n <namespace>",
+	Short:         "Delete a scheduled task and the resources created for it",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deleteScheduledTask(kubeClient, args[0], namespace)
+	},
+}
+
+// deleteScheduledTask deletes the CronJob of a scheduled task and the resources created for it, the PVC is kept
+func deleteScheduledTask(kubeClient kubernetes.Interface, taskName string, namespace string) error {
+	if err := kubeClient.BatchV1beta1().CronJobs(namespace).Delete(taskName, &metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("unable to delete scheduled task '%s' in namespace '%s' due to %+v", taskName, namespace, err)
+	}
+	if err := kubeClient.CoreV1().ConfigMaps(namespace).Delete(taskName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete config map '%s' due to %+v", taskName, err)
+	}
+	if err := kubeClient.CoreV1().Secrets(namespace).Delete(taskName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete secret '%s' due to %+v", taskName, err)
+	}
+	if err := kubeClient.CoreV1().ServiceAccounts(namespace).Delete(taskName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete service account '%s' due to %+v", taskName, err)
+	}
+	clusterRoleName := getScheduledTaskClusterRoleName(taskName, namespace)
+	if err := kubeClient.RbacV1().ClusterRoleBindings().Delete(clusterRoleName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete cluster role binding '%s' due to %+v", clusterRoleName, err)
+	}
+	if err := kubeClient.RbacV1().ClusterRoles().Delete(clusterRoleName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete cluster role '%s' due to %+v", clusterRoleName, err)
+	}
+	log.Infof("successfully deleted scheduled task '%s' in namespace '%s'", taskName, namespace)
+	if _, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(taskName, metav1.GetOptions{}); err == nil {
+		log.Infof("the PVC '%s' was kept, delete it with 'kubectl delete pvc -n %s %s' once its data is no longer needed", taskName, namespace, taskName)
+	}
+	return nil
+}
+
+// createScheduledTask creates or updates the CronJob of a scheduled task
+func createScheduledTask(kubeClient kubernetes.Interface, taskName string, namespace string, schedule string, labels map[string]string, podSpec corev1.PodSpec) error {
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: taskName, Namespace: namespace, Labels: labels},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       podSpec,
+					},
+				},
+			},
+		},
+	}
+	if _, err := kubeClient.BatchV1beta1().CronJobs(namespace).Create(cronJob); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("unable to create scheduled task '%s' in namespace '%s' due to %+v", taskName, namespace, err)
+		}
+		if _, err := kubeClient.BatchV1beta1().CronJobs(namespace).Update(cronJob); err != nil {
+			return fmt.Errorf("unable to update scheduled task '%s' in namespace '%s' due to %+v", taskName, namespace, err)
+		}
+	}
+	return nil
+}
+
+// createScheduledTaskServiceAccount creates a service account for the scheduled task that is only allowed to perform the given rules.
+// A task without rules gets a service account without RBAC that doesn't mount its token
+func createScheduledTaskServiceAccount(kubeClient kubernetes.Interface, taskName string, namespace string, labels map[string]string, rules []rbacv1.PolicyRule) error {
+	clusterRoleName := getScheduledTaskClusterRoleName(taskName, namespace)
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: taskName, Namespace: namespace, Labels: labels},
+	}
+	if len(rules) == 0 {
+		automount := false
+		serviceAccount.AutomountServiceAccountToken = &automount
+	}
+	if _, err := kubeClient.CoreV1().ServiceAccounts(namespace).Create(serviceAccount); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create service account '%s' due to %+v", taskName, err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	if _, err := kubeClient.RbacV1().ClusterRoles().Create(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName, Labels: labels},
+		Rules:      rules,
+	}); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create cluster role '%s' due to %+v", clusterRoleName, err)
+	}
+	if _, err := kubeClient.RbacV1().ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName, Labels: labels},
+		Subjects: []rbacv1.Subject{
+			{Kind: "ServiceAccount", Name: taskName, Namespace: namespace},
+		},
+		RoleRef: rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: clusterRoleName},
+	}); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create cluster role binding '%s' due to %+v", clusterRoleName, err)
+	}
+	return nil
+}
+
+// getScheduledTaskClusterRoleName returns the name of the cluster role of a scheduled task, which is unique across namespaces
+func getScheduledTaskClusterRoleName(taskName string, namespace string) string {
+	return fmt.Sprintf("%s-%s", taskName, namespace)
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+
+	scheduleCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the scheduled task")
+	cobra.MarkFlagRequired(scheduleCmd.PersistentFlags(), "namespace")
+
+	// Backups
+	addBackupScheduleFlags(scheduleBackupBlackDuckCmd)
+	scheduleBackupCmd.AddCommand(scheduleBackupBlackDuckCmd)
+	scheduleCmd.AddCommand(scheduleBackupCmd)
+
+	// Upgrade check
+	scheduleUpgradeCheckCmd.Flags().StringVar(&scheduleCron, "cron", scheduleCron, "Schedule of the task in cron format, e.g. \"0 6 * * 1\"")
+	cobra.MarkFlagRequired(scheduleUpgradeCheckCmd.Flags(), "cron")
+	scheduleUpgradeCheckCmd.Flags().StringVar(&scheduleImage, "image", scheduleImage, "Image of synopsysctl that runs the task")
+	scheduleUpgradeCheckCmd.Flags().StringVar(&scheduleProfilePath, "profile", scheduleProfilePath, "Absolute path to a profile file declaring the upgrade policies")
+	scheduleCmd.AddCommand(scheduleUpgradeCheckCmd)
+
+	// Certificate check
+	scheduleCertCheckCmd.Flags().StringVar(&scheduleCron, "cron", scheduleCron, "Schedule of the task in cron format, e.g. \"0 7 * * *\"")
+	cobra.MarkFlagRequired(scheduleCertCheckCmd.Flags(), "cron")
+	scheduleCertCheckCmd.Flags().StringVar(&scheduleWebhookURL, "webhook-url", scheduleWebhookURL, "URL of the webhook that the expiring certificates are posted to")
+	cobra.MarkFlagRequired(scheduleCertCheckCmd.Flags(), "webhook-url")
+	scheduleCertCheckCmd.Flags().StringVar(&scheduleWebhookFormat, "webhook-format", scheduleWebhookFormat, "Format of the webhook request [json|slack]")
+	scheduleCertCheckCmd.Flags().DurationVar(&scheduleCertificatesWithin, "within", scheduleCertificatesWithin, "Notify of the certificates that expire within this duration")
+	scheduleCertCheckCmd.Flags().StringVar(&scheduleImage, "image", scheduleImage, "Image of synopsysctl that runs the task")
+	scheduleCmd.AddCommand(scheduleCertCheckCmd)
+
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleDeleteCmd)
+}
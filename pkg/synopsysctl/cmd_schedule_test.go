@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// scheduledTaskLabels returns the labels of the scheduled task 'task'
+func scheduledTaskLabels() map[string]string {
+	return map[string]string{"app": "blackduck", "name": "bd", scheduledTaskLabel: "task"}
+}
+
+func TestCreateScheduledTask(t *testing.T) {
+	assert := assert.New(t)
+	kubeClient := fake.NewSimpleClientset()
+	labels := scheduledTaskLabels()
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "postgres"}}}
+
+	assert.NoError(createScheduledTask(kubeClient, "task", "ns", "0 2 * * *", labels, podSpec))
+	cronJob, err := kubeClient.BatchV1beta1().CronJobs("ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(labels, cronJob.Labels)
+	assert.Equal(labels, cronJob.Spec.JobTemplate.Labels)
+	assert.Equal(labels, cronJob.Spec.JobTemplate.Spec.Template.Labels)
+	assert.Equal("0 2 * * *", cronJob.Spec.Schedule)
+	assert.Equal(batchv1beta1.ForbidConcurrent, cronJob.Spec.ConcurrencyPolicy)
+	assert.Equal(podSpec, cronJob.Spec.JobTemplate.Spec.Template.Spec)
+
+	// a second run updates the CronJob instead of failing
+	assert.NoError(createScheduledTask(kubeClient, "task", "ns", "0 3 * * *", labels, podSpec))
+	cronJobs, err := kubeClient.BatchV1beta1().CronJobs("ns").List(metav1.ListOptions{LabelSelector: scheduledTaskLabel})
+	assert.NoError(err)
+	assert.Len(cronJobs.Items, 1)
+	assert.Equal("0 3 * * *", cronJobs.Items[0].Spec.Schedule)
+}
+
+func TestCreateScheduledTaskServiceAccount(t *testing.T) {
+	assert := assert.New(t)
+	kubeClient := fake.NewSimpleClientset()
+	labels := scheduledTaskLabels()
+	rules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}}
+
+	assert.NoError(createScheduledTaskServiceAccount(kubeClient, "task", "ns", labels, rules))
+	// a second run keeps the existing resources
+	assert.NoError(createScheduledTaskServiceAccount(kubeClient, "task", "ns", labels, rules))
+
+	serviceAccount, err := kubeClient.CoreV1().ServiceAccounts("ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(labels, serviceAccount.Labels)
+
+	clusterRole, err := kubeClient.RbacV1().ClusterRoles().Get("task-ns", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(labels, clusterRole.Labels)
+	assert.Equal(rules, clusterRole.Rules)
+
+	clusterRoleBinding, err := kubeClient.RbacV1().ClusterRoleBindings().Get("task-ns", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(labels, clusterRoleBinding.Labels)
+	assert.Equal([]rbacv1.Subject{{Kind: "ServiceAccount", Name: "task", Namespace: "ns"}}, clusterRoleBinding.Subjects)
+	assert.Equal(rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "task-ns"}, clusterRoleBinding.RoleRef)
+
+	clusterRoles, err := kubeClient.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Len(clusterRoles.Items, 1)
+}
+
+func TestCreateScheduledTaskServiceAccountWithoutRules(t *testing.T) {
+	assert := assert.New(t)
+	kubeClient := fake.NewSimpleClientset()
+
+	assert.NoError(createScheduledTaskServiceAccount(kubeClient, "task", "ns", scheduledTaskLabels(), nil))
+
+	serviceAccount, err := kubeClient.CoreV1().ServiceAccounts("ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.NotNil(serviceAccount.AutomountServiceAccountToken)
+	assert.False(*serviceAccount.AutomountServiceAccountToken)
+
+	// no RBAC is created for the service account
+	clusterRoles, err := kubeClient.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Empty(clusterRoles.Items)
+	clusterRoleBindings, err := kubeClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Empty(clusterRoleBindings.Items)
+}
+
+func TestDeleteScheduledTask(t *testing.T) {
+	assert := assert.New(t)
+	labels := scheduledTaskLabels()
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "ns", Labels: labels}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "ns", Labels: labels}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "ns", Labels: labels}},
+		// the task of the same name in another namespace
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "other-ns", Labels: labels}},
+	)
+	rules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}}
+	assert.NoError(createScheduledTaskServiceAccount(kubeClient, "task", "ns", labels, rules))
+	assert.NoError(createScheduledTaskServiceAccount(kubeClient, "task", "other-ns", labels, rules))
+	assert.NoError(createScheduledTask(kubeClient, "task", "ns", "0 2 * * *", labels, corev1.PodSpec{}))
+	assert.NoError(createScheduledTask(kubeClient, "task", "other-ns", "0 2 * * *", labels, corev1.PodSpec{}))
+
+	assert.NoError(deleteScheduledTask(kubeClient, "task", "ns"))
+
+	_, err := kubeClient.BatchV1beta1().CronJobs("ns").Get("task", metav1.GetOptions{})
+	assert.Error(err)
+	_, err = kubeClient.CoreV1().ConfigMaps("ns").Get("task", metav1.GetOptions{})
+	assert.Error(err)
+	_, err = kubeClient.CoreV1().Secrets("ns").Get("task", metav1.GetOptions{})
+	assert.Error(err)
+	_, err = kubeClient.CoreV1().ServiceAccounts("ns").Get("task", metav1.GetOptions{})
+	assert.Error(err)
+	_, err = kubeClient.RbacV1().ClusterRoles().Get("task-ns", metav1.GetOptions{})
+	assert.Error(err)
+	_, err = kubeClient.RbacV1().ClusterRoleBindings().Get("task-ns", metav1.GetOptions{})
+	assert.Error(err)
+
+	// the backups in the PVC and the task in the other namespace are kept
+	_, err = kubeClient.CoreV1().PersistentVolumeClaims("ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	_, err = kubeClient.BatchV1beta1().CronJobs("other-ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	_, err = kubeClient.CoreV1().ConfigMaps("other-ns").Get("task", metav1.GetOptions{})
+	assert.NoError(err)
+	_, err = kubeClient.RbacV1().ClusterRoles().Get("task-other-ns", metav1.GetOptions{})
+	assert.NoError(err)
+
+	// the task no longer exists
+	assert.Error(deleteScheduledTask(kubeClient, "task", "ns"))
+}
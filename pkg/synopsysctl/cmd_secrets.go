@@ -0,0 +1,337 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Secrets Command Options and Defaults
+var secretsShow = false
+var secretsSeedsFile = ""
+
+// instanceSecret is a secret that an instance depends on
+type instanceSecret struct {
+	Secret    *corev1.Secret
+	MountedBy []string
+}
+
+// secretsCmd lists and inspects the secrets of an instance
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "List and inspect the secrets of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// secretsListCmd lists the secrets that an instance depends on
+var secretsListCmd = &cobra.Command{
+	Use:           "list PRODUCT NAME -n NAMESPACE",
+	Example:       "synopsysctl secrets list blackduck <name> -n <namespace>\nsynopsysctl secrets list alert <name> -n <namespace>",
+	Short:         "List the secrets that an Alert or Black Duck instance depends on",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secrets, err := getInstanceSecrets(args[0], args[1], namespace)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tKEYS\tSIZE\tAGE\tMOUNTED BY")
+		for _, secret := range secrets {
+			mountedBy := strings.Join(secret.MountedBy, ",")
+			if len(mountedBy) == 0 {
+				mountedBy = "<none>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", secret.Secret.Name, secret.Secret.Type, len(secret.Secret.Data), getSecretSize(secret.Secret),
+				time.Since(secret.Secret.CreationTimestamp.Time).Round(time.Second), mountedBy)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// secretsInspectCmd shows the keys, and optionally the values, of the secrets that an instance depends on
+var secretsInspectCmd = &cobra.Command{
+	Use:           "inspect PRODUCT NAME [SECRET] -n NAMESPACE",
+	Example:       "synopsysctl secrets inspect blackduck <name> -n <namespace>\nsynopsysctl secrets inspect alert <name> <secret name> -n <namespace> --show",
+	Short:         "Inspect the secrets that an Alert or Black Duck instance depends on",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secrets, err := getInstanceSecrets(args[0], args[1], namespace)
+		if err != nil {
+			return err
+		}
+		if len(args) == 3 {
+			filtered := []instanceSecret{}
+			for _, secret := range secrets {
+				if secret.Secret.Name == args[2] {
+					filtered = append(filtered, secret)
+				}
+			}
+			if len(filtered) == 0 {
+				return fmt.Errorf("secret '%s' is not used by %s '%s' in namespace '%s'", args[2], args[0], args[1], namespace)
+			}
+			secrets = filtered
+		}
+
+		if secretsShow {
+			confirmed, err := confirmAction(fmt.Sprintf("this will print the decoded values of %d secret(s) to the terminal", len(secrets)))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("secret values were not shown because the action was not confirmed")
+			}
+		}
+
+		for _, secret := range secrets {
+			fmt.Printf("Name:       %s\n", secret.Secret.Name)
+			fmt.Printf("Type:       %s\n", secret.Secret.Type)
+			fmt.Printf("Created:    %s\n", secret.Secret.CreationTimestamp.Format(time.RFC3339))
+			fmt.Printf("Mounted by: %s\n", strings.Join(secret.MountedBy, ", "))
+			fmt.Printf("Data:\n")
+			keys := []string{}
+			for key := range secret.Secret.Data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if secretsShow {
+					fmt.Printf("  %s: %s\n", key, string(secret.Secret.Data[key]))
+				} else {
+					fmt.Printf("  %s: %d bytes\n", key, len(secret.Secret.Data[key]))
+				}
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// secretsExportSeedsCmd writes the generated credentials of an instance to an encrypted seed secrets file
+var secretsExportSeedsCmd = &cobra.Command{
+	Use:           "export-seeds PRODUCT NAME -n NAMESPACE --file PATH",
+	Example:       fmt.Sprintf("%s=<passphrase> synopsysctl secrets export-seeds blackduck <name> -n <namespace> --file seeds.json", util.SecretSeedsPassphraseEnv),
+	Short:         "Export the generated credentials of a Black Duck instance for 'create --seed-secrets-file'",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lookups, err := getSecretSeedLookups(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		passphrase, err := getSecretSeedsPassphrase()
+		if err != nil {
+			return err
+		}
+		seeds, err := util.GetSecretSeeds(kubeClient, namespace, lookups)
+		if err != nil {
+			return err
+		}
+		if len(seeds) == 0 {
+			return fmt.Errorf("%s '%s' in namespace '%s' has no generated credentials", args[0], args[1], namespace)
+		}
+		data, err := util.EncryptSecretSeeds(seeds, passphrase)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(secretsSeedsFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write the seed secrets file '%s' due to %+v", secretsSeedsFile, err)
+		}
+		log.Infof("exported %d credential(s) of %s '%s' to '%s'", len(seeds), args[0], args[1], secretsSeedsFile)
+		return nil
+	},
+}
+
+// validateProductArgs verifies the number of arguments and that the first argument is a supported product
+func validateProductArgs(counts ...int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		valid := false
+		expected := []string{}
+		for _, count := range counts {
+			if len(args) == count {
+				valid = true
+			}
+			expected = append(expected, fmt.Sprintf("%d", count))
+		}
+		if !valid {
+			cmd.Help()
+			return fmt.Errorf("this command takes %s arguments, but got %+v", strings.Join(expected, " or "), args)
+		}
+		// accept the product aliases, e.g. 'verify bd NAME'
+		if product := getProductName(args[0]); len(product) > 0 {
+			args[0] = product
+		}
+		if args[0] != util.AlertName && args[0] != util.BlackDuckName {
+			return fmt.Errorf("product must be '%s' or '%s', but got '%s'", util.AlertName, util.BlackDuckName, args[0])
+		}
+		return nil
+	}
+}
+
+// getInstanceSecrets returns the secrets created by the chart, the secrets created by synopsysctl and referenced in the Helm
+// values, and the secrets mounted by the pods of the instance, along with the pods that mount them
+func getInstanceSecrets(app string, name string, namespace string) ([]instanceSecret, error) {
+	labelSelector := fmt.Sprintf("app=%s, name=%s", app, name)
+	secretNames := map[string]bool{}
+
+	labeledSecrets, err := util.ListSecrets(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the secrets of %s '%s' in namespace '%s' due to %+v", app, name, namespace, err)
+	}
+	for _, secret := range labeledSecrets.Items {
+		secretNames[secret.Name] = true
+	}
+
+	helmReleaseName := name
+	if app == util.AlertName {
+		helmReleaseName = fmt.Sprintf("%s%s", name, globals.AlertPostSuffix)
+	}
+	if helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath); err == nil {
+		for _, secretName := range util.GetSecretNamesFromHelmValues(helmRelease.Config) {
+			secretNames[secretName] = true
+		}
+	} else {
+		log.Debugf("unable to get the Helm release '%s' in namespace '%s': %+v", helmReleaseName, namespace, err)
+	}
+
+	pods, err := util.ListPodsWithLabels(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of %s '%s' in namespace '%s' due to %+v", app, name, namespace, err)
+	}
+	mountedBy := map[string][]string{}
+	for _, pod := range pods.Items {
+		for _, secretName := range getSecretNamesFromPodSpec(pod.Spec) {
+			secretNames[secretName] = true
+			mountedBy[secretName] = append(mountedBy[secretName], pod.Name)
+		}
+	}
+
+	if len(secretNames) == 0 {
+		return nil, fmt.Errorf("no secrets found for %s '%s' in namespace '%s'", app, name, namespace)
+	}
+
+	sortedNames := []string{}
+	for secretName := range secretNames {
+		sortedNames = append(sortedNames, secretName)
+	}
+	sort.Strings(sortedNames)
+
+	secrets := []instanceSecret{}
+	for _, secretName := range sortedNames {
+		secret, err := util.GetSecret(kubeClient, namespace, secretName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				log.Warnf("secret '%s' is referenced by %s '%s' but does not exist", secretName, app, name)
+				continue
+			}
+			return nil, fmt.Errorf("unable to get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		secrets = append(secrets, instanceSecret{Secret: secret, MountedBy: mountedBy[secretName]})
+	}
+	return secrets, nil
+}
+
+// getSecretNamesFromPodSpec returns the secrets mounted as volumes, used in environment variables or used to pull images
+func getSecretNamesFromPodSpec(spec corev1.PodSpec) []string {
+	found := map[string]bool{}
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			found[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					found[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+	for _, pullSecret := range spec.ImagePullSecrets {
+		found[pullSecret.Name] = true
+	}
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				found[envFrom.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				found[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+	secretNames := []string{}
+	for secretName := range found {
+		secretNames = append(secretNames, secretName)
+	}
+	sort.Strings(secretNames)
+	return secretNames
+}
+
+// getSecretSize returns the total number of bytes stored in the secret
+func getSecretSize(secret *corev1.Secret) int {
+	size := 0
+	for _, value := range secret.Data {
+		size += len(value)
+	}
+	return size
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+
+	secretsCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(secretsCmd.PersistentFlags(), "namespace")
+	secretsCmd.AddCommand(secretsListCmd)
+
+	secretsInspectCmd.Flags().BoolVar(&secretsShow, "show", secretsShow, "If true, print the decoded secret values after confirmation")
+	secretsCmd.AddCommand(secretsInspectCmd)
+
+	secretsExportSeedsCmd.Flags().StringVar(&secretsSeedsFile, "file", secretsSeedsFile, "Path of the seed secrets file to write")
+	cobra.MarkFlagRequired(secretsExportSeedsCmd.Flags(), "file")
+	secretsCmd.AddCommand(secretsExportSeedsCmd)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateProductArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		counts   []int
+		args     []string
+		product  string
+		hasError bool
+	}{
+		{counts: []int{2}, args: []string{"blackduck", "bd1"}, product: util.BlackDuckName},
+		{counts: []int{2}, args: []string{"alert", "alt1"}, product: util.AlertName},
+		// the aliases are replaced by the product
+		{counts: []int{2}, args: []string{"bd", "bd1"}, product: util.BlackDuckName},
+		{counts: []int{2, 3}, args: []string{"blackduck", "bd1", "secret"}, product: util.BlackDuckName},
+		{counts: []int{2}, args: []string{"blackduck"}, hasError: true},
+		{counts: []int{2}, args: []string{"blackduck", "bd1", "secret"}, hasError: true},
+		{counts: []int{2}, args: []string{"opssight", "ops1"}, hasError: true},
+		{counts: []int{2}, args: []string{"ops", "ops1"}, hasError: true},
+	}
+
+	for _, test := range tests {
+		cmd := &cobra.Command{}
+		cmd.SetOutput(ioutil.Discard)
+		err := validateProductArgs(test.counts...)(cmd, test.args)
+		if test.hasError {
+			assert.Error(err, "%+v", test.args)
+		} else {
+			assert.NoError(err, "%+v", test.args)
+			assert.Equal(test.product, test.args[0])
+		}
+	}
+}
+
+func TestGetSecretNamesFromPodSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "certificate", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "bd-blackduck-webserver-certificate"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-proxy-certificate"}}},
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-config"}}},
+			}}}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-config"}}}},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		InitContainers: []corev1.Container{
+			{EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-db-creds"}}}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Env: []corev1.EnvVar{
+					{Name: "PLAIN", Value: "value"},
+					{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-db-creds"}, Key: "password"}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "bd-blackduck-config"}}}},
+			},
+		},
+	}
+	assert.Equal([]string{"bd-blackduck-db-creds", "bd-blackduck-proxy-certificate", "bd-blackduck-webserver-certificate", "registry"}, getSecretNamesFromPodSpec(spec))
+	assert.Equal([]string{}, getSecretNamesFromPodSpec(corev1.PodSpec{}))
+}
+
+func TestGetSecretSize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, getSecretSize(&corev1.Secret{}))
+	assert.Equal(9, getSecretSize(&corev1.Secret{Data: map[string][]byte{"user": []byte("admin"), "password": []byte("blah")}}))
+}
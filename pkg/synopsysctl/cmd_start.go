@@ -26,6 +26,7 @@ import (
 
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -38,7 +39,7 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start a Synopsys resource",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -52,7 +53,7 @@ var startAlertCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -72,13 +73,10 @@ var startAlertCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Running")
-
-		err = util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.AlertChartRepository, instanceStatusRunning)
 		if err != nil {
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg))
 		}
 
 		log.Infof("successfully submitted start Alert '%s' in namespace '%s'", alertName, namespace)
@@ -97,7 +95,7 @@ var startBlackDuckCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -115,12 +113,9 @@ var startBlackDuckCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Running")
-
-		err = util.UpdateWithHelm3(args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.BlackDuckChartRepository, instanceStatusRunning)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return fmt.Errorf("failed to create Blackduck resources: %w", err)
 		}
 		return nil
 	},
@@ -136,7 +131,7 @@ var startOpsSightCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -154,12 +149,9 @@ var startOpsSightCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Running")
-
-		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.OpsSightChartRepository, instanceStatusRunning)
 		if err != nil {
-			return fmt.Errorf("failed to create OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to create OpsSight resources: %w", err)
 		}
 		return nil
 	},
@@ -169,6 +161,8 @@ func init() {
 	startAlertCobraHelper = *alertctl.NewHelmValuesFromCobraFlags()
 
 	rootCmd.AddCommand(startCmd)
+	addPlanFlags(startCmd)
+	addDryRunFlag(startCmd)
 
 	startAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(startAlertCmd.Flags(), "namespace")
@@ -0,0 +1,379 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BDBA RabbitMQ management defaults of the chart
+var bdbaRabbitMQManagementPort = "15672"
+var bdbaRabbitMQDefaultUser = "user"
+
+// statusOutputFormat is set by the --output flag of the status commands
+var statusOutputFormat = "table"
+
+// Knowledge Base check flags of the Black Duck status command
+var statusKBCheck = false
+var statusKBMaxAge = util.BlackDuckKBDefaultMaxAge
+var statusBlackDuckURL = ""
+var statusBlackDuckAPIToken = ""
+
+// statusEventsSince and statusEventsLimit select the recent warning events printed by the status commands
+var statusEventsSince = time.Hour
+var statusEventsLimit = 10
+
+// statusKBTimeout is the timeout of the requests to the Knowledge Base endpoints and to the Black Duck API
+var statusKBTimeout = 10 * time.Second
+
+// statusCmd shows the status of a Synopsys resource
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return messages.Error(messages.MustSpecifySubCommand, nil)
+	},
+}
+
+// statusBDBACmd shows the health, the worker scaling and the queue depth of a BDBA instance
+var statusBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl status bdba -n <namespace>",
+	Short:         "Show the health, the workers and the RabbitMQ queues of a BDBA instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		release, err := util.GetWithHelm3(globals.BDBAName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("couldn't find BDBA in namespace '%s'", namespace)
+		}
+		values := util.GetReleaseValues(release)
+		concurrency := getIntHelmValue(values, []string{"worker", "concurrency"}, bdba.DefaultFlagTree.WorkerConcurrency)
+		replicas := getIntHelmValue(values, []string{"worker", "replicas"}, bdba.DefaultFlagTree.WorkerReplicas)
+
+		readyReplicas := 0
+		deployments, err := util.ListDeployments(kubeClient, namespace, "")
+		if err != nil {
+			return fmt.Errorf("unable to list the deployments in namespace '%s' due to %+v", namespace, err)
+		}
+		for _, deployment := range deployments.Items {
+			if strings.HasPrefix(deployment.Name, globals.BDBAName) && strings.Contains(deployment.Name, "worker") {
+				readyReplicas += int(deployment.Status.ReadyReplicas)
+			}
+		}
+
+		queues, err := getBDBAQueues(values)
+		if err != nil {
+			return err
+		}
+
+		product, err := products.New(globals.BDBAName, globals.BDBAName, namespace, getProductClients())
+		if err != nil {
+			return err
+		}
+		if err := printProductStatus(product); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		options := tableOptions(statusOutputFormat)
+		options.SortBy = ""
+		workers := util.NewTable(util.TableColumn{Name: "WORKERS"}, util.TableColumn{Name: "READY"}, util.TableColumn{Name: "CONCURRENCY"}, util.TableColumn{Name: "UTILIZATION"})
+		workers.AddRow(replicas, readyReplicas, concurrency, fmt.Sprintf("%.0f%%", bdba.WorkerUtilization(queues, readyReplicas, concurrency)))
+		if err := workers.Print(os.Stdout, options); err != nil {
+			return err
+		}
+		fmt.Println()
+		queueTable := util.NewTable(util.TableColumn{Name: "QUEUE"}, util.TableColumn{Name: "MESSAGES"}, util.TableColumn{Name: "READY"}, util.TableColumn{Name: "UNACKED"}, util.TableColumn{Name: "CONSUMERS"})
+		for _, queue := range queues {
+			queueTable.AddRow(queue.Name, queue.Messages, queue.MessagesReady, queue.MessagesUnacknowledged, queue.Consumers)
+		}
+		return queueTable.Print(os.Stdout, options)
+	},
+}
+
+// newStatusCmd returns the status command of a product whose instances are identified by name
+func newStatusCmd(app string, product string) *cobra.Command {
+	return &cobra.Command{
+		Use:           fmt.Sprintf("%s NAME -n NAMESPACE", app),
+		Example:       fmt.Sprintf("synopsysctl status %s <name> -n <namespace>", app),
+		Short:         fmt.Sprintf("Show the version, components, endpoints and health of a %s instance", product),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				cmd.Help()
+				return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			product, err := products.New(app, args[0], namespace, getProductClients())
+			if err != nil {
+				return err
+			}
+			if err := printProductStatus(product); err != nil {
+				return err
+			}
+			if app == util.BlackDuckName && statusKBCheck {
+				fmt.Println()
+				return printBlackDuckKBStatus(args[0], namespace)
+			}
+			return nil
+		},
+	}
+}
+
+// getProductClients returns the clients of the cluster for inspecting the products
+func getProductClients() products.Clients {
+	return products.Clients{RestConfig: restconfig, KubeClient: kubeClient, KubeConfig: kubeConfigPath}
+}
+
+// printProductStatus prints the version, components, endpoints and health of an instance
+func printProductStatus(product products.Product) error {
+	if format := strings.ToLower(statusOutputFormat); format != "table" && format != "wide" {
+		return util.ValidationError("output format must be 'table' or 'wide', got '%s'", statusOutputFormat)
+	}
+	version, err := product.Version()
+	if err != nil {
+		return err
+	}
+	components, err := product.Components()
+	if err != nil {
+		return err
+	}
+	endpoints, err := product.Endpoints()
+	if err != nil {
+		return err
+	}
+	health, err := product.HealthCheck()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name:      %s\n", product.Name())
+	fmt.Printf("Namespace: %s\n", product.Namespace())
+	fmt.Printf("Version:   %s\n", version)
+	fmt.Printf("Healthy:   %t\n", health.Healthy)
+	for _, finding := range health.Findings {
+		fmt.Printf("  - %s\n", finding)
+	}
+	fmt.Println()
+	options := tableOptions(statusOutputFormat)
+	componentTable := util.NewTable(
+		util.TableColumn{Name: "COMPONENT"},
+		util.TableColumn{Name: "KIND"},
+		util.TableColumn{Name: "READY"},
+		util.TableColumn{Name: "RESTARTS"},
+		util.TableColumn{Name: "READY REPLICAS", Wide: true},
+		util.TableColumn{Name: "UPDATED REPLICAS", Wide: true},
+		util.TableColumn{Name: "REPLICAS", Wide: true},
+	)
+	for _, component := range components {
+		componentTable.AddRow(component.Name, component.Kind, fmt.Sprintf("%d/%d", component.ReadyReplicas, component.Replicas), component.Restarts, component.ReadyReplicas, component.UpdatedReplicas, component.Replicas)
+	}
+	if err := componentTable.Print(os.Stdout, options); err != nil {
+		return err
+	}
+	if len(endpoints) > 0 {
+		fmt.Println()
+		// --sort-by sorts the components, the endpoints keep their order
+		options.SortBy = ""
+		endpointTable := util.NewTable(util.TableColumn{Name: "ENDPOINT"}, util.TableColumn{Name: "TYPE"}, util.TableColumn{Name: "ADDRESS"})
+		for _, endpoint := range endpoints {
+			endpointTable.AddRow(endpoint.Name, endpoint.Type, endpoint.Address)
+		}
+		if err := endpointTable.Print(os.Stdout, options); err != nil {
+			return err
+		}
+	}
+	return printRecentWarningEvents(product)
+}
+
+// printRecentWarningEvents prints the last warning events of the objects of the instance
+func printRecentWarningEvents(product products.Product) error {
+	if statusEventsLimit <= 0 {
+		return nil
+	}
+	components, err := getInstanceObjectComponents(product.Namespace(), product.LabelSelector())
+	if err != nil {
+		return fmt.Errorf("unable to list the objects of '%s' in namespace '%s' due to %+v", product.Name(), product.Namespace(), err)
+	}
+	events, err := kubeClient.CoreV1().Events(product.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the events in namespace '%s' due to %+v", product.Namespace(), err)
+	}
+	warnings := util.GetRecentWarningEvents(util.AggregateInstanceEvents(events.Items, components, time.Now().Add(-statusEventsSince)), statusEventsLimit)
+	fmt.Println()
+	if len(warnings) == 0 {
+		fmt.Printf("No warning events in the last %s\n", statusEventsSince)
+		return nil
+	}
+	fmt.Printf("Warning events in the last %s:\n", statusEventsSince)
+	printInstanceEvents(os.Stdout, warnings, true)
+	return nil
+}
+
+// printBlackDuckKBStatus prints the connectivity of the jobrunner to the Knowledge Base endpoints, through the proxy of
+// the instance, and when the Knowledge Base data was last updated if the Black Duck API can be queried
+func printBlackDuckKBStatus(name string, namespace string) error {
+	status := util.KBStatus{}
+	jobRunnerPod, err := util.FilterPodByNamePrefixInNamespace(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "jobrunner"))
+	if err != nil {
+		return fmt.Errorf("unable to filter the jobrunner pod in namespace '%s' due to %+v", namespace, err)
+	}
+	req := util.CreateExecContainerRequest(kubeClient, jobRunnerPod, "/bin/sh")
+	stdout, err := util.ExecContainer(restconfig, req, []string{util.GetKBConnectivityScript(util.BlackDuckKBEndpoints, statusKBTimeout)})
+	if err != nil {
+		return fmt.Errorf("unable to exec into the jobrunner pod in namespace '%s' due to %+v", namespace, err)
+	}
+	status.Endpoints = util.ParseKBConnectivity(stdout)
+
+	apiToken := statusBlackDuckAPIToken
+	if len(apiToken) == 0 {
+		apiToken = os.Getenv("BLACKDUCK_API_TOKEN")
+	}
+	if len(statusBlackDuckURL) > 0 && len(apiToken) > 0 {
+		client := util.NewProductAPIClient(statusBlackDuckURL, &util.BlackDuckAPITokenAuth{APIToken: apiToken}, statusKBTimeout)
+		if status.LastUpdate, err = util.GetLastKBUpdate(client); err != nil {
+			return err
+		}
+		status.Checked = true
+	}
+
+	fmt.Println("Knowledge Base:")
+	switch {
+	case !status.Checked:
+		fmt.Println("Last update: unknown, set --blackduck-url and --api-token to check the freshness of the vulnerability data")
+	case status.LastUpdate == nil:
+		fmt.Println("Last update: never")
+	default:
+		fmt.Printf("Last update: %s (%s ago)\n", status.LastUpdate.Format(time.RFC3339), time.Since(*status.LastUpdate).Round(time.Minute))
+	}
+	findings := util.GetKBFindings(status, statusKBMaxAge, time.Now())
+	fmt.Printf("Healthy:     %t\n", len(findings) == 0)
+	for _, finding := range findings {
+		fmt.Printf("  - %s\n", finding)
+	}
+	fmt.Println()
+	options := tableOptions(statusOutputFormat)
+	options.SortBy = ""
+	endpointTable := util.NewTable(util.TableColumn{Name: "KB ENDPOINT"}, util.TableColumn{Name: "REACHABLE"}, util.TableColumn{Name: "HTTP STATUS"})
+	for _, endpoint := range status.Endpoints {
+		endpointTable.AddRow(endpoint.URL, endpoint.Reachable, endpoint.StatusCode)
+	}
+	return endpointTable.Print(os.Stdout, options)
+}
+
+// getBDBAQueues lists the queues of the BDBA RabbitMQ through the management API, proxied by the Kubernetes API server
+func getBDBAQueues(values map[string]interface{}) ([]bdba.QueueStatus, error) {
+	rabbitMQName := fmt.Sprintf("%s-rabbitmq", globals.BDBAName)
+	secret, err := util.GetSecret(kubeClient, namespace, rabbitMQName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the RabbitMQ secret '%s' in namespace '%s' due to %+v", rabbitMQName, namespace, err)
+	}
+	user, ok := util.GetHelmValueFromMap(values, []string{"rabbitmq", "rabbitmq", "username"}).(string)
+	if !ok || len(user) == 0 {
+		user = bdbaRabbitMQDefaultUser
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", user, secret.Data["rabbitmq-password"])))
+	data, err := kubeClient.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("services").
+		Name(fmt.Sprintf("http:%s:%s", rabbitMQName, bdbaRabbitMQManagementPort)).
+		SubResource("proxy").
+		Suffix("api/queues").
+		SetHeader("Authorization", fmt.Sprintf("Basic %s", auth)).
+		DoRaw()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the queues from the RabbitMQ management API due to %+v", err)
+	}
+	return bdba.ParseQueues(data)
+}
+
+// getIntHelmValue returns an integer Helm value, or the default if it isn't set
+func getIntHelmValue(values map[string]interface{}, keyList []string, defaultValue int) int {
+	switch value := util.GetHelmValueFromMap(values, keyList).(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return defaultValue
+}
+
+// addStatusOutputFlags adds the flags of the output of the tables to a status command
+func addStatusOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&statusOutputFormat, "output", "o", statusOutputFormat, "Output format of the tables [table|wide]")
+	addTableFlags(cmd, "Sort the components by the column, e.g. kind, restarts or ready-replicas")
+	cmd.Flags().DurationVar(&statusEventsSince, "events-since", statusEventsSince, "Print the warning events of the instance of this period")
+	cmd.Flags().IntVar(&statusEventsLimit, "events-limit", statusEventsLimit, "Maximum number of warning events to print, 0 to not print the events")
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	for _, product := range []struct{ app, name string }{
+		{app: util.AlertName, name: "Alert"},
+		{app: util.BlackDuckName, name: "Black Duck"},
+		{app: util.OpsSightName, name: "OpsSight"},
+	} {
+		statusProductCmd := newStatusCmd(product.app, product.name)
+		statusProductCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+		cobra.MarkFlagRequired(statusProductCmd.Flags(), "namespace")
+		addStatusOutputFlags(statusProductCmd)
+		if product.app == util.BlackDuckName {
+			statusProductCmd.Flags().BoolVar(&statusKBCheck, "kb-check", statusKBCheck, "Check the connectivity to the Knowledge Base through the configured proxy and the freshness of the vulnerability data")
+			statusProductCmd.Flags().DurationVar(&statusKBMaxAge, "kb-max-age", statusKBMaxAge, "Age after which the vulnerability data is stale")
+			statusProductCmd.Flags().StringVar(&statusBlackDuckURL, "blackduck-url", statusBlackDuckURL, "URL of the Black Duck API to get the last Knowledge Base update from, e.g. https://blackduck.example.com")
+			statusProductCmd.Flags().StringVar(&statusBlackDuckAPIToken, "api-token", statusBlackDuckAPIToken, "Black Duck API token to get the last Knowledge Base update with [default: $BLACKDUCK_API_TOKEN]")
+			statusProductCmd.Example += fmt.Sprintf("\nsynopsysctl status %s <name> -n <namespace> --kb-check --blackduck-url https://blackduck.example.com", product.app)
+		}
+		statusCmd.AddCommand(statusProductCmd)
+	}
+
+	statusBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(statusBDBACmd.Flags(), "namespace")
+	addStatusOutputFlags(statusBDBACmd)
+	statusCmd.AddCommand(statusBDBACmd)
+}
@@ -26,6 +26,7 @@ import (
 
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -38,7 +39,7 @@ var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop a Synopsys resource",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -52,7 +53,7 @@ var stopAlertCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -72,13 +73,10 @@ var stopAlertCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
-
-		err = util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.AlertChartRepository, instanceStatusStopped)
 		if err != nil {
 			cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-			return fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg)
+			return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to create Alert resources: %+v", cleanErrorMsg))
 		}
 
 		log.Infof("successfully submitted stop Alert '%s' in namespace '%s'", alertName, namespace)
@@ -97,7 +95,7 @@ var stopBlackDuckCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -114,12 +112,9 @@ var stopBlackDuckCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
-
-		err = util.UpdateWithHelm3(args[0], namespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.BlackDuckChartRepository, instanceStatusStopped)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return fmt.Errorf("failed to create Blackduck resources: %w", err)
 		}
 		return nil
 	},
@@ -135,7 +130,7 @@ var stopOpsSightCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -153,12 +148,9 @@ var stopOpsSightCmd = &cobra.Command{
 			return fmt.Errorf("failed to set the app resources location due to %+v", err)
 		}
 
-		helmValuesMap := instance.Config
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
-
-		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
+		err = setInstanceStatus(instance, globals.OpsSightChartRepository, instanceStatusStopped)
 		if err != nil {
-			return fmt.Errorf("failed to create OpsSight resources: %+v", err)
+			return fmt.Errorf("failed to create OpsSight resources: %w", err)
 		}
 		return nil
 	},
@@ -168,6 +160,8 @@ func init() {
 	stopAlertCobraHelper = *alertctl.NewHelmValuesFromCobraFlags()
 
 	rootCmd.AddCommand(stopCmd)
+	addPlanFlags(stopCmd)
+	addDryRunFlag(stopCmd)
 
 	stopAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(stopAlertCmd.Flags(), "namespace")
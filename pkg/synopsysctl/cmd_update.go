@@ -38,8 +38,11 @@ import (
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
 	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
 	blackduck "github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/coverity"
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
 	opssight "github.com/blackducksoftware/synopsysctl/pkg/opssight"
+	"github.com/blackducksoftware/synopsysctl/pkg/polaris"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -57,6 +60,11 @@ var updateAlertCobraHelper alert.HelmValuesFromCobraFlags
 var updateBlackDuckCobraHelper blackduck.HelmValuesFromCobraFlags
 var updateOpsSightCobraHelper opssight.HelmValuesFromCobraFlags
 var updateBDBACobraHelper bdba.HelmValuesFromCobraFlags
+var updateCoverityCobraHelper coverity.HelmValuesFromCobraFlags
+var updatePolarisCobraHelper polaris.HelmValuesFromCobraFlags
+
+// Update Command Options and Defaults
+var updateMigrationTimeout = 2 * time.Hour
 
 // updateCmd provides functionality to update/upgrade features of
 // Synopsys resources
@@ -64,7 +72,7 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update a Synopsys resource",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("must specify a sub-command")
+		return messages.Error(messages.MustSpecifySubCommand, nil)
 	},
 }
 
@@ -82,7 +90,7 @@ var updateAlertCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -137,6 +145,10 @@ var updateAlertCmd = &cobra.Command{
 			return err
 		}
 
+		if err := waitForInstanceReady(util.AlertName, alertName, namespace); err != nil {
+			return err
+		}
+
 		log.Infof("Alert has been successfully Updated in namespace '%s'!", namespace)
 
 		return nil
@@ -153,6 +165,9 @@ func updateAlertHelmBased(cmd *cobra.Command, helmReleaseName string, alertName
 	updateAlertCobraHelper.SetArgs(helmRelease.Config)
 
 	// Update Helm Values with flags
+	if err := setValuesFromFiles(&updateAlertCobraHelper); err != nil {
+		return err
+	}
 	helmValuesMap, err := updateAlertCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 	if err != nil {
 		return err
@@ -205,50 +220,69 @@ func updateAlertHelmBased(cmd *cobra.Command, helmReleaseName string, alertName
 		if err != nil {
 			log.Fatalf("failed to read certificate file: %+v", err)
 		}
+		alertHostname, _ := util.GetHelmValueFromMap(helmValuesMap, []string{"environs", "ALERT_HOSTNAME"}).(string)
+		if err := util.ValidateCertificateAndKey([]byte(certificateData), []byte(certificateKeyData), alertHostname); err != nil {
+			return fmt.Errorf("invalid Alert certificate: %+v", err)
+		}
 		customCertificateSecretName := "alert-custom-certificate"
 		customCertificateSecret := alert.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData)
 		util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
-		if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil {
-			if k8serrors.IsAlreadyExists(err) {
-				if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&customCertificateSecret); err != nil {
-					return fmt.Errorf("failed to update certificate secret: %+v", err)
+		if !skipForPlan(util.PlanActionApply, "Secret", customCertificateSecretName, namespace) {
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil {
+				if k8serrors.IsAlreadyExists(err) {
+					if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&customCertificateSecret); err != nil {
+						return fmt.Errorf("failed to update certificate secret: %+v", err)
+					}
+				} else {
+					return fmt.Errorf("failed to create certificate secret: %+v", err)
 				}
-			} else {
-				return fmt.Errorf("failed to create certificate secret: %+v", err)
 			}
 		}
 	}
-	javaKeystoreFlag := cmd.Flag("java-keystore-file-path")
-	if javaKeystoreFlag.Changed {
-		javaKeystoreData, err := util.ReadFileData(javaKeystoreFlag.Value.String())
-		if err != nil {
-			log.Fatalf("failed to read Java Keystore file: %+v", err)
-		}
+	certificate, err := configureCertificateIssuer(cmd.Flags(), util.AlertName, alertName, namespace, helmValuesMap)
+	if err != nil {
+		return err
+	}
+	if err := applyCertificateIssuer(certificate); err != nil {
+		return err
+	}
+	javaKeystoreData, ok, err := alert.GetJavaKeystoreDataFromFlags(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	if ok {
 		javaKeystoreSecretName := "alert-java-keystore"
 		javaKeystoreSecret := alert.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData)
 		util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
-		if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil {
-			if k8serrors.IsAlreadyExists(err) {
-				if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&javaKeystoreSecret); err != nil {
-					return fmt.Errorf("failed to update javakeystore secret: %+v", err)
+		if !skipForPlan(util.PlanActionApply, "Secret", javaKeystoreSecretName, namespace) {
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil {
+				if k8serrors.IsAlreadyExists(err) {
+					if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&javaKeystoreSecret); err != nil {
+						return fmt.Errorf("failed to update javakeystore secret: %+v", err)
+					}
+				} else {
+					return fmt.Errorf("failed to create javakeystore secret: %+v", err)
 				}
-			} else {
-				return fmt.Errorf("failed to create javakeystore secret: %+v", err)
 			}
 		}
 	}
 
 	// Expose Services for Alert
-	err = alert.CRUDServiceOrRoute(restconfig, kubeClient, namespace, alertName, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
-	if err != nil {
-		return fmt.Errorf("failed to update exposed service due to %+v", err)
+	if !skipForPlan(util.PlanActionApply, "Service", fmt.Sprintf("%s-exposed", alertName), namespace) {
+		err = alert.CRUDServiceOrRoute(restconfig, kubeClient, namespace, alertName, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
+		if err != nil {
+			return fmt.Errorf("failed to update exposed service due to %+v", err)
+		}
+	}
+	if err := applyExposureIngress(util.AlertName, alertName, namespace, helmValuesMap); err != nil {
+		return fmt.Errorf("failed to update the ingress due to %+v", err)
 	}
 
 	// Update Alert Resources
 	err = util.UpdateWithHelm3(helmReleaseName, namespace, globals.AlertChartRepository, helmValuesMap, kubeConfigPath)
 	if err != nil {
 		cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
-		return fmt.Errorf("failed to update Alert resources due to %+v", cleanErrorMsg)
+		return util.WithExitCode(util.ExitCode(err), fmt.Errorf("failed to update Alert resources due to %+v", cleanErrorMsg))
 	}
 	return nil
 }
@@ -293,7 +327,7 @@ var updateBlackDuckCmd = &cobra.Command{
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -307,6 +341,11 @@ var updateBlackDuckCmd = &cobra.Command{
 			isOperatorBased = true
 		}
 
+		postUpgradeCheckSQL, err := readPostUpgradeCheckSQL()
+		if err != nil {
+			return err
+		}
+
 		if !isOperatorBased && instance != nil {
 			// Update the Helm Chart Location
 			globals.BlackDuckVersion = util.GetValueFromRelease(instance, []string{"imageTag"}).(string)
@@ -330,16 +369,14 @@ var updateBlackDuckCmd = &cobra.Command{
 			oldVersion := util.GetValueFromRelease(instance, []string{"imageTag"}).(string)
 			log.Debugf("old version: %+v", oldVersion)
 
-			var sizeYAMLFileNameInChart string
+			size, _ := instance.Config["size"].(string)
 			if cmd.Flag("size").Changed {
-				sizeYAMLFileNameInChart = fmt.Sprintf("%s.yaml", cmd.Flag("size").Value.String())
-			} else {
-				if size, found := instance.Config["size"]; found && len(size.(string)) > 0 {
-					sizeYAMLFileNameInChart = fmt.Sprintf("%s.yaml", size.(string))
-				}
+				size = cmd.Flag("size").Value.String()
+			}
+			sizeYAMLFileNameInChart, sizeValues, err := getBlackDuckSizeValues(cmd.Flags(), blackDuckNamespace, size)
+			if err != nil {
+				return err
 			}
-
-			sizeYAMLFileNameInChart = strings.ToLower(sizeYAMLFileNameInChart)
 
 			if len(sizeYAMLFileNameInChart) > 0 {
 				sizeValuesFromChart, err := util.ConvertFilesFromChartToMap(blackDuckNamespace, kubeConfigPath, globals.BlackDuckChartRepository, sizeYAMLFileNameInChart)
@@ -348,8 +385,12 @@ var updateBlackDuckCmd = &cobra.Command{
 				}
 				instance.Config = util.MergeMaps(instance.Config, sizeValuesFromChart)
 			}
+			instance.Config = util.MergeMaps(instance.Config, sizeValues)
 
 			updateBlackDuckCobraHelper.SetArgs(instance.Config)
+			if err := setValuesFromFiles(&updateBlackDuckCobraHelper); err != nil {
+				return err
+			}
 			helmValuesMap, err := updateBlackDuckCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 			if err != nil {
 				return err
@@ -363,6 +404,9 @@ var updateBlackDuckCmd = &cobra.Command{
 			// Create or update the secret based on the certificate/password file path is set
 			isSecretUpdated := false
 			for _, v := range secrets {
+				if skipForPlan(util.PlanActionApply, "Secret", v.Name, blackDuckNamespace) {
+					continue
+				}
 				if secret, err := util.GetSecret(kubeClient, blackDuckNamespace, v.Name); err == nil {
 					secret.Data = v.Data
 					secret.StringData = v.StringData
@@ -377,6 +421,13 @@ var updateBlackDuckCmd = &cobra.Command{
 				}
 				isSecretUpdated = true
 			}
+			certificate, err := configureCertificateIssuer(cmd.Flags(), util.BlackDuckName, args[0], blackDuckNamespace, helmValuesMap)
+			if err != nil {
+				return err
+			}
+			if err := applyCertificateIssuer(certificate); err != nil {
+				return err
+			}
 
 			// Whenever the secrets are created/updated, delete the corresponding pods to input the created/updated secrets
 			if isSecretUpdated {
@@ -405,6 +456,10 @@ var updateBlackDuckCmd = &cobra.Command{
 				}
 			}
 
+			if err := verifyBlackDuckReportingDatabase(cmd.Flags(), blackDuckName, blackDuckNamespace, globals.BlackDuckChartRepository, helmValuesMap); err != nil {
+				return err
+			}
+
 			// Update Security Context Permissions
 			newVals := util.MergeMaps(instance.Chart.Values, helmValuesMap)
 			err = runBlackDuckFileOwnershipJobs(blackDuckName, blackDuckNamespace, oldVersion, newVals, cmd.Flags())
@@ -419,14 +474,26 @@ var updateBlackDuckCmd = &cobra.Command{
 			}
 
 			// Deploy resources
+			updateStarted := time.Now()
+			planServiceOrRoute(util.GetResourceName(args[0], util.BlackDuckName, "webserver-exposed"), util.GetResourceName(args[0], util.BlackDuckName, ""), blackDuckNamespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
 			if err := util.UpdateWithHelm3(blackDuckName, blackDuckNamespace, globals.BlackDuckChartRepository, helmValuesMap, kubeConfigPath); err != nil {
-				return fmt.Errorf("failed to update Black Duck due to %+v", err)
+				return fmt.Errorf("failed to update Black Duck due to %w", err)
 			}
 
 			err = blackduck.CRUDServiceOrRoute(restconfig, kubeClient, blackDuckNamespace, args[0], helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"], cmd.Flags().Lookup("expose-ui").Changed)
 			if err != nil {
 				return err
 			}
+			if err := applyExposureIngress(util.BlackDuckName, args[0], blackDuckNamespace, helmValuesMap); err != nil {
+				return err
+			}
+
+			// Major upgrades run long database migrations, show their progress rather than appearing hung
+			if oldVersion != globals.BlackDuckVersion && updateMigrationTimeout > 0 {
+				if err := followBlackDuckMigrations(blackDuckName, blackDuckNamespace, updateStarted, updateMigrationTimeout); err != nil {
+					return err
+				}
+			}
 
 		} else if isOperatorBased {
 			if !cmd.Flag("version").Changed {
@@ -469,6 +536,16 @@ var updateBlackDuckCmd = &cobra.Command{
 			}
 		}
 
+		if err := waitForInstanceReady(util.BlackDuckName, blackDuckName, blackDuckNamespace); err != nil {
+			return err
+		}
+
+		if !isOperatorBased {
+			if err := runBlackDuckPostUpgradeCheck(blackDuckName, blackDuckNamespace, postUpgradeCheckSQL); err != nil {
+				return err
+			}
+		}
+
 		log.Infof("Black Duck has been successfully Updated in namespace '%s'!", blackDuckNamespace)
 		return nil
 	},
@@ -505,6 +582,9 @@ func runBlackDuckFileOwnershipJobs(blackDuckName, blackDuckNamespace, oldVersion
 	bdSecurityContextsWereChanged := flags.Lookup("security-context-file-path").Changed && newVersionIsGreaterThanOrEqualv2019x12x0
 
 	if (bdUpdatedToVersionWithSecurityContexts || bdSecurityContextsWereChanged) && !bdUpdatedToVersionWithSecurityContextsAndNoPersistentStorage && !util.IsOpenshift(kubeClient) {
+		if skipForPlan(util.PlanActionCreate, "Job", fmt.Sprintf("%s-set-file-ownership", blackDuckName), blackDuckNamespace) {
+			return nil
+		}
 		// Stop the BlackDuck instance
 		if strings.ToUpper(currState) != "STOPPED" {
 			log.Infof("stopping Black Duck to apply Security Context changes")
@@ -516,7 +596,7 @@ func runBlackDuckFileOwnershipJobs(blackDuckName, blackDuckNamespace, oldVersion
 			util.SetHelmValueInMap(tmpValuesMap, []string{"status"}, "Stopped")
 			err = util.UpdateWithHelm3(blackDuckName, namespace, globals.BlackDuckChartRepository, tmpValuesMap, kubeConfigPath)
 			if err != nil {
-				return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+				return fmt.Errorf("failed to create Blackduck resources: %w", err)
 			}
 			// Wait for Black Duck to Stop
 			log.Infof("waiting for Black Duck to stop...")
@@ -697,7 +777,7 @@ var updateBlackDuckMasterKeyCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 3 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 3 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 3, "Args": args})
 		}
 
 		if len(args[2]) != 32 {
@@ -727,7 +807,7 @@ var updateBlackDuckMasterKeyNativeCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 3 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 3 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 3, "Args": args})
 		}
 
 		if len(args[2]) != 32 {
@@ -833,7 +913,7 @@ var updateBlackDuckAddEnvironCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 2 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 2 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 2, "Args": args})
 		}
 		return nil
 	},
@@ -886,7 +966,7 @@ var updateOpsSightCmd = &cobra.Command{
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
 		}
 		return nil
 	},
@@ -911,6 +991,9 @@ var updateOpsSightCmd = &cobra.Command{
 		}
 
 		// Update Helm Values with flags
+		if err := setValuesFromFiles(&updateOpsSightCobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := updateOpsSightCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -921,7 +1004,11 @@ var updateOpsSightCmd = &cobra.Command{
 		// Update OpsSight Resources
 		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to update OpsSight resources due to %+v", err)
+			return fmt.Errorf("failed to update OpsSight resources due to %w", err)
+		}
+
+		if err := waitForInstanceReady(util.OpsSightName, opssightName, namespace); err != nil {
+			return err
 		}
 
 		log.Infof("OpsSight has been successfully updated in namespace '%s'!", namespace)
@@ -1007,7 +1094,7 @@ var updateOpsSightExternalHostCmd = &cobra.Command{
 		// Update OpsSight Resources
 		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to update OpsSight resources due to %+v", err)
+			return fmt.Errorf("failed to update OpsSight resources due to %w", err)
 		}
 
 		log.Infof("OpsSight has been successfully updated in namespace '%s'!", namespace)
@@ -1092,7 +1179,7 @@ var updateOpsSightExternalHostNativeCmd = &cobra.Command{
 		// Update OpsSight Resources
 		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to update OpsSight resources due to %+v", err)
+			return fmt.Errorf("failed to update OpsSight resources due to %w", err)
 		}
 
 		log.Infof("OpsSight has been successfully updated in namespace '%s'!", namespace)
@@ -1153,7 +1240,7 @@ var updateOpsSightAddRegistryCmd = &cobra.Command{
 		// Update OpsSight Resources
 		err = util.UpdateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to update OpsSight resources due to %+v", err)
+			return fmt.Errorf("failed to update OpsSight resources due to %w", err)
 		}
 
 		log.Infof("OpsSight has been successfully updated in namespace '%s'!", namespace)
@@ -1214,7 +1301,7 @@ var updateOpsSightAddRegistryNativeCmd = &cobra.Command{
 		// Update OpsSight Resources
 		err = util.TemplateWithHelm3(opssightName, namespace, globals.OpsSightChartRepository, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to update OpsSight resources due to %+v", err)
+			return fmt.Errorf("failed to update OpsSight resources due to %w", err)
 		}
 
 		log.Infof("OpsSight has been successfully updated in namespace '%s'!", namespace)
@@ -1233,7 +1320,7 @@ var updateBDBACmd = &cobra.Command{
 		// Check the Number of Arguments
 		if len(args) != 0 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
 		}
 		return nil
 	},
@@ -1245,6 +1332,9 @@ var updateBDBACmd = &cobra.Command{
 		updateBDBACobraHelper.SetArgs(helmRelease.Config)
 
 		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&updateBDBACobraHelper); err != nil {
+			return err
+		}
 		helmValuesMap, err := updateBDBACobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 		if err != nil {
 			return err
@@ -1269,9 +1359,22 @@ var updateBDBACmd = &cobra.Command{
 		}
 
 		// Update Resources
+		if cmd.Flags().Lookup("expose-ui").Changed || cmd.Flags().Lookup("expose-ui-hostname").Changed {
+			planServiceOrRoute(bdba.GetExposedResourceName(globals.BDBAName), bdba.GetExposedResourceName(globals.BDBAName), namespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
+		}
 		err = util.UpdateWithHelm3(globals.BDBAName, namespace, globals.BDBAChartRepository, helmValuesMap, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to update BDBA resources due to %+v", err)
+			return fmt.Errorf("failed to update BDBA resources due to %w", err)
+		}
+
+		if cmd.Flags().Lookup("expose-ui").Changed || cmd.Flags().Lookup("expose-ui-hostname").Changed {
+			if err := bdba.CRUDServiceOrRoute(restconfig, kubeClient, namespace, globals.BDBAName, helmValuesMap); err != nil {
+				return err
+			}
+		}
+
+		if err := waitForInstanceReady(globals.BDBAName, globals.BDBAName, namespace); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Updated in namespace '%s'!", namespace)
@@ -1279,6 +1382,142 @@ var updateBDBACmd = &cobra.Command{
 	},
 }
 
+// updateCoverityCmd updates a Coverity instance
+var updateCoverityCmd = &cobra.Command{
+	Use:           "coverity NAME -n NAMESPACE",
+	Example:       "synopsysctl update coverity <name> -n <namespace> --cim-replicas 2",
+	Short:         "Update a Coverity instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coverityName := args[0]
+		helmRelease, err := util.GetWithHelm3(coverityName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to get previous user defined values: %+v", err)
+		}
+		updateCoverityCobraHelper.SetArgs(helmRelease.Config)
+
+		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&updateCoverityCobraHelper); err != nil {
+			return err
+		}
+		helmValuesMap, err := updateCoverityCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// Set the Version from the Release
+		if cmd.Flags().Lookup("version").Changed {
+			globals.CoverityVersion = cmd.Flags().Lookup("version").Value.String()
+			util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.CoverityVersion)
+		} else {
+			if versionFromRelease := util.GetValueFromRelease(helmRelease, []string{"version"}); versionFromRelease != nil {
+				globals.CoverityVersion = versionFromRelease.(string)
+			} else {
+				return fmt.Errorf("please set --version for this update")
+			}
+		}
+
+		// Update the Helm Chart Location
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.CoverityChartName, globals.CoverityVersion, &globals.CoverityChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		// Update Resources
+		err = util.UpdateWithHelm3(coverityName, namespace, globals.CoverityChartRepository, helmValuesMap, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to update Coverity resources due to %w", err)
+		}
+
+		log.Infof("Coverity has been successfully Updated in namespace '%s'!", namespace)
+		return nil
+	},
+}
+
+// updatePolarisCmd updates a Polaris instance
+var updatePolarisCmd = &cobra.Command{
+	Use:           "polaris NAME -n NAMESPACE",
+	Example:       "synopsysctl update polaris <name> -n <namespace> --enable-reporting\nsynopsysctl update polaris <name> -n <namespace> --version <version>",
+	Short:         "Update a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 1, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		polarisName := args[0]
+		helmRelease, err := util.GetWithHelm3(polarisName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to get previous user defined values: %+v", err)
+		}
+		updatePolarisCobraHelper.SetArgs(helmRelease.Config)
+
+		// Get the flags to set Helm values
+		if err := setValuesFromFiles(&updatePolarisCobraHelper); err != nil {
+			return err
+		}
+		helmValuesMap, err := updatePolarisCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// Set the Version from the Release
+		if cmd.Flags().Lookup("version").Changed {
+			globals.PolarisVersion = cmd.Flags().Lookup("version").Value.String()
+			util.SetHelmValueInMap(helmValuesMap, []string{"version"}, globals.PolarisVersion)
+		} else {
+			if versionFromRelease := util.GetValueFromRelease(helmRelease, []string{"version"}); versionFromRelease != nil {
+				globals.PolarisVersion = versionFromRelease.(string)
+			} else {
+				return fmt.Errorf("please set --version for this update")
+			}
+		}
+		if util.CompareVersions(globals.PolarisVersion, polaris.MinVersion) < 0 {
+			return fmt.Errorf("update of Polaris instance is only supported for version %s and above", polaris.MinVersion)
+		}
+		if err := updatePolarisCobraHelper.VerifyChartVersionSupportsChangedFlags(cmd.Flags(), globals.PolarisVersion); err != nil {
+			return err
+		}
+
+		// Update the Helm Chart Location
+		err = UpdateHelmChartLocation(cmd.Flags(), globals.PolarisChartName, globals.PolarisVersion, &globals.PolarisChartRepository)
+		if err != nil {
+			return fmt.Errorf("failed to set the app resources location due to %+v", err)
+		}
+
+		// Update Resources
+		exposureChanged := cmd.Flags().Lookup("expose-ui").Changed || cmd.Flags().Lookup("fqdn").Changed
+		if exposureChanged {
+			planServiceOrRoute(polaris.GetExposedResourceName(polarisName), polaris.GetExposedResourceName(polarisName), namespace, helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
+		}
+		err = util.UpdateWithHelm3(polarisName, namespace, globals.PolarisChartRepository, helmValuesMap, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to update Polaris resources due to %w", err)
+		}
+
+		if exposureChanged {
+			if err := polaris.CRUDServiceOrRoute(restconfig, kubeClient, namespace, polarisName, helmValuesMap); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("Polaris has been successfully Updated in namespace '%s'!", namespace)
+		return nil
+	},
+}
+
 func init() {
 	// initialize global resource ctl structs for commands to use
 
@@ -1286,13 +1525,19 @@ func init() {
 	updateOpsSightCobraHelper = *opssight.NewHelmValuesFromCobraFlags()
 	updateBlackDuckCobraHelper = *blackduck.NewHelmValuesFromCobraFlags()
 	updateBDBACobraHelper = *bdba.NewHelmValuesFromCobraFlags()
+	updateCoverityCobraHelper = *coverity.NewHelmValuesFromCobraFlags()
+	updatePolarisCobraHelper = *polaris.NewHelmValuesFromCobraFlags()
 
 	rootCmd.AddCommand(updateCmd)
+	addPlanFlags(updateCmd)
+	addDryRunFlag(updateCmd)
 
 	// updateAlertCmd
 	updateAlertCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(updateAlertCmd.PersistentFlags(), "namespace")
 	updateAlertCobraHelper.AddCobraFlagsToCommand(updateAlertCmd, false)
+	addValuesFileFlag(updateAlertCmd)
+	addWaitFlags(updateAlertCmd)
 	addChartLocationPathFlag(updateAlertCmd)
 	updateCmd.AddCommand(updateAlertCmd)
 
@@ -1302,8 +1547,16 @@ func init() {
 	updateBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(updateBlackDuckCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(updateBlackDuckCmd)
+	updateBlackDuckCmd.Flags().DurationVar(&updateMigrationTimeout, "migration-timeout", updateMigrationTimeout, "Maximum time to follow the database migrations of a version upgrade, 0 to skip following them")
+	updateBlackDuckCmd.Flags().IntVar(&migrateDBPasswordOptions.Retries, "db-password-retries", migrateDBPasswordOptions.Retries, "Number of times to fetch the secret with the database passwords again while it doesn't exist when migrating an instance of Synopsys Operator, set --admin-password and --user-password to skip the secret")
+	updateBlackDuckCmd.Flags().DurationVar(&migrateDBPasswordOptions.Interval, "db-password-retry-interval", migrateDBPasswordOptions.Interval, "Time to wait between the fetches of the secret with the database passwords")
 	updateBlackDuckCmd.Flags().StringVar(&globals.DefaultBusyBoxImage, "busy-box-image", globals.DefaultBusyBoxImage, "Busy box image override for an air gapped customer (only use in case of updating security contexts)")
 	updateBlackDuckCobraHelper.AddCobraFlagsToCommand(updateBlackDuckCmd, false)
+	addValuesFileFlag(updateBlackDuckCmd)
+	addWaitFlags(updateBlackDuckCmd)
+	addPostUpgradeCheckFlags(updateBlackDuckCmd)
+	updateBlackDuckCmd.Flags().BoolVar(&skipReportingDatabaseValidation, "skip-reporting-postgres-validation", skipReportingDatabaseValidation, "If true, do not check that the reporting Postgres is a reachable read-only replica")
+	setVersionAwareHelp(updateBlackDuckCmd, blackduck.GetFlagsUnsupportedByVersion)
 	updateCmd.AddCommand(updateBlackDuckCmd)
 
 	// updateBlackDuckMasterKeyCmd
@@ -1325,6 +1578,8 @@ func init() {
 	cobra.MarkFlagRequired(updateOpsSightCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(updateOpsSightCmd)
 	updateOpsSightCobraHelper.AddCobraFlagsToCommand(updateOpsSightCmd, false)
+	addValuesFileFlag(updateOpsSightCmd)
+	addWaitFlags(updateOpsSightCmd)
 	updateCmd.AddCommand(updateOpsSightCmd)
 
 	// updateOpsSightExternalHostCmd
@@ -1341,6 +1596,25 @@ func init() {
 	updateBDBACmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(updateBDBACmd.PersistentFlags(), "namespace")
 	updateBDBACobraHelper.AddCobraFlagsToCommand(updateBDBACmd, false)
+	addValuesFileFlag(updateBDBACmd)
+	addWaitFlags(updateBDBACmd)
 	addChartLocationPathFlag(updateBDBACmd)
 	updateCmd.AddCommand(updateBDBACmd)
+
+	// Coverity
+	updateCoverityCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(updateCoverityCmd.PersistentFlags(), "namespace")
+	updateCoverityCobraHelper.AddCobraFlagsToCommand(updateCoverityCmd, false)
+	addValuesFileFlag(updateCoverityCmd)
+	addChartLocationPathFlag(updateCoverityCmd)
+	updateCmd.AddCommand(updateCoverityCmd)
+
+	// Polaris
+	updatePolarisCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(updatePolarisCmd.PersistentFlags(), "namespace")
+	updatePolarisCobraHelper.AddCobraFlagsToCommand(updatePolarisCmd, false)
+	addValuesFileFlag(updatePolarisCmd)
+	addChartLocationPathFlag(updatePolarisCmd)
+	setVersionAwareHelp(updatePolarisCmd, polaris.GetFlagsUnsupportedByVersion)
+	updateCmd.AddCommand(updatePolarisCmd)
 }
@@ -0,0 +1,207 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Upgrade Command Options and Defaults
+var upgradeAuto = false
+var upgradeProfilePath = ""
+var upgradeDryRun = false
+
+// fleetInstance is an instance of a product deployed with Helm
+type fleetInstance struct {
+	App       string
+	Name      string
+	Namespace string
+	Version   string
+}
+
+// upgradeCmd upgrades instances according to the upgrade policies of a profile
+var upgradeCmd = &cobra.Command{
+	Use:           "upgrade --auto",
+	Example:       "synopsysctl upgrade --auto --profile <profile file path>\nsynopsysctl upgrade --auto --dry-run\nsynopsysctl upgrade --auto -n <namespace>",
+	Short:         "Upgrade Alert and Black Duck instances to the versions approved by the upgrade policies of a profile",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return messages.Error(messages.ArgumentCount, messages.Args{"Count": 0, "Args": args})
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !upgradeAuto {
+			cmd.Help()
+			return fmt.Errorf("must specify --auto to upgrade instances according to the upgrade policies")
+		}
+		profile, err := loadProfile(upgradeProfilePath)
+		if err != nil {
+			return err
+		}
+		if len(profile.UpgradePolicy) == 0 {
+			return fmt.Errorf("no upgrade policies declared, add 'upgradePolicy' to the profile or the synopsysctl config file")
+		}
+
+		searchNamespace := ""
+		if cmd.Flags().Lookup("namespace").Changed {
+			searchNamespace = namespace
+		}
+
+		type plannedUpgrade struct {
+			instance fleetInstance
+			version  string
+		}
+		upgrades := []plannedUpgrade{}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "PRODUCT\tNAME\tNAMESPACE\tPOLICY\tCURRENT\tTARGET")
+		for _, product := range []struct{ app, chartName string }{
+			{app: util.AlertName, chartName: globals.AlertChartName},
+			{app: util.BlackDuckName, chartName: globals.BlackDuckChartName},
+		} {
+			policy, ok, err := profile.getUpgradePolicy(product.app)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				log.Debugf("no upgrade policy declared for %s", product.app)
+				continue
+			}
+			instances, err := listFleetInstances(product.app, searchNamespace)
+			if err != nil {
+				return err
+			}
+			availableVersions := util.GetAppVersionsFromChartURLs(globals.IndexChartURLs, product.chartName)
+			for _, instance := range instances {
+				target, upgrade := util.SelectUpgradeVersion(policy, instance.Version, availableVersions)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", instance.App, instance.Name, instance.Namespace, policy, instance.Version, target)
+				if upgrade {
+					upgrades = append(upgrades, plannedUpgrade{instance: instance, version: target})
+				}
+			}
+		}
+		w.Flush()
+
+		if len(upgrades) == 0 {
+			log.Infof("all instances are at the version approved by their upgrade policy")
+			return nil
+		}
+		if upgradeDryRun {
+			log.Infof("%d instance(s) would be upgraded, run without --dry-run to upgrade them", len(upgrades))
+			return nil
+		}
+
+		failed := 0
+		for _, upgrade := range upgrades {
+			logger := util.WithStep(util.NewLogger(upgrade.instance.App, upgrade.instance.Name, upgrade.instance.Namespace), "upgrade")
+			logger.Infof("upgrading %s '%s' in namespace '%s' from %s to %s", upgrade.instance.App, upgrade.instance.Name, upgrade.instance.Namespace, upgrade.instance.Version, upgrade.version)
+			if err := upgradeFleetInstance(upgrade.instance, upgrade.version); err != nil {
+				logger.Errorf("failed to upgrade %s '%s' in namespace '%s': %+v", upgrade.instance.App, upgrade.instance.Name, upgrade.instance.Namespace, err)
+				failed++
+				continue
+			}
+			logger.Infof("successfully upgraded %s '%s' in namespace '%s' to %s", upgrade.instance.App, upgrade.instance.Name, upgrade.instance.Namespace, upgrade.version)
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to upgrade %d of %d instance(s)", failed, len(upgrades))
+		}
+		return nil
+	},
+}
+
+// listFleetInstances finds the Helm based instances of the product based on the labels of their deployments
+func listFleetInstances(app string, searchNamespace string) ([]fleetInstance, error) {
+	deployments, err := util.ListDeployments(kubeClient, searchNamespace, fmt.Sprintf("app=%s", app))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s instances due to %+v", app, err)
+	}
+	instances := []fleetInstance{}
+	found := map[string]bool{}
+	for _, deployment := range deployments.Items {
+		name := deployment.GetLabels()["name"]
+		key := fmt.Sprintf("%s/%s", deployment.Namespace, name)
+		if len(name) == 0 || found[key] {
+			continue
+		}
+		found[key] = true
+
+		releaseName := name
+		versionKey := []string{"imageTag"}
+		if app == util.AlertName {
+			releaseName = fmt.Sprintf("%s%s", name, globals.AlertPostSuffix)
+			versionKey = []string{"alert", "imageTag"}
+		}
+		release, err := util.GetWithHelm3(releaseName, deployment.Namespace, kubeConfigPath)
+		if err != nil {
+			log.Warnf("skipping %s '%s' in namespace '%s' because it is not managed by Helm: %+v", app, name, deployment.Namespace, err)
+			continue
+		}
+		version, ok := util.GetValueFromRelease(release, versionKey).(string)
+		if !ok {
+			log.Warnf("skipping %s '%s' in namespace '%s' because its version is unknown", app, name, deployment.Namespace)
+			continue
+		}
+		instances = append(instances, fleetInstance{App: app, Name: name, Namespace: deployment.Namespace, Version: version})
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Namespace != instances[j].Namespace {
+			return instances[i].Namespace < instances[j].Namespace
+		}
+		return instances[i].Name < instances[j].Name
+	})
+	return instances, nil
+}
+
+// upgradeFleetInstance upgrades the instance with the update command of its product
+func upgradeFleetInstance(instance fleetInstance, version string) error {
+	productUpdateCmd := updateBlackDuckCmd
+	if instance.App == util.AlertName {
+		productUpdateCmd = updateAlertCmd
+	}
+	if err := productUpdateCmd.Flags().Set("version", version); err != nil {
+		return err
+	}
+	namespace = instance.Namespace
+	return productUpdateCmd.RunE(productUpdateCmd, []string{instance.Name})
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	addPlanFlags(upgradeCmd)
+
+	upgradeCmd.Flags().BoolVar(&upgradeAuto, "auto", upgradeAuto, "If true, upgrade every instance according to the upgrade policy declared for its product")
+	upgradeCmd.Flags().StringVar(&upgradeProfilePath, "profile", upgradeProfilePath, "Absolute path to a profile file declaring the upgrade policies (default upgradePolicy of the synopsysctl config file)")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", upgradeDryRun, "If true, only list the upgrades that would be performed")
+	upgradeCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instances to upgrade (default all namespaces)")
+}
@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// Verify Command Options and Defaults
+var verifyOutputFormat = "table"
+
+// verifyResult is the result of the verification of an instance, suitable as compliance evidence
+type verifyResult struct {
+	Product         string         `json:"product"`
+	Name            string         `json:"name"`
+	Namespace       string         `json:"namespace"`
+	ReleaseRevision int            `json:"releaseRevision"`
+	ChartVersion    string         `json:"chartVersion"`
+	ValuesChecksum  string         `json:"valuesChecksum"`
+	VerifiedAt      time.Time      `json:"verifiedAt"`
+	Drifted         bool           `json:"drifted"`
+	Objects         []verifyObject `json:"objects"`
+}
+
+// verifyObject is the result of the verification of a single object
+type verifyObject struct {
+	Kind             string `json:"kind"`
+	Name             string `json:"name"`
+	Status           string `json:"status"`
+	ExpectedChecksum string `json:"expectedChecksum"`
+	LiveChecksum     string `json:"liveChecksum,omitempty"`
+}
+
+// Verification statuses of an object
+const (
+	verifyStatusMatch   = "match"
+	verifyStatusDrifted = "drifted"
+	verifyStatusMissing = "missing"
+)
+
+// verifyCmd verifies that the live objects of an instance match the chart output for its stored values
+var verifyCmd = &cobra.Command{
+	Use:           "verify PRODUCT NAME -n NAMESPACE",
+	Example:       "synopsysctl verify blackduck <name> -n <namespace>\nsynopsysctl verify alert <name> -n <namespace> --output json > evidence.json",
+	Short:         "Detect tampering or manual drift of the objects of an Alert or Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyOutputFormat != "table" && verifyOutputFormat != "json" {
+			return fmt.Errorf("output must be 'table' or 'json', but got '%s'", verifyOutputFormat)
+		}
+		result, err := verifyInstance(args[0], args[1], namespace)
+		if err != nil {
+			return err
+		}
+
+		if verifyOutputFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "KIND\tNAME\tSTATUS\tEXPECTED CHECKSUM\tLIVE CHECKSUM")
+			for _, object := range result.Objects {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.12s\t%.12s\n", object.Kind, object.Name, object.Status, object.ExpectedChecksum, object.LiveChecksum)
+			}
+			w.Flush()
+		}
+
+		if result.Drifted {
+			drifted := 0
+			for _, object := range result.Objects {
+				if object.Status != verifyStatusMatch {
+					drifted++
+				}
+			}
+			return fmt.Errorf("%d object(s) of %s '%s' in namespace '%s' do not match the chart output", drifted, args[0], args[1], namespace)
+		}
+		return nil
+	},
+}
+
+// verifyInstance re-renders the chart of the instance with its stored values and compares the checksums of the rendered
+// objects with the checksums of the live objects, ignoring the fields that are not set by the chart
+func verifyInstance(app string, name string, namespace string) (*verifyResult, error) {
+	releaseName := name
+	if app == util.AlertName {
+		releaseName = fmt.Sprintf("%s%s", name, globals.AlertPostSuffix)
+	}
+	release, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s '%s' in namespace '%s': %+v", app, name, namespace, err)
+	}
+	actionConfig, err := util.CreateHelmActionConfiguration(kubeConfigPath, "", namespace)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := util.RenderManifests(releaseName, namespace, release.Chart, release.Config, actionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the chart of %s '%s' due to %+v", app, name, err)
+	}
+	objects, err := util.SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesChecksum, err := util.HashObject(release.Config)
+	if err != nil {
+		return nil, err
+	}
+	result := &verifyResult{
+		Product:         app,
+		Name:            name,
+		Namespace:       namespace,
+		ReleaseRevision: release.Version,
+		ChartVersion:    release.Chart.Metadata.Version,
+		ValuesChecksum:  valuesChecksum,
+		VerifiedAt:      time.Now(),
+		Objects:         []verifyObject{},
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the discovery client due to %+v", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	dynamicClient, err := dynamic.NewForConfig(restconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+
+	for _, object := range objects {
+		// the provenance of --stamp-metadata differs for every invocation
+		util.RemoveProvenanceAnnotations(object)
+		expected := &unstructured.Unstructured{Object: object}
+		if _, isHook := expected.GetAnnotations()["helm.sh/hook"]; isHook {
+			// hooks are usually deleted once they have run
+			continue
+		}
+		expectedChecksum, err := util.HashObject(object)
+		if err != nil {
+			return nil, err
+		}
+		verified := verifyObject{Kind: expected.GetKind(), Name: expected.GetName(), ExpectedChecksum: expectedChecksum}
+
+		gvk := schema.FromAPIVersionAndKind(expected.GetAPIVersion(), expected.GetKind())
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the resource of %s '%s' due to %+v", expected.GetKind(), expected.GetName(), err)
+		}
+		var resourceClient dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objectNamespace := expected.GetNamespace()
+			if len(objectNamespace) == 0 {
+				objectNamespace = namespace
+			}
+			resourceClient = dynamicClient.Resource(mapping.Resource).Namespace(objectNamespace)
+		}
+
+		live, err := resourceClient.Get(expected.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, fmt.Errorf("unable to get %s '%s' due to %+v", expected.GetKind(), expected.GetName(), err)
+			}
+			verified.Status = verifyStatusMissing
+			result.Drifted = true
+			result.Objects = append(result.Objects, verified)
+			continue
+		}
+
+		normalizedExpected, projected, err := util.ProjectLiveObject(object, live.Object)
+		if err != nil {
+			return nil, err
+		}
+		if verified.ExpectedChecksum, err = util.HashObject(normalizedExpected); err != nil {
+			return nil, err
+		}
+		if verified.LiveChecksum, err = util.HashObject(projected); err != nil {
+			return nil, err
+		}
+		verified.Status = verifyStatusMatch
+		if verified.ExpectedChecksum != verified.LiveChecksum {
+			verified.Status = verifyStatusDrifted
+			result.Drifted = true
+		}
+		result.Objects = append(result.Objects, verified)
+	}
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(verifyCmd.Flags(), "namespace")
+	verifyCmd.Flags().StringVarP(&verifyOutputFormat, "output", "o", verifyOutputFormat, "Output format [table|json]")
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// Verify Install Command Options and Defaults
+var verifyInstallOutputFormat = "table"
+
+// verifyInstallCmd runs the post-install verification checks of an instance
+var verifyInstallCmd = &cobra.Command{
+	Use:           "verify-install PRODUCT NAME -n NAMESPACE",
+	Example:       "synopsysctl verify-install blackduck <name> -n <namespace>\nsynopsysctl verify-install alert <name> -n <namespace> --output json",
+	Short:         "Verify that an Alert or Black Duck instance works after it was installed, e.g. that its login page is reachable",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          validateProductArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(verifyInstallOutputFormat)
+		if format != "table" && format != "json" && format != "yaml" {
+			return util.ValidationError("output format must be 'table', 'json' or 'yaml', got '%s'", verifyInstallOutputFormat)
+		}
+		report, err := verifyInstall(args[0], args[1], namespace)
+		if err != nil {
+			return err
+		}
+		if format == "table" {
+			printVerificationReport(report)
+		} else if _, err := PrintComponent(report, format); err != nil {
+			return err
+		}
+		return verificationReportError(report)
+	},
+}
+
+// verificationChecks returns the post-install checks that the product contributes
+func verificationChecks(app string, name string) []products.VerificationCheck {
+	switch app {
+	case util.BlackDuckName:
+		return blackduck.VerificationChecks(name)
+	case util.AlertName:
+		return alertctl.VerificationChecks(name)
+	}
+	return []products.VerificationCheck{}
+}
+
+// verifyInstall runs the post-install checks of the product against the instance
+func verifyInstall(app string, name string, namespace string) (*products.VerificationReport, error) {
+	clients := getProductClients()
+	product, err := products.New(app, name, namespace, clients)
+	if err != nil {
+		return nil, err
+	}
+	version, err := product.Version()
+	if err != nil {
+		return nil, err
+	}
+	target := &products.VerificationTarget{Product: product, Clients: clients, Version: version}
+	return products.RunVerificationChecks(target, verificationChecks(app, name)), nil
+}
+
+// printVerificationReport prints the results of the checks as a table
+func printVerificationReport(report *products.VerificationReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tMESSAGE")
+	for _, result := range report.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, strings.ToUpper(result.Status), result.Message)
+	}
+	w.Flush()
+}
+
+// verificationReportError returns an error with the failed checks of the report, or nil if it passed
+func verificationReportError(report *products.VerificationReport) error {
+	if report.Passed {
+		return nil
+	}
+	failed := []string{}
+	for _, result := range report.Failed() {
+		failed = append(failed, fmt.Sprintf("%s: %s", result.Check, result.Message))
+	}
+	return fmt.Errorf("%d of %d verification check(s) failed for %s '%s' in namespace '%s':\n  %s", len(failed), len(report.Results), report.Product, report.Name, report.Namespace, strings.Join(failed, "\n  "))
+}
+
+func init() {
+	rootCmd.AddCommand(verifyInstallCmd)
+
+	verifyInstallCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(verifyInstallCmd.Flags(), "namespace")
+	verifyInstallCmd.Flags().StringVarP(&verifyInstallOutputFormat, "output", "o", verifyInstallOutputFormat, "Output format [table|json|yaml]")
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// assumeYesEnv is the environ that skips the confirmation of destructive commands, e.g. in pipelines
+const assumeYesEnv = "SYNOPSYSCTL_ASSUME_YES"
+
+// assumeYes is set by the --yes flag of the destructive commands
+var assumeYes = false
+
+// addConfirmationFlag adds the --yes flag to a destructive command and its sub-commands
+func addConfirmationFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", assumeYes, fmt.Sprintf("If true, don't ask for confirmation (can also be set with the %s environ)", assumeYesEnv))
+}
+
+// isConfirmationAssumed returns true if the --yes flag or the SYNOPSYSCTL_ASSUME_YES environ is set
+func isConfirmationAssumed() bool {
+	if assumeYes {
+		return true
+	}
+	value, exists := os.LookupEnv(assumeYesEnv)
+	if !exists {
+		return false
+	}
+	yes, err := strconv.ParseBool(value)
+	return err == nil && yes
+}
+
+// confirmDestructiveAction asks the user to confirm a destructive action if synopsysctl is attached to a terminal and
+// doesn't only plan its changes. Only the commands that guard every change with their plan have the --plan flag, so a
+// plan can't make changes without confirmation. It returns an error if the user doesn't confirm the action
+func confirmDestructiveAction(description string) error {
+	if isConfirmationAssumed() || !util.IsTerminal(os.Stdin) || util.ActivePlan != nil {
+		return nil
+	}
+	confirmed, err := confirmAction(description)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return messages.Error(messages.ActionNotConfirmed, nil)
+	}
+	return nil
+}
+
+// confirmAction describes the action and asks the user to confirm it on the terminal
+func confirmAction(description string) (bool, error) {
+	return readConfirmation(os.Stdin, os.Stderr, description)
+}
+
+// readConfirmation writes the prompt of the action and reads the answer of the user, only 'y' and 'yes' confirm it
+func readConfirmation(in io.Reader, out io.Writer, description string) (bool, error) {
+	fmt.Fprint(out, messages.Get(messages.ConfirmPrompt, messages.Args{"Description": description}))
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && len(answer) == 0 {
+		return false, messages.Error(messages.ConfirmationReadFailed, messages.Args{"Error": fmt.Sprintf("%+v", err)})
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
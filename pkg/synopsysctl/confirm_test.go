@@ -0,0 +1,106 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsConfirmationAssumed(t *testing.T) {
+	assert := assert.New(t)
+	defer func(value bool) { assumeYes = value }(assumeYes)
+	if value, exists := os.LookupEnv(assumeYesEnv); exists {
+		defer os.Setenv(assumeYesEnv, value)
+	} else {
+		defer os.Unsetenv(assumeYesEnv)
+	}
+
+	var tests = []struct {
+		flag     bool
+		env      string
+		expected bool
+	}{
+		// an empty env is unset
+		{flag: false, env: "", expected: false},
+		{flag: true, env: "", expected: true},
+		{flag: false, env: "true", expected: true},
+		{flag: false, env: "1", expected: true},
+		{flag: false, env: "false", expected: false},
+		{flag: false, env: "maybe", expected: false},
+		{flag: true, env: "false", expected: true},
+	}
+
+	for _, test := range tests {
+		assumeYes = test.flag
+		if len(test.env) > 0 {
+			os.Setenv(assumeYesEnv, test.env)
+		} else {
+			os.Unsetenv(assumeYesEnv)
+		}
+		assert.Equal(test.expected, isConfirmationAssumed(), "flag %t, env '%s'", test.flag, test.env)
+	}
+}
+
+func TestReadConfirmation(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		answer    string
+		confirmed bool
+		hasError  bool
+	}{
+		{answer: "y\n", confirmed: true},
+		{answer: "YES\n", confirmed: true},
+		{answer: "  yes  \n", confirmed: true},
+		// the last line of a pipe may have no newline
+		{answer: "y", confirmed: true},
+		{answer: "\n", confirmed: false},
+		{answer: "no\n", confirmed: false},
+		{answer: "yep\n", confirmed: false},
+		{answer: "", hasError: true},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		confirmed, err := readConfirmation(strings.NewReader(test.answer), out, "this will delete Black Duck 'bd' in namespace 'ns'")
+		if test.hasError {
+			assert.Error(err, "answer %q", test.answer)
+		} else {
+			assert.NoError(err, "answer %q", test.answer)
+		}
+		assert.Equal(test.confirmed, confirmed, "answer %q", test.answer)
+		assert.Contains(out.String(), "this will delete Black Duck 'bd' in namespace 'ns'")
+	}
+}
+
+func TestConfirmDestructiveActionAssumed(t *testing.T) {
+	assert := assert.New(t)
+	defer func(value bool) { assumeYes = value }(assumeYes)
+
+	assumeYes = true
+	assert.NoError(confirmDestructiveAction("this will delete Black Duck 'bd' in namespace 'ns'"))
+}
@@ -37,3 +37,9 @@ type CRSpecBuilderFromCobraFlagsInterface interface {
 	GenerateCRSpecFromFlags(*pflag.FlagSet) (interface{}, error) // calls SetSpecFieldByFlag on each flag in flagset
 	SetCRSpecFieldByFlag(*pflag.Flag)                            // updates the resource's spec with the value from a flag
 }
+
+// HelmValuesArgsInterface requires the Helm values of a product that the changed Cobra flags are applied on top of
+type HelmValuesArgsInterface interface {
+	GetArgs() map[string]interface{} // returns the Helm values
+	SetArgs(map[string]interface{})  // sets the top level Helm values
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// deleteReportOnly is set by the --report-only flag of the delete commands
+var deleteReportOnly = false
+
+// deleteReportFormat is the format of the delete report
+var deleteReportFormat = "table"
+
+// deleteReportOutputFile is the file of the delete report, the report is printed if it is empty
+var deleteReportOutputFile = ""
+
+// addDeleteReportFlags adds the --report-only flags to the delete command and its sub-commands
+func addDeleteReportFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&deleteReportOnly, "report-only", deleteReportOnly, "If true, write a report of everything the command would delete, including the capacities of the PVCs, and stop")
+	cmd.PersistentFlags().StringVar(&deleteReportFormat, "report-format", deleteReportFormat, "Format of the report of --report-only [table|json|yaml]")
+	cmd.PersistentFlags().StringVar(&deleteReportOutputFile, "report-output-file", deleteReportOutputFile, "File to write the report of --report-only to instead of printing it")
+}
+
+// writeDeleteReport completes the report with the namespace if --delete-namespace is set and the details of its
+// PVCs, and writes it
+func writeDeleteReport(report *util.DeleteReport, namespace string) error {
+	if deleteInstanceNamespace {
+		if err := report.AddNamespace(kubeClient, namespace); err != nil {
+			return err
+		}
+	}
+	if err := report.AddPVCDetails(kubeClient); err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if len(deleteReportOutputFile) > 0 {
+		f, err := os.Create(deleteReportOutputFile)
+		if err != nil {
+			return fmt.Errorf("unable to create report file '%s' due to %+v", deleteReportOutputFile, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := report.Print(w, deleteReportFormat); err != nil {
+		return err
+	}
+	if len(deleteReportOutputFile) > 0 {
+		log.Infof("report of %d object(s) to delete written to '%s'", len(report.Objects), deleteReportOutputFile)
+	}
+	return nil
+}
+
+// addReleaseToDeleteReport adds the Helm release and the objects of its manifest to the report
+func addReleaseToDeleteReport(report *util.DeleteReport, releaseName string, namespace string) error {
+	helmRelease, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to get release '%s' in namespace '%s': %+v", releaseName, namespace, err)
+	}
+	chart := ""
+	if helmRelease.Chart != nil && helmRelease.Chart.Metadata != nil {
+		chart = fmt.Sprintf("%s-%s", helmRelease.Chart.Metadata.Name, helmRelease.Chart.Metadata.Version)
+	}
+	return report.AddRelease(helmRelease.Name, namespace, chart, helmRelease.Version, helmRelease.Manifest)
+}
+
+// reportDeleteAlert writes the report of everything that deleteAlert would delete
+func reportDeleteAlert(cmd *cobra.Command, alertName string, namespace string) error {
+	report := util.NewDeleteReport(cmd.CommandPath())
+	helmReleaseName := fmt.Sprintf("%s%s", alertName, globals.AlertPostSuffix)
+	helmRelease, err := util.GetWithHelm3(helmReleaseName, namespace, kubeConfigPath)
+	if err != nil {
+		cleanErrorMsg := cleanAlertHelmError(err.Error(), helmReleaseName, alertName)
+		return fmt.Errorf("failed to get Alert values: %+v", cleanErrorMsg)
+	}
+	if err := addCertificateIssuerToDeleteReport(report, namespace, helmRelease.Config); err != nil {
+		return err
+	}
+	for _, key := range []string{"webserverCustomCertificatesSecretName", "javaKeystoreSecretName"} {
+		if name, ok := helmRelease.Config[key].(string); ok {
+			if err := report.AddSecrets(kubeClient, namespace, name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := addReleaseToDeleteReport(report, helmReleaseName, namespace); err != nil {
+		return err
+	}
+	if err := report.AddExposure(restconfig, kubeClient, alert.GetExposure(namespace, alertName)); err != nil {
+		return err
+	}
+	if err := report.AddPVCs(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", util.AlertName, alertName), util.DeleteSourceLabels); err != nil {
+		return err
+	}
+	return writeDeleteReport(report, namespace)
+}
+
+// reportDeleteBlackDuck writes the report of everything that deleteBlackDuck would delete, and of the namespace if
+// --delete-namespace is set
+func reportDeleteBlackDuck(cmd *cobra.Command, name string, namespace string) error {
+	report := util.NewDeleteReport(cmd.CommandPath())
+	if err := addReleaseToDeleteReport(report, name, namespace); err != nil {
+		return err
+	}
+	secrets := []string{}
+	for _, v := range []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"} {
+		secrets = append(secrets, fmt.Sprintf("%s-%s-%s", name, util.BlackDuckName, v))
+	}
+	if err := report.AddSecrets(kubeClient, namespace, secrets...); err != nil {
+		return err
+	}
+	if helmRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath); err == nil {
+		if err := addCertificateIssuerToDeleteReport(report, namespace, helmRelease.Config); err != nil {
+			return err
+		}
+	}
+	if err := report.AddLabeledResources(kubeClient, namespace, util.BlackDuckName, name); err != nil {
+		return err
+	}
+	if err := report.AddExposure(restconfig, kubeClient, blackduck.GetExposure(namespace, name)); err != nil {
+		return err
+	}
+	return writeDeleteReport(report, namespace)
+}
+
+// reportDeleteRelease writes the report of the deletion of a release without resources outside of the chart
+func reportDeleteRelease(cmd *cobra.Command, releaseName string, namespace string, e *util.Exposure) error {
+	report := util.NewDeleteReport(cmd.CommandPath())
+	if err := addReleaseToDeleteReport(report, releaseName, namespace); err != nil {
+		return err
+	}
+	if e != nil {
+		if err := report.AddExposure(restconfig, kubeClient, *e); err != nil {
+			return err
+		}
+	}
+	return writeDeleteReport(report, namespace)
+}
+
+// reportDeleteBDBA writes the report of everything that the deletion of BDBA would delete
+func reportDeleteBDBA(cmd *cobra.Command, namespace string) error {
+	e := bdba.GetExposure(namespace, globals.BDBAName)
+	return reportDeleteRelease(cmd, globals.BDBAName, namespace, &e)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// skipExternalPostgresValidation skips checking that the external Postgres is reachable and prepared for Black Duck
+var skipExternalPostgresValidation = false
+
+// externalPostgresValidationTimeout is the maximum time to wait for the pod validating the external Postgres
+var externalPostgresValidationTimeout = 2 * time.Minute
+
+// verifyBlackDuckExternalPostgres checks that the external Postgres in the Helm values is reachable from the namespace
+// with the admin and user credentials, and that it has the databases and the extensions of Black Duck
+func verifyBlackDuckExternalPostgres(name string, namespace string, helmValues map[string]interface{}) error {
+	postgres, err := blackduck.GetExternalPostgres(helmValues)
+	if err != nil || postgres == nil {
+		return err
+	}
+	if skipExternalPostgresValidation {
+		log.Warnf("skipping the validation of the external Postgres '%s:%d'", postgres.Host, postgres.Port)
+		return nil
+	}
+	if util.ActivePlan != nil {
+		return nil
+	}
+	return validateExternalPostgres(name, namespace, postgres)
+}
+
+// validateExternalPostgres runs a pod with a Postgres client in the namespace that runs the checks of the external
+// Postgres. The credentials are passed to the pod in a secret that is deleted with the pod
+func validateExternalPostgres(name string, namespace string, postgres *blackduck.ExternalPostgres) error {
+	address := fmt.Sprintf("%s:%d", postgres.Host, postgres.Port)
+	log.Infof("validating the external Postgres '%s'...", address)
+	podName := util.GetResourceName(name, util.BlackDuckName, "external-db-check")
+	labels := map[string]string{"app": util.BlackDuckName, "name": name, "component": "external-db-check"}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace, Labels: labels},
+		Data: map[string][]byte{
+			blackduck.ExternalPostgresAdminPasswordKey: []byte(postgres.AdminPassword),
+			blackduck.ExternalPostgresUserPasswordKey:  []byte(postgres.UserPassword),
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Create(secret); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the secret of the pod validating the external Postgres due to %+v", err)
+	}
+	defer func() {
+		if err := util.DeleteSecret(kubeClient, namespace, podName); err != nil && !k8serrors.IsNotFound(err) {
+			log.Warnf("unable to delete secret '%s' due to %+v", podName, err)
+		}
+	}()
+
+	secretEnv := func(envName string, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: envName,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: podName},
+				Key:                  key,
+			}},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Labels: labels},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "psql",
+					Image:   globals.DefaultPostgresClientImage,
+					Command: []string{"/bin/bash", "-c", blackduck.ExternalPostgresCheckScript},
+					Env: []corev1.EnvVar{
+						{Name: "PGHOST", Value: postgres.Host},
+						{Name: "PGPORT", Value: fmt.Sprintf("%d", postgres.Port)},
+						{Name: "PGSSLMODE", Value: postgres.SSLMode()},
+						{Name: "PGCONNECT_TIMEOUT", Value: "10"},
+						{Name: "ADMIN_USER", Value: postgres.Admin},
+						{Name: "USER_USER", Value: postgres.User},
+						{Name: "DATABASES", Value: strings.Join(bdutil.BlackDuckDatabases, " ")},
+						{Name: "EXTENSIONS", Value: strings.Join(blackduck.ExternalPostgresExtensions, " ")},
+						secretEnv("ADMIN_PASSWORD", blackduck.ExternalPostgresAdminPasswordKey),
+						secretEnv("USER_PASSWORD", blackduck.ExternalPostgresUserPasswordKey),
+					},
+				},
+			},
+		},
+	}
+	if _, err := kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+		return fmt.Errorf("failed to create the pod validating the external Postgres due to %+v", err)
+	}
+	defer func() {
+		if err := util.DeletePod(kubeClient, namespace, podName); err != nil {
+			log.Warnf("unable to delete pod '%s' due to %+v", podName, err)
+		}
+	}()
+
+	timeout := time.NewTimer(externalPostgresValidationTimeout)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	defer timeout.Stop()
+	for {
+		select {
+		case <-timeout.C:
+			return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("the validation of the external Postgres '%s' did not complete within %s, use --skip-external-postgres-validation if the pod can't run in namespace '%s'", address, externalPostgresValidationTimeout, namespace))
+		case <-ticker.C:
+			current, err := util.GetPod(kubeClient, namespace, podName)
+			if err != nil {
+				return fmt.Errorf("unable to get pod '%s' due to %+v", podName, err)
+			}
+			if current.Status.Phase != corev1.PodSucceeded && current.Status.Phase != corev1.PodFailed {
+				continue
+			}
+			logs, err := kubeClient.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{}).DoRaw()
+			if err != nil {
+				return fmt.Errorf("unable to get the logs of pod '%s' due to %+v", podName, err)
+			}
+			log.Debugf("external Postgres check output:\n%s", logs)
+			if problems := blackduck.ParseExternalPostgresCheck(postgres, string(logs)); len(problems) > 0 {
+				return fmt.Errorf("the external Postgres '%s' can't be used by Black Duck:\n  %s", address, strings.Join(problems, "\n  "))
+			}
+			log.Infof("the external Postgres '%s' is reachable and has the databases and extensions of Black Duck", address)
+			return nil
+		}
+	}
+}
@@ -22,10 +22,16 @@ under the License.
 package synopsysctl
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -38,10 +44,132 @@ func verifyClusterType(cType string) error {
 
 func addChartLocationPathFlag(cmd *cobra.Command) {
 	var tmp string
-	cmd.Flags().StringVarP(&tmp, "app-resources-path", "", "", "Absolute path to an application Tarball for air-gapped customer")
+	cmd.Flags().StringVarP(&tmp, "app-resources-path", "", "", "Absolute path to an application Tarball for air-gapped customer, or its URL, e.g. a signed URL, an s3://BUCKET/KEY or gs://BUCKET/OBJECT URL whose credentials are read from the environment, or an oci://REGISTRY/REPOSITORY[:TAG] chart whose tag defaults to the latest chart of the version")
 	// cmd.Flags().MarkHidden("app-resources-path")
+	// mirror-images has the credentials of the registry of its images
+	if cmd.Flags().Lookup("registry-username") == nil {
+		cmd.Flags().StringVar(&util.ChartRegistryUsername, "registry-username", util.ChartRegistryUsername, "Username of the OCI registry of --app-resources-path (default the credentials of the registry in the Helm registry config file)")
+		cmd.Flags().StringVar(&util.ChartRegistryPassword, "registry-password", util.ChartRegistryPassword, "Password of the OCI registry of --app-resources-path")
+	}
+}
+
+// setVersionAwareHelp hides the flags that the chart of the version selected by --version doesn't support from the help output
+func setVersionAwareHelp(cmd *cobra.Command, unsupportedFlags func(version string) []string) {
+	defaultHelp := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		if versionFlag := c.Flags().Lookup("version"); versionFlag != nil && len(versionFlag.Value.String()) > 0 {
+			for _, name := range unsupportedFlags(versionFlag.Value.String()) {
+				if flag := c.Flags().Lookup(name); flag != nil {
+					flag.Hidden = true
+				}
+			}
+		}
+		defaultHelp(c, args)
+	})
+}
+
+// newInterruptibleContext returns a context that is cancelled when synopsysctl is interrupted, so that waiting
+// for the cluster can be stopped without killing the process
+func newInterruptibleContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
+
+// valuesFilePaths are the Helm values files of --values, a file takes precedence over the files before it
+var valuesFilePaths []string
+
+// addValuesFileFlag adds the --values flag to a create or update command
+func addValuesFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&valuesFilePaths, "values", "f", valuesFilePaths, "Absolute path to a YAML file with Helm values of the chart, can be repeated (the flags take precedence over the files)")
+}
+
+// setValuesFromFiles merges the --values files into the Helm values of a product before the flags are applied, so that
+// the flags take precedence over the files and the files take precedence over the current values of an instance
+func setValuesFromFiles(helper HelmValuesArgsInterface) error {
+	if len(valuesFilePaths) == 0 {
+		return nil
+	}
+	values, err := util.ReadHelmValuesFiles(valuesFilePaths)
+	if err != nil {
+		return util.ValidationError("%+v", err)
+	}
+	helper.SetArgs(util.MergeMaps(helper.GetArgs(), values))
+	return nil
+}
+
+// nativeOutputFormat and nativeOutputDir are set by the --output and --output-dir flags of the native commands
+var nativeOutputFormat = util.NativeOutputFormatYAML
+var nativeOutputDir = ""
+
+// addNativeOutputFlags adds the flags of the output of a native command
+func addNativeOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&nativeOutputFormat, "output", "o", nativeOutputFormat, "Output format of the resources, json prints a single v1 List [yaml|json]")
+	cmd.Flags().StringVar(&nativeOutputDir, "output-dir", nativeOutputDir, "If set, write each resource to its own file <kind>-<name>.yaml in the directory, along with a kustomization.yaml of the files, instead of printing them")
+}
+
+// printNativeResources renders the chart and prints the objects followed by the rendered resources in the --output
+// format, or writes them to the files of --output-dir
+func printNativeResources(objects []interface{}, releaseName, chartURL string, vals map[string]interface{}, extraFiles ...string) error {
+	if err := util.ValidateNativeOutputFormat(nativeOutputFormat); err != nil {
+		return err
+	}
+	manifests, err := util.RenderWithHelm3(releaseName, namespace, chartURL, vals, extraFiles...)
+	if err != nil {
+		return err
+	}
+	if len(nativeOutputDir) > 0 {
+		manifestObjects, err := util.GetManifestObjects(objects, manifests)
+		if err != nil {
+			return err
+		}
+		fileNames, err := util.WriteManifestFiles(nativeOutputDir, namespace, manifestObjects, nativeOutputFormat)
+		if err != nil {
+			return err
+		}
+		log.Infof("wrote %d resource(s) and %s to '%s'", len(fileNames), util.KustomizationFileName, nativeOutputDir)
+		return nil
+	}
+	if strings.ToLower(nativeOutputFormat) == util.NativeOutputFormatJSON {
+		list, err := util.NewManifestList(objects, manifests)
+		if err != nil {
+			return err
+		}
+		_, err = PrintComponent(list, "JSON")
+		return err
+	}
+	for _, object := range objects {
+		fmt.Printf("---\n")
+		if _, err := PrintComponent(object, "YAML"); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%+v\n", manifests)
+	return nil
 }
 
+// instanceUsage returns the usage, the example arguments and the number of arguments of a command on an instance of a
+// product, the BDBA instance is named by its namespace
+func instanceUsage(app string) (string, string, int) {
+	if app == globals.BDBAName {
+		return fmt.Sprintf("%s -n NAMESPACE", app), fmt.Sprintf("%s -n <namespace>", app), 0
+	}
+	return fmt.Sprintf("%s NAME -n NAMESPACE", app), fmt.Sprintf("%s <name> -n <namespace>", app), 1
+}
+
+// offlineCommandAnnotation marks the commands that don't connect to the cluster, like the native commands
+const offlineCommandAnnotation = "synopsysctl/offline"
+
 func addNativeFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&globals.NativeClusterType, "target", globals.NativeClusterType, "Type of cluster to generate the resources for [KUBERNETES|OPENSHIFT]")
+	cmd.Flags().StringVar(&renderContext, "render-context", renderContext, "Kube-context of a cluster to look up the existing objects of the instance in while rendering, e.g. to reuse its database passwords (opt-in)")
 }
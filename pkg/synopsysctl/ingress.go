@@ -0,0 +1,43 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// applyExposureIngress creates or updates the ingress of the user interface of an Alert or Black Duck instance if it is
+// exposed with --expose-ui ingress, and deletes it otherwise
+func applyExposureIngress(app string, name string, namespace string, helmValues map[string]interface{}) error {
+	if util.ActivePlan != nil {
+		if util.GetIngressFromHelmValues(helmValues) != nil {
+			skipForPlan(util.PlanActionApply, "Ingress", util.GetResourceName(name, app, ""), namespace)
+		}
+		return nil
+	}
+	if app == util.AlertName {
+		return alert.CRUDIngress(restconfig, namespace, name, helmValues)
+	}
+	return blackduck.CRUDIngress(restconfig, namespace, name, helmValues)
+}
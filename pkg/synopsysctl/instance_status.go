@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"os"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Statuses of the instances, as set by the status value of the charts
+const (
+	instanceStatusRunning = "Running"
+	instanceStatusStopped = "Stopped"
+)
+
+// setInstanceStatus stops or starts an instance with the status value of its chart, or by scaling its deployments and
+// stateful sets for the charts without one, and prints the workloads whose replicas changed
+func setInstanceStatus(instance *release.Release, chartURL string, status string) error {
+	before, err := util.GetInstanceWorkloads(kubeClient, namespace, instance.Manifest)
+	if err != nil {
+		return err
+	}
+	hasStatus, err := util.ChartHasHelmValue(chartURL, []string{"status"})
+	if err != nil {
+		return err
+	}
+	if hasStatus {
+		helmValuesMap := instance.Config
+		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, status)
+		if err := util.UpdateWithHelm3(instance.Name, namespace, chartURL, helmValuesMap, kubeConfigPath); err != nil {
+			return err
+		}
+	} else {
+		log.Infof("the chart of release '%s' has no status value, scaling its workloads instead", instance.Name)
+		for _, workload := range before {
+			if skipForPlan(util.PlanActionApply, workload.Kind, workload.Name, namespace) {
+				continue
+			}
+			if err := util.ScaleInstanceWorkload(kubeClient, namespace, workload, status == instanceStatusStopped); err != nil {
+				return err
+			}
+		}
+		if util.ActivePlan != nil {
+			return nil
+		}
+	}
+
+	after, err := util.GetInstanceWorkloads(kubeClient, namespace, instance.Manifest)
+	if err != nil {
+		return err
+	}
+	if util.PrintWorkloadChanges(os.Stdout, before, after) == 0 {
+		log.Infof("the replicas of the workloads of release '%s' didn't change, the instance may already be %s", instance.Name, map[string]string{instanceStatusRunning: "running", instanceStatusStopped: "stopped"}[status])
+	}
+	return nil
+}
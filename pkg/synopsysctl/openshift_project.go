@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenShift Project Options and Defaults
+var openshiftProjectCreate = false
+var openshiftProjectDisplayName = ""
+var openshiftProjectDescription = ""
+var openshiftProjectNodeSelector = ""
+var openshiftProjectAnnotations = map[string]string{}
+
+// addOpenShiftProjectFlags adds the flags to create the namespace of the instance as an OpenShift project
+func addOpenShiftProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&openshiftProjectCreate, "openshift-project-create", openshiftProjectCreate, "If true, create the namespace as an OpenShift project with a ProjectRequest if it doesn't exist, so that the project template of the cluster applies to it")
+	cmd.Flags().StringVar(&openshiftProjectDisplayName, "openshift-project-display-name", openshiftProjectDisplayName, "Display name of the OpenShift project created with --openshift-project-create")
+	cmd.Flags().StringVar(&openshiftProjectDescription, "openshift-project-description", openshiftProjectDescription, "Description of the OpenShift project created with --openshift-project-create")
+	cmd.Flags().StringVar(&openshiftProjectNodeSelector, "openshift-project-node-selector", openshiftProjectNodeSelector, "Node selector of the OpenShift project created with --openshift-project-create [e.g. region=east,tier=apps]")
+	cmd.Flags().StringToStringVar(&openshiftProjectAnnotations, "openshift-project-annotations", openshiftProjectAnnotations, "Annotations of the OpenShift project created with --openshift-project-create [e.g. key1=value1,key2=value2]")
+}
+
+// getOpenShiftProject returns the project of the flags for the namespace
+func getOpenShiftProject(namespace string, labels map[string]string) util.OpenShiftProject {
+	return util.OpenShiftProject{
+		Name:         namespace,
+		DisplayName:  openshiftProjectDisplayName,
+		Description:  openshiftProjectDescription,
+		NodeSelector: openshiftProjectNodeSelector,
+		Annotations:  openshiftProjectAnnotations,
+		Labels:       labels,
+	}
+}
+
+// ensureOpenShiftProject creates the namespace as an OpenShift project if --openshift-project-create is set and the
+// namespace doesn't exist yet
+func ensureOpenShiftProject(namespace string) error {
+	if !openshiftProjectCreate {
+		return nil
+	}
+	project := getOpenShiftProject(namespace, nil)
+	if _, err := project.GetAnnotations(); err != nil {
+		return err
+	}
+	if _, err := util.GetNamespace(kubeClient, namespace); err == nil {
+		log.Debugf("namespace '%s' already exists, it is not created as an OpenShift project", namespace)
+		return nil
+	} else if !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get namespace '%s' due to %+v", namespace, err)
+	}
+	if !util.IsOpenshift(kubeClient) {
+		return util.ValidationError("--openshift-project-create requires an OpenShift cluster")
+	}
+	if skipForPlan(util.PlanActionCreate, "Project", namespace, "") {
+		return nil
+	}
+	if _, err := util.CreateOpenShiftProject(restconfig, kubeClient, project); err != nil {
+		return err
+	}
+	log.Infof("created OpenShift project '%s'", namespace)
+	return nil
+}
+
+// createNamespace creates a namespace with the labels, as an OpenShift project if --openshift-project-create is set
+func createNamespace(namespace string, labels map[string]string) (*corev1.Namespace, error) {
+	if openshiftProjectCreate {
+		if !util.IsOpenshift(kubeClient) {
+			return nil, util.ValidationError("--openshift-project-create requires an OpenShift cluster")
+		}
+		return util.CreateOpenShiftProject(restconfig, kubeClient, getOpenShiftProject(namespace, labels))
+	}
+	ns, err := kubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   namespace,
+			Labels: labels,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create namespace '%s' due to %+v", namespace, err)
+	}
+	return ns, nil
+}
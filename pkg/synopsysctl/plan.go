@@ -0,0 +1,108 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"os"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// planChanges is set by the --plan flag of the mutating commands
+var planChanges = false
+
+// planOutputFormat is the format of the printed plan
+var planOutputFormat = "table"
+
+// dryRunMode is set by the --dry-run flag of the mutating commands
+var dryRunMode = ""
+
+// addPlanFlags adds the --plan flags to a mutating command and its sub-commands
+func addPlanFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&planChanges, "plan", planChanges, "If true, print the changes the command would make to the cluster and stop")
+	cmd.PersistentFlags().StringVar(&planOutputFormat, "plan-output", planOutputFormat, "Output format of --plan [table|json]")
+}
+
+// addDryRunFlag adds the --dry-run flag to a mutating command and its sub-commands. A dry run is a plan that prints the
+// manifests of its Helm operations
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&dryRunMode, "dry-run", dryRunMode, "If set, print the manifests the command would apply or delete and stop, 'server' renders them with a Helm dry run against the cluster [client|server]")
+}
+
+// startPlan starts the plan of the command if --plan or --dry-run is set
+func startPlan(cmd *cobra.Command) error {
+	if planChanges && len(dryRunMode) > 0 {
+		return util.ValidationError("--plan and --dry-run can't be used together")
+	}
+	if planChanges {
+		if planOutputFormat != "table" && planOutputFormat != "json" {
+			return util.ValidationError("--plan-output must be 'table' or 'json', got '%s'", planOutputFormat)
+		}
+		util.StartPlan(cmd.CommandPath())
+	}
+	if len(dryRunMode) > 0 {
+		if dryRunMode != util.DryRunClient && dryRunMode != util.DryRunServer {
+			return util.ValidationError("--dry-run must be '%s' or '%s', got '%s'", util.DryRunClient, util.DryRunServer, dryRunMode)
+		}
+		util.StartPlan(cmd.CommandPath()).DryRun = dryRunMode
+	}
+	return nil
+}
+
+// planServiceOrRoute records the exposed service or the route of the user interface, which the command creates after
+// its Helm operation and so after the plan stopped
+func planServiceOrRoute(serviceName string, routeName string, namespace string, isExposedUI interface{}, exposedServiceType interface{}) {
+	if exposed, _ := isExposedUI.(bool); !exposed || exposedServiceType == "Ingress" {
+		return
+	}
+	if exposedServiceType == "OpenShift" {
+		skipForPlan(util.PlanActionApply, "Route", routeName, namespace)
+		return
+	}
+	skipForPlan(util.PlanActionApply, "Service", serviceName, namespace)
+}
+
+// skipForPlan records a change in the active plan and returns true if the command only plans its changes, so that the
+// caller doesn't make the change
+func skipForPlan(action string, kind string, name string, namespace string) bool {
+	if util.ActivePlan == nil {
+		return false
+	}
+	util.ActivePlan.AddResource(action, kind, name, namespace)
+	return true
+}
+
+// printActivePlan prints the plan of the command if --plan or --dry-run is set. It returns false if the command failed
+// before its changes were planned
+func printActivePlan(cmdErr error) (bool, error) {
+	if util.ActivePlan == nil {
+		return false, nil
+	}
+	if cmdErr != nil && !util.ActivePlan.Complete {
+		return false, nil
+	}
+	if len(util.ActivePlan.DryRun) > 0 {
+		return true, util.ActivePlan.PrintDryRun(os.Stdout)
+	}
+	return true, util.ActivePlan.Print(os.Stdout, planOutputFormat)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// POC Command Options and Defaults
+var pocMode = false
+var pocTTL = 72 * time.Hour
+
+// addPOCFlags adds the flags to deploy a time-boxed trial instance
+func addPOCFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&pocMode, "poc", pocMode, "If true, deploy a minimal trial instance (ephemeral storage, single replicas, relaxed probes) that expires after --poc-ttl")
+	cmd.Flags().DurationVar(&pocTTL, "poc-ttl", pocTTL, "Time until a trial instance created with --poc expires [e.g. 4h, 72h]")
+}
+
+// pocBlackDuckComponents are the components of Black Duck that a trial instance runs with a single replica
+var pocBlackDuckComponents = []string{"authentication", "binaryscanner", "bomengine", "jobrunner", "scan", "webapp"}
+
+// pocAlertComponents are the components of Alert that a trial instance runs with a single replica
+var pocAlertComponents = []string{"alert", "cfssl", "postgres"}
+
+// setPOCHelmValues sets the Helm values of the minimal footprint of a trial instance. The values that the user already
+// set with their flags, like --size or --persistent-storage, are kept
+func setPOCHelmValues(appName string, helmValuesMap map[string]interface{}) error {
+	if pocTTL <= 0 {
+		return fmt.Errorf("--poc-ttl must be greater than 0")
+	}
+	setPOCHelmValue(helmValuesMap, []string{"enablePersistentStorage"}, false)
+	setPOCHelmValue(helmValuesMap, []string{"enableLivenessProbe"}, false)
+	components := []string{}
+	switch appName {
+	case util.BlackDuckName:
+		setPOCHelmValue(helmValuesMap, []string{"size"}, "small")
+		components = pocBlackDuckComponents
+	case util.AlertName:
+		setPOCHelmValue(helmValuesMap, []string{"enableStandalone"}, true)
+		components = pocAlertComponents
+	}
+	for _, component := range components {
+		setPOCHelmValue(helmValuesMap, []string{component, "replicas"}, 1)
+	}
+	return nil
+}
+
+// setPOCHelmValue sets a Helm value of a trial instance unless it is already set, in which case it is kept with a warning
+func setPOCHelmValue(helmValuesMap map[string]interface{}, path []string, value interface{}) {
+	current := util.GetHelmValueFromMap(helmValuesMap, path)
+	if current == nil {
+		util.SetHelmValueInMap(helmValuesMap, path, value)
+		return
+	}
+	if fmt.Sprint(current) != fmt.Sprint(value) {
+		log.Warnf("the trial instance uses '%s' set to '%v' instead of '%v' of --poc", strings.Join(path, "."), current, value)
+	}
+}
+
+// labelPOCInstance adds the POC labels with the expiry to all resources of the instance
+func labelPOCInstance(appName string, name string, namespace string) error {
+	expiry := time.Now().Add(pocTTL)
+	labelSelector := fmt.Sprintf("app=%s, name=%s", appName, name)
+	if err := util.AddLabelsToInstanceResources(kubeClient, namespace, labelSelector, util.GetPOCLabels(expiry)); err != nil {
+		return fmt.Errorf("failed to label the trial instance: %+v", err)
+	}
+	log.Infof("trial instance '%s' in namespace '%s' expires at %s, run 'synopsysctl gc --expired' to clean up expired trial instances", name, namespace, expiry.Format(time.RFC3339))
+	return nil
+}
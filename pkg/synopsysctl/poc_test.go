@@ -0,0 +1,68 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPOCHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	alertValues := map[string]interface{}{}
+	assert.NoError(setPOCHelmValues(util.AlertName, alertValues))
+	assert.Equal(false, alertValues["enablePersistentStorage"])
+	assert.Equal(false, alertValues["enableLivenessProbe"])
+	assert.Equal(true, alertValues["enableStandalone"])
+	for _, component := range pocAlertComponents {
+		assert.Equal(1, util.GetHelmValueFromMap(alertValues, []string{component, "replicas"}))
+	}
+
+	blackDuckValues := map[string]interface{}{}
+	assert.NoError(setPOCHelmValues(util.BlackDuckName, blackDuckValues))
+	assert.Equal(false, blackDuckValues["enablePersistentStorage"])
+	assert.Equal(false, blackDuckValues["enableLivenessProbe"])
+	assert.Equal("small", blackDuckValues["size"])
+	for _, component := range pocBlackDuckComponents {
+		assert.Equal(1, util.GetHelmValueFromMap(blackDuckValues, []string{component, "replicas"}))
+	}
+
+	// the values set with the flags of the user are kept
+	userValues := map[string]interface{}{"size": "medium", "enablePersistentStorage": true, "scan": map[string]interface{}{"replicas": 2}}
+	assert.NoError(setPOCHelmValues(util.BlackDuckName, userValues))
+	assert.Equal("medium", userValues["size"])
+	assert.Equal(true, userValues["enablePersistentStorage"])
+	assert.Equal(2, util.GetHelmValueFromMap(userValues, []string{"scan", "replicas"}))
+	assert.Equal(1, util.GetHelmValueFromMap(userValues, []string{"webapp", "replicas"}))
+}
+
+func TestSetPOCHelmValuesTTL(t *testing.T) {
+	assert := assert.New(t)
+	defer func(ttl time.Duration) { pocTTL = ttl }(pocTTL)
+
+	pocTTL = 0
+	assert.Error(setPOCHelmValues(util.AlertName, map[string]interface{}{}))
+}
@@ -0,0 +1,246 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	securityclient "github.com/openshift/client-go/security/clientset/versioned/typed/security/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// preflightNamespacedPermissions are the permissions in the namespace of the instance that every chart needs
+var preflightNamespacedPermissions = []util.PreflightPermission{
+	{Verb: "create", Group: "apps", Resource: "deployments"},
+	{Verb: "create", Group: "apps", Resource: "statefulsets"},
+	{Verb: "create", Resource: "services"},
+	{Verb: "create", Resource: "configmaps"},
+	{Verb: "create", Resource: "secrets"},
+	{Verb: "create", Resource: "persistentvolumeclaims"},
+	{Verb: "create", Resource: "serviceaccounts"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "roles"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "rolebindings"},
+}
+
+// preflightClusterPermissions are the cluster scoped permissions of the products whose charts create cluster roles
+var preflightClusterPermissions = map[string][]util.PreflightPermission{
+	util.OpsSightName: {
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	},
+}
+
+// preflightSCCs are the OpenShift security context constraints that the service accounts of the products need, the
+// OpsSight scanners need a privileged container and the Black Duck database runs with a fixed user
+var preflightSCCs = map[string]string{
+	util.AlertName:     "restricted",
+	util.BlackDuckName: "anyuid",
+	util.OpsSightName:  "privileged",
+	globals.BDBAName:   "restricted",
+}
+
+// preflightChartURL returns the chart of a product
+func preflightChartURL(app string) string {
+	switch app {
+	case util.AlertName:
+		return globals.AlertChartRepository
+	case util.BlackDuckName:
+		return globals.BlackDuckChartRepository
+	case util.OpsSightName:
+		return globals.OpsSightChartRepository
+	}
+	return globals.BDBAChartRepository
+}
+
+// runPreflightChecks validates that an instance of the product can be created in the namespace, and that its name doesn't
+// collide with another instance if it is set
+func runPreflightChecks(flagset *pflag.FlagSet, app string, namespace string, instanceName string, storageClass string, registry string) *util.PreflightReport {
+	report := util.NewPreflightReport(app, namespace)
+
+	chartURL := preflightChartURL(app)
+	var loadedChart *chart.Chart
+	actionConfig, err := util.CreateHelmActionConfiguration(kubeConfigPath, "", namespace)
+	if err == nil {
+		loadedChart, err = util.LoadChart(chartURL, actionConfig)
+	}
+	if err != nil {
+		report.Add(util.NewPreflightResult(util.PreflightCheckChartRepository, util.PreflightFail, "unable to load the chart '%s': %+v", chartURL, err))
+	} else {
+		report.Add(util.NewPreflightResult(util.PreflightCheckChartRepository, util.PreflightPass, "loaded the chart '%s'", chartURL))
+	}
+
+	info, err := util.GetClusterInfo(kubeClient)
+	if err != nil {
+		log.Debugf("unable to discover the cluster due to %+v", err)
+		info = &util.ClusterInfo{}
+	}
+	kubeVersionConstraint := ""
+	if loadedChart != nil && loadedChart.Metadata != nil {
+		kubeVersionConstraint = loadedChart.Metadata.KubeVersion
+	}
+	report.Add(util.CheckPreflightKubeVersion(info.ServerVersion, kubeVersionConstraint))
+
+	if len(instanceName) > 0 {
+		report.Add(checkPreflightNameCollision(app, namespace, instanceName, loadedChart, actionConfig))
+	}
+
+	if loadedChart != nil {
+		report.Add(checkPreflightCapacity(flagset, app, namespace, loadedChart, actionConfig))
+	} else {
+		report.Add(util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "unable to compute the requests of the instance without its chart"))
+	}
+
+	if storageClasses, err := kubeClient.StorageV1().StorageClasses().List(metav1.ListOptions{}); err != nil {
+		report.Add(util.NewPreflightResult(util.PreflightCheckStorageClass, util.PreflightWarn, "unable to list the storage classes due to %+v", err))
+	} else {
+		names := []string{}
+		for _, storageClass := range storageClasses.Items {
+			names = append(names, storageClass.Name)
+		}
+		report.Add(util.CheckPreflightStorageClass(storageClass, info.DefaultStorageClass, names))
+	}
+
+	report.Add(checkPreflightPermissions(app, namespace))
+
+	if util.IsOpenshift(kubeClient) {
+		report.Add(checkPreflightSCC(app))
+	} else {
+		report.Add(util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightPass, "not an OpenShift cluster"))
+	}
+
+	if registryURL, err := util.GetRegistryAPIURL(registry); err != nil {
+		report.Add(util.NewPreflightResult(util.PreflightCheckImageRegistry, util.PreflightFail, "%+v", err))
+	} else if err := util.CheckURLReachable(registryURL); err != nil {
+		report.Add(util.NewPreflightResult(util.PreflightCheckImageRegistry, util.PreflightFail, "%+v", err))
+	} else {
+		report.Add(util.NewPreflightResult(util.PreflightCheckImageRegistry, util.PreflightPass, "reached the registry '%s'", registry))
+	}
+	return report
+}
+
+// checkPreflightNameCollision checks that the name of the instance isn't used by a release or the resources of another
+// instance in the namespace, e.g. an Alert instance named like a Black Duck instance
+func checkPreflightNameCollision(app string, namespace string, instanceName string, loadedChart *chart.Chart, actionConfig *action.Configuration) util.PreflightResult {
+	if loadedChart == nil {
+		return util.NewPreflightResult(util.PreflightCheckNameCollision, util.PreflightWarn, "unable to render the names of the resources without the chart")
+	}
+	existingRelease, _ := util.GetWithHelm3(instanceName, namespace, kubeConfigPath)
+	if err := util.CheckReleaseNameCollision(existingRelease, loadedChart.Metadata.Name); err != nil {
+		return util.NewPreflightResult(util.PreflightCheckNameCollision, util.PreflightFail, "%+v", err)
+	}
+	if err := util.CheckNameCollisionsForActionConfig(instanceName, namespace, loadedChart, map[string]interface{}{}, actionConfig); err != nil {
+		return util.NewPreflightResult(util.PreflightCheckNameCollision, util.PreflightFail, "%+v", err)
+	}
+	return util.NewPreflightResult(util.PreflightCheckNameCollision, util.PreflightPass, "the name '%s' is not used by another instance", instanceName)
+}
+
+// checkPreflightCapacity compares the requests of the rendered chart with the resources that are left on the nodes
+func checkPreflightCapacity(flagset *pflag.FlagSet, app string, namespace string, loadedChart *chart.Chart, actionConfig *action.Configuration) util.PreflightResult {
+	helmValues := map[string]interface{}{}
+	extraFiles := []string{}
+	if app == util.BlackDuckName {
+		sizeFile, sizeValues, err := getBlackDuckSizeValues(flagset, namespace, checkBlackDuckSize)
+		if err != nil {
+			return util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "%+v", err)
+		}
+		if len(sizeFile) > 0 {
+			extraFiles = append(extraFiles, sizeFile)
+		}
+		helmValues = util.MergeMaps(sizeValues, map[string]interface{}{"size": checkBlackDuckSize})
+	}
+	manifest, err := util.RenderLoadedChartManifests(app, namespace, loadedChart, helmValues, actionConfig, extraFiles...)
+	if err != nil {
+		return util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "unable to render the chart: %+v", err)
+	}
+	requested, err := util.GetManifestResourceRequests(manifest)
+	if err != nil {
+		return util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "%+v", err)
+	}
+	nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "unable to list the nodes due to %+v", err)
+	}
+	pods, err := kubeClient.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return util.NewPreflightResult(util.PreflightCheckCapacity, util.PreflightWarn, "unable to list the pods due to %+v", err)
+	}
+	return util.CheckPreflightCapacity(requested, util.GetAvailableResources(nodes.Items, pods.Items))
+}
+
+// checkPreflightPermissions asks the cluster whether the current user has the permissions needed to create the instance
+func checkPreflightPermissions(app string, namespace string) util.PreflightResult {
+	denied := []util.PreflightPermission{}
+	permissions := append(append([]util.PreflightPermission{}, preflightNamespacedPermissions...), preflightClusterPermissions[app]...)
+	for i, permission := range permissions {
+		permissionNamespace := namespace
+		if i >= len(preflightNamespacedPermissions) {
+			permissionNamespace = ""
+		}
+		allowed, err := isAllowed(authorizationv1.ResourceAttributes{Namespace: permissionNamespace, Verb: permission.Verb, Group: permission.Group, Resource: permission.Resource})
+		if err != nil {
+			return util.NewPreflightResult(util.PreflightCheckPermissions, util.PreflightWarn, "unable to review the permissions of the user due to %+v", err)
+		}
+		if !allowed {
+			denied = append(denied, permission)
+		}
+	}
+	return util.CheckPreflightPermissions(namespace, len(permissions), denied)
+}
+
+// checkPreflightSCC checks that the security context constraints of the product exists and that the user can use it or
+// grant it to the service accounts of the instance
+func checkPreflightSCC(app string) util.PreflightResult {
+	sccName := preflightSCCs[app]
+	securityClient, err := securityclient.NewForConfig(restconfig)
+	if err != nil {
+		return util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightWarn, "unable to create the OpenShift security client due to %+v", err)
+	}
+	if _, err := util.GetOpenShiftSecurityConstraint(securityClient, sccName); err != nil {
+		return util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightFail, "unable to get the security context constraints '%s' due to %+v", sccName, err)
+	}
+	for _, verb := range []string{"use", "update"} {
+		allowed, err := isAllowed(authorizationv1.ResourceAttributes{Verb: verb, Group: "security.openshift.io", Resource: "securitycontextconstraints", Name: sccName})
+		if err != nil {
+			return util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightWarn, "unable to review the permissions of the user due to %+v", err)
+		}
+		if allowed {
+			return util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightPass, "the user can %s the security context constraints '%s'", verb, sccName)
+		}
+	}
+	return util.NewPreflightResult(util.PreflightCheckOpenShiftSCC, util.PreflightWarn, "the user can't grant the security context constraints '%s', an administrator needs to add the service accounts of the instance to it", sccName)
+}
+
+// isAllowed returns true if the current user is allowed to perform the action
+func isAllowed(attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review, err := kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(&authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// synopsysctlProfile is a set of settings shared by the instances of a fleet, e.g.
+//
+//	upgradePolicy:
+//	  blackduck: track-minor
+//	  alert: pinned
+type synopsysctlProfile struct {
+	UpgradePolicy map[string]string `json:"upgradePolicy,omitempty"`
+}
+
+// loadProfile reads the profile from the file path, or from the synopsysctl config file if no path is given
+func loadProfile(profilePath string) (*synopsysctlProfile, error) {
+	profile := &synopsysctlProfile{}
+	if len(profilePath) == 0 {
+		profile.UpgradePolicy = viper.GetStringMapString("upgradePolicy")
+		return profile, nil
+	}
+	data, err := util.ReadFromFile(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %+v", err)
+	}
+	if err := yaml.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile '%s' due to %+v", profilePath, err)
+	}
+	return profile, nil
+}
+
+// getUpgradePolicy returns the upgrade policy that the profile declares for the product
+func (p *synopsysctlProfile) getUpgradePolicy(product string) (util.UpgradePolicy, bool, error) {
+	policy, ok := p.UpgradePolicy[product]
+	if !ok {
+		return "", false, nil
+	}
+	upgradePolicy, err := util.ParseUpgradePolicy(policy)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid upgrade policy for %s: %+v", product, err)
+	}
+	return upgradePolicy, true, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// renderContext is the kube-context of the cluster whose existing objects the native commands look up while rendering
+var renderContext = ""
+
+// getRenderContextClient returns a client of the cluster of the --render-context kube-context. The native commands
+// don't connect to a cluster otherwise
+func getRenderContextClient() (*kubernetes.Clientset, error) {
+	loadingRules := util.NewKubeconfigLoadingRules(kubeConfigPath)
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: renderContext}
+	overrides.ClusterInfo.InsecureSkipTLSVerify = insecureSkipTLSVerify
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kube-context '%s' due to %+v", renderContext, err)
+	}
+	return getKubeClient(config)
+}
+
+// resolveRenderContextLookups sets the Helm values of the lookups from the existing objects of the --render-context
+// cluster, so that rendering the manifests of an existing instance reuses its generated credentials
+func resolveRenderContextLookups(namespace string, lookups []util.RenderLookup, helmValuesMap map[string]interface{}) error {
+	if len(renderContext) == 0 {
+		return nil
+	}
+	client, err := getRenderContextClient()
+	if err != nil {
+		return err
+	}
+	if err := util.ResolveRenderLookups(client, namespace, lookups, helmValuesMap); err != nil {
+		return fmt.Errorf("failed to look up the existing objects in kube-context '%s' due to %+v", renderContext, err)
+	}
+	return nil
+}
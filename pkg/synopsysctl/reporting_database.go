@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// skipReportingDatabaseValidation skips checking that the reporting database is a reachable read-only replica
+var skipReportingDatabaseValidation = false
+
+// reportingDatabaseFlags are the Black Duck flags that configure the reporting database
+var reportingDatabaseFlags = []string{
+	"reporting-postgres-host",
+	"reporting-postgres-port",
+	"reporting-postgres-database",
+	"reporting-postgres-user",
+	"reporting-postgres-ssl",
+	"reporting-postgres-password-file-path",
+}
+
+// verifyBlackDuckReportingDatabase checks that the chart supports a reporting database and that the configured database
+// is reachable from the cluster and read-only, if a reporting database flag was set. The credentials secret must exist
+func verifyBlackDuckReportingDatabase(flagset *pflag.FlagSet, name string, namespace string, chartURL string, helmValues map[string]interface{}) error {
+	changed := false
+	for _, flag := range reportingDatabaseFlags {
+		if f := flagset.Lookup(flag); f != nil && f.Changed {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	supported, err := util.ChartHasHelmValue(chartURL, []string{"postgres", "reporting"})
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("the Black Duck chart at '%s' does not support a reporting database", chartURL)
+	}
+	host, _ := util.GetHelmValueFromMap(helmValues, []string{"postgres", "reporting", "host"}).(string)
+	if len(host) == 0 {
+		return fmt.Errorf("--reporting-postgres-host is required to use a reporting database")
+	}
+	secretName, _ := util.GetHelmValueFromMap(helmValues, []string{"postgres", "reporting", "secretName"}).(string)
+	if len(secretName) == 0 {
+		return fmt.Errorf("--reporting-postgres-password-file-path is required to use a reporting database")
+	}
+	if skipReportingDatabaseValidation {
+		log.Warnf("skipping the validation of the reporting database '%s'", host)
+		return nil
+	}
+	return validateReportingDatabase(name, namespace, secretName, helmValues)
+}
+
+// validateReportingDatabase runs a pod with a Postgres client in the namespace that connects to the reporting database
+// and checks that it is a replica in recovery or that its transactions are read-only
+func validateReportingDatabase(name string, namespace string, secretName string, helmValues map[string]interface{}) error {
+	getValue := func(key string, defaultValue interface{}) string {
+		if value := util.GetHelmValueFromMap(helmValues, []string{"postgres", "reporting", key}); value != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return fmt.Sprintf("%v", defaultValue)
+	}
+	host := getValue("host", "")
+	port := getValue("port", blackduck.DefaultFlagTree.ReportingPostgresPort)
+	database := getValue("database", blackduck.DefaultFlagTree.ReportingPostgresDatabase)
+	user := getValue("userUserName", blackduck.DefaultFlagTree.ReportingPostgresUser)
+	sslMode := "disable"
+	if getValue("ssl", true) == "true" {
+		sslMode = "require"
+	}
+
+	log.Infof("validating the reporting database '%s:%s'...", host, port)
+	podName := util.GetResourceName(name, util.BlackDuckName, "reporting-db-check")
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   podName,
+			Labels: map[string]string{"app": util.BlackDuckName, "name": name, "component": "reporting-db-check"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "psql",
+					Image:   globals.DefaultPostgresClientImage,
+					Command: []string{"psql", "-h", host, "-p", port, "-U", user, "-d", database, "-tAc", "SELECT pg_is_in_recovery() OR current_setting('transaction_read_only') = 'on'"},
+					Env: []corev1.EnvVar{
+						{Name: "PGSSLMODE", Value: sslMode},
+						{Name: "PGCONNECT_TIMEOUT", Value: "10"},
+						{
+							Name: "PGPASSWORD",
+							ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+								Key:                  blackduck.ReportingPostgresPasswordSecretKey,
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+		return fmt.Errorf("failed to create the pod validating the reporting database due to %+v", err)
+	}
+	defer func() {
+		if err := util.DeletePod(kubeClient, namespace, podName); err != nil {
+			log.Warnf("unable to delete pod '%s' due to %+v", podName, err)
+		}
+	}()
+
+	timeout := time.NewTimer(2 * time.Minute)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	defer timeout.Stop()
+	for {
+		select {
+		case <-timeout.C:
+			return fmt.Errorf("the validation of the reporting database '%s:%s' did not complete, use --skip-reporting-postgres-validation if it is not reachable from the namespace yet", host, port)
+		case <-ticker.C:
+			current, err := util.GetPod(kubeClient, namespace, podName)
+			if err != nil {
+				return fmt.Errorf("unable to get pod '%s' due to %+v", podName, err)
+			}
+			if current.Status.Phase != corev1.PodSucceeded && current.Status.Phase != corev1.PodFailed {
+				continue
+			}
+			logs, err := kubeClient.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{}).DoRaw()
+			if err != nil {
+				return fmt.Errorf("unable to get the logs of pod '%s' due to %+v", podName, err)
+			}
+			output := strings.TrimSpace(string(logs))
+			if current.Status.Phase == corev1.PodFailed {
+				return fmt.Errorf("the reporting database '%s:%s' is not reachable: %s", host, port, output)
+			}
+			if output != "t" {
+				return fmt.Errorf("the reporting database '%s:%s' accepts writes, it must be a read-only replica", host, port)
+			}
+			log.Infof("the reporting database '%s:%s' is a reachable read-only replica", host, port)
+			return nil
+		}
+	}
+}
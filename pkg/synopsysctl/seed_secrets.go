@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+
+	bdutil "github.com/blackducksoftware/synopsysctl/pkg/blackduck/util"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// seedSecretsFile is the encrypted file of the credentials that the chart uses instead of generating them
+var seedSecretsFile = ""
+
+// addSeedSecretsFlag adds the flag of the seed secrets file to a create command
+func addSeedSecretsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&seedSecretsFile, "seed-secrets-file", seedSecretsFile, fmt.Sprintf("Path to a file of 'secrets export-seeds' whose credentials are used instead of generated ones, e.g. to restore an instance with matching credentials. The passphrase is read from $%s", util.SecretSeedsPassphraseEnv))
+}
+
+// getSecretSeedLookups returns the credentials that the chart of the product generates
+func getSecretSeedLookups(app string, name string) ([]util.RenderLookup, error) {
+	switch app {
+	case util.BlackDuckName:
+		return bdutil.DBPasswordRenderLookups(name), nil
+	default:
+		return nil, util.ValidationError("the chart of %s doesn't generate credentials that can be seeded", app)
+	}
+}
+
+// getSecretSeedsPassphrase returns the passphrase of the seed secrets files
+func getSecretSeedsPassphrase() (string, error) {
+	passphrase := os.Getenv(util.SecretSeedsPassphraseEnv)
+	if len(passphrase) == 0 {
+		return "", util.ValidationError("$%s must be set to the passphrase of the seed secrets file", util.SecretSeedsPassphraseEnv)
+	}
+	return passphrase, nil
+}
+
+// applySeedSecretsFile sets the Helm values of the generated credentials of the instance from the --seed-secrets-file
+func applySeedSecretsFile(app string, name string, helmValuesMap map[string]interface{}) error {
+	if len(seedSecretsFile) == 0 {
+		return nil
+	}
+	lookups, err := getSecretSeedLookups(app, name)
+	if err != nil {
+		return err
+	}
+	passphrase, err := getSecretSeedsPassphrase()
+	if err != nil {
+		return err
+	}
+	data, err := util.ReadFromFile(seedSecretsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the seed secrets file '%s' due to %+v", seedSecretsFile, err)
+	}
+	seeds, err := util.DecryptSecretSeeds(data, passphrase)
+	if err != nil {
+		return err
+	}
+	applied := util.ApplySecretSeeds(seeds, lookups, helmValuesMap)
+	log.Infof("using %d seeded credential(s) of '%s'", len(applied), seedSecretsFile)
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/globals"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// verifyStorageAccess runs a job with the security context of Postgres that writes to a claim of the instance's storage
+// class before the instance is created
+var verifyStorageAccess = false
+
+// verifyStoragePerformance runs a fio benchmark on a claim of the instance's storage class before the instance is
+// created, and warns or fails if it is slower than the Black Duck minimum requirements [warn|fail]
+var verifyStoragePerformance = ""
+
+// Minimum storage performance of the Postgres volume of Black Duck for random 8k reads and writes
+var (
+	blackDuckMinimumPostgresIOPS    = 1000.0
+	blackDuckMaximumPostgresLatency = 10 * time.Millisecond
+)
+
+// storagePerformanceBenchmarkRuntime is how long the fio benchmark runs
+var storagePerformanceBenchmarkRuntime = 30 * time.Second
+
+// storageAccessProbeCommand prints the identity of the pod and the owner of the volume, then writes and removes a file
+const storageAccessProbeCommand = `id; ls -ldn %[1]s; set -e; echo synopsysctl > %[1]s/.synopsysctl-probe; cat %[1]s/.synopsysctl-probe; rm %[1]s/.synopsysctl-probe`
+
+// verifyBlackDuckStorageAccess checks that the Black Duck pods will be able to write to their persistent volumes with the
+// storage class and the security context of Postgres in the Helm values
+func verifyBlackDuckStorageAccess(name string, namespace string, helmValues map[string]interface{}) error {
+	if !verifyStorageAccess || util.ActivePlan != nil {
+		return nil
+	}
+	storageClass, _ := util.GetHelmValueFromMap(helmValues, []string{"storageClass"}).(string)
+	securityContext, err := podSecurityContextFromHelm(util.GetHelmValueFromMap(helmValues, []string{"postgres", "podSecurityContext"}))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("verifying that the pods can write to the volumes of storage class '%s'...", storageClass)
+	logs, err := util.RunStorageProbe(kubeClient, util.StorageProbe{
+		Name:            util.GetResourceName(name, util.BlackDuckName, "storage-probe"),
+		Namespace:       namespace,
+		StorageClass:    storageClass,
+		ClaimSize:       "1Gi",
+		Image:           globals.DefaultBusyBoxImage,
+		Command:         fmt.Sprintf(storageAccessProbeCommand, util.StorageProbeMountPath),
+		SecurityContext: securityContext,
+		Timeout:         5 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("the pods can't write to the volumes of storage class '%s', set --fs-group, --run-as-user or --supplemental-groups to a user or group that can: %w", storageClass, err)
+	}
+	log.Debugf("storage probe output:\n%s", logs)
+	log.Infof("the pods can write to the volumes of storage class '%s'", storageClass)
+	return nil
+}
+
+// checkStoragePerformanceFlag returns an error if --verify-storage-performance isn't valid
+func checkStoragePerformanceFlag() error {
+	if len(verifyStoragePerformance) > 0 && verifyStoragePerformance != "warn" && verifyStoragePerformance != "fail" {
+		return util.ValidationError("--verify-storage-performance must be 'warn' or 'fail', but got '%s'", verifyStoragePerformance)
+	}
+	return nil
+}
+
+// verifyBlackDuckStoragePerformance benchmarks a volume of the storage class in the Helm values and compares it to the
+// minimum requirements of the Black Duck Postgres volume
+func verifyBlackDuckStoragePerformance(name string, namespace string, helmValues map[string]interface{}) error {
+	if len(verifyStoragePerformance) == 0 || util.ActivePlan != nil {
+		return nil
+	}
+	storageClass, _ := util.GetHelmValueFromMap(helmValues, []string{"storageClass"}).(string)
+	securityContext, err := podSecurityContextFromHelm(util.GetHelmValueFromMap(helmValues, []string{"postgres", "podSecurityContext"}))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("benchmarking the volumes of storage class '%s' for %s...", storageClass, storagePerformanceBenchmarkRuntime)
+	logs, err := util.RunStorageProbe(kubeClient, util.StorageProbe{
+		Name:            util.GetResourceName(name, util.BlackDuckName, "storage-benchmark"),
+		Namespace:       namespace,
+		StorageClass:    storageClass,
+		ClaimSize:       "1Gi",
+		Image:           globals.DefaultFioImage,
+		Command:         util.FioCommand(util.StorageProbeMountPath, storagePerformanceBenchmarkRuntime),
+		SecurityContext: securityContext,
+		Timeout:         storagePerformanceBenchmarkRuntime + 5*time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to benchmark the volumes of storage class '%s': %w", storageClass, err)
+	}
+	result, err := util.ParseFioResult(logs)
+	if err != nil {
+		return err
+	}
+	log.Infof("the volumes of storage class '%s' have %s", storageClass, result)
+
+	problems := []string{}
+	if result.IOPS() < blackDuckMinimumPostgresIOPS {
+		problems = append(problems, fmt.Sprintf("%.0f IOPS is below the minimum of %.0f", result.IOPS(), blackDuckMinimumPostgresIOPS))
+	}
+	for _, latency := range []time.Duration{result.ReadLatency, result.WriteLatency} {
+		if latency > blackDuckMaximumPostgresLatency {
+			problems = append(problems, fmt.Sprintf("%s latency is above the maximum of %s", latency, blackDuckMaximumPostgresLatency))
+			break
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	err = fmt.Errorf("the volumes of storage class '%s' are too slow for the Black Duck database: %s", storageClass, strings.Join(problems, ", "))
+	if verifyStoragePerformance == "warn" {
+		log.Warnf("%+v", err)
+		return nil
+	}
+	return util.ValidationError("%+v, use --pvc-storage-class to choose a faster storage class", err)
+}
+
+// podSecurityContextFromHelm converts a pod security context in the Helm values
+func podSecurityContextFromHelm(value interface{}) (*corev1.PodSecurityContext, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod security context due to %+v", err)
+	}
+	securityContext := &corev1.PodSecurityContext{}
+	if err := json.Unmarshal(data, securityContext); err != nil {
+		return nil, fmt.Errorf("invalid pod security context due to %+v", err)
+	}
+	return securityContext, nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// tableSortBy is set by the --sort-by flag of the commands that print tables
+var tableSortBy = ""
+
+// tableNoHeaders is set by the --no-headers flag of the commands that print tables
+var tableNoHeaders = false
+
+// addTableFlags adds the --sort-by and --no-headers flags to a command that prints a table
+func addTableFlags(cmd *cobra.Command, sortUsage string) {
+	cmd.Flags().StringVar(&tableSortBy, "sort-by", tableSortBy, sortUsage)
+	cmd.Flags().BoolVar(&tableNoHeaders, "no-headers", tableNoHeaders, "If true, don't print the headers of the table")
+}
+
+// tableOptions returns the options of the tables of a command, the wide columns are printed with '-o wide'
+func tableOptions(outputFormat string) util.TableOptions {
+	return util.TableOptions{Wide: strings.ToLower(outputFormat) == "wide", SortBy: tableSortBy, NoHeaders: tableNoHeaders}
+}
@@ -67,11 +67,18 @@ func setSynopsysctlLogLevel() error {
 
 // setGlobalKubeConfigPath sets the global variable 'kubeConfigPath' with points to a kubeconfig file for accessing a cluster
 // If the kubeconfig flag was set then kubeConfigPath should already have the path.
-// If the kubeconfig flag was not set then it will check the environ 'KUBECONFIG' for a path
+// If the kubeconfig flag was not set then kubeConfigPath stays empty and the kubeconfigs of the 'KUBECONFIG' environ are
+// merged like kubectl does, ignoring the paths that don't exist
 func setGlobalKubeConfigPath(cmd *cobra.Command) error {
 	if !cmd.Flags().Lookup("kubeconfig").Changed { // if --kubeconfig flag wasn't set, check the environ
-		if kubeconfigEnvVal, exists := os.LookupEnv("KUBECONFIG"); exists { // set kubeconfig if environ is set
-			kubeConfigPath = kubeconfigEnvVal
+		if kubeconfigEnvVal, exists := os.LookupEnv("KUBECONFIG"); exists {
+			missing, err := util.ValidateKubeconfigPaths(kubeconfigEnvVal)
+			if err != nil {
+				return err
+			}
+			for _, path := range missing {
+				log.Debugf("ignoring the kubeconfig path '%s' of the KUBECONFIG environ that does not point to a file", path)
+			}
 		}
 	}
 	// if the kubeConfigPath was set, verify that the file exists
@@ -85,15 +92,8 @@ func setGlobalKubeConfigPath(cmd *cobra.Command) error {
 
 // GetKubeClientFromOutsideCluster returns the rest config of outside cluster
 func GetKubeClientFromOutsideCluster(kubeconfigpath string, insecureSkipTLSVerify bool) (*rest.Config, error) {
-	// Determine Config Paths
-	if home := homeDir(); len(kubeconfigpath) == 0 && home != "" {
-		kubeconfigpath = filepath.Join(home, ".kube", "config")
-	}
-
 	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{
-			ExplicitPath: kubeconfigpath,
-		},
+		util.NewKubeconfigLoadingRules(kubeconfigpath),
 		&clientcmd.ConfigOverrides{
 			ClusterInfo: clientcmdapi.Cluster{
 				Server:                "",
@@ -114,9 +114,26 @@ func homeDir() string {
 	return os.Getenv("USERPROFILE") // windows
 }
 
+// isRunningInCluster returns true if synopsysctl runs in a pod and no kubeconfig is available
+func isRunningInCluster() bool {
+	if len(kubeConfigPath) > 0 || len(os.Getenv("KUBERNETES_SERVICE_HOST")) == 0 || len(os.Getenv("KUBECONFIG")) > 0 {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(homeDir(), ".kube", "config"))
+	return os.IsNotExist(err)
+}
+
 // setGlobalRestConfig sets the global variable 'restconfig' for other commands to use
 func setGlobalRestConfig() error {
 	var err error
+	if isRunningInCluster() {
+		// synopsysctl is running in a pod, e.g. a scheduled task, so use the service account of the pod
+		restconfig, err = rest.InClusterConfig()
+		if err != nil {
+			return fmt.Errorf("unable to get the in-cluster config due to %+v", err)
+		}
+		return nil
+	}
 	restconfig, err = GetKubeClientFromOutsideCluster(kubeConfigPath, insecureSkipTLSVerify)
 	log.Debugf("rest config: %+v", restconfig)
 	if err != nil {
@@ -135,6 +152,67 @@ func setGlobalKubeClient() error {
 	return nil
 }
 
+// useKubeconfig points the global clients at the cluster of the kubeconfig, e.g. the target cluster of a move, and
+// returns a function that points them back at the previous cluster
+func useKubeconfig(path string) (func(), error) {
+	previousPath, previousRestConfig, previousKubeClient := kubeConfigPath, restconfig, kubeClient
+	restore := func() {
+		kubeConfigPath, restconfig, kubeClient = previousPath, previousRestConfig, previousKubeClient
+		setGlobalResourceClients()
+	}
+	config, err := GetKubeClientFromOutsideCluster(path, insecureSkipTLSVerify)
+	if err != nil {
+		return restore, util.WithExitCode(util.ExitCodeClusterUnreachable, fmt.Errorf("unable to load the kubeconfig '%s' due to %+v", path, err))
+	}
+	client, err := getKubeClient(config)
+	if err != nil {
+		return restore, util.WithExitCode(util.ExitCodeClusterUnreachable, fmt.Errorf("unable to connect to the cluster of kubeconfig '%s' due to %+v", path, err))
+	}
+	kubeConfigPath, restconfig, kubeClient = path, config, client
+	if err := setGlobalResourceClients(); err != nil {
+		return restore, util.WithExitCode(util.ExitCodeClusterUnreachable, err)
+	}
+	return restore, nil
+}
+
+// setGlobalClusterInfo loads the capabilities of the cluster of the current kube-context from the cache, or discovers
+// and caches them if the cache is older than clusterInfoCacheTTL or --refresh-cluster-info is set
+func setGlobalClusterInfo() {
+	kubeContext := getCurrentKubeContext()
+	cacheDir := filepath.Join(homeDir(), ".synopsysctl", "cache")
+	if !refreshClusterInfo {
+		if info, ok := util.LoadClusterInfoCache(cacheDir, kubeContext, clusterInfoCacheTTL); ok {
+			log.Debugf("using the cluster info of kube-context '%s' cached at %s", kubeContext, info.DiscoveredAt)
+			util.SetClusterInfo(kubeClient, info)
+			return
+		}
+	}
+	info, err := util.DiscoverClusterInfo(kubeClient)
+	if err != nil {
+		// the commands discover what they need themselves
+		log.Debugf("unable to discover the cluster info due to %+v", err)
+		return
+	}
+	util.SetClusterInfo(kubeClient, info)
+	if err := util.SaveClusterInfoCache(cacheDir, kubeContext, info); err != nil {
+		log.Debugf("%+v", err)
+	}
+}
+
+// getCurrentKubeContext returns the name of the kube-context synopsysctl uses, combined with the API server so that
+// contexts with the same name in different kubeconfigs don't share the cache
+func getCurrentKubeContext() string {
+	if isRunningInCluster() {
+		return "in-cluster"
+	}
+	kubeContext := ""
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(util.NewKubeconfigLoadingRules(kubeConfigPath), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err == nil {
+		kubeContext = rawConfig.CurrentContext
+	}
+	return fmt.Sprintf("%s@%s", kubeContext, restconfig.Host)
+}
+
 // setGlobalResourceClients sets the global variables for the Kuberentes rest config
 // and the resource clients
 func setGlobalResourceClients() error {
@@ -384,8 +462,22 @@ func getInstanceInfo(crdName string, appName string, namespace string, name stri
 // when synopsysctl starts (see file pkg/globals/helmglobalvalues.go)
 func UpdateHelmChartLocation(flags *pflag.FlagSet, chartName, appVersion string, chartVariable *string) error {
 	chartLocationFlag := flags.Lookup("app-resources-path")
+	if util.Offline {
+		// the chart index can't be downloaded to find the chart of the version
+		if err := util.ValidateOfflineChartLocation(chartLocationFlag.Value.String()); err != nil {
+			return err
+		}
+	}
 	if chartLocationFlag.Changed {
 		*chartVariable = chartLocationFlag.Value.String()
+		// an OCI reference without a tag refers to the chart of the version
+		if util.IsOCIChartLocation(*chartVariable) {
+			chartURL, err := util.ResolveOCIChartLocation(*chartVariable, appVersion)
+			if err != nil {
+				return fmt.Errorf("failed to get resources version for '%s': %+v", chartName, err)
+			}
+			*chartVariable = chartURL
+		}
 	} else {
 		if len(appVersion) > 0 {
 			chartURL, err := util.GetLatestChartURLForAppVersion(globals.IndexChartURLs, chartName, appVersion)
@@ -0,0 +1,141 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/products"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// waitForReady and waitTimeout are set by the --wait and --timeout flags of the create and update commands
+var waitForReady = false
+var verifyAfterWait = false
+var waitTimeout = 20 * time.Minute
+
+// waitFailFast and waitCrashLoopRestarts are set by the --fail-fast and --crashloop-restarts flags of the create and update commands
+var waitFailFast = true
+var waitCrashLoopRestarts int32 = 5
+
+// waitPollingPeriod is the period of checking the components of an instance
+var waitPollingPeriod = 5 * time.Second
+
+// addWaitFlags adds the flags of waiting for the instance to be ready to a create or update command
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&waitForReady, "wait", waitForReady, "If true, wait until the deployments and stateful sets of the instance are rolled out and ready")
+	cmd.Flags().DurationVar(&waitTimeout, "timeout", waitTimeout, "Maximum time to wait for the instance with --wait")
+	cmd.Flags().BoolVar(&waitFailFast, "fail-fast", waitFailFast, "If true, stop waiting with --wait as soon as a pod of the instance fails in a way waiting won't resolve, e.g. ImagePullBackOff or an unschedulable pod")
+	cmd.Flags().BoolVar(&verifyAfterWait, "verify", verifyAfterWait, "If true, run the checks of 'synopsysctl verify-install' once the instance is ready with --wait, and fail if one fails")
+	cmd.Flags().Int32Var(&waitCrashLoopRestarts, "crashloop-restarts", waitCrashLoopRestarts, "Number of restarts of a container in CrashLoopBackOff after which --fail-fast stops waiting [0 never stops]")
+}
+
+// waitForInstanceReady waits until all components of the instance run their latest spec and are ready if --wait is set,
+// and runs the post-install verification checks if --verify is set
+func waitForInstanceReady(app string, name string, namespace string) error {
+	if !waitForReady || util.ActivePlan != nil {
+		if verifyAfterWait && util.ActivePlan == nil {
+			log.Warnf("--verify is ignored without --wait")
+		}
+		return nil
+	}
+	product, err := products.New(app, name, namespace, getProductClients())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+	timeout := time.NewTimer(waitTimeout)
+	ticker := time.NewTicker(waitPollingPeriod)
+	defer ticker.Stop()
+	defer timeout.Stop()
+
+	log.Infof("waiting up to %s for %s '%s' in namespace '%s' to be ready...", waitTimeout, app, name, namespace)
+	pending := []string{"no components found"}
+	lastReady := -1
+	for {
+		components, err := product.Components()
+		if err != nil {
+			return err
+		}
+		if len(components) > 0 {
+			pending = products.GetPendingRollouts(components)
+			ready := len(components) - len(pending)
+			util.EmitProgress(util.ProgressPhaseWait, "components-ready", ready*100/len(components), "%d/%d components of %s '%s' are ready in namespace '%s'", ready, len(components), app, name, namespace)
+			if ready != lastReady {
+				log.Infof("%d/%d components are ready", ready, len(components))
+				lastReady = ready
+			}
+			if len(pending) == 0 {
+				return verifyInstanceAfterWait(app, name, namespace)
+			}
+		}
+		if err := checkPodFailures(app, name, namespace, product.LabelSelector()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for %s '%s' in namespace '%s'", app, name, namespace)
+		case <-timeout.C:
+			return util.WithExitCode(util.ExitCodeTimeout, fmt.Errorf("%s '%s' in namespace '%s' isn't ready after %s: %s", app, name, namespace, waitTimeout, strings.Join(pending, ", ")))
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPodFailures returns an error with the diagnosis of the pods of the instance that failed in a way waiting won't resolve if --fail-fast is set
+func checkPodFailures(app string, name string, namespace string, labelSelector string) error {
+	if !waitFailFast {
+		return nil
+	}
+	pods, err := util.ListPodsWithLabels(kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("failed to list the pods of %s '%s' in namespace '%s' due to %+v", app, name, namespace, err)
+	}
+	failures := util.GetPodFailures(pods.Items, waitCrashLoopRestarts)
+	if len(failures) == 0 {
+		return nil
+	}
+	diagnoses := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		diagnoses = append(diagnoses, failure.String())
+	}
+	return fmt.Errorf("%s '%s' in namespace '%s' won't become ready:\n  %s", app, name, namespace, strings.Join(diagnoses, "\n  "))
+}
+
+// verifyInstanceAfterWait runs the post-install verification checks of a ready instance if --verify is set
+func verifyInstanceAfterWait(app string, name string, namespace string) error {
+	if !verifyAfterWait {
+		return nil
+	}
+	log.Infof("verifying %s '%s' in namespace '%s'...", app, name, namespace)
+	report, err := verifyInstall(app, name, namespace)
+	if err != nil {
+		return err
+	}
+	printVerificationReport(report)
+	return verificationReportError(report)
+}
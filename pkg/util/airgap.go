@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Offline is true if synopsysctl must not download the charts, for clusters without internet access. The charts are
+// read from the local archives of --app-resources-path, e.g. downloaded by 'synopsysctl airgap export'
+var Offline = false
+
+// ValidateOfflineChartLocation returns an error if the chart location isn't a local chart archive or directory
+func ValidateOfflineChartLocation(location string) error {
+	if len(location) == 0 {
+		return ValidationError("--offline requires --app-resources-path with the path to a local chart archive, e.g. downloaded by 'synopsysctl airgap export'")
+	}
+	if strings.Contains(location, "://") {
+		return ValidationError("--offline requires a local chart archive, but --app-resources-path is the URL '%s'", location)
+	}
+	if _, err := os.Stat(location); err != nil {
+		return ValidationError("the local chart archive '%s' can't be read due to %+v", location, err)
+	}
+	return nil
+}
+
+// GetManifestImages returns the images of the containers and init containers of the workloads in the manifests, sorted
+// and without duplicates
+func GetManifestImages(manifests string) ([]string, error) {
+	objects, err := SplitManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	images := []string{}
+	for _, object := range objects {
+		podSpec := getWorkloadPodSpec(object)
+		if podSpec == nil {
+			continue
+		}
+		for _, container := range getPodSpecContainers(podSpec) {
+			image, _ := container["image"].(string)
+			if len(image) > 0 && !found[image] {
+				found[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// DownloadChart downloads the chart archive at the URL into the directory and returns the path of the archive
+func DownloadChart(chartURL string, dir string) (string, error) {
+	chartPath := chartURL
+	if IsObjectStorageChartLocation(chartURL) {
+		var err error
+		if chartPath, err = DownloadObjectStorageChart(chartURL); err != nil {
+			return "", err
+		}
+	} else {
+		actionConfig, err := CreateHelmActionConfiguration("", "", "")
+		if err != nil {
+			return "", err
+		}
+		client := action.NewInstall(actionConfig)
+		if chartPath, err = client.ChartPathOptions.LocateChart(chartURL, settings); err != nil {
+			return "", fmt.Errorf("failed to download the chart '%s' due to %+v", chartURL, err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create directory '%s' due to %+v", dir, err)
+	}
+	source, err := os.Open(chartPath)
+	if err != nil {
+		return "", fmt.Errorf("unable to open the chart '%s' due to %+v", chartPath, err)
+	}
+	defer source.Close()
+	archivePath := filepath.Join(dir, filepath.Base(chartPath))
+	archive, err := os.Create(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to create the chart archive '%s' due to %+v", archivePath, err)
+	}
+	defer archive.Close()
+	if _, err := io.Copy(archive, source); err != nil {
+		return "", fmt.Errorf("unable to write the chart archive '%s' due to %+v", archivePath, err)
+	}
+	return archivePath, nil
+}
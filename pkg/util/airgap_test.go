@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetManifestImages(t *testing.T) {
+	assert := assert.New(t)
+
+	manifests := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webapp
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: wait
+        image: docker.io/library/busybox:1.28
+      containers:
+      - name: webapp
+        image: docker.io/blackducksoftware/blackduck-webapp:2020.6.0
+      - name: logstash
+        image: docker.io/blackducksoftware/blackduck-logstash:1.0.5
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: bd-blackduck-init
+spec:
+  template:
+    spec:
+      containers:
+      - name: init
+        image: docker.io/library/busybox:1.28
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webapp
+`
+	images, err := GetManifestImages(manifests)
+	assert.NoError(err)
+	assert.Equal([]string{
+		"docker.io/blackducksoftware/blackduck-logstash:1.0.5",
+		"docker.io/blackducksoftware/blackduck-webapp:2020.6.0",
+		"docker.io/library/busybox:1.28",
+	}, images)
+}
+
+func TestValidateOfflineChartLocation(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "airgap")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "blackduck-2020.6.0.tgz")
+	assert.NoError(ioutil.WriteFile(archive, []byte{}, 0644))
+
+	assert.NoError(ValidateOfflineChartLocation(archive))
+	assert.Error(ValidateOfflineChartLocation(""))
+	assert.Error(ValidateOfflineChartLocation("https://sig-repo.synopsys.com/sig-cloudnative/blackduck-2020.6.0.tgz"))
+	assert.Error(ValidateOfflineChartLocation("s3://bucket/blackduck-2020.6.0.tgz"))
+	assert.Error(ValidateOfflineChartLocation(filepath.Join(dir, "missing.tgz")))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+)
+
+// servedKindsFunc returns the kinds served by the cluster for an API version
+type servedKindsFunc func(apiVersion string) (map[string]bool, error)
+
+// CheckAPIVersions returns an error that lists the rendered objects whose API version and kind are not served by the
+// cluster, so that an install or upgrade fails before Helm applies a part of the objects. The objects whose API version
+// is deprecated in the Kubernetes version of the cluster are logged as warnings
+func CheckAPIVersions(manifest string, discoveryClient discovery.DiscoveryInterface, kubeVersion string) error {
+	return checkAPIVersions(manifest, func(apiVersion string) (map[string]bool, error) {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(apiVersion)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return map[string]bool{}, nil
+			}
+			return nil, err
+		}
+		kinds := map[string]bool{}
+		for _, resource := range resources.APIResources {
+			kinds[resource.Kind] = true
+		}
+		return kinds, nil
+	}, kubeVersion)
+}
+
+func checkAPIVersions(manifest string, servedKinds servedKindsFunc, kubeVersion string) error {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return err
+	}
+	minorVersion, err := parseKubeMinorVersion(kubeVersion)
+	if err != nil {
+		minorVersion = lintUnknownKubeMinorVersion
+	}
+
+	served := map[string]map[string]bool{}
+	unserved := []string{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		apiVersion := u.GetAPIVersion()
+		kinds, ok := served[apiVersion]
+		if !ok {
+			if kinds, err = servedKinds(apiVersion); err != nil {
+				return fmt.Errorf("unable to get the resources of API version '%s' from the cluster due to %+v", apiVersion, err)
+			}
+			served[apiVersion] = kinds
+		}
+		if !kinds[u.GetKind()] {
+			problem := fmt.Sprintf("%s '%s' uses %s which is not served by the cluster", u.GetKind(), u.GetName(), apiVersion)
+			if deprecated, ok := deprecatedAPIVersions[fmt.Sprintf("%s/%s", apiVersion, u.GetKind())]; ok {
+				problem = fmt.Sprintf("%s (removed in Kubernetes 1.%d, use %s)", problem, deprecated.removedIn, deprecated.replacement)
+			}
+			unserved = append(unserved, problem)
+			continue
+		}
+		for _, finding := range lintAPIVersion(u, minorVersion) {
+			log.Warnf("%s", finding)
+		}
+	}
+	if len(unserved) > 0 {
+		sort.Strings(unserved)
+		return ValidationError("the resources use API versions that are not available in the cluster, use a version that supports the cluster:\n  - %s", strings.Join(unserved, "\n  - "))
+	}
+	return nil
+}
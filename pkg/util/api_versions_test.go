@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAPIVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	servedKinds := func(apiVersion string) (map[string]bool, error) {
+		switch apiVersion {
+		case "apps/v1":
+			return map[string]bool{"Deployment": true}, nil
+		case "networking.k8s.io/v1beta1":
+			return map[string]bool{"Ingress": true}, nil
+		}
+		return map[string]bool{}, nil
+	}
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webapp
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: webserver
+`
+	assert.NoError(checkAPIVersions(manifest, servedKinds, "v1.19.2"))
+
+	manifest += `---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: legacy
+`
+	err := checkAPIVersions(manifest, servedKinds, "v1.19.2")
+	assert.Error(err)
+	assert.Contains(err.Error(), "Deployment 'legacy' uses extensions/v1beta1 which is not served by the cluster (removed in Kubernetes 1.16, use apps/v1)")
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+
+	failing := func(apiVersion string) (map[string]bool, error) { return nil, fmt.Errorf("forbidden") }
+	assert.Error(checkAPIVersions(manifest, failing, ""))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Kinds of the destinations of a backup
+const (
+	BackupDestinationDirectory = "dir"
+	BackupDestinationPVC       = "pvc"
+	BackupDestinationS3        = "s3"
+)
+
+// backupDestinationPVCPrefix is the prefix of a persistent volume claim destination, e.g. pvc:blackduck-backups
+const backupDestinationPVCPrefix = "pvc:"
+
+// BackupDestination is where the dumps of a backup are written to
+type BackupDestination struct {
+	Kind string
+	// Location is the local directory, the name of the persistent volume claim or the S3 bucket
+	Location string
+	// Prefix is the key prefix of the dumps in the S3 bucket, or the directory of a backup in the claim
+	Prefix string
+}
+
+// ParseBackupDestination parses a local directory, pvc:CLAIM or s3://BUCKET[/PREFIX]
+func ParseBackupDestination(destination string) (BackupDestination, error) {
+	switch {
+	case len(strings.TrimSpace(destination)) == 0:
+		return BackupDestination{}, ValidationError("the backup destination must be a directory, pvc:CLAIM or s3://BUCKET[/PREFIX]")
+	case strings.HasPrefix(destination, backupDestinationPVCPrefix):
+		claim := strings.TrimPrefix(destination, backupDestinationPVCPrefix)
+		if errs := validation.IsDNS1123Subdomain(claim); len(errs) > 0 {
+			return BackupDestination{}, ValidationError("invalid persistent volume claim '%s' of the backup destination: %s", claim, strings.Join(errs, ", "))
+		}
+		return BackupDestination{Kind: BackupDestinationPVC, Location: claim}, nil
+	case strings.HasPrefix(destination, ChartLocationS3Scheme+"://"):
+		u, err := url.Parse(destination)
+		if err != nil || len(u.Host) == 0 {
+			return BackupDestination{}, ValidationError("invalid backup destination '%s', expected s3://BUCKET[/PREFIX]", destination)
+		}
+		return BackupDestination{Kind: BackupDestinationS3, Location: u.Host, Prefix: strings.Trim(u.Path, "/")}, nil
+	case strings.Contains(destination, "://"):
+		return BackupDestination{}, ValidationError("unsupported backup destination '%s', expected a directory, pvc:CLAIM or s3://BUCKET[/PREFIX]", destination)
+	}
+	return BackupDestination{Kind: BackupDestinationDirectory, Location: destination}, nil
+}
+
+// ParseBackupSource parses the location of a backup written by a backup command, a local directory of the dumps,
+// pvc:CLAIM/DIRECTORY or s3://BUCKET/PREFIX. The directory and the prefix are those of the backup, e.g.
+// blackduck-bd-20200601T123000Z
+func ParseBackupSource(source string) (BackupDestination, error) {
+	if strings.HasPrefix(source, backupDestinationPVCPrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(source, backupDestinationPVCPrefix), "/", 2)
+		if len(parts) != 2 || len(strings.Trim(parts[1], "/")) == 0 {
+			return BackupDestination{}, ValidationError("the backup source '%s' must have the directory of the backup, pvc:CLAIM/DIRECTORY", source)
+		}
+		if errs := validation.IsDNS1123Subdomain(parts[0]); len(errs) > 0 {
+			return BackupDestination{}, ValidationError("invalid persistent volume claim '%s' of the backup source: %s", parts[0], strings.Join(errs, ", "))
+		}
+		return BackupDestination{Kind: BackupDestinationPVC, Location: parts[0], Prefix: strings.Trim(parts[1], "/")}, nil
+	}
+	backup, err := ParseBackupDestination(source)
+	if err != nil {
+		return backup, err
+	}
+	if backup.Kind == BackupDestinationS3 && len(backup.Prefix) == 0 {
+		return BackupDestination{}, ValidationError("the backup source '%s' must have the prefix of the backup, s3://BUCKET/PREFIX", source)
+	}
+	return backup, nil
+}
+
+// String returns the destination as it is given on the command line
+func (d BackupDestination) String() string {
+	switch d.Kind {
+	case BackupDestinationPVC:
+		if len(d.Prefix) > 0 {
+			return fmt.Sprintf("%s%s/%s", backupDestinationPVCPrefix, d.Location, d.Prefix)
+		}
+		return backupDestinationPVCPrefix + d.Location
+	case BackupDestinationS3:
+		if len(d.Prefix) > 0 {
+			return fmt.Sprintf("%s://%s/%s", ChartLocationS3Scheme, d.Location, d.Prefix)
+		}
+		return fmt.Sprintf("%s://%s", ChartLocationS3Scheme, d.Location)
+	}
+	return d.Location
+}
+
+// GetBackupName returns the name of the directory or the key prefix of the dumps of a backup of an instance
+func GetBackupName(app string, name string, now time.Time) string {
+	return fmt.Sprintf("%s-%s-%s", app, name, now.UTC().Format("20060102T150405Z"))
+}
+
+// GetBackupNamePattern returns the glob pattern matching the names of the backups of an instance, but not those of
+// another instance whose name starts with the name of the instance
+func GetBackupNamePattern(app string, name string) string {
+	return fmt.Sprintf("%s-%s-[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]T[0-9][0-9][0-9][0-9][0-9][0-9]Z", app, name)
+}
+
+// GetExpiredBackups returns the backups of the instance among the names that are older than the newest retention
+// backups. The names of other files and of the backups of other instances are ignored
+func GetExpiredBackups(names []string, app string, name string, retention int) []string {
+	pattern := GetBackupNamePattern(app, name)
+	backups := []string{}
+	for _, n := range names {
+		if matched, _ := filepath.Match(pattern, n); matched {
+			backups = append(backups, n)
+		}
+	}
+	if retention <= 0 || len(backups) <= retention {
+		return []string{}
+	}
+	// the timestamps of the names sort chronologically
+	sort.Strings(backups)
+	return backups[:len(backups)-retention]
+}
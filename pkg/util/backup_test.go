@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBackupDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	destination, err := ParseBackupDestination("/var/backups")
+	assert.NoError(err)
+	assert.Equal(BackupDestination{Kind: BackupDestinationDirectory, Location: "/var/backups"}, destination)
+
+	destination, err = ParseBackupDestination("pvc:blackduck-backups")
+	assert.NoError(err)
+	assert.Equal(BackupDestination{Kind: BackupDestinationPVC, Location: "blackduck-backups"}, destination)
+	assert.Equal("pvc:blackduck-backups", destination.String())
+
+	destination, err = ParseBackupDestination("s3://backups/blackduck/prod/")
+	assert.NoError(err)
+	assert.Equal(BackupDestination{Kind: BackupDestinationS3, Location: "backups", Prefix: "blackduck/prod"}, destination)
+	assert.Equal("s3://backups/blackduck/prod", destination.String())
+
+	for _, invalid := range []string{"", "pvc:", "pvc:Invalid_Claim", "s3://", "gs://backups"} {
+		_, err := ParseBackupDestination(invalid)
+		assert.Error(err, invalid)
+	}
+}
+
+func TestParseBackupSource(t *testing.T) {
+	assert := assert.New(t)
+
+	source, err := ParseBackupSource("pvc:blackduck-backups/blackduck-bd-20200601T123000Z")
+	assert.NoError(err)
+	assert.Equal(BackupDestination{Kind: BackupDestinationPVC, Location: "blackduck-backups", Prefix: "blackduck-bd-20200601T123000Z"}, source)
+	assert.Equal("pvc:blackduck-backups/blackduck-bd-20200601T123000Z", source.String())
+
+	source, err = ParseBackupSource("s3://backups/prod/blackduck-bd-20200601T123000Z")
+	assert.NoError(err)
+	assert.Equal(BackupDestination{Kind: BackupDestinationS3, Location: "backups", Prefix: "prod/blackduck-bd-20200601T123000Z"}, source)
+
+	source, err = ParseBackupSource("./backups/blackduck-bd-20200601T123000Z")
+	assert.NoError(err)
+	assert.Equal(BackupDestinationDirectory, source.Kind)
+
+	for _, invalid := range []string{"", "pvc:blackduck-backups", "pvc:blackduck-backups/", "pvc:Invalid/backup", "s3://backups"} {
+		_, err := ParseBackupSource(invalid)
+		assert.Error(err, invalid)
+	}
+}
+
+func TestGetBackupName(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2020, time.June, 1, 12, 30, 0, 0, time.UTC)
+	assert.Equal("blackduck-bd-20200601T123000Z", GetBackupName("blackduck", "bd", now))
+}
+
+func TestGetExpiredBackups(t *testing.T) {
+	assert := assert.New(t)
+	names := []string{
+		"blackduck-bd-20200603T000000Z",
+		"blackduck-bd-20200601T000000Z",
+		"blackduck-bd-2-20200601T000000Z",
+		"blackduck-bd-20200602T000000Z",
+		"notes.txt",
+	}
+	assert.Equal([]string{"blackduck-bd-20200601T000000Z"}, GetExpiredBackups(names, "blackduck", "bd", 2))
+	assert.Equal([]string{"blackduck-bd-20200601T000000Z", "blackduck-bd-20200602T000000Z"}, GetExpiredBackups(names, "blackduck", "bd", 1))
+	assert.Empty(GetExpiredBackups(names, "blackduck", "bd", 3))
+	assert.Empty(GetExpiredBackups(names, "blackduck", "bd", 0))
+	assert.Empty(GetExpiredBackups(names, "blackduck", "bd-2", 1))
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// bundleRedacted replaces the secret values in a diagnostics bundle
+const bundleRedacted = "<redacted>"
+
+// secretKeyRegexp matches the keys whose values are secrets, e.g. POSTGRES_PASSWORD or sealKey
+var secretKeyRegexp = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|passphrase|private[_-]?key|api[_-]?key|access[_-]?key|seal[_-]?key)`)
+
+// secretLineRegexp matches the lines of a configuration file that assign a value to a key, e.g. KEY=value or key: value
+var secretLineRegexp = regexp.MustCompile(`^(\s*(?:export\s+)?["']?([A-Za-z0-9_.-]+)["']?\s*[=:]\s*)(\S.*)$`)
+
+// IsSecretKey returns true if the key names a secret value
+func IsSecretKey(key string) bool {
+	return secretKeyRegexp.MatchString(key)
+}
+
+// RedactConfigMapData returns a copy of the data of a config map without the values of the secret keys, and without the
+// values of the secret keys of the configuration files it contains
+func RedactConfigMapData(data map[string]string) map[string]string {
+	redacted := make(map[string]string, len(data))
+	for key, value := range data {
+		if IsSecretKey(key) {
+			redacted[key] = bundleRedacted
+			continue
+		}
+		lines := strings.Split(value, "\n")
+		for i, line := range lines {
+			if match := secretLineRegexp.FindStringSubmatch(line); match != nil && IsSecretKey(match[2]) {
+				lines[i] = match[1] + bundleRedacted
+			}
+		}
+		redacted[key] = strings.Join(lines, "\n")
+	}
+	return redacted
+}
+
+// RedactHelmValues returns a copy of the Helm values without the values of the secret keys
+func RedactHelmValues(values map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			redacted[key] = RedactHelmValues(v)
+		default:
+			if IsSecretKey(key) && value != nil && value != "" {
+				redacted[key] = bundleRedacted
+			} else {
+				redacted[key] = value
+			}
+		}
+	}
+	return redacted
+}
+
+// RedactManifest returns the manifest without the data of the Secrets and the secret values of the ConfigMaps
+func RedactManifest(manifest string) (string, error) {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return "", err
+	}
+	documents := []string{}
+	for _, object := range objects {
+		switch object["kind"] {
+		case "Secret":
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := object[field].(map[string]interface{}); ok {
+					for key := range data {
+						data[key] = bundleRedacted
+					}
+				}
+			}
+		case "ConfigMap":
+			if data, ok := object["data"].(map[string]interface{}); ok {
+				stringData := map[string]string{}
+				for key, value := range data {
+					stringData[key] = fmt.Sprintf("%v", value)
+				}
+				for key, value := range RedactConfigMapData(stringData) {
+					data[key] = value
+				}
+			}
+		}
+		document, err := yaml.Marshal(object)
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal the redacted manifest due to %+v", err)
+		}
+		documents = append(documents, string(document))
+	}
+	return strings.Join(documents, "---\n"), nil
+}
+
+// BundleWriter writes the files of a diagnostics bundle into a gzipped tar archive, under a root directory
+type BundleWriter struct {
+	root    string
+	gzip    *gzip.Writer
+	archive *tar.Writer
+	now     time.Time
+}
+
+// NewBundleWriter returns a writer of a bundle whose files are in the root directory of the archive
+func NewBundleWriter(w io.Writer, root string) *BundleWriter {
+	gzipWriter := gzip.NewWriter(w)
+	return &BundleWriter{root: root, gzip: gzipWriter, archive: tar.NewWriter(gzipWriter), now: time.Now()}
+}
+
+// AddFile adds a file to the bundle, the name is relative to the root directory
+func (b *BundleWriter) AddFile(name string, data []byte) error {
+	if err := b.archive.WriteHeader(&tar.Header{Name: path.Join(b.root, name), Mode: 0644, Size: int64(len(data)), ModTime: b.now, Typeflag: tar.TypeReg}); err != nil {
+		return fmt.Errorf("unable to add '%s' to the bundle due to %+v", name, err)
+	}
+	if _, err := b.archive.Write(data); err != nil {
+		return fmt.Errorf("unable to add '%s' to the bundle due to %+v", name, err)
+	}
+	return nil
+}
+
+// Close writes the end of the archive
+func (b *BundleWriter) Close() error {
+	if err := b.archive.Close(); err != nil {
+		return err
+	}
+	return b.gzip.Close()
+}
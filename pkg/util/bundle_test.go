@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	assert := assert.New(t)
+	for _, key := range []string{"POSTGRES_PASSWORD", "adminPassword", "sealKey", "SEAL_KEY", "apiToken", "aws_secret_access_key", "privateKey"} {
+		assert.True(IsSecretKey(key), key)
+	}
+	for _, key := range []string{"HUB_POSTGRES_USER", "PUBLIC_HUB_WEBSERVER_HOST", "key", "size"} {
+		assert.False(IsSecretKey(key), key)
+	}
+}
+
+func TestRedactConfigMapData(t *testing.T) {
+	assert := assert.New(t)
+	data := map[string]string{
+		"HUB_POSTGRES_USER":      "blackduck_user",
+		"HUB_POSTGRES_PASSWORD":  "secret",
+		"application.properties": "server.port=8443\nspring.datasource.password=secret\n  export API_TOKEN = abc",
+		"config.yaml":            "host: example.com\nadminPassword: \"secret\"",
+	}
+	redacted := RedactConfigMapData(data)
+	assert.Equal(map[string]string{
+		"HUB_POSTGRES_USER":      "blackduck_user",
+		"HUB_POSTGRES_PASSWORD":  "<redacted>",
+		"application.properties": "server.port=8443\nspring.datasource.password=<redacted>\n  export API_TOKEN = <redacted>",
+		"config.yaml":            "host: example.com\nadminPassword: <redacted>",
+	}, redacted)
+	assert.Equal("secret", data["HUB_POSTGRES_PASSWORD"])
+}
+
+func TestRedactHelmValues(t *testing.T) {
+	assert := assert.New(t)
+	values := map[string]interface{}{
+		"size": "small",
+		"postgres": map[string]interface{}{
+			"host":          "postgres",
+			"adminPassword": "secret",
+			"userPassword":  "",
+		},
+		"sealKey": "abc",
+	}
+	assert.Equal(map[string]interface{}{
+		"size": "small",
+		"postgres": map[string]interface{}{
+			"host":          "postgres",
+			"adminPassword": "<redacted>",
+			"userPassword":  "",
+		},
+		"sealKey": "<redacted>",
+	}, RedactHelmValues(values))
+	assert.Equal("secret", values["postgres"].(map[string]interface{})["adminPassword"])
+}
+
+func TestRedactManifest(t *testing.T) {
+	assert := assert.New(t)
+	manifest := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-creds
+data:
+  HUB_POSTGRES_ADMIN_PASSWORD_FILE: c2VjcmV0
+stringData:
+  other: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  HUB_POSTGRES_USER: blackduck_user
+  HUB_POSTGRES_PASSWORD: secret
+`
+	redacted, err := RedactManifest(manifest)
+	assert.NoError(err)
+	assert.NotContains(redacted, "c2VjcmV0")
+	assert.NotContains(redacted, "value")
+	assert.NotContains(redacted, ": secret")
+	assert.Contains(redacted, "blackduck_user")
+	assert.Contains(redacted, "---\n")
+}
+
+func TestBundleWriter(t *testing.T) {
+	assert := assert.New(t)
+	buffer := &bytes.Buffer{}
+	bundle := NewBundleWriter(buffer, "bundle")
+	assert.NoError(bundle.AddFile("version.txt", []byte("1.0.0")))
+	assert.NoError(bundle.AddFile("logs/pod.log", []byte("started")))
+	assert.NoError(bundle.Close())
+
+	gzipReader, err := gzip.NewReader(buffer)
+	assert.NoError(err)
+	archive := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		data, _ := ioutil.ReadAll(archive)
+		files[header.Name] = string(data)
+	}
+	assert.Equal(map[string]string{"bundle/version.txt": "1.0.0", "bundle/logs/pod.log": "started"}, files)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats of the certificate expiry notifications sent to a webhook
+const (
+	// CertificateWebhookFormatJSON posts the CertificateExpiryNotification as JSON
+	CertificateWebhookFormatJSON = "json"
+	// CertificateWebhookFormatSlack posts a message with a "text" field, which Slack, Mattermost and Teams incoming webhooks accept
+	CertificateWebhookFormatSlack = "slack"
+)
+
+// CertificateWebhookTimeout is the timeout of a request to the certificate expiry webhook
+var CertificateWebhookTimeout = 30 * time.Second
+
+// CertificateExpiry is the earliest expiry of the certificates stored in a secret of an instance
+type CertificateExpiry struct {
+	App       string    `json:"app"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Secret    string    `json:"secret"`
+	Expiry    time.Time `json:"expiry"`
+	Expired   bool      `json:"expired"`
+}
+
+// CertificateExpiryNotification is the payload of the JSON webhook format
+type CertificateExpiryNotification struct {
+	Source       string              `json:"source"`
+	GeneratedAt  time.Time           `json:"generatedAt"`
+	Within       string              `json:"within"`
+	Certificates []CertificateExpiry `json:"certificates"`
+}
+
+// ValidateCertificateWebhookFormat returns an error if the format isn't a supported webhook format
+func ValidateCertificateWebhookFormat(format string) error {
+	switch format {
+	case CertificateWebhookFormatJSON, CertificateWebhookFormatSlack:
+		return nil
+	}
+	return ValidationError("webhook format must be '%s' or '%s', got '%s'", CertificateWebhookFormatJSON, CertificateWebhookFormatSlack, format)
+}
+
+// GetCertificateExpiry returns the earliest expiry of the certificates in the data of a secret if it is before now + within.
+// The boolean is false if the secret has no certificate or its certificates expire later
+func GetCertificateExpiry(app string, name string, namespace string, secretName string, data map[string][]byte, within time.Duration, now time.Time) (CertificateExpiry, bool) {
+	expiry, found := GetEarliestCertificateExpiry(data)
+	if !found || !expiry.Before(now.Add(within)) {
+		return CertificateExpiry{}, false
+	}
+	return CertificateExpiry{
+		App:       app,
+		Name:      name,
+		Namespace: namespace,
+		Secret:    secretName,
+		Expiry:    expiry,
+		Expired:   !expiry.After(now),
+	}, true
+}
+
+// SortCertificateExpiries sorts the expiries by date, the earliest first
+func SortCertificateExpiries(expiries []CertificateExpiry) {
+	sort.SliceStable(expiries, func(i, j int) bool {
+		return expiries[i].Expiry.Before(expiries[j].Expiry)
+	})
+}
+
+// NewCertificateExpiryWebhookPayload returns the body of the webhook request in the given format
+func NewCertificateExpiryWebhookPayload(format string, expiries []CertificateExpiry, within time.Duration, now time.Time) ([]byte, error) {
+	if err := ValidateCertificateWebhookFormat(format); err != nil {
+		return nil, err
+	}
+	if format == CertificateWebhookFormatSlack {
+		lines := []string{fmt.Sprintf("synopsysctl found %d certificate(s) that expire within %s:", len(expiries), within)}
+		for _, expiry := range expiries {
+			state := "expires"
+			if expiry.Expired {
+				state = "expired"
+			}
+			lines = append(lines, fmt.Sprintf("• %s '%s' in namespace '%s': secret '%s' %s at %s", expiry.App, expiry.Name, expiry.Namespace, expiry.Secret, state, expiry.Expiry.UTC().Format(time.RFC3339)))
+		}
+		return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+	}
+	return json.Marshal(CertificateExpiryNotification{
+		Source:       "synopsysctl",
+		GeneratedAt:  now.UTC(),
+		Within:       within.String(),
+		Certificates: expiries,
+	})
+}
+
+// NotifyCertificateExpiry posts the expiries to a webhook in the given format
+func NotifyCertificateExpiry(webhookURL string, format string, expiries []CertificateExpiry, within time.Duration) error {
+	payload, err := NewCertificateExpiryWebhookPayload(format, expiries, within, time.Now())
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Timeout: CertificateWebhookTimeout}).Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to send the certificate expiry notification due to %+v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook rejected the certificate expiry notification with status '%s'", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCertificateExpiry(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	soonCert, _ := generateTestCertificateAndKey(t, nil, now.Add(-time.Hour), now.Add(24*time.Hour))
+	laterCert, _ := generateTestCertificateAndKey(t, nil, now.Add(-time.Hour), now.Add(90*24*time.Hour))
+	expiredCert, _ := generateTestCertificateAndKey(t, nil, now.Add(-48*time.Hour), now.Add(-time.Hour))
+
+	expiry, found := GetCertificateExpiry("blackduck", "bd", "ns", "bd-webserver-certificate", map[string][]byte{"tls.crt": soonCert}, 30*24*time.Hour, now)
+	assert.True(found)
+	assert.Equal(CertificateExpiry{App: "blackduck", Name: "bd", Namespace: "ns", Secret: "bd-webserver-certificate", Expiry: now.Add(24 * time.Hour)}, expiry)
+
+	expiry, found = GetCertificateExpiry("alert", "al", "ns", "al-certificate", map[string][]byte{"tls.crt": expiredCert}, 30*24*time.Hour, now)
+	assert.True(found)
+	assert.True(expiry.Expired)
+
+	_, found = GetCertificateExpiry("blackduck", "bd", "ns", "bd-webserver-certificate", map[string][]byte{"tls.crt": laterCert}, 30*24*time.Hour, now)
+	assert.False(found)
+
+	_, found = GetCertificateExpiry("blackduck", "bd", "ns", "bd-db-creds", map[string][]byte{"password": []byte("secret")}, 30*24*time.Hour, now)
+	assert.False(found)
+}
+
+func TestSortCertificateExpiries(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	expiries := []CertificateExpiry{
+		{Secret: "later", Expiry: now.Add(2 * time.Hour)},
+		{Secret: "expired", Expiry: now.Add(-time.Hour)},
+		{Secret: "soon", Expiry: now.Add(time.Hour)},
+	}
+	SortCertificateExpiries(expiries)
+	assert.Equal([]string{"expired", "soon", "later"}, []string{expiries[0].Secret, expiries[1].Secret, expiries[2].Secret})
+}
+
+func TestNewCertificateExpiryWebhookPayload(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	expiries := []CertificateExpiry{
+		{App: "blackduck", Name: "bd", Namespace: "ns", Secret: "bd-webserver-certificate", Expiry: now.Add(-time.Hour), Expired: true},
+		{App: "alert", Name: "al", Namespace: "ns", Secret: "al-certificate", Expiry: now.Add(24 * time.Hour)},
+	}
+
+	payload, err := NewCertificateExpiryWebhookPayload(CertificateWebhookFormatJSON, expiries, 720*time.Hour, now)
+	assert.NoError(err)
+	notification := CertificateExpiryNotification{}
+	assert.NoError(json.Unmarshal(payload, &notification))
+	assert.Equal("synopsysctl", notification.Source)
+	assert.Equal("720h0m0s", notification.Within)
+	assert.Equal(expiries, notification.Certificates)
+
+	payload, err = NewCertificateExpiryWebhookPayload(CertificateWebhookFormatSlack, expiries, 720*time.Hour, now)
+	assert.NoError(err)
+	message := map[string]string{}
+	assert.NoError(json.Unmarshal(payload, &message))
+	lines := strings.Split(message["text"], "\n")
+	assert.Len(lines, 3)
+	assert.Contains(lines[1], "secret 'bd-webserver-certificate' expired at 2020-05-01T11:00:00Z")
+	assert.Contains(lines[2], "secret 'al-certificate' expires at 2020-05-02T12:00:00Z")
+
+	_, err = NewCertificateExpiryWebhookPayload("xml", expiries, 720*time.Hour, now)
+	assert.Error(err)
+}
+
+func TestNotifyCertificateExpiry(t *testing.T) {
+	assert := assert.New(t)
+	var received CertificateExpiryNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+	}))
+	defer server.Close()
+
+	expiries := []CertificateExpiry{{App: "blackduck", Name: "bd", Namespace: "ns", Secret: "bd-webserver-certificate", Expiry: time.Now().UTC().Truncate(time.Second)}}
+	assert.NoError(NotifyCertificateExpiry(server.URL, CertificateWebhookFormatJSON, expiries, time.Hour))
+	assert.Equal(expiries, received.Certificates)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	assert.Error(NotifyCertificateExpiry(failing.URL, CertificateWebhookFormatJSON, expiries, time.Hour))
+}
@@ -0,0 +1,295 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Keys of the certificate and its key in the webserver certificate secrets of the charts and in the secrets of cert-manager
+const (
+	WebserverCertificateKey    = "WEBSERVER_CUSTOM_CERT_FILE"
+	WebserverCertificateKeyKey = "WEBSERVER_CUSTOM_KEY_FILE"
+	CertManagerCertificateKey  = "tls.crt"
+	CertManagerKeyKey          = "tls.key"
+)
+
+// Kinds of the cert-manager issuers
+const (
+	CertManagerIssuer        = "Issuer"
+	CertManagerClusterIssuer = "ClusterIssuer"
+)
+
+// certManagerCertificateResource is the resource of the cert-manager certificates
+var certManagerCertificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1alpha2", Resource: "certificates"}
+
+// certificateIssuerHelmPath is the path of the cert-manager issuer in the Helm values. The values are not used by the
+// charts, the keys of the secret issued by cert-manager are mapped to the keys the charts read by post-rendering
+var certificateIssuerHelmPath = []string{"synopsysctl", "certificateIssuer"}
+
+// CertificateIssuer is the cert-manager issuer of the webserver certificate of an instance and the secret it issues
+// the certificate into. The name of the secret doesn't change when the certificate is renewed, so the pods read the
+// renewed certificate from the same secret
+type CertificateIssuer struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	SecretName string `json:"secretName"`
+}
+
+// ParseCertificateIssuer parses an issuer in the format [Issuer/|ClusterIssuer/]NAME, an Issuer by default
+func ParseCertificateIssuer(value string) (*CertificateIssuer, error) {
+	issuer := &CertificateIssuer{Name: value, Kind: CertManagerIssuer}
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		switch strings.ToLower(parts[0]) {
+		case strings.ToLower(CertManagerIssuer):
+			issuer.Kind = CertManagerIssuer
+		case strings.ToLower(CertManagerClusterIssuer):
+			issuer.Kind = CertManagerClusterIssuer
+		default:
+			return nil, ValidationError("invalid certificate issuer '%s', the format is [Issuer/|ClusterIssuer/]NAME", value)
+		}
+		issuer.Name = parts[1]
+	}
+	if len(issuer.Name) == 0 || strings.Contains(issuer.Name, "/") {
+		return nil, ValidationError("invalid certificate issuer '%s', the format is [Issuer/|ClusterIssuer/]NAME", value)
+	}
+	return issuer, nil
+}
+
+// GetCertificateIssuerSecretName returns the name of the secret of the webserver certificate issued by cert-manager.
+// It differs from the secret of a certificate file, so that cert-manager never writes into a secret synopsysctl created
+func GetCertificateIssuerSecretName(name string, appName string) string {
+	return GetResourceName(name, appName, "webserver-tls")
+}
+
+// SetCertificateIssuerInHelmValues stores the issuer in the Helm values, or removes it if the issuer is nil
+func SetCertificateIssuerInHelmValues(helmValues map[string]interface{}, issuer *CertificateIssuer) {
+	if issuer == nil {
+		deleteSynopsysctlHelmValue(helmValues, certificateIssuerHelmPath[1])
+		return
+	}
+	SetHelmValueInMap(helmValues, certificateIssuerHelmPath, map[string]interface{}{
+		"name":       issuer.Name,
+		"kind":       issuer.Kind,
+		"secretName": issuer.SecretName,
+	})
+}
+
+// GetCertificateIssuerFromHelmValues returns the issuer stored in the Helm values, or nil
+func GetCertificateIssuerFromHelmValues(helmValues map[string]interface{}) *CertificateIssuer {
+	values, ok := GetHelmValueFromMap(helmValues, certificateIssuerHelmPath).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	issuer := &CertificateIssuer{}
+	issuer.Name, _ = values["name"].(string)
+	issuer.Kind, _ = values["kind"].(string)
+	issuer.SecretName, _ = values["secretName"].(string)
+	if len(issuer.Name) == 0 || len(issuer.SecretName) == 0 {
+		return nil
+	}
+	return issuer
+}
+
+// NewCertManagerCertificate returns the cert-manager certificate of the hosts, issued into the secret of the issuer
+func NewCertManagerCertificate(namespace string, labels map[string]string, issuer CertificateIssuer, hosts []string) *unstructured.Unstructured {
+	dnsNames := []interface{}{}
+	for _, host := range hosts {
+		dnsNames = append(dnsNames, host)
+	}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": certManagerCertificateResource.GroupVersion().String(),
+		"kind":       "Certificate",
+		"spec": map[string]interface{}{
+			"secretName": issuer.SecretName,
+			"commonName": hosts[0],
+			"dnsNames":   dnsNames,
+			"issuerRef": map[string]interface{}{
+				"name":  issuer.Name,
+				"kind":  issuer.Kind,
+				"group": certManagerCertificateResource.Group,
+			},
+		},
+	}}
+	certificate.SetName(issuer.SecretName)
+	certificate.SetNamespace(namespace)
+	certificate.SetLabels(labels)
+	return certificate
+}
+
+// ApplyCertManagerCertificate creates the certificate or updates the spec of the existing one
+func ApplyCertManagerCertificate(restConfig *rest.Config, certificate *unstructured.Unstructured) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	client := dynamicClient.Resource(certManagerCertificateResource).Namespace(certificate.GetNamespace())
+	current, err := client.Get(certificate.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		if _, err := client.Create(certificate, metav1.CreateOptions{}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to create certificate '%s' in namespace '%s', cert-manager isn't installed in the cluster", certificate.GetName(), certificate.GetNamespace())
+			}
+			return fmt.Errorf("unable to create certificate '%s' in namespace '%s' due to %+v", certificate.GetName(), certificate.GetNamespace(), err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to get certificate '%s' in namespace '%s' due to %+v", certificate.GetName(), certificate.GetNamespace(), err)
+	}
+	current.Object["spec"] = certificate.Object["spec"]
+	current.SetLabels(certificate.GetLabels())
+	if _, err := client.Update(current, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update certificate '%s' in namespace '%s' due to %+v", certificate.GetName(), certificate.GetNamespace(), err)
+	}
+	return nil
+}
+
+// DeleteCertManagerCertificate deletes the certificate of the issuer and the secret that cert-manager issued it into
+func DeleteCertManagerCertificate(restConfig *rest.Config, clientset *kubernetes.Clientset, namespace string, issuer CertificateIssuer) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	if err := dynamicClient.Resource(certManagerCertificateResource).Namespace(namespace).Delete(issuer.SecretName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete certificate '%s' in namespace '%s' due to %+v", issuer.SecretName, namespace, err)
+	}
+	if err := DeleteSecret(clientset, namespace, issuer.SecretName); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete secret '%s' in namespace '%s' due to %+v", issuer.SecretName, namespace, err)
+	}
+	return nil
+}
+
+// WaitForCertificateSecret waits until cert-manager issued the certificate into the secret, so that the pods can mount it
+func WaitForCertificateSecret(clientset *kubernetes.Clientset, namespace string, secretName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		secret, err := GetSecret(clientset, namespace, secretName)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		if err == nil && len(secret.Data[CertManagerCertificateKey]) > 0 && len(secret.Data[CertManagerKeyKey]) > 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return WithExitCode(ExitCodeTimeout, fmt.Errorf("cert-manager didn't issue the certificate into secret '%s' in namespace '%s' within %s, check the events of certificate '%s'", secretName, namespace, timeout, secretName))
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// certificateSecretKeysPostRenderer maps the keys of the webserver certificate secret that the charts read to the keys
+// of the secret issued by cert-manager
+type certificateSecretKeysPostRenderer struct {
+	secretName string
+}
+
+// certificateSecretKeys maps the keys of the charts to the keys of cert-manager
+var certificateSecretKeys = map[string]string{
+	WebserverCertificateKey:    CertManagerCertificateKey,
+	WebserverCertificateKeyKey: CertManagerKeyKey,
+}
+
+// getCertificateSecretKeysPostRenderer returns the post-renderer of the secret of the cert-manager issuer, or nil
+func getCertificateSecretKeysPostRenderer(helmValues map[string]interface{}) *certificateSecretKeysPostRenderer {
+	issuer := GetCertificateIssuerFromHelmValues(helmValues)
+	if issuer == nil {
+		return nil
+	}
+	return &certificateSecretKeysPostRenderer{secretName: issuer.SecretName}
+}
+
+// Run implements postrender.PostRenderer
+func (r *certificateSecretKeysPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	objects, err := SplitManifests(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+	}
+	output := &bytes.Buffer{}
+	for _, object := range objects {
+		if podSpec := getWorkloadPodSpec(object); podSpec != nil {
+			r.mapPodSpecKeys(podSpec)
+		}
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(output, "---\n%s\n", strings.TrimRight(string(data), "\n"))
+	}
+	return output, nil
+}
+
+// mapPodSpecKeys maps the keys of the secret volumes and the secret environs of the pod spec
+func (r *certificateSecretKeysPostRenderer) mapPodSpecKeys(podSpec map[string]interface{}) {
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		secret, _ := volume["secret"].(map[string]interface{})
+		if secret == nil || secret["secretName"] != r.secretName {
+			continue
+		}
+		items, _ := secret["items"].([]interface{})
+		if len(items) == 0 {
+			// without items the files are named by the keys, keep the file names the containers read
+			secret["items"] = []interface{}{
+				map[string]interface{}{"key": CertManagerCertificateKey, "path": WebserverCertificateKey},
+				map[string]interface{}{"key": CertManagerKeyKey, "path": WebserverCertificateKeyKey},
+			}
+			continue
+		}
+		for _, i := range items {
+			item, _ := i.(map[string]interface{})
+			if key, _ := item["key"].(string); len(certificateSecretKeys[key]) > 0 {
+				item["key"] = certificateSecretKeys[key]
+			}
+		}
+	}
+
+	containers, _ := podSpec["containers"].([]interface{})
+	initContainers, _ := podSpec["initContainers"].([]interface{})
+	for _, c := range append(append([]interface{}{}, initContainers...), containers...) {
+		container, _ := c.(map[string]interface{})
+		env, _ := container["env"].([]interface{})
+		for _, e := range env {
+			environ, _ := e.(map[string]interface{})
+			valueFrom, _ := environ["valueFrom"].(map[string]interface{})
+			secretKeyRef, _ := valueFrom["secretKeyRef"].(map[string]interface{})
+			if secretKeyRef == nil || secretKeyRef["name"] != r.secretName {
+				continue
+			}
+			if key, _ := secretKeyRef["key"].(string); len(certificateSecretKeys[key]) > 0 {
+				secretKeyRef["key"] = certificateSecretKeys[key]
+			}
+		}
+	}
+}
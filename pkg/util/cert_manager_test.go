@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCertificateIssuer(t *testing.T) {
+	assert := assert.New(t)
+	issuer, err := ParseCertificateIssuer("letsencrypt")
+	assert.NoError(err)
+	assert.Equal(&CertificateIssuer{Name: "letsencrypt", Kind: CertManagerIssuer}, issuer)
+
+	issuer, err = ParseCertificateIssuer("clusterissuer/letsencrypt")
+	assert.NoError(err)
+	assert.Equal(&CertificateIssuer{Name: "letsencrypt", Kind: CertManagerClusterIssuer}, issuer)
+
+	for _, value := range []string{"", "Issuer/", "Certificate/letsencrypt", "Issuer/a/b"} {
+		_, err = ParseCertificateIssuer(value)
+		assert.Error(err, value)
+	}
+}
+
+func TestCertificateIssuerHelmValues(t *testing.T) {
+	assert := assert.New(t)
+	helmValues := map[string]interface{}{}
+	assert.Nil(GetCertificateIssuerFromHelmValues(helmValues))
+
+	issuer := &CertificateIssuer{Name: "letsencrypt", Kind: CertManagerClusterIssuer, SecretName: GetCertificateIssuerSecretName("bd", BlackDuckName)}
+	SetCertificateIssuerInHelmValues(helmValues, issuer)
+	assert.Equal(issuer, GetCertificateIssuerFromHelmValues(helmValues))
+	assert.Equal("bd-blackduck-webserver-tls", issuer.SecretName)
+
+	SetCertificateIssuerInHelmValues(helmValues, nil)
+	assert.Nil(GetCertificateIssuerFromHelmValues(helmValues))
+}
+
+func TestNewCertManagerCertificate(t *testing.T) {
+	assert := assert.New(t)
+	issuer := CertificateIssuer{Name: "letsencrypt", Kind: CertManagerIssuer, SecretName: "bd-blackduck-webserver-tls"}
+	certificate := NewCertManagerCertificate("ns", map[string]string{"app": "blackduck", "name": "bd"}, issuer, []string{"bd.example.com"})
+	assert.Equal("cert-manager.io/v1alpha2", certificate.GetAPIVersion())
+	assert.Equal("bd-blackduck-webserver-tls", certificate.GetName())
+	assert.Equal("ns", certificate.GetNamespace())
+	spec := certificate.Object["spec"].(map[string]interface{})
+	assert.Equal("bd-blackduck-webserver-tls", spec["secretName"])
+	assert.Equal("bd.example.com", spec["commonName"])
+	assert.Equal([]interface{}{"bd.example.com"}, spec["dnsNames"])
+	assert.Equal(map[string]interface{}{"name": "letsencrypt", "kind": "Issuer", "group": "cert-manager.io"}, spec["issuerRef"])
+}
+
+func TestCertificateSecretKeysPostRenderer(t *testing.T) {
+	assert := assert.New(t)
+	manifests := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webserver
+spec:
+  template:
+    spec:
+      containers:
+      - name: webserver
+        env:
+        - name: CERT
+          valueFrom:
+            secretKeyRef:
+              name: bd-blackduck-webserver-tls
+              key: WEBSERVER_CUSTOM_CERT_FILE
+      volumes:
+      - name: certificate
+        secret:
+          secretName: bd-blackduck-webserver-tls
+      - name: mapped
+        secret:
+          secretName: bd-blackduck-webserver-tls
+          items:
+          - key: WEBSERVER_CUSTOM_KEY_FILE
+            path: key.pem
+      - name: other
+        secret:
+          secretName: other
+`
+	renderer := &certificateSecretKeysPostRenderer{secretName: "bd-blackduck-webserver-tls"}
+	output, err := renderer.Run(bytes.NewBufferString(manifests))
+	assert.NoError(err)
+	objects, err := SplitManifests(output.String())
+	assert.NoError(err)
+	podSpec := getWorkloadPodSpec(objects[0])
+	volumes := podSpec["volumes"].([]interface{})
+	assert.Equal([]interface{}{
+		map[string]interface{}{"key": "tls.crt", "path": "WEBSERVER_CUSTOM_CERT_FILE"},
+		map[string]interface{}{"key": "tls.key", "path": "WEBSERVER_CUSTOM_KEY_FILE"},
+	}, volumes[0].(map[string]interface{})["secret"].(map[string]interface{})["items"])
+	assert.Equal([]interface{}{
+		map[string]interface{}{"key": "tls.key", "path": "key.pem"},
+	}, volumes[1].(map[string]interface{})["secret"].(map[string]interface{})["items"])
+	assert.Nil(volumes[2].(map[string]interface{})["secret"].(map[string]interface{})["items"])
+	env := podSpec["containers"].([]interface{})[0].(map[string]interface{})["env"].([]interface{})
+	assert.Equal("tls.crt", env[0].(map[string]interface{})["valueFrom"].(map[string]interface{})["secretKeyRef"].(map[string]interface{})["key"])
+}
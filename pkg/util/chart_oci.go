@@ -0,0 +1,263 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ChartLocationOCIScheme is the scheme of the charts in OCI registries, e.g. oci://ghcr.io/org/charts/blackduck:2020.6.0
+const ChartLocationOCIScheme = "oci"
+
+// Media types of the content layer of the charts in OCI registries, the first one was used by Helm 3.0 to 3.6
+var ociChartLayerMediaTypes = []string{"application/tar+gzip", "application/vnd.cncf.helm.chart.content.v1.tar+gzip"}
+
+// ChartRegistryUsername and ChartRegistryPassword are the credentials of the OCI registries of the charts. The
+// credentials of the registry in the Helm registry config file are used if they aren't set
+var ChartRegistryUsername = ""
+var ChartRegistryPassword = ""
+
+// ociChartReference is a chart in an OCI registry
+type ociChartReference struct {
+	registry   string
+	repository string
+	tag        string
+}
+
+// IsOCIChartLocation returns true if the chart location is an oci://REGISTRY/REPOSITORY[:TAG] reference
+func IsOCIChartLocation(location string) bool {
+	return strings.HasPrefix(location, ChartLocationOCIScheme+"://")
+}
+
+// parseOCIChartReference parses an oci://REGISTRY/REPOSITORY[:TAG] reference
+func parseOCIChartReference(location string) (*ociChartReference, error) {
+	reference := strings.TrimPrefix(location, ChartLocationOCIScheme+"://")
+	i := strings.Index(reference, "/")
+	if !IsOCIChartLocation(location) || i <= 0 {
+		return nil, ValidationError("invalid chart location '%s', expected %s://REGISTRY/REPOSITORY[:TAG]", location, ChartLocationOCIScheme)
+	}
+	ref := &ociChartReference{registry: reference[:i], repository: reference[i+1:]}
+	if j := strings.LastIndex(ref.repository, ":"); j >= 0 {
+		ref.repository, ref.tag = ref.repository[:j], ref.repository[j+1:]
+	}
+	if len(ref.repository) == 0 || strings.HasSuffix(ref.repository, "/") {
+		return nil, ValidationError("invalid chart location '%s', expected %s://REGISTRY/REPOSITORY[:TAG]", location, ChartLocationOCIScheme)
+	}
+	return ref, nil
+}
+
+func (r *ociChartReference) String() string {
+	if len(r.tag) == 0 {
+		return fmt.Sprintf("%s://%s/%s", ChartLocationOCIScheme, r.registry, r.repository)
+	}
+	return fmt.Sprintf("%s://%s/%s:%s", ChartLocationOCIScheme, r.registry, r.repository, r.tag)
+}
+
+// chartName returns the name of the chart, the last path element of its repository
+func (r *ociChartReference) chartName() string {
+	return path.Base(r.repository)
+}
+
+// newChartRegistryClient returns a client of the registry with the credentials of the charts, or the credentials of the
+// registries if they aren't set, e.g. on mirror-images whose --registry-username is the one of its registry
+func newChartRegistryClient(ref *ociChartReference) (*registryClient, error) {
+	client := newRegistryClient()
+	if len(ChartRegistryUsername) > 0 {
+		client.username, client.password = ChartRegistryUsername, ChartRegistryPassword
+	}
+	if len(client.username) > 0 {
+		return client, nil
+	}
+	var err error
+	client.username, client.password, err = getRegistryConfigCredentials(settings.RegistryConfig, ref.registry)
+	return client, err
+}
+
+// getRegistryConfigCredentials returns the credentials of the registry in the Helm registry config file, which has the
+// format of the Docker config file. No credentials are returned if the file or the registry doesn't exist
+func getRegistryConfigCredentials(configPath string, registry string) (string, string, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("unable to read the registry config file '%s' due to %+v", configPath, err)
+	}
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", "", fmt.Errorf("invalid registry config file '%s' due to %+v", configPath, err)
+	}
+	for _, key := range []string{registry, "https://" + registry} {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+		if len(auth.Auth) == 0 {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid credentials of registry '%s' in the registry config file '%s' due to %+v", registry, configPath, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("invalid credentials of registry '%s' in the registry config file '%s'", registry, configPath)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
+
+// ResolveOCIChartLocation returns the chart reference with the tag of the latest chart of the app version, or of the
+// latest chart if the app version is empty. A reference with a tag is returned as is
+func ResolveOCIChartLocation(location string, appVersion string) (string, error) {
+	ref, err := parseOCIChartReference(location)
+	if err != nil {
+		return "", err
+	}
+	if len(ref.tag) > 0 {
+		return location, nil
+	}
+	client, err := newChartRegistryClient(ref)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.do(http.MethodGet, fmt.Sprintf("https://%s/v2/%s/tags/list", ref.registry, ref.repository), nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to list the tags of chart '%s' due to %+v", location, err)
+	}
+	defer resp.Body.Close()
+	tags := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return "", fmt.Errorf("failed to decode the tags of chart '%s' due to %+v", location, err)
+	}
+	ref.tag = selectOCIChartTag(ref.chartName(), tags.Tags, appVersion)
+	if len(ref.tag) == 0 {
+		if len(appVersion) > 0 {
+			return "", fmt.Errorf("chart '%s' has no tag of version %s", location, appVersion)
+		}
+		return "", fmt.Errorf("chart '%s' has no tags", location)
+	}
+	return ref.String(), nil
+}
+
+// selectOCIChartTag returns the tag of the latest chart of the app version, or of the latest chart if the app version
+// is empty. The tags are chart versions, i.e. the app version and an optional chart number, e.g. 2020.6.0-1
+func selectOCIChartTag(chartName string, tags []string, appVersion string) string {
+	packageNames := []string{}
+	for _, tag := range tags {
+		packageNames = append(packageNames, fmt.Sprintf("%s-%s", chartName, tag))
+	}
+	var packageName string
+	if len(appVersion) > 0 {
+		packageName, _ = GetLatestChartURLForAppVersion(packageNames, chartName, appVersion)
+	} else {
+		packageName, _ = GetLatestChartURLForApp(packageNames, chartName)
+	}
+	return strings.TrimPrefix(packageName, chartName+"-")
+}
+
+// DownloadOCIChart pulls the chart of an oci:// reference into a temporary directory and returns its local path. The
+// reference must have a tag, see ResolveOCIChartLocation
+func DownloadOCIChart(location string) (string, error) {
+	downloadedChartsLock.Lock()
+	defer downloadedChartsLock.Unlock()
+	if chartPath, ok := downloadedCharts[location]; ok {
+		return chartPath, nil
+	}
+
+	ref, err := parseOCIChartReference(location)
+	if err != nil {
+		return "", err
+	}
+	if len(ref.tag) == 0 {
+		return "", ValidationError("chart location '%s' has no tag, expected %s://REGISTRY/REPOSITORY:TAG", location, ChartLocationOCIScheme)
+	}
+	client, err := newChartRegistryClient(ref)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.do(http.MethodGet, fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.registry, ref.repository, ref.tag), []string{ociImageManifestMediaType})
+	if err != nil {
+		return "", fmt.Errorf("unable to get the manifest of chart '%s' due to %+v", location, err)
+	}
+	manifest := &imageManifest{}
+	err = json.NewDecoder(resp.Body).Decode(manifest)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the manifest of chart '%s' due to %+v", location, err)
+	}
+	var layer *imageDescriptor
+	for i := range manifest.Layers {
+		for _, mediaType := range ociChartLayerMediaTypes {
+			if manifest.Layers[i].MediaType == mediaType {
+				layer = &manifest.Layers[i]
+			}
+		}
+	}
+	if layer == nil {
+		return "", fmt.Errorf("'%s' is not a chart, its manifest has no chart content layer", location)
+	}
+
+	resp, err = client.do(http.MethodGet, fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.registry, ref.repository, layer.Digest), nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to download the chart '%s' due to %+v", location, err)
+	}
+	defer resp.Body.Close()
+	dir, err := ioutil.TempDir("", "synopsysctl-chart-")
+	if err != nil {
+		return "", err
+	}
+	// name the package after the chart and its version, the version of the chart is parsed from it
+	chartPath := filepath.Join(dir, fmt.Sprintf("%s-%s.tgz", ref.chartName(), ref.tag))
+	f, err := os.Create(chartPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return "", fmt.Errorf("unable to download the chart '%s' due to %+v", location, err)
+	}
+	if digest := fmt.Sprintf("sha256:%x", hash.Sum(nil)); digest != layer.Digest {
+		return "", fmt.Errorf("the digest %s of the chart '%s' doesn't match the digest %s of its manifest", digest, location, layer.Digest)
+	}
+	downloadedCharts[location] = chartPath
+	return chartPath, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOCIChartReference(t *testing.T) {
+	assert := assert.New(t)
+	ref, err := parseOCIChartReference("oci://ghcr.io/org/charts/blackduck:2020.6.0-1")
+	assert.NoError(err)
+	assert.Equal(ociChartReference{registry: "ghcr.io", repository: "org/charts/blackduck", tag: "2020.6.0-1"}, *ref)
+	assert.Equal("blackduck", ref.chartName())
+
+	ref, err = parseOCIChartReference("oci://localhost:5000/blackduck")
+	assert.NoError(err)
+	assert.Equal(ociChartReference{registry: "localhost:5000", repository: "blackduck"}, *ref)
+	assert.Equal("oci://localhost:5000/blackduck", ref.String())
+
+	for _, location := range []string{"https://ghcr.io/org/blackduck", "oci://ghcr.io", "oci://ghcr.io/", "oci:///blackduck"} {
+		_, err := parseOCIChartReference(location)
+		assert.Error(err, location)
+	}
+	assert.True(IsOCIChartLocation("oci://ghcr.io/org/charts/blackduck"))
+	assert.False(IsOCIChartLocation("s3://charts/blackduck-2020.6.0.tgz"))
+}
+
+func TestSelectOCIChartTag(t *testing.T) {
+	assert := assert.New(t)
+	tags := []string{"2020.4.0", "2020.6.0", "2020.6.0-1", "2020.6.0-2", "latest"}
+	assert.Equal("2020.6.0-2", selectOCIChartTag("blackduck", tags, "2020.6.0"))
+	assert.Equal("2020.4.0", selectOCIChartTag("blackduck", tags, "2020.4.0"))
+	assert.Equal("2020.6.0-2", selectOCIChartTag("blackduck", tags, ""))
+	assert.Empty(selectOCIChartTag("blackduck", tags, "2020.8.0"))
+}
+
+func TestGetRegistryConfigCredentials(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "registry")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "registry.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass:word"))
+	assert.NoError(ioutil.WriteFile(configPath, []byte(fmt.Sprintf(`{"auths": {"ghcr.io": {"auth": "%s"}, "https://registry.example.com": {"username": "admin", "password": "secret"}}}`, auth)), 0600))
+
+	username, password, err := getRegistryConfigCredentials(configPath, "ghcr.io")
+	assert.NoError(err)
+	assert.Equal("user", username)
+	assert.Equal("pass:word", password)
+	username, password, err = getRegistryConfigCredentials(configPath, "registry.example.com")
+	assert.NoError(err)
+	assert.Equal("admin", username)
+	assert.Equal("secret", password)
+	username, _, err = getRegistryConfigCredentials(configPath, "docker.io")
+	assert.NoError(err)
+	assert.Empty(username)
+	username, _, err = getRegistryConfigCredentials(filepath.Join(dir, "missing.json"), "ghcr.io")
+	assert.NoError(err)
+	assert.Empty(username)
+}
+
+func TestDownloadOCIChart(t *testing.T) {
+	assert := assert.New(t)
+	content := []byte("chart")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/charts/blackduck/tags/list":
+			fmt.Fprint(w, `{"name": "charts/blackduck", "tags": ["2020.4.0", "2020.6.0", "2020.6.0-1"]}`)
+		case "/v2/charts/blackduck/manifests/2020.6.0-1":
+			fmt.Fprintf(w, `{"schemaVersion": 2, "config": {"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": "sha256:0"}, "layers": [{"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": "%s", "size": %d}]}`, digest, len(content))
+		case "/v2/charts/blackduck/blobs/" + digest:
+			w.Write(content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	defer func() { http.DefaultTransport = defaultTransport }()
+	ChartRegistryUsername, ChartRegistryPassword = "user", "pass"
+	defer func() { ChartRegistryUsername, ChartRegistryPassword = "", "" }()
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+	location, err := ResolveOCIChartLocation(fmt.Sprintf("oci://%s/charts/blackduck", registry), "2020.6.0")
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("oci://%s/charts/blackduck:2020.6.0-1", registry), location)
+	_, err = ResolveOCIChartLocation(fmt.Sprintf("oci://%s/charts/blackduck", registry), "2020.8.0")
+	assert.Error(err)
+
+	chartPath, err := DownloadOCIChart(location)
+	assert.NoError(err)
+	defer os.RemoveAll(filepath.Dir(chartPath))
+	assert.Equal("blackduck-2020.6.0-1.tgz", filepath.Base(chartPath))
+	data, _ := ioutil.ReadFile(chartPath)
+	assert.Equal(content, data)
+
+	_, err = DownloadOCIChart(fmt.Sprintf("oci://%s/charts/blackduck", registry))
+	assert.Error(err)
+}
+
+func TestNewChartRegistryClient(t *testing.T) {
+	assert := assert.New(t)
+	ref := &ociChartReference{registry: "registry.example.com", repository: "charts/blackduck"}
+	defer func() {
+		ChartRegistryUsername, ChartRegistryPassword = "", ""
+		RegistryUsername, RegistryPassword = "", ""
+	}()
+
+	// the credentials of the registries are used if the ones of the charts aren't set
+	RegistryUsername, RegistryPassword = "mirror", "mirror-pass"
+	client, err := newChartRegistryClient(ref)
+	assert.NoError(err)
+	assert.Equal("mirror", client.username)
+	assert.Equal("mirror-pass", client.password)
+
+	ChartRegistryUsername, ChartRegistryPassword = "charts", "charts-pass"
+	client, err = newChartRegistryClient(ref)
+	assert.NoError(err)
+	assert.Equal("charts", client.username)
+	assert.Equal("charts-pass", client.password)
+}
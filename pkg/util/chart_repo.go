@@ -0,0 +1,169 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// ChartRepoUsername and ChartRepoPassword are the basic auth credentials of the chart repositories, e.g. of an internal
+// Artifactory, and ChartRepoCAFile is the PEM file of the CA of their certificates
+var ChartRepoUsername = ""
+var ChartRepoPassword = ""
+var ChartRepoCAFile = ""
+
+// ChartRepoInsecureSkipTLSVerify disables the verification of the certificates of the chart repositories
+var ChartRepoInsecureSkipTLSVerify = false
+
+// IsChartRepoConfigured returns true if credentials, a CA or the insecure mode are set for the chart repositories
+func IsChartRepoConfigured() bool {
+	return len(ChartRepoUsername) > 0 || len(ChartRepoCAFile) > 0 || ChartRepoInsecureSkipTLSVerify
+}
+
+// IsHTTPChartLocation returns true if the chart location is an http:// or https:// URL
+func IsHTTPChartLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// chartRepoGetter is the Helm getter of the http and https chart repositories with the credentials, the CA and the
+// insecure mode of the chart repositories. The credentials are only sent to the repository of the getter
+type chartRepoGetter struct {
+	client  *http.Client
+	repoURL *url.URL
+}
+
+// newChartRepoGetter returns the getter of the chart repository at the URL, it fails if the CA file can't be read
+func newChartRepoGetter(repoURL string) (getter.Getter, error) {
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil || len(parsedURL.Host) == 0 {
+		return nil, ValidationError("invalid chart repository '%s'", repoURL)
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: ChartRepoInsecureSkipTLSVerify}
+	if len(ChartRepoCAFile) > 0 {
+		data, err := ioutil.ReadFile(ChartRepoCAFile)
+		if err != nil {
+			return nil, ValidationError("unable to read the CA file '%s' of the chart repositories due to %+v", ChartRepoCAFile, err)
+		}
+		// the CA is added to the system's, a repository may redirect to a CDN with a public certificate
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, ValidationError("the CA file '%s' of the chart repositories has no PEM certificate", ChartRepoCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{Timeout: ChartStorageTimeout, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}}
+	return &chartRepoGetter{client: client, repoURL: parsedURL}, nil
+}
+
+// Get downloads the chart or index at the URL
+func (g *chartRepoGetter) Get(href string, options ...getter.Option) (*bytes.Buffer, error) {
+	req, err := http.NewRequest(http.MethodGet, href, nil)
+	if err != nil {
+		return nil, err
+	}
+	// an index may list charts of other hosts, which don't get the credentials of the repository
+	if len(ChartRepoUsername) > 0 && req.URL.Scheme == g.repoURL.Scheme && strings.EqualFold(req.URL.Host, g.repoURL.Host) {
+		req.SetBasicAuth(ChartRepoUsername, ChartRepoPassword)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("access to '%s' is denied, check --chart-repo-username and --chart-repo-password", href)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch '%s': %s", href, resp.Status)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, resp.Body); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// getChartRepoGetters returns the Helm getters, with the getter of the chart repository at the URL for http and https if
+// credentials, a CA or the insecure mode are set
+func getChartRepoGetters(repoURL string) getter.Providers {
+	providers := getter.All(settings)
+	if !IsChartRepoConfigured() {
+		return providers
+	}
+	newGetter := func(options ...getter.Option) (getter.Getter, error) {
+		return newChartRepoGetter(repoURL)
+	}
+	// the first provider of a scheme is used
+	return append(getter.Providers{{Schemes: []string{"http", "https"}, New: newGetter}}, providers...)
+}
+
+// DownloadHTTPChart downloads the chart at an http:// or https:// URL with the credentials, the CA and the insecure mode
+// of the chart repositories into a temporary directory and returns its local path. Helm 3.1 doesn't apply a CA or the
+// insecure mode to the download of a chart by its URL
+func DownloadHTTPChart(location string) (string, error) {
+	downloadedChartsLock.Lock()
+	defer downloadedChartsLock.Unlock()
+	if chartPath, ok := downloadedCharts[location]; ok {
+		return chartPath, nil
+	}
+
+	chartURL, err := url.Parse(location)
+	if err != nil || len(chartURL.Host) == 0 {
+		return "", ValidationError("invalid chart location '%s'", location)
+	}
+	chartGetter, err := newChartRepoGetter(location)
+	if err != nil {
+		return "", err
+	}
+	buf, err := chartGetter.Get(location)
+	if err != nil {
+		return "", fmt.Errorf("unable to download the chart '%s' due to %+v", location, err)
+	}
+	dir, err := ioutil.TempDir("", "synopsysctl-chart-")
+	if err != nil {
+		return "", err
+	}
+	// keep the name of the package, the version of the chart is parsed from it
+	chartPath := filepath.Join(dir, path.Base(chartURL.Path))
+	if err := ioutil.WriteFile(chartPath, buf.Bytes(), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	downloadedCharts[location] = chartPath
+	return chartPath, nil
+}
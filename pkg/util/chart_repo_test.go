@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadHTTPChart(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/artifactory/charts/blackduck-2020.6.0.tgz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "chart")
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "chart-repo")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	assert.NoError(ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644))
+	defer func() {
+		ChartRepoUsername, ChartRepoPassword, ChartRepoCAFile, ChartRepoInsecureSkipTLSVerify = "", "", "", false
+	}()
+
+	// the certificate of the server isn't trusted without its CA
+	ChartRepoUsername, ChartRepoPassword = "user", "pass"
+	_, err = DownloadHTTPChart(server.URL + "/artifactory/charts/blackduck-2020.6.0.tgz")
+	assert.Error(err)
+
+	ChartRepoCAFile = caFile
+	assert.True(IsChartRepoConfigured())
+	chartPath, err := DownloadHTTPChart(server.URL + "/artifactory/charts/blackduck-2020.6.0.tgz")
+	assert.NoError(err)
+	defer os.RemoveAll(filepath.Dir(chartPath))
+	assert.Equal("blackduck-2020.6.0.tgz", filepath.Base(chartPath))
+	data, _ := ioutil.ReadFile(chartPath)
+	assert.Equal("chart", string(data))
+
+	ChartRepoUsername, ChartRepoCAFile, ChartRepoInsecureSkipTLSVerify = "", "", true
+	repoGetter, err := getChartRepoGetters(server.URL + "/artifactory/charts").ByScheme("https")
+	assert.NoError(err)
+	_, err = repoGetter.Get(server.URL + "/artifactory/charts/blackduck-2020.6.0.tgz")
+	assert.Error(err)
+	assert.Contains(err.Error(), "--chart-repo-username")
+
+	ChartRepoCAFile = filepath.Join(dir, "missing.pem")
+	_, err = newChartRepoGetter(server.URL)
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+}
+
+func TestChartRepoGetterCredentials(t *testing.T) {
+	assert := assert.New(t)
+	authorized := map[string]bool{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		authorized[r.Host] = ok
+		fmt.Fprint(w, "index")
+	})
+	repoServer := httptest.NewServer(handler)
+	defer repoServer.Close()
+	otherServer := httptest.NewServer(handler)
+	defer otherServer.Close()
+	defer func() { ChartRepoUsername, ChartRepoPassword = "", "" }()
+
+	ChartRepoUsername, ChartRepoPassword = "user", "pass"
+	repoGetter, err := newChartRepoGetter(repoServer.URL + "/artifactory/charts")
+	assert.NoError(err)
+	_, err = repoGetter.Get(repoServer.URL + "/artifactory/charts/index.yaml")
+	assert.NoError(err)
+	assert.True(authorized[strings.TrimPrefix(repoServer.URL, "http://")])
+
+	// the credentials of the repository aren't sent to the other hosts of its index
+	_, err = repoGetter.Get(otherServer.URL + "/blackduck-2020.6.0.tgz")
+	assert.NoError(err)
+	assert.False(authorized[strings.TrimPrefix(otherServer.URL, "http://")])
+
+	_, err = newChartRepoGetter("charts")
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+}
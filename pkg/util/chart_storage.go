@@ -0,0 +1,289 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schemes of the chart locations in object storages
+const (
+	ChartLocationS3Scheme  = "s3"
+	ChartLocationGCSScheme = "gs"
+)
+
+// ChartStorageTimeout is the timeout of the download of a chart from an object storage
+var ChartStorageTimeout = 5 * time.Minute
+
+// GCSEndpoint is the URL of the Google Cloud Storage JSON API
+var GCSEndpoint = "https://storage.googleapis.com"
+
+// gcsMetadataTokenURL returns the access token of the service account of a Google Compute Engine or GKE node
+var gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcsReadOnlyScope is the OAuth scope requested for the service account keys
+const gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// downloadedCharts caches the local paths of the charts downloaded from object storages by their location, so that a
+// command that loads a chart several times downloads it once
+var downloadedCharts = map[string]string{}
+var downloadedChartsLock sync.Mutex
+
+// IsObjectStorageChartLocation returns true if the chart location is an s3://BUCKET/KEY or gs://BUCKET/OBJECT URL
+func IsObjectStorageChartLocation(location string) bool {
+	return strings.HasPrefix(location, ChartLocationS3Scheme+"://") || strings.HasPrefix(location, ChartLocationGCSScheme+"://")
+}
+
+// DownloadObjectStorageChart downloads the chart at an s3:// or gs:// URL into a temporary directory and returns its
+// local path. The credentials are resolved from the environment. S3 uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN in AWS_REGION or AWS_DEFAULT_REGION, and AWS_ENDPOINT_URL for S3 compatible storages. Google Cloud
+// Storage uses GOOGLE_OAUTH_ACCESS_TOKEN, the service account key of GOOGLE_APPLICATION_CREDENTIALS or the service
+// account of the node. Without credentials the object is requested anonymously, e.g. from a public bucket
+func DownloadObjectStorageChart(location string) (string, error) {
+	downloadedChartsLock.Lock()
+	defer downloadedChartsLock.Unlock()
+	if chartPath, ok := downloadedCharts[location]; ok {
+		return chartPath, nil
+	}
+
+	chartURL, err := url.Parse(location)
+	if err != nil || len(chartURL.Host) == 0 || len(strings.Trim(chartURL.Path, "/")) == 0 {
+		return "", ValidationError("invalid chart location '%s', expected %s://BUCKET/KEY or %s://BUCKET/OBJECT", location, ChartLocationS3Scheme, ChartLocationGCSScheme)
+	}
+	var req *http.Request
+	switch chartURL.Scheme {
+	case ChartLocationS3Scheme:
+		req, err = newS3ObjectRequest(chartURL.Host, strings.TrimPrefix(chartURL.Path, "/"))
+	case ChartLocationGCSScheme:
+		req, err = newGCSObjectRequest(chartURL.Host, strings.TrimPrefix(chartURL.Path, "/"))
+	default:
+		return "", ValidationError("unsupported chart location scheme '%s'", chartURL.Scheme)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{Timeout: ChartStorageTimeout, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to download the chart '%s' due to %+v", location, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("chart '%s' doesn't exist", location)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return "", fmt.Errorf("access to the chart '%s' is denied, check the credentials in the environment", location)
+	case resp.StatusCode/100 != 2:
+		return "", fmt.Errorf("unexpected status '%s' downloading the chart '%s'", resp.Status, location)
+	}
+
+	dir, err := ioutil.TempDir("", "synopsysctl-chart-")
+	if err != nil {
+		return "", err
+	}
+	// keep the name of the package, the version of the chart is parsed from it
+	chartPath := filepath.Join(dir, path.Base(chartURL.Path))
+	f, err := os.Create(chartPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", fmt.Errorf("unable to download the chart '%s' due to %+v", location, err)
+	}
+	downloadedCharts[location] = chartPath
+	return chartPath, nil
+}
+
+// newS3ObjectRequest returns the path style request of an S3 object, signed with the credentials of the environment
+func newS3ObjectRequest(bucketName string, key string) (*http.Request, error) {
+	bucket := GetS3BucketFromEnv(bucketName)
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	endpoint.Path = fmt.Sprintf("/%s/%s", bucketName, key)
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(bucket.AccessKeyID) > 0 {
+		SignS3Request(req, bucket, time.Now().UTC())
+	}
+	return req, nil
+}
+
+// newGCSObjectRequest returns the request of the content of a Google Cloud Storage object, authorized with the
+// credentials of the environment
+func newGCSObjectRequest(bucket string, object string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", strings.TrimSuffix(GCSEndpoint, "/"), url.PathEscape(bucket), url.PathEscape(object)), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := getGCSAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// gcsTokenResponse is the response of the OAuth token endpoints of Google
+type gcsTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// getGCSAccessToken returns the OAuth access token of the environment, or an empty token if there are no credentials
+func getGCSAccessToken() (string, error) {
+	return GetGoogleAccessToken(gcsReadOnlyScope)
+}
+
+// GetGoogleAccessToken returns the OAuth access token of the environment for the scope of the service account keys, or
+// an empty token if there are no credentials. The token of the node's service account has the scopes of the node
+func GetGoogleAccessToken(scope string) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); len(token) > 0 {
+		return token, nil
+	}
+	if keyFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); len(keyFile) > 0 {
+		data, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("unable to read the service account key '%s' due to %+v", keyFile, err)
+		}
+		return getGoogleServiceAccountToken(data, scope, time.Now())
+	}
+
+	// the metadata server only exists on Google Cloud, elsewhere the object is requested anonymously
+	req, err := http.NewRequest(http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
+	if err != nil {
+		return "", nil
+	}
+	defer resp.Body.Close()
+	token := gcsTokenResponse{}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&token) != nil {
+		return "", nil
+	}
+	return token.AccessToken, nil
+}
+
+// gcsServiceAccountKey is the JSON key file of a Google service account
+type gcsServiceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// GetGCSServiceAccountToken exchanges a JWT signed by the service account key for a read-only access token
+func GetGCSServiceAccountToken(keyData []byte, now time.Time) (string, error) {
+	return getGoogleServiceAccountToken(keyData, gcsReadOnlyScope, now)
+}
+
+// getGoogleServiceAccountToken exchanges a JWT signed by the service account key for an access token of the scope
+func getGoogleServiceAccountToken(keyData []byte, scope string, now time.Time) (string, error) {
+	key := gcsServiceAccountKey{}
+	if err := json.Unmarshal(keyData, &key); err != nil {
+		return "", fmt.Errorf("invalid service account key due to %+v", err)
+	}
+	assertion, err := newGoogleServiceAccountJWT(key.ClientEmail, key.PrivateKey, key.TokenURI, scope, now)
+	if err != nil {
+		return "", err
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).PostForm(key.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get an access token of the service account '%s' due to %+v", key.ClientEmail, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to get an access token of the service account '%s', the token endpoint returned '%s'", key.ClientEmail, resp.Status)
+	}
+	token := gcsTokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode the access token of the service account '%s' due to %+v", key.ClientEmail, err)
+	}
+	return token.AccessToken, nil
+}
+
+// NewGCSServiceAccountJWT returns the JWT of the OAuth JWT bearer grant, signed with the RSA private key of a service account
+func NewGCSServiceAccountJWT(clientEmail string, privateKey string, tokenURI string, now time.Time) (string, error) {
+	return newGoogleServiceAccountJWT(clientEmail, privateKey, tokenURI, gcsReadOnlyScope, now)
+}
+
+// newGoogleServiceAccountJWT returns the JWT of the OAuth JWT bearer grant of the scope
+func newGoogleServiceAccountJWT(clientEmail string, privateKey string, tokenURI string, scope string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return "", fmt.Errorf("the private key of the service account '%s' is not PEM encoded", clientEmail)
+	}
+	var rsaKey *rsa.PrivateKey
+	if parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		var ok bool
+		if rsaKey, ok = parsedKey.(*rsa.PrivateKey); !ok {
+			return "", fmt.Errorf("the private key of the service account '%s' is not an RSA key", clientEmail)
+		}
+	} else if rsaKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("unable to parse the private key of the service account '%s' due to %+v", clientEmail, err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   clientEmail,
+		"scope": scope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsObjectStorageChartLocation(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsObjectStorageChartLocation("s3://charts/blackduck-2020.6.0.tgz"))
+	assert.True(IsObjectStorageChartLocation("gs://charts/blackduck-2020.6.0.tgz"))
+	assert.False(IsObjectStorageChartLocation("https://charts.example.com/blackduck-2020.6.0.tgz"))
+	assert.False(IsObjectStorageChartLocation("/charts/blackduck-2020.6.0.tgz"))
+}
+
+func TestDownloadS3Chart(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") || r.Header.Get("X-Amz-Security-Token") != "session" ||
+			!strings.Contains(r.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/charts/synopsys/blackduck-2020.6.0.tgz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "chart")
+	}))
+	defer server.Close()
+	for key, value := range map[string]string{"AWS_ENDPOINT_URL": server.URL, "AWS_ACCESS_KEY_ID": "key", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_SESSION_TOKEN": "session"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	chartPath, err := DownloadObjectStorageChart("s3://charts/synopsys/blackduck-2020.6.0.tgz")
+	assert.NoError(err)
+	defer os.RemoveAll(filepath.Dir(chartPath))
+	assert.Equal("blackduck-2020.6.0.tgz", filepath.Base(chartPath))
+	data, _ := ioutil.ReadFile(chartPath)
+	assert.Equal("chart", string(data))
+
+	_, err = DownloadObjectStorageChart("s3://charts/missing-1.0.0.tgz")
+	assert.Error(err)
+	_, err = DownloadObjectStorageChart("s3://charts")
+	assert.Error(err)
+}
+
+func TestDownloadGCSChart(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/storage/v1/b/charts/o/synopsys%2Falert-6.0.0.tgz" || r.URL.Query().Get("alt") != "media" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "chart")
+	}))
+	defer server.Close()
+	defer func(endpoint string) { GCSEndpoint = endpoint }(GCSEndpoint)
+	GCSEndpoint = server.URL
+	os.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "token")
+	defer os.Unsetenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+
+	chartPath, err := DownloadObjectStorageChart("gs://charts/synopsys/alert-6.0.0.tgz")
+	assert.NoError(err)
+	defer os.RemoveAll(filepath.Dir(chartPath))
+	data, _ := ioutil.ReadFile(chartPath)
+	assert.Equal("chart", string(data))
+}
+
+func TestNewGCSServiceAccountJWT(t *testing.T) {
+	assert := assert.New(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(err)
+	keyData, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	assert.NoError(err)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData}))
+	now := time.Unix(1590000000, 0)
+
+	jwt, err := NewGCSServiceAccountJWT("ctl@project.iam.gserviceaccount.com", privateKey, "https://oauth2.googleapis.com/token", now)
+	assert.NoError(err)
+	parts := strings.Split(jwt, ".")
+	assert.Len(parts, 3)
+	claimsData, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	claims := map[string]interface{}{}
+	assert.NoError(json.Unmarshal(claimsData, &claims))
+	assert.Equal("ctl@project.iam.gserviceaccount.com", claims["iss"])
+	assert.Equal("https://oauth2.googleapis.com/token", claims["aud"])
+	assert.Equal(float64(1590003600), claims["exp"])
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, hashed[:], signature))
+
+	_, err = NewGCSServiceAccountJWT("ctl@project.iam.gserviceaccount.com", "not a key", "https://oauth2.googleapis.com/token", now)
+	assert.Error(err)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Cluster types of --cluster-type
+const (
+	ClusterTypeKubernetes = "kubernetes"
+	ClusterTypeOpenShift  = "openshift"
+)
+
+// ClusterTypeOverride is the type of the cluster set by the user, the type is discovered if it is empty
+var ClusterTypeOverride = ""
+
+// openShiftAPIGroups are served by OpenShift clusters only
+var openShiftAPIGroups = []string{"route.openshift.io", "security.openshift.io", "project.openshift.io"}
+
+// defaultStorageClassAnnotations mark the default storage class of a cluster
+var defaultStorageClassAnnotations = []string{"storageclass.kubernetes.io/is-default-class", "storageclass.beta.kubernetes.io/is-default-class"}
+
+// IsDefaultStorageClass returns true if the annotations of a storage class mark it as the default storage class
+func IsDefaultStorageClass(annotations map[string]string) bool {
+	for _, annotation := range defaultStorageClassAnnotations {
+		if annotations[annotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterInfo is the result of the discovery of a cluster's capabilities
+type ClusterInfo struct {
+	IsOpenShift         bool      `json:"isOpenShift"`
+	ServerVersion       string    `json:"serverVersion"`
+	APIGroups           []string  `json:"apiGroups"`
+	DefaultStorageClass string    `json:"defaultStorageClass"`
+	DiscoveredAt        time.Time `json:"discoveredAt"`
+}
+
+// HasAPIGroup returns true if the cluster serves the API group
+func (i *ClusterInfo) HasAPIGroup(group string) bool {
+	for _, apiGroup := range i.APIGroups {
+		if apiGroup == group {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterInfos are the discovered cluster capabilities of each client in this process
+var clusterInfos = map[*kubernetes.Clientset]*ClusterInfo{}
+var clusterInfosLock sync.Mutex
+
+// SetClusterInfo sets the capabilities of the cluster of the client, e.g. from the cache on disk, so they are not discovered again
+func SetClusterInfo(clientset *kubernetes.Clientset, info *ClusterInfo) {
+	clusterInfosLock.Lock()
+	defer clusterInfosLock.Unlock()
+	clusterInfos[clientset] = info
+}
+
+// GetClusterInfo returns the capabilities of the cluster of the client, they are discovered once per client
+func GetClusterInfo(clientset *kubernetes.Clientset) (*ClusterInfo, error) {
+	clusterInfosLock.Lock()
+	defer clusterInfosLock.Unlock()
+	if info, ok := clusterInfos[clientset]; ok {
+		return info, nil
+	}
+	info, err := DiscoverClusterInfo(clientset)
+	if err != nil {
+		return nil, err
+	}
+	clusterInfos[clientset] = info
+	return info, nil
+}
+
+// DiscoverClusterInfo queries the cluster for its capabilities. Restricted users may not be allowed to read some of the
+// discovery endpoints, so the discovery only fails if neither the API paths nor the API groups can be read
+func DiscoverClusterInfo(clientset *kubernetes.Clientset) (*ClusterInfo, error) {
+	info := &ClusterInfo{DiscoveredAt: time.Now()}
+	body, pathsErr := clientset.Discovery().RESTClient().Get().AbsPath("/").Do().Raw()
+	if pathsErr != nil {
+		log.Debugf("unable to discover the API paths of the cluster due to %+v", pathsErr)
+	} else {
+		info.IsOpenShift = strings.Contains(string(body), "openshift")
+	}
+
+	groups, groupsErr := clientset.Discovery().ServerGroups()
+	if groupsErr != nil {
+		log.Debugf("unable to get the API groups of the cluster due to %+v", groupsErr)
+	} else {
+		for _, group := range groups.Groups {
+			info.APIGroups = append(info.APIGroups, group.Name)
+			for _, openShiftGroup := range openShiftAPIGroups {
+				if group.Name == openShiftGroup {
+					info.IsOpenShift = true
+				}
+			}
+		}
+	}
+	if pathsErr != nil && groupsErr != nil {
+		return nil, fmt.Errorf("unable to discover the type of the cluster due to %+v, use --cluster-type to set it", groupsErr)
+	}
+
+	if version, err := clientset.Discovery().ServerVersion(); err == nil {
+		info.ServerVersion = version.GitVersion
+	} else {
+		log.Debugf("unable to get the version of the cluster due to %+v", err)
+	}
+
+	// users may not be allowed to list storage classes, the default storage class is optional
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(metav1.ListOptions{})
+	if err == nil {
+		for _, storageClass := range storageClasses.Items {
+			if IsDefaultStorageClass(storageClass.Annotations) {
+				info.DefaultStorageClass = storageClass.Name
+			}
+		}
+	}
+	return info, nil
+}
+
+// clusterInfoCacheFileNameRegex matches the characters that can't be used in the file name of a cache entry
+var clusterInfoCacheFileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// clusterInfoCacheFile returns the path of the cache entry of a kube-context
+func clusterInfoCacheFile(cacheDir string, kubeContext string) string {
+	return filepath.Join(cacheDir, fmt.Sprintf("cluster-info-%s.json", clusterInfoCacheFileNameRegex.ReplaceAllString(kubeContext, "_")))
+}
+
+// LoadClusterInfoCache returns the cached capabilities of the cluster of a kube-context if they are newer than the ttl
+func LoadClusterInfoCache(cacheDir string, kubeContext string, ttl time.Duration) (*ClusterInfo, bool) {
+	data, err := ioutil.ReadFile(clusterInfoCacheFile(cacheDir, kubeContext))
+	if err != nil {
+		return nil, false
+	}
+	info := &ClusterInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, false
+	}
+	if time.Since(info.DiscoveredAt) > ttl {
+		return nil, false
+	}
+	return info, true
+}
+
+// SaveClusterInfoCache caches the capabilities of the cluster of a kube-context
+func SaveClusterInfoCache(cacheDir string, kubeContext string, info *ClusterInfo) error {
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return fmt.Errorf("unable to create the cache directory '%s' due to %+v", cacheDir, err)
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the cluster info due to %+v", err)
+	}
+	if err := ioutil.WriteFile(clusterInfoCacheFile(cacheDir, kubeContext), data, 0600); err != nil {
+		return fmt.Errorf("unable to write the cluster info cache due to %+v", err)
+	}
+	return nil
+}
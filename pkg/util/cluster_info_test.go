@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterInfoCache(t *testing.T) {
+	assert := assert.New(t)
+	cacheDir, err := ioutil.TempDir("", "cluster-info")
+	assert.NoError(err)
+	defer os.RemoveAll(cacheDir)
+
+	kubeContext := "admin@cluster/one@https://10.0.0.1:6443"
+	_, ok := LoadClusterInfoCache(cacheDir, kubeContext, time.Hour)
+	assert.False(ok)
+
+	info := &ClusterInfo{IsOpenShift: true, ServerVersion: "v1.17.3", APIGroups: []string{"route.openshift.io"}, DefaultStorageClass: "gp2", DiscoveredAt: time.Now().Round(0)}
+	assert.NoError(SaveClusterInfoCache(cacheDir, kubeContext, info))
+	assert.Equal(filepath.Join(cacheDir, "cluster-info-admin_cluster_one_https___10.0.0.1_6443.json"), clusterInfoCacheFile(cacheDir, kubeContext))
+
+	cached, ok := LoadClusterInfoCache(cacheDir, kubeContext, time.Hour)
+	assert.True(ok)
+	assert.True(cached.DiscoveredAt.Equal(info.DiscoveredAt))
+	cached.DiscoveredAt = info.DiscoveredAt
+	assert.Equal(info, cached)
+	assert.True(cached.HasAPIGroup("route.openshift.io"))
+	assert.False(cached.HasAPIGroup("snapshot.storage.k8s.io"))
+
+	// expired
+	_, ok = LoadClusterInfoCache(cacheDir, kubeContext, 0)
+	assert.False(ok)
+}
+
+func TestIsOpenshiftClusterTypeOverride(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { ClusterTypeOverride = "" }()
+
+	// the override is used without querying the cluster
+	ClusterTypeOverride = ClusterTypeOpenShift
+	assert.True(IsOpenshift(nil))
+	ClusterTypeOverride = ClusterTypeKubernetes
+	assert.False(IsOpenshift(nil))
+}
+
+func TestIsDefaultStorageClass(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsDefaultStorageClass(map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}))
+	assert.True(IsDefaultStorageClass(map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "true"}))
+	assert.False(IsDefaultStorageClass(map[string]string{"storageclass.kubernetes.io/is-default-class": "false"}))
+	assert.False(IsDefaultStorageClass(nil))
+}
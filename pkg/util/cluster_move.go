@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lastAppliedConfigAnnotation is the annotation of kubectl apply, it refers to the object of the source cluster
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// GetSecretsForCluster returns copies of the secrets of an instance to create in the namespace of another cluster.
+// The secrets managed by Helm are skipped since the chart creates them from the values, and the service account
+// tokens are skipped since the target cluster issues its own
+func GetSecretsForCluster(secrets []*corev1.Secret, namespace string) []*corev1.Secret {
+	copies := []*corev1.Secret{}
+	for _, secret := range secrets {
+		if secret.Labels["app.kubernetes.io/managed-by"] == "Helm" || secret.Type == corev1.SecretTypeServiceAccountToken {
+			continue
+		}
+		annotations := map[string]string{}
+		for key, value := range secret.Annotations {
+			if key != lastAppliedConfigAnnotation {
+				annotations[key] = value
+			}
+		}
+		copies = append(copies, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        secret.Name,
+				Namespace:   namespace,
+				Labels:      secret.Labels,
+				Annotations: annotations,
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		})
+	}
+	return copies
+}
@@ -0,0 +1,67 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSecretsForCluster(t *testing.T) {
+	assert := assert.New(t)
+	secrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "bd-blackduck-webserver-certificate",
+				Namespace:       "source",
+				UID:             "1234",
+				ResourceVersion: "42",
+				Labels:          map[string]string{"app": "blackduck", "name": "bd"},
+				Annotations:     map[string]string{lastAppliedConfigAnnotation: "{}", "owner": "team"},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-db-creds", Labels: map[string]string{"app.kubernetes.io/managed-by": "Helm"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-token-abcde"},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		},
+	}
+
+	copies := GetSecretsForCluster(secrets, "target")
+	assert.Len(copies, 1)
+	copied := copies[0]
+	assert.Equal("bd-blackduck-webserver-certificate", copied.Name)
+	assert.Equal("target", copied.Namespace)
+	assert.Empty(copied.UID)
+	assert.Empty(copied.ResourceVersion)
+	assert.Equal(map[string]string{"owner": "team"}, copied.Annotations)
+	assert.Equal(secrets[0].Labels, copied.Labels)
+	assert.Equal(corev1.SecretTypeTLS, copied.Type)
+	assert.Equal([]byte("key"), copied.Data["tls.key"])
+}
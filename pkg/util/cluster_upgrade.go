@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Rules of the cluster upgrade check
+const (
+	UpgradeRuleRemovedAPI        = "removed-api-version"
+	UpgradeRulePodSecurityPolicy = "pod-security-policy"
+	UpgradeRuleIngressClass      = "ingress-class"
+)
+
+const (
+	// podSecurityPolicyRemovedIn is the Kubernetes minor version that removed PodSecurityPolicies
+	podSecurityPolicyRemovedIn = 25
+	// ingressClassAnnotationRemovedIn is the Kubernetes minor version that removed the Ingress APIs that
+	// ingress controllers read the class annotation with
+	ingressClassAnnotationRemovedIn = 22
+	ingressClassAnnotation          = "kubernetes.io/ingress.class"
+)
+
+// CheckClusterUpgrade returns the problems of the rendered objects that break them on the Kubernetes version, e.g. 1.29:
+// API versions that the version doesn't serve, reliance on PodSecurityPolicies and Ingresses selected by the class annotation
+func CheckClusterUpgrade(manifest string, kubeVersion string) ([]LintFinding, error) {
+	if len(kubeVersion) == 0 {
+		return nil, fmt.Errorf("the Kubernetes version to upgrade to is required")
+	}
+	minorVersion, err := parseKubeMinorVersion(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	findings := []LintFinding{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		findings = append(findings, checkRemovedAPIVersion(u, minorVersion)...)
+		findings = append(findings, checkPodSecurityPolicyReliance(u, minorVersion)...)
+		findings = append(findings, checkIngressClass(u, minorVersion)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return findings, nil
+}
+
+// checkRemovedAPIVersion returns a finding if the API version of the object isn't served by the Kubernetes minor version
+func checkRemovedAPIVersion(u *unstructured.Unstructured, minorVersion int) []LintFinding {
+	deprecated, ok := deprecatedAPIVersions[fmt.Sprintf("%s/%s", u.GetAPIVersion(), u.GetKind())]
+	if !ok || minorVersion < deprecated.removedIn {
+		return nil
+	}
+	message := fmt.Sprintf("%s is removed in Kubernetes 1.%d, use %s", u.GetAPIVersion(), deprecated.removedIn, deprecated.replacement)
+	return []LintFinding{{Rule: UpgradeRuleRemovedAPI, Kind: u.GetKind(), Name: u.GetName(), Message: message}}
+}
+
+// checkPodSecurityPolicyReliance returns a finding if the object is a PodSecurityPolicy, or a role that grants the use
+// of PodSecurityPolicies, and the Kubernetes minor version doesn't enforce them anymore
+func checkPodSecurityPolicyReliance(u *unstructured.Unstructured, minorVersion int) []LintFinding {
+	if minorVersion < podSecurityPolicyRemovedIn {
+		return nil
+	}
+	message := fmt.Sprintf("PodSecurityPolicies are removed in Kubernetes 1.%d, label the namespace for Pod Security Admission instead", podSecurityPolicyRemovedIn)
+	switch u.GetKind() {
+	case "PodSecurityPolicy":
+		return []LintFinding{{Rule: UpgradeRulePodSecurityPolicy, Kind: u.GetKind(), Name: u.GetName(), Message: message}}
+	case "Role", "ClusterRole":
+		rules, _, _ := unstructured.NestedSlice(u.Object, "rules")
+		for _, rule := range rules {
+			ruleMap, _ := rule.(map[string]interface{})
+			resources, _, _ := unstructured.NestedStringSlice(ruleMap, "resources")
+			for _, resource := range resources {
+				if resource == "podsecuritypolicies" {
+					return []LintFinding{{Rule: UpgradeRulePodSecurityPolicy, Kind: u.GetKind(), Name: u.GetName(), Message: message}}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkIngressClass returns a finding if the Ingress selects its controller with the class annotation instead of
+// spec.ingressClassName on a Kubernetes minor version whose ingress controllers only read the field
+func checkIngressClass(u *unstructured.Unstructured, minorVersion int) []LintFinding {
+	if u.GetKind() != "Ingress" || minorVersion < ingressClassAnnotationRemovedIn {
+		return nil
+	}
+	if className, _, _ := unstructured.NestedString(u.Object, "spec", "ingressClassName"); len(className) > 0 {
+		return nil
+	}
+	message := "no spec.ingressClassName, the Ingress is only served by the default IngressClass of the cluster"
+	if className, ok := u.GetAnnotations()[ingressClassAnnotation]; ok {
+		message = fmt.Sprintf("the %s annotation is deprecated since Kubernetes 1.18, set spec.ingressClassName to '%s'", ingressClassAnnotation, className)
+	}
+	return []LintFinding{{Rule: UpgradeRuleIngressClass, Kind: u.GetKind(), Name: u.GetName(), Message: message}}
+}
+
+// MinimumCompatibleChartURL returns the chart of the lowest version above the current version whose rendered objects
+// have no cluster upgrade findings for the Kubernetes version, or an empty string if no version is compatible. The
+// current version is the chart version of the instance, e.g. 5.3.1-12, and the render function renders the objects
+// of a chart URL with the values of the instance
+func MinimumCompatibleChartURL(chartURLs []string, chartName string, currentVersion string, kubeVersion string, render func(chartURL string) (string, error)) (string, error) {
+	currentProductVersion := ParsePackageName(fmt.Sprintf("%s-%s", chartName, currentVersion))[1]
+	candidates := [][]string{}
+	for _, url := range chartURLs {
+		parsed := ParsePackageName(url)
+		if parsed[0] == chartName && CompareVersions(parsed[1], currentProductVersion) > 0 {
+			candidates = append(candidates, []string{url, parsed[1], parsed[2]})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if compared := CompareVersions(candidates[i][1], candidates[j][1]); compared != 0 {
+			return compared < 0
+		}
+		iChartNum, _ := strconv.Atoi(candidates[i][2])
+		jChartNum, _ := strconv.Atoi(candidates[j][2])
+		return iChartNum < jChartNum
+	})
+	for _, candidate := range candidates {
+		manifest, err := render(candidate[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to render the chart at '%s' due to %+v", candidate[0], err)
+		}
+		findings, err := CheckClusterUpgrade(manifest, kubeVersion)
+		if err != nil {
+			return "", err
+		}
+		if len(findings) == 0 {
+			return candidate[0], nil
+		}
+	}
+	return "", nil
+}
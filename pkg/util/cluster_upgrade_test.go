@@ -0,0 +1,149 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const clusterUpgradeManifest = `apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: bd-psp
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: bd-psp-user
+rules:
+- apiGroups: ["policy"]
+  resources: ["podsecuritypolicies"]
+  verbs: ["use"]
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: bd-webserver
+  annotations:
+    kubernetes.io/ingress.class: nginx
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: bd-cleanup
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-webapp
+`
+
+func TestCheckClusterUpgrade(t *testing.T) {
+	assert := assert.New(t)
+
+	findings, err := CheckClusterUpgrade(clusterUpgradeManifest, "1.21")
+	assert.NoError(err)
+	assert.Empty(findings)
+
+	findings, err = CheckClusterUpgrade(clusterUpgradeManifest, "1.22")
+	assert.NoError(err)
+	assert.Equal([]string{"Ingress/bd-webserver/" + UpgradeRuleRemovedAPI, "Ingress/bd-webserver/" + UpgradeRuleIngressClass}, findingKeys(findings))
+	assert.Contains(findings[1].Message, "set spec.ingressClassName to 'nginx'")
+
+	findings, err = CheckClusterUpgrade(clusterUpgradeManifest, "v1.29.1")
+	assert.NoError(err)
+	assert.Equal([]string{
+		"CronJob/bd-cleanup/" + UpgradeRuleRemovedAPI,
+		"Ingress/bd-webserver/" + UpgradeRuleRemovedAPI,
+		"Ingress/bd-webserver/" + UpgradeRuleIngressClass,
+		"PodSecurityPolicy/bd-psp/" + UpgradeRuleRemovedAPI,
+		"PodSecurityPolicy/bd-psp/" + UpgradeRulePodSecurityPolicy,
+		"Role/bd-psp-user/" + UpgradeRulePodSecurityPolicy,
+	}, findingKeys(findings))
+
+	_, err = CheckClusterUpgrade(clusterUpgradeManifest, "")
+	assert.Error(err)
+	_, err = CheckClusterUpgrade(clusterUpgradeManifest, "2.0")
+	assert.Error(err)
+}
+
+func TestCheckIngressClass(t *testing.T) {
+	assert := assert.New(t)
+
+	withClass := "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\nspec:\n  ingressClassName: nginx\n"
+	findings, err := CheckClusterUpgrade(withClass, "1.29")
+	assert.NoError(err)
+	assert.Empty(findings)
+
+	withoutClass := "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\n"
+	findings, err = CheckClusterUpgrade(withoutClass, "1.29")
+	assert.NoError(err)
+	assert.Equal([]string{"Ingress/web/" + UpgradeRuleIngressClass}, findingKeys(findings))
+	assert.Contains(findings[0].Message, "default IngressClass")
+}
+
+func TestMinimumCompatibleChartURL(t *testing.T) {
+	assert := assert.New(t)
+
+	chartURLs := []string{
+		"https://repo/blackduck-2020.4.0.tgz",
+		"https://repo/blackduck-2020.6.0.tgz",
+		"https://repo/blackduck-2021.2.0.tgz",
+		"https://repo/blackduck-2020.10.0.tgz",
+		"https://repo/synopsys-alert-6.0.0.tgz",
+	}
+	manifests := map[string]string{
+		"https://repo/blackduck-2020.6.0.tgz":  "apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: cleanup\n",
+		"https://repo/blackduck-2020.10.0.tgz": "apiVersion: batch/v1\nkind: CronJob\nmetadata:\n  name: cleanup\n",
+		"https://repo/blackduck-2021.2.0.tgz":  "apiVersion: batch/v1\nkind: CronJob\nmetadata:\n  name: cleanup\n",
+	}
+	rendered := []string{}
+	render := func(chartURL string) (string, error) {
+		rendered = append(rendered, chartURL)
+		return manifests[chartURL], nil
+	}
+
+	chartURL, err := MinimumCompatibleChartURL(chartURLs, "blackduck", "2020.4.0", "1.25", render)
+	assert.NoError(err)
+	assert.Equal("https://repo/blackduck-2020.10.0.tgz", chartURL)
+	assert.Equal([]string{"https://repo/blackduck-2020.6.0.tgz", "https://repo/blackduck-2020.10.0.tgz"}, rendered)
+
+	chartURL, err = MinimumCompatibleChartURL(chartURLs, "blackduck", "2021.2.0", "1.25", render)
+	assert.NoError(err)
+	assert.Empty(chartURL)
+
+	failing := func(chartURL string) (string, error) { return "", fmt.Errorf("not found") }
+	_, err = MinimumCompatibleChartURL(chartURLs, "blackduck", "2020.4.0", "1.25", failing)
+	assert.Error(err)
+}
+
+// findingKeys returns the kind, name and rule of each finding
+func findingKeys(findings []LintFinding) []string {
+	keys := []string{}
+	for _, finding := range findings {
+		keys = append(keys, fmt.Sprintf("%s/%s/%s", finding.Kind, finding.Name, finding.Rule))
+	}
+	return keys
+}
@@ -24,7 +24,6 @@ package util
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"reflect"
 	"strings"
 	"time"
@@ -71,6 +70,8 @@ const (
 	NODEPORT = "NODEPORT"
 	// LOADBALANCER denotes to create a LoadBalancer service
 	LOADBALANCER = "LOADBALANCER"
+	// INGRESS denotes to create an Ingress
+	INGRESS = "INGRESS"
 )
 
 // CreateContainer will create the container
@@ -344,7 +345,7 @@ func CreateServiceWithMultiplePort(name string, selectLabel map[string]string, n
 
 // CreateSecretFromFile will create the secret from file
 func CreateSecretFromFile(clientset *kubernetes.Clientset, jsonFile string, namespace string, name string, dataKey string) (*corev1.Secret, error) {
-	file, err := ioutil.ReadFile(jsonFile)
+	file, err := ReadFromFile(jsonFile)
 	if err != nil {
 		log.Panicf("Unable to read the secret file %s due to error: %v\n", jsonFile, err)
 	}
@@ -375,7 +376,7 @@ func GetSecret(clientset *kubernetes.Clientset, namespace string, name string) (
 }
 
 // ListSecrets will list the secret
-func ListSecrets(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.SecretList, error) {
+func ListSecrets(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.SecretList, error) {
 	return clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -385,7 +386,7 @@ func UpdateSecret(clientset *kubernetes.Clientset, namespace string, secret *cor
 }
 
 // DeleteSecret will delete the secret
-func DeleteSecret(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteSecret(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -395,7 +396,7 @@ func GetConfigMap(clientset *kubernetes.Clientset, namespace string, name string
 }
 
 // ListConfigMaps will list the config map
-func ListConfigMaps(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ConfigMapList, error) {
+func ListConfigMaps(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ConfigMapList, error) {
 	return clientset.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -405,7 +406,7 @@ func UpdateConfigMap(clientset *kubernetes.Clientset, namespace string, configMa
 }
 
 // DeleteConfigMap will delete the config map
-func DeleteConfigMap(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteConfigMap(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().ConfigMaps(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -468,7 +469,7 @@ func GetReplicationController(clientset *kubernetes.Clientset, namespace string,
 }
 
 // ListReplicationControllers will get the replication controllers corresponding to a namespace
-func ListReplicationControllers(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ReplicationControllerList, error) {
+func ListReplicationControllers(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ReplicationControllerList, error) {
 	return clientset.CoreV1().ReplicationControllers(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -478,7 +479,7 @@ func UpdateReplicationController(clientset *kubernetes.Clientset, namespace stri
 }
 
 // DeleteReplicationController will delete the replication controller corresponding to a namespace and name
-func DeleteReplicationController(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteReplicationController(clientset kubernetes.Interface, namespace string, name string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return clientset.CoreV1().ReplicationControllers(namespace).Delete(name, &metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
@@ -491,7 +492,7 @@ func GetDeployment(clientset *kubernetes.Clientset, namespace string, name strin
 }
 
 // ListDeployments will get all the deployments corresponding to a namespace
-func ListDeployments(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*appsv1.DeploymentList, error) {
+func ListDeployments(clientset kubernetes.Interface, namespace string, labelSelector string) (*appsv1.DeploymentList, error) {
 	return clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -501,7 +502,7 @@ func UpdateDeployment(clientset *kubernetes.Clientset, namespace string, deploym
 }
 
 // DeleteDeployment will delete the deployment corresponding to a namespace and name
-func DeleteDeployment(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteDeployment(clientset kubernetes.Interface, namespace string, name string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return clientset.AppsV1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
@@ -591,7 +592,7 @@ func GetService(clientset *kubernetes.Clientset, namespace string, serviceName s
 }
 
 // ListServices will list the service information for the input service name inside the input namespace
-func ListServices(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ServiceList, error) {
+func ListServices(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ServiceList, error) {
 	return clientset.CoreV1().Services(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -633,7 +634,7 @@ func UpdateService(clientset *kubernetes.Clientset, namespace string, service *c
 }
 
 // DeleteService will delete the service information for the input service name inside the input namespace
-func DeleteService(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteService(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().Services(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -653,7 +654,7 @@ func GetPVC(clientset *kubernetes.Clientset, namespace string, name string) (*co
 }
 
 // ListPVCs will list the PVC for the given label selector
-func ListPVCs(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.PersistentVolumeClaimList, error) {
+func ListPVCs(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.PersistentVolumeClaimList, error) {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -663,7 +664,7 @@ func UpdatePVC(clientset *kubernetes.Clientset, namespace string, pvc *corev1.Pe
 }
 
 // DeletePVC will delete the PVC information for the input pvc name inside the input namespace
-func DeletePVC(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeletePVC(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -941,7 +942,7 @@ func GetServiceAccount(clientset *kubernetes.Clientset, namespace string, name s
 }
 
 // ListServiceAccounts list a service account
-func ListServiceAccounts(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ServiceAccountList, error) {
+func ListServiceAccounts(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ServiceAccountList, error) {
 	return clientset.CoreV1().ServiceAccounts(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -951,7 +952,7 @@ func UpdateServiceAccount(clientset *kubernetes.Clientset, namespace string, ser
 }
 
 // DeleteServiceAccount delete a service account
-func DeleteServiceAccount(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteServiceAccount(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().ServiceAccounts(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -1540,21 +1541,26 @@ func GetOperatorRoleBindings(clientset *kubernetes.Clientset, namespace string)
 
 // GetKubernetesVersion will return the kubernetes version
 func GetKubernetesVersion(clientset *kubernetes.Clientset) (string, error) {
-	k, err := clientset.Discovery().ServerVersion()
-	if k != nil {
-		return k.GitVersion, nil
+	info, err := GetClusterInfo(clientset)
+	if err != nil {
+		return "", err
 	}
-	return "", err
+	return info.ServerVersion, nil
 }
 
-// IsOpenshift will whether it is an openshift cluster
+// IsOpenshift will whether it is an openshift cluster, unless the type of the cluster is set by ClusterTypeOverride
 func IsOpenshift(clientset *kubernetes.Clientset) bool {
-	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/").Do().Raw()
+	if len(ClusterTypeOverride) > 0 {
+		return ClusterTypeOverride == ClusterTypeOpenShift
+	}
+	info, err := GetClusterInfo(clientset)
 	if err != nil {
+		// remember the decision so that all the resources and Helm values of the command agree on the type
+		log.Warnf("assuming a Kubernetes cluster: %+v", err)
+		SetClusterInfo(clientset, &ClusterInfo{DiscoveredAt: time.Now()})
 		return false
 	}
-
-	return strings.Contains(string(body), "openshift")
+	return info.IsOpenShift
 }
 
 // IsOperatorExist returns whether the operator exist or not
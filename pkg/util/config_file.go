@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the synopsysctl config file in the home directory
+const ConfigFileName = ".synopsysctl.yaml"
+
+// ReadConfigFile returns the settings of the synopsysctl config file, which are empty if the file doesn't exist
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config file '%s' due to %+v", path, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the config file '%s' due to %+v", path, err)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	return config, nil
+}
+
+// WriteConfigFile writes the settings to the synopsysctl config file, which is only readable by the user since it can
+// contain the paths and the registries of an environment
+func WriteConfigFile(path string, config map[string]interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the config due to %+v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the config file '%s' due to %+v", path, err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write the config file '%s' due to %+v", path, err)
+	}
+	return nil
+}
+
+// configKeyList splits a key of the config file at the dots, e.g. 'blackduck.version'
+func configKeyList(key string) ([]string, error) {
+	keyList := strings.Split(key, ".")
+	for _, k := range keyList {
+		if len(k) == 0 {
+			return nil, ValidationError("invalid config key '%s'", key)
+		}
+	}
+	return keyList, nil
+}
+
+// GetConfigValue returns the value of a key of the config file, the key of a nested setting is separated by dots
+func GetConfigValue(config map[string]interface{}, key string) (interface{}, bool) {
+	keyList, err := configKeyList(key)
+	if err != nil {
+		return nil, false
+	}
+	value := GetHelmValueFromMap(config, keyList)
+	return value, value != nil
+}
+
+// SetConfigValue sets the value of a key of the config file, the key of a nested setting is separated by dots
+func SetConfigValue(config map[string]interface{}, key string, value interface{}) error {
+	keyList, err := configKeyList(key)
+	if err != nil {
+		return err
+	}
+	for i, k := range keyList[:len(keyList)-1] {
+		next, ok := config[k]
+		if !ok || next == nil {
+			next = map[string]interface{}{}
+			config[k] = next
+		}
+		if config, ok = next.(map[string]interface{}); !ok {
+			return ValidationError("cannot set '%s' because '%s' is not a section", key, strings.Join(keyList[:i+1], "."))
+		}
+	}
+	config[keyList[len(keyList)-1]] = value
+	return nil
+}
+
+// UnsetConfigValue removes a key from the config file and returns false if the key isn't set. Sections that become
+// empty are removed as well
+func UnsetConfigValue(config map[string]interface{}, key string) bool {
+	keyList, err := configKeyList(key)
+	if err != nil {
+		return false
+	}
+	return unsetConfigKeyList(config, keyList)
+}
+
+func unsetConfigKeyList(config map[string]interface{}, keyList []string) bool {
+	value, ok := config[keyList[0]]
+	if !ok {
+		return false
+	}
+	if len(keyList) == 1 {
+		delete(config, keyList[0])
+		return true
+	}
+	section, ok := value.(map[string]interface{})
+	if !ok || !unsetConfigKeyList(section, keyList[1:]) {
+		return false
+	}
+	if len(section) == 0 {
+		delete(config, keyList[0])
+	}
+	return true
+}
+
+// FormatConfigValue returns a value of the config file as the value of a flag, a list is joined with commas
+func FormatConfigValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return "", false
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(items, ","), true
+	case float64:
+		// the numbers of YAML are decoded as float64
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return fmt.Sprintf("%v", value), true
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValues(t *testing.T) {
+	assert := assert.New(t)
+
+	config := map[string]interface{}{}
+	assert.NoError(SetConfigValue(config, "namespace", "synopsys"))
+	assert.NoError(SetConfigValue(config, "blackduck.version", "2020.6.0"))
+	assert.NoError(SetConfigValue(config, "blackduck.registry", "registry.example.com"))
+	assert.Equal(map[string]interface{}{
+		"namespace": "synopsys",
+		"blackduck": map[string]interface{}{"version": "2020.6.0", "registry": "registry.example.com"},
+	}, config)
+
+	value, ok := GetConfigValue(config, "blackduck.version")
+	assert.True(ok)
+	assert.Equal("2020.6.0", value)
+	_, ok = GetConfigValue(config, "alert.version")
+	assert.False(ok)
+	_, ok = GetConfigValue(config, "blackduck..version")
+	assert.False(ok)
+
+	err := SetConfigValue(config, "namespace.name", "other")
+	assert.EqualError(err, "cannot set 'namespace.name' because 'namespace' is not a section")
+
+	assert.True(UnsetConfigValue(config, "blackduck.version"))
+	assert.True(UnsetConfigValue(config, "blackduck.registry"))
+	assert.False(UnsetConfigValue(config, "blackduck.registry"))
+	assert.Equal(map[string]interface{}{"namespace": "synopsys"}, config)
+}
+
+func TestFormatConfigValue(t *testing.T) {
+	assert := assert.New(t)
+
+	var tests = []struct {
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{value: "2020.6.0", expected: "2020.6.0", ok: true},
+		{value: true, expected: "true", ok: true},
+		{value: float64(3), expected: "3", ok: true},
+		{value: 1.5, expected: "1.5", ok: true},
+		{value: []interface{}{"a", "b"}, expected: "a,b", ok: true},
+		{value: map[string]interface{}{"version": "1"}, expected: "", ok: false},
+	}
+	for _, test := range tests {
+		formatted, ok := FormatConfigValue(test.value)
+		assert.Equal(test.expected, formatted)
+		assert.Equal(test.ok, ok)
+	}
+}
+
+func TestReadWriteConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-config")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ConfigFileName)
+
+	config, err := ReadConfigFile(path)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{}, config)
+
+	assert.NoError(SetConfigValue(config, "blackduck.version", "2020.6.0"))
+	assert.NoError(WriteConfigFile(path, config))
+	info, err := os.Stat(path)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	read, err := ReadConfigFile(path)
+	assert.NoError(err)
+	assert.Equal(config, read)
+}
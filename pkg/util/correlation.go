@@ -0,0 +1,174 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogFieldCorrelationID is the structured field of the correlation ID of the command in the log messages
+const LogFieldCorrelationID = "correlationID"
+
+// CorrelationIDAnnotation is the annotation of the Kubernetes Events with the correlation ID of the command
+const CorrelationIDAnnotation = "synopsys.com/correlation-id"
+
+// Reasons of the Kubernetes Events of the Helm operations
+const (
+	EventReasonInstall   = "SynopsysctlInstall"
+	EventReasonUpgrade   = "SynopsysctlUpgrade"
+	EventReasonRollback  = "SynopsysctlRollback"
+	EventReasonUninstall = "SynopsysctlUninstall"
+)
+
+// CorrelationID identifies the invocation of a command in its logs, plans, Kubernetes Events and Helm release
+// descriptions, so that everything a single run changed can be traced. It is set by --correlation-id, e.g. to continue
+// the ID of a pipeline, or generated by InitCorrelationID
+var CorrelationID = ""
+
+var correlationIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,63}$`)
+
+// InitCorrelationID validates the correlation ID of the command, or generates a new one if it is not set
+func InitCorrelationID(id string) error {
+	if len(id) > 0 {
+		if !correlationIDRegexp.MatchString(id) {
+			return ValidationError("--correlation-id must be at most 63 letters, digits, '.', '_', ':' or '-', got '%s'", id)
+		}
+		CorrelationID = id
+		return nil
+	}
+	generated, err := NewCorrelationID()
+	if err != nil {
+		return err
+	}
+	CorrelationID = generated
+	return nil
+}
+
+// NewCorrelationID returns a random version 4 UUID
+func NewCorrelationID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate a correlation ID due to %+v", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// correlationIDHook adds the correlation ID to the log messages. The text messages only show it on warnings and
+// errors, so that a failure can be reported with its ID without cluttering the normal output
+type correlationIDHook struct {
+	allLevels bool
+}
+
+// Levels returns the log levels of the hook
+func (h *correlationIDHook) Levels() []log.Level {
+	if h.allLevels {
+		return log.AllLevels
+	}
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel}
+}
+
+// Fire adds the correlation ID field to the entry
+func (h *correlationIDHook) Fire(entry *log.Entry) error {
+	if len(CorrelationID) > 0 {
+		entry.Data[LogFieldCorrelationID] = CorrelationID
+	}
+	return nil
+}
+
+// describeWithCorrelationID appends the correlation ID to the description of a Helm release
+func describeWithCorrelationID(description string) string {
+	if len(CorrelationID) == 0 {
+		return description
+	}
+	return fmt.Sprintf("%s (correlation ID %s)", description, CorrelationID)
+}
+
+// annotateReleaseForActionConfig adds the correlation ID to the description of the release revision and records a
+// Kubernetes Event of the operation. A failure only warns, the operation itself succeeded
+func annotateReleaseForActionConfig(actionConfig *action.Configuration, namespace string, rel *release.Release, reason string, message string) {
+	if rel != nil && rel.Info != nil {
+		rel.Info.Description = describeWithCorrelationID(rel.Info.Description)
+		if err := actionConfig.Releases.Update(rel); err != nil {
+			log.Warnf("unable to add the correlation ID to the description of release '%s' due to %+v", rel.Name, err)
+		}
+	}
+	recordReleaseEventForActionConfig(actionConfig, namespace, reason, message)
+}
+
+// recordReleaseEventForActionConfig records a Kubernetes Event of a Helm operation with a client of the Helm action
+// configuration
+func recordReleaseEventForActionConfig(actionConfig *action.Configuration, namespace string, reason string, message string) {
+	clientset, err := clientsetForActionConfig(actionConfig)
+	if err == nil {
+		err = RecordOperationEvent(clientset, namespace, reason, message)
+	}
+	if err != nil {
+		log.Warnf("unable to record the event '%s' in namespace '%s' due to %+v", reason, namespace, err)
+	}
+}
+
+// NewOperationEvent returns a Kubernetes Event of an operation of synopsysctl on the namespace, annotated with the
+// correlation ID of the command
+func NewOperationEvent(namespace string, reason string, message string, now time.Time) *corev1.Event {
+	timestamp := metav1.NewTime(now)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("synopsysctl.%x", now.UnixNano()),
+			Namespace:   namespace,
+			Annotations: map[string]string{},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       namespace,
+		},
+		Reason:         reason,
+		Message:        describeWithCorrelationID(message),
+		Source:         corev1.EventSource{Component: "synopsysctl"},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+		Type:           corev1.EventTypeNormal,
+	}
+	if len(CorrelationID) > 0 {
+		event.Annotations[CorrelationIDAnnotation] = CorrelationID
+	}
+	return event
+}
+
+// RecordOperationEvent creates a Kubernetes Event of an operation of synopsysctl in the namespace
+func RecordOperationEvent(clientset *kubernetes.Clientset, namespace string, reason string, message string) error {
+	_, err := clientset.CoreV1().Events(namespace).Create(NewOperationEvent(namespace, reason, message, time.Now()))
+	return err
+}
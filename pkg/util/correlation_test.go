@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitCorrelationID(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { CorrelationID = "" }()
+
+	assert.NoError(InitCorrelationID("pipeline-42.run:1"))
+	assert.Equal("pipeline-42.run:1", CorrelationID)
+
+	assert.Error(InitCorrelationID("not valid"))
+	assert.Equal("pipeline-42.run:1", CorrelationID)
+
+	assert.NoError(InitCorrelationID(""))
+	assert.Regexp(regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), CorrelationID)
+	generated := CorrelationID
+	assert.NoError(InitCorrelationID(""))
+	assert.NotEqual(generated, CorrelationID)
+}
+
+func TestCorrelationIDHook(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { CorrelationID = "" }()
+
+	entry := log.NewEntry(log.StandardLogger())
+	assert.NoError((&correlationIDHook{}).Fire(entry))
+	assert.NotContains(entry.Data, LogFieldCorrelationID)
+
+	CorrelationID = "abc"
+	assert.NoError((&correlationIDHook{}).Fire(entry))
+	assert.Equal("abc", entry.Data[LogFieldCorrelationID])
+
+	assert.NotContains((&correlationIDHook{}).Levels(), log.InfoLevel)
+	assert.Contains((&correlationIDHook{}).Levels(), log.ErrorLevel)
+	assert.Contains((&correlationIDHook{allLevels: true}).Levels(), log.InfoLevel)
+}
+
+func TestNewOperationEvent(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { CorrelationID = "" }()
+
+	event := NewOperationEvent("ns", EventReasonUpgrade, "upgraded release 'bd'", time.Unix(0, 255))
+	assert.Equal("upgraded release 'bd'", event.Message)
+	assert.Empty(event.Annotations)
+
+	CorrelationID = "abc"
+	event = NewOperationEvent("ns", EventReasonUpgrade, "upgraded release 'bd'", time.Unix(0, 255))
+	assert.Equal("synopsysctl.ff", event.Name)
+	assert.Equal("ns", event.Namespace)
+	assert.Equal("Namespace", event.InvolvedObject.Kind)
+	assert.Equal("ns", event.InvolvedObject.Name)
+	assert.Equal(EventReasonUpgrade, event.Reason)
+	assert.Equal("upgraded release 'bd' (correlation ID abc)", event.Message)
+	assert.Equal("abc", event.Annotations[CorrelationIDAnnotation])
+}
+
+func TestDescribeWithCorrelationID(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { CorrelationID = "" }()
+
+	assert.Equal("Upgrade complete", describeWithCorrelationID("Upgrade complete"))
+	CorrelationID = "abc"
+	assert.Equal("Upgrade complete (correlation ID abc)", describeWithCorrelationID("Upgrade complete"))
+}
@@ -0,0 +1,280 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// Sources of the objects of a delete report
+const (
+	DeleteSourceHelm      = "helm"
+	DeleteSourceLabels    = "labels"
+	DeleteSourceExposure  = "exposure"
+	DeleteSourceSecret    = "secret"
+	DeleteSourceNamespace = "namespace"
+)
+
+// DeleteReport is the list of everything that a delete command would remove from the cluster, suitable as an
+// attachment of a change ticket
+type DeleteReport struct {
+	Command          string                `json:"command"`
+	CreatedAt        time.Time             `json:"createdAt"`
+	Releases         []DeleteReportRelease `json:"releases"`
+	Objects          []DeleteReportObject  `json:"objects"`
+	StorageCapacity  string                `json:"storageCapacity"`
+	PersistentClaims int                   `json:"persistentVolumeClaims"`
+}
+
+// DeleteReportRelease is a Helm release that the delete command would uninstall
+type DeleteReportRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Chart     string `json:"chart,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+}
+
+// DeleteReportObject is an object that the delete command would remove, with the storage details of a PVC
+type DeleteReportObject struct {
+	Kind          string `json:"kind"`
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace,omitempty"`
+	Source        string `json:"source"`
+	Capacity      string `json:"capacity,omitempty"`
+	StorageClass  string `json:"storageClass,omitempty"`
+	VolumeName    string `json:"volumeName,omitempty"`
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+}
+
+// NewDeleteReport returns an empty delete report of the command
+func NewDeleteReport(command string) *DeleteReport {
+	return &DeleteReport{
+		Command:   command,
+		CreatedAt: time.Now().UTC(),
+		Releases:  []DeleteReportRelease{},
+		Objects:   []DeleteReportObject{},
+	}
+}
+
+// AddObject adds an object to the report, an object that is already in it keeps its first source
+func (r *DeleteReport) AddObject(kind string, name string, namespace string, source string) {
+	for _, object := range r.Objects {
+		if object.Kind == kind && object.Name == name && object.Namespace == namespace {
+			return
+		}
+	}
+	r.Objects = append(r.Objects, DeleteReportObject{Kind: kind, Name: name, Namespace: namespace, Source: source})
+}
+
+// AddRelease adds a Helm release and the objects of its manifest to the report
+func (r *DeleteReport) AddRelease(name string, namespace string, chart string, revision int, manifest string) error {
+	r.Releases = append(r.Releases, DeleteReportRelease{Name: name, Namespace: namespace, Chart: chart, Revision: revision})
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return fmt.Errorf("couldn't read the manifest of release '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		objectNamespace := u.GetNamespace()
+		if len(objectNamespace) == 0 && !isClusterScopedKind(u.GetKind()) {
+			objectNamespace = namespace
+		}
+		r.AddObject(u.GetKind(), u.GetName(), objectNamespace, DeleteSourceHelm)
+	}
+	return nil
+}
+
+// isClusterScopedKind returns true for the cluster scoped kinds that the charts contain
+func isClusterScopedKind(kind string) bool {
+	switch kind {
+	case "ClusterRole", "ClusterRoleBinding", "Namespace", "PersistentVolume", "StorageClass", "CustomResourceDefinition":
+		return true
+	}
+	return false
+}
+
+// AddLabeledResources adds the namespaced resources that DeleteResourcesWithLabels would delete
+func (r *DeleteReport) AddLabeledResources(clientset kubernetes.Interface, namespace string, app string, name string) error {
+	resources, err := ListResourcesWithLabels(clientset, namespace, app, name)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		r.AddObject(resource.Kind, resource.Name, namespace, DeleteSourceLabels)
+	}
+	return nil
+}
+
+// AddPVCs adds the PVCs in the namespace that match the label selector
+func (r *DeleteReport) AddPVCs(clientset *kubernetes.Clientset, namespace string, labelSelector string, source string) error {
+	pvcs, err := ListPVCs(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list pvc in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, pvc := range pvcs.Items {
+		r.AddObject("PersistentVolumeClaim", pvc.Name, namespace, source)
+	}
+	return nil
+}
+
+// AddExposure adds the service, the ingress and the route that DeleteExposure would delete
+func (r *DeleteReport) AddExposure(restConfig *rest.Config, clientset *kubernetes.Clientset, e Exposure) error {
+	objects, err := ListExposureObjects(restConfig, clientset, e)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		r.AddObject(object.Kind, object.Name, e.Namespace, DeleteSourceExposure)
+	}
+	return nil
+}
+
+// AddSecrets adds the secrets that exist of the ones that synopsysctl creates outside of the chart
+func (r *DeleteReport) AddSecrets(clientset *kubernetes.Clientset, namespace string, names ...string) error {
+	for _, name := range names {
+		if _, err := GetSecret(clientset, namespace, name); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get secret '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		r.AddObject("Secret", name, namespace, DeleteSourceSecret)
+	}
+	return nil
+}
+
+// AddNamespace adds a namespace and its PVCs, which are deleted with it
+func (r *DeleteReport) AddNamespace(clientset *kubernetes.Clientset, namespace string) error {
+	r.AddObject("Namespace", namespace, "", DeleteSourceNamespace)
+	return r.AddPVCs(clientset, namespace, "", DeleteSourceNamespace)
+}
+
+// AddPVCDetails adds the capacity, the storage class and the reclaim policy of the volume of the PVCs of the report
+// and sums up their capacity. A volume with the Retain policy outlives the delete
+func (r *DeleteReport) AddPVCDetails(clientset *kubernetes.Clientset) error {
+	for i, object := range r.Objects {
+		if object.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+		pvc, err := GetPVC(clientset, object.Namespace, object.Name)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get pvc '%s' in namespace '%s' due to %+v", object.Name, object.Namespace, err)
+		}
+		if capacity, ok := pvc.Status.Capacity["storage"]; ok {
+			r.Objects[i].Capacity = capacity.String()
+		} else if request, ok := pvc.Spec.Resources.Requests["storage"]; ok {
+			r.Objects[i].Capacity = request.String()
+		}
+		if pvc.Spec.StorageClassName != nil {
+			r.Objects[i].StorageClass = *pvc.Spec.StorageClassName
+		}
+		r.Objects[i].VolumeName = pvc.Spec.VolumeName
+		if len(pvc.Spec.VolumeName) > 0 {
+			pv, err := clientset.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("couldn't get persistent volume '%s' due to %+v", pvc.Spec.VolumeName, err)
+			} else if err == nil {
+				r.Objects[i].ReclaimPolicy = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			}
+		}
+	}
+	return r.sumStorageCapacity()
+}
+
+// sumStorageCapacity sets the number and the total capacity of the PVCs of the report
+func (r *DeleteReport) sumStorageCapacity() error {
+	total := resource.Quantity{}
+	r.PersistentClaims = 0
+	for _, object := range r.Objects {
+		if object.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+		r.PersistentClaims++
+		if len(object.Capacity) == 0 {
+			continue
+		}
+		capacity, err := resource.ParseQuantity(object.Capacity)
+		if err != nil {
+			return fmt.Errorf("couldn't parse the capacity '%s' of pvc '%s' due to %+v", object.Capacity, object.Name, err)
+		}
+		total.Add(capacity)
+	}
+	r.StorageCapacity = total.String()
+	return nil
+}
+
+// Print writes the report as a table, as JSON or as YAML, sorted by namespace, kind and name
+func (r *DeleteReport) Print(w io.Writer, format string) error {
+	sort.SliceStable(r.Objects, func(i, j int) bool {
+		a, b := r.Objects[i], r.Objects[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "yaml":
+		out, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	case "", "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintf(tw, "DELETE REPORT OF '%s' (%s)\n", r.Command, r.CreatedAt.Format(time.RFC3339))
+		for _, release := range r.Releases {
+			fmt.Fprintf(tw, "HELM RELEASE '%s' IN NAMESPACE '%s'\t%s\trevision %d\n", release.Name, release.Namespace, release.Chart, release.Revision)
+		}
+		fmt.Fprintln(tw, "KIND\tNAME\tNAMESPACE\tSOURCE\tCAPACITY\tSTORAGE CLASS\tRECLAIM POLICY")
+		for _, object := range r.Objects {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", object.Kind, object.Name, object.Namespace, object.Source, object.Capacity, object.StorageClass, object.ReclaimPolicy)
+		}
+		fmt.Fprintf(tw, "TOTAL\t%d objects\t\t\t%s in %d pvc\t\t\n", len(r.Objects), r.StorageCapacity, r.PersistentClaims)
+		return tw.Flush()
+	default:
+		return ValidationError("report format must be 'table', 'json' or 'yaml', but got '%s'", format)
+	}
+}
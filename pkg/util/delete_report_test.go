@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteReportAddRelease(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: postgres
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: binding
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webserver
+  namespace: other
+`
+	report := NewDeleteReport("delete blackduck bd")
+	assert.NoError(t, report.AddRelease("bd", "ns", "blackduck-2020.6.0", 2, manifest))
+	report.AddObject("Service", "webserver", "other", DeleteSourceExposure)
+	report.AddObject("Secret", "bd-blackduck-webserver-certificate", "ns", DeleteSourceSecret)
+	assert.Equal(t, []DeleteReportRelease{{Name: "bd", Namespace: "ns", Chart: "blackduck-2020.6.0", Revision: 2}}, report.Releases)
+	assert.Equal(t, []DeleteReportObject{
+		{Kind: "PersistentVolumeClaim", Name: "postgres", Namespace: "ns", Source: DeleteSourceHelm},
+		{Kind: "ClusterRoleBinding", Name: "binding", Source: DeleteSourceHelm},
+		{Kind: "Service", Name: "webserver", Namespace: "other", Source: DeleteSourceHelm},
+		{Kind: "Secret", Name: "bd-blackduck-webserver-certificate", Namespace: "ns", Source: DeleteSourceSecret},
+	}, report.Objects)
+}
+
+func TestDeleteReportSumStorageCapacity(t *testing.T) {
+	assert := assert.New(t)
+	report := NewDeleteReport("delete blackduck bd")
+	report.Objects = []DeleteReportObject{
+		{Kind: "PersistentVolumeClaim", Name: "postgres", Namespace: "ns", Capacity: "150Gi"},
+		{Kind: "PersistentVolumeClaim", Name: "uploadcache", Namespace: "ns", Capacity: "100Gi"},
+		{Kind: "PersistentVolumeClaim", Name: "pending", Namespace: "ns"},
+		{Kind: "ConfigMap", Name: "config", Namespace: "ns"},
+	}
+	assert.NoError(report.sumStorageCapacity())
+	assert.Equal("250Gi", report.StorageCapacity)
+	assert.Equal(3, report.PersistentClaims)
+
+	report.Objects[0].Capacity = "lots"
+	assert.Error(report.sumStorageCapacity())
+}
+
+func TestDeleteReportPrint(t *testing.T) {
+	assert := assert.New(t)
+	report := NewDeleteReport("delete blackduck bd")
+	report.AddObject("Service", "webserver", "ns", DeleteSourceExposure)
+	report.AddObject("ConfigMap", "config", "ns", DeleteSourceHelm)
+	report.AddObject("Namespace", "ns", "", DeleteSourceNamespace)
+
+	var out bytes.Buffer
+	assert.NoError(report.Print(&out, "json"))
+	printed := DeleteReport{}
+	assert.NoError(json.Unmarshal(out.Bytes(), &printed))
+	assert.Equal("delete blackduck bd", printed.Command)
+	assert.Equal([]string{"Namespace", "ConfigMap", "Service"}, []string{printed.Objects[0].Kind, printed.Objects[1].Kind, printed.Objects[2].Kind})
+
+	out.Reset()
+	assert.NoError(report.Print(&out, "yaml"))
+	assert.True(strings.Contains(out.String(), "source: exposure"))
+
+	out.Reset()
+	assert.NoError(report.Print(&out, "table"))
+	assert.True(strings.Contains(out.String(), "DELETE REPORT OF 'delete blackduck bd'"))
+
+	assert.Error(report.Print(&out, "xml"))
+}
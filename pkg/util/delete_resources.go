@@ -0,0 +1,169 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LabeledResource is a namespaced resource of an instance that DeleteResourcesWithLabels deletes
+type LabeledResource struct {
+	Kind string
+	Name string
+}
+
+// legacyOperatorNames are the names, after the <name>-<app>- prefix, of the config maps, the secrets and the service
+// accounts that a legacy operator created for an instance. Other resources of these kinds with the labels of the
+// instance, e.g. the secrets of backups or seeds, are kept
+var legacyOperatorNames = map[string][]string{
+	"ConfigMap":      {"config", "db-config", "postgres-init-config"},
+	"Secret":         {"db-creds", "upload-cache"},
+	"ServiceAccount": {"service-account"},
+}
+
+// isLegacyOperatorResource returns true if the resource of the kind is named like a resource that a legacy operator
+// created for the instance. The deployments, the replication controllers and the services other than the exposed ones
+// were all created by the operator, the exposed services are deleted with the exposure of the instance
+func isLegacyOperatorResource(kind string, resourceName string, app string, name string) bool {
+	prefix := fmt.Sprintf("%s-%s-", name, app)
+	if !strings.HasPrefix(resourceName, prefix) {
+		return false
+	}
+	switch kind {
+	case "Deployment", "ReplicationController":
+		return true
+	case "Service":
+		return !strings.HasSuffix(resourceName, exposedSuffix)
+	}
+	for _, legacyName := range legacyOperatorNames[kind] {
+		if resourceName == prefix+legacyName {
+			return true
+		}
+	}
+	return false
+}
+
+// ListResourcesWithLabels returns the namespaced resources of an instance that DeleteResourcesWithLabels deletes: the
+// resources with the labels of the instance that a legacy operator created, and its persistent volume claims
+func ListResourcesWithLabels(clientset kubernetes.Interface, namespace string, app string, name string) ([]LabeledResource, error) {
+	labelSelector := fmt.Sprintf("app=%s, name=%s", app, name)
+	resources := []LabeledResource{}
+	add := func(kind string, resourceName string) {
+		if isLegacyOperatorResource(kind, resourceName, app, name) {
+			resources = append(resources, LabeledResource{Kind: kind, Name: resourceName})
+		}
+	}
+
+	deployments, err := ListDeployments(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list deployments in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, deployment := range deployments.Items {
+		add("Deployment", deployment.Name)
+	}
+
+	rcs, err := ListReplicationControllers(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list replication controllers in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, rc := range rcs.Items {
+		add("ReplicationController", rc.Name)
+	}
+
+	services, err := ListServices(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list services in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, service := range services.Items {
+		add("Service", service.Name)
+	}
+
+	configMaps, err := ListConfigMaps(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list config maps in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, configMap := range configMaps.Items {
+		add("ConfigMap", configMap.Name)
+	}
+
+	secrets, err := ListSecrets(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list secrets in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, secret := range secrets.Items {
+		add("Secret", secret.Name)
+	}
+
+	serviceAccounts, err := ListServiceAccounts(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list service accounts in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, serviceAccount := range serviceAccounts.Items {
+		add("ServiceAccount", serviceAccount.Name)
+	}
+
+	pvcs, err := ListPVCs(clientset, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list pvc in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, pvc := range pvcs.Items {
+		resources = append(resources, LabeledResource{Kind: "PersistentVolumeClaim", Name: pvc.Name})
+	}
+	return resources, nil
+}
+
+// DeleteResourcesWithLabels deletes the resources of an instance that a legacy operator created and its persistent
+// volume claims, without deleting the namespace and the other workloads and secrets in it
+func DeleteResourcesWithLabels(clientset kubernetes.Interface, namespace string, app string, name string) error {
+	resources, err := ListResourcesWithLabels(clientset, namespace, app, name)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		log.Debugf("deleting %s '%s' in namespace '%s'", resource.Kind, resource.Name, namespace)
+		switch resource.Kind {
+		case "Deployment":
+			err = DeleteDeployment(clientset, namespace, resource.Name)
+		case "ReplicationController":
+			err = DeleteReplicationController(clientset, namespace, resource.Name)
+		case "Service":
+			err = DeleteService(clientset, namespace, resource.Name)
+		case "ConfigMap":
+			err = DeleteConfigMap(clientset, namespace, resource.Name)
+		case "Secret":
+			err = DeleteSecret(clientset, namespace, resource.Name)
+		case "ServiceAccount":
+			err = DeleteServiceAccount(clientset, namespace, resource.Name)
+		case "PersistentVolumeClaim":
+			err = DeletePVC(clientset, namespace, resource.Name)
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete %s '%s' in namespace '%s' due to %+v", strings.ToLower(resource.Kind), resource.Name, namespace, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// labeledObjectMeta returns the metadata of an object of the Black Duck instance 'bd' in namespace 'ns'
+func labeledObjectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: map[string]string{"app": BlackDuckName, "name": "bd"}}
+}
+
+func newLabeledResourcesClientset() *fake.Clientset {
+	objects := []runtime.Object{
+		// resources of the legacy operator
+		&appsv1.Deployment{ObjectMeta: labeledObjectMeta("bd-blackduck-webserver")},
+		&corev1.ReplicationController{ObjectMeta: labeledObjectMeta("bd-blackduck-postgres")},
+		&corev1.Service{ObjectMeta: labeledObjectMeta("bd-blackduck-webserver")},
+		&corev1.ConfigMap{ObjectMeta: labeledObjectMeta("bd-blackduck-config")},
+		&corev1.Secret{ObjectMeta: labeledObjectMeta("bd-blackduck-db-creds")},
+		&corev1.ServiceAccount{ObjectMeta: labeledObjectMeta("bd-blackduck-service-account")},
+		&corev1.PersistentVolumeClaim{ObjectMeta: labeledObjectMeta("bd-blackduck-postgres")},
+		// labeled resources that aren't the operator's
+		&corev1.Secret{ObjectMeta: labeledObjectMeta("bd-blackduck-backup")},
+		&corev1.Secret{ObjectMeta: labeledObjectMeta("bd-seed-secrets")},
+		&corev1.ConfigMap{ObjectMeta: labeledObjectMeta("bd-blackduck-custom-config")},
+		&corev1.ServiceAccount{ObjectMeta: labeledObjectMeta("bd-blackduck-scheduled-backup")},
+		&corev1.Service{ObjectMeta: labeledObjectMeta("bd-blackduck-webserver-exposed")},
+		// resources of other instances and workloads
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other-blackduck-webserver", Namespace: "ns", Labels: map[string]string{"app": BlackDuckName, "name": "other"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-db-creds", Namespace: "other-ns", Labels: map[string]string{"app": BlackDuckName, "name": "bd"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-config", Namespace: "ns"}},
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestListResourcesWithLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	resources, err := ListResourcesWithLabels(newLabeledResourcesClientset(), "ns", BlackDuckName, "bd")
+	assert.NoError(err)
+	assert.Equal([]LabeledResource{
+		{Kind: "Deployment", Name: "bd-blackduck-webserver"},
+		{Kind: "ReplicationController", Name: "bd-blackduck-postgres"},
+		{Kind: "Service", Name: "bd-blackduck-webserver"},
+		{Kind: "ConfigMap", Name: "bd-blackduck-config"},
+		{Kind: "Secret", Name: "bd-blackduck-db-creds"},
+		{Kind: "ServiceAccount", Name: "bd-blackduck-service-account"},
+		{Kind: "PersistentVolumeClaim", Name: "bd-blackduck-postgres"},
+	}, resources)
+}
+
+func TestDeleteResourcesWithLabels(t *testing.T) {
+	assert := assert.New(t)
+	clientset := newLabeledResourcesClientset()
+
+	assert.NoError(DeleteResourcesWithLabels(clientset, "ns", BlackDuckName, "bd"))
+
+	deployments, _ := clientset.AppsV1().Deployments("ns").List(metav1.ListOptions{})
+	assert.Equal([]string{"other-blackduck-webserver"}, objectNames(len(deployments.Items), func(i int) string { return deployments.Items[i].Name }))
+	rcs, _ := clientset.CoreV1().ReplicationControllers("ns").List(metav1.ListOptions{})
+	assert.Empty(rcs.Items)
+	services, _ := clientset.CoreV1().Services("ns").List(metav1.ListOptions{})
+	assert.Equal([]string{"bd-blackduck-webserver-exposed"}, objectNames(len(services.Items), func(i int) string { return services.Items[i].Name }))
+	configMaps, _ := clientset.CoreV1().ConfigMaps("ns").List(metav1.ListOptions{})
+	assert.Equal([]string{"bd-blackduck-config", "bd-blackduck-custom-config"}, objectNames(len(configMaps.Items), func(i int) string { return configMaps.Items[i].Name }))
+	secrets, _ := clientset.CoreV1().Secrets("ns").List(metav1.ListOptions{})
+	assert.Equal([]string{"bd-blackduck-backup", "bd-seed-secrets"}, objectNames(len(secrets.Items), func(i int) string { return secrets.Items[i].Name }))
+	serviceAccounts, _ := clientset.CoreV1().ServiceAccounts("ns").List(metav1.ListOptions{})
+	assert.Equal([]string{"bd-blackduck-scheduled-backup"}, objectNames(len(serviceAccounts.Items), func(i int) string { return serviceAccounts.Items[i].Name }))
+	pvcs, _ := clientset.CoreV1().PersistentVolumeClaims("ns").List(metav1.ListOptions{})
+	assert.Empty(pvcs.Items)
+	otherSecrets, _ := clientset.CoreV1().Secrets("other-ns").List(metav1.ListOptions{})
+	assert.Len(otherSecrets.Items, 1)
+
+	// the resources are already deleted when it's run again
+	assert.NoError(DeleteResourcesWithLabels(clientset, "ns", BlackDuckName, "bd"))
+}
+
+// objectNames returns the sorted names of a list of n objects
+func objectNames(n int, name func(i int) string) []string {
+	names := []string{}
+	for i := 0; i < n; i++ {
+		names = append(names, name(i))
+	}
+	sort.Strings(names)
+	return names
+}
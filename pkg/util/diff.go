@@ -0,0 +1,240 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ANSI colors of the diff output
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// ObjectDiff is a change of an object between two manifests, the fields are only set if the object is updated
+type ObjectDiff struct {
+	Action    string
+	Kind      string
+	Name      string
+	Namespace string
+	Fields    []FieldDiff
+}
+
+// FieldDiff is a field of an object that is added, removed or changed
+type FieldDiff struct {
+	Action string
+	Path   string
+	Old    interface{}
+	New    interface{}
+}
+
+// DiffManifests compares the objects of the current and the new manifest of a release and returns the objects that are
+// created, deleted or updated, with the changed fields of the updated ones
+func DiffManifests(currentManifest string, newManifest string, namespace string) ([]ObjectDiff, error) {
+	current, err := manifestObjectsByKey(currentManifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	planned, err := manifestObjectsByKey(newManifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range planned {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := []ObjectDiff{}
+	for _, key := range keys {
+		currentObject, inCurrent := current[key]
+		plannedObject, inPlanned := planned[key]
+		switch {
+		case !inCurrent:
+			diffs = append(diffs, ObjectDiff{Action: PlanActionCreate, Kind: plannedObject.GetKind(), Name: plannedObject.GetName(), Namespace: plannedObject.GetNamespace()})
+		case !inPlanned:
+			diffs = append(diffs, ObjectDiff{Action: PlanActionDelete, Kind: currentObject.GetKind(), Name: currentObject.GetName(), Namespace: currentObject.GetNamespace()})
+		default:
+			normalizedCurrent, err := normalizeObject(currentObject.Object)
+			if err != nil {
+				return nil, err
+			}
+			normalizedPlanned, err := normalizeObject(plannedObject.Object)
+			if err != nil {
+				return nil, err
+			}
+			fields := []FieldDiff{}
+			diffValues("", normalizedCurrent, normalizedPlanned, &fields)
+			if len(fields) == 0 {
+				continue
+			}
+			if plannedObject.GetKind() == "Secret" {
+				redactSecretFields(fields)
+			}
+			diffs = append(diffs, ObjectDiff{Action: PlanActionUpdate, Kind: plannedObject.GetKind(), Name: plannedObject.GetName(), Namespace: plannedObject.GetNamespace(), Fields: fields})
+		}
+	}
+	return diffs, nil
+}
+
+// diffValues adds the fields that differ between the current and the new value, maps are compared by key and lists of
+// the same length by index
+func diffValues(path string, current interface{}, planned interface{}, fields *[]FieldDiff) {
+	switch c := current.(type) {
+	case map[string]interface{}:
+		p, ok := planned.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for key := range c {
+			keys = append(keys, key)
+		}
+		for key := range p {
+			if _, found := c[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if len(path) > 0 {
+				fieldPath = fmt.Sprintf("%s.%s", path, key)
+			}
+			currentValue, inCurrent := c[key]
+			plannedValue, inPlanned := p[key]
+			switch {
+			case !inCurrent:
+				*fields = append(*fields, FieldDiff{Action: PlanActionCreate, Path: fieldPath, New: plannedValue})
+			case !inPlanned:
+				*fields = append(*fields, FieldDiff{Action: PlanActionDelete, Path: fieldPath, Old: currentValue})
+			default:
+				diffValues(fieldPath, currentValue, plannedValue, fields)
+			}
+		}
+		return
+	case []interface{}:
+		p, ok := planned.([]interface{})
+		if !ok || len(p) != len(c) {
+			break
+		}
+		for i := range c {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), c[i], p[i], fields)
+		}
+		return
+	}
+	if !reflect.DeepEqual(current, planned) {
+		*fields = append(*fields, FieldDiff{Action: PlanActionUpdate, Path: path, Old: current, New: planned})
+	}
+}
+
+// redactSecretFields hides the values of the data of a Secret, only the changed keys are shown
+func redactSecretFields(fields []FieldDiff) {
+	for i, field := range fields {
+		key := strings.SplitN(field.Path, ".", 2)[0]
+		if key != "data" && key != "stringData" {
+			continue
+		}
+		if field.Old != nil {
+			fields[i].Old = "<redacted>"
+		}
+		if field.New != nil {
+			fields[i].New = "<redacted>"
+		}
+	}
+}
+
+// PrintManifestDiff writes the changed objects and their changed fields, in green if they are added, in red if they
+// are removed and in yellow if they are changed, unless the colors are disabled
+func PrintManifestDiff(w io.Writer, diffs []ObjectDiff, color bool) error {
+	for _, diff := range diffs {
+		if _, err := fmt.Fprintln(w, colorize(diff.Action, fmt.Sprintf("%s %s %s/%s", diffSymbol(diff.Action), diff.Kind, diff.Namespace, diff.Name), color)); err != nil {
+			return err
+		}
+		for _, field := range diff.Fields {
+			var line string
+			switch field.Action {
+			case PlanActionCreate:
+				line = fmt.Sprintf("    + %s: %s", field.Path, formatDiffValue(field.New))
+			case PlanActionDelete:
+				line = fmt.Sprintf("    - %s: %s", field.Path, formatDiffValue(field.Old))
+			default:
+				line = fmt.Sprintf("    ~ %s: %s -> %s", field.Path, formatDiffValue(field.Old), formatDiffValue(field.New))
+			}
+			if _, err := fmt.Fprintln(w, colorize(field.Action, line, color)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffSymbol returns the symbol of an action of the diff
+func diffSymbol(action string) string {
+	switch action {
+	case PlanActionCreate:
+		return "+"
+	case PlanActionDelete:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// colorize returns the line in the color of the action of the diff
+func colorize(action string, line string, color bool) string {
+	if !color {
+		return line
+	}
+	switch action {
+	case PlanActionCreate:
+		return colorGreen + line + colorReset
+	case PlanActionDelete:
+		return colorRed + line + colorReset
+	default:
+		return colorYellow + line + colorReset
+	}
+}
+
+// formatDiffValue returns the compact JSON encoding of a list or a map and the plain value of the other types
+func formatDiffValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
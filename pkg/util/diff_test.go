@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffManifests(t *testing.T) {
+	assert := assert.New(t)
+	currentManifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webserver
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: webserver
+        image: webserver:1.0
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: b2xk
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: removed
+`
+	newManifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webserver
+  labels:
+    version: "2.0"
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: webserver
+        image: webserver:2.0
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: bmV3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+`
+	diffs, err := DiffManifests(currentManifest, newManifest, "ns")
+	assert.NoError(err)
+	assert.Equal([]ObjectDiff{
+		{Action: PlanActionCreate, Kind: "ConfigMap", Name: "added", Namespace: "ns"},
+		{Action: PlanActionUpdate, Kind: "Deployment", Name: "webserver", Namespace: "ns", Fields: []FieldDiff{
+			{Action: PlanActionCreate, Path: "metadata.labels", New: map[string]interface{}{"version": "2.0"}},
+			{Action: PlanActionUpdate, Path: "spec.replicas", Old: float64(1), New: float64(2)},
+			{Action: PlanActionUpdate, Path: "spec.template.spec.containers[0].image", Old: "webserver:1.0", New: "webserver:2.0"},
+		}},
+		{Action: PlanActionUpdate, Kind: "Secret", Name: "credentials", Namespace: "ns", Fields: []FieldDiff{
+			{Action: PlanActionUpdate, Path: "data.password", Old: "<redacted>", New: "<redacted>"},
+		}},
+		{Action: PlanActionDelete, Kind: "Service", Name: "removed", Namespace: "ns"},
+	}, diffs)
+
+	unchanged, err := DiffManifests(currentManifest, currentManifest, "ns")
+	assert.NoError(err)
+	assert.Empty(unchanged)
+}
+
+func TestPrintManifestDiff(t *testing.T) {
+	assert := assert.New(t)
+	diffs := []ObjectDiff{
+		{Action: PlanActionUpdate, Kind: "Deployment", Name: "webserver", Namespace: "ns", Fields: []FieldDiff{
+			{Action: PlanActionCreate, Path: "metadata.labels", New: map[string]interface{}{"version": "2.0"}},
+			{Action: PlanActionUpdate, Path: "spec.replicas", Old: float64(1), New: float64(2)},
+		}},
+		{Action: PlanActionDelete, Kind: "Service", Name: "removed", Namespace: "ns"},
+	}
+
+	var plain bytes.Buffer
+	assert.NoError(PrintManifestDiff(&plain, diffs, false))
+	assert.Equal(`~ Deployment ns/webserver
+    + metadata.labels: {"version":"2.0"}
+    ~ spec.replicas: 1 -> 2
+- Service ns/removed
+`, plain.String())
+
+	var colored bytes.Buffer
+	assert.NoError(PrintManifestDiff(&colored, diffs, true))
+	assert.True(strings.Contains(colored.String(), colorRed+"- Service ns/removed"+colorReset))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// SplitManifests parses a multi-document YAML manifest into its objects, skipping empty documents
+func SplitManifests(manifest string) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+	for _, document := range strings.Split("\n"+manifest, "\n---") {
+		if len(strings.TrimSpace(document)) == 0 {
+			continue
+		}
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, fmt.Errorf("unable to parse manifest due to %+v", err)
+		}
+		if len(object) == 0 {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// ProjectLiveObject returns the fields of the live object that are set in the expected object, so that fields defaulted
+// by the API server or added by controllers are ignored when both are compared. Values that differ only in their notation,
+// e.g. resource quantities or Secret stringData, are normalized to the expected notation
+func ProjectLiveObject(expected map[string]interface{}, live map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	normalizedExpected, err := normalizeObject(expected)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalizedLive, err := normalizeObject(live)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind, _ := normalizedExpected["kind"].(string); kind == "Secret" {
+		convertSecretStringData(normalizedExpected)
+	}
+	delete(normalizedExpected, "status")
+	projected, _ := projectValue(normalizedExpected, normalizedLive).(map[string]interface{})
+	return normalizedExpected, projected, nil
+}
+
+// HashObject returns the SHA-256 checksum of the canonical JSON encoding of the object
+func HashObject(object interface{}) (string, error) {
+	// encoding/json sorts map keys, which makes the encoding canonical
+	data, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// normalizeObject converts the object to the types of a JSON decoding, e.g. all numbers become float64
+func normalizeObject(object map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// convertSecretStringData moves the stringData of a Secret into its data, as the API server does
+func convertSecretStringData(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{}
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%v", value)))
+	}
+	secret["data"] = data
+	delete(secret, "stringData")
+}
+
+func projectValue(expected interface{}, live interface{}) interface{} {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		projected := map[string]interface{}{}
+		for key, value := range e {
+			// keys missing from the live object stay missing, so the difference remains visible
+			if liveValue, found := l[key]; found {
+				projected[key] = projectValue(value, liveValue)
+			}
+		}
+		return projected
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(e) {
+			return live
+		}
+		projected := make([]interface{}, len(l))
+		for i := range l {
+			projected[i] = projectValue(e[i], l[i])
+		}
+		return projected
+	case string:
+		if l, ok := live.(string); ok && l != e && equalQuantities(e, l) {
+			return e
+		}
+		if l, ok := live.(float64); ok && equalQuantities(e, fmt.Sprintf("%v", l)) {
+			return e
+		}
+		return live
+	case float64:
+		if l, ok := live.(string); ok && equalQuantities(fmt.Sprintf("%v", e), l) {
+			return e
+		}
+		return live
+	}
+	return live
+}
+
+func equalQuantities(a string, b string) bool {
+	qa, err := resource.ParseQuantity(a)
+	if err != nil {
+		return false
+	}
+	qb, err := resource.ParseQuantity(b)
+	if err != nil {
+		return false
+	}
+	return qa.Cmp(qb) == 0
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestSplitManifests(t *testing.T) {
+	manifest := `---
+# Source: blackduck/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bd-blackduck-db-creds
+---
+---
+# Source: blackduck/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webserver
+`
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0]["kind"] != "Secret" || objects[1]["kind"] != "Service" {
+		t.Errorf("unexpected objects %+v", objects)
+	}
+}
+
+func TestProjectLiveObject(t *testing.T) {
+	expected := map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "bd-blackduck-webserver", "labels": map[string]interface{}{"app": "blackduck"}},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "webserver", "resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1000m", "memory": "1Gi"}}},
+					},
+				},
+			},
+		},
+	}
+	live := func(replicas int64, memory string) map[string]interface{} {
+		return map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "bd-blackduck-webserver", "uid": "1234", "labels": map[string]interface{}{"app": "blackduck"}},
+			"spec": map[string]interface{}{
+				"replicas":             replicas,
+				"revisionHistoryLimit": int64(10),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "webserver", "imagePullPolicy": "Always", "resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1", "memory": memory}}},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{"readyReplicas": int64(1)},
+		}
+	}
+
+	var tests = []struct {
+		description string
+		live        map[string]interface{}
+		drifted     bool
+	}{
+		{description: "defaulted fields only", live: live(1, "1Gi"), drifted: false},
+		{description: "replicas changed", live: live(3, "1Gi"), drifted: true},
+		{description: "memory limit changed", live: live(1, "2Gi"), drifted: true},
+	}
+
+	for _, test := range tests {
+		normalizedExpected, projected, err := ProjectLiveObject(expected, test.live)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedHash, _ := HashObject(normalizedExpected)
+		liveHash, _ := HashObject(projected)
+		if (expectedHash != liveHash) != test.drifted {
+			t.Errorf("%s: expected drifted %t, got %t", test.description, test.drifted, expectedHash != liveHash)
+		}
+	}
+
+	secret := map[string]interface{}{"kind": "Secret", "stringData": map[string]interface{}{"password": "abc"}}
+	liveSecret := map[string]interface{}{"kind": "Secret", "data": map[string]interface{}{"password": "YWJj"}, "type": "Opaque"}
+	normalizedExpected, projected, err := ProjectLiveObject(secret, liveSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHash, _ := HashObject(normalizedExpected)
+	liveHash, _ := HashObject(projected)
+	if expectedHash != liveHash {
+		t.Errorf("secret stringData should match the live data")
+	}
+}
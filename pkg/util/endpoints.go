@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetLoadBalancerHosts returns the host of each ingress point of a load balancer service, the hostname is
+// preferred over the IP address because some cloud providers (e.g. AWS ELB) only set the hostname
+func GetLoadBalancerHosts(service *corev1.Service) []string {
+	hosts := []string{}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if len(ingress.Hostname) > 0 {
+			hosts = append(hosts, ingress.Hostname)
+		} else if len(ingress.IP) > 0 {
+			hosts = append(hosts, ingress.IP)
+		}
+	}
+	return hosts
+}
+
+// GetNodeHosts returns an address of each node, the external IP address is preferred over the internal IP address
+// and the hostname
+func GetNodeHosts(nodes []corev1.Node) []string {
+	hosts := []string{}
+	for _, node := range nodes {
+		for _, addressType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeExternalDNS, corev1.NodeInternalIP, corev1.NodeHostName} {
+			if address := getNodeAddress(node, addressType); len(address) > 0 {
+				hosts = append(hosts, address)
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+func getNodeAddress(node corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == addressType && len(address.Address) > 0 {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// GetServiceEndpoints returns the host:port addresses that reach the first port of a load balancer or node port
+// service from outside of the cluster
+func GetServiceEndpoints(clientset *kubernetes.Clientset, service *corev1.Service) ([]string, error) {
+	if len(service.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service '%s' has no ports", service.Name)
+	}
+	port := service.Spec.Ports[0]
+
+	endpoints := []string{}
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, host := range GetLoadBalancerHosts(service) {
+			endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(int(port.Port))))
+		}
+		if len(endpoints) == 0 {
+			return nil, fmt.Errorf("the load balancer of service '%s' has not been assigned an address yet", service.Name)
+		}
+	case corev1.ServiceTypeNodePort:
+		nodes, err := clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list nodes due to %+v", err)
+		}
+		for _, host := range GetNodeHosts(nodes.Items) {
+			endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(int(port.NodePort))))
+		}
+		if len(endpoints) == 0 {
+			return nil, fmt.Errorf("unable to find the address of a node")
+		}
+	default:
+		return nil, fmt.Errorf("service '%s' of type '%s' isn't reachable from outside of the cluster", service.Name, service.Spec.Type)
+	}
+	return endpoints, nil
+}
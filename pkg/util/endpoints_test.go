@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetLoadBalancerHosts(t *testing.T) {
+	service := &corev1.Service{Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+		{Hostname: "a1b2.elb.amazonaws.com"},
+		{IP: "10.0.0.1"},
+		{IP: "10.0.0.2", Hostname: "lb.example.com"},
+		{},
+	}}}}
+	assert.Equal(t, []string{"a1b2.elb.amazonaws.com", "10.0.0.1", "lb.example.com"}, GetLoadBalancerHosts(service))
+}
+
+func TestGetNodeHosts(t *testing.T) {
+	nodes := []corev1.Node{
+		{Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "192.168.0.1"},
+			{Type: corev1.NodeExternalIP, Address: "34.1.2.3"},
+		}}},
+		{Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "node-2"},
+			{Type: corev1.NodeInternalIP, Address: "192.168.0.2"},
+		}}},
+		{},
+	}
+	assert.Equal(t, []string{"34.1.2.3", "192.168.0.2"}, GetNodeHosts(nodes))
+}
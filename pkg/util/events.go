@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// InstanceEvent is an event of an object of an instance. Repeated events of an object with the same reason and message
+// are aggregated into one, with the count of all of them and the time of the last one
+type InstanceEvent struct {
+	Time      time.Time `json:"time"`
+	Component string    `json:"component,omitempty"`
+	Kind      string    `json:"kind"`
+	Object    string    `json:"object"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+}
+
+// EventObjectKey returns the key of an object in the map of the components of the objects of an instance
+func EventObjectKey(kind string, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// GetEventTime returns the time an event was last seen
+func GetEventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// GetInstanceEvent returns the event if its object is one of the objects of the instance, the components are keyed by
+// EventObjectKey. The boolean is false if the event belongs to another object
+func GetInstanceEvent(event corev1.Event, components map[string]string) (InstanceEvent, bool) {
+	component, ok := components[EventObjectKey(event.InvolvedObject.Kind, event.InvolvedObject.Name)]
+	if !ok {
+		return InstanceEvent{}, false
+	}
+	count := event.Count
+	if count == 0 {
+		count = 1
+	}
+	return InstanceEvent{
+		Time:      GetEventTime(event),
+		Component: component,
+		Kind:      event.InvolvedObject.Kind,
+		Object:    event.InvolvedObject.Name,
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Count:     count,
+	}, true
+}
+
+// AggregateInstanceEvents returns the events of the objects of the instance that were seen since the given time,
+// aggregated and sorted by time, the oldest first
+func AggregateInstanceEvents(events []corev1.Event, components map[string]string, since time.Time) []InstanceEvent {
+	aggregated := map[string]*InstanceEvent{}
+	for _, event := range events {
+		instanceEvent, ok := GetInstanceEvent(event, components)
+		if !ok || instanceEvent.Time.Before(since) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%s/%s", instanceEvent.Kind, instanceEvent.Object, instanceEvent.Type, instanceEvent.Reason, instanceEvent.Message)
+		if existing, ok := aggregated[key]; ok {
+			existing.Count += instanceEvent.Count
+			if instanceEvent.Time.After(existing.Time) {
+				existing.Time = instanceEvent.Time
+			}
+			continue
+		}
+		aggregated[key] = &instanceEvent
+	}
+	instanceEvents := make([]InstanceEvent, 0, len(aggregated))
+	for _, instanceEvent := range aggregated {
+		instanceEvents = append(instanceEvents, *instanceEvent)
+	}
+	sort.Slice(instanceEvents, func(i, j int) bool {
+		a, b := instanceEvents[i], instanceEvents[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+		return a.Reason < b.Reason
+	})
+	return instanceEvents
+}
+
+// GetRecentWarningEvents returns the last warning events of the aggregated events, the oldest first
+func GetRecentWarningEvents(events []InstanceEvent, limit int) []InstanceEvent {
+	warnings := []InstanceEvent{}
+	for _, event := range events {
+		if event.Type == corev1.EventTypeWarning {
+			warnings = append(warnings, event)
+		}
+	}
+	if len(warnings) > limit {
+		warnings = warnings[len(warnings)-limit:]
+	}
+	return warnings
+}
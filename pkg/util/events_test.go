@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestEvent(kind string, name string, reason string, message string, count int32, last time.Time) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: name},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestGetEventTime(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now().Truncate(time.Second)
+
+	assert.Equal(now, GetEventTime(corev1.Event{LastTimestamp: metav1.NewTime(now), FirstTimestamp: metav1.NewTime(now.Add(-time.Hour))}))
+	assert.Equal(now, GetEventTime(corev1.Event{EventTime: metav1.NewMicroTime(now)}))
+	assert.Equal(now, GetEventTime(corev1.Event{FirstTimestamp: metav1.NewTime(now)}))
+	assert.Equal(now, GetEventTime(corev1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now)}}))
+}
+
+func TestGetInstanceEvent(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now().Truncate(time.Second)
+	components := map[string]string{EventObjectKey("Pod", "bd-webserver-1"): "webserver"}
+
+	event, ok := GetInstanceEvent(newTestEvent("Pod", "bd-webserver-1", "BackOff", "Back-off restarting failed container", 0, now), components)
+	assert.True(ok)
+	assert.Equal(InstanceEvent{Time: now, Component: "webserver", Kind: "Pod", Object: "bd-webserver-1", Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting failed container", Count: 1}, event)
+
+	_, ok = GetInstanceEvent(newTestEvent("Pod", "other-pod", "BackOff", "Back-off restarting failed container", 1, now), components)
+	assert.False(ok)
+	_, ok = GetInstanceEvent(newTestEvent("Deployment", "bd-webserver-1", "ScalingReplicaSet", "Scaled up", 1, now), components)
+	assert.False(ok)
+}
+
+func TestAggregateInstanceEvents(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now().Truncate(time.Second)
+	components := map[string]string{
+		EventObjectKey("Pod", "bd-webserver-1"):       "webserver",
+		EventObjectKey("Pod", "bd-postgres-1"):        "postgres",
+		EventObjectKey("Deployment", "bd-webserver"):  "webserver",
+		EventObjectKey("PersistentVolumeClaim", "pg"): "",
+	}
+	events := []corev1.Event{
+		newTestEvent("Pod", "bd-webserver-1", "BackOff", "Back-off restarting failed container", 3, now.Add(-10*time.Minute)),
+		newTestEvent("Pod", "bd-webserver-1", "BackOff", "Back-off restarting failed container", 2, now.Add(-5*time.Minute)),
+		newTestEvent("Pod", "bd-postgres-1", "FailedMount", "Unable to attach or mount volumes", 1, now.Add(-20*time.Minute)),
+		newTestEvent("Deployment", "bd-webserver", "ScalingReplicaSet", "Scaled up replica set", 1, now.Add(-2*time.Hour)),
+		newTestEvent("PersistentVolumeClaim", "pg", "ProvisioningFailed", "storageclass not found", 1, now.Add(-time.Minute)),
+		newTestEvent("Pod", "unrelated", "BackOff", "Back-off restarting failed container", 1, now),
+	}
+
+	aggregated := AggregateInstanceEvents(events, components, now.Add(-time.Hour))
+	assert.Len(aggregated, 3)
+	assert.Equal("bd-postgres-1", aggregated[0].Object)
+	assert.Equal("bd-webserver-1", aggregated[1].Object)
+	assert.Equal(int32(5), aggregated[1].Count)
+	assert.Equal(now.Add(-5*time.Minute), aggregated[1].Time)
+	assert.Equal("pg", aggregated[2].Object)
+
+	assert.Len(AggregateInstanceEvents(events, components, time.Time{}), 4)
+	assert.Empty(AggregateInstanceEvents(events, map[string]string{}, time.Time{}))
+}
+
+func TestGetRecentWarningEvents(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	events := []InstanceEvent{
+		{Object: "bd-postgres-1", Type: corev1.EventTypeWarning, Time: now.Add(-3 * time.Minute)},
+		{Object: "bd-webserver-1", Type: corev1.EventTypeNormal, Time: now.Add(-2 * time.Minute)},
+		{Object: "bd-webserver-1", Type: corev1.EventTypeWarning, Time: now.Add(-time.Minute)},
+		{Object: "pg", Type: corev1.EventTypeWarning, Time: now},
+	}
+	recent := GetRecentWarningEvents(events, 2)
+	assert.Len(recent, 2)
+	assert.Equal("bd-webserver-1", recent[0].Object)
+	assert.Equal("pg", recent[1].Object)
+	assert.Len(GetRecentWarningEvents(events, 10), 3)
+	assert.Empty(GetRecentWarningEvents(nil, 10))
+}
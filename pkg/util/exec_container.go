@@ -23,6 +23,7 @@ package util
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"strings"
 
@@ -76,3 +77,29 @@ func ExecContainer(kubeConfig *rest.Config, request *rest.Request, command []str
 	log.Debugf("stdout: %s, stderr: %s", stdout.String(), stderr.String())
 	return stdout.String(), err
 }
+
+// StreamContainerCommand runs the command in the first container of the pod with the input of stdin, if it isn't nil,
+// and writes its output to stdout while it runs, e.g. to copy a large file into or out of the pod
+func StreamContainerCommand(kubeConfig *rest.Config, clientset *kubernetes.Clientset, pod *corev1.Pod, command []string, stdin io.Reader, stdout io.Writer) error {
+	request := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(kubeConfig, "POST", request.URL())
+	if err != nil {
+		return fmt.Errorf("unable to exec into pod '%s' due to %+v", pod.Name, err)
+	}
+	var stderr bytes.Buffer
+	if err := exec.Stream(remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("'%s' failed in pod '%s' due to %+v: %s", strings.Join(command, " "), pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Exit codes of synopsysctl
+const (
+	// ExitCodeSuccess is returned if the command succeeded
+	ExitCodeSuccess = 0
+	// ExitCodeFailure is returned for all errors without a more specific exit code
+	ExitCodeFailure = 1
+	// ExitCodeValidation is returned if the arguments or flags of the command are invalid
+	ExitCodeValidation = 2
+	// ExitCodeClusterUnreachable is returned if synopsysctl can't access the cluster
+	ExitCodeClusterUnreachable = 3
+	// ExitCodeHelmFailure is returned if a Helm action failed
+	ExitCodeHelmFailure = 4
+	// ExitCodeTimeout is returned if the command didn't complete in time
+	ExitCodeTimeout = 5
+	// ExitCodePartialSuccess is returned if only some of the changes of the command succeeded
+	ExitCodePartialSuccess = 6
+	// ExitCodeWarning is returned if the command succeeded with warnings and --fail-on warning is set
+	ExitCodeWarning = 7
+)
+
+// ExitError is an error with the exit code of synopsysctl
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// WithExitCode wraps the error with an exit code. Errors that wrap it with '%w' keep the exit code
+func WithExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// ValidationError returns an error with the ExitCodeValidation exit code
+func ValidationError(format string, args ...interface{}) error {
+	return WithExitCode(ExitCodeValidation, fmt.Errorf(format, args...))
+}
+
+// ExitCode returns the exit code of synopsysctl for the error
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, wait.ErrWaitTimeout) {
+		return ExitCodeTimeout
+	}
+	return ExitCodeFailure
+}
+
+// warningCounter is a logger hook that counts the logged warnings
+type warningCounter struct {
+	count int32
+}
+
+// Levels returns the levels counted by the hook
+func (c *warningCounter) Levels() []log.Level {
+	return []log.Level{log.WarnLevel}
+}
+
+// Fire counts a logged warning
+func (c *warningCounter) Fire(entry *log.Entry) error {
+	atomic.AddInt32(&c.count, 1)
+	return nil
+}
+
+var loggedWarnings = &warningCounter{}
+
+func init() {
+	log.AddHook(loggedWarnings)
+}
+
+// WarningCount returns the number of warnings logged so far
+func WarningCount() int {
+	return int(atomic.LoadInt32(&loggedWarnings.count))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "success", err: nil, expected: ExitCodeSuccess},
+		{name: "generic error", err: fmt.Errorf("failed"), expected: ExitCodeFailure},
+		{name: "validation error", err: ValidationError("invalid flag '%s'", "size"), expected: ExitCodeValidation},
+		{name: "wrapped helm failure", err: fmt.Errorf("failed to create resources: %w", WithExitCode(ExitCodeHelmFailure, fmt.Errorf("install failed"))), expected: ExitCodeHelmFailure},
+		{name: "context deadline", err: fmt.Errorf("waiting: %w", context.DeadlineExceeded), expected: ExitCodeTimeout},
+		{name: "wait timeout", err: wait.ErrWaitTimeout, expected: ExitCodeTimeout},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.expected {
+			t.Errorf("%s: expected exit code %d, got %d", test.name, test.expected, code)
+		}
+	}
+	if WithExitCode(ExitCodeHelmFailure, nil) != nil {
+		t.Errorf("expected nil when wrapping a nil error")
+	}
+}
+
+func TestWarningCount(t *testing.T) {
+	before := WarningCount()
+	log.Warnf("test warning")
+	if count := WarningCount(); count != before+1 {
+		t.Errorf("expected %d warnings, got %d", before+1, count)
+	}
+}
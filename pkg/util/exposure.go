@@ -0,0 +1,269 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Exposure is the service, the OpenShift route and the ingress that expose the user interface of an instance and that
+// synopsysctl manages outside of the chart. The objects are found by their names and by the identity labels of the
+// instance, app and name, so that renames of the release or of the name label never strand them
+type Exposure struct {
+	App       string
+	Namespace string
+	// Name is the value of the name label of the instance, e.g. its Helm release name
+	Name string
+	// ServiceName, RouteName and IngressName are the names of the exposed service, the route and the ingress of the instance
+	ServiceName string
+	RouteName   string
+	IngressName string
+	// PreviousNames are former values of the name label of the instance, e.g. before a migration renamed its release
+	PreviousNames []string
+}
+
+// exposedSuffix is the suffix of the names of the exposed services
+const exposedSuffix = "-exposed"
+
+// isHelmManaged returns true if the labels are those of an object of a chart, the chart reconciles them itself
+func isHelmManaged(labels map[string]string) bool {
+	if _, ok := labels["helm.sh/chart"]; ok {
+		return true
+	}
+	return labels["app.kubernetes.io/managed-by"] == "Helm"
+}
+
+// hasIdentity returns true if the labels are the identity labels of the instance or of one of its previous names
+func (e Exposure) hasIdentity(labels map[string]string) bool {
+	if labels["app"] != e.App {
+		return false
+	}
+	for _, name := range append([]string{e.Name}, e.PreviousNames...) {
+		if labels["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExposureObject returns true if the Service, Route or Ingress with the name and labels exposes the instance and
+// isn't managed by its chart
+func (e Exposure) IsExposureObject(kind string, name string, labels map[string]string) bool {
+	if isHelmManaged(labels) {
+		return false
+	}
+	switch kind {
+	case "Service":
+		return name == e.ServiceName || (e.hasIdentity(labels) && strings.HasSuffix(name, exposedSuffix))
+	case "Route":
+		return name == e.RouteName || name == e.ServiceName || e.hasIdentity(labels)
+	case "Ingress":
+		return (len(e.IngressName) > 0 && name == e.IngressName) || e.hasIdentity(labels)
+	}
+	return false
+}
+
+// Relabel returns copies of the labels and the selector of an exposure object with the identity of the instance, and
+// whether they changed. Only the identity keys that the selector already has are set in the selector
+func (e Exposure) Relabel(labels map[string]string, selector map[string]string) (map[string]string, map[string]string, bool) {
+	identity := map[string]string{"app": e.App, "name": e.Name}
+	changed := false
+	newLabels := map[string]string{}
+	for key, value := range labels {
+		newLabels[key] = value
+	}
+	for key, value := range identity {
+		if newLabels[key] != value {
+			newLabels[key] = value
+			changed = true
+		}
+	}
+	var newSelector map[string]string
+	if selector != nil {
+		newSelector = map[string]string{}
+		for key, value := range selector {
+			newSelector[key] = value
+			if identityValue, ok := identity[key]; ok && value != identityValue {
+				newSelector[key] = identityValue
+				changed = true
+			}
+		}
+	}
+	return newLabels, newSelector, changed
+}
+
+// ReconcileExposure updates the identity labels, and the selectors of the services, of the exposure objects of the
+// instance. It is idempotent and is run by every command that creates, updates or migrates an instance
+func ReconcileExposure(restConfig *rest.Config, kubeClient *kubernetes.Clientset, e Exposure) error {
+	services, err := ListServices(kubeClient, e.Namespace, "")
+	if err != nil {
+		return fmt.Errorf("unable to list the services in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if !e.IsExposureObject("Service", svc.Name, svc.Labels) {
+			continue
+		}
+		labels, selector, changed := e.Relabel(svc.Labels, svc.Spec.Selector)
+		if !changed {
+			continue
+		}
+		log.Debugf("updating the identity labels of the exposed service '%s' in namespace '%s'", svc.Name, e.Namespace)
+		svc.Labels = labels
+		svc.Spec.Selector = selector
+		if _, err := kubeClient.CoreV1().Services(e.Namespace).Update(svc); err != nil {
+			return fmt.Errorf("unable to update the exposed service '%s' in namespace '%s' due to %+v", svc.Name, e.Namespace, err)
+		}
+	}
+
+	ingressClient, err := getIngressClient(restConfig, e.Namespace)
+	if err != nil {
+		return err
+	}
+	ingresses, err := ingressClient.List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the ingresses in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !e.IsExposureObject("Ingress", ingress.GetName(), ingress.GetLabels()) {
+			continue
+		}
+		if labels, _, changed := e.Relabel(ingress.GetLabels(), nil); changed {
+			ingress.SetLabels(labels)
+			if _, err := ingressClient.Update(ingress, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("unable to update the ingress '%s' in namespace '%s' due to %+v", ingress.GetName(), e.Namespace, err)
+			}
+		}
+	}
+
+	if !IsOpenshift(kubeClient) {
+		return nil
+	}
+	routeClient := GetRouteClient(restConfig, kubeClient, e.Namespace)
+	routes, err := ListRoutes(routeClient, e.Namespace, "")
+	if err != nil {
+		return fmt.Errorf("unable to list the routes in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		if !e.IsExposureObject("Route", route.Name, route.Labels) {
+			continue
+		}
+		if labels, _, changed := e.Relabel(route.Labels, nil); changed {
+			route.Labels = labels
+			if _, err := UpdateRoute(routeClient, e.Namespace, route); err != nil {
+				return fmt.Errorf("unable to update the route '%s' in namespace '%s' due to %+v", route.Name, e.Namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ExposureObject is a service, an ingress or a route of the exposure of an instance
+type ExposureObject struct {
+	Kind string
+	Name string
+}
+
+// ListExposureObjects returns the exposure objects of the instance, including those labeled with a previous name
+func ListExposureObjects(restConfig *rest.Config, kubeClient *kubernetes.Clientset, e Exposure) ([]ExposureObject, error) {
+	objects := []ExposureObject{}
+	services, err := ListServices(kubeClient, e.Namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the services in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for _, svc := range services.Items {
+		if e.IsExposureObject("Service", svc.Name, svc.Labels) {
+			objects = append(objects, ExposureObject{Kind: "Service", Name: svc.Name})
+		}
+	}
+
+	ingressClient, err := getIngressClient(restConfig, e.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	ingresses, err := ingressClient.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the ingresses in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for _, ingress := range ingresses.Items {
+		if e.IsExposureObject("Ingress", ingress.GetName(), ingress.GetLabels()) {
+			objects = append(objects, ExposureObject{Kind: "Ingress", Name: ingress.GetName()})
+		}
+	}
+
+	if !IsOpenshift(kubeClient) {
+		return objects, nil
+	}
+	routes, err := ListRoutes(GetRouteClient(restConfig, kubeClient, e.Namespace), e.Namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the routes in namespace '%s' due to %+v", e.Namespace, err)
+	}
+	for _, route := range routes.Items {
+		if e.IsExposureObject("Route", route.Name, route.Labels) {
+			objects = append(objects, ExposureObject{Kind: "Route", Name: route.Name})
+		}
+	}
+	return objects, nil
+}
+
+// DeleteExposure deletes the exposure objects of the instance, including those labeled with a previous name
+func DeleteExposure(restConfig *rest.Config, kubeClient *kubernetes.Clientset, e Exposure) error {
+	objects, err := ListExposureObjects(restConfig, kubeClient, e)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		switch object.Kind {
+		case "Service":
+			err = DeleteService(kubeClient, e.Namespace, object.Name)
+		case "Ingress":
+			err = DeleteIngress(restConfig, e.Namespace, object.Name)
+		case "Route":
+			err = DeleteRoute(GetRouteClient(restConfig, kubeClient, e.Namespace), e.Namespace, object.Name)
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete the %s '%s' in namespace '%s' due to %+v", strings.ToLower(object.Kind), object.Name, e.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// DeleteIngress deletes the ingress of the user interface of an instance, e.g. when it is exposed another way
+func DeleteIngress(restConfig *rest.Config, namespace string, name string) error {
+	ingressClient, err := getIngressClient(restConfig, namespace)
+	if err != nil {
+		return err
+	}
+	if err := ingressClient.Delete(name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsExposureObject(t *testing.T) {
+	assert := assert.New(t)
+	e := Exposure{App: "alert", Name: "al-alert", ServiceName: "al-alert-exposed", RouteName: "al-alert", PreviousNames: []string{"al"}}
+
+	assert.True(e.IsExposureObject("Service", "al-alert-exposed", nil))
+	assert.True(e.IsExposureObject("Service", "al-exposed", map[string]string{"app": "alert", "name": "al"}))
+	assert.False(e.IsExposureObject("Service", "al-alert", map[string]string{"app": "alert", "name": "al-alert"}))
+	assert.False(e.IsExposureObject("Service", "other-exposed", map[string]string{"app": "alert", "name": "other"}))
+	assert.False(e.IsExposureObject("Service", "al-alert-exposed", map[string]string{"helm.sh/chart": "alert-6.0.0"}))
+	assert.False(e.IsExposureObject("Service", "al-alert-exposed", map[string]string{"app.kubernetes.io/managed-by": "Helm"}))
+
+	assert.True(e.IsExposureObject("Route", "al-alert", nil))
+	assert.True(e.IsExposureObject("Route", "al", map[string]string{"app": "alert", "name": "al"}))
+	assert.True(e.IsExposureObject("Ingress", "al", map[string]string{"app": "alert", "name": "al-alert"}))
+	assert.False(e.IsExposureObject("Ingress", "al-alert", nil))
+	e.IngressName = "al-alert"
+	assert.True(e.IsExposureObject("Ingress", "al-alert", nil))
+	assert.False(e.IsExposureObject("Ingress", "al-alert", map[string]string{"helm.sh/chart": "alert-6.0.0"}))
+	assert.False(e.IsExposureObject("ConfigMap", "al-alert-exposed", nil))
+}
+
+func TestRelabel(t *testing.T) {
+	assert := assert.New(t)
+	e := Exposure{App: "alert", Name: "al-alert"}
+
+	labels, selector, changed := e.Relabel(map[string]string{"app": "alert", "name": "al", "component": "alert"}, map[string]string{"app": "alert", "name": "al", "component": "alert"})
+	assert.True(changed)
+	assert.Equal(map[string]string{"app": "alert", "name": "al-alert", "component": "alert"}, labels)
+	assert.Equal(map[string]string{"app": "alert", "name": "al-alert", "component": "alert"}, selector)
+
+	labels, selector, changed = e.Relabel(labels, map[string]string{"component": "alert"})
+	assert.False(changed)
+	assert.Equal(map[string]string{"component": "alert"}, selector)
+
+	labels, selector, changed = e.Relabel(nil, nil)
+	assert.True(changed)
+	assert.Equal(map[string]string{"app": "alert", "name": "al-alert"}, labels)
+	assert.Nil(selector)
+}
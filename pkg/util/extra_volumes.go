@@ -0,0 +1,336 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// ExtraVolume is a user-provided volume that is mounted into all containers of a component
+type ExtraVolume struct {
+	Component string `json:"component"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// Types of extra volumes, the name of the extra volume is the name of the source
+const (
+	ExtraVolumeTypePVC       = "pvc"
+	ExtraVolumeTypeConfigMap = "configmap"
+	ExtraVolumeTypeSecret    = "secret"
+	ExtraVolumeTypeEmptyDir  = "emptydir"
+)
+
+// extraVolumesHelmPath is the path of the extra volumes in the Helm values. The values are not used by the charts,
+// they are applied by post-rendering the manifests so that they are re-applied on every update
+var extraVolumesHelmPath = []string{"synopsysctl", "extraVolumes"}
+
+var volumeNameRegexp = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ParseExtraVolume parses an extra volume in the format component=name:type:/mount/path[:ro]
+func ParseExtraVolume(value string) (*ExtraVolume, error) {
+	componentAndVolume := strings.SplitN(value, "=", 2)
+	if len(componentAndVolume) != 2 || len(componentAndVolume[0]) == 0 {
+		return nil, fmt.Errorf("invalid extra volume '%s', the format is component=name:type:/mount/path[:ro]", value)
+	}
+	fields := strings.Split(componentAndVolume[1], ":")
+	if len(fields) < 3 || len(fields) > 4 {
+		return nil, fmt.Errorf("invalid extra volume '%s', the format is component=name:type:/mount/path[:ro]", value)
+	}
+	volume := &ExtraVolume{
+		Component: componentAndVolume[0],
+		Name:      fields[0],
+		Type:      strings.ToLower(fields[1]),
+		MountPath: fields[2],
+	}
+	if len(fields) == 4 {
+		if fields[3] != "ro" {
+			return nil, fmt.Errorf("invalid extra volume '%s', the only supported option is 'ro'", value)
+		}
+		volume.ReadOnly = true
+	}
+	if err := volume.Validate(); err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// ReadExtraVolumesFile reads a JSON or YAML file containing a list of extra volumes
+func ReadExtraVolumesFile(path string) ([]ExtraVolume, error) {
+	data, err := ReadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	volumes := []ExtraVolume{}
+	if err := yaml.Unmarshal(data, &volumes); err != nil {
+		return nil, fmt.Errorf("failed to parse extra volumes file '%s' due to %+v", path, err)
+	}
+	for _, volume := range volumes {
+		if err := volume.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return volumes, nil
+}
+
+// GetExtraVolumesFromFlags returns the extra volumes of the extra-volume flag values followed by those of the file
+func GetExtraVolumesFromFlags(values []string, filePath string) ([]ExtraVolume, error) {
+	volumes := []ExtraVolume{}
+	for _, value := range values {
+		volume, err := ParseExtraVolume(value)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, *volume)
+	}
+	if len(filePath) > 0 {
+		fileVolumes, err := ReadExtraVolumesFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, fileVolumes...)
+	}
+	return volumes, nil
+}
+
+// Validate returns an error if the extra volume cannot be mounted
+func (v *ExtraVolume) Validate() error {
+	switch v.Type {
+	case ExtraVolumeTypePVC, ExtraVolumeTypeConfigMap, ExtraVolumeTypeSecret, ExtraVolumeTypeEmptyDir:
+	default:
+		return fmt.Errorf("invalid type '%s' of extra volume '%s', must be '%s', '%s', '%s' or '%s'", v.Type, v.Name, ExtraVolumeTypePVC, ExtraVolumeTypeConfigMap, ExtraVolumeTypeSecret, ExtraVolumeTypeEmptyDir)
+	}
+	if len(v.Component) == 0 || len(v.Name) == 0 {
+		return fmt.Errorf("extra volume '%s' requires a component and a name", v.Name)
+	}
+	if !strings.HasPrefix(v.MountPath, "/") {
+		return fmt.Errorf("mount path '%s' of extra volume '%s' must be absolute", v.MountPath, v.Name)
+	}
+	return nil
+}
+
+// volumeName returns the name of the extra volume in the pod spec
+func (v *ExtraVolume) volumeName() string {
+	name := "extra-" + strings.Trim(volumeNameRegexp.ReplaceAllString(strings.ToLower(v.Name), "-"), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// SetExtraVolumesInHelmValues stores the extra volumes in the Helm values, replacing the previous ones
+func SetExtraVolumesInHelmValues(helmValues map[string]interface{}, volumes []ExtraVolume) error {
+	// store plain JSON types so the values can be compared and stored by Helm
+	data, err := json.Marshal(volumes)
+	if err != nil {
+		return err
+	}
+	values := []interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	SetHelmValueInMap(helmValues, extraVolumesHelmPath, values)
+	return nil
+}
+
+// GetExtraVolumesFromHelmValues returns the extra volumes stored in the Helm values
+func GetExtraVolumesFromHelmValues(helmValues map[string]interface{}) ([]ExtraVolume, error) {
+	volumes := []ExtraVolume{}
+	values := GetHelmValueFromMap(helmValues, extraVolumesHelmPath)
+	if values == nil {
+		return volumes, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &volumes); err != nil {
+		return nil, fmt.Errorf("invalid extra volumes in the values due to %+v", err)
+	}
+	return volumes, nil
+}
+
+// getHelmPostRenderer returns the post-renderer for the Helm values, or nil if no post-rendering is needed
+func getHelmPostRenderer(helmValues map[string]interface{}) (postrender.PostRenderer, error) {
+	renderers := helmPostRenderers{}
+	volumes, err := GetExtraVolumesFromHelmValues(helmValues)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) > 0 {
+		renderers = append(renderers, &extraVolumesPostRenderer{volumes: volumes})
+	}
+	environs, err := GetSecretEnvironsFromHelmValues(helmValues)
+	if err != nil {
+		return nil, err
+	}
+	if len(environs) > 0 {
+		renderers = append(renderers, &secretEnvironsPostRenderer{environs: environs})
+	}
+	if certificateRenderer := getCertificateSecretKeysPostRenderer(helmValues); certificateRenderer != nil {
+		renderers = append(renderers, certificateRenderer)
+	}
+	if waitForDBRenderer := getWaitForDBPostRenderer(helmValues); waitForDBRenderer != nil {
+		renderers = append(renderers, waitForDBRenderer)
+	}
+	if imagesRenderer := getImagesPostRenderer(helmValues); imagesRenderer != nil {
+		renderers = append(renderers, imagesRenderer)
+	}
+	if len(renderers) == 0 {
+		return nil, nil
+	}
+	return renderers, nil
+}
+
+// helmPostRenderers runs the post-renderers one after the other
+type helmPostRenderers []postrender.PostRenderer
+
+// Run implements postrender.PostRenderer
+func (r helmPostRenderers) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	var err error
+	for _, renderer := range r {
+		if renderedManifests, err = renderer.Run(renderedManifests); err != nil {
+			return nil, err
+		}
+	}
+	return renderedManifests, nil
+}
+
+// extraVolumesPostRenderer adds the extra volumes to the workloads of their component
+type extraVolumesPostRenderer struct {
+	volumes []ExtraVolume
+}
+
+// Run implements postrender.PostRenderer
+func (r *extraVolumesPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	documents := strings.Split("\n"+renderedManifests.String(), "\n---")
+	mountedComponents := map[string]bool{}
+	output := &bytes.Buffer{}
+	for _, document := range documents {
+		if len(strings.TrimSpace(document)) == 0 {
+			continue
+		}
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+		}
+		components := []string{}
+		for _, volume := range r.volumes {
+			components = append(components, volume.Component)
+		}
+		podSpec, component := getWorkloadPodSpecAndComponent(object, components)
+		if podSpec != nil {
+			for _, volume := range r.volumes {
+				if volume.Component == component {
+					addExtraVolumeToPodSpec(podSpec, volume)
+					mountedComponents[component] = true
+				}
+			}
+			data, err := yaml.Marshal(object)
+			if err != nil {
+				return nil, err
+			}
+			document = "\n" + string(data)
+		}
+		fmt.Fprintf(output, "---%s\n", strings.TrimRight(document, "\n"))
+	}
+	for _, volume := range r.volumes {
+		if !mountedComponents[volume.Component] {
+			return nil, fmt.Errorf("component '%s' of extra volume '%s' does not match any workload", volume.Component, volume.Name)
+		}
+	}
+	return output, nil
+}
+
+// getWorkloadPodSpecAndComponent returns the pod spec of the workload if it belongs to one of the components.
+// The component matches the 'component' label of the workload or the suffix of its name
+func getWorkloadPodSpecAndComponent(object map[string]interface{}, components []string) (map[string]interface{}, string) {
+	podSpec := getWorkloadPodSpec(object)
+	if podSpec == nil {
+		return nil, ""
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	labels, _ := metadata["labels"].(map[string]interface{})
+	for _, component := range components {
+		if labels["component"] == component || strings.HasSuffix(name, "-"+component) {
+			return podSpec, component
+		}
+	}
+	return nil, ""
+}
+
+// getWorkloadPodSpec returns the pod spec of the pod template if the object is a workload
+func getWorkloadPodSpec(object map[string]interface{}) map[string]interface{} {
+	switch object["kind"] {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicationController", "Job":
+	default:
+		return nil
+	}
+	spec, _ := object["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	return podSpec
+}
+
+// addExtraVolumeToPodSpec adds the volume to the pod spec and mounts it in every container
+func addExtraVolumeToPodSpec(podSpec map[string]interface{}, volume ExtraVolume) {
+	source := map[string]interface{}{}
+	switch volume.Type {
+	case ExtraVolumeTypePVC:
+		source["persistentVolumeClaim"] = map[string]interface{}{"claimName": volume.Name, "readOnly": volume.ReadOnly}
+	case ExtraVolumeTypeConfigMap:
+		source["configMap"] = map[string]interface{}{"name": volume.Name}
+	case ExtraVolumeTypeSecret:
+		source["secret"] = map[string]interface{}{"secretName": volume.Name}
+	case ExtraVolumeTypeEmptyDir:
+		source["emptyDir"] = map[string]interface{}{}
+	}
+	source["name"] = volume.volumeName()
+	volumes, _ := podSpec["volumes"].([]interface{})
+	podSpec["volumes"] = append(volumes, source)
+
+	mount := map[string]interface{}{"name": volume.volumeName(), "mountPath": volume.MountPath}
+	if len(volume.SubPath) > 0 {
+		mount["subPath"] = volume.SubPath
+	}
+	if volume.ReadOnly {
+		mount["readOnly"] = true
+	}
+	containers, _ := podSpec["containers"].([]interface{})
+	for _, container := range containers {
+		if c, ok := container.(map[string]interface{}); ok {
+			mounts, _ := c["volumeMounts"].([]interface{})
+			c["volumeMounts"] = append(mounts, mount)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseExtraVolume(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected *ExtraVolume
+	}{
+		{
+			value:    "webapp=custom-certs:secret:/opt/certs:ro",
+			expected: &ExtraVolume{Component: "webapp", Name: "custom-certs", Type: ExtraVolumeTypeSecret, MountPath: "/opt/certs", ReadOnly: true},
+		},
+		{
+			value:    "jobrunner=scratch:EmptyDir:/tmp/scratch",
+			expected: &ExtraVolume{Component: "jobrunner", Name: "scratch", Type: ExtraVolumeTypeEmptyDir, MountPath: "/tmp/scratch"},
+		},
+		{value: "custom-certs:secret:/opt/certs", expected: nil},
+		{value: "webapp=custom-certs:hostpath:/opt/certs", expected: nil},
+		{value: "webapp=custom-certs:secret:opt/certs", expected: nil},
+		{value: "webapp=custom-certs:secret:/opt/certs:rw", expected: nil},
+	}
+
+	for _, test := range tests {
+		volume, err := ParseExtraVolume(test.value)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("expected an error for '%s'", test.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %+v", test.value, err)
+		} else if *volume != *test.expected {
+			t.Errorf("expected %+v, got %+v", *test.expected, *volume)
+		}
+	}
+}
+
+func TestExtraVolumesHelmValues(t *testing.T) {
+	volumes := []ExtraVolume{{Component: "webapp", Name: "custom-certs", Type: ExtraVolumeTypeSecret, MountPath: "/opt/certs", ReadOnly: true}}
+	helmValues := map[string]interface{}{}
+	if err := SetExtraVolumesInHelmValues(helmValues, volumes); err != nil {
+		t.Fatal(err)
+	}
+	result, err := GetExtraVolumesFromHelmValues(helmValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != volumes[0] {
+		t.Errorf("expected %+v, got %+v", volumes, result)
+	}
+}
+
+func TestExtraVolumesPostRenderer(t *testing.T) {
+	manifests := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webapp
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: webapp
+      - name: logstash
+`
+	renderer := &extraVolumesPostRenderer{volumes: []ExtraVolume{{Component: "webapp", Name: "custom-certs", Type: ExtraVolumeTypeSecret, MountPath: "/opt/certs", ReadOnly: true}}}
+	output, err := renderer.Run(bytes.NewBufferString(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := output.String()
+	if strings.Count(rendered, "mountPath: /opt/certs") != 2 || strings.Count(rendered, "secretName: custom-certs") != 1 {
+		t.Errorf("extra volume not added to the webapp deployment:\n%s", rendered)
+	}
+
+	renderer.volumes[0].Component = "unknown"
+	if _, err := renderer.Run(bytes.NewBufferString(manifests)); err == nil {
+		t.Errorf("expected an error for a component without workloads")
+	}
+}
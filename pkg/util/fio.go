@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FioCommand returns a short fio benchmark of random 8k reads and writes, the page size of Postgres, in the directory
+func FioCommand(directory string, runtime time.Duration) string {
+	return fmt.Sprintf("fio --name=postgres --directory=%s --rw=randrw --rwmixread=70 --bs=8k --size=256m --ioengine=libaio --direct=1 --iodepth=16 --time_based --runtime=%d --output-format=json",
+		directory, int(runtime.Seconds()))
+}
+
+// FioResult is the performance measured by a fio benchmark
+type FioResult struct {
+	ReadIOPS     float64
+	WriteIOPS    float64
+	ReadLatency  time.Duration
+	WriteLatency time.Duration
+}
+
+// IOPS returns the total number of read and write operations per second
+func (r FioResult) IOPS() float64 {
+	return r.ReadIOPS + r.WriteIOPS
+}
+
+// String ...
+func (r FioResult) String() string {
+	return fmt.Sprintf("%.0f read IOPS with %s latency, %.0f write IOPS with %s latency", r.ReadIOPS, r.ReadLatency, r.WriteIOPS, r.WriteLatency)
+}
+
+// fioOutput is the part of the JSON output of fio that is used
+type fioOutput struct {
+	Jobs []struct {
+		Read  fioStats `json:"read"`
+		Write fioStats `json:"write"`
+	} `json:"jobs"`
+}
+
+type fioStats struct {
+	IOPS        float64 `json:"iops"`
+	Completions struct {
+		Mean float64 `json:"mean"`
+	} `json:"clat_ns"`
+}
+
+// ParseFioResult parses the JSON output of fio, any text before the JSON object (e.g. warnings) is ignored
+func ParseFioResult(output string) (FioResult, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return FioResult{}, fmt.Errorf("the fio output doesn't contain a result: %s", output)
+	}
+	parsed := fioOutput{}
+	if err := json.Unmarshal([]byte(output[start:]), &parsed); err != nil {
+		return FioResult{}, fmt.Errorf("failed to parse the fio output due to %+v", err)
+	}
+	if len(parsed.Jobs) == 0 {
+		return FioResult{}, fmt.Errorf("the fio output doesn't contain a job")
+	}
+	job := parsed.Jobs[0]
+	return FioResult{
+		ReadIOPS:     job.Read.IOPS,
+		WriteIOPS:    job.Write.IOPS,
+		ReadLatency:  time.Duration(job.Read.Completions.Mean),
+		WriteLatency: time.Duration(job.Write.Completions.Mean),
+	}, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFioResult(t *testing.T) {
+	assert := assert.New(t)
+
+	output := `note: both iodepth >= 1 and synchronous I/O engine are selected
+{
+  "fio version" : "fio-3.13",
+  "jobs" : [
+    {
+      "jobname" : "postgres",
+      "read" : {"iops" : 2100.5, "clat_ns" : {"mean" : 1500000.0}},
+      "write" : {"iops" : 900.25, "clat_ns" : {"mean" : 4000000.0}}
+    }
+  ]
+}`
+	result, err := ParseFioResult(output)
+	assert.NoError(err)
+	assert.Equal(FioResult{ReadIOPS: 2100.5, WriteIOPS: 900.25, ReadLatency: 1500 * time.Microsecond, WriteLatency: 4 * time.Millisecond}, result)
+	assert.Equal(3000.75, result.IOPS())
+
+	_, err = ParseFioResult("fio: pid=0, err=13/file:filesetup.c:174, error=Permission denied")
+	assert.Error(err)
+	_, err = ParseFioResult(`{"jobs": []}`)
+	assert.Error(err)
+}
+
+func TestFioCommand(t *testing.T) {
+	assert := assert.New(t)
+	assert.Contains(FioCommand("/data", 30*time.Second), "--directory=/data")
+	assert.Contains(FioCommand("/data", 30*time.Second), "--runtime=30")
+}
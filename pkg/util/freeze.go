@@ -0,0 +1,257 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// FreezeAnnotation marks the Helm release records of a frozen instance, its value is the reason of the freeze
+	FreezeAnnotation = "synopsys.com/freeze"
+	// FreezeTimeAnnotation holds the time the instance was frozen at
+	FreezeTimeAnnotation = "synopsys.com/frozen-at"
+	// freezeWebhookService is the service the freeze webhook calls. It is never deployed, the webhook fails closed and
+	// so rejects every request it matches
+	freezeWebhookService = "synopsysctl-freeze"
+	// freezeWebhookMinKubeMinorVersion is the first minor version of Kubernetes 1.x that serves admissionregistration/v1
+	// and sets the 'kubernetes.io/metadata.name' label that the freeze webhook selects the namespace by
+	freezeWebhookMinKubeMinorVersion = 21
+)
+
+// OverrideFreeze allows the mutations of frozen instances. It is only set by the explicit --override-freeze flag
+var OverrideFreeze = false
+
+// ReleaseFreeze is the freeze of the instance of a release, e.g. for the period of a compliance audit
+type ReleaseFreeze struct {
+	Reason   string
+	FrozenAt string
+}
+
+// helmReleaseSelector returns the label selector of the secrets Helm stores the revisions of a release in
+func helmReleaseSelector(releaseName string) string {
+	return fmt.Sprintf("owner=helm, name=%s", releaseName)
+}
+
+// FreezeRelease annotates all revisions of the release as frozen, so that synopsysctl refuses to change the instance
+func FreezeRelease(clientset *kubernetes.Clientset, namespace string, releaseName string, reason string, now time.Time) error {
+	secrets, err := ListSecrets(clientset, namespace, helmReleaseSelector(releaseName))
+	if err != nil {
+		return fmt.Errorf("unable to list the revisions of release '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	if len(secrets.Items) == 0 {
+		return fmt.Errorf("release '%s' does not exist in namespace '%s'", releaseName, namespace)
+	}
+	for _, secret := range secrets.Items {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[FreezeAnnotation] = reason
+		secret.Annotations[FreezeTimeAnnotation] = now.UTC().Format(time.RFC3339)
+		if _, err := UpdateSecret(clientset, namespace, &secret); err != nil {
+			return fmt.Errorf("unable to freeze revision '%s' of release '%s' due to %+v", secret.Name, releaseName, err)
+		}
+	}
+	return nil
+}
+
+// UnfreezeRelease removes the freeze from all revisions of the release and returns false if the release wasn't frozen
+func UnfreezeRelease(clientset *kubernetes.Clientset, namespace string, releaseName string) (bool, error) {
+	secrets, err := ListSecrets(clientset, namespace, helmReleaseSelector(releaseName))
+	if err != nil {
+		return false, fmt.Errorf("unable to list the revisions of release '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	unfrozen := false
+	for _, secret := range secrets.Items {
+		if _, ok := secret.Annotations[FreezeAnnotation]; !ok {
+			continue
+		}
+		delete(secret.Annotations, FreezeAnnotation)
+		delete(secret.Annotations, FreezeTimeAnnotation)
+		if _, err := UpdateSecret(clientset, namespace, &secret); err != nil {
+			return unfrozen, fmt.Errorf("unable to unfreeze revision '%s' of release '%s' due to %+v", secret.Name, releaseName, err)
+		}
+		unfrozen = true
+	}
+	return unfrozen, nil
+}
+
+// GetReleaseFreeze returns the freeze of the release, nil if the release isn't frozen
+func GetReleaseFreeze(clientset kubernetes.Interface, namespace string, releaseName string) (*ReleaseFreeze, error) {
+	secrets, err := ListSecrets(clientset, namespace, helmReleaseSelector(releaseName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the revisions of release '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	return freezeFromSecrets(secrets.Items), nil
+}
+
+// freezeFromSecrets returns the freeze of the revisions of a release, a release is frozen if any revision is
+func freezeFromSecrets(secrets []corev1.Secret) *ReleaseFreeze {
+	for _, secret := range secrets {
+		if reason, ok := secret.Annotations[FreezeAnnotation]; ok {
+			return &ReleaseFreeze{Reason: reason, FrozenAt: secret.Annotations[FreezeTimeAnnotation]}
+		}
+	}
+	return nil
+}
+
+// CheckReleaseNotFrozen returns an error if the release is frozen and --override-freeze isn't set. The freeze webhook
+// would reject the changes of the override as well, so it is removed. A plan doesn't change the instance, so the
+// changes to a frozen instance can still be previewed
+func CheckReleaseNotFrozen(clientset kubernetes.Interface, namespace string, releaseName string) error {
+	if ActivePlan != nil {
+		return nil
+	}
+	freeze, err := GetReleaseFreeze(clientset, namespace, releaseName)
+	if err != nil || freeze == nil {
+		return err
+	}
+	if OverrideFreeze {
+		log.Warnf("release '%s' in namespace '%s' is frozen since %s, changing it because --override-freeze is set", releaseName, namespace, freeze.FrozenAt)
+		deleted, err := DeleteFreezeWebhook(clientset, namespace, releaseName)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			log.Warnf("deleted the validating webhook configuration '%s' of the freeze, run 'synopsysctl freeze' with --webhook to create it again", FreezeWebhookConfigurationName(namespace, releaseName))
+		}
+		return nil
+	}
+	message := fmt.Sprintf("release '%s' in namespace '%s' is frozen since %s", releaseName, namespace, freeze.FrozenAt)
+	if len(freeze.Reason) > 0 {
+		message = fmt.Sprintf("%s (%s)", message, freeze.Reason)
+	}
+	return ValidationError("%s, unfreeze it or use --override-freeze to change it", message)
+}
+
+// checkReleaseNotFrozenForActionConfig checks the freeze of the release with a client of the Helm action configuration
+func checkReleaseNotFrozenForActionConfig(actionConfig *action.Configuration, namespace string, releaseName string) error {
+	clientset, err := clientsetForActionConfig(actionConfig)
+	if err != nil {
+		return err
+	}
+	return CheckReleaseNotFrozen(clientset, namespace, releaseName)
+}
+
+// FreezeWebhookConfigurationName returns the name of the webhook configuration that freezes the resources of a release
+func FreezeWebhookConfigurationName(namespace string, releaseName string) string {
+	return fmt.Sprintf("synopsysctl-freeze-%s-%s", namespace, releaseName)
+}
+
+// CheckFreezeWebhookSupported returns an error if a cluster of the Kubernetes version can't run the freeze webhook. It
+// requires Kubernetes 1.21 or later, see freezeWebhookMinKubeMinorVersion
+func CheckFreezeWebhookSupported(kubeVersion string) error {
+	if len(kubeVersion) == 0 {
+		return fmt.Errorf("unable to get the version of the cluster, the freeze webhook requires Kubernetes 1.%d or later", freezeWebhookMinKubeMinorVersion)
+	}
+	minor, err := parseKubeMinorVersion(kubeVersion)
+	if err != nil {
+		return err
+	}
+	if minor < freezeWebhookMinKubeMinorVersion {
+		return ValidationError("the freeze webhook requires Kubernetes 1.%d or later, the cluster runs %s", freezeWebhookMinKubeMinorVersion, kubeVersion)
+	}
+	return nil
+}
+
+// NewFreezeWebhookConfiguration returns a validating webhook configuration that rejects the updates and deletions of
+// the resources of an instance, e.g. by kubectl. The webhook calls a service that is never deployed and fails closed,
+// so it rejects the changes of synopsysctl as well until --override-freeze or unfreeze deletes it. The namespace is
+// matched by the 'kubernetes.io/metadata.name' label, the configuration requires Kubernetes 1.21 or later
+func NewFreezeWebhookConfiguration(namespace string, releaseName string, instanceLabels map[string]string) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	path := "/"
+	operations := []admissionregistrationv1.OperationType{admissionregistrationv1.Update, admissionregistrationv1.Delete}
+	rules := []admissionregistrationv1.RuleWithOperations{}
+	for group, resources := range map[string][]string{
+		"":                   {"configmaps", "persistentvolumeclaims", "secrets", "serviceaccounts", "services"},
+		"apps":               {"daemonsets", "deployments", "deployments/scale", "statefulsets", "statefulsets/scale"},
+		"batch":              {"cronjobs", "jobs"},
+		"extensions":         {"ingresses"},
+		"networking.k8s.io":  {"ingresses"},
+		"route.openshift.io": {"routes"},
+	} {
+		rules = append(rules, admissionregistrationv1.RuleWithOperations{
+			Operations: operations,
+			Rule:       admissionregistrationv1.Rule{APIGroups: []string{group}, APIVersions: []string{"*"}, Resources: resources},
+		})
+	}
+	// the rules of a map are in random order, sort them so that the configuration is stable
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].APIGroups[0] < rules[j].APIGroups[0]
+	})
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "ValidatingWebhookConfiguration"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   FreezeWebhookConfigurationName(namespace, releaseName),
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "synopsysctl"},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: fmt.Sprintf("%s.%s.freeze.synopsys.com", releaseName, namespace),
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: namespace, Name: freezeWebhookService, Path: &path},
+				},
+				Rules:                   rules,
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+				NamespaceSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": namespace}},
+				ObjectSelector:          &metav1.LabelSelector{MatchLabels: instanceLabels},
+			},
+		},
+	}
+}
+
+// CreateFreezeWebhook creates the webhook configuration that freezes the resources of an instance
+func CreateFreezeWebhook(clientset kubernetes.Interface, webhook *admissionregistrationv1.ValidatingWebhookConfiguration) error {
+	_, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(webhook)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create the validating webhook configuration '%s' due to %+v", webhook.Name, err)
+	}
+	return nil
+}
+
+// DeleteFreezeWebhook deletes the webhook configuration that freezes the resources of a release and returns false if
+// it doesn't exist
+func DeleteFreezeWebhook(clientset kubernetes.Interface, namespace string, releaseName string) (bool, error) {
+	name := FreezeWebhookConfigurationName(namespace, releaseName)
+	err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(name, &metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to delete the validating webhook configuration '%s' due to %+v", name, err)
+	}
+	return true, nil
+}
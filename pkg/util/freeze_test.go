@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFreezeFromSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.bd.v1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.bd.v2"}},
+	}
+	assert.Nil(freezeFromSecrets(secrets))
+
+	secrets[1].Annotations = map[string]string{FreezeAnnotation: "SOC 2 audit", FreezeTimeAnnotation: "2020-06-01T12:00:00Z"}
+	assert.Equal(&ReleaseFreeze{Reason: "SOC 2 audit", FrozenAt: "2020-06-01T12:00:00Z"}, freezeFromSecrets(secrets))
+}
+
+func TestNewFreezeWebhookConfiguration(t *testing.T) {
+	assert := assert.New(t)
+
+	webhook := NewFreezeWebhookConfiguration("ns", "bd", map[string]string{"app": "blackduck", "name": "bd"})
+	assert.Equal("synopsysctl-freeze-ns-bd", webhook.Name)
+	assert.Len(webhook.Webhooks, 1)
+	hook := webhook.Webhooks[0]
+	assert.Equal("bd.ns.freeze.synopsys.com", hook.Name)
+	assert.Equal(admissionregistrationv1.Fail, *hook.FailurePolicy)
+	assert.Equal([]string{"v1"}, hook.AdmissionReviewVersions)
+	assert.Equal(map[string]string{"app": "blackduck", "name": "bd"}, hook.ObjectSelector.MatchLabels)
+	assert.Equal(map[string]string{"kubernetes.io/metadata.name": "ns"}, hook.NamespaceSelector.MatchLabels)
+	assert.Equal(freezeWebhookService, hook.ClientConfig.Service.Name)
+
+	groups := []string{}
+	for _, rule := range hook.Rules {
+		groups = append(groups, rule.APIGroups[0])
+		assert.Equal([]admissionregistrationv1.OperationType{admissionregistrationv1.Update, admissionregistrationv1.Delete}, rule.Operations)
+		// the pods and the endpoints are changed by the controllers of the cluster
+		assert.NotContains(rule.Resources, "pods")
+		assert.NotContains(rule.Resources, "endpoints")
+	}
+	assert.Equal([]string{"", "apps", "batch", "extensions", "networking.k8s.io", "route.openshift.io"}, groups)
+}
+
+func TestCheckFreezeWebhookSupported(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(CheckFreezeWebhookSupported("v1.21.0"))
+	assert.NoError(CheckFreezeWebhookSupported("v1.22.9-eks-4c6976"))
+	assert.NoError(CheckFreezeWebhookSupported("v1.25+"))
+	assert.Error(CheckFreezeWebhookSupported("v1.20.4"))
+	assert.Error(CheckFreezeWebhookSupported("v1.17.3"))
+	assert.Error(CheckFreezeWebhookSupported(""))
+	assert.Error(CheckFreezeWebhookSupported("latest"))
+}
+
+func TestCheckReleaseNotFrozen(t *testing.T) {
+	assert := assert.New(t)
+	defer func(overrideFreeze bool) { OverrideFreeze = overrideFreeze }(OverrideFreeze)
+
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:        "sh.helm.release.v1.bd.v1",
+				Namespace:   "ns",
+				Labels:      map[string]string{"owner": "helm", "name": "bd"},
+				Annotations: map[string]string{FreezeAnnotation: "SOC 2 audit", FreezeTimeAnnotation: "2020-06-01T12:00:00Z"},
+			}},
+			NewFreezeWebhookConfiguration("ns", "bd", map[string]string{"app": "blackduck", "name": "bd"}),
+		)
+	}
+	webhookExists := func(clientset *fake.Clientset) bool {
+		_, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(FreezeWebhookConfigurationName("ns", "bd"), metav1.GetOptions{})
+		return err == nil
+	}
+
+	// the webhook is kept while synopsysctl refuses to change the instance
+	OverrideFreeze = false
+	clientset := newClientset()
+	err := CheckReleaseNotFrozen(clientset, "ns", "bd")
+	assert.EqualError(err, "release 'bd' in namespace 'ns' is frozen since 2020-06-01T12:00:00Z (SOC 2 audit), unfreeze it or use --override-freeze to change it")
+	assert.True(webhookExists(clientset))
+
+	// the webhook would reject the changes of the override
+	OverrideFreeze = true
+	assert.NoError(CheckReleaseNotFrozen(clientset, "ns", "bd"))
+	assert.False(webhookExists(clientset))
+	assert.NoError(CheckReleaseNotFrozen(clientset, "ns", "bd"))
+
+	// other releases aren't frozen
+	OverrideFreeze = false
+	assert.NoError(CheckReleaseNotFrozen(newClientset(), "ns", "other"))
+}
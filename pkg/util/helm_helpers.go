@@ -40,7 +40,6 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
@@ -55,12 +54,6 @@ var settings = cli.New()
 // Modified from https://github.com/openshift/console/blob/cdf6b189b71e488033ecaba7d90258d9f9453478/pkg/helm/actions/install_chart.go
 // Helm Actions: https://github.com/helm/helm/tree/9bc7934f350233fa72a11d2d29065aa78ab62792/pkg/action
 func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeConfig string, dryRun bool, extraFiles ...string) error {
-	// Check if resouce already exists
-	existingRelease, _ := GetWithHelm3(releaseName, namespace, kubeConfig)
-	if existingRelease != nil {
-		return fmt.Errorf("release '%s' already exists in namespace '%s'", existingRelease.Name, existingRelease.Namespace)
-	}
-
 	// Create the new Release
 	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
 	if err != nil {
@@ -69,12 +62,18 @@ func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 
 	chart, err := LoadChart(chartURL, actionConfig)
 	if err != nil {
-		return fmt.Errorf("failed to load application resources at '%s' due to %s", chartURL, err)
+		return WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to load application resources at '%s' due to %s", chartURL, err))
 	}
 	validInstallableChart, err := isChartInstallable(chart)
 	if !validInstallableChart {
 		return fmt.Errorf("release at '%s' is not installable: %s", chartURL, err)
 	}
+	// Check if resouce already exists, e.g. an instance of another product with the same name
+	existingRelease, _ := GetWithHelm3(releaseName, namespace, kubeConfig)
+	if err := CheckReleaseNameCollision(existingRelease, chart.Metadata.Name); err != nil {
+		return err
+	}
+	EmitProgress(ProgressPhaseHelm, PlanHelmInstall, 10, "loaded chart '%s' for release '%s'", chartURL, releaseName)
 	if chart.Metadata.Deprecated {
 		log.Warnf("the release at '%s' is deprecated", chartURL)
 	}
@@ -116,11 +115,43 @@ func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 		return fmt.Errorf("failed to merge extra configuration files during create due to %s", err)
 	}
 	vals = MergeMaps(fileValues, vals)
+	if dryRun {
+		WarnIgnoredHelmValues(chart, vals)
+	}
+
+	if err := resolveImageDigestsInHelmValues(releaseName, namespace, chart, vals, actionConfig); err != nil {
+		return err
+	}
+	if client.PostRenderer, err = getReleasePostRenderer(vals, chart); err != nil {
+		return err
+	}
+
+	if err := verifyAPIVersionsForCluster(releaseName, namespace, chart, vals, actionConfig); err != nil {
+		return err
+	}
+	if err := CheckNameCollisionsForActionConfig(releaseName, namespace, chart, vals, actionConfig); err != nil {
+		return err
+	}
 
-	_, err = client.Run(chart, vals) // deploy the chart into the namespace from the actionConfig
+	if ActivePlan != nil && !dryRun {
+		return planHelmOperation(PlanHelmInstall, releaseName, namespace, chartURL, "", chart, vals, actionConfig, func() (*release.Release, error) {
+			client.DryRun = true
+			return client.Run(chart, vals)
+		})
+	}
+
+	EmitProgress(ProgressPhaseHelm, PlanHelmInstall, 50, "installing release '%s' in namespace '%s'", releaseName, namespace)
+	rel, err := client.Run(chart, vals) // deploy the chart into the namespace from the actionConfig
 	if err != nil {
-		return fmt.Errorf("failed to run install due to %s", err)
+		return WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to run install due to %s", err))
+	}
+	if dryRun {
+		WarnLintFindings(rel.Manifest)
+		return nil
 	}
+	saveReleaseSecretsForActionConfig(actionConfig, namespace, releaseName, rel.Version, vals)
+	annotateReleaseForActionConfig(actionConfig, namespace, rel, EventReasonInstall, fmt.Sprintf("installed release '%s' from chart '%s'", releaseName, chartURL))
+	EmitProgress(ProgressPhaseHelm, PlanHelmInstall, 100, "installed release '%s' in namespace '%s'", releaseName, namespace)
 	return nil
 }
 
@@ -142,6 +173,7 @@ func UpdateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 	if !validInstallableChart {
 		return fmt.Errorf("release at '%s' is not installable: %s", chartURL, err)
 	}
+	EmitProgress(ProgressPhaseHelm, PlanHelmUpgrade, 10, "loaded chart '%s' for release '%s'", chartURL, releaseName)
 
 	client := action.NewUpgrade(actionConfig)
 	if client.Version == "" && client.Devel {
@@ -153,41 +185,183 @@ func UpdateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 		return fmt.Errorf("failed to merge extra configuration files during update due to %s", err)
 	}
 
-	client.ResetValues = true                     // rememeber the values that have been set previously
-	_, err = client.Run(releaseName, chart, vals) // updates the release in the namespace from the actionConfig
+	if err := resolveImageDigestsInHelmValues(releaseName, namespace, chart, vals, actionConfig); err != nil {
+		return err
+	}
+	if client.PostRenderer, err = getReleasePostRenderer(vals, chart); err != nil {
+		return err
+	}
+
+	if err := verifyAPIVersionsForCluster(releaseName, namespace, chart, vals, actionConfig); err != nil {
+		return err
+	}
+
+	if ActivePlan != nil {
+		currentRelease, err := GetWithHelm3(releaseName, namespace, kubeConfig)
+		if err != nil {
+			return err
+		}
+		return planHelmOperation(PlanHelmUpgrade, releaseName, namespace, chartURL, currentRelease.Manifest, chart, vals, actionConfig, func() (*release.Release, error) {
+			client.DryRun = true
+			client.ResetValues = true
+			return client.Run(releaseName, chart, vals)
+		})
+	}
+
+	if err := checkReleaseNotFrozenForActionConfig(actionConfig, namespace, releaseName); err != nil {
+		return err
+	}
+	EmitProgress(ProgressPhaseHelm, PlanHelmUpgrade, 50, "upgrading release '%s' in namespace '%s'", releaseName, namespace)
+	client.ResetValues = true                        // rememeber the values that have been set previously
+	rel, err := client.Run(releaseName, chart, vals) // updates the release in the namespace from the actionConfig
 	if err != nil {
-		return fmt.Errorf("failed to run upgrade: %s", err)
+		return WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to run upgrade: %s", err))
 	}
+	saveReleaseSecretsForActionConfig(actionConfig, namespace, releaseName, rel.Version, vals)
+	annotateReleaseForActionConfig(actionConfig, namespace, rel, EventReasonUpgrade, fmt.Sprintf("upgraded release '%s' to revision %d with chart '%s'", releaseName, rel.Version, chartURL))
+	EmitProgress(ProgressPhaseHelm, PlanHelmUpgrade, 100, "upgraded release '%s' in namespace '%s' to revision %d", releaseName, namespace, rel.Version)
 	return nil
 }
 
+// RollbackWithHelm3 uses the helm NewRollback action to roll the release back to a revision, or to the revision before
+// the current one if the revision is 0, and returns the revision that was restored
+func RollbackWithHelm3(releaseName, namespace, kubeConfig string, revision int) (int, error) {
+	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	if err != nil {
+		return 0, err
+	}
+	history, err := action.NewHistory(actionConfig).Run(releaseName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the history of release '%s' due to %s", releaseName, err)
+	}
+	targetRevision, err := getRollbackRevision(history, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	if ActivePlan != nil {
+		currentRelease, err := GetWithHelm3(releaseName, namespace, kubeConfig)
+		if err != nil {
+			return 0, err
+		}
+		ActivePlan.AddHelmOperation(PlanHelmRollback, releaseName, namespace, "")
+		for _, rel := range history {
+			if rel.Version != targetRevision {
+				continue
+			}
+			if err := ActivePlan.AddHelmManifests(currentRelease.Manifest, rel.Manifest, namespace); err != nil {
+				return 0, err
+			}
+		}
+		return 0, ErrPlanComplete
+	}
+
+	if err := checkReleaseNotFrozenForActionConfig(actionConfig, namespace, releaseName); err != nil {
+		return 0, err
+	}
+	EmitProgress(ProgressPhaseHelm, PlanHelmRollback, 0, "rolling release '%s' in namespace '%s' back to revision %d", releaseName, namespace, targetRevision)
+	client := action.NewRollback(actionConfig)
+	client.Version = targetRevision
+	if err := client.Run(releaseName); err != nil {
+		return 0, WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to run rollback due to %s", err))
+	}
+	// the rollback creates a new revision with the manifest of the target revision
+	rel, err := actionConfig.Releases.Last(releaseName)
+	if err != nil {
+		log.Warnf("unable to get the revision of the rollback of release '%s' due to %+v", releaseName, err)
+		rel = nil
+	}
+	annotateReleaseForActionConfig(actionConfig, namespace, rel, EventReasonRollback, fmt.Sprintf("rolled release '%s' back to revision %d", releaseName, targetRevision))
+	EmitProgress(ProgressPhaseHelm, PlanHelmRollback, 100, "rolled release '%s' in namespace '%s' back to revision %d", releaseName, namespace, targetRevision)
+	return targetRevision, nil
+}
+
+// getRollbackRevision returns the revision to roll back to, the revision before the latest one if the revision is 0
+func getRollbackRevision(history []*release.Release, revision int) (int, error) {
+	if len(history) == 0 {
+		return 0, fmt.Errorf("release has no revisions")
+	}
+	latest := 0
+	found := false
+	for _, rel := range history {
+		if rel.Version > latest {
+			latest = rel.Version
+		}
+		if rel.Version == revision {
+			found = true
+		}
+	}
+	if revision == 0 {
+		if latest <= 1 {
+			return 0, ValidationError("release has no revision before revision %d to roll back to", latest)
+		}
+		return latest - 1, nil
+	}
+	if !found {
+		return 0, ValidationError("revision %d was not found in the history of the release", revision)
+	}
+	if revision == latest {
+		return 0, ValidationError("revision %d is the current revision of the release", revision)
+	}
+	return revision, nil
+}
+
 // TemplateWithHelm3 prints the kube manifest files for a resource
 func TemplateWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, extraFiles ...string) error {
-	actionConfig, err := CreateHelmActionConfiguration("", "", namespace)
+	templateOutput, err := renderChartManifests(releaseName, namespace, chartURL, vals, extraFiles...)
 	if err != nil {
 		return err
 	}
+	fmt.Printf("%+v\n", templateOutput)
+	return nil
+}
+
+// RenderWithHelm3 returns the kube manifest files for a resource
+func RenderWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, extraFiles ...string) (string, error) {
+	return renderChartManifests(releaseName, namespace, chartURL, vals, extraFiles...)
+}
+
+// LintWithHelm3 renders the kube manifest files for a resource and lints them for the Kubernetes version
+func LintWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeVersion string, extraFiles ...string) ([]LintFinding, error) {
+	manifests, err := renderChartManifests(releaseName, namespace, chartURL, vals, extraFiles...)
+	if err != nil {
+		return nil, err
+	}
+	return LintManifests(manifests, kubeVersion)
+}
+
+// renderChartManifests loads the chart and renders the kube manifest files with the values and the extra files of the chart
+func renderChartManifests(releaseName, namespace, chartURL string, vals map[string]interface{}, extraFiles ...string) (string, error) {
+	actionConfig, err := CreateHelmActionConfiguration("", "", namespace)
+	if err != nil {
+		return "", err
+	}
 	chart, err := LoadChart(chartURL, actionConfig)
 	if err != nil {
-		return err
+		return "", err
 	}
+	return RenderLoadedChartManifests(releaseName, namespace, chart, vals, actionConfig, extraFiles...)
+}
+
+// RenderLoadedChartManifests renders the kube manifest files of a loaded chart with the values and the extra files of the chart
+func RenderLoadedChartManifests(releaseName, namespace string, chart *chart.Chart, vals map[string]interface{}, actionConfig *action.Configuration, extraFiles ...string) (string, error) {
 	validInstallableChart, err := isChartInstallable(chart)
 	if !validInstallableChart {
-		return err
+		return "", err
 	}
 
 	fileValues := map[string]interface{}{}
 	if err := mergeValuesWithExtraFilesFromChart(chart, fileValues, extraFiles); err != nil {
-		return fmt.Errorf("failed to merge extra configuration files during template due to %s", err)
+		return "", fmt.Errorf("failed to merge extra configuration files during template due to %s", err)
 	}
 	vals = MergeMaps(fileValues, vals)
+	WarnIgnoredHelmValues(chart, vals)
 
 	templateOutput, err := RenderManifests(releaseName, namespace, chart, vals, actionConfig)
 	if err != nil {
-		return fmt.Errorf("failed to render kube manifest files due to %s", err)
+		return "", fmt.Errorf("failed to render kube manifest files due to %s", err)
 	}
-	fmt.Printf("%+v\n", templateOutput)
-	return nil
+	return templateOutput, nil
 }
 
 // RenderManifests converts a helm chart to a string of the kube manifest files
@@ -208,6 +382,12 @@ func RenderManifests(releaseName, namespace string, chart *chart.Chart, vals map
 	client.ClientOnly = !validate
 	client.IncludeCRDs = includeCrds
 
+	postRenderer, err := getReleasePostRenderer(vals, chart)
+	if err != nil {
+		return emptyResponse, err
+	}
+	client.PostRenderer = postRenderer
+
 	rel, err := client.Run(chart, vals)
 	if err != nil {
 		return emptyResponse, err
@@ -236,14 +416,74 @@ func DeleteWithHelm3(releaseName, namespace, kubeConfig string) error {
 	if releaseExists := ReleaseExists(releaseName, namespace, kubeConfig); !releaseExists {
 		return fmt.Errorf("release '%s' does not exist", releaseName)
 	}
+	if ActivePlan != nil {
+		currentRelease, err := GetWithHelm3(releaseName, namespace, kubeConfig)
+		if err != nil {
+			return err
+		}
+		ActivePlan.AddHelmOperation(PlanHelmUninstall, releaseName, namespace, "")
+		if err := ActivePlan.AddHelmManifests(currentRelease.Manifest, "", namespace); err != nil {
+			return err
+		}
+		return ErrPlanComplete
+	}
+	if err := checkReleaseNotFrozenForActionConfig(actionConfig, namespace, releaseName); err != nil {
+		return err
+	}
+	EmitProgress(ProgressPhaseHelm, PlanHelmUninstall, 0, "uninstalling release '%s' in namespace '%s'", releaseName, namespace)
 	client := action.NewUninstall(actionConfig)
 	_, err = client.Run(releaseName) // deletes the releaseName from the namespace in the actionConfig
 	if err != nil {
-		return fmt.Errorf("failed to run uninstall due to %s", err)
+		return WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to run uninstall due to %s", err))
 	}
+	deleteReleaseSecretsForActionConfig(actionConfig, namespace, releaseName)
+	recordReleaseEventForActionConfig(actionConfig, namespace, EventReasonUninstall, fmt.Sprintf("uninstalled release '%s'", releaseName))
+	EmitProgress(ProgressPhaseHelm, PlanHelmUninstall, 100, "uninstalled release '%s' in namespace '%s'", releaseName, namespace)
 	return nil
 }
 
+// verifyAPIVersionsForCluster renders the chart and checks that the cluster serves the API versions of the objects, so
+// that an install or upgrade of an old chart version fails before Helm applies a part of the objects
+func verifyAPIVersionsForCluster(releaseName, namespace string, chart *chart.Chart, vals map[string]interface{}, actionConfig *action.Configuration) error {
+	manifest, err := RenderManifests(releaseName, namespace, chart, vals, actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests to check the API versions due to %+v", err)
+	}
+	discoveryClient, err := actionConfig.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("unable to create the discovery client due to %+v", err)
+	}
+	kubeVersion := ""
+	if version, err := discoveryClient.ServerVersion(); err == nil {
+		kubeVersion = version.GitVersion
+	}
+	return CheckAPIVersions(manifest, discoveryClient, kubeVersion)
+}
+
+// planHelmOperation adds a Helm operation and the changes of its manifest to the active plan instead of running it. The
+// manifest is rendered locally, or by the Helm dry run of the operation against the cluster in a server dry run
+func planHelmOperation(operation, releaseName, namespace, chartURL, currentManifest string, chart *chart.Chart, vals map[string]interface{}, actionConfig *action.Configuration, serverDryRun func() (*release.Release, error)) error {
+	var manifest string
+	if ActivePlan.DryRun == DryRunServer {
+		rel, err := serverDryRun()
+		if err != nil {
+			return WithExitCode(ExitCodeHelmFailure, fmt.Errorf("failed to run the %s dry run due to %s", operation, err))
+		}
+		manifest = rel.Manifest
+	} else {
+		var err error
+		manifest, err = RenderManifests(releaseName, namespace, chart, vals, actionConfig)
+		if err != nil {
+			return fmt.Errorf("failed to render the manifests of the plan due to %s", err)
+		}
+	}
+	ActivePlan.AddHelmOperation(operation, releaseName, namespace, chartURL)
+	if err := ActivePlan.AddHelmManifests(currentManifest, manifest, namespace); err != nil {
+		return err
+	}
+	return ErrPlanComplete
+}
+
 // GetWithHelm3 uses the helm NewGet action to return a Release with information about
 // a resource from the cluster
 func GetWithHelm3(releaseName, namespace, kubeConfig string) (*release.Release, error) {
@@ -265,6 +505,21 @@ func GetWithHelm3(releaseName, namespace, kubeConfig string) (*release.Release,
 	return nil, fmt.Errorf("unable to find release '%s' in namespace '%s'", releaseName, namespace)
 }
 
+// ListReleasesWithHelm3 returns the Helm releases of the namespace, or of all namespaces if the namespace is empty
+func ListReleasesWithHelm3(namespace, kubeConfig string) ([]*release.Release, error) {
+	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	if err != nil {
+		return nil, err
+	}
+	aList := action.NewList(actionConfig)
+	aList.AllNamespaces = len(namespace) == 0
+	releases, err := aList.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the releases due to %s", err)
+	}
+	return releases, nil
+}
+
 // ConvertFilesFromChartToMap checks whether an file exist in the chart. If exist, it will convert the file to map and return the map
 func ConvertFilesFromChartToMap(namespace, kubeConfig, chartURL, fileName string) (map[string]interface{}, error) {
 	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
@@ -319,7 +574,7 @@ func GetActionConfigurations(host, namespace, token string, transport *http.Roun
 	inClusterCfg, err := rest.InClusterConfig()
 
 	if err != nil {
-		fmt.Print("Running outside cluster, CAFile is unset")
+		fmt.Fprintln(MessageWriter(), "Running outside cluster, CAFile is unset")
 	} else {
 		confFlags.CAFile = &inClusterCfg.CAFile
 	}
@@ -334,6 +589,36 @@ func GetActionConfigurations(host, namespace, token string, transport *http.Roun
 func LoadChart(chartURL string, actionConfig *action.Configuration) (*chart.Chart, error) {
 	client := action.NewInstall(actionConfig)
 
+	if Offline {
+		if err := ValidateOfflineChartLocation(chartURL); err != nil {
+			return nil, err
+		}
+	}
+
+	// Helm doesn't download from object storages
+	if IsObjectStorageChartLocation(chartURL) {
+		chartPath, err := DownloadObjectStorageChart(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		chartURL = chartPath
+	}
+	if IsChartRepoConfigured() && IsHTTPChartLocation(chartURL) {
+		chartPath, err := DownloadHTTPChart(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		chartURL = chartPath
+	}
+	// Helm 3.1 only pulls the charts of OCI registries into its cache with the experimental registry commands
+	if IsOCIChartLocation(chartURL) {
+		chartPath, err := DownloadOCIChart(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		chartURL = chartPath
+	}
+
 	// Get full path - checks local machine and chart repository
 	chartFullPath, err := client.ChartPathOptions.LocateChart(chartURL, settings)
 	if err != nil {
@@ -347,6 +632,29 @@ func LoadChart(chartURL string, actionConfig *action.Configuration) (*chart.Char
 	return chart, nil
 }
 
+// ChartHasHelmValue returns true if the default values of the chart contain the key, e.g. to check
+// whether a chart version supports a setting
+func ChartHasHelmValue(chartURL string, keyList []string) (bool, error) {
+	actionConfig, err := CreateHelmActionConfiguration("", "", "")
+	if err != nil {
+		return false, err
+	}
+	chart, err := LoadChart(chartURL, actionConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to load the chart at '%s' due to %+v", chartURL, err)
+	}
+	// the key may be set to null in the values of the chart
+	parent, ok := GetHelmValueFromMap(chart.Values, keyList[:len(keyList)-1]).(map[string]interface{})
+	if len(keyList) == 1 {
+		parent, ok = chart.Values, true
+	}
+	if !ok {
+		return false, nil
+	}
+	_, found := parent[keyList[len(keyList)-1]]
+	return found, nil
+}
+
 // ParseChartVersion ...
 func ParseChartVersion(chartURL string) string {
 	chartPackageSplit := ParsePackageName(chartURL)
@@ -488,7 +796,7 @@ func GetIndexFile(repoURL string, actionConfig *action.Configuration) (*repo.Ind
 	keyFile := client.ChartPathOptions.KeyFile
 	caFile := client.ChartPathOptions.CaFile
 
-	getters := getter.All(settings)
+	getters := getChartRepoGetters(repoURL)
 
 	// Download and write the index file to a temporary location
 	buf := make([]byte, 20)
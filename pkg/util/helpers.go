@@ -131,7 +131,7 @@ func IsExistInStringSlice(slice []string, str string) bool {
 // IsExposeServiceValid validates the expose service type
 func IsExposeServiceValid(serviceType string) bool {
 	switch strings.ToUpper(serviceType) {
-	case NONE, NODEPORT, LOADBALANCER, OPENSHIFT:
+	case NONE, NODEPORT, LOADBALANCER, OPENSHIFT, INGRESS:
 		return true
 	}
 	return false
@@ -0,0 +1,169 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/messages"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// synopsysctlHelmValuesKey is the top level key of the values that are consumed by synopsysctl instead of the chart
+const synopsysctlHelmValuesKey = "synopsysctl"
+
+// IgnoredHelmValue is a value that the chart will not consume
+type IgnoredHelmValue struct {
+	Path       string
+	Suggestion string
+}
+
+// String returns the warning for the ignored value
+func (v IgnoredHelmValue) String() string {
+	if len(v.Suggestion) > 0 {
+		return messages.Get(messages.IgnoredHelmValueSuggestion, messages.Args{"Path": v.Path, "Suggestion": v.Suggestion})
+	}
+	return messages.Get(messages.IgnoredHelmValue, messages.Args{"Path": v.Path})
+}
+
+// FindIgnoredHelmValues returns the values that neither exist in the default values of the chart (or its
+// sub-charts) nor are referenced by its templates, e.g. typos or settings that the chart version doesn't support
+func FindIgnoredHelmValues(ch *chart.Chart, vals map[string]interface{}) []IgnoredHelmValue {
+	defaults := chartDefaultValues(ch)
+	templates := chartTemplateData(ch)
+	ignored := []IgnoredHelmValue{}
+	for key, value := range vals {
+		if key == synopsysctlHelmValuesKey || key == "global" {
+			continue
+		}
+		ignored = append(ignored, findIgnoredHelmValues(defaults, templates, []string{key}, value)...)
+	}
+	sort.Slice(ignored, func(i, j int) bool { return ignored[i].Path < ignored[j].Path })
+	return ignored
+}
+
+// WarnIgnoredHelmValues logs a warning for each value that the chart will not consume
+func WarnIgnoredHelmValues(ch *chart.Chart, vals map[string]interface{}) {
+	for _, ignoredValue := range FindIgnoredHelmValues(ch, vals) {
+		log.Warnf("%s", ignoredValue)
+	}
+}
+
+func findIgnoredHelmValues(defaults map[string]interface{}, templates string, keyList []string, value interface{}) []IgnoredHelmValue {
+	parentKeys := keyList[:len(keyList)-1]
+	key := keyList[len(keyList)-1]
+
+	siblings := defaults
+	if len(parentKeys) > 0 {
+		parent, ok := GetHelmValueFromMap(defaults, parentKeys).(map[string]interface{})
+		if !ok || len(parent) == 0 {
+			// free-form values such as nodeSelector or annotations
+			return nil
+		}
+		siblings = parent
+	}
+
+	defaultValue, found := siblings[key]
+	if !found {
+		if strings.Contains(templates, ".Values."+strings.Join(keyList, ".")) {
+			return nil
+		}
+		return []IgnoredHelmValue{{Path: strings.Join(keyList, "."), Suggestion: suggestHelmValueKey(parentKeys, key, siblings)}}
+	}
+
+	valueMap, isMap := value.(map[string]interface{})
+	if _, defaultIsMap := defaultValue.(map[string]interface{}); !isMap || !defaultIsMap {
+		return nil
+	}
+	ignored := []IgnoredHelmValue{}
+	for childKey, childValue := range valueMap {
+		childKeyList := append(append([]string{}, keyList...), childKey)
+		ignored = append(ignored, findIgnoredHelmValues(defaults, templates, childKeyList, childValue)...)
+	}
+	return ignored
+}
+
+// chartDefaultValues returns the default values of the chart including the values of its sub-charts
+func chartDefaultValues(ch *chart.Chart) map[string]interface{} {
+	defaults := map[string]interface{}{}
+	for _, dependency := range ch.Dependencies() {
+		defaults[dependency.Name()] = chartDefaultValues(dependency)
+	}
+	return MergeMaps(defaults, ch.Values)
+}
+
+// chartTemplateData returns the templates of the chart and its sub-charts as a single string
+func chartTemplateData(ch *chart.Chart) string {
+	var data strings.Builder
+	for _, template := range ch.Templates {
+		data.Write(template.Data)
+	}
+	for _, dependency := range ch.Dependencies() {
+		data.WriteString(chartTemplateData(dependency))
+	}
+	return data.String()
+}
+
+// suggestHelmValueKey returns the path of the known key that is closest to an unknown key
+func suggestHelmValueKey(parentKeys []string, key string, known map[string]interface{}) string {
+	suggestion, bestDistance := "", 3
+	for knownKey := range known {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(knownKey))
+		if distance < bestDistance || (distance == bestDistance && len(suggestion) > 0 && knownKey < suggestion) {
+			suggestion, bestDistance = knownKey, distance
+		}
+	}
+	if len(suggestion) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, parentKeys...), suggestion), ".")
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestFindIgnoredHelmValues(t *testing.T) {
+	postgres := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "postgres"},
+		Values:   map[string]interface{}{"port": 5432},
+	}
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "blackduck"},
+		Values: map[string]interface{}{
+			"imageTag":     "2020.10.0",
+			"nodeSelector": map[string]interface{}{},
+			"redis": map[string]interface{}{
+				"maxTotal": 128,
+			},
+		},
+		Templates: []*chart.File{{Name: "templates/web.yaml", Data: []byte("{{ .Values.webserver.port }}")}},
+	}
+	ch.AddDependency(postgres)
+
+	vals := map[string]interface{}{
+		"imageTag":     "2020.12.0",
+		"imagetag":     "2020.12.0",
+		"nodeSelector": map[string]interface{}{"disk": "ssd"},
+		"redis": map[string]interface{}{
+			"maxTotl": 64,
+		},
+		"postgres":    map[string]interface{}{"port": 5433, "prot": "db"},
+		"webserver":   map[string]interface{}{"port": 8443},
+		"synopsysctl": map[string]interface{}{"pinImageDigests": true},
+		"unknown":     true,
+	}
+
+	assert.Equal(t, []IgnoredHelmValue{
+		{Path: "imagetag", Suggestion: "imageTag"},
+		{Path: "postgres.prot", Suggestion: "postgres.port"},
+		{Path: "redis.maxTotl", Suggestion: "redis.maxTotal"},
+		{Path: "unknown"},
+	}, FindIgnoredHelmValues(ch, vals))
+}
+
+func TestIgnoredHelmValueString(t *testing.T) {
+	assert.Equal(t, "value 'imagetag' is not used by the chart and will be ignored, did you mean 'imageTag'?", IgnoredHelmValue{Path: "imagetag", Suggestion: "imageTag"}.String())
+	assert.Equal(t, "value 'unknown' is not used by the chart and will be ignored", IgnoredHelmValue{Path: "unknown"}.String())
+}
@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Paths of the image settings in the Helm values. The settings are not used by the charts,
+// they are applied by post-rendering the manifests
+var (
+	imagePullPolicyHelmPath = []string{"synopsysctl", "imagePullPolicy"}
+	pinImageDigestsHelmPath = []string{"synopsysctl", "pinImageDigests"}
+	imageDigestsHelmPath    = []string{"synopsysctl", "imageDigests"}
+)
+
+// ValidateImagePullPolicy returns an error if the policy is not a Kubernetes image pull policy
+func ValidateImagePullPolicy(policy string) error {
+	switch policy {
+	case "Always", "IfNotPresent", "Never":
+		return nil
+	}
+	return fmt.Errorf("invalid image pull policy '%s', must be 'Always', 'IfNotPresent' or 'Never'", policy)
+}
+
+// SetImagePullPolicyInHelmValues sets the image pull policy of all containers, an empty policy keeps the policies of the chart
+func SetImagePullPolicyInHelmValues(helmValues map[string]interface{}, policy string) error {
+	if len(policy) == 0 {
+		deleteSynopsysctlHelmValue(helmValues, imagePullPolicyHelmPath[1])
+		return nil
+	}
+	if err := ValidateImagePullPolicy(policy); err != nil {
+		return err
+	}
+	SetHelmValueInMap(helmValues, imagePullPolicyHelmPath, policy)
+	return nil
+}
+
+// SetPinImageDigestsInHelmValues enables or disables pinning the images to their digests. Disabling it drops the recorded digests
+func SetPinImageDigestsInHelmValues(helmValues map[string]interface{}, pin bool) {
+	if !pin {
+		deleteSynopsysctlHelmValue(helmValues, pinImageDigestsHelmPath[1])
+		deleteSynopsysctlHelmValue(helmValues, imageDigestsHelmPath[1])
+		return
+	}
+	SetHelmValueInMap(helmValues, pinImageDigestsHelmPath, true)
+}
+
+// deleteSynopsysctlHelmValue deletes a key from the synopsysctl Helm values
+func deleteSynopsysctlHelmValue(helmValues map[string]interface{}, key string) {
+	if synopsysctlValues, ok := GetHelmValueFromMap(helmValues, []string{"synopsysctl"}).(map[string]interface{}); ok {
+		delete(synopsysctlValues, key)
+	}
+}
+
+// getImageDigestsFromHelmValues returns the recorded images, mapping the image with its tag to the image with its digest
+func getImageDigestsFromHelmValues(helmValues map[string]interface{}) map[string]string {
+	digests := map[string]string{}
+	values, _ := GetHelmValueFromMap(helmValues, imageDigestsHelmPath).(map[string]interface{})
+	for image, digest := range values {
+		if digestString, ok := digest.(string); ok {
+			digests[image] = digestString
+		}
+	}
+	return digests
+}
+
+// resolveImageDigestsInHelmValues records the digests of the images that are rendered by the chart if pinning is enabled.
+// Images that are already recorded are not resolved again, so the installed images do not change on update, and
+// images that are no longer rendered, e.g. after a version change, are dropped
+func resolveImageDigestsInHelmValues(releaseName, namespace string, chart *chart.Chart, vals map[string]interface{}, actionConfig *action.Configuration) error {
+	if pin, _ := GetHelmValueFromMap(vals, pinImageDigestsHelmPath).(bool); !pin {
+		return nil
+	}
+	recordedDigests := getImageDigestsFromHelmValues(vals)
+	// render the images with their tags
+	deleteSynopsysctlHelmValue(vals, imageDigestsHelmPath[1])
+	manifests, err := RenderManifests(releaseName, namespace, chart, vals, actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests to resolve the image digests due to %+v", err)
+	}
+	objects, err := SplitManifests(manifests)
+	if err != nil {
+		return err
+	}
+	digests := map[string]interface{}{}
+	for _, object := range objects {
+		podSpec := getWorkloadPodSpec(object)
+		if podSpec == nil {
+			continue
+		}
+		for _, container := range getPodSpecContainers(podSpec) {
+			image, _ := container["image"].(string)
+			// images that are pinned by the chart contain their digest already
+			if len(image) == 0 || strings.Contains(image, "@") {
+				continue
+			}
+			if _, ok := digests[image]; ok {
+				continue
+			}
+			if digest, ok := recordedDigests[image]; ok {
+				digests[image] = digest
+				continue
+			}
+			digest, err := ResolveImageDigest(image)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the digest of image '%s' due to %+v", image, err)
+			}
+			digests[image] = digest
+		}
+	}
+	SetHelmValueInMap(vals, imageDigestsHelmPath, digests)
+	return nil
+}
+
+// getPodSpecContainers returns the init containers and containers of the pod spec
+func getPodSpecContainers(podSpec map[string]interface{}) []map[string]interface{} {
+	containers := []map[string]interface{}{}
+	for _, key := range []string{"initContainers", "containers"} {
+		list, _ := podSpec[key].([]interface{})
+		for _, container := range list {
+			if c, ok := container.(map[string]interface{}); ok {
+				containers = append(containers, c)
+			}
+		}
+	}
+	return containers
+}
+
+// getImagesPostRenderer returns the post-renderer for the image pull policy and digests, or nil if none are set
+func getImagesPostRenderer(helmValues map[string]interface{}) *imagesPostRenderer {
+	policy, _ := GetHelmValueFromMap(helmValues, imagePullPolicyHelmPath).(string)
+	digests := getImageDigestsFromHelmValues(helmValues)
+	if len(policy) == 0 && len(digests) == 0 {
+		return nil
+	}
+	return &imagesPostRenderer{pullPolicy: policy, digests: digests}
+}
+
+// imagesPostRenderer sets the image pull policy of all containers and replaces the images by their recorded digests
+type imagesPostRenderer struct {
+	pullPolicy string
+	digests    map[string]string
+}
+
+// Run implements postrender.PostRenderer
+func (r *imagesPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	objects, err := SplitManifests(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+	}
+	output := &bytes.Buffer{}
+	for _, object := range objects {
+		if podSpec := getWorkloadPodSpec(object); podSpec != nil {
+			for _, container := range getPodSpecContainers(podSpec) {
+				image, _ := container["image"].(string)
+				if digest, ok := r.digests[image]; ok {
+					container["image"] = digest
+				}
+				if len(r.pullPolicy) > 0 {
+					container["imagePullPolicy"] = r.pullPolicy
+				}
+			}
+		}
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(output, "---\n%s\n", strings.TrimRight(string(data), "\n"))
+	}
+	return output, nil
+}
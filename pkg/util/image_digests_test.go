@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetImagePullPolicyInHelmValues(t *testing.T) {
+	tests := []struct {
+		policy      string
+		expectError bool
+	}{
+		{policy: "Always", expectError: false},
+		{policy: "IfNotPresent", expectError: false},
+		{policy: "Never", expectError: false},
+		{policy: "", expectError: false},
+		{policy: "always", expectError: true},
+	}
+
+	for _, test := range tests {
+		helmValues := map[string]interface{}{}
+		err := SetImagePullPolicyInHelmValues(helmValues, test.policy)
+		if (err != nil) != test.expectError {
+			t.Errorf("unexpected error for '%s': %+v", test.policy, err)
+		}
+	}
+}
+
+func TestImagesPostRenderer(t *testing.T) {
+	manifests := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webapp
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: synopsys-init
+        image: docker.io/blackducksoftware/synopsys-init:1.0.0
+      containers:
+      - name: webapp
+        image: docker.io/blackducksoftware/blackduck-webapp:2020.6.0
+`
+	helmValues := map[string]interface{}{}
+	if err := SetImagePullPolicyInHelmValues(helmValues, "IfNotPresent"); err != nil {
+		t.Fatal(err)
+	}
+	SetPinImageDigestsInHelmValues(helmValues, true)
+	SetHelmValueInMap(helmValues, imageDigestsHelmPath, map[string]interface{}{
+		"docker.io/blackducksoftware/blackduck-webapp:2020.6.0": "docker.io/blackducksoftware/blackduck-webapp@sha256:1234",
+	})
+
+	output, err := getImagesPostRenderer(helmValues).Run(bytes.NewBufferString(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := output.String()
+	if !strings.Contains(rendered, "image: docker.io/blackducksoftware/blackduck-webapp@sha256:1234") ||
+		!strings.Contains(rendered, "image: docker.io/blackducksoftware/synopsys-init:1.0.0") ||
+		strings.Count(rendered, "imagePullPolicy: IfNotPresent") != 2 {
+		t.Errorf("unexpected rendered manifests:\n%s", rendered)
+	}
+
+	SetPinImageDigestsInHelmValues(helmValues, false)
+	if len(getImageDigestsFromHelmValues(helmValues)) != 0 {
+		t.Errorf("expected the digests to be dropped once pinning is disabled")
+	}
+}
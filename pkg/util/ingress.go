@@ -0,0 +1,214 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// ingressResource is the networking.k8s.io/v1 Ingress that synopsysctl creates, served from Kubernetes 1.19
+var ingressResource = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+
+// legacyIngressResource is the Ingress of the clusters before Kubernetes 1.19, which serve the ingresses of other
+// tools as extensions/v1beta1
+var legacyIngressResource = schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}
+
+// ingressHelmPath is the path of the ingress of the user interface in the Helm values. The values are not used by the
+// charts, synopsysctl applies the ingress itself
+var ingressHelmPath = []string{"synopsysctl", "ingress"}
+
+// backendProtocolAnnotation makes the NGINX ingress controller connect to the HTTPS port of the webserver with HTTPS
+const backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+// IngressConfig is the ingress of the user interface of an instance
+type IngressConfig struct {
+	ClassName     string `json:"className"`
+	Host          string `json:"host"`
+	TLSSecretName string `json:"tlsSecretName"`
+}
+
+// EnableIngressInHelmValues exposes the user interface with an ingress instead of the exposed service of the chart
+func EnableIngressInHelmValues(helmValues map[string]interface{}) {
+	SetHelmValueInMap(helmValues, []string{"exposeui"}, false)
+	SetHelmValueInMap(helmValues, append(ingressHelmPath, "enabled"), true)
+}
+
+// DisableIngressInHelmValues removes the ingress and its settings from the Helm values
+func DisableIngressInHelmValues(helmValues map[string]interface{}) {
+	deleteSynopsysctlHelmValue(helmValues, ingressHelmPath[1])
+}
+
+// SetIngressHelmValue sets a setting of the ingress, e.g. 'className', 'host' or 'tlsSecretName', in the Helm values.
+// An empty value removes the setting
+func SetIngressHelmValue(helmValues map[string]interface{}, key string, value string) {
+	if len(value) > 0 {
+		SetHelmValueInMap(helmValues, append(ingressHelmPath, key), value)
+		return
+	}
+	if values, ok := GetHelmValueFromMap(helmValues, ingressHelmPath).(map[string]interface{}); ok {
+		delete(values, key)
+	}
+}
+
+// GetIngressFromHelmValues returns the ingress of the user interface of the Helm values, or nil if it isn't exposed
+// with an ingress
+func GetIngressFromHelmValues(helmValues map[string]interface{}) *IngressConfig {
+	values, ok := GetHelmValueFromMap(helmValues, ingressHelmPath).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if enabled, _ := values["enabled"].(bool); !enabled {
+		return nil
+	}
+	config := &IngressConfig{}
+	config.ClassName, _ = values["className"].(string)
+	config.Host, _ = values["host"].(string)
+	config.TLSSecretName, _ = values["tlsSecretName"].(string)
+	return config
+}
+
+// NewIngress returns the networking.k8s.io/v1 ingress that routes the host of the config to the HTTPS port of the service
+func NewIngress(namespace string, name string, labels map[string]string, config IngressConfig, serviceName string, servicePort int64) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": config.Host,
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": serviceName,
+									"port": map[string]interface{}{"number": servicePort},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(config.ClassName) > 0 {
+		spec["ingressClassName"] = config.ClassName
+	}
+	if len(config.TLSSecretName) > 0 {
+		spec["tls"] = []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{config.Host},
+				"secretName": config.TLSSecretName,
+			},
+		}
+	}
+	ingress := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": ingressResource.GroupVersion().String(),
+		"kind":       "Ingress",
+		"spec":       spec,
+	}}
+	ingress.SetName(name)
+	ingress.SetNamespace(namespace)
+	ingress.SetLabels(labels)
+	ingress.SetAnnotations(map[string]string{backendProtocolAnnotation: "HTTPS"})
+	return ingress
+}
+
+// ApplyIngress creates the ingress or updates the spec of the existing one
+func ApplyIngress(restConfig *rest.Config, ingress *unstructured.Unstructured) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	client := dynamicClient.Resource(ingressResource).Namespace(ingress.GetNamespace())
+	current, err := client.Get(ingress.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		if _, err := client.Create(ingress, metav1.CreateOptions{}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to create ingress '%s' in namespace '%s', the cluster doesn't serve networking.k8s.io/v1 ingresses of Kubernetes 1.19 and later", ingress.GetName(), ingress.GetNamespace())
+			}
+			return fmt.Errorf("unable to create ingress '%s' in namespace '%s' due to %+v", ingress.GetName(), ingress.GetNamespace(), err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to get ingress '%s' in namespace '%s' due to %+v", ingress.GetName(), ingress.GetNamespace(), err)
+	}
+	current.Object["spec"] = ingress.Object["spec"]
+	current.SetLabels(ingress.GetLabels())
+	annotations := current.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range ingress.GetAnnotations() {
+		annotations[key] = value
+	}
+	current.SetAnnotations(annotations)
+	if _, err := client.Update(current, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update ingress '%s' in namespace '%s' due to %+v", ingress.GetName(), ingress.GetNamespace(), err)
+	}
+	return nil
+}
+
+// getIngressClient returns the client of the ingresses in the namespace, of networking.k8s.io/v1 if the cluster serves
+// it, and of extensions/v1beta1 otherwise
+func getIngressClient(restConfig *rest.Config, namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	client := dynamicClient.Resource(ingressResource).Namespace(namespace)
+	if _, err := client.List(metav1.ListOptions{Limit: 1}); k8serrors.IsNotFound(err) {
+		return dynamicClient.Resource(legacyIngressResource).Namespace(namespace), nil
+	}
+	return client, nil
+}
+
+// NewExposureIngress returns the ingress of the user interface of the instance to the service, or nil if the Helm values
+// don't expose it with an ingress
+func NewExposureIngress(e Exposure, helmValues map[string]interface{}, serviceName string, servicePort int64) (*unstructured.Unstructured, error) {
+	config := GetIngressFromHelmValues(helmValues)
+	if config == nil {
+		return nil, nil
+	}
+	if len(config.Host) == 0 {
+		return nil, ValidationError("--ingress-host must be set to expose the user interface with an ingress")
+	}
+	labels := map[string]string{"app": e.App, "name": e.Name}
+	return NewIngress(e.Namespace, e.IngressName, labels, *config, serviceName, servicePort), nil
+}
+
+// ReconcileExposureIngress applies the ingress of the user interface of the instance, or deletes it if the ingress is nil
+func ReconcileExposureIngress(restConfig *rest.Config, e Exposure, ingress *unstructured.Unstructured) error {
+	if ingress == nil {
+		if err := DeleteIngress(restConfig, e.Namespace, e.IngressName); err != nil {
+			return fmt.Errorf("unable to delete ingress '%s' in namespace '%s' due to %+v", e.IngressName, e.Namespace, err)
+		}
+		return nil
+	}
+	return ApplyIngress(restConfig, ingress)
+}
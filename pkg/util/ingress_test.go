@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIngressHelmValues(t *testing.T) {
+	assert := assert.New(t)
+	helmValues := map[string]interface{}{"exposeui": true, "exposedServiceType": "NodePort"}
+	assert.Nil(GetIngressFromHelmValues(helmValues))
+
+	EnableIngressInHelmValues(helmValues)
+	SetIngressHelmValue(helmValues, "className", "nginx")
+	SetIngressHelmValue(helmValues, "host", "bd.example.com")
+	SetIngressHelmValue(helmValues, "tlsSecretName", "bd-tls")
+	assert.Equal(false, helmValues["exposeui"])
+	assert.Equal(&IngressConfig{ClassName: "nginx", Host: "bd.example.com", TLSSecretName: "bd-tls"}, GetIngressFromHelmValues(helmValues))
+
+	SetIngressHelmValue(helmValues, "tlsSecretName", "")
+	assert.Equal(&IngressConfig{ClassName: "nginx", Host: "bd.example.com"}, GetIngressFromHelmValues(helmValues))
+
+	DisableIngressInHelmValues(helmValues)
+	assert.Nil(GetIngressFromHelmValues(helmValues))
+	assert.Nil(GetHelmValueFromMap(helmValues, ingressHelmPath))
+}
+
+func TestNewExposureIngress(t *testing.T) {
+	assert := assert.New(t)
+	e := Exposure{App: "blackduck", Namespace: "ns", Name: "bd", IngressName: "bd-blackduck"}
+	helmValues := map[string]interface{}{}
+
+	ingress, err := NewExposureIngress(e, helmValues, "bd-blackduck-webserver", 443)
+	assert.NoError(err)
+	assert.Nil(ingress)
+
+	EnableIngressInHelmValues(helmValues)
+	_, err = NewExposureIngress(e, helmValues, "bd-blackduck-webserver", 443)
+	assert.Error(err)
+
+	SetIngressHelmValue(helmValues, "host", "bd.example.com")
+	SetIngressHelmValue(helmValues, "className", "nginx")
+	SetIngressHelmValue(helmValues, "tlsSecretName", "bd-tls")
+	ingress, err = NewExposureIngress(e, helmValues, "bd-blackduck-webserver", 443)
+	assert.NoError(err)
+	assert.Equal("networking.k8s.io/v1", ingress.GetAPIVersion())
+	assert.Equal("Ingress", ingress.GetKind())
+	assert.Equal("bd-blackduck", ingress.GetName())
+	assert.Equal("ns", ingress.GetNamespace())
+	assert.Equal(map[string]string{"app": "blackduck", "name": "bd"}, ingress.GetLabels())
+	assert.Equal("HTTPS", ingress.GetAnnotations()[backendProtocolAnnotation])
+	assert.True(e.IsExposureObject("Ingress", ingress.GetName(), ingress.GetLabels()))
+
+	className, _, _ := unstructured.NestedString(ingress.Object, "spec", "ingressClassName")
+	assert.Equal("nginx", className)
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	assert.Len(rules, 1)
+	rule := rules[0].(map[string]interface{})
+	assert.Equal("bd.example.com", rule["host"])
+	paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+	serviceName, _, _ := unstructured.NestedString(paths[0].(map[string]interface{}), "backend", "service", "name")
+	assert.Equal("bd-blackduck-webserver", serviceName)
+	port, _, _ := unstructured.NestedInt64(paths[0].(map[string]interface{}), "backend", "service", "port", "number")
+	assert.Equal(int64(443), port)
+	tls, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "tls")
+	assert.Equal("bd-tls", tls[0].(map[string]interface{})["secretName"])
+}
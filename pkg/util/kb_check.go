@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlackDuckKBEndpoints are the Knowledge Base endpoints that Black Duck downloads the vulnerability data from
+var BlackDuckKBEndpoints = []string{
+	"https://kb.blackducksoftware.com",
+	"https://updates.suite.blackducksoftware.com",
+}
+
+// BlackDuckKBUpdateJobTypes are the types of the Black Duck jobs that update the Knowledge Base data
+var BlackDuckKBUpdateJobTypes = []string{"KbUpdateJob", "VulnerabilityUpdateJob"}
+
+// BlackDuckKBDefaultMaxAge is the age after which the Knowledge Base data is stale, the updates run at least daily
+var BlackDuckKBDefaultMaxAge = 48 * time.Hour
+
+// BlackDuckKBProxyPasswordFile is where the Black Duck containers mount the proxy password
+var BlackDuckKBProxyPasswordFile = "/tmp/secrets/HUB_PROXY_PASSWORD_FILE"
+
+// KBEndpointStatus is the connectivity of a Knowledge Base endpoint from inside an instance
+type KBEndpointStatus struct {
+	URL        string
+	StatusCode int
+	Reachable  bool
+}
+
+// KBStatus is the connectivity and the freshness of the Knowledge Base data of a Black Duck instance
+type KBStatus struct {
+	Endpoints []KBEndpointStatus
+	// LastUpdate is nil if the jobs of the instance weren't checked or no update job finished
+	LastUpdate *time.Time
+	Checked    bool
+}
+
+// GetKBConnectivityScript returns the shell script that requests the endpoints through the proxy configured in the
+// HUB_PROXY_* environment of a Black Duck container, and prints a line 'URL HTTP_CODE' per endpoint.
+// The HTTP code is 000 if the endpoint couldn't be reached
+func GetKBConnectivityScript(endpoints []string, timeout time.Duration) string {
+	quoted := []string{}
+	for _, endpoint := range endpoints {
+		quoted = append(quoted, fmt.Sprintf("'%s'", strings.Replace(endpoint, "'", "", -1)))
+	}
+	return fmt.Sprintf(`proxy=""
+if [ -n "$HUB_PROXY_HOST" ]; then proxy="${HUB_PROXY_SCHEME:-http}://$HUB_PROXY_HOST:${HUB_PROXY_PORT:-80}"; fi
+auth=""
+if [ -n "$HUB_PROXY_USER" ]; then auth="$HUB_PROXY_USER:$(cat %s 2>/dev/null || echo "$HUB_PROXY_PASSWORD")"; fi
+for url in %s; do
+  code=$(curl -s -o /dev/null -w '%%{http_code}' --max-time %d ${proxy:+--proxy "$proxy"} ${auth:+--proxy-user "$auth"} "$url")
+  echo "$url ${code:-000}"
+done
+`, BlackDuckKBProxyPasswordFile, strings.Join(quoted, " "), int(timeout.Seconds()))
+}
+
+// ParseKBConnectivity parses the output of the connectivity script, an endpoint that answers with any HTTP status is reachable
+func ParseKBConnectivity(output string) []KBEndpointStatus {
+	statuses := []KBEndpointStatus{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		code, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, KBEndpointStatus{URL: fields[0], StatusCode: code, Reachable: code > 0})
+	}
+	return statuses
+}
+
+// blackDuckJobs is a page of the jobs of the Black Duck API
+type blackDuckJobs struct {
+	Items []struct {
+		JobSpec struct {
+			JobType string `json:"jobType"`
+		} `json:"jobSpec"`
+		Status     string     `json:"status"`
+		FinishedAt *time.Time `json:"finishedAt"`
+	} `json:"items"`
+}
+
+// GetLastKBUpdate returns when a Knowledge Base update job of the Black Duck instance last completed, or nil if none did
+func GetLastKBUpdate(client *ProductAPIClient) (*time.Time, error) {
+	jobs := blackDuckJobs{}
+	if err := client.DoJSON(http.MethodGet, "/api/jobs?limit=1000&sort=finishedAt%20DESC", nil, &jobs); err != nil {
+		return nil, fmt.Errorf("unable to list the jobs of the Black Duck instance due to %+v", err)
+	}
+	var lastUpdate *time.Time
+	for _, job := range jobs.Items {
+		if job.FinishedAt == nil || !strings.EqualFold(job.Status, "COMPLETED") || !isKBUpdateJob(job.JobSpec.JobType) {
+			continue
+		}
+		if lastUpdate == nil || job.FinishedAt.After(*lastUpdate) {
+			finishedAt := *job.FinishedAt
+			lastUpdate = &finishedAt
+		}
+	}
+	return lastUpdate, nil
+}
+
+// isKBUpdateJob returns true if the job type updates the Knowledge Base data
+func isKBUpdateJob(jobType string) bool {
+	for _, kbJobType := range BlackDuckKBUpdateJobTypes {
+		if strings.EqualFold(jobType, kbJobType) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKBFindings returns the problems of the Knowledge Base connectivity and freshness, sorted
+func GetKBFindings(status KBStatus, maxAge time.Duration, now time.Time) []string {
+	findings := []string{}
+	for _, endpoint := range status.Endpoints {
+		if !endpoint.Reachable {
+			findings = append(findings, fmt.Sprintf("the Knowledge Base endpoint '%s' is not reachable, check the proxy settings and the network policies", endpoint.URL))
+		} else if endpoint.StatusCode == http.StatusProxyAuthRequired {
+			findings = append(findings, fmt.Sprintf("the proxy rejected the credentials for the Knowledge Base endpoint '%s'", endpoint.URL))
+		}
+	}
+	sort.Strings(findings)
+	if status.Checked {
+		if status.LastUpdate == nil {
+			findings = append(findings, "no Knowledge Base update job completed, the vulnerability data was never updated")
+		} else if age := now.Sub(*status.LastUpdate); age > maxAge {
+			findings = append(findings, fmt.Sprintf("the vulnerability data is stale, the Knowledge Base was last updated %s ago", age.Round(time.Minute)))
+		}
+	}
+	return findings
+}
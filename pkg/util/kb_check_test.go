@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKBConnectivityScript(t *testing.T) {
+	assert := assert.New(t)
+	script := GetKBConnectivityScript([]string{"https://kb.example.com", "https://updates.example.com"}, 10*time.Second)
+	assert.Contains(script, "for url in 'https://kb.example.com' 'https://updates.example.com'; do")
+	assert.Contains(script, "--max-time 10")
+	assert.Contains(script, "$HUB_PROXY_HOST")
+}
+
+func TestParseKBConnectivity(t *testing.T) {
+	assert := assert.New(t)
+	statuses := ParseKBConnectivity("https://kb.example.com 200\nhttps://updates.example.com 000\nunexpected output\n")
+	assert.Equal([]KBEndpointStatus{
+		{URL: "https://kb.example.com", StatusCode: 200, Reachable: true},
+		{URL: "https://updates.example.com", StatusCode: 0, Reachable: false},
+	}, statuses)
+}
+
+func TestGetLastKBUpdate(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tokens/authenticate":
+			fmt.Fprint(w, `{"bearerToken": "bearer", "expiresInMilliseconds": 7200000}`)
+		case "/api/jobs":
+			fmt.Fprint(w, `{"items": [
+				{"jobSpec": {"jobType": "ScanJob"}, "status": "COMPLETED", "finishedAt": "2020-06-03T10:00:00.000Z"},
+				{"jobSpec": {"jobType": "KbUpdateJob"}, "status": "FAILED", "finishedAt": "2020-06-02T10:00:00.000Z"},
+				{"jobSpec": {"jobType": "KbUpdateJob"}, "status": "COMPLETED", "finishedAt": "2020-06-01T10:00:00.000Z"},
+				{"jobSpec": {"jobType": "VulnerabilityUpdateJob"}, "status": "RUNNING"}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	lastUpdate, err := GetLastKBUpdate(NewProductAPIClient(server.URL, &BlackDuckAPITokenAuth{APIToken: "api-token"}, time.Minute))
+	assert.NoError(err)
+	if assert.NotNil(lastUpdate) {
+		assert.Equal(time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC), lastUpdate.UTC())
+	}
+}
+
+func TestGetKBFindings(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2020, 6, 4, 10, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+	old := now.Add(-72 * time.Hour)
+
+	assert.Empty(GetKBFindings(KBStatus{Endpoints: []KBEndpointStatus{{URL: "https://kb.example.com", StatusCode: 200, Reachable: true}}, LastUpdate: &recent, Checked: true}, 48*time.Hour, now))
+	// the freshness isn't reported if the jobs weren't checked
+	assert.Empty(GetKBFindings(KBStatus{}, 48*time.Hour, now))
+
+	findings := GetKBFindings(KBStatus{
+		Endpoints: []KBEndpointStatus{
+			{URL: "https://updates.example.com", StatusCode: 0},
+			{URL: "https://kb.example.com", StatusCode: 407, Reachable: true},
+		},
+		LastUpdate: &old,
+		Checked:    true,
+	}, 48*time.Hour, now)
+	assert.Len(findings, 3)
+	assert.True(strings.Contains(findings[0], "'https://updates.example.com' is not reachable"))
+	assert.True(strings.Contains(findings[1], "proxy rejected"))
+	assert.True(strings.Contains(findings[2], "stale, the Knowledge Base was last updated 72h0m0s ago"))
+
+	assert.Equal([]string{"no Knowledge Base update job completed, the vulnerability data was never updated"}, GetKBFindings(KBStatus{Checked: true}, 48*time.Hour, now))
+}
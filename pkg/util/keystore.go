@@ -0,0 +1,256 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	javaKeystoreMagic          uint32 = 0xFEEDFEED
+	javaKeystorePrivateKeyTag  uint32 = 1
+	javaKeystoreTrustedCertTag uint32 = 2
+	javaKeystoreWhitener              = "Mighty Aphrodite"
+)
+
+// DefaultJavaKeystorePassword is the password used by the JVM for its default truststore
+const DefaultJavaKeystorePassword = "changeit"
+
+// JavaKeystoreEntry is an entry of a Java Keystore
+type JavaKeystoreEntry struct {
+	Alias        string
+	CreationDate time.Time
+	PrivateKey   bool
+	Certificates []*x509.Certificate
+}
+
+// ParseJavaKeystore parses a JKS keystore and returns its entries. If a password is given,
+// the integrity of the keystore is verified against it
+func ParseJavaKeystore(data []byte, password string) ([]JavaKeystoreEntry, error) {
+	if len(data) < 12+sha1.Size {
+		return nil, fmt.Errorf("the Java Keystore is too short")
+	}
+	r := bytes.NewReader(data[:len(data)-sha1.Size])
+
+	var magic, version, count uint32
+	for _, v := range []*uint32{&magic, &version, &count} {
+		if err := binary.Read(r, binary.BigEndian, v); err != nil {
+			return nil, fmt.Errorf("unable to read the Java Keystore header due to %+v", err)
+		}
+	}
+	if magic != javaKeystoreMagic {
+		return nil, fmt.Errorf("the file is not a JKS Java Keystore (PKCS12 keystores can be converted from PEM certificates with --from-pem)")
+	}
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported Java Keystore version %d", version)
+	}
+
+	entries := []JavaKeystoreEntry{}
+	for i := uint32(0); i < count; i++ {
+		entry, err := readJavaKeystoreEntry(r, version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read entry %d of the Java Keystore due to %+v", i, err)
+		}
+		entries = append(entries, *entry)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("the Java Keystore contains %d unexpected trailing bytes", r.Len())
+	}
+
+	if len(password) > 0 {
+		digest := javaKeystoreDigest(data[:len(data)-sha1.Size], password)
+		if !bytes.Equal(digest, data[len(data)-sha1.Size:]) {
+			return nil, fmt.Errorf("the Java Keystore password is incorrect or the keystore has been tampered with")
+		}
+	}
+	return entries, nil
+}
+
+// NewJavaKeystoreFromPEM builds a JKS keystore that trusts every certificate found in the PEM data
+func NewJavaKeystoreFromPEM(pemData [][]byte, password string) ([]byte, error) {
+	certificates := []*x509.Certificate{}
+	for _, data := range pemData {
+		found := false
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse the certificate due to %+v", err)
+			}
+			certificates = append(certificates, certificate)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("the PEM data does not contain any CERTIFICATE block")
+		}
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("at least one PEM certificate is required to build a Java Keystore")
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, javaKeystoreMagic)
+	binary.Write(buf, binary.BigEndian, uint32(2))
+	binary.Write(buf, binary.BigEndian, uint32(len(certificates)))
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	aliases := map[string]bool{}
+	for i, certificate := range certificates {
+		alias := strings.ToLower(certificate.Subject.CommonName)
+		if len(alias) == 0 || aliases[alias] {
+			alias = fmt.Sprintf("%s-%d", alias, i)
+			alias = strings.TrimPrefix(alias, "-")
+		}
+		aliases[alias] = true
+
+		binary.Write(buf, binary.BigEndian, javaKeystoreTrustedCertTag)
+		writeJavaKeystoreUTF(buf, alias)
+		binary.Write(buf, binary.BigEndian, timestamp)
+		writeJavaKeystoreUTF(buf, "X.509")
+		binary.Write(buf, binary.BigEndian, uint32(len(certificate.Raw)))
+		buf.Write(certificate.Raw)
+	}
+	buf.Write(javaKeystoreDigest(buf.Bytes(), password))
+	return buf.Bytes(), nil
+}
+
+func readJavaKeystoreEntry(r *bytes.Reader, version uint32) (*JavaKeystoreEntry, error) {
+	var tag uint32
+	if err := binary.Read(r, binary.BigEndian, &tag); err != nil {
+		return nil, err
+	}
+	alias, err := readJavaKeystoreUTF(r)
+	if err != nil {
+		return nil, err
+	}
+	var timestamp int64
+	if err := binary.Read(r, binary.BigEndian, &timestamp); err != nil {
+		return nil, err
+	}
+	entry := &JavaKeystoreEntry{
+		Alias:        alias,
+		CreationDate: time.Unix(0, timestamp*int64(time.Millisecond)),
+	}
+
+	switch tag {
+	case javaKeystorePrivateKeyTag:
+		entry.PrivateKey = true
+		// the private key is encrypted with its own password, only its length is needed to skip it
+		if _, err := readJavaKeystoreBytes(r); err != nil {
+			return nil, err
+		}
+		var chainLength uint32
+		if err := binary.Read(r, binary.BigEndian, &chainLength); err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < chainLength; i++ {
+			certificate, err := readJavaKeystoreCertificate(r, version)
+			if err != nil {
+				return nil, err
+			}
+			entry.Certificates = append(entry.Certificates, certificate)
+		}
+	case javaKeystoreTrustedCertTag:
+		certificate, err := readJavaKeystoreCertificate(r, version)
+		if err != nil {
+			return nil, err
+		}
+		entry.Certificates = append(entry.Certificates, certificate)
+	default:
+		return nil, fmt.Errorf("unknown entry type %d", tag)
+	}
+	return entry, nil
+}
+
+func readJavaKeystoreCertificate(r *bytes.Reader, version uint32) (*x509.Certificate, error) {
+	if version == 2 {
+		certificateType, err := readJavaKeystoreUTF(r)
+		if err != nil {
+			return nil, err
+		}
+		if certificateType != "X.509" {
+			return nil, fmt.Errorf("unsupported certificate type '%s'", certificateType)
+		}
+	}
+	raw, err := readJavaKeystoreBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(raw)
+}
+
+func readJavaKeystoreBytes(r *bytes.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if int64(length) > int64(r.Len()) {
+		return nil, fmt.Errorf("field length %d exceeds the remaining %d bytes", length, r.Len())
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func readJavaKeystoreUTF(r *bytes.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func writeJavaKeystoreUTF(w io.Writer, s string) {
+	binary.Write(w, binary.BigEndian, uint16(len(s)))
+	w.Write([]byte(s))
+}
+
+// javaKeystoreDigest computes the keyed integrity check that the JDK appends to a JKS keystore
+func javaKeystoreDigest(data []byte, password string) []byte {
+	h := sha1.New()
+	for _, c := range password {
+		h.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	h.Write([]byte(javaKeystoreWhitener))
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func TestJavaKeystoreFromPEM(t *testing.T) {
+	rootCertificate, _, err := GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: "Root CA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediateCertificate, _, err := GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: "Intermediate CA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := []byte(rootCertificate + intermediateCertificate)
+
+	keystore, err := NewJavaKeystoreFromPEM([][]byte{bundle}, DefaultJavaKeystorePassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		description string
+		data        []byte
+		password    string
+		aliases     []string
+		shouldFail  bool
+	}{
+		{description: "correct password", data: keystore, password: DefaultJavaKeystorePassword, aliases: []string{"root ca", "intermediate ca"}},
+		{description: "no password", data: keystore, password: "", aliases: []string{"root ca", "intermediate ca"}},
+		{description: "wrong password", data: keystore, password: "secret", shouldFail: true},
+		{description: "tampered", data: bytes.Replace(keystore, []byte("root ca"), []byte("evil ca"), 1), password: DefaultJavaKeystorePassword, shouldFail: true},
+		{description: "truncated", data: keystore[:40], password: "", shouldFail: true},
+		{description: "not a keystore", data: bundle, password: "", shouldFail: true},
+	}
+
+	for _, test := range tests {
+		entries, err := ParseJavaKeystore(test.data, test.password)
+		if test.shouldFail {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %+v", test.description, err)
+			continue
+		}
+		if len(entries) != len(test.aliases) {
+			t.Errorf("%s: expected %d entries, got %d", test.description, len(test.aliases), len(entries))
+			continue
+		}
+		for i, entry := range entries {
+			if entry.Alias != test.aliases[i] || entry.PrivateKey || len(entry.Certificates) != 1 {
+				t.Errorf("%s: unexpected entry %+v", test.description, entry)
+			}
+		}
+	}
+
+	if _, err := NewJavaKeystoreFromPEM([][]byte{[]byte("not pem")}, DefaultJavaKeystorePassword); err == nil {
+		t.Errorf("expected an error for PEM data without certificates")
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeconfigContext is a context of a kubeconfig as printed by 'kubectl config get-contexts'
+type KubeconfigContext struct {
+	Current   bool   `json:"current"`
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	AuthInfo  string `json:"authInfo"`
+	Namespace string `json:"namespace"`
+}
+
+// SplitKubeconfigPaths splits the KUBECONFIG environ into its paths like kubectl, with the list separator of the OS and
+// without empty and duplicate paths
+func SplitKubeconfigPaths(value string) []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, path := range filepath.SplitList(value) {
+		if len(path) == 0 || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// ValidateKubeconfigPaths returns the paths of the KUBECONFIG environ that don't exist. Like kubectl, these are ignored
+// while loading, but it fails if none of the paths exist
+func ValidateKubeconfigPaths(value string) ([]string, error) {
+	paths := SplitKubeconfigPaths(value)
+	missing := []string{}
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing = append(missing, path)
+		}
+	}
+	if len(paths) > 0 && len(missing) == len(paths) {
+		return missing, fmt.Errorf("none of the kubeconfig paths '%s' of the KUBECONFIG environ point to a file", strings.Join(paths, "', '"))
+	}
+	return missing, nil
+}
+
+// NewKubeconfigLoadingRules returns the loading rules of kubectl: the kubeconfig of the explicit path if it's set, else
+// the merged kubeconfigs of the KUBECONFIG environ, else ~/.kube/config. The first kubeconfig that sets a value wins
+func NewKubeconfigLoadingRules(explicitPath string) *clientcmd.ClientConfigLoadingRules {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = explicitPath
+	return loadingRules
+}
+
+// GetKubeconfigContexts returns the contexts of the kubeconfig sorted by name, or only the contexts of the names
+func GetKubeconfigContexts(config *clientcmdapi.Config, names ...string) ([]KubeconfigContext, error) {
+	if len(names) == 0 {
+		for name := range config.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	contexts := []KubeconfigContext{}
+	for _, name := range names {
+		context, ok := config.Contexts[name]
+		if !ok {
+			return nil, fmt.Errorf("context '%s' not found in the kubeconfig", name)
+		}
+		contexts = append(contexts, KubeconfigContext{
+			Current:   name == config.CurrentContext,
+			Name:      name,
+			Cluster:   context.Cluster,
+			AuthInfo:  context.AuthInfo,
+			Namespace: context.Namespace,
+		})
+	}
+	return contexts, nil
+}
+
+// PrintKubeconfigContexts writes the contexts in the columns of 'kubectl config get-contexts', or only their names
+func PrintKubeconfigContexts(w io.Writer, contexts []KubeconfigContext, namesOnly bool, noHeaders bool) error {
+	if namesOnly {
+		for _, context := range contexts {
+			fmt.Fprintln(w, context.Name)
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, "CURRENT\tNAME\tCLUSTER\tAUTHINFO\tNAMESPACE")
+	}
+	for _, context := range contexts {
+		current := ""
+		if context.Current {
+			current = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", current, context.Name, context.Cluster, context.AuthInfo, context.Namespace)
+	}
+	return tw.Flush()
+}
+
+// WriteKubeconfigForContext writes the kubeconfig of the loading rules of the explicit path to a file of the directory
+// with the context as its current context, so that the Helm helpers that only take the path of a kubeconfig use it.
+// The file contains the credentials of the kubeconfig and must be removed by the caller
+func WriteKubeconfigForContext(explicitPath string, context string, dir string) (string, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(NewKubeconfigLoadingRules(explicitPath), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("unable to load the kubeconfig due to %+v", err)
+	}
+	if _, ok := config.Contexts[context]; !ok {
+		return "", ValidationError("context '%s' not found in the kubeconfig", context)
+	}
+	config.CurrentContext = context
+	file, err := ioutil.TempFile(dir, "kubeconfig-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create the kubeconfig of context '%s' due to %+v", context, err)
+	}
+	file.Close()
+	if err := clientcmd.WriteToFile(config, file.Name()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("unable to write the kubeconfig of context '%s' due to %+v", context, err)
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestSplitKubeconfigPaths(t *testing.T) {
+	assert := assert.New(t)
+	value := strings.Join([]string{"/a/config", "", "/b/config", "/a/config"}, string(os.PathListSeparator))
+	assert.Equal([]string{"/a/config", "/b/config"}, SplitKubeconfigPaths(value))
+	assert.Empty(SplitKubeconfigPaths(""))
+}
+
+func TestValidateKubeconfigPaths(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	existing := filepath.Join(dir, "config")
+	assert.NoError(ioutil.WriteFile(existing, []byte("apiVersion: v1\nkind: Config\n"), 0600))
+	missing := filepath.Join(dir, "missing")
+
+	result, err := ValidateKubeconfigPaths(strings.Join([]string{missing, existing}, string(os.PathListSeparator)))
+	assert.NoError(err)
+	assert.Equal([]string{missing}, result)
+
+	_, err = ValidateKubeconfigPaths(missing)
+	assert.Error(err)
+}
+
+func TestNewKubeconfigLoadingRulesMergesKubeconfigEnviron(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	first := clientcmdapi.NewConfig()
+	first.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev"}
+	first.AuthInfos["dev"] = &clientcmdapi.AuthInfo{Token: "dev"}
+	first.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "dev", Namespace: "first"}
+	first.CurrentContext = "dev"
+	second := clientcmdapi.NewConfig()
+	second.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod"}
+	second.AuthInfos["prod"] = &clientcmdapi.AuthInfo{Token: "prod"}
+	second.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod"}
+	second.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "dev", Namespace: "second"}
+	second.CurrentContext = "prod"
+	firstPath, secondPath := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	assert.NoError(clientcmd.WriteToFile(*first, firstPath))
+	assert.NoError(clientcmd.WriteToFile(*second, secondPath))
+
+	previous, set := os.LookupEnv(clientcmd.RecommendedConfigPathEnvVar)
+	defer func() {
+		if set {
+			os.Setenv(clientcmd.RecommendedConfigPathEnvVar, previous)
+		} else {
+			os.Unsetenv(clientcmd.RecommendedConfigPathEnvVar)
+		}
+	}()
+	os.Setenv(clientcmd.RecommendedConfigPathEnvVar, strings.Join([]string{firstPath, filepath.Join(dir, "missing"), secondPath}, string(os.PathListSeparator)))
+
+	config, err := NewKubeconfigLoadingRules("").Load()
+	assert.NoError(err)
+	// the first kubeconfig that sets a value wins
+	assert.Equal("dev", config.CurrentContext)
+	assert.Equal("first", config.Contexts["dev"].Namespace)
+	assert.Contains(config.Contexts, "prod")
+
+	// the explicit path replaces the environ
+	config, err = NewKubeconfigLoadingRules(secondPath).Load()
+	assert.NoError(err)
+	assert.Equal("prod", config.CurrentContext)
+	assert.Equal("second", config.Contexts["dev"].Namespace)
+}
+
+func TestGetKubeconfigContexts(t *testing.T) {
+	assert := assert.New(t)
+	config := clientcmdapi.NewConfig()
+	config.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod-cluster", AuthInfo: "admin"}
+	config.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev-cluster", AuthInfo: "me", Namespace: "synopsys"}
+	config.CurrentContext = "prod"
+
+	contexts, err := GetKubeconfigContexts(config)
+	assert.NoError(err)
+	assert.Equal([]KubeconfigContext{
+		{Name: "dev", Cluster: "dev-cluster", AuthInfo: "me", Namespace: "synopsys"},
+		{Current: true, Name: "prod", Cluster: "prod-cluster", AuthInfo: "admin"},
+	}, contexts)
+
+	contexts, err = GetKubeconfigContexts(config, "prod")
+	assert.NoError(err)
+	assert.Len(contexts, 1)
+
+	_, err = GetKubeconfigContexts(config, "staging")
+	assert.Error(err)
+
+	var out bytes.Buffer
+	assert.NoError(PrintKubeconfigContexts(&out, contexts, false, false))
+	assert.Equal("CURRENT   NAME   CLUSTER        AUTHINFO   NAMESPACE\n*         prod   prod-cluster   admin      \n", out.String())
+	out.Reset()
+	assert.NoError(PrintKubeconfigContexts(&out, contexts, true, false))
+	assert.Equal("prod\n", out.String())
+}
+
+func TestWriteKubeconfigForContext(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev"}
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "secret"}
+	config.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "admin"}
+	config.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "admin", Namespace: "synopsys"}
+	config.CurrentContext = "dev"
+	path := filepath.Join(dir, "config")
+	assert.NoError(clientcmd.WriteToFile(*config, path))
+
+	written, err := WriteKubeconfigForContext(path, "prod", dir)
+	assert.NoError(err)
+	info, err := os.Stat(written)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	loaded, err := clientcmd.LoadFromFile(written)
+	assert.NoError(err)
+	assert.Equal("prod", loaded.CurrentContext)
+	assert.Equal("https://prod", loaded.Clusters["prod"].Server)
+	assert.Equal("secret", loaded.AuthInfos["admin"].Token)
+
+	_, err = WriteKubeconfigForContext(path, "staging", dir)
+	assert.Error(err)
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+}
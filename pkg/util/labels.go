@@ -0,0 +1,128 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// POCLabel marks every resource of an instance that was created with --poc
+	POCLabel = "synopsys.com/poc"
+	// POCExpiryLabel holds the unix time after which a POC instance is considered expired
+	POCExpiryLabel = "synopsys.com/poc-expiry"
+)
+
+// GetPOCLabels returns the labels that mark an instance as a POC that expires at the given time
+func GetPOCLabels(expiry time.Time) map[string]string {
+	return map[string]string{
+		POCLabel:       "true",
+		POCExpiryLabel: strconv.FormatInt(expiry.Unix(), 10),
+	}
+}
+
+// GetPOCExpiryFromLabels returns the expiry time stored in the labels. The boolean is false if the labels
+// don't belong to a POC instance
+func GetPOCExpiryFromLabels(labels map[string]string) (time.Time, bool, error) {
+	if labels[POCLabel] != "true" {
+		return time.Time{}, false, nil
+	}
+	expiryValue, ok := labels[POCExpiryLabel]
+	if !ok {
+		return time.Time{}, true, fmt.Errorf("label '%s' is missing", POCExpiryLabel)
+	}
+	expiry, err := strconv.ParseInt(expiryValue, 10, 64)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("label '%s' has an invalid value '%s': %+v", POCExpiryLabel, expiryValue, err)
+	}
+	return time.Unix(expiry, 0), true, nil
+}
+
+// AddLabelsToInstanceResources adds the labels to the deployments, services, secrets, config maps and
+// persistent volume claims that match the labelSelector
+func AddLabelsToInstanceResources(clientset *kubernetes.Clientset, namespace string, labelSelector string, labels map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	deployments, err := ListDeployments(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list deployments in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, deployment := range deployments.Items {
+		if _, err := clientset.AppsV1().Deployments(namespace).Patch(deployment.Name, types.MergePatchType, patch); err != nil {
+			return fmt.Errorf("unable to label deployment '%s' in namespace '%s' due to %+v", deployment.Name, namespace, err)
+		}
+	}
+
+	services, err := ListServices(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list services in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, service := range services.Items {
+		if _, err := clientset.CoreV1().Services(namespace).Patch(service.Name, types.MergePatchType, patch); err != nil {
+			return fmt.Errorf("unable to label service '%s' in namespace '%s' due to %+v", service.Name, namespace, err)
+		}
+	}
+
+	secrets, err := ListSecrets(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list secrets in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, secret := range secrets.Items {
+		if _, err := clientset.CoreV1().Secrets(namespace).Patch(secret.Name, types.MergePatchType, patch); err != nil {
+			return fmt.Errorf("unable to label secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+		}
+	}
+
+	configMaps, err := ListConfigMaps(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list config maps in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, configMap := range configMaps.Items {
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Patch(configMap.Name, types.MergePatchType, patch); err != nil {
+			return fmt.Errorf("unable to label config map '%s' in namespace '%s' due to %+v", configMap.Name, namespace, err)
+		}
+	}
+
+	pvcs, err := ListPVCs(clientset, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("unable to list pvcs in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, pvc := range pvcs.Items {
+		if _, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Patch(pvc.Name, types.MergePatchType, patch); err != nil {
+			return fmt.Errorf("unable to label pvc '%s' in namespace '%s' due to %+v", pvc.Name, namespace, err)
+		}
+	}
+	return nil
+}
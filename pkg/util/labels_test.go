@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPOCExpiryFromLabels(t *testing.T) {
+	expiry := time.Unix(1602720000, 0)
+	var tests = []struct {
+		description    string
+		labels         map[string]string
+		expectedExpiry time.Time
+		expectedIsPOC  bool
+		expectedErr    bool
+	}{
+		{
+			description:    "labels of a POC instance",
+			labels:         GetPOCLabels(expiry),
+			expectedExpiry: expiry,
+			expectedIsPOC:  true,
+		},
+		{
+			description:   "labels of a regular instance",
+			labels:        map[string]string{"app": "blackduck", "name": "bd"},
+			expectedIsPOC: false,
+		},
+		{
+			description:   "POC label without an expiry",
+			labels:        map[string]string{POCLabel: "true"},
+			expectedIsPOC: true,
+			expectedErr:   true,
+		},
+		{
+			description:   "POC label with an invalid expiry",
+			labels:        map[string]string{POCLabel: "true", POCExpiryLabel: "tomorrow"},
+			expectedIsPOC: true,
+			expectedErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		actualExpiry, actualIsPOC, err := GetPOCExpiryFromLabels(test.labels)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("failed test case '%s': expected error %t, got %+v", test.description, test.expectedErr, err)
+		}
+		if actualIsPOC != test.expectedIsPOC {
+			t.Errorf("failed test case '%s': expected isPOC %t, got %t", test.description, test.expectedIsPOC, actualIsPOC)
+		}
+		if !actualExpiry.Equal(test.expectedExpiry) {
+			t.Errorf("failed test case '%s': expected expiry %s, got %s", test.description, test.expectedExpiry, actualExpiry)
+		}
+	}
+}
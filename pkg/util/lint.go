@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Rules of the manifest linter
+const (
+	LintRuleMissingProbes  = "missing-probes"
+	LintRuleMissingLimits  = "missing-resource-limits"
+	LintRuleDeprecatedAPI  = "deprecated-api-version"
+	LintRuleLatestImageTag = "latest-image-tag"
+)
+
+const (
+	lintLatestTag = "latest"
+	// lintUnknownKubeMinorVersion reports all deprecated API versions
+	lintUnknownKubeMinorVersion = -1
+)
+
+// LintFinding is a problem found in a rendered object
+type LintFinding struct {
+	Rule      string `json:"rule"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+// String returns the finding as a warning
+func (f LintFinding) String() string {
+	if len(f.Container) > 0 {
+		return fmt.Sprintf("%s '%s' container '%s': %s [%s]", f.Kind, f.Name, f.Container, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%s '%s': %s [%s]", f.Kind, f.Name, f.Message, f.Rule)
+}
+
+// deprecatedAPIVersion is an API version of a kind that is deprecated and removed in a Kubernetes minor version
+type deprecatedAPIVersion struct {
+	deprecatedIn int
+	removedIn    int
+	replacement  string
+}
+
+// deprecatedAPIVersions are the deprecated API versions by API version and kind
+var deprecatedAPIVersions = map[string]deprecatedAPIVersion{
+	"extensions/v1beta1/Deployment":                         {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"extensions/v1beta1/DaemonSet":                          {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"extensions/v1beta1/ReplicaSet":                         {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"extensions/v1beta1/NetworkPolicy":                      {deprecatedIn: 9, removedIn: 16, replacement: "networking.k8s.io/v1"},
+	"extensions/v1beta1/PodSecurityPolicy":                  {deprecatedIn: 10, removedIn: 16, replacement: "policy/v1beta1"},
+	"extensions/v1beta1/Ingress":                            {deprecatedIn: 14, removedIn: 22, replacement: "networking.k8s.io/v1"},
+	"apps/v1beta1/Deployment":                               {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta1/StatefulSet":                              {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta2/Deployment":                               {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta2/StatefulSet":                              {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta2/DaemonSet":                                {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta2/ReplicaSet":                               {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"networking.k8s.io/v1beta1/Ingress":                     {deprecatedIn: 19, removedIn: 22, replacement: "networking.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/Role":                {deprecatedIn: 17, removedIn: 22, replacement: "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/RoleBinding":         {deprecatedIn: 17, removedIn: 22, replacement: "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRole":         {deprecatedIn: 17, removedIn: 22, replacement: "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRoleBinding":  {deprecatedIn: 17, removedIn: 22, replacement: "rbac.authorization.k8s.io/v1"},
+	"apiextensions.k8s.io/v1beta1/CustomResourceDefinition": {deprecatedIn: 16, removedIn: 22, replacement: "apiextensions.k8s.io/v1"},
+	"batch/v1beta1/CronJob":                                 {deprecatedIn: 21, removedIn: 25, replacement: "batch/v1"},
+	"policy/v1beta1/PodDisruptionBudget":                    {deprecatedIn: 21, removedIn: 25, replacement: "policy/v1"},
+	"policy/v1beta1/PodSecurityPolicy":                      {deprecatedIn: 21, removedIn: 25, replacement: "Pod Security Admission"},
+	"networking.k8s.io/v1beta1/IngressClass":                {deprecatedIn: 19, removedIn: 22, replacement: "networking.k8s.io/v1"},
+	"scheduling.k8s.io/v1beta1/PriorityClass":               {deprecatedIn: 14, removedIn: 22, replacement: "scheduling.k8s.io/v1"},
+	"storage.k8s.io/v1beta1/StorageClass":                   {deprecatedIn: 19, removedIn: 22, replacement: "storage.k8s.io/v1"},
+	"discovery.k8s.io/v1beta1/EndpointSlice":                {deprecatedIn: 21, removedIn: 25, replacement: "discovery.k8s.io/v1"},
+	"autoscaling/v2beta1/HorizontalPodAutoscaler":           {deprecatedIn: 22, removedIn: 25, replacement: "autoscaling/v2"},
+	"autoscaling/v2beta2/HorizontalPodAutoscaler":           {deprecatedIn: 23, removedIn: 26, replacement: "autoscaling/v2"},
+	"storage.k8s.io/v1beta1/CSIStorageCapacity":             {deprecatedIn: 24, removedIn: 27, replacement: "storage.k8s.io/v1"},
+}
+
+// LintManifests checks the rendered objects for missing probes and resource limits, images with the latest tag and API
+// versions that are deprecated in the Kubernetes version, e.g. v1.17.3. All deprecated API versions are reported if the
+// Kubernetes version is empty
+func LintManifests(manifest string, kubeVersion string) ([]LintFinding, error) {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	minorVersion, err := parseKubeMinorVersion(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	findings := []LintFinding{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		findings = append(findings, lintAPIVersion(u, minorVersion)...)
+		podSpec := getWorkloadPodSpec(object)
+		if podSpec == nil {
+			continue
+		}
+		// jobs run to completion, probes don't apply to them
+		checkProbes := u.GetKind() != "Job"
+		for _, key := range []string{"initContainers", "containers"} {
+			list, _ := podSpec[key].([]interface{})
+			for _, item := range list {
+				container, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				findings = append(findings, lintContainer(u, container, checkProbes && key == "containers")...)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return findings, nil
+}
+
+// WarnLintFindings lints the rendered objects and logs a warning for each finding. The API versions are skipped as
+// they are checked against the cluster before an install or upgrade
+func WarnLintFindings(manifest string) {
+	findings, err := LintManifests(manifest, "")
+	if err != nil {
+		log.Debugf("unable to lint the manifests due to %+v", err)
+		return
+	}
+	for _, finding := range findings {
+		if finding.Rule != LintRuleDeprecatedAPI {
+			log.Warnf("%s", finding)
+		}
+	}
+}
+
+// lintAPIVersion returns a finding if the API version of the object is deprecated in the Kubernetes minor version
+func lintAPIVersion(u *unstructured.Unstructured, minorVersion int) []LintFinding {
+	deprecated, ok := deprecatedAPIVersions[fmt.Sprintf("%s/%s", u.GetAPIVersion(), u.GetKind())]
+	if !ok || (minorVersion != lintUnknownKubeMinorVersion && minorVersion < deprecated.deprecatedIn) {
+		return nil
+	}
+	message := fmt.Sprintf("%s is deprecated since Kubernetes 1.%d and removed in 1.%d, use %s", u.GetAPIVersion(), deprecated.deprecatedIn, deprecated.removedIn, deprecated.replacement)
+	if minorVersion >= deprecated.removedIn {
+		message = fmt.Sprintf("%s is not served by Kubernetes 1.%d, use %s", u.GetAPIVersion(), minorVersion, deprecated.replacement)
+	}
+	return []LintFinding{{Rule: LintRuleDeprecatedAPI, Kind: u.GetKind(), Name: u.GetName(), Message: message}}
+}
+
+// lintContainer returns the findings of a container of a workload
+func lintContainer(u *unstructured.Unstructured, container map[string]interface{}, checkProbes bool) []LintFinding {
+	findings := []LintFinding{}
+	name, _ := container["name"].(string)
+	newFinding := func(rule string, message string) LintFinding {
+		return LintFinding{Rule: rule, Kind: u.GetKind(), Name: u.GetName(), Container: name, Message: message}
+	}
+
+	if checkProbes {
+		missing := []string{}
+		for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+			if _, ok := container[probe]; !ok {
+				missing = append(missing, probe)
+			}
+		}
+		if len(missing) > 0 {
+			findings = append(findings, newFinding(LintRuleMissingProbes, fmt.Sprintf("no %s", strings.Join(missing, " and "))))
+		}
+	}
+
+	limits, _ := GetHelmValueFromMap(container, []string{"resources", "limits"}).(map[string]interface{})
+	missing := []string{}
+	for _, resource := range []string{"cpu", "memory"} {
+		if _, ok := limits[resource]; !ok {
+			missing = append(missing, resource)
+		}
+	}
+	if len(missing) > 0 {
+		findings = append(findings, newFinding(LintRuleMissingLimits, fmt.Sprintf("no %s limit", strings.Join(missing, " and "))))
+	}
+
+	if image, _ := container["image"].(string); isLatestImageTag(image) {
+		findings = append(findings, newFinding(LintRuleLatestImageTag, fmt.Sprintf("image '%s' uses the latest tag, pin a version or a digest", image)))
+	}
+	return findings
+}
+
+// isLatestImageTag returns true if the image has no tag or the latest tag and isn't pinned to a digest
+func isLatestImageTag(image string) bool {
+	if len(image) == 0 || strings.Contains(image, "@") {
+		return false
+	}
+	lastSegment := image[strings.LastIndex(image, "/")+1:]
+	separator := strings.LastIndex(lastSegment, ":")
+	return separator < 0 || lastSegment[separator+1:] == lintLatestTag
+}
+
+// parseKubeMinorVersion returns the minor version of a Kubernetes version such as v1.17.3 or 1.17
+func parseKubeMinorVersion(kubeVersion string) (int, error) {
+	if len(kubeVersion) == 0 {
+		return lintUnknownKubeMinorVersion, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(kubeVersion, "v"), ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, fmt.Errorf("invalid Kubernetes version '%s', must be like 1.17", kubeVersion)
+	}
+	// managed clusters report versions such as v1.17+ or v1.17.9-eks-4c6976
+	minor, err := strconv.Atoi(strings.TrimRight(parts[1], "+"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Kubernetes version '%s', must be like 1.17", kubeVersion)
+	}
+	return minor, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintManifests(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: webapp
+        image: docker.io/blackducksoftware/blackduck-webapp:2020.6.0
+        livenessProbe: {}
+        readinessProbe: {}
+        resources:
+          limits:
+            cpu: "1"
+            memory: 2Gi
+      - name: logstash
+        image: docker.io/blackducksoftware/blackduck-logstash
+        readinessProbe: {}
+        resources:
+          limits:
+            memory: 1Gi
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: registry:5000/migrate:latest
+        resources:
+          limits:
+            cpu: "1"
+            memory: 1Gi
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: webserver
+`
+	findings, err := LintManifests(manifest, "v1.17.3")
+	assert.NoError(err)
+	assert.Equal([]LintFinding{
+		{Rule: LintRuleMissingProbes, Kind: "Deployment", Name: "webapp", Container: "logstash", Message: "no livenessProbe"},
+		{Rule: LintRuleMissingLimits, Kind: "Deployment", Name: "webapp", Container: "logstash", Message: "no cpu limit"},
+		{Rule: LintRuleLatestImageTag, Kind: "Deployment", Name: "webapp", Container: "logstash", Message: "image 'docker.io/blackducksoftware/blackduck-logstash' uses the latest tag, pin a version or a digest"},
+		{Rule: LintRuleLatestImageTag, Kind: "Job", Name: "migrate", Container: "migrate", Message: "image 'registry:5000/migrate:latest' uses the latest tag, pin a version or a digest"},
+	}, findings)
+
+	findings, err = LintManifests(manifest, "1.22")
+	assert.NoError(err)
+	assert.Contains(findings, LintFinding{Rule: LintRuleDeprecatedAPI, Kind: "Ingress", Name: "webserver", Message: "networking.k8s.io/v1beta1 is not served by Kubernetes 1.22, use networking.k8s.io/v1"})
+
+	findings, err = LintManifests(manifest, "")
+	assert.NoError(err)
+	assert.Contains(findings, LintFinding{Rule: LintRuleDeprecatedAPI, Kind: "Ingress", Name: "webserver", Message: "networking.k8s.io/v1beta1 is deprecated since Kubernetes 1.19 and removed in 1.22, use networking.k8s.io/v1"})
+
+	_, err = LintManifests(manifest, "latest")
+	assert.Error(err)
+}
+
+func TestIsLatestImageTag(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isLatestImageTag("alpine"))
+	assert.True(isLatestImageTag("registry:5000/alpine"))
+	assert.True(isLatestImageTag("alpine:latest"))
+	assert.False(isLatestImageTag("registry:5000/alpine:3.12"))
+	assert.False(isLatestImageTag("alpine@sha256:abcdef"))
+}
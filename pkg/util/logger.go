@@ -0,0 +1,73 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Structured fields of the log messages, so that the messages of operations on several instances can be filtered
+const (
+	LogFieldProduct   = "product"
+	LogFieldInstance  = "instance"
+	LogFieldNamespace = "namespace"
+	LogFieldStep      = "step"
+)
+
+// Log formats of --log-format
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogger returns the shared logger with the fields of an instance, empty fields are omitted. A logrus entry
+// writes each message with a single locked write, so the messages of goroutines don't interleave
+func NewLogger(product string, instance string, namespace string) *log.Entry {
+	fields := log.Fields{}
+	for key, value := range map[string]string{LogFieldProduct: product, LogFieldInstance: instance, LogFieldNamespace: namespace} {
+		if len(value) > 0 {
+			fields[key] = value
+		}
+	}
+	return log.WithFields(fields)
+}
+
+// WithStep returns the logger with the step of the operation that is logging
+func WithStep(logger *log.Entry, step string) *log.Entry {
+	return logger.WithField(LogFieldStep, step)
+}
+
+// ConfigureLogFormat sets the formatter of the shared logger, JSON messages keep the structured fields filterable. JSON
+// messages have the correlation ID of the command, text messages only on warnings and errors
+func ConfigureLogFormat(format string) error {
+	switch format {
+	case "", LogFormatText:
+		log.AddHook(&correlationIDHook{})
+		return nil
+	case LogFormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+		log.AddHook(&correlationIDHook{allLevels: true})
+		return nil
+	default:
+		return ValidationError("--log-format must be '%s' or '%s', got '%s'", LogFormatText, LogFormatJSON, format)
+	}
+}
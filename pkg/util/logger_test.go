@@ -0,0 +1,49 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := WithStep(NewLogger(BlackDuckName, "bd", "ns"), "upgrade")
+	assert.Equal(log.Fields{LogFieldProduct: BlackDuckName, LogFieldInstance: "bd", LogFieldNamespace: "ns", LogFieldStep: "upgrade"}, logger.Data)
+
+	assert.Equal(log.Fields{LogFieldNamespace: "ns"}, NewLogger("", "", "ns").Data)
+}
+
+func TestConfigureLogFormat(t *testing.T) {
+	assert := assert.New(t)
+	formatter := log.StandardLogger().Formatter
+	defer log.SetFormatter(formatter)
+
+	assert.NoError(ConfigureLogFormat(LogFormatText))
+	assert.NoError(ConfigureLogFormat(LogFormatJSON))
+	assert.IsType(&log.JSONFormatter{}, log.StandardLogger().Formatter)
+	assert.Error(ConfigureLogFormat("xml"))
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// componentLabels are the labels of the pods that name the component of a product, the charts of Alert, Black Duck
+// and OpsSight set 'component' and the chart of BDBA sets 'app.kubernetes.io/component'
+var componentLabels = []string{"component", "app.kubernetes.io/component"}
+
+// LogSource is a container of a pod whose logs are read
+type LogSource struct {
+	Pod       string
+	Container string
+}
+
+// Prefix returns the prefix of the log lines of the container
+func (s LogSource) Prefix() string {
+	return fmt.Sprintf("[%s/%s] ", s.Pod, s.Container)
+}
+
+// PodComponent returns the component of a pod from its labels, empty if the pod has no component label
+func PodComponent(pod corev1.Pod) string {
+	return LabelsComponent(pod.Labels)
+}
+
+// LabelsComponent returns the component of an object of an instance from its labels, empty if there is no component label
+func LabelsComponent(labels map[string]string) string {
+	for _, label := range componentLabels {
+		if component, ok := labels[label]; ok {
+			return component
+		}
+	}
+	return ""
+}
+
+// GetLogSources returns the containers of the pods of the component, or of all pods if the component is empty. A pod
+// without a component label is matched by its name. The pods that didn't start yet have no logs and are skipped
+func GetLogSources(pods []corev1.Pod, component string) []LogSource {
+	sources := []LogSource{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		if len(component) > 0 {
+			podComponent := PodComponent(pod)
+			if podComponent != component && (len(podComponent) > 0 || !strings.Contains(pod.Name, component)) {
+				continue
+			}
+		}
+		for _, container := range pod.Spec.Containers {
+			sources = append(sources, LogSource{Pod: pod.Name, Container: container.Name})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Pod != sources[j].Pod {
+			return sources[i].Pod < sources[j].Pod
+		}
+		return sources[i].Container < sources[j].Container
+	})
+	return sources
+}
+
+// GetPodComponents returns the sorted components of the pods
+func GetPodComponents(pods []corev1.Pod) []string {
+	found := map[string]bool{}
+	for _, pod := range pods {
+		if component := PodComponent(pod); len(component) > 0 {
+			found[component] = true
+		}
+	}
+	components := []string{}
+	for component := range found {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// lockedWriter serializes the writes of concurrent log streams
+type lockedWriter struct {
+	sync.Mutex
+	w io.Writer
+}
+
+// NewLockedWriter returns a writer that is safe for concurrent writes, a write is never interleaved with another
+func NewLockedWriter(w io.Writer) io.Writer {
+	return &lockedWriter{w: w}
+}
+
+// Write writes the data with the lock held
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.w.Write(p)
+}
+
+// CopyPrefixedLines copies the lines of the reader to the writer with the prefix. Each line is written with a single
+// write, so the lines of concurrent copies to a locked writer don't interleave
+func CopyPrefixedLines(w io.Writer, r io.Reader, prefix string) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if _, err := io.WriteString(w, prefix+scanner.Text()+"\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetLogSources(t *testing.T) {
+	assert := assert.New(t)
+
+	newPod := func(name string, labels map[string]string, phase corev1.PodPhase, containers ...string) corev1.Pod {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}, Status: corev1.PodStatus{Phase: phase}}
+		for _, container := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		}
+		return pod
+	}
+	pods := []corev1.Pod{
+		newPod("bd-blackduck-webserver-1", map[string]string{"component": "webserver"}, corev1.PodRunning, "webserver"),
+		newPod("bd-blackduck-scan-1", map[string]string{"component": "scan"}, corev1.PodRunning, "scan", "sidecar"),
+		newPod("bd-blackduck-scan-2", map[string]string{"component": "scan"}, corev1.PodPending, "scan"),
+		newPod("bdba-worker-1", map[string]string{"app.kubernetes.io/component": "worker"}, corev1.PodRunning, "worker"),
+		newPod("bd-blackduck-jobrunner-1", nil, corev1.PodSucceeded, "jobrunner"),
+	}
+
+	assert.Equal([]LogSource{
+		{Pod: "bd-blackduck-jobrunner-1", Container: "jobrunner"},
+		{Pod: "bd-blackduck-scan-1", Container: "scan"},
+		{Pod: "bd-blackduck-scan-1", Container: "sidecar"},
+		{Pod: "bd-blackduck-webserver-1", Container: "webserver"},
+		{Pod: "bdba-worker-1", Container: "worker"},
+	}, GetLogSources(pods, ""))
+	assert.Equal([]LogSource{{Pod: "bd-blackduck-scan-1", Container: "scan"}, {Pod: "bd-blackduck-scan-1", Container: "sidecar"}}, GetLogSources(pods, "scan"))
+	assert.Equal([]LogSource{{Pod: "bdba-worker-1", Container: "worker"}}, GetLogSources(pods, "worker"))
+	// a pod without a component label is matched by its name
+	assert.Equal([]LogSource{{Pod: "bd-blackduck-jobrunner-1", Container: "jobrunner"}}, GetLogSources(pods, "jobrunner"))
+	assert.Empty(GetLogSources(pods, "registration"))
+
+	assert.Equal([]string{"scan", "webserver", "worker"}, GetPodComponents(pods))
+}
+
+func TestCopyPrefixedLines(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	source := LogSource{Pod: "bd-blackduck-webserver-1", Container: "webserver"}
+	assert.NoError(CopyPrefixedLines(NewLockedWriter(&out), strings.NewReader("first\nsecond"), source.Prefix()))
+	assert.Equal("[bd-blackduck-webserver-1/webserver] first\n[bd-blackduck-webserver-1/webserver] second\n", out.String())
+}
@@ -0,0 +1,304 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Endpoints of the APIs of the managed Kubernetes services, variables so that they can be replaced, e.g. by a proxy
+var (
+	// EKSEndpointFormat is the URL of the Amazon EKS API of a region
+	EKSEndpointFormat = "https://eks.%s.amazonaws.com"
+	// STSEndpointFormat is the URL of the AWS Security Token Service of a region
+	STSEndpointFormat = "https://sts.%s.amazonaws.com"
+	// GKEEndpoint is the URL of the Google Kubernetes Engine API
+	GKEEndpoint = "https://container.googleapis.com"
+	// AzureManagementEndpoint is the URL of the Azure Resource Manager API
+	AzureManagementEndpoint = "https://management.azure.com"
+	// AzureLoginEndpoint is the URL of the Azure Active Directory token endpoints
+	AzureLoginEndpoint = "https://login.microsoftonline.com"
+)
+
+// ExecCredentialAPIVersion is the API version of the credentials printed for the exec plugin of a kubeconfig user
+const ExecCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// gkeScope is the OAuth scope of the access tokens of the Google Kubernetes Engine API and clusters
+const gkeScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// eksTokenPrefix is the prefix of the bearer tokens of an EKS cluster
+const eksTokenPrefix = "k8s-aws-v1."
+
+// EKSTokenLifetime is the time an EKS token is valid, the presigned request expires after 15 minutes
+const EKSTokenLifetime = 14 * time.Minute
+
+// aksAPIVersion is the API version of the Azure Kubernetes Service API
+const aksAPIVersion = "2020-09-01"
+
+// cloudAPITimeout is the timeout of the requests to the APIs of the managed Kubernetes services
+var cloudAPITimeout = 30 * time.Second
+
+// ManagedCluster is the API server of a cluster of a managed Kubernetes service
+type ManagedCluster struct {
+	Server                   string
+	CertificateAuthorityData []byte
+}
+
+// GetEKSCluster returns the API server of an Amazon EKS cluster
+func GetEKSCluster(name string, region string, credentials AWSCredentials) (*ManagedCluster, error) {
+	if len(credentials.AccessKeyID) == 0 || len(credentials.SecretAccessKey) == 0 {
+		return nil, ValidationError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to get EKS cluster '%s'", name)
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(EKSEndpointFormat+"/clusters/%s", region, url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+	SignAWSRequest(req, credentials, region, "eks", time.Now().UTC())
+	response := struct {
+		Cluster struct {
+			Endpoint             string `json:"endpoint"`
+			CertificateAuthority struct {
+				Data string `json:"data"`
+			} `json:"certificateAuthority"`
+		} `json:"cluster"`
+	}{}
+	if err := doCloudRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("unable to get EKS cluster '%s' in region '%s' due to %+v", name, region, err)
+	}
+	ca, err := base64.StdEncoding.DecodeString(response.Cluster.CertificateAuthority.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority of EKS cluster '%s' due to %+v", name, err)
+	}
+	return &ManagedCluster{Server: response.Cluster.Endpoint, CertificateAuthorityData: ca}, nil
+}
+
+// NewEKSToken returns the bearer token of an EKS cluster, a presigned request of the AWS STS GetCallerIdentity action
+// that the cluster sends to identify the user
+func NewEKSToken(clusterName string, region string, credentials AWSCredentials, now time.Time) (string, error) {
+	if len(credentials.AccessKeyID) == 0 || len(credentials.SecretAccessKey) == 0 {
+		return "", ValidationError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to get a token of EKS cluster '%s'", clusterName)
+	}
+	endpoint, err := url.Parse(fmt.Sprintf(STSEndpointFormat, region))
+	if err != nil {
+		return "", err
+	}
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := fmt.Sprintf("%s/%s/sts/aws4_request", date, region)
+	signedHeaders := "host;x-k8s-aws-id"
+
+	query := url.Values{}
+	query.Set("Action", "GetCallerIdentity")
+	query.Set("Version", "2011-06-15")
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", fmt.Sprintf("%s/%s", credentials.AccessKeyID, scope))
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", "60")
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if len(credentials.SessionToken) > 0 {
+		query.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-k8s-aws-id:%s\n", endpoint.Host, clusterName)
+	canonicalRequest := strings.Join([]string{http.MethodGet, "/", query.Encode(), canonicalHeaders, signedHeaders, emptyPayloadHash}, "\n")
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(hashedRequest[:])}, "\n")
+	query.Set("X-Amz-Signature", hex.EncodeToString(hmacSHA256(S3SigningKey(credentials.SecretAccessKey, date, region, "sts"), stringToSign)))
+
+	endpoint.Path = "/"
+	endpoint.RawQuery = query.Encode()
+	return eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(endpoint.String())), nil
+}
+
+// GetGKECluster returns the API server of a Google Kubernetes Engine cluster
+func GetGKECluster(project string, location string, name string, token string) (*ManagedCluster, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/projects/%s/locations/%s/clusters/%s", GKEEndpoint, url.PathEscape(project), url.PathEscape(location), url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := struct {
+		Endpoint   string `json:"endpoint"`
+		MasterAuth struct {
+			ClusterCaCertificate string `json:"clusterCaCertificate"`
+		} `json:"masterAuth"`
+	}{}
+	if err := doCloudRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("unable to get GKE cluster '%s' in project '%s' and location '%s' due to %+v", name, project, location, err)
+	}
+	ca, err := base64.StdEncoding.DecodeString(response.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority of GKE cluster '%s' due to %+v", name, err)
+	}
+	return &ManagedCluster{Server: "https://" + response.Endpoint, CertificateAuthorityData: ca}, nil
+}
+
+// GetGKEToken returns the access token of the Google credentials of the environment for the GKE clusters
+func GetGKEToken() (string, error) {
+	token, err := GetGoogleAccessToken(gkeScope)
+	if err != nil {
+		return "", err
+	}
+	if len(token) == 0 {
+		return "", ValidationError("no Google credentials found, set GOOGLE_APPLICATION_CREDENTIALS or GOOGLE_OAUTH_ACCESS_TOKEN")
+	}
+	return token, nil
+}
+
+// GetAzureAccessToken returns the access token of the Azure Resource Manager API of the service principal of
+// AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET
+func GetAzureAccessToken() (string, error) {
+	tenantID, clientID, clientSecret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if len(tenantID) == 0 || len(clientID) == 0 || len(clientSecret) == 0 {
+		return "", ValidationError("AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET must be set to access the Azure Kubernetes Service")
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"scope":         {AzureManagementEndpoint + "/.default"},
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/oauth2/v2.0/token", AzureLoginEndpoint, url.PathEscape(tenantID)), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := doCloudRequest(req, &response); err != nil {
+		return "", fmt.Errorf("unable to get an Azure access token of client '%s' due to %+v", clientID, err)
+	}
+	return response.AccessToken, nil
+}
+
+// GetAKSKubeconfig returns the kubeconfig of the user credentials of an Azure Kubernetes Service cluster
+func GetAKSKubeconfig(subscription string, resourceGroup string, name string, token string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/listClusterUserCredential?api-version=%s",
+		AzureManagementEndpoint, url.PathEscape(subscription), url.PathEscape(resourceGroup), url.PathEscape(name), aksAPIVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := struct {
+		Kubeconfigs []struct {
+			Name  string `json:"name"`
+			Value []byte `json:"value"`
+		} `json:"kubeconfigs"`
+	}{}
+	if err := doCloudRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("unable to get the credentials of AKS cluster '%s' in resource group '%s' due to %+v", name, resourceGroup, err)
+	}
+	if len(response.Kubeconfigs) == 0 {
+		return nil, fmt.Errorf("AKS cluster '%s' in resource group '%s' has no user credentials", name, resourceGroup)
+	}
+	return response.Kubeconfigs[0].Value, nil
+}
+
+// AddManagedClusterToKubeconfig adds or replaces the cluster, user and context of the name in the kubeconfig, with a
+// user whose token is printed by the exec command, and makes the context current
+func AddManagedClusterToKubeconfig(config *clientcmdapi.Config, contextName string, cluster *ManagedCluster, exec *clientcmdapi.ExecConfig) {
+	clusterEntry := clientcmdapi.NewCluster()
+	clusterEntry.Server = cluster.Server
+	clusterEntry.CertificateAuthorityData = cluster.CertificateAuthorityData
+	authInfo := clientcmdapi.NewAuthInfo()
+	authInfo.Exec = exec
+	setKubeconfigContext(config, contextName, clusterEntry, authInfo)
+}
+
+// AddKubeconfigToKubeconfig adds or replaces the cluster, user and context of the name in the kubeconfig with those of
+// the current context of another kubeconfig, e.g. returned by a managed Kubernetes service, and makes the context current
+func AddKubeconfigToKubeconfig(config *clientcmdapi.Config, contextName string, data []byte) error {
+	source, err := clientcmd.Load(data)
+	if err != nil {
+		return fmt.Errorf("invalid kubeconfig due to %+v", err)
+	}
+	context, ok := source.Contexts[source.CurrentContext]
+	if !ok {
+		return fmt.Errorf("the kubeconfig has no current context")
+	}
+	cluster, ok := source.Clusters[context.Cluster]
+	if !ok {
+		return fmt.Errorf("the kubeconfig has no cluster '%s'", context.Cluster)
+	}
+	authInfo, ok := source.AuthInfos[context.AuthInfo]
+	if !ok {
+		return fmt.Errorf("the kubeconfig has no user '%s'", context.AuthInfo)
+	}
+	setKubeconfigContext(config, contextName, cluster, authInfo)
+	return nil
+}
+
+// NewExecCredential returns the credential printed by the exec plugin of a kubeconfig user
+func NewExecCredential(token string, expiration time.Time) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": ExecCredentialAPIVersion,
+		"kind":       "ExecCredential",
+		"status": map[string]interface{}{
+			"token":               token,
+			"expirationTimestamp": expiration.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// setKubeconfigContext sets the cluster, user and context of the name and makes the context current
+func setKubeconfigContext(config *clientcmdapi.Config, contextName string, cluster *clientcmdapi.Cluster, authInfo *clientcmdapi.AuthInfo) {
+	config.Clusters[contextName] = cluster
+	config.AuthInfos[contextName] = authInfo
+	context := clientcmdapi.NewContext()
+	context.Cluster = contextName
+	context.AuthInfo = contextName
+	if existing, ok := config.Contexts[contextName]; ok {
+		// keep the namespace of the context
+		context.Namespace = existing.Namespace
+	}
+	config.Contexts[contextName] = context
+	config.CurrentContext = contextName
+}
+
+// doCloudRequest sends the request to the API of a managed Kubernetes service and decodes the JSON response
+func doCloudRequest(req *http.Request, response interface{}) error {
+	resp, err := (&http.Client{Timeout: cloudAPITimeout, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("the API returned '%s': %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
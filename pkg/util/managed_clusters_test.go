@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestNewEKSToken(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	credentials := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session/token="}
+	token, err := NewEKSToken("cluster", "us-east-2", credentials, now)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(token, eksTokenPrefix))
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, eksTokenPrefix))
+	assert.NoError(err)
+	presigned, err := url.Parse(string(decoded))
+	assert.NoError(err)
+	assert.Equal("sts.us-east-2.amazonaws.com", presigned.Host)
+	query := presigned.Query()
+	assert.Equal("GetCallerIdentity", query.Get("Action"))
+	assert.Equal("AKIDEXAMPLE/20200601/us-east-2/sts/aws4_request", query.Get("X-Amz-Credential"))
+	assert.Equal("20200601T120000Z", query.Get("X-Amz-Date"))
+	assert.Equal("host;x-k8s-aws-id", query.Get("X-Amz-SignedHeaders"))
+	assert.Equal("session/token=", query.Get("X-Amz-Security-Token"))
+	assert.Len(query.Get("X-Amz-Signature"), 64)
+
+	// the signature depends on the cluster
+	other, err := NewEKSToken("other", "us-east-2", credentials, now)
+	assert.NoError(err)
+	assert.NotEqual(token, other)
+
+	_, err = NewEKSToken("cluster", "us-east-2", AWSCredentials{}, now)
+	assert.Error(err)
+}
+
+func TestGetGKECluster(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/project/locations/us-central1/clusters/cluster" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"endpoint": "10.0.0.1", "masterAuth": {"clusterCaCertificate": "%s"}}`, base64.StdEncoding.EncodeToString([]byte("ca")))
+	}))
+	defer server.Close()
+	defer func(endpoint string) { GKEEndpoint = endpoint }(GKEEndpoint)
+	GKEEndpoint = server.URL
+
+	cluster, err := GetGKECluster("project", "us-central1", "cluster", "token")
+	assert.NoError(err)
+	assert.Equal(&ManagedCluster{Server: "https://10.0.0.1", CertificateAuthorityData: []byte("ca")}, cluster)
+
+	_, err = GetGKECluster("project", "us-central1", "missing", "token")
+	assert.Error(err)
+}
+
+func TestGetAKSKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/resourceGroups/group/providers/Microsoft.ContainerService/managedClusters/cluster/listClusterUserCredential") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"kubeconfigs": []map[string]interface{}{{"name": "clusterUser", "value": []byte("kubeconfig")}}})
+	}))
+	defer server.Close()
+	defer func(endpoint string) { AzureManagementEndpoint = endpoint }(AzureManagementEndpoint)
+	AzureManagementEndpoint = server.URL
+
+	kubeconfig, err := GetAKSKubeconfig("subscription", "group", "cluster", "token")
+	assert.NoError(err)
+	assert.Equal([]byte("kubeconfig"), kubeconfig)
+}
+
+func TestAddManagedClusterToKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config := clientcmdapi.NewConfig()
+	config.Contexts["gke_project_us-central1_cluster"] = &clientcmdapi.Context{Cluster: "old", AuthInfo: "old", Namespace: "synopsys"}
+	exec := &clientcmdapi.ExecConfig{APIVersion: ExecCredentialAPIVersion, Command: "synopsysctl", Args: []string{"config", "get-token", "--from-gke"}}
+	AddManagedClusterToKubeconfig(config, "gke_project_us-central1_cluster", &ManagedCluster{Server: "https://10.0.0.1", CertificateAuthorityData: []byte("ca")}, exec)
+
+	assert.Equal("gke_project_us-central1_cluster", config.CurrentContext)
+	assert.Equal("https://10.0.0.1", config.Clusters["gke_project_us-central1_cluster"].Server)
+	assert.Equal(exec, config.AuthInfos["gke_project_us-central1_cluster"].Exec)
+	context := config.Contexts["gke_project_us-central1_cluster"]
+	assert.Equal("gke_project_us-central1_cluster", context.Cluster)
+	assert.Equal("synopsys", context.Namespace)
+}
+
+func TestAddKubeconfigToKubeconfig(t *testing.T) {
+	assert := assert.New(t)
+
+	aksKubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://cluster.hcp.eastus.azmk8s.io:443
+contexts:
+- name: cluster
+  context:
+    cluster: cluster
+    user: clusterUser_group_cluster
+current-context: cluster
+users:
+- name: clusterUser_group_cluster
+  user:
+    token: secret
+`
+	config := clientcmdapi.NewConfig()
+	assert.NoError(AddKubeconfigToKubeconfig(config, "aks-cluster", []byte(aksKubeconfig)))
+	assert.Equal("aks-cluster", config.CurrentContext)
+	assert.Equal("https://cluster.hcp.eastus.azmk8s.io:443", config.Clusters["aks-cluster"].Server)
+	assert.Equal("secret", config.AuthInfos["aks-cluster"].Token)
+
+	assert.Error(AddKubeconfigToKubeconfig(config, "aks-cluster", []byte("apiVersion: v1\nkind: Config\n")))
+}
+
+func TestNewExecCredential(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := NewExecCredential("token", time.Date(2020, time.June, 1, 12, 14, 0, 0, time.UTC))
+	assert.NoError(err)
+	assert.JSONEq(`{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential", "status": {"token": "token", "expirationTimestamp": "2020-06-01T12:14:00Z"}}`, string(data))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Progress markers in the logs of the database migrations
+var (
+	// Flyway logs a line per applied migration and a summary at the end
+	migrationStepRegexp     = regexp.MustCompile(`Migrating schema "?([^" ]+)"? to version "?([^" ]+)"?`)
+	migrationDoneRegexp     = regexp.MustCompile(`Successfully applied (\d+) migrations?`)
+	migrationUpToDateRegexp = regexp.MustCompile(`Schema "?[^" ]+"? is up to date`)
+	migrationFailedRegexp   = regexp.MustCompile(`(?i)migration.*\bfailed\b`)
+	migrationCounterRegexp  = regexp.MustCompile(`(?i)migrat.*\b(\d+)\s*(?:/|of)\s*(\d+)\b`)
+)
+
+// MigrationProgress is the progress of the database migrations parsed from the logs of the migrating pod
+type MigrationProgress struct {
+	Started time.Time
+	// Applied is the number of migrations applied since the progress is followed
+	Applied int
+	// Current and Total are set if the logs contain a counter of the migrations, e.g. 12/40
+	Current  int
+	Total    int
+	LastStep string
+	Done     bool
+	Failure  string
+}
+
+// NewMigrationProgress returns the progress of migrations started at the time
+func NewMigrationProgress(started time.Time) *MigrationProgress {
+	return &MigrationProgress{Started: started}
+}
+
+// ParseLine updates the progress with a log line and returns true if the line is a progress marker
+func (p *MigrationProgress) ParseLine(line string) bool {
+	if match := migrationDoneRegexp.FindStringSubmatch(line); match != nil {
+		p.Done = true
+		return true
+	}
+	if migrationUpToDateRegexp.MatchString(line) {
+		p.Done = true
+		return true
+	}
+	if migrationFailedRegexp.MatchString(line) {
+		p.Failure = line
+		return true
+	}
+	marker := false
+	if match := migrationStepRegexp.FindStringSubmatch(line); match != nil {
+		p.Applied++
+		p.LastStep = fmt.Sprintf("%s %s", match[1], match[2])
+		marker = true
+	}
+	if match := migrationCounterRegexp.FindStringSubmatch(line); match != nil {
+		current, _ := strconv.Atoi(match[1])
+		total, _ := strconv.Atoi(match[2])
+		if current <= total && total > 0 {
+			p.Current, p.Total = current, total
+			marker = true
+		}
+	}
+	return marker
+}
+
+// ETA returns the estimated remaining time of the migrations, which is only known if the logs contain a counter
+func (p *MigrationProgress) ETA(now time.Time) (time.Duration, bool) {
+	if p.Total == 0 || p.Current == 0 {
+		return 0, false
+	}
+	perMigration := now.Sub(p.Started) / time.Duration(p.Current)
+	return perMigration * time.Duration(p.Total-p.Current), true
+}
+
+// Percent returns the percent of the applied migrations, ProgressUnknown if the logs don't contain a counter
+func (p *MigrationProgress) Percent() int {
+	switch {
+	case p.Done:
+		return 100
+	case p.Total == 0:
+		return ProgressUnknown
+	}
+	return p.Current * 100 / p.Total
+}
+
+// Status returns a one line description of the progress
+func (p *MigrationProgress) Status(now time.Time) string {
+	elapsed := now.Sub(p.Started).Round(time.Second)
+	switch {
+	case len(p.Failure) > 0:
+		return fmt.Sprintf("migration failed after %s: %s", elapsed, p.Failure)
+	case p.Done:
+		return fmt.Sprintf("migrations completed in %s", elapsed)
+	}
+	status := fmt.Sprintf("%d migration(s) applied in %s", p.Applied, elapsed)
+	if p.Total > 0 {
+		status = fmt.Sprintf("migration %d/%d, %s elapsed", p.Current, p.Total, elapsed)
+	}
+	if len(p.LastStep) > 0 {
+		status = fmt.Sprintf("%s, last %s", status, p.LastStep)
+	}
+	if eta, ok := p.ETA(now); ok {
+		status = fmt.Sprintf("%s, ETA %s", status, eta.Round(time.Second))
+	}
+	return status
+}
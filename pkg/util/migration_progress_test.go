@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrationProgress(t *testing.T) {
+	started := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		lines           []string
+		expectedApplied int
+		expectedDone    bool
+		expectedFailed  bool
+		expectedETA     time.Duration
+		expectETA       bool
+	}{
+		{
+			name: "flyway steps",
+			lines: []string{
+				`Current version of schema "st": 2020.4.0.010`,
+				`Migrating schema "st" to version 2020.6.0.001 - add column`,
+				`Migrating schema "st" to version 2020.6.0.002 - add index`,
+			},
+			expectedApplied: 2,
+		},
+		{
+			name: "flyway done",
+			lines: []string{
+				`Migrating schema "st" to version 2020.6.0.001 - add column`,
+				`Successfully applied 1 migration to schema "st" (execution time 00:01.012s)`,
+			},
+			expectedApplied: 1,
+			expectedDone:    true,
+		},
+		{
+			name:         "up to date",
+			lines:        []string{`Schema "st" is up to date. No migration necessary.`},
+			expectedDone: true,
+		},
+		{
+			name:           "failed",
+			lines:          []string{`Migration of schema "st" to version 2020.6.0.002 failed! Changes successfully rolled back.`},
+			expectedFailed: true,
+		},
+		{
+			name:        "counter",
+			lines:       []string{`Running migration 10/40`},
+			expectedETA: 30 * time.Minute,
+			expectETA:   true,
+		},
+	}
+
+	for _, test := range tests {
+		progress := NewMigrationProgress(started)
+		for _, line := range test.lines {
+			progress.ParseLine(line)
+		}
+		if progress.Applied != test.expectedApplied || progress.Done != test.expectedDone || (len(progress.Failure) > 0) != test.expectedFailed {
+			t.Errorf("%s: unexpected progress %+v", test.name, progress)
+		}
+		eta, ok := progress.ETA(started.Add(10 * time.Minute))
+		if ok != test.expectETA || eta != test.expectedETA {
+			t.Errorf("%s: expected ETA %s, got %s", test.name, test.expectedETA, eta)
+		}
+	}
+}
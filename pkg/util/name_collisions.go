@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// existingObjectFunc returns the labels of an object of the rendered manifest that already exists in the cluster, or
+// false if it doesn't exist
+type existingObjectFunc func(object *unstructured.Unstructured) (map[string]string, bool, error)
+
+// SuggestInstanceName returns another name for an instance whose name collides with an instance of another product,
+// e.g. 'bd-alert' for an Alert instance named 'bd'
+func SuggestInstanceName(releaseName string, chartName string) string {
+	product := strings.TrimPrefix(chartName, "synopsys-")
+	if len(product) == 0 || strings.HasSuffix(releaseName, "-"+product) {
+		return fmt.Sprintf("%s-2", releaseName)
+	}
+	return fmt.Sprintf("%s-%s", releaseName, product)
+}
+
+// CheckReleaseNameCollision returns an error if a release with the name of the new instance exists, with a rename
+// suggestion if the release is an instance of another product
+func CheckReleaseNameCollision(existing *release.Release, chartName string) error {
+	if existing == nil {
+		return nil
+	}
+	existingChart := ""
+	if existing.Chart != nil && existing.Chart.Metadata != nil {
+		existingChart = existing.Chart.Metadata.Name
+	}
+	if len(existingChart) == 0 || existingChart == chartName {
+		return fmt.Errorf("release '%s' already exists in namespace '%s'", existing.Name, existing.Namespace)
+	}
+	return ValidationError("the name '%s' is already used by an instance of chart '%s' in namespace '%s', choose another name for the instance of chart '%s', e.g. '%s'",
+		existing.Name, existingChart, existing.Namespace, chartName, SuggestInstanceName(existing.Name, chartName))
+}
+
+// describeResourceOwner returns the instance that the labels of a resource belong to. The charts label their resources
+// with the product in 'app' and the instance in 'name', other charts use the recommended Kubernetes labels
+func describeResourceOwner(labels map[string]string) string {
+	app := labels["app"]
+	if len(app) == 0 {
+		app = labels["app.kubernetes.io/name"]
+	}
+	instance := labels["name"]
+	if len(instance) == 0 {
+		instance = labels["app.kubernetes.io/instance"]
+	}
+	switch {
+	case len(app) > 0 && len(instance) > 0:
+		return fmt.Sprintf("belongs to the %s instance '%s'", app, instance)
+	case len(app) > 0:
+		return fmt.Sprintf("belongs to an instance of %s", app)
+	case len(instance) > 0:
+		return fmt.Sprintf("belongs to the instance '%s'", instance)
+	}
+	return "was not created by synopsysctl"
+}
+
+// checkNameCollisions returns an error that lists the objects of the rendered manifest that already exist, since Helm
+// refuses to install a release over resources it doesn't own
+func checkNameCollisions(releaseName string, namespace string, chartName string, manifest string, existingObject existingObjectFunc) error {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return err
+	}
+	collisions := []string{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		labels, exists, err := existingObject(u)
+		if err != nil {
+			return fmt.Errorf("unable to get %s '%s' due to %+v", u.GetKind(), u.GetName(), err)
+		}
+		if exists {
+			collisions = append(collisions, fmt.Sprintf("%s '%s' already exists and %s", u.GetKind(), u.GetName(), describeResourceOwner(labels)))
+		}
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	sort.Strings(collisions)
+	return ValidationError("the name '%s' collides with resources in namespace '%s' that Helm can't take over:\n  - %s\nchoose another name for the instance, e.g. '%s'",
+		releaseName, namespace, strings.Join(collisions, "\n  - "), SuggestInstanceName(releaseName, chartName))
+}
+
+// CheckNameCollisionsForActionConfig renders the chart of a new instance and returns an error if any of its objects
+// already exists in the cluster, e.g. a service of an instance of another product with the same name
+func CheckNameCollisionsForActionConfig(releaseName string, namespace string, chart *chart.Chart, vals map[string]interface{}, actionConfig *action.Configuration) error {
+	manifest, err := RenderManifests(releaseName, namespace, chart, vals, actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests to check the names of the resources due to %+v", err)
+	}
+	restConfig, err := actionConfig.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	mapper, err := actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("unable to create the REST mapper due to %+v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	return checkNameCollisions(releaseName, namespace, chart.Metadata.Name, manifest, func(object *unstructured.Unstructured) (map[string]string, bool, error) {
+		gvk := object.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}, gvk.Version)
+		if err != nil {
+			// the API versions are checked separately, an unknown kind can't collide
+			return nil, false, nil
+		}
+		var client dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objectNamespace := object.GetNamespace()
+			if len(objectNamespace) == 0 {
+				objectNamespace = namespace
+			}
+			client = dynamicClient.Resource(mapping.Resource).Namespace(objectNamespace)
+		}
+		existing, err := client.Get(object.GetName(), metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+			// a user with restricted permissions gets the error of Helm instead
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+		return existing.GetLabels(), true, nil
+	})
+}
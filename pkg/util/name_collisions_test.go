@@ -0,0 +1,109 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSuggestInstanceName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("bd-alert", SuggestInstanceName("bd", "synopsys-alert"))
+	assert.Equal("bd-blackduck", SuggestInstanceName("bd", "blackduck"))
+	assert.Equal("bd-alert-2", SuggestInstanceName("bd-alert", "synopsys-alert"))
+	assert.Equal("bd-2", SuggestInstanceName("bd", ""))
+}
+
+func TestCheckReleaseNameCollision(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(CheckReleaseNameCollision(nil, "synopsys-alert"))
+
+	existing := &release.Release{Name: "bd", Namespace: "ns", Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "synopsys-alert"}}}
+	err := CheckReleaseNameCollision(existing, "synopsys-alert")
+	assert.EqualError(err, "release 'bd' already exists in namespace 'ns'")
+	assert.NotEqual(ExitCodeValidation, ExitCode(err))
+
+	err = CheckReleaseNameCollision(existing, "blackduck")
+	assert.Error(err)
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+	assert.Contains(err.Error(), "instance of chart 'synopsys-alert'")
+	assert.Contains(err.Error(), "e.g. 'bd-blackduck'")
+}
+
+func TestCheckNameCollisions(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: bd-alert
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bd-alert-secret
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-alert
+`
+	existing := map[string]map[string]string{
+		"Service/bd-alert":       {"app": "blackduck", "name": "bd"},
+		"Secret/bd-alert-secret": {},
+	}
+	existingObject := func(object *unstructured.Unstructured) (map[string]string, bool, error) {
+		labels, ok := existing[object.GetKind()+"/"+object.GetName()]
+		return labels, ok, nil
+	}
+
+	err := checkNameCollisions("bd", "ns", "synopsys-alert", manifest, existingObject)
+	assert.Error(err)
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+	assert.Contains(err.Error(), "Service 'bd-alert' already exists and belongs to the blackduck instance 'bd'")
+	assert.Contains(err.Error(), "Secret 'bd-alert-secret' already exists and was not created by synopsysctl")
+	assert.NotContains(err.Error(), "Deployment")
+	assert.Contains(err.Error(), "e.g. 'bd-alert'")
+
+	assert.NoError(checkNameCollisions("other", "ns", "synopsys-alert", "apiVersion: v1\nkind: Service\nmetadata:\n  name: other-alert\n", existingObject))
+
+	assert.Error(checkNameCollisions("bd", "ns", "synopsys-alert", manifest, func(object *unstructured.Unstructured) (map[string]string, bool, error) {
+		return nil, false, errors.New("unavailable")
+	}))
+}
+
+func TestDescribeResourceOwner(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("belongs to the blackduck instance 'bd'", describeResourceOwner(map[string]string{"app": "blackduck", "name": "bd"}))
+	assert.Equal("belongs to the bdba instance 'scanner'", describeResourceOwner(map[string]string{"app.kubernetes.io/name": "bdba", "app.kubernetes.io/instance": "scanner"}))
+	assert.Equal("belongs to an instance of alert", describeResourceOwner(map[string]string{"app": "alert"}))
+	assert.Equal("was not created by synopsysctl", describeResourceOwner(nil))
+}
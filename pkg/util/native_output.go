@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats of the native commands
+const (
+	NativeOutputFormatYAML = "yaml"
+	NativeOutputFormatJSON = "json"
+)
+
+// KustomizationFileName is the name of the index of the files written by WriteManifestFiles
+const KustomizationFileName = "kustomization.yaml"
+
+// ValidateNativeOutputFormat returns an error if the format isn't an output format of the native commands
+func ValidateNativeOutputFormat(format string) error {
+	switch strings.ToLower(format) {
+	case NativeOutputFormatYAML, NativeOutputFormatJSON:
+		return nil
+	}
+	return ValidationError("output format must be '%s' or '%s', got '%s'", NativeOutputFormatYAML, NativeOutputFormatJSON, format)
+}
+
+// GetManifestObjects returns the objects followed by the objects of the rendered manifests in their generic form
+func GetManifestObjects(objects []interface{}, manifests string) ([]map[string]interface{}, error) {
+	items := []map[string]interface{}{}
+	for _, object := range objects {
+		// convert the typed objects to the generic form of the objects of the manifests
+		data, err := json.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert the object to JSON due to %+v", err)
+		}
+		item := map[string]interface{}{}
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("unable to convert the object to JSON due to %+v", err)
+		}
+		items = append(items, item)
+	}
+	manifestObjects, err := SplitManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	return append(items, manifestObjects...), nil
+}
+
+// NewManifestList returns the objects followed by the objects of the rendered manifests as a single v1 List, the
+// document that 'kubectl apply -f' and the tools consuming JSON accept
+func NewManifestList(objects []interface{}, manifests string) (map[string]interface{}, error) {
+	manifestObjects, err := GetManifestObjects(objects, manifests)
+	if err != nil {
+		return nil, err
+	}
+	items := []interface{}{}
+	for _, object := range manifestObjects {
+		items = append(items, object)
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}, nil
+}
+
+// manifestFileNameRegexp matches the characters that are replaced in the file names of the objects
+var manifestFileNameRegexp = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// GetManifestFileName returns the file name of an object, <kind>-<name>.<format> in lower case
+func GetManifestFileName(object map[string]interface{}, format string) string {
+	kind, _ := object["kind"].(string)
+	name, _ := GetHelmValueFromMap(object, []string{"metadata", "name"}).(string)
+	return fmt.Sprintf("%s-%s.%s", manifestFileNameRegexp.ReplaceAllString(strings.ToLower(kind), "-"), manifestFileNameRegexp.ReplaceAllString(strings.ToLower(name), "-"), strings.ToLower(format))
+}
+
+// WriteManifestFiles writes each object to its own file in the directory, in the output format of the native commands,
+// and a kustomization.yaml of the files in the namespace. It returns the names of the files of the objects
+func WriteManifestFiles(dir string, namespace string, objects []map[string]interface{}, format string) ([]string, error) {
+	if err := ValidateNativeOutputFormat(format); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the directory '%s' due to %+v", dir, err)
+	}
+	extension := strings.ToLower(format)
+	fileNames := []string{}
+	written := map[string]bool{}
+	for _, object := range objects {
+		fileName := GetManifestFileName(object, format)
+		// objects of the same kind and name, e.g. in different API groups, don't overwrite each other
+		base := strings.TrimSuffix(fileName, "."+extension)
+		for i := 2; written[fileName]; i++ {
+			fileName = fmt.Sprintf("%s-%d.%s", base, i, extension)
+		}
+		written[fileName] = true
+
+		var data []byte
+		var err error
+		if extension == NativeOutputFormatJSON {
+			data, err = json.MarshalIndent(object, "", "  ")
+		} else {
+			data, err = yaml.Marshal(object)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal '%s' due to %+v", fileName, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+			return nil, fmt.Errorf("unable to write '%s' due to %+v", fileName, err)
+		}
+		fileNames = append(fileNames, fileName)
+	}
+
+	kustomization := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  fileNames,
+	}
+	if len(namespace) > 0 {
+		kustomization["namespace"] = namespace
+	}
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, KustomizationFileName), data, 0644); err != nil {
+		return nil, fmt.Errorf("unable to write '%s' due to %+v", KustomizationFileName, err)
+	}
+	return fileNames, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateNativeOutputFormat(t *testing.T) {
+	assert := assert.New(t)
+	assert.NoError(ValidateNativeOutputFormat("yaml"))
+	assert.NoError(ValidateNativeOutputFormat("JSON"))
+	assert.Error(ValidateNativeOutputFormat("table"))
+}
+
+func TestNewManifestList(t *testing.T) {
+	assert := assert.New(t)
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "certificate", Namespace: "default"},
+		Data:       map[string][]byte{"tls.crt": []byte("crt")},
+	}
+	manifests := `---
+# Source: blackduck/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: webserver
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webserver
+`
+	list, err := NewManifestList([]interface{}{secret}, manifests)
+	assert.NoError(err)
+	assert.Equal("List", list["kind"])
+	items := list["items"].([]interface{})
+	assert.Len(items, 3)
+	assert.Equal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "certificate", "namespace": "default", "creationTimestamp": nil},
+		"data":       map[string]interface{}{"tls.crt": "Y3J0"},
+	}, items[0])
+	assert.Equal("Service", items[1].(map[string]interface{})["kind"])
+	assert.Equal("Deployment", items[2].(map[string]interface{})["kind"])
+
+	list, err = NewManifestList(nil, "")
+	assert.NoError(err)
+	assert.Equal([]interface{}{}, list["items"])
+}
+
+func TestGetManifestFileName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("deployment-webserver.yaml", GetManifestFileName(map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"name": "webserver"}}, "yaml"))
+	assert.Equal("clusterrolebinding-system-blackduck.json", GetManifestFileName(map[string]interface{}{"kind": "ClusterRoleBinding", "metadata": map[string]interface{}{"name": "system:blackduck"}}, "JSON"))
+}
+
+func TestWriteManifestFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "manifests")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	objects := []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "webserver"}},
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "webserver"}},
+		{"apiVersion": "extensions/v1beta1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "webserver"}},
+	}
+	fileNames, err := WriteManifestFiles(filepath.Join(dir, "blackduck"), "synopsys", objects, "yaml")
+	assert.NoError(err)
+	assert.Equal([]string{"service-webserver.yaml", "deployment-webserver.yaml", "deployment-webserver-2.yaml"}, fileNames)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "blackduck", "deployment-webserver.yaml"))
+	assert.NoError(err)
+	assert.Equal("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: webserver\n", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "blackduck", KustomizationFileName))
+	assert.NoError(err)
+	assert.Equal(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: synopsys
+resources:
+- service-webserver.yaml
+- deployment-webserver.yaml
+- deployment-webserver-2.yaml
+`, string(data))
+
+	_, err = WriteManifestFiles(dir, "synopsys", objects, "table")
+	assert.Error(err)
+}
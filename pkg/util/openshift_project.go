@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	projectv1 "github.com/openshift/api/project/v1"
+	projectclient "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// OpenShiftNodeSelectorAnnotation is the annotation of a project that restricts its pods to the matching nodes
+const OpenShiftNodeSelectorAnnotation = "openshift.io/node-selector"
+
+// OpenShiftProject is a project created with a ProjectRequest, so that the project template of the cluster (quotas,
+// limit ranges, role bindings, network policies) applies to it as it does to the projects created with 'oc new-project'
+type OpenShiftProject struct {
+	Name         string
+	DisplayName  string
+	Description  string
+	NodeSelector string
+	Annotations  map[string]string
+	Labels       map[string]string
+}
+
+// GetAnnotations returns the annotations that are added to the namespace of the project after it is created
+func (p OpenShiftProject) GetAnnotations() (map[string]string, error) {
+	annotations := map[string]string{}
+	for key, value := range p.Annotations {
+		annotations[key] = value
+	}
+	if len(p.NodeSelector) > 0 {
+		if _, err := labels.ConvertSelectorToLabelsMap(p.NodeSelector); err != nil {
+			return nil, ValidationError("invalid node selector '%s' of project '%s': %+v", p.NodeSelector, p.Name, err)
+		}
+		annotations[OpenShiftNodeSelectorAnnotation] = p.NodeSelector
+	}
+	return annotations, nil
+}
+
+// CreateOpenShiftProject requests the project and adds the annotations and labels to its namespace. Adding the node
+// selector annotation usually requires a cluster administrator, the project is kept if the namespace can't be updated
+func CreateOpenShiftProject(restConfig *rest.Config, clientset *kubernetes.Clientset, project OpenShiftProject) (*corev1.Namespace, error) {
+	annotations, err := project.GetAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	projectClient, err := projectclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the OpenShift project client due to %+v", err)
+	}
+	_, err = projectClient.ProjectRequests().Create(&projectv1.ProjectRequest{
+		ObjectMeta:  metav1.ObjectMeta{Name: project.Name},
+		DisplayName: project.DisplayName,
+		Description: project.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to request OpenShift project '%s' due to %+v", project.Name, err)
+	}
+
+	// the namespace of the project is created by the project template and can take a moment to be visible
+	var ns *corev1.Namespace
+	for attempt := 0; ; attempt++ {
+		ns, err = GetNamespace(clientset, project.Name)
+		if err == nil {
+			break
+		}
+		if !k8serrors.IsNotFound(err) || attempt >= 10 {
+			return nil, fmt.Errorf("unable to get the namespace of OpenShift project '%s' due to %+v", project.Name, err)
+		}
+		time.Sleep(time.Second)
+	}
+	if len(annotations) == 0 && len(project.Labels) == 0 {
+		return ns, nil
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		ns.Annotations[key] = value
+	}
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	for key, value := range project.Labels {
+		ns.Labels[key] = value
+	}
+	updated, err := UpdateNamespace(clientset, ns)
+	if err != nil {
+		return ns, fmt.Errorf("OpenShift project '%s' was created but its annotations and labels couldn't be set due to %+v", project.Name, err)
+	}
+	return updated, nil
+}
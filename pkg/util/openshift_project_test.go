@@ -0,0 +1,45 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenShiftProjectGetAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	annotations, err := OpenShiftProject{Name: "bd"}.GetAnnotations()
+	assert.NoError(err)
+	assert.Empty(annotations)
+
+	project := OpenShiftProject{Name: "bd", NodeSelector: "region=east,tier=apps", Annotations: map[string]string{"owner": "ops"}}
+	annotations, err = project.GetAnnotations()
+	assert.NoError(err)
+	assert.Equal(map[string]string{"owner": "ops", OpenShiftNodeSelectorAnnotation: "region=east,tier=apps"}, annotations)
+	assert.Len(project.Annotations, 1)
+
+	_, err = OpenShiftProject{Name: "bd", NodeSelector: "region"}.GetAnnotations()
+	assert.Error(err)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Output controls of synopsysctl
+var (
+	// Quiet suppresses all messages except the errors
+	Quiet = false
+	// NoColor disables the colors of the log messages
+	NoColor = false
+)
+
+// IsTerminal returns true if the file is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ConfigureOutput sets the level and the formatter of the logger from the output controls. Colors are disabled
+// if the --no-color flag or the NO_COLOR environ is set, or if the logs are not written to a terminal
+func ConfigureOutput(quiet bool, noColor bool) {
+	Quiet = quiet
+	_, noColorEnv := os.LookupEnv("NO_COLOR")
+	NoColor = noColor || noColorEnv || !IsTerminal(os.Stderr)
+	if Quiet {
+		log.SetLevel(log.ErrorLevel)
+	}
+	log.SetFormatter(&log.TextFormatter{
+		DisableColors: NoColor,
+		ForceColors:   !NoColor,
+		FullTimestamp: NoColor,
+	})
+}
+
+// ShowProgress returns true if periodic progress messages should be printed, i.e. synopsysctl isn't quiet and
+// runs in a terminal. In CI logs and when the output is piped to a file only the changes of the progress are printed
+func ShowProgress() bool {
+	return !Quiet && IsTerminal(os.Stderr)
+}
+
+// MessageWriter returns the writer for informational messages that aren't the result of a command; it
+// discards the messages if synopsysctl is quiet
+func MessageWriter() io.Writer {
+	if Quiet {
+		return ioutil.Discard
+	}
+	return os.Stderr
+}
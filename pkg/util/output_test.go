@@ -0,0 +1,67 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestIsTerminal(t *testing.T) {
+	f, err := ioutil.TempFile("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if IsTerminal(f) {
+		t.Errorf("regular file '%s' is reported as a terminal", f.Name())
+	}
+	if IsTerminal(nil) {
+		t.Errorf("nil file is reported as a terminal")
+	}
+}
+
+func TestConfigureOutput(t *testing.T) {
+	level := log.GetLevel()
+	defer func() {
+		log.SetLevel(level)
+		ConfigureOutput(false, false)
+	}()
+
+	ConfigureOutput(true, true)
+	if log.GetLevel() != log.ErrorLevel {
+		t.Errorf("expected the log level %s with --quiet, got %s", log.ErrorLevel, log.GetLevel())
+	}
+	if !NoColor {
+		t.Errorf("expected colors to be disabled with --no-color")
+	}
+	if ShowProgress() {
+		t.Errorf("expected no progress with --quiet")
+	}
+	if MessageWriter() != ioutil.Discard {
+		t.Errorf("expected the messages to be discarded with --quiet")
+	}
+}
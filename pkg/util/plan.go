@@ -0,0 +1,272 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Actions of the resources of a plan
+const (
+	PlanActionCreate    = "create"
+	PlanActionUpdate    = "update"
+	PlanActionApply     = "apply"
+	PlanActionDelete    = "delete"
+	PlanActionUnchanged = "unchanged"
+)
+
+// Helm operations of a plan
+const (
+	PlanHelmInstall   = "install"
+	PlanHelmUpgrade   = "upgrade"
+	PlanHelmUninstall = "uninstall"
+	PlanHelmRollback  = "rollback"
+)
+
+// Modes of a dry run
+const (
+	DryRunClient = "client"
+	DryRunServer = "server"
+)
+
+// ActivePlan records the changes of the command instead of making them if it is set. The Helm operations complete the
+// plan, the command stops at the first one
+var ActivePlan *Plan
+
+// ErrPlanComplete is returned by the Helm operations of a plan to stop the command
+var ErrPlanComplete = errors.New("stopped after planning the changes")
+
+// Plan is the list of changes that a command would make to the cluster, suitable for approvals and audit records
+type Plan struct {
+	Command        string              `json:"command"`
+	CorrelationID  string              `json:"correlationID,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	HelmOperations []PlanHelmOperation `json:"helmOperations"`
+	Secrets        []PlanResource      `json:"secrets"`
+	Resources      []PlanResource      `json:"resources"`
+	// Complete is true once the command reached a Helm operation, the changes after it are not known
+	Complete bool `json:"-"`
+	// DryRun is the mode of a dry run, the plan then prints the manifests of its Helm operations
+	DryRun string `json:"-"`
+	// Diff is true if the plan keeps the current and the new manifest of its Helm operations to compare them
+	Diff bool `json:"-"`
+}
+
+// PlanHelmOperation is a Helm operation of a plan
+type PlanHelmOperation struct {
+	Operation string `json:"operation"`
+	Release   string `json:"release"`
+	Namespace string `json:"namespace"`
+	Chart     string `json:"chart,omitempty"`
+	// Manifest is applied by the operation, or deleted by an uninstall, and is only set in a dry run or a diff
+	Manifest string `json:"-"`
+	// CurrentManifest is the manifest of the release before the operation, and is only set in a diff
+	CurrentManifest string `json:"-"`
+}
+
+// PlanResource is a change of a Kubernetes resource
+type PlanResource struct {
+	Action    string `json:"action"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// StartPlan sets the active plan of a command
+func StartPlan(command string) *Plan {
+	ActivePlan = &Plan{
+		Command:        command,
+		CorrelationID:  CorrelationID,
+		CreatedAt:      time.Now(),
+		HelmOperations: []PlanHelmOperation{},
+		Secrets:        []PlanResource{},
+		Resources:      []PlanResource{},
+	}
+	return ActivePlan
+}
+
+// AddHelmOperation adds a Helm operation to the plan and completes it
+func (p *Plan) AddHelmOperation(operation string, releaseName string, namespace string, chartURL string) {
+	p.HelmOperations = append(p.HelmOperations, PlanHelmOperation{Operation: operation, Release: releaseName, Namespace: namespace, Chart: chartURL})
+	p.Complete = true
+}
+
+// AddResource adds a change of a resource to the plan, the secrets are listed separately
+func (p *Plan) AddResource(action string, kind string, name string, namespace string) {
+	resource := PlanResource{Action: action, Kind: kind, Name: name, Namespace: namespace}
+	if kind == "Secret" {
+		p.Secrets = append(p.Secrets, resource)
+	} else {
+		p.Resources = append(p.Resources, resource)
+	}
+}
+
+// AddManifestChanges adds the changes between the objects of the current and the new manifest of a release
+func (p *Plan) AddManifestChanges(currentManifest string, newManifest string, namespace string) error {
+	current, err := manifestObjectsByKey(currentManifest, namespace)
+	if err != nil {
+		return err
+	}
+	planned, err := manifestObjectsByKey(newManifest, namespace)
+	if err != nil {
+		return err
+	}
+	keys := []string{}
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range planned {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		currentObject, inCurrent := current[key]
+		plannedObject, inPlanned := planned[key]
+		action := PlanActionUnchanged
+		object := plannedObject
+		switch {
+		case !inCurrent:
+			action = PlanActionCreate
+		case !inPlanned:
+			action, object = PlanActionDelete, currentObject
+		default:
+			currentHash, err := HashObject(currentObject.Object)
+			if err != nil {
+				return err
+			}
+			plannedHash, err := HashObject(plannedObject.Object)
+			if err != nil {
+				return err
+			}
+			if currentHash != plannedHash {
+				action = PlanActionUpdate
+			}
+		}
+		p.AddResource(action, object.GetKind(), object.GetName(), object.GetNamespace())
+	}
+	return nil
+}
+
+// AddHelmManifests adds the changes between the current and the new manifest of the last Helm operation. In a dry run
+// it adds the manifest that the operation applies instead, or the current one if the operation deletes the release, and
+// in a diff it keeps both manifests
+func (p *Plan) AddHelmManifests(currentManifest string, newManifest string, namespace string) error {
+	if len(p.DryRun) == 0 && !p.Diff {
+		return p.AddManifestChanges(currentManifest, newManifest, namespace)
+	}
+	if len(p.HelmOperations) == 0 {
+		return fmt.Errorf("no Helm operation to add the manifest to")
+	}
+	if p.Diff {
+		operation := &p.HelmOperations[len(p.HelmOperations)-1]
+		operation.CurrentManifest, operation.Manifest = currentManifest, newManifest
+		return nil
+	}
+	manifest := newManifest
+	if len(manifest) == 0 {
+		manifest = currentManifest
+	}
+	p.HelmOperations[len(p.HelmOperations)-1].Manifest = strings.TrimSpace(manifest)
+	return nil
+}
+
+// manifestObjectsByKey returns the objects of a manifest by kind, namespace and name
+func manifestObjectsByKey(manifest string, namespace string) (map[string]*unstructured.Unstructured, error) {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]*unstructured.Unstructured{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		if len(u.GetNamespace()) == 0 {
+			u.SetNamespace(namespace)
+		}
+		byKey[fmt.Sprintf("%s/%s/%s", u.GetKind(), u.GetNamespace(), u.GetName())] = u
+	}
+	return byKey, nil
+}
+
+// Print writes the plan as a table or as JSON
+func (p *Plan) Print(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(p)
+	case "", "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintf(tw, "PLAN OF '%s'\n", p.Command)
+		fmt.Fprintln(tw, "ACTION\tKIND\tNAME\tNAMESPACE")
+		for _, operation := range p.HelmOperations {
+			fmt.Fprintf(tw, "%s\tHelmRelease\t%s\t%s\n", operation.Operation, operation.Release, operation.Namespace)
+		}
+		for _, resources := range [][]PlanResource{p.Secrets, p.Resources} {
+			for _, resource := range resources {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", resource.Action, resource.Kind, resource.Name, resource.Namespace)
+			}
+		}
+		return tw.Flush()
+	default:
+		return ValidationError("plan output must be 'table' or 'json', but got '%s'", format)
+	}
+}
+
+// PrintDryRun writes the manifests of the Helm operations of a dry run, followed by the resources that the command
+// changes outside of Helm as comments
+func (p *Plan) PrintDryRun(w io.Writer) error {
+	for _, operation := range p.HelmOperations {
+		verb := "applies"
+		if operation.Operation == PlanHelmUninstall {
+			verb = "deletes"
+		}
+		if _, err := fmt.Fprintf(w, "# Helm %s of release '%s' in namespace '%s' (%s dry run) %s:\n---\n%s\n", operation.Operation, operation.Release, operation.Namespace, p.DryRun, verb, operation.Manifest); err != nil {
+			return err
+		}
+	}
+	// the manifest changes are not added in a dry run, so the resources are the ones synopsysctl changes itself
+	resources := append(append([]PlanResource{}, p.Secrets...), p.Resources...)
+	if len(resources) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "# Changes outside of Helm:"); err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if _, err := fmt.Fprintf(w, "# %s %s '%s' in namespace '%s'\n", resource.Action, resource.Kind, resource.Name, resource.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanAddManifestChanges(t *testing.T) {
+	currentManifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: old
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: removed
+`
+	newManifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: new
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: added
+`
+	plan := &Plan{}
+	assert.NoError(t, plan.AddManifestChanges(currentManifest, newManifest, "ns"))
+	assert.Equal(t, []PlanResource{
+		{Action: PlanActionUpdate, Kind: "ConfigMap", Name: "changed", Namespace: "ns"},
+		{Action: PlanActionUnchanged, Kind: "ConfigMap", Name: "unchanged", Namespace: "ns"},
+		{Action: PlanActionDelete, Kind: "Service", Name: "removed", Namespace: "ns"},
+	}, plan.Resources)
+	assert.Equal(t, []PlanResource{{Action: PlanActionCreate, Kind: "Secret", Name: "added", Namespace: "ns"}}, plan.Secrets)
+}
+
+func TestPlanPrint(t *testing.T) {
+	plan := &Plan{Command: "synopsysctl update blackduck"}
+	plan.AddResource(PlanActionApply, "Secret", "bd-blackduck-webserver-certificate", "ns")
+	plan.AddHelmOperation(PlanHelmUpgrade, "bd", "ns", "https://example.com/blackduck.tgz")
+	assert.True(t, plan.Complete)
+
+	var table bytes.Buffer
+	assert.NoError(t, plan.Print(&table, "table"))
+	assert.True(t, strings.Contains(table.String(), "upgrade"))
+	assert.True(t, strings.Contains(table.String(), "bd-blackduck-webserver-certificate"))
+
+	var out bytes.Buffer
+	assert.NoError(t, plan.Print(&out, "json"))
+	decoded := Plan{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, plan.HelmOperations, decoded.HelmOperations)
+	assert.Equal(t, plan.Secrets, decoded.Secrets)
+
+	assert.Error(t, plan.Print(&out, "yaml"))
+}
+
+func TestPlanDryRun(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+	plan := &Plan{Command: "synopsysctl delete alert", DryRun: DryRunClient}
+	plan.AddResource(PlanActionDelete, "Secret", "alert-custom-certificate", "ns")
+	plan.AddHelmOperation(PlanHelmUninstall, "alert", "ns", "")
+	assert.NoError(t, plan.AddHelmManifests(manifest, "", "ns"))
+	assert.Equal(t, strings.TrimSpace(manifest), plan.HelmOperations[0].Manifest)
+	assert.Empty(t, plan.Resources)
+
+	var out bytes.Buffer
+	assert.NoError(t, plan.PrintDryRun(&out))
+	assert.True(t, strings.Contains(out.String(), "uninstall of release 'alert' in namespace 'ns' (client dry run) deletes"))
+	assert.True(t, strings.Contains(out.String(), "name: config"))
+	assert.True(t, strings.Contains(out.String(), "# delete Secret 'alert-custom-certificate' in namespace 'ns'"))
+
+	assert.Error(t, (&Plan{DryRun: DryRunServer}).AddHelmManifests(manifest, manifest, "ns"))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodFailure is a failure of a pod that won't be resolved by waiting longer
+type PodFailure struct {
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+}
+
+// String returns the diagnosis of the failure
+func (f PodFailure) String() string {
+	object := fmt.Sprintf("pod '%s'", f.Pod)
+	if len(f.Container) > 0 {
+		object = fmt.Sprintf("container '%s' of pod '%s'", f.Container, f.Pod)
+	}
+	if len(f.Message) > 0 {
+		return fmt.Sprintf("%s: %s: %s", object, f.Reason, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", object, f.Reason)
+}
+
+// terminalWaitingReasons are the waiting reasons of containers that can't start without a change of the spec or the cluster
+var terminalWaitingReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
+
+// unresolvableSchedulingMessages are parts of the messages of unschedulable pods that waiting won't resolve
+var unresolvableSchedulingMessages = []string{
+	"Insufficient ",
+	"didn't match node selector",
+	"didn't match Pod's node affinity",
+	"had taint",
+	"had untolerated taint",
+	"volume node affinity conflict",
+	"not found",
+}
+
+// GetPodFailures returns the terminal failures of the pods, i.e. images that can't be pulled, containers in CrashLoopBackOff
+// that restarted at least crashLoopRestarts times and pods that can't be scheduled on any node of the cluster
+func GetPodFailures(pods []corev1.Pod, crashLoopRestarts int32) []PodFailure {
+	failures := []PodFailure{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if failure, ok := getSchedulingFailure(pod); ok {
+			failures = append(failures, failure)
+			continue
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if failure, ok := getContainerFailure(pod.Name, status, crashLoopRestarts); ok {
+				failures = append(failures, failure)
+			}
+		}
+	}
+	return failures
+}
+
+// getSchedulingFailure returns the failure of a pending pod if the scheduler can't place it for a reason waiting won't resolve
+func getSchedulingFailure(pod corev1.Pod) (PodFailure, bool) {
+	if pod.Status.Phase != corev1.PodPending {
+		return PodFailure{}, false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse || condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		for _, message := range unresolvableSchedulingMessages {
+			if strings.Contains(condition.Message, message) {
+				return PodFailure{Pod: pod.Name, Reason: "FailedScheduling", Message: condition.Message}, true
+			}
+		}
+	}
+	return PodFailure{}, false
+}
+
+// getContainerFailure returns the failure of a container that is waiting for a terminal reason
+func getContainerFailure(pod string, status corev1.ContainerStatus, crashLoopRestarts int32) (PodFailure, bool) {
+	waiting := status.State.Waiting
+	if waiting == nil {
+		return PodFailure{}, false
+	}
+	failure := PodFailure{Pod: pod, Container: status.Name, Reason: waiting.Reason, Message: waiting.Message}
+	if terminalWaitingReasons[waiting.Reason] {
+		return failure, true
+	}
+	if waiting.Reason != "CrashLoopBackOff" || crashLoopRestarts <= 0 || status.RestartCount < crashLoopRestarts {
+		return PodFailure{}, false
+	}
+	failure.Message = fmt.Sprintf("restarted %d times", status.RestartCount)
+	if terminated := status.LastTerminationState.Terminated; terminated != nil {
+		failure.Message = fmt.Sprintf("%s, last exit code %d (%s)", failure.Message, terminated.ExitCode, terminated.Reason)
+		if len(terminated.Message) > 0 {
+			failure.Message = fmt.Sprintf("%s: %s", failure.Message, strings.TrimSpace(terminated.Message))
+		}
+	}
+	return failure, true
+}
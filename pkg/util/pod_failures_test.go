@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestWaitingPod(name string, reason string, restarts int32) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "webserver",
+				RestartCount: restarts,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+			}},
+		},
+	}
+}
+
+func newTestUnschedulablePod(name string, message string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: message,
+			}},
+		},
+	}
+}
+
+func TestGetPodFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	failures := GetPodFailures([]corev1.Pod{newTestWaitingPod("bd-webserver-1", "ImagePullBackOff", 0)}, 5)
+	assert.Len(failures, 1)
+	assert.Equal("container 'webserver' of pod 'bd-webserver-1': ImagePullBackOff", failures[0].String())
+
+	assert.Empty(GetPodFailures([]corev1.Pod{newTestWaitingPod("bd-webserver-1", "ContainerCreating", 0)}, 5))
+	assert.Empty(GetPodFailures([]corev1.Pod{newTestWaitingPod("bd-webserver-1", "CrashLoopBackOff", 4)}, 5))
+	assert.Empty(GetPodFailures([]corev1.Pod{newTestWaitingPod("bd-webserver-1", "CrashLoopBackOff", 10)}, 0))
+
+	crashing := newTestWaitingPod("bd-webserver-1", "CrashLoopBackOff", 5)
+	crashing.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}
+	failures = GetPodFailures([]corev1.Pod{crashing}, 5)
+	assert.Len(failures, 1)
+	assert.Equal("restarted 5 times, last exit code 1 (Error)", failures[0].Message)
+
+	deleted := newTestWaitingPod("bd-webserver-1", "ImagePullBackOff", 0)
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Empty(GetPodFailures([]corev1.Pod{deleted}, 5))
+}
+
+func TestGetPodFailuresScheduling(t *testing.T) {
+	assert := assert.New(t)
+
+	failures := GetPodFailures([]corev1.Pod{newTestUnschedulablePod("bd-postgres-1", "0/3 nodes are available: 3 Insufficient memory.")}, 5)
+	assert.Len(failures, 1)
+	assert.Equal("FailedScheduling", failures[0].Reason)
+	assert.Equal("", failures[0].Container)
+
+	failures = GetPodFailures([]corev1.Pod{newTestUnschedulablePod("bd-postgres-1", "persistentvolumeclaim \"bd-postgres\" not found")}, 5)
+	assert.Len(failures, 1)
+
+	assert.Empty(GetPodFailures([]corev1.Pod{newTestUnschedulablePod("bd-postgres-1", "pod has unbound immediate PersistentVolumeClaims")}, 5))
+}
@@ -0,0 +1,305 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Statuses of the preflight checks, ordered by severity
+const (
+	PreflightPass = "pass"
+	PreflightWarn = "warn"
+	PreflightFail = "fail"
+)
+
+// Names of the preflight checks
+const (
+	PreflightCheckKubeVersion     = "kubernetes-version"
+	PreflightCheckCapacity        = "capacity"
+	PreflightCheckStorageClass    = "storage-class"
+	PreflightCheckPermissions     = "permissions"
+	PreflightCheckOpenShiftSCC    = "openshift-scc"
+	PreflightCheckChartRepository = "chart-repository"
+	PreflightCheckImageRegistry   = "image-registry"
+	PreflightCheckNameCollision   = "name-collision"
+)
+
+// PreflightMinimumKubeVersion is the oldest Kubernetes version that the client libraries of synopsysctl are tested with,
+// older clusters get a warning unless the chart declares its own supported versions
+var PreflightMinimumKubeVersion = "1.16"
+
+// PreflightCapacityWarningRatio is the share of the available resources above which the requests of an instance get a
+// warning, since the nodes are rarely packed completely
+var PreflightCapacityWarningRatio = 0.8
+
+// PreflightReachabilityTimeout is the timeout of the requests to the chart repository and the image registry
+var PreflightReachabilityTimeout = 15 * time.Second
+
+// preflightSeverities orders the statuses of the preflight checks
+var preflightSeverities = map[string]int{PreflightPass: 0, PreflightWarn: 1, PreflightFail: 2}
+
+// PreflightResult is the result of a preflight check
+type PreflightResult struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewPreflightResult returns the result of a check with a formatted message
+func NewPreflightResult(check string, status string, format string, args ...interface{}) PreflightResult {
+	return PreflightResult{Check: check, Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// PreflightReport is the result of the preflight checks of a product in a namespace
+type PreflightReport struct {
+	App       string            `json:"app"`
+	Namespace string            `json:"namespace"`
+	Status    string            `json:"status"`
+	Results   []PreflightResult `json:"results"`
+}
+
+// NewPreflightReport returns a report without results, which passes
+func NewPreflightReport(app string, namespace string) *PreflightReport {
+	return &PreflightReport{App: app, Namespace: namespace, Status: PreflightPass, Results: []PreflightResult{}}
+}
+
+// Add adds the result of a check, the status of the report is the most severe status of its results
+func (r *PreflightReport) Add(result PreflightResult) {
+	r.Results = append(r.Results, result)
+	if preflightSeverities[result.Status] > preflightSeverities[r.Status] {
+		r.Status = result.Status
+	}
+}
+
+// Count returns the number of results with the status
+func (r *PreflightReport) Count(status string) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckPreflightKubeVersion checks the version of the cluster, e.g. v1.17.3, against the kubeVersion constraint of the
+// chart, e.g. ">=1.16.0-0", or PreflightMinimumKubeVersion if the chart has no constraint
+func CheckPreflightKubeVersion(serverVersion string, chartConstraint string) PreflightResult {
+	if len(serverVersion) == 0 {
+		return NewPreflightResult(PreflightCheckKubeVersion, PreflightWarn, "unable to get the version of the cluster")
+	}
+	if len(chartConstraint) > 0 {
+		if !chartutil.IsCompatibleRange(chartConstraint, serverVersion) {
+			return NewPreflightResult(PreflightCheckKubeVersion, PreflightFail, "Kubernetes %s is not supported by the chart, which requires %s", serverVersion, chartConstraint)
+		}
+		return NewPreflightResult(PreflightCheckKubeVersion, PreflightPass, "Kubernetes %s is supported by the chart, which requires %s", serverVersion, chartConstraint)
+	}
+	minor, err := parseKubeMinorVersion(serverVersion)
+	if err != nil {
+		return NewPreflightResult(PreflightCheckKubeVersion, PreflightWarn, "%+v", err)
+	}
+	minimum, _ := parseKubeMinorVersion(PreflightMinimumKubeVersion)
+	if minor < minimum {
+		return NewPreflightResult(PreflightCheckKubeVersion, PreflightWarn, "Kubernetes %s is older than %s, the oldest version synopsysctl is tested with", serverVersion, PreflightMinimumKubeVersion)
+	}
+	return NewPreflightResult(PreflightCheckKubeVersion, PreflightPass, "Kubernetes %s", serverVersion)
+}
+
+// GetManifestResourceRequests returns the sum of the CPU and memory requests of the replicas of the workloads in the manifest
+func GetManifestResourceRequests(manifest string) (corev1.ResourceList, error) {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+	for _, object := range objects {
+		podSpec := getWorkloadPodSpec(object)
+		if podSpec == nil {
+			continue
+		}
+		replicas := int64(1)
+		if spec, ok := object["spec"].(map[string]interface{}); ok {
+			switch value := spec["replicas"].(type) {
+			case int64:
+				replicas = value
+			case float64:
+				replicas = int64(value)
+			}
+		}
+		containers, _ := podSpec["containers"].([]interface{})
+		for _, container := range containers {
+			containerMap, _ := container.(map[string]interface{})
+			resources, _ := containerMap["resources"].(map[string]interface{})
+			requests, _ := resources["requests"].(map[string]interface{})
+			for name, total := range map[string]*resource.Quantity{"cpu": &cpu, "memory": &memory} {
+				value, ok := requests[name]
+				if !ok {
+					continue
+				}
+				quantity, err := resource.ParseQuantity(fmt.Sprintf("%v", value))
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s request '%v' due to %+v", name, value, err)
+				}
+				for i := int64(0); i < replicas; i++ {
+					total.Add(quantity)
+				}
+			}
+		}
+	}
+	return corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}, nil
+}
+
+// GetAvailableResources returns the allocatable CPU and memory of the ready and schedulable nodes minus the requests of
+// the pods that run on them
+func GetAvailableResources(nodes []corev1.Node, pods []corev1.Pod) corev1.ResourceList {
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+	schedulable := map[string]bool{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready = true
+			}
+		}
+		if !ready {
+			continue
+		}
+		schedulable[node.Name] = true
+		cpu.Add(*node.Status.Allocatable.Cpu())
+		memory.Add(*node.Status.Allocatable.Memory())
+	}
+	for _, pod := range pods {
+		if !schedulable[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			cpu.Sub(*container.Resources.Requests.Cpu())
+			memory.Sub(*container.Resources.Requests.Memory())
+		}
+	}
+	return corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}
+}
+
+// CheckPreflightCapacity compares the CPU and memory requests of an instance with the available resources of the cluster
+func CheckPreflightCapacity(requested corev1.ResourceList, available corev1.ResourceList) PreflightResult {
+	status := PreflightPass
+	messages := []string{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		request := requested[name]
+		free := available[name]
+		message := fmt.Sprintf("%s requests %s of %s available", name, request.String(), free.String())
+		switch {
+		case request.Cmp(free) > 0:
+			status = PreflightFail
+			message += " (insufficient)"
+		case float64(request.MilliValue()) > PreflightCapacityWarningRatio*float64(free.MilliValue()):
+			if status == PreflightPass {
+				status = PreflightWarn
+			}
+			message += fmt.Sprintf(" (more than %.0f%%)", PreflightCapacityWarningRatio*100)
+		}
+		messages = append(messages, message)
+	}
+	return NewPreflightResult(PreflightCheckCapacity, status, "%s", strings.Join(messages, ", "))
+}
+
+// CheckPreflightStorageClass checks that the storage class of the instance exists, or that the cluster has a default
+// storage class if the instance doesn't set one
+func CheckPreflightStorageClass(storageClass string, defaultStorageClass string, storageClasses []string) PreflightResult {
+	if len(storageClass) > 0 {
+		for _, name := range storageClasses {
+			if name == storageClass {
+				return NewPreflightResult(PreflightCheckStorageClass, PreflightPass, "storage class '%s' exists", storageClass)
+			}
+		}
+		sort.Strings(storageClasses)
+		return NewPreflightResult(PreflightCheckStorageClass, PreflightFail, "storage class '%s' doesn't exist, the storage classes are [%s]", storageClass, strings.Join(storageClasses, "|"))
+	}
+	if len(defaultStorageClass) == 0 {
+		return NewPreflightResult(PreflightCheckStorageClass, PreflightFail, "the cluster has no default storage class, set --pvc-storage-class or mark a storage class as default")
+	}
+	return NewPreflightResult(PreflightCheckStorageClass, PreflightPass, "the default storage class is '%s'", defaultStorageClass)
+}
+
+// PreflightPermission is a permission that the user needs to create an instance
+type PreflightPermission struct {
+	Verb     string
+	Group    string
+	Resource string
+}
+
+// String returns the permission like 'create apps/deployments'
+func (p PreflightPermission) String() string {
+	if len(p.Group) == 0 {
+		return fmt.Sprintf("%s %s", p.Verb, p.Resource)
+	}
+	return fmt.Sprintf("%s %s/%s", p.Verb, p.Group, p.Resource)
+}
+
+// CheckPreflightPermissions returns the result of the permission check from the permissions that were denied
+func CheckPreflightPermissions(namespace string, checked int, denied []PreflightPermission) PreflightResult {
+	if len(denied) == 0 {
+		return NewPreflightResult(PreflightCheckPermissions, PreflightPass, "the user has the %d permissions needed in namespace '%s'", checked, namespace)
+	}
+	names := []string{}
+	for _, permission := range denied {
+		names = append(names, permission.String())
+	}
+	return NewPreflightResult(PreflightCheckPermissions, PreflightFail, "the user is not allowed to %s in namespace '%s'", strings.Join(names, ", "), namespace)
+}
+
+// CheckURLReachable returns an error if the URL doesn't respond. Any HTTP status is a response, e.g. a registry answers
+// 401 without credentials
+func CheckURLReachable(url string) error {
+	resp, err := (&http.Client{Timeout: PreflightReachabilityTimeout}).Get(url)
+	if err != nil {
+		return fmt.Errorf("unable to reach '%s' due to %+v", url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("'%s' returned '%s'", url, resp.Status)
+	}
+	return nil
+}
+
+// GetRegistryAPIURL returns the URL of the version check of the registry API of a registry, e.g. docker.io/blackducksoftware
+func GetRegistryAPIURL(registry string) (string, error) {
+	ref, err := parseImageReference(strings.TrimSuffix(registry, "/") + "/preflight")
+	if err != nil {
+		return "", fmt.Errorf("invalid registry '%s'", registry)
+	}
+	return fmt.Sprintf("https://%s/v2/", ref.apiHost), nil
+}
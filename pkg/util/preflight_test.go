@@ -0,0 +1,204 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreflightReport(t *testing.T) {
+	assert := assert.New(t)
+	report := NewPreflightReport("blackduck", "ns")
+	assert.Equal(PreflightPass, report.Status)
+
+	report.Add(NewPreflightResult(PreflightCheckKubeVersion, PreflightWarn, "old"))
+	report.Add(NewPreflightResult(PreflightCheckStorageClass, PreflightFail, "missing"))
+	report.Add(NewPreflightResult(PreflightCheckCapacity, PreflightPass, "ok"))
+	assert.Equal(PreflightFail, report.Status)
+	assert.Equal(1, report.Count(PreflightWarn))
+	assert.Equal(1, report.Count(PreflightPass))
+	assert.Len(report.Results, 3)
+}
+
+func TestCheckPreflightKubeVersion(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(PreflightPass, CheckPreflightKubeVersion("v1.17.3", "").Status)
+	assert.Equal(PreflightPass, CheckPreflightKubeVersion("v1.18.9-eks-4c6976", "").Status)
+	assert.Equal(PreflightWarn, CheckPreflightKubeVersion("v1.11.0", "").Status)
+	assert.Equal(PreflightWarn, CheckPreflightKubeVersion("", "").Status)
+	assert.Equal(PreflightWarn, CheckPreflightKubeVersion("v2.0.0", "").Status)
+	assert.Equal(PreflightPass, CheckPreflightKubeVersion("v1.17.3", ">=1.13.0-0").Status)
+	assert.Equal(PreflightFail, CheckPreflightKubeVersion("v1.12.3", ">=1.13.0-0").Status)
+}
+
+func TestGetManifestResourceRequests(t *testing.T) {
+	assert := assert.New(t)
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webserver
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: webserver
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+      - name: sidecar
+        resources:
+          requests:
+            cpu: 100m
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: postgres
+spec:
+  template:
+    spec:
+      containers:
+      - name: postgres
+        resources:
+          requests:
+            cpu: 1
+            memory: 2Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webserver
+`
+	requests, err := GetManifestResourceRequests(manifest)
+	assert.NoError(err)
+	cpu := requests[corev1.ResourceCPU]
+	memory := requests[corev1.ResourceMemory]
+	assert.Equal(int64(2200), cpu.MilliValue())
+	assert.Equal(int64(4*1024*1024*1024), memory.Value())
+
+	_, err = GetManifestResourceRequests("kind: Deployment\nspec:\n  template:\n    spec:\n      containers:\n      - resources:\n          requests:\n            cpu: lots\n")
+	assert.Error(err)
+}
+
+func TestGetAvailableResources(t *testing.T) {
+	assert := assert.New(t)
+	readyNode := func(name string, cpu string, memory string, unschedulable bool) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	pod := func(node string, cpu string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	notReady := readyNode("not-ready", "8", "32Gi", false)
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	available := GetAvailableResources(
+		[]corev1.Node{readyNode("a", "4", "16Gi", false), readyNode("b", "4", "16Gi", false), readyNode("cordoned", "8", "32Gi", true), notReady},
+		[]corev1.Pod{pod("a", "1", corev1.PodRunning), pod("b", "500m", corev1.PodRunning), pod("b", "2", corev1.PodSucceeded), pod("cordoned", "1", corev1.PodRunning)},
+	)
+	cpu := available[corev1.ResourceCPU]
+	memory := available[corev1.ResourceMemory]
+	assert.Equal(int64(6500), cpu.MilliValue())
+	assert.Equal(int64(32*1024*1024*1024), memory.Value())
+}
+
+func TestCheckPreflightCapacity(t *testing.T) {
+	assert := assert.New(t)
+	resources := func(cpu string, memory string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+	}
+	assert.Equal(PreflightPass, CheckPreflightCapacity(resources("4", "8Gi"), resources("16", "64Gi")).Status)
+	assert.Equal(PreflightWarn, CheckPreflightCapacity(resources("15", "8Gi"), resources("16", "64Gi")).Status)
+	assert.Equal(PreflightFail, CheckPreflightCapacity(resources("4", "80Gi"), resources("16", "64Gi")).Status)
+	assert.Equal(PreflightFail, CheckPreflightCapacity(resources("20", "60Gi"), resources("16", "64Gi")).Status)
+}
+
+func TestCheckPreflightStorageClass(t *testing.T) {
+	assert := assert.New(t)
+	classes := []string{"standard", "fast"}
+	assert.Equal(PreflightPass, CheckPreflightStorageClass("fast", "standard", classes).Status)
+	assert.Equal(PreflightFail, CheckPreflightStorageClass("slow", "standard", classes).Status)
+	assert.Equal(PreflightPass, CheckPreflightStorageClass("", "standard", classes).Status)
+	assert.Equal(PreflightFail, CheckPreflightStorageClass("", "", classes).Status)
+}
+
+func TestCheckPreflightPermissions(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(PreflightPass, CheckPreflightPermissions("ns", 5, nil).Status)
+	result := CheckPreflightPermissions("ns", 5, []PreflightPermission{{Verb: "create", Group: "apps", Resource: "deployments"}, {Verb: "create", Resource: "secrets"}})
+	assert.Equal(PreflightFail, result.Status)
+	assert.Equal("the user is not allowed to create apps/deployments, create secrets in namespace 'ns'", result.Message)
+}
+
+func TestCheckURLReachable(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(CheckURLReachable(server.URL + "/v2/"))
+	assert.Error(CheckURLReachable(server.URL + "/broken"))
+}
+
+func TestGetRegistryAPIURL(t *testing.T) {
+	assert := assert.New(t)
+	for registry, expected := range map[string]string{
+		"docker.io/blackducksoftware":     "https://registry-1.docker.io/v2/",
+		"blackducksoftware":               "https://registry-1.docker.io/v2/",
+		"gcr.io/project/":                 "https://gcr.io/v2/",
+		"registry.example.com:5000/synop": "https://registry.example.com:5000/v2/",
+	} {
+		url, err := GetRegistryAPIURL(registry)
+		assert.NoError(err)
+		assert.Equal(expected, url)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ProductAPIInsecureSkipVerify disables the verification of the certificates of the product APIs. It is only set by
+// the explicit --product-api-insecure-skip-verify flag
+var ProductAPIInsecureSkipVerify = false
+
+// Defaults of the product API clients
+const (
+	ProductAPIDefaultMaxRetries = 5
+	productAPIMaxRetryAfter     = 5 * time.Minute
+	productAPITokenExpiryMargin = time.Minute
+)
+
+// ProductAPIAuth provides the Authorization header of the requests to a product API
+type ProductAPIAuth interface {
+	// Authorization returns the value of the Authorization header, renewing the cached credentials if renew is true
+	Authorization(client *ProductAPIClient, renew bool) (string, error)
+}
+
+// ProductAPIBasicAuth authenticates with a user name and password, e.g. for BDBA
+type ProductAPIBasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authorization implements ProductAPIAuth
+func (a *ProductAPIBasicAuth) Authorization(client *ProductAPIClient, renew bool) (string, error) {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)), nil
+}
+
+// BlackDuckAPITokenAuth authenticates with a Black Duck API token, which is exchanged for a bearer token that is cached
+// until it expires
+type BlackDuckAPITokenAuth struct {
+	APIToken    string
+	mutex       sync.Mutex
+	bearerToken string
+	expiry      time.Time
+}
+
+// Authorization implements ProductAPIAuth
+func (a *BlackDuckAPITokenAuth) Authorization(client *ProductAPIClient, renew bool) (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !renew && len(a.bearerToken) > 0 && time.Now().Add(productAPITokenExpiryMargin).Before(a.expiry) {
+		return "Bearer " + a.bearerToken, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, client.BaseURL+"/api/tokens/authenticate", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "token "+a.APIToken)
+	req.Header.Set("Accept", "application/vnd.blackducksoftware.user-4+json")
+	resp, err := client.send(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate with the API token due to %+v", err)
+	}
+	defer resp.Body.Close()
+	token := struct {
+		BearerToken           string `json:"bearerToken"`
+		ExpiresInMilliseconds int64  `json:"expiresInMilliseconds"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode the bearer token due to %+v", err)
+	}
+	a.bearerToken = token.BearerToken
+	a.expiry = time.Now().Add(time.Duration(token.ExpiresInMilliseconds) * time.Millisecond)
+	return "Bearer " + a.bearerToken, nil
+}
+
+// ProductAPIClient sends the requests to the REST API of a product. It adds the authorization of the requests, renews
+// the credentials when they are rejected and retries the requests that are rate limited
+type ProductAPIClient struct {
+	BaseURL    string
+	Auth       ProductAPIAuth
+	MaxRetries int
+	httpClient *http.Client
+}
+
+// NewProductAPIClient returns a client of the API at the base URL, e.g. https://blackduck.example.com.
+// The certificate of the API is verified unless ProductAPIInsecureSkipVerify is set
+func NewProductAPIClient(baseURL string, auth ProductAPIAuth, timeout time.Duration) *ProductAPIClient {
+	if ProductAPIInsecureSkipVerify {
+		log.Warnf("the certificate of '%s' is not verified", baseURL)
+	}
+	return &ProductAPIClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Auth:       auth,
+		MaxRetries: ProductAPIDefaultMaxRetries,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: ProductAPIInsecureSkipVerify},
+			},
+		},
+	}
+}
+
+// Do sends the request to the path of the API and returns the response if its status is 2xx, the caller closes its body.
+// The body is sent again on retries if it is an io.ReadSeeker, other bodies are not retried
+func (c *ProductAPIClient) Do(method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	renewed := false
+	for attempt := 0; ; attempt++ {
+		if seeker, ok := body.(io.ReadSeeker); ok && attempt > 0 {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequest(method, c.BaseURL+path, body)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		// the length of files is not known by the request
+		if seeker, ok := body.(io.Seeker); ok && req.ContentLength == 0 {
+			if size, err := seeker.Seek(0, io.SeekEnd); err == nil {
+				seeker.Seek(0, io.SeekStart)
+				req.ContentLength = size
+			}
+		}
+		if c.Auth != nil {
+			authorization, err := c.Auth.Authorization(c, renewed)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		retryable := body == nil
+		if _, ok := body.(io.ReadSeeker); ok {
+			retryable = true
+		}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && c.Auth != nil && !renewed && retryable:
+			// the cached credentials expired or were revoked
+			resp.Body.Close()
+			renewed = true
+			continue
+		case (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && attempt < c.MaxRetries && retryable:
+			wait := getRetryAfter(resp.Header.Get("Retry-After"), attempt)
+			resp.Body.Close()
+			log.Debugf("'%s %s' returned '%s', retrying in %s", method, path, resp.Status, wait)
+			time.Sleep(wait)
+			continue
+		}
+		return checkProductAPIResponse(resp)
+	}
+}
+
+// send sends a request without authorization or retries
+func (c *ProductAPIClient) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return checkProductAPIResponse(resp)
+}
+
+// checkProductAPIResponse returns an error with the beginning of the body if the status of the response is not 2xx
+func checkProductAPIResponse(resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("'%s %s' returned '%s': %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+}
+
+// getRetryAfter returns the time to wait from a Retry-After header in seconds or as a date, or an exponential backoff
+func getRetryAfter(retryAfter string, attempt int) time.Duration {
+	wait := time.Duration(1<<uint(attempt)) * time.Second
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		wait = time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+	}
+	if wait > productAPIMaxRetryAfter {
+		wait = productAPIMaxRetryAfter
+	}
+	return wait
+}
+
+// DoJSON sends the request body encoded as JSON, if any, and decodes the JSON response into the response body, if any
+func (c *ProductAPIClient) DoJSON(method, path string, requestBody interface{}, responseBody interface{}) error {
+	var body io.Reader
+	headers := map[string]string{"Accept": "application/json"}
+	if requestBody != nil {
+		data, err := json.Marshal(requestBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		headers["Content-Type"] = "application/json"
+	}
+	resp, err := c.Do(method, path, body, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if responseBody == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(responseBody); err != nil {
+		return fmt.Errorf("failed to decode the response of '%s %s' due to %+v", method, path, err)
+	}
+	return nil
+}
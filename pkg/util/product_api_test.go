@@ -0,0 +1,134 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProductAPIClientTokenAuth(t *testing.T) {
+	authentications := 0
+	rejectNext := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tokens/authenticate":
+			if r.Header.Get("Authorization") != "token api-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			authentications++
+			fmt.Fprintf(w, `{"bearerToken": "bearer-%d", "expiresInMilliseconds": 7200000}`, authentications)
+		case "/api/current-version":
+			if rejectNext || r.Header.Get("Authorization") != fmt.Sprintf("Bearer bearer-%d", authentications) {
+				rejectNext = false
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"version": "2020.6.0"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewProductAPIClient(server.URL, &BlackDuckAPITokenAuth{APIToken: "api-token"}, time.Minute)
+	version := struct {
+		Version string `json:"version"`
+	}{}
+	for i := 0; i < 2; i++ {
+		if err := client.DoJSON(http.MethodGet, "/api/current-version", nil, &version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if version.Version != "2020.6.0" || authentications != 1 {
+		t.Errorf("expected the bearer token to be cached, got %d authentications", authentications)
+	}
+
+	// a revoked token is renewed
+	rejectNext = true
+	if err := client.DoJSON(http.MethodGet, "/api/current-version", nil, &version); err != nil {
+		t.Fatal(err)
+	}
+	if authentications != 2 {
+		t.Errorf("expected the bearer token to be renewed, got %d authentications", authentications)
+	}
+
+	if err := client.DoJSON(http.MethodGet, "/api/unknown", nil, nil); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a not found error, got %+v", err)
+	}
+}
+
+func TestProductAPIClientRetryAfter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := NewProductAPIClient(server.URL, &ProductAPIBasicAuth{Username: "admin", Password: "secret"}, time.Minute)
+	if err := client.DoJSON(http.MethodPost, "/api/upload", map[string]string{"name": "test"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	requests = 0
+	client.MaxRetries = 1
+	if err := client.DoJSON(http.MethodGet, "/api/upload", nil, nil); err == nil {
+		t.Errorf("expected an error once the retries are exhausted")
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter string
+		attempt    int
+		expected   time.Duration
+	}{
+		{retryAfter: "10", attempt: 0, expected: 10 * time.Second},
+		{retryAfter: "", attempt: 2, expected: 4 * time.Second},
+		{retryAfter: "100000", attempt: 0, expected: productAPIMaxRetryAfter},
+		{retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT", attempt: 0, expected: 0},
+	}
+
+	for _, test := range tests {
+		if wait := getRetryAfter(test.retryAfter, test.attempt); wait != test.expected {
+			t.Errorf("expected %s for '%s', got %s", test.expected, test.retryAfter, wait)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Progress formats of --progress
+const (
+	ProgressFormatNone = ""
+	ProgressFormatJSON = "json"
+)
+
+// ProgressUnknown is the percent of a progress event whose completion is unknown
+const ProgressUnknown = -1
+
+// Phases of the progress events
+const (
+	ProgressPhaseHelm    = "helm"
+	ProgressPhaseWait    = "wait"
+	ProgressPhaseMigrate = "migrate"
+	ProgressPhaseRestore = "restore"
+)
+
+// ProgressEvent is a machine-readable progress event of a long operation, written as a line of JSON so that GUIs and
+// CI wrappers can render their own progress bars instead of parsing the logs
+type ProgressEvent struct {
+	Time          time.Time `json:"time"`
+	CorrelationID string    `json:"correlationID,omitempty"`
+	Phase         string    `json:"phase"`
+	Step          string    `json:"step,omitempty"`
+	Percent       *int      `json:"percent,omitempty"`
+	Message       string    `json:"message,omitempty"`
+}
+
+// progressEvents is the writer of the progress events, the events are discarded if it's nil
+var progressEvents struct {
+	sync.Mutex
+	encoder *json.Encoder
+}
+
+// ConfigureProgress sets the format of the progress events and the file descriptor they are written to, 2 is the
+// standard error. The standard output is reserved for the results of the commands
+func ConfigureProgress(format string, fd int) error {
+	switch format {
+	case ProgressFormatNone:
+		SetProgressWriter(nil)
+		return nil
+	case ProgressFormatJSON:
+	default:
+		return ValidationError("--progress must be '%s', got '%s'", ProgressFormatJSON, format)
+	}
+	switch fd {
+	case 0, 1:
+		return ValidationError("--progress-fd must not be the standard input or output, got %d", fd)
+	case 2:
+		SetProgressWriter(os.Stderr)
+		return nil
+	}
+	if fd < 0 {
+		return ValidationError("--progress-fd must be a file descriptor, got %d", fd)
+	}
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if _, err := file.Stat(); err != nil {
+		return ValidationError("--progress-fd %d is not an open file descriptor due to %+v", fd, err)
+	}
+	SetProgressWriter(file)
+	return nil
+}
+
+// SetProgressWriter sets the writer of the progress events, nil disables the events
+func SetProgressWriter(w io.Writer) {
+	progressEvents.Lock()
+	defer progressEvents.Unlock()
+	progressEvents.encoder = nil
+	if w != nil {
+		progressEvents.encoder = json.NewEncoder(w)
+	}
+}
+
+// EmitProgress writes a progress event if the events are enabled. The percent is ProgressUnknown if the completion of
+// the step is unknown
+func EmitProgress(phase string, step string, percent int, format string, args ...interface{}) {
+	progressEvents.Lock()
+	defer progressEvents.Unlock()
+	if progressEvents.encoder == nil {
+		return
+	}
+	event := ProgressEvent{Time: time.Now().UTC(), CorrelationID: CorrelationID, Phase: phase, Step: step, Message: fmt.Sprintf(format, args...)}
+	if percent != ProgressUnknown {
+		if percent > 100 {
+			percent = 100
+		}
+		event.Percent = &percent
+	}
+	// a failed write of a progress event must not fail the operation
+	progressEvents.encoder.Encode(event)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitProgress(t *testing.T) {
+	assert := assert.New(t)
+
+	// no events are written by default
+	EmitProgress(ProgressPhaseHelm, PlanHelmInstall, 10, "ignored")
+
+	var out bytes.Buffer
+	SetProgressWriter(&out)
+	defer SetProgressWriter(nil)
+	EmitProgress(ProgressPhaseHelm, PlanHelmInstall, 50, "installing release '%s'", "bd")
+	EmitProgress(ProgressPhaseMigrate, "", ProgressUnknown, "3 migration(s) applied")
+	EmitProgress(ProgressPhaseWait, "pods-running", 120, "")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 3)
+	events := []ProgressEvent{}
+	for _, line := range lines {
+		event := ProgressEvent{}
+		assert.NoError(json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	assert.Equal("helm", events[0].Phase)
+	assert.Equal("install", events[0].Step)
+	assert.Equal(50, *events[0].Percent)
+	assert.Equal("installing release 'bd'", events[0].Message)
+	assert.Nil(events[1].Percent)
+	assert.NotContains(lines[1], "percent")
+	assert.Equal(100, *events[2].Percent)
+}
+
+func TestConfigureProgress(t *testing.T) {
+	assert := assert.New(t)
+	defer SetProgressWriter(nil)
+
+	assert.NoError(ConfigureProgress("", 2))
+	assert.NoError(ConfigureProgress("json", 2))
+	assert.Equal(ExitCodeValidation, ExitCode(ConfigureProgress("text", 2)))
+	assert.Equal(ExitCodeValidation, ExitCode(ConfigureProgress("json", 1)))
+	assert.Equal(ExitCodeValidation, ExitCode(ConfigureProgress("json", 1000)))
+}
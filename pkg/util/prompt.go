@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Prompter asks questions on a terminal and reads the answers line by line
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter returns a Prompter that writes the questions to out and reads the answers from in
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readAnswer reads the next line of the input, it returns an error at the end of the input if the line is empty
+func (p *Prompter) readAnswer() (string, error) {
+	answer, err := p.in.ReadString('\n')
+	if err != nil && len(answer) == 0 {
+		if err == io.EOF {
+			return "", fmt.Errorf("no answer was given")
+		}
+		return "", fmt.Errorf("failed to read the answer due to %+v", err)
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// Ask asks a question until validate accepts the answer, an empty answer is replaced by the default answer
+func (p *Prompter) Ask(question string, defaultAnswer string, validate func(string) error) (string, error) {
+	for {
+		if len(defaultAnswer) > 0 {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, defaultAnswer)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		answer, err := p.readAnswer()
+		if err != nil {
+			return "", err
+		}
+		if len(answer) == 0 {
+			answer = defaultAnswer
+		}
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(p.out, "  %+v\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// Choose asks to pick one of the choices by its number or its value, an empty answer picks the default choice
+func (p *Prompter) Choose(question string, choices []string, defaultChoice string) (string, error) {
+	fmt.Fprintf(p.out, "%s\n", question)
+	for i, choice := range choices {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, choice)
+	}
+	var chosen string
+	_, err := p.Ask("Choice", defaultChoice, func(answer string) error {
+		if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= len(choices) {
+			chosen = choices[index-1]
+			return nil
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(choice, answer) {
+				chosen = choice
+				return nil
+			}
+		}
+		return fmt.Errorf("choose a number between 1 and %d", len(choices))
+	})
+	return chosen, err
+}
+
+// Confirm asks a yes/no question, an empty answer is replaced by the default answer
+func (p *Prompter) Confirm(question string, defaultAnswer bool) (bool, error) {
+	defaultValue := "y/N"
+	if defaultAnswer {
+		defaultValue = "Y/n"
+	}
+	var confirmed bool
+	_, err := p.Ask(question, defaultValue, func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			confirmed = true
+		case "n", "no":
+			confirmed = false
+		case strings.ToLower(defaultValue):
+			confirmed = defaultAnswer
+		default:
+			return fmt.Errorf("answer yes or no")
+		}
+		return nil
+	})
+	return confirmed, err
+}
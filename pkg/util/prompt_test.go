@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrompterAsk(t *testing.T) {
+	assert := assert.New(t)
+
+	out := &bytes.Buffer{}
+	p := NewPrompter(strings.NewReader("\n"), out)
+	answer, err := p.Ask("Namespace", "blackduck", nil)
+	assert.Nil(err)
+	assert.Equal("blackduck", answer)
+	assert.Equal("Namespace [blackduck]: ", out.String())
+
+	// invalid answers are asked again
+	out.Reset()
+	p = NewPrompter(strings.NewReader("Bad_Name\nbd\n"), out)
+	answer, err = p.Ask("Namespace", "", func(answer string) error {
+		if strings.ToLower(answer) != answer {
+			return fmt.Errorf("must be lower case")
+		}
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal("bd", answer)
+	assert.Contains(out.String(), "must be lower case")
+
+	// the end of the input without an answer is an error
+	p = NewPrompter(strings.NewReader(""), out)
+	_, err = p.Ask("Namespace", "", nil)
+	assert.NotNil(err)
+}
+
+func TestPrompterChoose(t *testing.T) {
+	assert := assert.New(t)
+
+	choices := []string{"small", "medium", "large"}
+	for input, expected := range map[string]string{"2\n": "medium", "LARGE\n": "large", "\n": "small", "4\nmedium\n": "medium"} {
+		p := NewPrompter(strings.NewReader(input), &bytes.Buffer{})
+		chosen, err := p.Choose("Size", choices, "small")
+		assert.Nil(err, input)
+		assert.Equal(expected, chosen, input)
+	}
+}
+
+func TestPrompterConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	for input, expected := range map[string]bool{"y\n": true, "No\n": false, "\n": true, "maybe\nn\n": false} {
+		p := NewPrompter(strings.NewReader(input), &bytes.Buffer{})
+		confirmed, err := p.Confirm("Use SSL", true)
+		assert.Nil(err, input)
+		assert.Equal(expected, confirmed, input)
+	}
+}
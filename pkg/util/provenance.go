@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations of the provenance of the rendered objects, set with --stamp-metadata. The correlation ID uses the
+// annotation of the Kubernetes Events
+const (
+	ProvenanceToolVersionAnnotation  = "synopsys.com/synopsysctl-version"
+	ProvenanceChartVersionAnnotation = "synopsys.com/chart-version"
+	ProvenanceValuesDigestAnnotation = "synopsys.com/values-digest"
+)
+
+// provenanceAnnotations are the annotations set by the provenance post-renderer
+var provenanceAnnotations = []string{ProvenanceToolVersionAnnotation, ProvenanceChartVersionAnnotation, ProvenanceValuesDigestAnnotation, CorrelationIDAnnotation}
+
+// StampMetadata annotates the objects of the installs and upgrades with their provenance if it is set by --stamp-metadata
+var StampMetadata = false
+
+// SynopsysctlVersion is the version of synopsysctl in the provenance annotations
+var SynopsysctlVersion = ""
+
+// GetProvenanceAnnotations returns the provenance annotations of the objects rendered from the chart with the values,
+// the empty annotations are omitted
+func GetProvenanceAnnotations(chart *chart.Chart, helmValues map[string]interface{}) (map[string]string, error) {
+	valuesDigest, err := HashObject(helmValues)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute the digest of the values due to %+v", err)
+	}
+	chartVersion := ""
+	if chart != nil && chart.Metadata != nil {
+		chartVersion = chart.Metadata.Version
+	}
+	annotations := map[string]string{}
+	for key, value := range map[string]string{
+		ProvenanceToolVersionAnnotation:  SynopsysctlVersion,
+		ProvenanceChartVersionAnnotation: chartVersion,
+		ProvenanceValuesDigestAnnotation: valuesDigest,
+		CorrelationIDAnnotation:          CorrelationID,
+	} {
+		if len(value) > 0 {
+			annotations[key] = value
+		}
+	}
+	return annotations, nil
+}
+
+// RemoveProvenanceAnnotations removes the provenance annotations from an object, e.g. before it is compared with the
+// object rendered by another invocation
+func RemoveProvenanceAnnotations(object map[string]interface{}) {
+	u := &unstructured.Unstructured{Object: object}
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		return
+	}
+	for _, key := range provenanceAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(object, "metadata", "annotations")
+		return
+	}
+	u.SetAnnotations(annotations)
+}
+
+// getReleasePostRenderer returns the post-renderer of an install or upgrade of the chart, which stamps the provenance
+// of the objects if --stamp-metadata is set
+func getReleasePostRenderer(helmValues map[string]interface{}, chart *chart.Chart) (postrender.PostRenderer, error) {
+	renderer, err := getHelmPostRenderer(helmValues)
+	if err != nil || !StampMetadata {
+		return renderer, err
+	}
+	annotations, err := GetProvenanceAnnotations(chart, helmValues)
+	if err != nil {
+		return nil, err
+	}
+	stamp := &provenancePostRenderer{annotations: annotations}
+	if renderer == nil {
+		return stamp, nil
+	}
+	return helmPostRenderers{renderer, stamp}, nil
+}
+
+// provenancePostRenderer annotates the objects with their provenance. The pod templates are not annotated, since the
+// correlation ID would restart the pods on every upgrade
+type provenancePostRenderer struct {
+	annotations map[string]string
+}
+
+// Run implements postrender.PostRenderer
+func (r *provenancePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	objects, err := SplitManifests(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+	}
+	output := &bytes.Buffer{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		annotations := u.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for key, value := range r.annotations {
+			annotations[key] = value
+		}
+		u.SetAnnotations(annotations)
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(output, "---\n%s\n", strings.TrimRight(string(data), "\n"))
+	}
+	return output, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestGetProvenanceAnnotations(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { SynopsysctlVersion, CorrelationID = "", "" }()
+
+	testChart := &chart.Chart{Metadata: &chart.Metadata{Name: "blackduck", Version: "2020.6.0"}}
+	annotations, err := GetProvenanceAnnotations(testChart, map[string]interface{}{"size": "small"})
+	assert.NoError(err)
+	assert.Equal("2020.6.0", annotations[ProvenanceChartVersionAnnotation])
+	assert.Len(annotations[ProvenanceValuesDigestAnnotation], 64)
+	assert.NotContains(annotations, ProvenanceToolVersionAnnotation)
+	assert.NotContains(annotations, CorrelationIDAnnotation)
+
+	SynopsysctlVersion, CorrelationID = "v1.1.0", "abc"
+	other, err := GetProvenanceAnnotations(testChart, map[string]interface{}{"size": "medium"})
+	assert.NoError(err)
+	assert.Equal("v1.1.0", other[ProvenanceToolVersionAnnotation])
+	assert.Equal("abc", other[CorrelationIDAnnotation])
+	assert.NotEqual(annotations[ProvenanceValuesDigestAnnotation], other[ProvenanceValuesDigestAnnotation])
+}
+
+func TestProvenancePostRenderer(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    owner: team
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+	renderer := &provenancePostRenderer{annotations: map[string]string{ProvenanceChartVersionAnnotation: "2020.6.0", CorrelationIDAnnotation: "abc"}}
+	output, err := renderer.Run(bytes.NewBufferString(manifest))
+	assert.NoError(err)
+	objects, err := SplitManifests(output.String())
+	assert.NoError(err)
+	assert.Len(objects, 2)
+	assert.Equal(map[string]interface{}{"owner": "team", ProvenanceChartVersionAnnotation: "2020.6.0", CorrelationIDAnnotation: "abc"}, objects[0]["metadata"].(map[string]interface{})["annotations"])
+	assert.Equal(map[string]interface{}{ProvenanceChartVersionAnnotation: "2020.6.0", CorrelationIDAnnotation: "abc"}, objects[1]["metadata"].(map[string]interface{})["annotations"])
+	// the pod template isn't stamped so that the pods aren't restarted by every upgrade
+	assert.NotContains(GetHelmValueFromMap(objects[0], []string{"spec", "template", "metadata"}), "annotations")
+
+	RemoveProvenanceAnnotations(objects[0])
+	assert.Equal(map[string]interface{}{"owner": "team"}, objects[0]["metadata"].(map[string]interface{})["annotations"])
+	RemoveProvenanceAnnotations(objects[1])
+	assert.NotContains(objects[1]["metadata"], "annotations")
+}
+
+func TestGetReleasePostRenderer(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { StampMetadata = false }()
+
+	testChart := &chart.Chart{Metadata: &chart.Metadata{Name: "blackduck", Version: "2020.6.0"}}
+	renderer, err := getReleasePostRenderer(map[string]interface{}{}, testChart)
+	assert.NoError(err)
+	assert.Nil(renderer)
+
+	StampMetadata = true
+	renderer, err = getReleasePostRenderer(map[string]interface{}{}, testChart)
+	assert.NoError(err)
+	assert.IsType(&provenancePostRenderer{}, renderer)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// originalReclaimPolicyAnnotation keeps the reclaim policy of a persistent volume while it is rebound to another claim
+const originalReclaimPolicyAnnotation = "synopsys.com/original-reclaim-policy"
+
+// RebindPersistentVolumeClaim moves the persistent volume bound to the claim oldName to a new claim newName in the same
+// namespace without losing the data. The volume is retained while the old claim is deleted and its reclaim policy
+// is restored once the new claim is bound. A rebind that was interrupted is resumed from the state of the volume
+func RebindPersistentVolumeClaim(clientset *kubernetes.Clientset, namespace string, oldName string, newName string, timeout time.Duration) error {
+	newPVC, err := GetPVC(clientset, namespace, newName)
+	newPVCExists := err == nil
+	if newPVCExists && newPVC.Status.Phase == corev1.ClaimBound {
+		log.Infof("persistent volume claim '%s' in namespace '%s' is already bound", newName, namespace)
+		return restoreReclaimPolicy(clientset, newPVC.Spec.VolumeName)
+	} else if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", newName, namespace, err)
+	}
+
+	oldPVC, err := GetPVC(clientset, namespace, oldName)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get persistent volume claim '%s' in namespace '%s' due to %+v", oldName, namespace, err)
+	}
+	if err != nil {
+		oldPVC = nil
+	}
+
+	pv, err := findClaimedPersistentVolume(clientset, namespace, oldPVC, oldName, newName)
+	if err != nil {
+		return err
+	}
+
+	// retain the volume so that deleting the old claim doesn't delete the data
+	if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		pv.Annotations = InitLabels(pv.Annotations)
+		pv.Annotations[originalReclaimPolicyAnnotation] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+		pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		if _, err = clientset.CoreV1().PersistentVolumes().Update(pv); err != nil {
+			return fmt.Errorf("unable to retain persistent volume '%s' due to %+v", pv.Name, err)
+		}
+	}
+
+	if oldPVC != nil {
+		log.Infof("deleting persistent volume claim '%s' in namespace '%s'", oldName, namespace)
+		if err := DeletePVC(clientset, namespace, oldName); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete persistent volume claim '%s' in namespace '%s' due to %+v", oldName, namespace, err)
+		}
+		err = wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+			_, err := GetPVC(clientset, namespace, oldName)
+			return k8serrors.IsNotFound(err), nil
+		})
+		if err != nil {
+			return fmt.Errorf("persistent volume claim '%s' in namespace '%s' was not deleted: %w", oldName, namespace, err)
+		}
+	}
+
+	// reserve the volume for the new claim
+	pvName := pv.Name
+	if pv, err = clientset.CoreV1().PersistentVolumes().Get(pvName, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("unable to get persistent volume '%s' due to %+v", pvName, err)
+	}
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Kind: "PersistentVolumeClaim", APIVersion: "v1", Namespace: namespace, Name: newName}
+	if pv, err = clientset.CoreV1().PersistentVolumes().Update(pv); err != nil {
+		return fmt.Errorf("unable to reserve persistent volume '%s' for claim '%s' due to %+v", pvName, newName, err)
+	}
+
+	if !newPVCExists {
+		log.Infof("creating persistent volume claim '%s' in namespace '%s' bound to persistent volume '%s'", newName, namespace, pv.Name)
+		if _, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Create(NewReboundPersistentVolumeClaim(pv, oldPVC, namespace, newName)); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("unable to create persistent volume claim '%s' in namespace '%s' due to %+v", newName, namespace, err)
+		}
+	}
+	err = wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		pvc, err := GetPVC(clientset, namespace, newName)
+		return err == nil && pvc.Status.Phase == corev1.ClaimBound, nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistent volume claim '%s' in namespace '%s' was not bound: %w", newName, namespace, err)
+	}
+	return restoreReclaimPolicy(clientset, pv.Name)
+}
+
+// findClaimedPersistentVolume returns the persistent volume of the old claim, or the volume whose claim reference points
+// to the old or the new claim if the old claim was already deleted
+func findClaimedPersistentVolume(clientset *kubernetes.Clientset, namespace string, oldPVC *corev1.PersistentVolumeClaim, oldName string, newName string) (*corev1.PersistentVolume, error) {
+	if oldPVC != nil {
+		if len(oldPVC.Spec.VolumeName) == 0 {
+			return nil, fmt.Errorf("persistent volume claim '%s' in namespace '%s' is not bound to a persistent volume", oldName, namespace)
+		}
+		pv, err := clientset.CoreV1().PersistentVolumes().Get(oldPVC.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get persistent volume '%s' due to %+v", oldPVC.Spec.VolumeName, err)
+		}
+		return pv, nil
+	}
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the persistent volumes due to %+v", err)
+	}
+	for i, pv := range pvs.Items {
+		if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == namespace && (pv.Spec.ClaimRef.Name == oldName || pv.Spec.ClaimRef.Name == newName) {
+			return &pvs.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("neither persistent volume claim '%s' nor a persistent volume claimed by it exists in namespace '%s'", oldName, namespace)
+}
+
+// NewReboundPersistentVolumeClaim returns a claim with the name bound to the persistent volume. The labels, access modes
+// and storage class are copied from the old claim if it still exists, otherwise from the volume
+func NewReboundPersistentVolumeClaim(pv *corev1.PersistentVolume, oldPVC *corev1.PersistentVolumeClaim, namespace string, name string) *corev1.PersistentVolumeClaim {
+	storageClassName := pv.Spec.StorageClassName
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pv.Spec.AccessModes,
+			StorageClassName: &storageClassName,
+			VolumeName:       pv.Name,
+			VolumeMode:       pv.Spec.VolumeMode,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: pv.Spec.Capacity[corev1.ResourceStorage]},
+			},
+		},
+	}
+	if oldPVC != nil {
+		pvc.Labels = oldPVC.Labels
+		pvc.Spec.AccessModes = oldPVC.Spec.AccessModes
+		pvc.Spec.StorageClassName = oldPVC.Spec.StorageClassName
+	}
+	return pvc
+}
+
+// restoreReclaimPolicy restores the reclaim policy of a persistent volume retained by RebindPersistentVolumeClaim
+func restoreReclaimPolicy(clientset *kubernetes.Clientset, name string) error {
+	pv, err := clientset.CoreV1().PersistentVolumes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get persistent volume '%s' due to %+v", name, err)
+	}
+	policy, ok := pv.Annotations[originalReclaimPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(pv.Annotations, originalReclaimPolicyAnnotation)
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+	if _, err := clientset.CoreV1().PersistentVolumes().Update(pv); err != nil {
+		return fmt.Errorf("unable to restore the reclaim policy of persistent volume '%s' due to %+v", name, err)
+	}
+	return nil
+}
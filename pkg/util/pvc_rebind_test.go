@@ -0,0 +1,71 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewReboundPersistentVolumeClaim(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: "standard",
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+		},
+	}
+
+	pvc := NewReboundPersistentVolumeClaim(pv, nil, "alert", "alert-alert-pvc")
+	if pvc.Name != "alert-alert-pvc" || pvc.Namespace != "alert" {
+		t.Errorf("unexpected claim %s/%s", pvc.Namespace, pvc.Name)
+	}
+	if pvc.Spec.VolumeName != "pvc-1234" {
+		t.Errorf("expected the claim to be bound to volume 'pvc-1234', got '%s'", pvc.Spec.VolumeName)
+	}
+	if *pvc.Spec.StorageClassName != "standard" {
+		t.Errorf("expected storage class 'standard', got '%s'", *pvc.Spec.StorageClassName)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "5Gi" {
+		t.Errorf("expected size 5Gi, got %s", size.String())
+	}
+
+	storageClass := "gp2"
+	oldPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "alert", "name": "alert"}},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: &storageClass,
+		},
+	}
+	pvc = NewReboundPersistentVolumeClaim(pv, oldPVC, "alert", "alert-alert-pvc")
+	if pvc.Labels["app"] != "alert" {
+		t.Errorf("expected the labels of the old claim, got %+v", pvc.Labels)
+	}
+	if *pvc.Spec.StorageClassName != "gp2" || pvc.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("expected the storage class and access modes of the old claim, got %s %+v", *pvc.Spec.StorageClassName, pvc.Spec.AccessModes)
+	}
+}
@@ -22,21 +22,100 @@ under the License.
 package util
 
 import (
+	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// MaxFileDataSize is the maximum number of bytes that are read from a file, stdin, URL or inline data
+var MaxFileDataSize int64 = 10 * 1024 * 1024
+
+// FileDataURLTimeout is the time to wait for a file that is downloaded from a URL
+var FileDataURLTimeout = 30 * time.Second
+
+const (
+	// stdinFilePath reads the file data from stdin
+	stdinFilePath = "-"
+	// base64FilePathPrefix reads the file data inline from the flag value
+	base64FilePathPrefix = "base64:"
+	// httpsFilePathPrefix downloads the file data
+	httpsFilePathPrefix = "https://"
 )
 
+// stdinIsRead is set once a file was read from stdin; stdin can only be read once
+var stdinIsRead = false
+
 // ReadFileData returns the information within a file as a string
 func ReadFileData(filepath string) (string, error) {
-	data, err := ioutil.ReadFile(filepath)
+	data, err := ReadFromFile(filepath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read from file %s: %s", filepath, err)
 	}
 	return string(data), nil
 }
 
-// ReadFromFile will read the file
+// ReadFromFile will read the file. Besides a local path the filePath can be '-' to read from stdin,
+// an https:// URL to download the file, or 'base64:<data>' to pass the data inline
 func ReadFromFile(filePath string) ([]byte, error) {
-	file, err := ioutil.ReadFile(filePath)
-	return file, err
+	switch {
+	case filePath == stdinFilePath:
+		if stdinIsRead {
+			return nil, fmt.Errorf("stdin can only be used for one file")
+		}
+		stdinIsRead = true
+		return readLimited(os.Stdin)
+	case strings.HasPrefix(filePath, base64FilePathPrefix):
+		encoded := strings.TrimSpace(strings.TrimPrefix(filePath, base64FilePathPrefix))
+		if int64(base64.StdEncoding.DecodedLen(len(encoded))) > MaxFileDataSize {
+			return nil, fmt.Errorf("inline data is larger than %d bytes", MaxFileDataSize)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 inline data: %+v", err)
+		}
+		return data, nil
+	case strings.HasPrefix(strings.ToLower(filePath), httpsFilePathPrefix):
+		client := &http.Client{Timeout: FileDataURLTimeout}
+		resp, err := client.Get(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("download failed with status '%s'", resp.Status)
+		}
+		return readLimited(resp.Body)
+	}
+	return ioutil.ReadFile(CleanFilePath(filePath))
+}
+
+// CleanFilePath removes surrounding quotes, expands '~' to the home directory and converts
+// the separators to the ones of the current operating system
+func CleanFilePath(filePath string) string {
+	filePath = strings.TrimSpace(filePath)
+	filePath = strings.Trim(filePath, `"'`)
+	if expanded, err := homedir.Expand(filePath); err == nil {
+		filePath = expanded
+	}
+	return filepath.Clean(filepath.FromSlash(filePath))
+}
+
+// readLimited reads at most MaxFileDataSize bytes from the reader
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxFileDataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxFileDataSize {
+		return nil, fmt.Errorf("data is larger than %d bytes", MaxFileDataSize)
+	}
+	return data, nil
 }
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "synopsysctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	localFile := filepath.Join(dir, "tls.crt")
+	if err := ioutil.WriteFile(localFile, []byte("certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		description string
+		filePath    string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			description: "local file",
+			filePath:    localFile,
+			expected:    "certificate",
+		},
+		{
+			description: "local file with quotes",
+			filePath:    `"` + localFile + `"`,
+			expected:    "certificate",
+		},
+		{
+			description: "inline base64 data",
+			filePath:    "base64:Y2VydGlmaWNhdGU=",
+			expected:    "certificate",
+		},
+		{
+			description: "invalid inline base64 data",
+			filePath:    "base64:not base64",
+			expectedErr: true,
+		},
+		{
+			description: "missing local file",
+			filePath:    filepath.Join(dir, "missing.crt"),
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := ReadFromFile(test.filePath)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("failed test case '%s': expected error %t, got %+v", test.description, test.expectedErr, err)
+		}
+		if string(actual) != test.expected {
+			t.Errorf("failed test case '%s': expected '%s', got '%s'", test.description, test.expected, string(actual))
+		}
+	}
+}
+
+func TestReadFromFileSizeLimit(t *testing.T) {
+	defaultMaxFileDataSize := MaxFileDataSize
+	defer func() { MaxFileDataSize = defaultMaxFileDataSize }()
+	MaxFileDataSize = 4
+
+	if _, err := ReadFromFile("base64:Y2VydGlmaWNhdGU="); err == nil {
+		t.Errorf("expected an error for inline data that is larger than the size limit")
+	}
+}
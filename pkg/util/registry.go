@@ -0,0 +1,378 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Media types of the image manifests
+const (
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	ociImageIndexMediaType      = "application/vnd.oci.image.index.v1+json"
+	ociImageManifestMediaType   = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var manifestMediaTypes = []string{dockerManifestListMediaType, dockerManifestMediaType, ociImageIndexMediaType, ociImageManifestMediaType}
+
+var imageReferenceRegexp = regexp.MustCompile(`^(?:([a-zA-Z0-9.-]+(?::[0-9]+)?)/)?([a-z0-9._/-]+?)(?::([a-zA-Z0-9._-]+))?(?:@(sha256:[a-f0-9]{64}))?$`)
+
+// imageReference is an image parsed into the parts used by the registry API
+type imageReference struct {
+	registry   string
+	apiHost    string
+	repository string
+	// reference is the digest of the image if present, otherwise its tag
+	reference string
+}
+
+// parseImageReference parses an image, defaulting the registry to Docker Hub and the tag to latest
+func parseImageReference(image string) (*imageReference, error) {
+	match := imageReferenceRegexp.FindStringSubmatch(image)
+	if match == nil {
+		return nil, fmt.Errorf("invalid image '%s'", image)
+	}
+	ref := &imageReference{registry: match[1], repository: match[2], reference: match[3]}
+	// the first path element is a registry only if it looks like a host
+	if len(ref.registry) > 0 && !strings.ContainsAny(ref.registry, ".:") && ref.registry != "localhost" {
+		ref.repository = ref.registry + "/" + ref.repository
+		ref.registry = ""
+	}
+	if len(ref.registry) == 0 {
+		ref.registry = "docker.io"
+	}
+	if len(match[4]) > 0 {
+		ref.reference = match[4]
+	} else if len(ref.reference) == 0 {
+		ref.reference = "latest"
+	}
+	ref.apiHost = ref.registry
+	if ref.registry == "docker.io" {
+		ref.apiHost = "registry-1.docker.io"
+		if !strings.Contains(ref.repository, "/") {
+			ref.repository = "library/" + ref.repository
+		}
+	}
+	return ref, nil
+}
+
+// RegistryUsername and RegistryPassword are the credentials of the registries, e.g. of a private mirror. The anonymous
+// pull token is requested if they aren't set
+var RegistryUsername = ""
+var RegistryPassword = ""
+
+// registryClient sends requests to a registry with the pull token of the repository
+type registryClient struct {
+	client   *http.Client
+	token    string
+	username string
+	password string
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{client: &http.Client{Timeout: 10 * time.Minute}, username: RegistryUsername, password: RegistryPassword}
+}
+
+// registryStatusError is returned if the registry responded with an unexpected status
+type registryStatusError struct {
+	url        string
+	status     string
+	statusCode int
+}
+
+func (e *registryStatusError) Error() string {
+	return fmt.Sprintf("registry returned '%s' for '%s'", e.status, e.url)
+}
+
+// do sends the request to the registry, requesting a token if the registry requires one. The caller closes the body
+func (c *registryClient) do(method, url string, accept []string) (*http.Response, error) {
+	resp, err := c.send(method, url, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if c.token, err = c.getToken(resp.Header.Get("Www-Authenticate")); err != nil {
+			return nil, err
+		}
+		if resp, err = c.send(method, url, accept); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &registryStatusError{url: url, status: resp.Status, statusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+func (c *registryClient) send(method, url string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.client.Do(req)
+}
+
+var authenticateParameterRegexp = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+
+// getToken requests a pull token from the token service of the Bearer challenge, with the credentials of the client
+// if they are set
+func (c *registryClient) getToken(challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication '%s'", challenge)
+	}
+	parameters := map[string]string{}
+	for _, match := range authenticateParameterRegexp.FindAllStringSubmatch(challenge, -1) {
+		parameters[match[1]] = match[2]
+	}
+	req, err := http.NewRequest(http.MethodGet, parameters["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	query.Set("service", parameters["service"])
+	query.Set("scope", parameters["scope"])
+	req.URL.RawQuery = query.Encode()
+	if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token service returned '%s'", resp.Status)
+	}
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to decode the token due to %+v", err)
+	}
+	if len(tokenResponse.Token) > 0 {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
+
+// ResolveImageDigest returns the image with the digest of the manifest its tag refers to in the registry,
+// e.g. docker.io/blackducksoftware/blackduck-webapp@sha256:...
+func ResolveImageDigest(image string) (string, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return "", err
+	}
+	resp, err := newRegistryClient().do(http.MethodHead, ref.manifestURL(ref.reference), manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if len(digest) == 0 {
+		return "", fmt.Errorf("registry '%s' did not return the digest of '%s:%s'", ref.registry, ref.repository, ref.reference)
+	}
+	return fmt.Sprintf("%s/%s@%s", ref.registry, ref.repository, digest), nil
+}
+
+// ImageExists returns true if the manifest of the image exists in its registry
+func ImageExists(image string) (bool, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return false, err
+	}
+	resp, err := newRegistryClient().do(http.MethodHead, ref.manifestURL(ref.reference), manifestMediaTypes)
+	if err != nil {
+		var statusErr *registryStatusError
+		if errors.As(err, &statusErr) && statusErr.statusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// RewriteImageRegistry returns the image in the registry and repository prefix, keeping the last path element of its
+// repository and its tag or digest, e.g. registry.example.com/synopsys/blackduck-webapp:2020.6.0 for the prefix
+// registry.example.com/synopsys
+func RewriteImageRegistry(image string, prefix string) string {
+	name := image
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		name = image[i+1:]
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(prefix, "/"), name)
+}
+
+func (r *imageReference) manifestURL(reference string) string {
+	return fmt.Sprintf("https://%s/v2/%s/manifests/%s", r.apiHost, r.repository, reference)
+}
+
+func (r *imageReference) blobURL(digest string) string {
+	return fmt.Sprintf("https://%s/v2/%s/blobs/%s", r.apiHost, r.repository, digest)
+}
+
+// imageManifest is an image manifest or an index of the manifests of each platform
+type imageManifest struct {
+	MediaType string               `json:"mediaType"`
+	Config    imageDescriptor      `json:"config"`
+	Layers    []imageDescriptor    `json:"layers"`
+	Manifests []platformDescriptor `json:"manifests"`
+}
+
+type imageDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type platformDescriptor struct {
+	imageDescriptor
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+// getImageManifest returns the manifest of the image, selecting the linux/amd64 manifest of a multi-platform image
+func (c *registryClient) getImageManifest(ref *imageReference) (*imageManifest, error) {
+	reference := ref.reference
+	for i := 0; i < 2; i++ {
+		resp, err := c.do(http.MethodGet, ref.manifestURL(reference), manifestMediaTypes)
+		if err != nil {
+			return nil, err
+		}
+		manifest := &imageManifest{}
+		err = json.NewDecoder(resp.Body).Decode(manifest)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the manifest due to %+v", err)
+		}
+		if len(manifest.Manifests) == 0 {
+			return manifest, nil
+		}
+		reference = ""
+		for _, platformManifest := range manifest.Manifests {
+			if platformManifest.Platform.OS == "linux" && platformManifest.Platform.Architecture == "amd64" {
+				reference = platformManifest.Digest
+			}
+		}
+		if len(reference) == 0 {
+			return nil, fmt.Errorf("image has no linux/amd64 manifest")
+		}
+	}
+	return nil, fmt.Errorf("image manifest index refers to another index")
+}
+
+// SaveImage pulls the image from its registry and writes it to the writer as a tar archive in the format of 'docker save'
+func SaveImage(image string, w io.Writer) error {
+	return SaveImages([]string{image}, w)
+}
+
+// SaveImages pulls the images from their registries and writes them to the writer as a single tar archive in the
+// format of 'docker save', so that 'docker load' loads all of them. The layers shared by the images are written once
+func SaveImages(images []string, w io.Writer) error {
+	archive := tar.NewWriter(w)
+	written := map[string]bool{}
+	archiveManifest := []map[string]interface{}{}
+	for _, image := range images {
+		ref, err := parseImageReference(image)
+		if err != nil {
+			return err
+		}
+		// the pull token is scoped to the repository of the image
+		client := newRegistryClient()
+		manifest, err := client.getImageManifest(ref)
+		if err != nil {
+			return fmt.Errorf("failed to get the manifest of image '%s' due to %+v", image, err)
+		}
+
+		writeBlob := func(name string, descriptor imageDescriptor) error {
+			if written[name] {
+				return nil
+			}
+			resp, err := client.do(http.MethodGet, ref.blobURL(descriptor.Digest), nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if err := archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: descriptor.Size, Typeflag: tar.TypeReg}); err != nil {
+				return err
+			}
+			if _, err = io.CopyN(archive, resp.Body, descriptor.Size); err != nil {
+				return err
+			}
+			written[name] = true
+			return nil
+		}
+
+		configName := strings.TrimPrefix(manifest.Config.Digest, "sha256:") + ".json"
+		if err := writeBlob(configName, manifest.Config); err != nil {
+			return fmt.Errorf("failed to get the config of image '%s' due to %+v", image, err)
+		}
+		layerNames := []string{}
+		for _, layer := range manifest.Layers {
+			layerName := strings.TrimPrefix(layer.Digest, "sha256:") + "/layer.tar"
+			if err := writeBlob(layerName, layer); err != nil {
+				return fmt.Errorf("failed to get layer '%s' of image '%s' due to %+v", layer.Digest, image, err)
+			}
+			layerNames = append(layerNames, layerName)
+		}
+
+		repoTags := []string{}
+		if !strings.HasPrefix(ref.reference, "sha256:") {
+			repoTags = append(repoTags, fmt.Sprintf("%s/%s:%s", ref.registry, ref.repository, ref.reference))
+		}
+		archiveManifest = append(archiveManifest, map[string]interface{}{"Config": configName, "RepoTags": repoTags, "Layers": layerNames})
+	}
+
+	data, err := json.Marshal(archiveManifest)
+	if err != nil {
+		return err
+	}
+	if err := archive.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	if _, err := archive.Write(data); err != nil {
+		return err
+	}
+	return archive.Close()
+}
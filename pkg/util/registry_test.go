@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected imageReference
+	}{
+		{
+			image:    "docker.io/blackducksoftware/blackduck-webapp:2020.6.0",
+			expected: imageReference{registry: "docker.io", apiHost: "registry-1.docker.io", repository: "blackducksoftware/blackduck-webapp", reference: "2020.6.0"},
+		},
+		{
+			image:    "blackducksoftware/blackduck-webapp",
+			expected: imageReference{registry: "docker.io", apiHost: "registry-1.docker.io", repository: "blackducksoftware/blackduck-webapp", reference: "latest"},
+		},
+		{
+			image:    "alpine:3.12",
+			expected: imageReference{registry: "docker.io", apiHost: "registry-1.docker.io", repository: "library/alpine", reference: "3.12"},
+		},
+		{
+			image:    "localhost:5000/blackduck-webapp:2020.6.0",
+			expected: imageReference{registry: "localhost:5000", apiHost: "localhost:5000", repository: "blackduck-webapp", reference: "2020.6.0"},
+		},
+		{
+			image:    "gcr.io/project/blackduck-webapp@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expected: imageReference{registry: "gcr.io", apiHost: "gcr.io", repository: "project/blackduck-webapp", reference: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		},
+	}
+
+	for _, test := range tests {
+		ref, err := parseImageReference(test.image)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %+v", test.image, err)
+		} else if *ref != test.expected {
+			t.Errorf("expected %+v, got %+v", test.expected, *ref)
+		}
+	}
+}
+
+func TestRewriteImageRegistry(t *testing.T) {
+	tests := []struct {
+		image    string
+		prefix   string
+		expected string
+	}{
+		{image: "docker.io/blackducksoftware/blackduck-webapp:2020.6.0", prefix: "registry.example.com/synopsys", expected: "registry.example.com/synopsys/blackduck-webapp:2020.6.0"},
+		{image: "alpine:3.12", prefix: "registry.example.com:5000/", expected: "registry.example.com:5000/alpine:3.12"},
+		{image: "gcr.io/project/blackduck-webapp@sha256:0123", prefix: "mirror", expected: "mirror/blackduck-webapp@sha256:0123"},
+	}
+
+	for _, test := range tests {
+		if rewritten := RewriteImageRegistry(test.image, test.prefix); rewritten != test.expected {
+			t.Errorf("expected '%s', got '%s'", test.expected, rewritten)
+		}
+	}
+}
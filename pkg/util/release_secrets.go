@@ -0,0 +1,233 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Labels of the snapshots of the secrets that synopsysctl created for a release revision
+const (
+	releaseSecretsReleaseLabel  = "synopsys.com/release-secrets"
+	releaseSecretsRevisionLabel = "synopsys.com/release-revision"
+)
+
+// releaseSecret is a secret stored in a snapshot
+type releaseSecret struct {
+	Type   corev1.SecretType `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Data   map[string][]byte `json:"data"`
+}
+
+// GetSecretNamesFromHelmValues returns the values of all keys ending in SecretName, e.g. javaKeystoreSecretName
+func GetSecretNamesFromHelmValues(values map[string]interface{}) []string {
+	secretNames := []string{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			secretNames = append(secretNames, GetSecretNamesFromHelmValues(v)...)
+		case string:
+			if strings.HasSuffix(key, "SecretName") && len(v) > 0 {
+				secretNames = append(secretNames, v)
+			}
+		}
+	}
+	sort.Strings(secretNames)
+	return secretNames
+}
+
+// releaseSecretsSnapshotName returns the name of the snapshot of the secrets of a release revision
+func releaseSecretsSnapshotName(releaseName string, revision int) string {
+	return fmt.Sprintf("%s-synopsysctl-secrets-v%d", releaseName, revision)
+}
+
+// SaveReleaseSecrets stores a copy of the secrets that synopsysctl created for the revision of the release, i.e. the
+// secrets referenced by the Helm values that Helm doesn't manage, so that a rollback can restore them
+func SaveReleaseSecrets(clientset *kubernetes.Clientset, namespace string, releaseName string, revision int, vals map[string]interface{}) error {
+	secrets := []*corev1.Secret{}
+	for _, secretName := range GetSecretNamesFromHelmValues(vals) {
+		secret, err := GetSecret(clientset, namespace, secretName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("unable to get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		if secret.Labels["app.kubernetes.io/managed-by"] == "Helm" {
+			// the rollback of the release restores the secrets of the chart
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+	snapshot, err := newReleaseSecretsSnapshot(releaseName, revision, secrets)
+	if err != nil {
+		return err
+	}
+	if _, err := clientset.CoreV1().Secrets(namespace).Create(snapshot); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("unable to save the secrets of revision %d of release '%s' due to %+v", revision, releaseName, err)
+		}
+		if _, err := UpdateSecret(clientset, namespace, snapshot); err != nil {
+			return fmt.Errorf("unable to save the secrets of revision %d of release '%s' due to %+v", revision, releaseName, err)
+		}
+	}
+	return nil
+}
+
+// RestoreReleaseSecrets creates or updates the secrets stored for the revision of the release and returns their names.
+// Nothing is restored if the revision has no snapshot, e.g. it was installed by an older synopsysctl
+func RestoreReleaseSecrets(clientset *kubernetes.Clientset, namespace string, releaseName string, revision int) ([]string, error) {
+	snapshot, err := GetSecret(clientset, namespace, releaseSecretsSnapshotName(releaseName, revision))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Debugf("no secrets were saved for revision %d of release '%s'", revision, releaseName)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get the secrets of revision %d of release '%s' due to %+v", revision, releaseName, err)
+	}
+	secrets, err := secretsFromReleaseSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	restored := []string{}
+	for _, secret := range secrets {
+		existing, err := GetSecret(clientset, namespace, secret.Name)
+		switch {
+		case k8serrors.IsNotFound(err):
+			_, err = clientset.CoreV1().Secrets(namespace).Create(secret)
+		case err == nil:
+			existing.Labels = secret.Labels
+			existing.Data = secret.Data
+			_, err = UpdateSecret(clientset, namespace, existing)
+		}
+		if err != nil {
+			return restored, fmt.Errorf("unable to restore secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+		}
+		restored = append(restored, secret.Name)
+	}
+	return restored, nil
+}
+
+// DeleteReleaseSecrets deletes the snapshots of the secrets of all revisions of the release
+func DeleteReleaseSecrets(clientset *kubernetes.Clientset, namespace string, releaseName string) error {
+	snapshots, err := ListSecrets(clientset, namespace, fmt.Sprintf("%s=%s", releaseSecretsReleaseLabel, releaseName))
+	if err != nil {
+		return fmt.Errorf("unable to list the saved secrets of release '%s' due to %+v", releaseName, err)
+	}
+	for _, snapshot := range snapshots.Items {
+		if err := DeleteSecret(clientset, namespace, snapshot.Name); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete secret '%s' in namespace '%s' due to %+v", snapshot.Name, namespace, err)
+		}
+	}
+	return nil
+}
+
+// saveReleaseSecretsForActionConfig saves the secrets of the release revision with a client of the Helm action configuration
+func saveReleaseSecretsForActionConfig(actionConfig *action.Configuration, namespace string, releaseName string, revision int, vals map[string]interface{}) {
+	clientset, err := clientsetForActionConfig(actionConfig)
+	if err != nil {
+		log.Warnf("unable to save the secrets of release '%s' for rollbacks due to %+v", releaseName, err)
+		return
+	}
+	if err := SaveReleaseSecrets(clientset, namespace, releaseName, revision, vals); err != nil {
+		log.Warnf("%+v, a rollback to this revision will not restore them", err)
+	}
+}
+
+// deleteReleaseSecretsForActionConfig deletes the saved secrets of the release with a client of the Helm action configuration
+func deleteReleaseSecretsForActionConfig(actionConfig *action.Configuration, namespace string, releaseName string) {
+	clientset, err := clientsetForActionConfig(actionConfig)
+	if err == nil {
+		err = DeleteReleaseSecrets(clientset, namespace, releaseName)
+	}
+	if err != nil {
+		log.Warnf("unable to delete the saved secrets of release '%s' due to %+v", releaseName, err)
+	}
+}
+
+// clientsetForActionConfig returns a Kubernetes client for the cluster of the Helm action configuration
+func clientsetForActionConfig(actionConfig *action.Configuration) (*kubernetes.Clientset, error) {
+	restConfig, err := actionConfig.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// newReleaseSecretsSnapshot returns a secret that stores the type, labels and data of the secrets by name
+func newReleaseSecretsSnapshot(releaseName string, revision int, secrets []*corev1.Secret) (*corev1.Secret, error) {
+	data := map[string][]byte{}
+	for _, secret := range secrets {
+		value, err := json.Marshal(releaseSecret{Type: secret.Type, Labels: secret.Labels, Data: secret.Data})
+		if err != nil {
+			return nil, fmt.Errorf("unable to store secret '%s' due to %+v", secret.Name, err)
+		}
+		data[secret.Name] = value
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: releaseSecretsSnapshotName(releaseName, revision),
+			Labels: map[string]string{
+				releaseSecretsReleaseLabel:  releaseName,
+				releaseSecretsRevisionLabel: strconv.Itoa(revision),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}, nil
+}
+
+// secretsFromReleaseSnapshot returns the secrets stored in a snapshot sorted by name
+func secretsFromReleaseSnapshot(snapshot *corev1.Secret) ([]*corev1.Secret, error) {
+	names := []string{}
+	for name := range snapshot.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	secrets := []*corev1.Secret{}
+	for _, name := range names {
+		stored := releaseSecret{}
+		if err := json.Unmarshal(snapshot.Data[name], &stored); err != nil {
+			return nil, fmt.Errorf("unable to read secret '%s' from '%s' due to %+v", name, snapshot.Name, err)
+		}
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: snapshot.Namespace, Labels: stored.Labels},
+			Type:       stored.Type,
+			Data:       stored.Data,
+		})
+	}
+	return secrets, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSecretNamesFromHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	values := map[string]interface{}{
+		"javaKeystoreSecretName":                "alert-java-keystore",
+		"webserverCustomCertificatesSecretName": "alert-custom-certificate",
+		"postgres": map[string]interface{}{
+			"passwordSecretName": "",
+			"host":               "db",
+		},
+		"tlsCertSecretName": 5,
+	}
+	assert.Equal([]string{"alert-custom-certificate", "alert-java-keystore"}, GetSecretNamesFromHelmValues(values))
+}
+
+func TestReleaseSecretsSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	secrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "proxy-certificate", Labels: map[string]string{"app": "blackduck"}},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"HUB_PROXY_CERT_FILE": []byte("cert")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-webserver-certificate"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")},
+		},
+	}
+	snapshot, err := newReleaseSecretsSnapshot("bd", 3, secrets)
+	assert.NoError(err)
+	assert.Equal("bd-synopsysctl-secrets-v3", snapshot.Name)
+	assert.Equal(map[string]string{releaseSecretsReleaseLabel: "bd", releaseSecretsRevisionLabel: "3"}, snapshot.Labels)
+	assert.Len(snapshot.Data, 2)
+
+	snapshot.Namespace = "ns"
+	restored, err := secretsFromReleaseSnapshot(snapshot)
+	assert.NoError(err)
+	assert.Len(restored, 2)
+	assert.Equal("bd-blackduck-webserver-certificate", restored[0].Name)
+	assert.Equal("ns", restored[0].Namespace)
+	assert.Equal(corev1.SecretTypeTLS, restored[0].Type)
+	assert.Equal(secrets[1].Data, restored[0].Data)
+	assert.Equal("proxy-certificate", restored[1].Name)
+	assert.Equal(map[string]string{"app": "blackduck"}, restored[1].Labels)
+	assert.Equal(secrets[0].Data, restored[1].Data)
+
+	snapshot.Data["broken"] = []byte("{")
+	_, err = secretsFromReleaseSnapshot(snapshot)
+	assert.Error(err)
+}
+
+func TestGetRollbackRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	history := []*release.Release{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	revision, err := getRollbackRevision(history, 0)
+	assert.NoError(err)
+	assert.Equal(2, revision)
+
+	revision, err = getRollbackRevision(history, 1)
+	assert.NoError(err)
+	assert.Equal(1, revision)
+
+	_, err = getRollbackRevision(history, 3)
+	assert.Error(err)
+	_, err = getRollbackRevision(history, 5)
+	assert.Error(err)
+	_, err = getRollbackRevision(history[:1], 0)
+	assert.Error(err)
+	_, err = getRollbackRevision(nil, 0)
+	assert.Error(err)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"strings"
+)
+
+// RenamedResourceName returns the name of a resource of an instance once the instance is renamed from oldName to
+// newName, and false if the name of the resource isn't derived from the name of the instance
+func RenamedResourceName(resourceName string, oldName string, newName string) (string, bool) {
+	if !strings.HasPrefix(resourceName, oldName+"-") {
+		return resourceName, false
+	}
+	return newName + strings.TrimPrefix(resourceName, oldName), true
+}
+
+// RenamedLabels returns a copy of the labels of an object of an instance with the new name of the instance. The labels
+// of the Helm release are dropped, the object isn't managed by the chart of the new release
+func RenamedLabels(labels map[string]string, newName string) map[string]string {
+	renamed := map[string]string{}
+	for key, value := range labels {
+		if key == "helm.sh/chart" || (key == "app.kubernetes.io/managed-by" && value == "Helm") {
+			continue
+		}
+		renamed[key] = value
+	}
+	renamed["name"] = newName
+	return renamed
+}
+
+// RenameHelmValueReferences replaces the names of secrets and persistent volume claims derived from oldName in the
+// values, i.e. of the keys ending in SecretName or persistentVolumeClaimName, with the names derived from newName
+func RenameHelmValueReferences(values map[string]interface{}, oldName string, newName string) {
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			RenameHelmValueReferences(v, oldName, newName)
+		case string:
+			if strings.HasSuffix(key, "SecretName") || key == "persistentVolumeClaimName" {
+				values[key], _ = RenamedResourceName(v, oldName, newName)
+			}
+		}
+	}
+}
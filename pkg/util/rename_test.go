@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenamedResourceName(t *testing.T) {
+	assert := assert.New(t)
+	name, renamed := RenamedResourceName("bd-blackduck-postgres", "bd", "hub")
+	assert.True(renamed)
+	assert.Equal("hub-blackduck-postgres", name)
+
+	name, renamed = RenamedResourceName("bdx-blackduck-postgres", "bd", "hub")
+	assert.False(renamed)
+	assert.Equal("bdx-blackduck-postgres", name)
+
+	_, renamed = RenamedResourceName("bd", "bd", "hub")
+	assert.False(renamed)
+}
+
+func TestRenamedLabels(t *testing.T) {
+	assert := assert.New(t)
+	labels := map[string]string{"app": "blackduck", "name": "bd", "component": "postgres", "app.kubernetes.io/managed-by": "Helm", "helm.sh/chart": "blackduck-2020.6.0"}
+	assert.Equal(map[string]string{"app": "blackduck", "name": "hub", "component": "postgres"}, RenamedLabels(labels, "hub"))
+	assert.Equal("bd", labels["name"])
+}
+
+func TestRenameHelmValueReferences(t *testing.T) {
+	assert := assert.New(t)
+	values := map[string]interface{}{
+		"certAuthCACertSecretName": "bd-blackduck-auth-custom-ca",
+		"proxyCertSecretName":      "corporate-proxy",
+		"postgres": map[string]interface{}{
+			"persistentVolumeClaimName": "bd-blackduck-postgres",
+			"claimSize":                 "bd-150Gi",
+		},
+	}
+	RenameHelmValueReferences(values, "bd", "hub")
+	assert.Equal("hub-blackduck-auth-custom-ca", values["certAuthCACertSecretName"])
+	assert.Equal("corporate-proxy", values["proxyCertSecretName"])
+	assert.Equal("hub-blackduck-postgres", GetHelmValueFromMap(values, []string{"postgres", "persistentVolumeClaimName"}))
+	assert.Equal("bd-150Gi", GetHelmValueFromMap(values, []string{"postgres", "claimSize"}))
+}
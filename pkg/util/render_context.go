@@ -0,0 +1,80 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RenderLookup sets a Helm value from a key of an existing secret while rendering the manifests of an instance, the
+// equivalent of the Helm 'lookup' function so that a render doesn't generate new credentials for an existing instance
+type RenderLookup struct {
+	SecretName string
+	Key        string
+	ValuePath  []string
+}
+
+// ResolveRenderLookups sets the Helm values of the lookups whose secrets exist in the namespace. Values that are
+// already set, e.g. by a flag, are not overwritten
+func ResolveRenderLookups(clientset *kubernetes.Clientset, namespace string, lookups []RenderLookup, vals map[string]interface{}) error {
+	return resolveRenderLookups(func(name string) (*corev1.Secret, error) {
+		return GetSecret(clientset, namespace, name)
+	}, lookups, vals)
+}
+
+func resolveRenderLookups(getSecret func(name string) (*corev1.Secret, error), lookups []RenderLookup, vals map[string]interface{}) error {
+	secrets := map[string]*corev1.Secret{}
+	for _, lookup := range lookups {
+		if GetHelmValueFromMap(vals, lookup.ValuePath) != nil {
+			continue
+		}
+		secret, ok := secrets[lookup.SecretName]
+		if !ok {
+			var err error
+			secret, err = getSecret(lookup.SecretName)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to look up secret '%s' due to %+v", lookup.SecretName, err)
+			}
+			if err != nil {
+				secret = nil
+			}
+			secrets[lookup.SecretName] = secret
+		}
+		if secret == nil {
+			log.Debugf("secret '%s' doesn't exist, the chart will generate the value of '%s'", lookup.SecretName, lookup.ValuePath)
+			continue
+		}
+		value, ok := secret.Data[lookup.Key]
+		if !ok {
+			log.Debugf("key '%s' is missing in secret '%s'", lookup.Key, lookup.SecretName)
+			continue
+		}
+		log.Debugf("reusing key '%s' of secret '%s' for '%s'", lookup.Key, lookup.SecretName, lookup.ValuePath)
+		SetHelmValueInMap(vals, lookup.ValuePath, string(value))
+	}
+	return nil
+}
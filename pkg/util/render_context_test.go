@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResolveRenderLookups(t *testing.T) {
+	assert := assert.New(t)
+
+	secrets := map[string]*corev1.Secret{
+		"bd-blackduck-db-creds": {Data: map[string][]byte{"HUB_POSTGRES_ADMIN_PASSWORD_FILE": []byte("admin"), "HUB_POSTGRES_USER_PASSWORD_FILE": []byte("user")}},
+	}
+	calls := 0
+	getSecret := func(name string) (*corev1.Secret, error) {
+		calls++
+		if secret, ok := secrets[name]; ok {
+			return secret, nil
+		}
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	lookups := []RenderLookup{
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_ADMIN_PASSWORD_FILE", ValuePath: []string{"postgres", "adminPassword"}},
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_USER_PASSWORD_FILE", ValuePath: []string{"postgres", "userPassword"}},
+		{SecretName: "bd-blackduck-db-creds", Key: "MISSING", ValuePath: []string{"postgres", "missing"}},
+		{SecretName: "other", Key: "key", ValuePath: []string{"other"}},
+	}
+	vals := map[string]interface{}{"postgres": map[string]interface{}{"userPassword": "explicit"}}
+
+	assert.NoError(resolveRenderLookups(getSecret, lookups, vals))
+	assert.Equal(map[string]interface{}{"postgres": map[string]interface{}{"adminPassword": "admin", "userPassword": "explicit"}}, vals)
+	assert.Equal(2, calls)
+
+	failing := func(name string) (*corev1.Secret, error) { return nil, fmt.Errorf("forbidden") }
+	assert.Error(resolveRenderLookups(failing, lookups, map[string]interface{}{}))
+}
@@ -0,0 +1,355 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Bucket is a bucket of an S3 compatible object storage
+type S3Bucket struct {
+	// Endpoint is the URL of the object storage, e.g. https://s3.us-east-1.amazonaws.com
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set with temporary credentials, e.g. of an assumed role
+	SessionToken string
+}
+
+// emptyPayloadHash is the SHA256 hash of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// CheckS3Bucket returns an error if the bucket doesn't exist or can't be accessed with the credentials
+func CheckS3Bucket(bucket S3Bucket) error {
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	// path style requests work with AWS and with S3 compatible storages like Minio
+	endpoint.Path = fmt.Sprintf("/%s", bucket.Bucket)
+	req, err := http.NewRequest(http.MethodHead, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	SignS3Request(req, bucket, time.Now().UTC())
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach the S3 endpoint '%s' due to %+v", bucket.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("S3 bucket '%s' doesn't exist at '%s'", bucket.Bucket, bucket.Endpoint)
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return fmt.Errorf("access to S3 bucket '%s' at '%s' is denied, check the credentials and the region", bucket.Bucket, bucket.Endpoint)
+	}
+	return fmt.Errorf("unexpected status '%s' checking S3 bucket '%s' at '%s'", resp.Status, bucket.Bucket, bucket.Endpoint)
+}
+
+// GetS3BucketFromEnv returns the bucket with the credentials of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN in AWS_REGION or AWS_DEFAULT_REGION, and the endpoint AWS_ENDPOINT_URL for S3 compatible storages
+func GetS3BucketFromEnv(bucketName string) S3Bucket {
+	region := os.Getenv("AWS_REGION")
+	if len(region) == 0 {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if len(region) == 0 {
+		region = "us-east-1"
+	}
+	credentials := GetAWSCredentialsFromEnv()
+	bucket := S3Bucket{
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		Region:          region,
+		Bucket:          bucketName,
+		AccessKeyID:     credentials.AccessKeyID,
+		SecretAccessKey: credentials.SecretAccessKey,
+		SessionToken:    credentials.SessionToken,
+	}
+	if len(bucket.Endpoint) == 0 {
+		bucket.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	return bucket
+}
+
+// s3MaximumPutSize is the largest object that can be uploaded with a single PUT request
+const s3MaximumPutSize = 5 << 30
+
+// PutS3Object uploads the file to the key of the bucket with a single signed PUT request
+func PutS3Object(bucket S3Bucket, key string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > s3MaximumPutSize {
+		return fmt.Errorf("'%s' is larger than the maximum size of a single S3 upload of 5GiB", path)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("unable to read '%s' due to %+v", path, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	endpoint.Path = fmt.Sprintf("/%s/%s", bucket.Bucket, strings.TrimPrefix(key, "/"))
+	req, err := http.NewRequest(http.MethodPut, endpoint.String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	signAWSRequestWithPayloadHash(req, AWSCredentials{AccessKeyID: bucket.AccessKeyID, SecretAccessKey: bucket.SecretAccessKey, SessionToken: bucket.SessionToken}, bucket.Region, "s3", hex.EncodeToString(hash.Sum(nil)), time.Now().UTC())
+	resp, err := (&http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to upload '%s' to S3 bucket '%s' due to %+v", path, bucket.Bucket, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("S3 bucket '%s' doesn't exist at '%s'", bucket.Bucket, bucket.Endpoint)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("upload to S3 bucket '%s' at '%s' is denied, check the credentials and the region", bucket.Bucket, bucket.Endpoint)
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("unexpected status '%s' uploading '%s' to S3 bucket '%s'", resp.Status, key, bucket.Bucket)
+	}
+	return nil
+}
+
+// GetS3Object downloads the object with the key of the bucket into the writer
+func GetS3Object(bucket S3Bucket, key string, w io.Writer) error {
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	endpoint.Path = fmt.Sprintf("/%s/%s", bucket.Bucket, strings.TrimPrefix(key, "/"))
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	if len(bucket.AccessKeyID) > 0 {
+		SignS3Request(req, bucket, time.Now().UTC())
+	}
+	resp, err := (&http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to download '%s' from S3 bucket '%s' due to %+v", key, bucket.Bucket, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("'%s' doesn't exist in S3 bucket '%s'", key, bucket.Bucket)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("access to '%s' in S3 bucket '%s' is denied, check the credentials and the region", key, bucket.Bucket)
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("unexpected status '%s' downloading '%s' from S3 bucket '%s'", resp.Status, key, bucket.Bucket)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("unable to download '%s' from S3 bucket '%s' due to %+v", key, bucket.Bucket, err)
+	}
+	return nil
+}
+
+// s3ListBucketResult is the response of the ListObjectsV2 request of a bucket
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	CommonPrefixes []struct {
+		Prefix string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// ListS3Objects returns the keys of the objects with the prefix, and the common prefixes of the keys up to the next
+// delimiter if the delimiter isn't empty, like the directories of a directory
+func ListS3Objects(bucket S3Bucket, prefix string, delimiter string) ([]string, []string, error) {
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return nil, nil, fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	endpoint.Path = fmt.Sprintf("/%s", bucket.Bucket)
+	keys, prefixes := []string{}, []string{}
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": []string{"2"}, "prefix": []string{prefix}}
+		if len(delimiter) > 0 {
+			query.Set("delimiter", delimiter)
+		}
+		if len(continuationToken) > 0 {
+			query.Set("continuation-token", continuationToken)
+		}
+		endpoint.RawQuery = query.Encode()
+		req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		SignS3Request(req, bucket, time.Now().UTC())
+		resp, err := (&http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to list '%s' in S3 bucket '%s' due to %+v", prefix, bucket.Bucket, err)
+		}
+		result := s3ListBucketResult{}
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			err = fmt.Errorf("S3 bucket '%s' doesn't exist at '%s'", bucket.Bucket, bucket.Endpoint)
+		case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+			err = fmt.Errorf("listing S3 bucket '%s' at '%s' is denied, check the credentials and the region", bucket.Bucket, bucket.Endpoint)
+		case resp.StatusCode/100 != 2:
+			err = fmt.Errorf("unexpected status '%s' listing '%s' in S3 bucket '%s'", resp.Status, prefix, bucket.Bucket)
+		default:
+			if err = xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+				err = fmt.Errorf("unable to parse the list of '%s' in S3 bucket '%s' due to %+v", prefix, bucket.Bucket, err)
+			}
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		for _, commonPrefix := range result.CommonPrefixes {
+			prefixes = append(prefixes, commonPrefix.Prefix)
+		}
+		if !result.IsTruncated || len(result.NextContinuationToken) == 0 {
+			return keys, prefixes, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// DeleteS3Object deletes the object with the key of the bucket, deleting a missing object succeeds
+func DeleteS3Object(bucket S3Bucket, key string) error {
+	endpoint, err := url.Parse(bucket.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return fmt.Errorf("invalid S3 endpoint '%s'", bucket.Endpoint)
+	}
+	endpoint.Path = fmt.Sprintf("/%s/%s", bucket.Bucket, strings.TrimPrefix(key, "/"))
+	req, err := http.NewRequest(http.MethodDelete, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	SignS3Request(req, bucket, time.Now().UTC())
+	resp, err := (&http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}).Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to delete '%s' from S3 bucket '%s' due to %+v", key, bucket.Bucket, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("deleting '%s' from S3 bucket '%s' is denied, check the credentials and the region", key, bucket.Bucket)
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("unexpected status '%s' deleting '%s' from S3 bucket '%s'", resp.Status, key, bucket.Bucket)
+	}
+	return nil
+}
+
+// SignS3Request adds the AWS signature version 4 headers of a request without body to the request
+func SignS3Request(req *http.Request, bucket S3Bucket, now time.Time) {
+	SignAWSRequest(req, AWSCredentials{AccessKeyID: bucket.AccessKeyID, SecretAccessKey: bucket.SecretAccessKey, SessionToken: bucket.SessionToken}, bucket.Region, "s3", now)
+}
+
+// AWSCredentials are the credentials of an AWS user or assumed role
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set with temporary credentials, e.g. of an assumed role
+	SessionToken string
+}
+
+// GetAWSCredentialsFromEnv returns the credentials of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+func GetAWSCredentialsFromEnv() AWSCredentials {
+	return AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// SignAWSRequest adds the AWS signature version 4 headers of a request without body of the service to the request
+func SignAWSRequest(req *http.Request, credentials AWSCredentials, region string, service string, now time.Time) {
+	signAWSRequestWithPayloadHash(req, credentials, region, service, emptyPayloadHash, now)
+}
+
+// signAWSRequestWithPayloadHash adds the AWS signature version 4 headers of a request with the hex encoded SHA256 hash
+// of its body to the request
+func signAWSRequestWithPayloadHash(req *http.Request, credentials AWSCredentials, region string, service string, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	if len(credentials.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", credentials.SessionToken)
+	}
+	path := req.URL.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{req.Method, path, req.URL.Query().Encode(), canonicalHeaders, signedHeaders, payloadHash}, "\n")
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(hashedRequest[:])}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(S3SigningKey(credentials.SecretAccessKey, date, region, service), stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// S3SigningKey derives the AWS signature version 4 signing key of a day, region and service
+func S3SigningKey(secretAccessKey string, date string, region string, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
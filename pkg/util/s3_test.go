@@ -0,0 +1,177 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestS3SigningKey(t *testing.T) {
+	// example of the AWS signature version 4 documentation
+	key := S3SigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	expected := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("expected signing key %s, got %s", expected, hex.EncodeToString(key))
+	}
+}
+
+func TestCheckS3Bucket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/uploads" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	defer server.Close()
+
+	bucket := S3Bucket{Endpoint: server.URL, Region: "us-east-1", Bucket: "uploads", AccessKeyID: "key", SecretAccessKey: "secret"}
+	if err := CheckS3Bucket(bucket); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+	bucket.Bucket = "missing"
+	if err := CheckS3Bucket(bucket); err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("expected a missing bucket error, got %+v", err)
+	}
+	bucket.AccessKeyID = ""
+	if err := CheckS3Bucket(bucket); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected an access denied error, got %+v", err)
+	}
+	bucket.Endpoint = "not a url"
+	if err := CheckS3Bucket(bucket); err == nil {
+		t.Errorf("expected an invalid endpoint error")
+	}
+}
+
+func TestPutS3Object(t *testing.T) {
+	uploaded := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		hash := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		uploaded[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	f, err := ioutil.TempFile("", "s3-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("dump")
+	f.Close()
+
+	bucket := S3Bucket{Endpoint: server.URL, Region: "us-east-1", Bucket: "backups", AccessKeyID: "key", SecretAccessKey: "secret"}
+	if err := PutS3Object(bucket, "blackduck/bds_hub.dump", f.Name()); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+	if uploaded["/backups/blackduck/bds_hub.dump"] != "dump" {
+		t.Errorf("expected the object to be uploaded, got %+v", uploaded)
+	}
+	bucket.AccessKeyID = "other"
+	if err := PutS3Object(bucket, "blackduck/bds_hub.dump", f.Name()); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected an access denied error, got %+v", err)
+	}
+}
+
+func TestGetS3Object(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/backups/blackduck/bds_hub.dump" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("dump"))
+	}))
+	defer server.Close()
+
+	bucket := S3Bucket{Endpoint: server.URL, Region: "us-east-1", Bucket: "backups"}
+	var buf bytes.Buffer
+	if err := GetS3Object(bucket, "blackduck/bds_hub.dump", &buf); err != nil || buf.String() != "dump" {
+		t.Errorf("expected the object, got '%s' and error %+v", buf.String(), err)
+	}
+	if err := GetS3Object(bucket, "blackduck/bdio.dump", &buf); err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("expected a missing object error, got %+v", err)
+	}
+}
+
+func TestListS3Objects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/backups" || r.URL.Query().Get("list-type") != "2" || r.URL.Query().Get("delimiter") != "/" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("continuation-token") == "" {
+			w.Write([]byte(`<ListBucketResult><Contents><Key>prod/notes.txt</Key></Contents><CommonPrefixes><Prefix>prod/blackduck-bd-20200601T000000Z/</Prefix></CommonPrefixes><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`))
+			return
+		}
+		w.Write([]byte(`<ListBucketResult><CommonPrefixes><Prefix>prod/blackduck-bd-20200602T000000Z/</Prefix></CommonPrefixes><IsTruncated>false</IsTruncated></ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	bucket := S3Bucket{Endpoint: server.URL, Region: "us-east-1", Bucket: "backups"}
+	keys, prefixes, err := ListS3Objects(bucket, "prod/", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "prod/notes.txt" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if len(prefixes) != 2 || prefixes[1] != "prod/blackduck-bd-20200602T000000Z/" {
+		t.Errorf("unexpected prefixes %v", prefixes)
+	}
+}
+
+func TestDeleteS3Object(t *testing.T) {
+	deleted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		deleted = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bucket := S3Bucket{Endpoint: server.URL, Region: "us-east-1", Bucket: "backups"}
+	if err := DeleteS3Object(bucket, "prod/bds_hub.dump"); err != nil || deleted != "/backups/prod/bds_hub.dump" {
+		t.Errorf("expected the object to be deleted, got '%s' and error %+v", deleted, err)
+	}
+}
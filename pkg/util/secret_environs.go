@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SecretEnviron is an environ whose value is read from the key of an existing secret instead of the config map of
+// the environs, so that passwords like the LDAP bind password or the SMTP password are not stored in plain text
+type SecretEnviron struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+	Key    string `json:"key"`
+	// Components restricts the environ to the workloads of the components, by default it is added to all the
+	// containers that read the config map of the environs
+	Components []string `json:"components,omitempty"`
+}
+
+// secretEnvironsHelmPath is the path of the secret environs in the Helm values. Like the extra volumes, the values are
+// not used by the charts, the environs are added by post-rendering the manifests
+var secretEnvironsHelmPath = []string{"synopsysctl", "secretEnvirons"}
+
+// ReadSecretEnvironsFile reads a JSON or YAML file mapping the names of the environs to their secret, key and optional
+// components, e.g. 'LDAP_BIND_PASSWORD: {secret: ldap-credentials, key: password}'
+func ReadSecretEnvironsFile(path string) ([]SecretEnviron, error) {
+	data, err := ReadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mapping := map[string]SecretEnviron{}
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse secret environs file '%s' due to %+v", path, err)
+	}
+	environs := []SecretEnviron{}
+	for name, environ := range mapping {
+		environ.Name = name
+		if err := environ.Validate(); err != nil {
+			return nil, err
+		}
+		environs = append(environs, environ)
+	}
+	sort.Slice(environs, func(i, j int) bool { return environs[i].Name < environs[j].Name })
+	return environs, nil
+}
+
+// Validate returns an error if the environ cannot be read from the secret
+func (e *SecretEnviron) Validate() error {
+	if errs := validation.IsEnvVarName(e.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid name of secret environ '%s': %s", e.Name, strings.Join(errs, ", "))
+	}
+	if len(e.Secret) == 0 || len(e.Key) == 0 {
+		return fmt.Errorf("secret environ '%s' requires a secret and a key", e.Name)
+	}
+	if errs := validation.IsDNS1123Subdomain(e.Secret); len(errs) > 0 {
+		return fmt.Errorf("invalid secret '%s' of secret environ '%s': %s", e.Secret, e.Name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsConfigMapKey(e.Key); len(errs) > 0 {
+		return fmt.Errorf("invalid key '%s' of secret environ '%s': %s", e.Key, e.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// SetSecretEnvironsInHelmValues stores the secret environs in the Helm values, replacing the previous ones. The plain
+// environs of the same names are removed, so that their values don't remain in the config map of the environs
+func SetSecretEnvironsInHelmValues(helmValues map[string]interface{}, environs []SecretEnviron) error {
+	if len(environs) == 0 {
+		deleteSynopsysctlHelmValue(helmValues, secretEnvironsHelmPath[1])
+		return nil
+	}
+	plainEnvirons, _ := GetHelmValueFromMap(helmValues, []string{"environs"}).(map[string]interface{})
+	for _, environ := range environs {
+		if _, ok := plainEnvirons[environ.Name]; ok {
+			log.Warnf("environ '%s' is read from secret '%s', its plain value is ignored", environ.Name, environ.Secret)
+			delete(plainEnvirons, environ.Name)
+		}
+	}
+
+	// store plain JSON types so the values can be compared and stored by Helm
+	data, err := json.Marshal(environs)
+	if err != nil {
+		return err
+	}
+	values := []interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	SetHelmValueInMap(helmValues, secretEnvironsHelmPath, values)
+	return nil
+}
+
+// GetSecretEnvironsFromHelmValues returns the secret environs stored in the Helm values
+func GetSecretEnvironsFromHelmValues(helmValues map[string]interface{}) ([]SecretEnviron, error) {
+	environs := []SecretEnviron{}
+	values := GetHelmValueFromMap(helmValues, secretEnvironsHelmPath)
+	if values == nil {
+		return environs, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &environs); err != nil {
+		return nil, fmt.Errorf("invalid secret environs in the values due to %+v", err)
+	}
+	return environs, nil
+}
+
+// secretEnvironsPostRenderer adds the secret environs to the containers of the workloads
+type secretEnvironsPostRenderer struct {
+	environs []SecretEnviron
+}
+
+// Run implements postrender.PostRenderer
+func (r *secretEnvironsPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	objects, err := SplitManifests(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+	}
+	added := map[string]bool{}
+	output := &bytes.Buffer{}
+	for _, object := range objects {
+		if podSpec := getWorkloadPodSpec(object); podSpec != nil {
+			for _, environ := range r.environs {
+				if addSecretEnvironToPodSpec(object, podSpec, environ) {
+					added[environ.Name] = true
+				}
+			}
+		}
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(output, "---\n%s\n", strings.TrimRight(string(data), "\n"))
+	}
+	for _, environ := range r.environs {
+		if !added[environ.Name] {
+			return nil, fmt.Errorf("secret environ '%s' does not match any container", environ.Name)
+		}
+	}
+	return output, nil
+}
+
+// addSecretEnvironToPodSpec adds the environ to the containers of the workload it applies to, replacing an environ of
+// the same name. It returns true if the environ was added to a container
+func addSecretEnvironToPodSpec(object map[string]interface{}, podSpec map[string]interface{}, environ SecretEnviron) bool {
+	allContainers := false
+	if len(environ.Components) > 0 {
+		if spec, _ := getWorkloadPodSpecAndComponent(object, environ.Components); spec == nil {
+			return false
+		}
+		allContainers = true
+	}
+	env := map[string]interface{}{
+		"name":      environ.Name,
+		"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": environ.Secret, "key": environ.Key}},
+	}
+	added := false
+	containers, _ := podSpec["containers"].([]interface{})
+	for _, container := range containers {
+		c, ok := container.(map[string]interface{})
+		if !ok || (!allContainers && !readsConfigMapEnvirons(c)) {
+			continue
+		}
+		envs, _ := c["env"].([]interface{})
+		replaced := []interface{}{}
+		for _, e := range envs {
+			if m, ok := e.(map[string]interface{}); ok && m["name"] == environ.Name {
+				continue
+			}
+			replaced = append(replaced, e)
+		}
+		c["env"] = append(replaced, env)
+		added = true
+	}
+	return added
+}
+
+// readsConfigMapEnvirons returns true if the container reads its environs from a config map
+func readsConfigMapEnvirons(container map[string]interface{}) bool {
+	envFrom, _ := container["envFrom"].([]interface{})
+	for _, source := range envFrom {
+		if s, ok := source.(map[string]interface{}); ok && s["configMapRef"] != nil {
+			return true
+		}
+	}
+	return false
+}
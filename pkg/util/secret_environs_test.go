@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSecretEnvironsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret-environs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "environs.yaml")
+	data := "SMTP_PASSWORD: {secret: smtp, key: password, components: [webapp]}\nLDAP_BIND_PASSWORD: {secret: ldap, key: password}\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	environs, err := ReadSecretEnvironsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(environs) != 2 || environs[0].Name != "LDAP_BIND_PASSWORD" || environs[1].Name != "SMTP_PASSWORD" || environs[1].Components[0] != "webapp" {
+		t.Errorf("unexpected secret environs %+v", environs)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("LDAP_BIND_PASSWORD: {secret: ldap}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSecretEnvironsFile(path); err == nil {
+		t.Errorf("expected an error for a secret environ without a key")
+	}
+}
+
+func TestSecretEnvironsPostRenderer(t *testing.T) {
+	manifests := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webapp
+  labels:
+    component: webapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: webapp
+        envFrom:
+        - configMapRef:
+            name: bd-blackduck-config
+        env:
+        - name: LDAP_BIND_PASSWORD
+          value: plain
+      - name: logstash
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-postgres
+spec:
+  template:
+    spec:
+      containers:
+      - name: postgres
+`
+	helmValues := map[string]interface{}{"environs": map[string]interface{}{"LDAP_BIND_PASSWORD": "plain", "HUB_TIMEZONE": "UTC"}}
+	environs := []SecretEnviron{{Name: "LDAP_BIND_PASSWORD", Secret: "ldap", Key: "password"}, {Name: "SMTP_PASSWORD", Secret: "smtp", Key: "password", Components: []string{"webapp"}}}
+	if err := SetSecretEnvironsInHelmValues(helmValues, environs); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := helmValues["environs"].(map[string]interface{})["LDAP_BIND_PASSWORD"]; ok {
+		t.Errorf("expected the plain environ to be removed from the values")
+	}
+	renderer, err := getHelmPostRenderer(helmValues)
+	if err != nil || renderer == nil {
+		t.Fatalf("expected a post-renderer, got %+v", err)
+	}
+	output, err := renderer.Run(bytes.NewBufferString(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := output.String()
+	if strings.Count(rendered, "name: LDAP_BIND_PASSWORD") != 1 || strings.Contains(rendered, "value: plain") {
+		t.Errorf("plain environ not replaced by the secret environ:\n%s", rendered)
+	}
+	if strings.Count(rendered, "name: SMTP_PASSWORD") != 2 || strings.Count(rendered, "secretKeyRef") != 3 {
+		t.Errorf("component environ not added to all the containers of the webapp:\n%s", rendered)
+	}
+
+	if err := SetSecretEnvironsInHelmValues(helmValues, []SecretEnviron{{Name: "UNUSED", Secret: "s", Key: "k", Components: []string{"jobrunner"}}}); err != nil {
+		t.Fatal(err)
+	}
+	renderer, _ = getHelmPostRenderer(helmValues)
+	if _, err := renderer.Run(bytes.NewBufferString(manifests)); err == nil {
+		t.Errorf("expected an error for a secret environ that matches no container")
+	}
+}
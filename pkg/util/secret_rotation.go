@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RestartedAtAnnotation is the annotation of the pod template that is changed to restart the pods of a deployment
+const RestartedAtAnnotation = "synopsys.com/restartedAt"
+
+// PostgresAlterPasswordSQL returns the statement that sets the password of a Postgres role
+func PostgresAlterPasswordSQL(role string, password string) string {
+	return fmt.Sprintf("ALTER ROLE \"%s\" WITH PASSWORD '%s';\n", strings.Replace(role, `"`, `""`, -1), strings.Replace(password, "'", "''", -1))
+}
+
+// OrderDeploymentsForRestart returns the names of the deployments in the order of their component label. The
+// deployments of a component that isn't in the order are restarted last, sorted by name
+func OrderDeploymentsForRestart(deployments []appsv1.Deployment, order []string) []string {
+	position := func(deployment appsv1.Deployment) int {
+		for i, component := range order {
+			if deployment.Labels["component"] == component {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sorted := append([]appsv1.Deployment{}, deployments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if position(sorted[i]) != position(sorted[j]) {
+			return position(sorted[i]) < position(sorted[j])
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	names := make([]string, 0, len(sorted))
+	for _, deployment := range sorted {
+		names = append(names, deployment.Name)
+	}
+	return names
+}
+
+// IsDeploymentRolledOut returns true if the controller observed the latest spec of the deployment and all its
+// replicas run it and are ready
+func IsDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.ReadyReplicas >= replicas &&
+		deployment.Status.Replicas <= replicas
+}
+
+// RestartDeployment replaces the pods of a deployment by changing the restart annotation of its pod template
+func RestartDeployment(clientset *kubernetes.Clientset, namespace string, name string) error {
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"%s":"%s"}}}}}`, RestartedAtAnnotation, time.Now().UTC().Format(time.RFC3339))
+	if _, err := clientset.AppsV1().Deployments(namespace).Patch(name, types.StrategicMergePatchType, []byte(patch)); err != nil {
+		return fmt.Errorf("unable to restart deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	return nil
+}
+
+// WaitForDeploymentRollout waits until the deployment is rolled out
+func WaitForDeploymentRollout(clientset *kubernetes.Clientset, namespace string, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to get deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		if IsDeploymentRolledOut(deployment) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return WithExitCode(ExitCodeTimeout, fmt.Errorf("deployment '%s' in namespace '%s' isn't rolled out after %s", name, namespace, timeout))
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
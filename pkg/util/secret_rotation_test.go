@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPostgresAlterPasswordSQL(t *testing.T) {
+	assert.Equal(t, "ALTER ROLE \"blackduck_user\" WITH PASSWORD 'abc';\n", PostgresAlterPasswordSQL("blackduck_user", "abc"))
+	assert.Equal(t, "ALTER ROLE \"a\"\"b\" WITH PASSWORD 'it''s';\n", PostgresAlterPasswordSQL(`a"b`, "it's"))
+}
+
+func TestOrderDeploymentsForRestart(t *testing.T) {
+	deployment := func(name string, component string) appsv1.Deployment {
+		return appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"component": component}}}
+	}
+	deployments := []appsv1.Deployment{
+		deployment("bd-blackduck-webserver", "webserver"),
+		deployment("bd-blackduck-scan", "scan"),
+		deployment("bd-blackduck-documentation", "documentation"),
+		deployment("bd-blackduck-authentication", "authentication"),
+		deployment("bd-blackduck-cfssl", "cfssl"),
+	}
+	assert.Equal(t, []string{
+		"bd-blackduck-authentication",
+		"bd-blackduck-scan",
+		"bd-blackduck-webserver",
+		"bd-blackduck-cfssl",
+		"bd-blackduck-documentation",
+	}, OrderDeploymentsForRestart(deployments, []string{"authentication", "scan", "webserver"}))
+}
+
+func TestIsDeploymentRolledOut(t *testing.T) {
+	assert := assert.New(t)
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+	}
+	assert.True(IsDeploymentRolledOut(deployment))
+
+	deployment.Status.ObservedGeneration = 2
+	assert.False(IsDeploymentRolledOut(deployment))
+
+	deployment.Status.ObservedGeneration = 3
+	deployment.Status.Replicas = 3
+	assert.False(IsDeploymentRolledOut(deployment), "an old pod is still terminating")
+
+	deployment.Status.Replicas = 2
+	deployment.Status.UpdatedReplicas = 1
+	assert.False(IsDeploymentRolledOut(deployment))
+}
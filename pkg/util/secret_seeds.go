@@ -0,0 +1,211 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretSeedsPassphraseEnv is the environment variable of the passphrase that encrypts a seed secrets file
+const SecretSeedsPassphraseEnv = "SYNOPSYSCTL_SEED_SECRETS_PASSPHRASE"
+
+// secretSeedsVersion is the version of the format of a seed secrets file
+const secretSeedsVersion = "v1"
+
+// secretSeedsIterations is the number of PBKDF2 iterations that derive the key of a seed secrets file from the passphrase
+const secretSeedsIterations = 100000
+
+// secretSeedsFile is the content of a seed secrets file. The data is the JSON of the credentials by Helm value path,
+// e.g. postgres.adminPassword, encrypted with AES-256-GCM and a key derived from the passphrase
+type secretSeedsFile struct {
+	Version    string `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// GetSecretSeedPath returns the key of the Helm value path in a seed secrets file, e.g. postgres.adminPassword
+func GetSecretSeedPath(valuePath []string) string {
+	return strings.Join(valuePath, ".")
+}
+
+// EncryptSecretSeeds returns the content of a seed secrets file of the credentials by Helm value path
+func EncryptSecretSeeds(seeds map[string]string, passphrase string) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ValidationError("the passphrase of the seed secrets file is empty")
+	}
+	plaintext, err := json.Marshal(seeds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal the seed secrets due to %+v", err)
+	}
+	file := secretSeedsFile{Version: secretSeedsVersion, Iterations: secretSeedsIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return nil, fmt.Errorf("unable to generate the salt of the seed secrets due to %+v", err)
+	}
+	gcm, err := newSecretSeedsCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate the nonce of the seed secrets due to %+v", err)
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plaintext, []byte(file.Version))
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// DecryptSecretSeeds returns the credentials by Helm value path of the content of a seed secrets file
+func DecryptSecretSeeds(data []byte, passphrase string) (map[string]string, error) {
+	if len(passphrase) == 0 {
+		return nil, ValidationError("the passphrase of the seed secrets file is empty")
+	}
+	file := secretSeedsFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, ValidationError("the seed secrets file is invalid due to %+v", err)
+	}
+	if file.Version != secretSeedsVersion {
+		return nil, ValidationError("version '%s' of the seed secrets file is not supported", file.Version)
+	}
+	if file.Iterations <= 0 || len(file.Salt) == 0 {
+		return nil, ValidationError("the seed secrets file has no key derivation parameters")
+	}
+	gcm, err := newSecretSeedsCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, ValidationError("the nonce of the seed secrets file is invalid")
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, []byte(file.Version))
+	if err != nil {
+		return nil, ValidationError("unable to decrypt the seed secrets file, the passphrase is wrong or the file was modified")
+	}
+	seeds := map[string]string{}
+	if err := json.Unmarshal(plaintext, &seeds); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the seed secrets due to %+v", err)
+	}
+	return seeds, nil
+}
+
+// ApplySecretSeeds sets the Helm values of the lookups from the seed secrets, so that the chart uses them instead of
+// generating new credentials. Values that are already set, e.g. by a flag, are not overwritten. Seeds that don't match a
+// lookup are ignored with a warning
+func ApplySecretSeeds(seeds map[string]string, lookups []RenderLookup, vals map[string]interface{}) []string {
+	applied := []string{}
+	known := map[string]bool{}
+	for _, lookup := range lookups {
+		path := GetSecretSeedPath(lookup.ValuePath)
+		known[path] = true
+		value, ok := seeds[path]
+		if !ok {
+			continue
+		}
+		if GetHelmValueFromMap(vals, lookup.ValuePath) != nil {
+			log.Debugf("'%s' is already set, the seed secret is not used", path)
+			continue
+		}
+		SetHelmValueInMap(vals, lookup.ValuePath, value)
+		applied = append(applied, path)
+	}
+	for _, path := range sortedSecretSeedPaths(seeds) {
+		if !known[path] {
+			log.Warnf("the seed secret '%s' is not a generated credential of the instance and is ignored", path)
+		}
+	}
+	return applied
+}
+
+// GetSecretSeeds returns the credentials of the lookups by Helm value path from the secrets of an existing instance
+func GetSecretSeeds(clientset *kubernetes.Clientset, namespace string, lookups []RenderLookup) (map[string]string, error) {
+	return getSecretSeeds(func(name string) (*corev1.Secret, error) {
+		return GetSecret(clientset, namespace, name)
+	}, lookups)
+}
+
+func getSecretSeeds(getSecret func(name string) (*corev1.Secret, error), lookups []RenderLookup) (map[string]string, error) {
+	vals := map[string]interface{}{}
+	if err := resolveRenderLookups(getSecret, lookups, vals); err != nil {
+		return nil, err
+	}
+	seeds := map[string]string{}
+	for _, lookup := range lookups {
+		if value, ok := GetHelmValueFromMap(vals, lookup.ValuePath).(string); ok {
+			seeds[GetSecretSeedPath(lookup.ValuePath)] = value
+		}
+	}
+	return seeds, nil
+}
+
+// sortedSecretSeedPaths returns the Helm value paths of the seed secrets in order
+func sortedSecretSeedPaths(seeds map[string]string) []string {
+	paths := []string{}
+	for path := range seeds {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// newSecretSeedsCipher returns the AES-256-GCM cipher of the key derived from the passphrase
+func newSecretSeedsCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, iterations, 32))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the cipher of the seed secrets due to %+v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key of keyLen bytes from the password with PBKDF2 and HMAC-SHA256 (RFC 8018)
+func pbkdf2SHA256(password []byte, salt []byte, iterations int, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := []byte{}
+	for block := 1; len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestEncryptSecretSeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	seeds := map[string]string{"postgres.adminPassword": "admin", "postgres.userPassword": "user"}
+	data, err := EncryptSecretSeeds(seeds, "passphrase")
+	assert.NoError(err)
+	assert.NotContains(string(data), "admin")
+
+	decrypted, err := DecryptSecretSeeds(data, "passphrase")
+	assert.NoError(err)
+	assert.Equal(seeds, decrypted)
+
+	_, err = DecryptSecretSeeds(data, "wrong")
+	assert.Error(err)
+	_, err = DecryptSecretSeeds([]byte("not json"), "passphrase")
+	assert.Error(err)
+	_, err = EncryptSecretSeeds(seeds, "")
+	assert.Error(err)
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// test vector of RFC 7914, section 11
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783", hex.EncodeToString(key))
+}
+
+func TestApplySecretSeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	lookups := []RenderLookup{
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_ADMIN_PASSWORD_FILE", ValuePath: []string{"postgres", "adminPassword"}},
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_USER_PASSWORD_FILE", ValuePath: []string{"postgres", "userPassword"}},
+	}
+	seeds := map[string]string{"postgres.adminPassword": "admin", "postgres.userPassword": "user", "unknown": "value"}
+	vals := map[string]interface{}{"postgres": map[string]interface{}{"userPassword": "explicit"}}
+
+	assert.Equal([]string{"postgres.adminPassword"}, ApplySecretSeeds(seeds, lookups, vals))
+	assert.Equal(map[string]interface{}{"postgres": map[string]interface{}{"adminPassword": "admin", "userPassword": "explicit"}}, vals)
+}
+
+func TestGetSecretSeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	getSecret := func(name string) (*corev1.Secret, error) {
+		if name == "bd-blackduck-db-creds" {
+			return &corev1.Secret{Data: map[string][]byte{"HUB_POSTGRES_ADMIN_PASSWORD_FILE": []byte("admin")}}, nil
+		}
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	lookups := []RenderLookup{
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_ADMIN_PASSWORD_FILE", ValuePath: []string{"postgres", "adminPassword"}},
+		{SecretName: "bd-blackduck-db-creds", Key: "HUB_POSTGRES_USER_PASSWORD_FILE", ValuePath: []string{"postgres", "userPassword"}},
+	}
+
+	seeds, err := getSecretSeeds(getSecret, lookups)
+	assert.NoError(err)
+	assert.Equal(map[string]string{"postgres.adminPassword": "admin"}, seeds)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StorageProbeMountPath is the path where the claim of a storage probe is mounted
+const StorageProbeMountPath = "/data"
+
+// StorageProbe is a job that runs a shell command against a new persistent volume claim, to check that the pods of an
+// instance will be able to use the storage before the instance is installed
+type StorageProbe struct {
+	Name            string
+	Namespace       string
+	StorageClass    string
+	ClaimSize       string
+	Image           string
+	Command         string
+	SecurityContext *corev1.PodSecurityContext
+	Timeout         time.Duration
+}
+
+// RunStorageProbe runs the probe and returns the logs of its pod. The claim and the job are always deleted
+func RunStorageProbe(clientset *kubernetes.Clientset, probe StorageProbe) (string, error) {
+	claim, err := newStorageProbeClaim(probe)
+	if err != nil {
+		return "", err
+	}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(probe.Namespace).Create(claim); err != nil {
+		return "", fmt.Errorf("unable to create persistent volume claim '%s' in namespace '%s' due to %+v", claim.Name, probe.Namespace, err)
+	}
+	defer func() {
+		if err := DeletePVC(clientset, probe.Namespace, claim.Name); err != nil && !k8serrors.IsNotFound(err) {
+			log.Warnf("unable to delete persistent volume claim '%s' in namespace '%s' due to %+v", claim.Name, probe.Namespace, err)
+		}
+	}()
+
+	job := newStorageProbeJob(probe)
+	if _, err := clientset.BatchV1().Jobs(probe.Namespace).Create(job); err != nil {
+		return "", fmt.Errorf("unable to create job '%s' in namespace '%s' due to %+v", job.Name, probe.Namespace, err)
+	}
+	defer func() {
+		propagation := metav1.DeletePropagationBackground
+		if err := clientset.BatchV1().Jobs(probe.Namespace).Delete(job.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !k8serrors.IsNotFound(err) {
+			log.Warnf("unable to delete job '%s' in namespace '%s' due to %+v", job.Name, probe.Namespace, err)
+		}
+	}()
+
+	failed := false
+	err = wait.PollImmediate(2*time.Second, probe.Timeout, func() (bool, error) {
+		current, err := clientset.BatchV1().Jobs(probe.Namespace).Get(job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		failed = current.Status.Failed > 0
+		return failed || current.Status.Succeeded > 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return "", WithExitCode(ExitCodeTimeout, fmt.Errorf("job '%s' in namespace '%s' didn't complete within %s, check that storage class '%s' can provision a volume", job.Name, probe.Namespace, probe.Timeout, probe.StorageClass))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to get job '%s' in namespace '%s' due to %+v", job.Name, probe.Namespace, err)
+	}
+
+	logs, err := getStorageProbeLogs(clientset, probe.Namespace, job.Name)
+	if err != nil {
+		return "", err
+	}
+	if failed {
+		return logs, fmt.Errorf("job '%s' in namespace '%s' failed: %s", job.Name, probe.Namespace, logs)
+	}
+	return logs, nil
+}
+
+// getStorageProbeLogs returns the logs of the pod of the job
+func getStorageProbeLogs(clientset *kubernetes.Clientset, namespace string, jobName string) (string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("job-name=%s", jobName)})
+	if err != nil {
+		return "", fmt.Errorf("unable to list the pods of job '%s' in namespace '%s' due to %+v", jobName, namespace, err)
+	}
+	if len(pods.Items) == 0 {
+		return "", fmt.Errorf("job '%s' in namespace '%s' has no pods", jobName, namespace)
+	}
+	logs, err := clientset.CoreV1().Pods(namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).DoRaw()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the logs of pod '%s' due to %+v", pods.Items[0].Name, err)
+	}
+	return strings.TrimSpace(string(logs)), nil
+}
+
+// newStorageProbeClaim returns the claim of the probe, the default storage class is used if the storage class is empty
+func newStorageProbeClaim(probe StorageProbe) (*corev1.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(probe.ClaimSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid claim size '%s' due to %+v", probe.ClaimSize, err)
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probe.Name,
+			Namespace: probe.Namespace,
+			Labels:    map[string]string{"app": "synopsysctl", "component": "storage-probe"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if len(probe.StorageClass) > 0 {
+		claim.Spec.StorageClassName = &probe.StorageClass
+	}
+	return claim, nil
+}
+
+// newStorageProbeJob returns the job of the probe with the claim mounted at StorageProbeMountPath
+func newStorageProbeJob(probe StorageProbe) *batchv1.Job {
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probe.Name,
+			Namespace: probe.Namespace,
+			Labels:    map[string]string{"app": "synopsysctl", "component": "storage-probe"},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					SecurityContext: probe.SecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:         "probe",
+							Image:        probe.Image,
+							Command:      []string{"/bin/sh", "-c", probe.Command},
+							VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: StorageProbeMountPath}},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: probe.Name},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewStorageProbeClaim(t *testing.T) {
+	assert := assert.New(t)
+
+	claim, err := newStorageProbeClaim(StorageProbe{Name: "probe", Namespace: "ns", ClaimSize: "1Gi"})
+	assert.NoError(err)
+	assert.Nil(claim.Spec.StorageClassName)
+	assert.Equal("1Gi", claim.Spec.Resources.Requests.Storage().String())
+
+	claim, err = newStorageProbeClaim(StorageProbe{Name: "probe", Namespace: "ns", ClaimSize: "1Gi", StorageClass: "nfs"})
+	assert.NoError(err)
+	assert.Equal("nfs", *claim.Spec.StorageClassName)
+
+	_, err = newStorageProbeClaim(StorageProbe{Name: "probe", Namespace: "ns", ClaimSize: "one"})
+	assert.Error(err)
+}
+
+func TestNewStorageProbeJob(t *testing.T) {
+	assert := assert.New(t)
+	fsGroup := int64(2000)
+	probe := StorageProbe{Name: "probe", Namespace: "ns", Image: "busybox", Command: "touch /data/x", SecurityContext: &corev1.PodSecurityContext{FSGroup: &fsGroup}}
+
+	job := newStorageProbeJob(probe)
+	assert.Equal(probe.SecurityContext, job.Spec.Template.Spec.SecurityContext)
+	assert.Equal([]string{"/bin/sh", "-c", "touch /data/x"}, job.Spec.Template.Spec.Containers[0].Command)
+	assert.Equal("probe", job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(int32(0), *job.Spec.BackoffLimit)
+}
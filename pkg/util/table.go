@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// TableColumn is a column of a table, a wide column is only printed with '-o wide'
+type TableColumn struct {
+	Name string
+	Wide bool
+}
+
+// TableOptions are the options of the output of a table
+type TableOptions struct {
+	// Wide prints the wide columns
+	Wide bool
+	// SortBy is the name of the column to sort the rows by, the rows keep their order if it's empty
+	SortBy string
+	// NoHeaders doesn't print the header row
+	NoHeaders bool
+}
+
+// Table is a kubectl-style table with one cell per column in each row
+type Table struct {
+	Columns []TableColumn
+	Rows    [][]string
+}
+
+// NewTable returns an empty table with the columns
+func NewTable(columns ...TableColumn) *Table {
+	return &Table{Columns: columns, Rows: [][]string{}}
+}
+
+// AddRow appends a row to the table, the cells are formatted with %v
+func (t *Table) AddRow(cells ...interface{}) {
+	row := make([]string, len(t.Columns))
+	for i := range row {
+		if i < len(cells) {
+			row[i] = fmt.Sprintf("%v", cells[i])
+		}
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// Print writes the table in aligned columns. The rows are sorted by the column of the options, which can be a wide
+// column, and the cells are never empty so that the output can be split on whitespace by awk or cut
+func (t *Table) Print(w io.Writer, options TableOptions) error {
+	rows := t.Rows
+	if len(options.SortBy) > 0 {
+		column, err := t.columnIndex(options.SortBy)
+		if err != nil {
+			return err
+		}
+		rows = append([][]string{}, t.Rows...)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return lessCell(rows[i][column], rows[j][column])
+		})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if !options.NoHeaders {
+		headers := []string{}
+		for _, column := range t.Columns {
+			if !column.Wide || options.Wide {
+				headers = append(headers, column.Name)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		cells := []string{}
+		for i, column := range t.Columns {
+			if column.Wide && !options.Wide {
+				continue
+			}
+			cell := row[i]
+			if len(cell) == 0 {
+				cell = "<none>"
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// columnIndex returns the index of the column with the name, ignoring the case and matching dashes and underscores
+// with spaces, e.g. 'app-version' matches 'APP VERSION'
+func (t *Table) columnIndex(name string) (int, error) {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(s)))
+	}
+	names := []string{}
+	for i, column := range t.Columns {
+		if normalize(column.Name) == normalize(name) {
+			return i, nil
+		}
+		names = append(names, strings.ToLower(strings.Replace(column.Name, " ", "-", -1)))
+	}
+	return 0, ValidationError("cannot sort by '%s', the columns are [%s]", name, strings.Join(names, "|"))
+}
+
+// lessCell compares two cells as numbers if both are numbers, and as strings otherwise
+func lessCell(a string, b string) bool {
+	numberA, errA := strconv.ParseFloat(a, 64)
+	numberB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return numberA < numberB
+	}
+	return a < b
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTablePrint(t *testing.T) {
+	assert := assert.New(t)
+
+	table := NewTable(TableColumn{Name: "NAME"}, TableColumn{Name: "PODS"}, TableColumn{Name: "CHART VERSION", Wide: true})
+	table.AddRow("b", 10, "1.0.0")
+	table.AddRow("a", 9, "")
+
+	var out bytes.Buffer
+	assert.NoError(table.Print(&out, TableOptions{}))
+	assert.Equal("NAME   PODS\nb      10\na      9\n", out.String())
+
+	out.Reset()
+	assert.NoError(table.Print(&out, TableOptions{Wide: true, SortBy: "pods"}))
+	assert.Equal("NAME   PODS   CHART VERSION\na      9      <none>\nb      10     1.0.0\n", out.String())
+
+	out.Reset()
+	assert.NoError(table.Print(&out, TableOptions{SortBy: "chart-version", NoHeaders: true}))
+	assert.Equal("a   9\nb   10\n", out.String())
+
+	out.Reset()
+	assert.NoError(table.Print(&out, TableOptions{SortBy: "NAME"}))
+	assert.Equal("NAME   PODS\na      9\nb      10\n", out.String())
+
+	err := table.Print(&out, TableOptions{SortBy: "age"})
+	assert.EqualError(err, "cannot sort by 'age', the columns are [name|pods|chart-version]")
+	assert.Equal(ExitCodeValidation, ExitCode(err))
+}
@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
 	"math/big"
 	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
+// CertificateExpiryWarningPeriod is the time before the expiry of a certificate when a warning is logged
+var CertificateExpiryWarningPeriod = 30 * 24 * time.Hour
+
 // GeneratePemSelfSignedCertificateAndKey returns a self-signed certificate and its key
 func GeneratePemSelfSignedCertificateAndKey(name pkix.Name) (string, string, error) {
 
@@ -62,3 +68,76 @@ func GeneratePemSelfSignedCertificateAndKey(name pkix.Name) (string, string, err
 func genx509SerialNumber() (*big.Int, error) {
 	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 }
+
+// ValidateCertificateAndKey verifies that the PEM certificate and key can be parsed, that the key belongs to the
+// certificate, that the certificate is currently valid and, if a hostname is given, that the certificate covers it
+func ValidateCertificateAndKey(certificatePEM []byte, keyPEM []byte, hostname string) error {
+	return validateCertificateAndKey(certificatePEM, keyPEM, hostname, time.Now())
+}
+
+func validateCertificateAndKey(certificatePEM []byte, keyPEM []byte, hostname string, now time.Time) error {
+	certificateBlock, _ := pem.Decode(certificatePEM)
+	if certificateBlock == nil || certificateBlock.Type != "CERTIFICATE" {
+		return fmt.Errorf("the certificate file does not contain a PEM encoded CERTIFICATE block")
+	}
+	certificate, err := x509.ParseCertificate(certificateBlock.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse the certificate: %+v", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return fmt.Errorf("the certificate key file does not contain a PEM encoded block")
+	}
+	if keyBlock.Type == "CERTIFICATE" {
+		return fmt.Errorf("the certificate key file contains a certificate instead of a private key")
+	}
+	if _, err := tls.X509KeyPair(certificatePEM, keyPEM); err != nil {
+		return fmt.Errorf("the certificate key does not match the certificate: %+v", err)
+	}
+
+	if now.Before(certificate.NotBefore) {
+		return fmt.Errorf("the certificate is not valid before %s", certificate.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("the certificate expired at %s", certificate.NotAfter.Format(time.RFC3339))
+	}
+	if now.Add(CertificateExpiryWarningPeriod).After(certificate.NotAfter) {
+		log.Warnf("the certificate expires soon at %s", certificate.NotAfter.Format(time.RFC3339))
+	}
+
+	if len(hostname) > 0 {
+		if err := certificate.VerifyHostname(hostname); err != nil {
+			return fmt.Errorf("the certificate does not cover the hostname '%s' (DNS names: %v, IP addresses: %v)", hostname, certificate.DNSNames, certificate.IPAddresses)
+		}
+	}
+	return nil
+}
+
+// GetEarliestCertificateExpiry returns the earliest expiry of the PEM certificates stored in the values, e.g. the data of a secret.
+// The boolean is false if none of the values contains a certificate
+func GetEarliestCertificateExpiry(values map[string][]byte) (time.Time, bool) {
+	earliest := time.Time{}
+	found := false
+	for _, value := range values {
+		for {
+			var block *pem.Block
+			block, value = pem.Decode(value)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+			if !found || certificate.NotAfter.Before(earliest) {
+				earliest = certificate.NotAfter
+				found = true
+			}
+		}
+	}
+	return earliest, found
+}
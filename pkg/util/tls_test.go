@@ -0,0 +1,141 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func generateTestCertificateAndKey(t *testing.T, dnsNames []string, notBefore time.Time, notAfter time.Time) ([]byte, []byte) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "synopsysctl-test"},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certOut := &bytes.Buffer{}
+	pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	keyOut := &bytes.Buffer{}
+	pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+	return certOut.Bytes(), keyOut.Bytes()
+}
+
+func TestValidateCertificateAndKey(t *testing.T) {
+	now := time.Now()
+	cert, key := generateTestCertificateAndKey(t, []string{"blackduck.example.com"}, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	_, otherKey := generateTestCertificateAndKey(t, []string{"blackduck.example.com"}, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiredCert, expiredKey := generateTestCertificateAndKey(t, []string{"blackduck.example.com"}, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	var tests = []struct {
+		description string
+		cert        []byte
+		key         []byte
+		hostname    string
+		expectedErr bool
+	}{
+		{
+			description: "valid certificate and key",
+			cert:        cert,
+			key:         key,
+			hostname:    "blackduck.example.com",
+		},
+		{
+			description: "valid certificate and key without a hostname",
+			cert:        cert,
+			key:         key,
+		},
+		{
+			description: "certificate is not PEM encoded",
+			cert:        []byte("not a certificate"),
+			key:         key,
+			expectedErr: true,
+		},
+		{
+			description: "certificate and key are swapped",
+			cert:        key,
+			key:         cert,
+			expectedErr: true,
+		},
+		{
+			description: "key doesn't match the certificate",
+			cert:        cert,
+			key:         otherKey,
+			expectedErr: true,
+		},
+		{
+			description: "certificate has expired",
+			cert:        expiredCert,
+			key:         expiredKey,
+			expectedErr: true,
+		},
+		{
+			description: "certificate doesn't cover the hostname",
+			cert:        cert,
+			key:         key,
+			hostname:    "alert.example.com",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateCertificateAndKey(test.cert, test.key, test.hostname, now)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("failed test case '%s': expected error %t, got %+v", test.description, test.expectedErr, err)
+		}
+	}
+}
+
+func TestGetEarliestCertificateExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	soonCert, soonKey := generateTestCertificateAndKey(t, nil, now.Add(-time.Hour), now.Add(24*time.Hour))
+	laterCert, _ := generateTestCertificateAndKey(t, nil, now.Add(-time.Hour), now.Add(48*time.Hour))
+
+	expiry, found := GetEarliestCertificateExpiry(map[string][]byte{
+		"tls.crt":  append(append([]byte{}, laterCert...), soonCert...),
+		"tls.key":  soonKey,
+		"password": []byte("not a certificate"),
+	})
+	if !found || !expiry.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("expected expiry %s, got %s (found %t)", now.Add(24*time.Hour), expiry, found)
+	}
+
+	if _, found := GetEarliestCertificateExpiry(map[string][]byte{"tls.key": soonKey}); found {
+		t.Errorf("expected no certificate to be found")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UpgradePolicy ...
+type UpgradePolicy string
+
+// Upgrade policies that can be declared per product
+const (
+	// UpgradePolicyLatest upgrades to the latest available version
+	UpgradePolicyLatest UpgradePolicy = "track-latest"
+	// UpgradePolicyTrackMinor upgrades to the latest version with the same major version
+	UpgradePolicyTrackMinor UpgradePolicy = "track-minor"
+	// UpgradePolicyTrackPatch upgrades to the latest version with the same major and minor version
+	UpgradePolicyTrackPatch UpgradePolicy = "track-patch"
+	// UpgradePolicyPinned never upgrades
+	UpgradePolicyPinned UpgradePolicy = "pinned"
+)
+
+var upgradePolicyVersionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// ParseUpgradePolicy returns the upgrade policy for the string. Besides the policy names, pin-major and pin-minor are accepted
+// as aliases of track-minor and track-patch, and an explicit version pins the product to that version
+func ParseUpgradePolicy(policy string) (UpgradePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case string(UpgradePolicyLatest), "latest":
+		return UpgradePolicyLatest, nil
+	case string(UpgradePolicyTrackMinor), "pin-major":
+		return UpgradePolicyTrackMinor, nil
+	case string(UpgradePolicyTrackPatch), "pin-minor":
+		return UpgradePolicyTrackPatch, nil
+	case string(UpgradePolicyPinned), "pin", "none":
+		return UpgradePolicyPinned, nil
+	}
+	if upgradePolicyVersionRegexp.MatchString(strings.TrimSpace(policy)) {
+		return UpgradePolicy(strings.TrimSpace(policy)), nil
+	}
+	return "", fmt.Errorf("invalid upgrade policy '%s', must be '%s', '%s', '%s', '%s' or a version", policy, UpgradePolicyLatest, UpgradePolicyTrackMinor, UpgradePolicyTrackPatch, UpgradePolicyPinned)
+}
+
+// SelectUpgradeVersion returns the version that an instance running the current version should be upgraded to
+// according to the policy. The boolean is false if no upgrade is needed
+func SelectUpgradeVersion(policy UpgradePolicy, currentVersion string, availableVersions []string) (string, bool) {
+	current := strings.Split(currentVersion, ".")
+	selected := currentVersion
+	for _, version := range availableVersions {
+		if CompareVersions(version, selected) <= 0 {
+			continue
+		}
+		candidate := strings.Split(version, ".")
+		switch policy {
+		case UpgradePolicyPinned:
+			continue
+		case UpgradePolicyLatest:
+		case UpgradePolicyTrackMinor:
+			if len(current) < 1 || len(candidate) < 1 || candidate[0] != current[0] {
+				continue
+			}
+		case UpgradePolicyTrackPatch:
+			if len(current) < 2 || len(candidate) < 2 || candidate[0] != current[0] || candidate[1] != current[1] {
+				continue
+			}
+		default:
+			if CompareVersions(version, string(policy)) != 0 {
+				continue
+			}
+		}
+		selected = version
+	}
+	return selected, selected != currentVersion
+}
+
+// GetAppVersionsFromChartURLs returns the application versions of all charts of the app in the chart URLs
+func GetAppVersionsFromChartURLs(chartURLs []string, appName string) []string {
+	versions := []string{}
+	found := map[string]bool{}
+	for _, url := range chartURLs {
+		packageNameSlice := ParsePackageName(url)
+		if packageNameSlice[0] == appName && len(packageNameSlice[1]) > 0 && !found[packageNameSlice[1]] {
+			found[packageNameSlice[1]] = true
+			versions = append(versions, packageNameSlice[1])
+		}
+	}
+	return versions
+}
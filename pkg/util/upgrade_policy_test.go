@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestParseUpgradePolicy(t *testing.T) {
+	var tests = []struct {
+		input      string
+		expected   UpgradePolicy
+		shouldFail bool
+	}{
+		{input: "track-minor", expected: UpgradePolicyTrackMinor},
+		{input: "pin-major", expected: UpgradePolicyTrackMinor},
+		{input: "Track-Patch", expected: UpgradePolicyTrackPatch},
+		{input: "latest", expected: UpgradePolicyLatest},
+		{input: "pinned", expected: UpgradePolicyPinned},
+		{input: "2020.6.1", expected: UpgradePolicy("2020.6.1")},
+		{input: "sometimes", shouldFail: true},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseUpgradePolicy(test.input)
+		if test.shouldFail != (err != nil) {
+			t.Errorf("input %s: unexpected error %+v", test.input, err)
+		}
+		if policy != test.expected {
+			t.Errorf("input %s: expected %s, got %s", test.input, test.expected, policy)
+		}
+	}
+}
+
+func TestSelectUpgradeVersion(t *testing.T) {
+	available := []string{"2020.4.0", "2020.4.2", "2020.6.0", "2020.6.1", "2021.2.0", "2019.12.0"}
+
+	var tests = []struct {
+		policy   UpgradePolicy
+		current  string
+		expected string
+		upgrade  bool
+	}{
+		{policy: UpgradePolicyLatest, current: "2020.4.0", expected: "2021.2.0", upgrade: true},
+		{policy: UpgradePolicyTrackMinor, current: "2020.4.0", expected: "2020.6.1", upgrade: true},
+		{policy: UpgradePolicyTrackPatch, current: "2020.4.0", expected: "2020.4.2", upgrade: true},
+		{policy: UpgradePolicyTrackPatch, current: "2020.4.2", expected: "2020.4.2", upgrade: false},
+		{policy: UpgradePolicyPinned, current: "2020.4.0", expected: "2020.4.0", upgrade: false},
+		{policy: UpgradePolicy("2020.6.0"), current: "2020.4.0", expected: "2020.6.0", upgrade: true},
+		{policy: UpgradePolicy("2019.12.0"), current: "2020.4.0", expected: "2020.4.0", upgrade: false},
+	}
+
+	for _, test := range tests {
+		version, upgrade := SelectUpgradeVersion(test.policy, test.current, available)
+		if version != test.expected || upgrade != test.upgrade {
+			t.Errorf("policy %s from %s: expected %s (%t), got %s (%t)", test.policy, test.current, test.expected, test.upgrade, version, upgrade)
+		}
+	}
+}
+
+func TestGetAppVersionsFromChartURLs(t *testing.T) {
+	chartURLs := []string{
+		"https://repo/blackduck-2020.4.0.tgz",
+		"https://repo/blackduck-2020.6.0.tgz",
+		"https://repo/synopsys-alert-5.3.1-12.tgz",
+		"https://repo/synopsys-alert-5.3.1-13.tgz",
+	}
+	if versions := GetAppVersionsFromChartURLs(chartURLs, "synopsys-alert"); len(versions) != 1 || versions[0] != "5.3.1" {
+		t.Errorf("unexpected Alert versions %+v", versions)
+	}
+	if versions := GetAppVersionsFromChartURLs(chartURLs, "blackduck"); len(versions) != 2 {
+		t.Errorf("unexpected Black Duck versions %+v", versions)
+	}
+}
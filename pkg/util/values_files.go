@@ -0,0 +1,46 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// ReadHelmValuesFiles returns the merged Helm values of YAML files, the values of a file take precedence over the values
+// of the files before it
+func ReadHelmValuesFiles(filePaths []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, filePath := range filePaths {
+		data, err := ReadFileData(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the values file '%s' due to %+v", filePath, err)
+		}
+		fileValues := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(data), &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to parse the values file '%s' due to %+v", filePath, err)
+		}
+		values = MergeMaps(values, fileValues)
+	}
+	return values, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadHelmValuesFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	base := filepath.Join(dir, "base.yaml")
+	if err := ioutil.WriteFile(base, []byte("postgres:\n  isExternal: false\n  claimSize: 150Gi\nsize: small\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	override := filepath.Join(dir, "override.yaml")
+	if err := ioutil.WriteFile(override, []byte("postgres:\n  claimSize: 300Gi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := ReadHelmValuesFiles([]string{base, override})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"postgres": map[string]interface{}{"isExternal": false, "claimSize": "300Gi"}, "size": "small"}, values)
+
+	values, err = ReadHelmValuesFiles(nil)
+	assert.NoError(err)
+	assert.Empty(values)
+
+	_, err = ReadHelmValuesFiles([]string{filepath.Join(dir, "missing.yaml")})
+	assert.Error(err)
+}
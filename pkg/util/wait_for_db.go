@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// waitForDBHelmPath is the path of the wait-for-db init container settings in the Helm values. Like the extra volumes,
+// the settings are not used by the charts, the init container is added by post-rendering the manifests
+var waitForDBHelmPath = []string{"synopsysctl", "waitForDB"}
+
+// waitForDBInitContainerName is the name of the injected init container
+const waitForDBInitContainerName = "synopsys-wait-for-db"
+
+// WaitForDBComponents are the Black Duck components that connect to the database
+var WaitForDBComponents = []string{"authentication", "bomengine", "jobrunner", "matchengine", "registration", "scan", "webapp"}
+
+// SetWaitForDBInitInHelmValues enables the wait-for-postgres init container with the image, or disables it
+func SetWaitForDBInitInHelmValues(helmValues map[string]interface{}, enabled bool, image string) {
+	if !enabled {
+		deleteSynopsysctlHelmValue(helmValues, waitForDBHelmPath[1])
+		return
+	}
+	SetHelmValueInMap(helmValues, waitForDBHelmPath, map[string]interface{}{"image": image})
+}
+
+// getWaitForDBPostRenderer returns the post-renderer injecting the wait-for-postgres init container, or nil if disabled
+func getWaitForDBPostRenderer(helmValues map[string]interface{}) *waitForDBPostRenderer {
+	settings, ok := GetHelmValueFromMap(helmValues, waitForDBHelmPath).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	image, _ := settings["image"].(string)
+	renderer := &waitForDBPostRenderer{image: image, port: "5432"}
+	if isExternal, _ := GetHelmValueFromMap(helmValues, []string{"postgres", "isExternal"}).(bool); isExternal {
+		renderer.host, _ = GetHelmValueFromMap(helmValues, []string{"postgres", "host"}).(string)
+	}
+	if port := GetHelmValueFromMap(helmValues, []string{"postgres", "port"}); port != nil {
+		renderer.port = fmt.Sprintf("%v", port)
+	}
+	return renderer
+}
+
+// waitForDBPostRenderer adds an init container waiting for postgres to accept connections to the components using the database
+type waitForDBPostRenderer struct {
+	image string
+	// host is empty for the internal database, it is taken from the rendered postgres service
+	host string
+	port string
+}
+
+// Run implements postrender.PostRenderer
+func (r *waitForDBPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	objects, err := SplitManifests(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rendered manifests due to %+v", err)
+	}
+	host := r.host
+	if len(host) == 0 {
+		for _, object := range objects {
+			metadata, _ := object["metadata"].(map[string]interface{})
+			if name, _ := metadata["name"].(string); object["kind"] == "Service" && strings.HasSuffix(name, "-postgres") {
+				host = name
+			}
+		}
+	}
+	if len(host) == 0 {
+		// no database to wait for
+		return renderedManifests, nil
+	}
+
+	initContainer := map[string]interface{}{
+		"name":    waitForDBInitContainerName,
+		"image":   r.image,
+		"command": []interface{}{"/bin/sh", "-c", fmt.Sprintf("until pg_isready -h %s -p %s; do echo waiting for database %s:%s; sleep 5; done", host, r.port, host, r.port)},
+	}
+
+	output := &bytes.Buffer{}
+	for _, object := range objects {
+		if podSpec, _ := getWorkloadPodSpecAndComponent(object, WaitForDBComponents); podSpec != nil {
+			initContainers, _ := podSpec["initContainers"].([]interface{})
+			podSpec["initContainers"] = append([]interface{}{initContainer}, initContainers...)
+		}
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(output, "---\n%s\n", strings.TrimRight(string(data), "\n"))
+	}
+	return output, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWaitForDBPostRenderer(t *testing.T) {
+	manifests := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-postgres
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-postgres
+spec:
+  template:
+    spec:
+      containers:
+      - name: postgres
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: webapp
+`
+	helmValues := map[string]interface{}{}
+	SetWaitForDBInitInHelmValues(helmValues, true, "postgres-client")
+	renderer := getWaitForDBPostRenderer(helmValues)
+	if renderer == nil {
+		t.Fatal("expected a post-renderer")
+	}
+	output, err := renderer.Run(bytes.NewBufferString(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := output.String()
+	if strings.Count(rendered, waitForDBInitContainerName) != 1 || !strings.Contains(rendered, "pg_isready -h bd-blackduck-postgres -p 5432") {
+		t.Errorf("init container not added to the webapp deployment only:\n%s", rendered)
+	}
+
+	SetWaitForDBInitInHelmValues(helmValues, false, "")
+	if getWaitForDBPostRenderer(helmValues) != nil {
+		t.Errorf("expected no post-renderer once disabled")
+	}
+}
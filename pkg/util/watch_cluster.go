@@ -22,12 +22,17 @@ under the License.
 package util
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -82,15 +87,16 @@ func WaitForPodsToBeRunningOrComplete(kubeClient *kubernetes.Clientset, namespac
 				return errors.Wrap(err, "failed to list pods")
 			}
 			// Check if all pods are running or succeeded
-			podsAreAllStartedOrSucceeded := true
+			started := 0
 			for _, po := range pods.Items {
-				podIsRunning := PodIsRunningOrComplete(po)
-				if !podIsRunning || !PodContainersAreRunning(po) {
-					podsAreAllStartedOrSucceeded = false
-					break
+				if PodIsRunningOrComplete(po) && PodContainersAreRunning(po) {
+					started++
 				}
 			}
-			if podsAreAllStartedOrSucceeded {
+			if len(pods.Items) > 0 {
+				EmitProgress(ProgressPhaseWait, "pods-running", started*100/len(pods.Items), "%d/%d pods are running or complete in namespace '%s'", started, len(pods.Items), namespace)
+			}
+			if started == len(pods.Items) {
 				return nil
 			}
 		}
@@ -235,26 +241,100 @@ func WaitForMoreThanNPods(kubeClient *kubernetes.Clientset, namespace string, la
 	}
 }
 
-// WaitForNamespaceToTerminate ...
-func WaitForNamespaceToTerminate(kubeClient *kubernetes.Clientset, namespace string) error {
-	timeout := time.NewTimer(2 * time.Minute) // fail after 2 minutes
-	ticker := time.NewTicker(1 * time.Second) // check every 1 second
-	defer ticker.Stop()
-	defer timeout.Stop()
+// namespaceDeletionConditions are the conditions of a terminating namespace that report what blocks its deletion
+var namespaceDeletionConditions = map[corev1.NamespaceConditionType]bool{
+	"NamespaceDeletionDiscoveryFailure":           true,
+	"NamespaceDeletionGroupVersionParsingFailure": true,
+	"NamespaceDeletionContentFailure":             true,
+	"NamespaceContentRemaining":                   true,
+	"NamespaceFinalizersRemaining":                true,
+}
 
+// NamespaceTerminationError is returned when a namespace didn't terminate in time, it lists the finalizers
+// and the remaining resources that block the deletion
+type NamespaceTerminationError struct {
+	Namespace  string
+	Finalizers []string
+	Blocking   []string
+}
+
+// Error ...
+func (e *NamespaceTerminationError) Error() string {
+	msg := fmt.Sprintf("namespace '%s' failed to terminate", e.Namespace)
+	if len(e.Finalizers) > 0 {
+		msg = fmt.Sprintf("%s, it is waiting for the finalizers %s", msg, strings.Join(e.Finalizers, ", "))
+	}
+	if len(e.Blocking) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, strings.Join(e.Blocking, "; "))
+	}
+	return msg
+}
+
+// NewNamespaceTerminationError returns the error for a namespace that is stuck in terminating
+func NewNamespaceTerminationError(ns *corev1.Namespace) *NamespaceTerminationError {
+	err := &NamespaceTerminationError{Namespace: ns.Name, Finalizers: []string{}, Blocking: []string{}}
+	for _, finalizer := range ns.Spec.Finalizers {
+		err.Finalizers = append(err.Finalizers, string(finalizer))
+	}
+	err.Finalizers = append(err.Finalizers, ns.Finalizers...)
+	for _, condition := range ns.Status.Conditions {
+		if namespaceDeletionConditions[condition.Type] && condition.Status == corev1.ConditionTrue {
+			err.Blocking = append(err.Blocking, condition.Message)
+		}
+	}
+	return err
+}
+
+// WaitForNamespaceToTerminate watches the namespace until it is deleted, the context is cancelled or the timeout
+// expires. A namespace that doesn't terminate in time returns a NamespaceTerminationError
+func WaitForNamespaceToTerminate(ctx context.Context, kubeClient *kubernetes.Clientset, namespace string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		ns, err := GetNamespace(kubeClient, namespace)
+		if err != nil && k8serrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("couldn't get namespace '%s' due to %+v", namespace, err)
+		}
+		watcher, err := kubeClient.CoreV1().Namespaces().Watch(metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", namespace).String(),
+			ResourceVersion: ns.ResourceVersion,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't watch namespace '%s' due to %+v", namespace, err)
+		}
+		deleted, err := watchNamespaceUntilDeleted(ctx, watcher, ns)
+		if err != nil || deleted {
+			return err
+		}
+		// the watch was closed by the API server, get the namespace again and start a new one
+	}
+}
+
+func watchNamespaceUntilDeleted(ctx context.Context, watcher watch.Interface, ns *corev1.Namespace) (bool, error) {
+	defer watcher.Stop()
 	for {
 		select {
-		case <-timeout.C:
-			return fmt.Errorf("namespace '%s' failed to terminate", namespace)
-		case <-ticker.C:
-			ns, err := GetNamespace(kubeClient, namespace)
-			if err != nil && k8serrors.IsNotFound(err) {
-				return nil
-			} else if err != nil {
-				return fmt.Errorf("%+v", err)
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return false, WithExitCode(ExitCodeTimeout, NewNamespaceTerminationError(ns))
 			}
-			if (ns == &corev1.Namespace{}) {
-				return nil
+			return false, fmt.Errorf("stopped waiting for namespace '%s' to terminate: %w", ns.Name, ctx.Err())
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			switch event.Type {
+			case watch.Deleted:
+				return true, nil
+			case watch.Added, watch.Modified:
+				if updated, ok := event.Object.(*corev1.Namespace); ok {
+					ns = updated
+				}
+			case watch.Error:
+				return false, nil
 			}
 		}
 	}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewNamespaceTerminationError(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "bd", Finalizers: []string{"example.com/cleanup"}},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: "NamespaceContentRemaining", Status: corev1.ConditionTrue, Message: "Some resources are remaining: persistentvolumeclaims. has 2 resource instances"},
+				{Type: "NamespaceDeletionDiscoveryFailure", Status: corev1.ConditionFalse, Message: "All resources successfully discovered"},
+			},
+		},
+	}
+	err := NewNamespaceTerminationError(ns)
+	assert.Equal(t, []string{"kubernetes", "example.com/cleanup"}, err.Finalizers)
+	assert.Equal(t, "namespace 'bd' failed to terminate, it is waiting for the finalizers kubernetes, example.com/cleanup: Some resources are remaining: persistentvolumeclaims. has 2 resource instances", err.Error())
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StoppedReplicasAnnotation keeps the replicas of a workload that synopsysctl scaled to zero, so that a start restores
+// them. It is only used for the charts without a status value
+const StoppedReplicasAnnotation = "synopsys.com/stopped-replicas"
+
+// InstanceWorkload is a deployment or stateful set of an instance with its desired replicas
+type InstanceWorkload struct {
+	Kind     string
+	Name     string
+	Replicas int32
+	// StoppedReplicas are the replicas before synopsysctl scaled the workload to zero, or 0 if it didn't
+	StoppedReplicas int32
+}
+
+// GetManifestWorkloads returns the deployments and stateful sets of the manifest of a release, sorted by kind and name.
+// Their replicas are not set
+func GetManifestWorkloads(manifest string) ([]InstanceWorkload, error) {
+	objects, err := SplitManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	workloads := []InstanceWorkload{}
+	for _, object := range objects {
+		u := &unstructured.Unstructured{Object: object}
+		if u.GetKind() == "Deployment" || u.GetKind() == "StatefulSet" {
+			workloads = append(workloads, InstanceWorkload{Kind: u.GetKind(), Name: u.GetName()})
+		}
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+	return workloads, nil
+}
+
+// GetInstanceWorkloads returns the deployments and stateful sets of the manifest of a release with their replicas in
+// the cluster, the workloads that don't exist are skipped
+func GetInstanceWorkloads(clientset *kubernetes.Clientset, namespace string, manifest string) ([]InstanceWorkload, error) {
+	workloads, err := GetManifestWorkloads(manifest)
+	if err != nil {
+		return nil, err
+	}
+	existing := []InstanceWorkload{}
+	for _, workload := range workloads {
+		var replicas *int32
+		var annotations map[string]string
+		if workload.Kind == "Deployment" {
+			deployment, err := clientset.AppsV1().Deployments(namespace).Get(workload.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("unable to get deployment '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+			}
+			replicas, annotations = deployment.Spec.Replicas, deployment.Annotations
+		} else {
+			statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(workload.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("unable to get stateful set '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+			}
+			replicas, annotations = statefulSet.Spec.Replicas, statefulSet.Annotations
+		}
+		// the replicas default to 1 if they are not set
+		workload.Replicas = 1
+		if replicas != nil {
+			workload.Replicas = *replicas
+		}
+		if stopped, err := strconv.Atoi(annotations[StoppedReplicasAnnotation]); err == nil {
+			workload.StoppedReplicas = int32(stopped)
+		}
+		existing = append(existing, workload)
+	}
+	return existing, nil
+}
+
+// workloadScalePatch returns the merge patch that stops or starts a workload and its new replicas. A stop keeps the
+// replicas in the annotation, a start restores them, or 1 replica if the workload wasn't stopped by synopsysctl
+func workloadScalePatch(workload InstanceWorkload, stop bool) ([]byte, int32, error) {
+	var annotation interface{}
+	var replicas int32
+	if stop {
+		if workload.Replicas == 0 {
+			return nil, 0, nil
+		}
+		annotation = strconv.Itoa(int(workload.Replicas))
+	} else {
+		if workload.Replicas > 0 {
+			return nil, workload.Replicas, nil
+		}
+		replicas = 1
+		if workload.StoppedReplicas > 0 {
+			replicas = workload.StoppedReplicas
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{StoppedReplicasAnnotation: annotation}},
+		"spec":     map[string]interface{}{"replicas": replicas},
+	})
+	return patch, replicas, err
+}
+
+// ScaleInstanceWorkload stops a workload by scaling it to zero, or starts it by restoring its replicas, for the charts
+// without a status value
+func ScaleInstanceWorkload(clientset *kubernetes.Clientset, namespace string, workload InstanceWorkload, stop bool) error {
+	patch, _, err := workloadScalePatch(workload, stop)
+	if err != nil || patch == nil {
+		return err
+	}
+	if workload.Kind == "Deployment" {
+		_, err = clientset.AppsV1().Deployments(namespace).Patch(workload.Name, types.MergePatchType, patch)
+	} else {
+		_, err = clientset.AppsV1().StatefulSets(namespace).Patch(workload.Name, types.MergePatchType, patch)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to scale %s '%s' in namespace '%s' due to %+v", workload.Kind, workload.Name, namespace, err)
+	}
+	return nil
+}
+
+// PrintWorkloadChanges prints the workloads whose replicas changed between before and after, and returns their number
+func PrintWorkloadChanges(out io.Writer, before []InstanceWorkload, after []InstanceWorkload) int {
+	replicasBefore := map[string]int32{}
+	for _, workload := range before {
+		replicasBefore[workload.Kind+"/"+workload.Name] = workload.Replicas
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	changed := 0
+	for _, workload := range after {
+		previous, ok := replicasBefore[workload.Kind+"/"+workload.Name]
+		if !ok || previous == workload.Replicas {
+			continue
+		}
+		if changed == 0 {
+			fmt.Fprintln(w, "KIND\tNAME\tREPLICAS")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d -> %d\n", workload.Kind, workload.Name, previous, workload.Replicas)
+		changed++
+	}
+	w.Flush()
+	return changed
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetManifestWorkloads(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: bd-blackduck-postgres
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webserver
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-webserver
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-authentication
+`
+	workloads, err := GetManifestWorkloads(manifest)
+	assert.NoError(err)
+	assert.Equal([]InstanceWorkload{
+		{Kind: "Deployment", Name: "bd-blackduck-authentication"},
+		{Kind: "Deployment", Name: "bd-blackduck-webserver"},
+		{Kind: "StatefulSet", Name: "bd-blackduck-postgres"},
+	}, workloads)
+}
+
+func TestWorkloadScalePatch(t *testing.T) {
+	assert := assert.New(t)
+
+	patch, replicas, err := workloadScalePatch(InstanceWorkload{Kind: "Deployment", Name: "web", Replicas: 3}, true)
+	assert.NoError(err)
+	assert.Equal(int32(0), replicas)
+	assert.JSONEq(`{"metadata":{"annotations":{"synopsys.com/stopped-replicas":"3"}},"spec":{"replicas":0}}`, string(patch))
+
+	// a stopped workload keeps the replicas of its first stop
+	patch, _, err = workloadScalePatch(InstanceWorkload{Kind: "Deployment", Name: "web", Replicas: 0, StoppedReplicas: 3}, true)
+	assert.NoError(err)
+	assert.Nil(patch)
+
+	patch, replicas, err = workloadScalePatch(InstanceWorkload{Kind: "Deployment", Name: "web", Replicas: 0, StoppedReplicas: 3}, false)
+	assert.NoError(err)
+	assert.Equal(int32(3), replicas)
+	assert.JSONEq(`{"metadata":{"annotations":{"synopsys.com/stopped-replicas":null}},"spec":{"replicas":3}}`, string(patch))
+
+	_, replicas, err = workloadScalePatch(InstanceWorkload{Kind: "StatefulSet", Name: "db", Replicas: 0}, false)
+	assert.NoError(err)
+	assert.Equal(int32(1), replicas)
+
+	patch, replicas, err = workloadScalePatch(InstanceWorkload{Kind: "StatefulSet", Name: "db", Replicas: 2}, false)
+	assert.NoError(err)
+	assert.Nil(patch)
+	assert.Equal(int32(2), replicas)
+}
+
+func TestPrintWorkloadChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	before := []InstanceWorkload{{Kind: "Deployment", Name: "web", Replicas: 2}, {Kind: "StatefulSet", Name: "db", Replicas: 1}, {Kind: "Deployment", Name: "idle", Replicas: 0}}
+	after := []InstanceWorkload{{Kind: "Deployment", Name: "web", Replicas: 0}, {Kind: "StatefulSet", Name: "db", Replicas: 0}, {Kind: "Deployment", Name: "idle", Replicas: 0}}
+
+	out := &bytes.Buffer{}
+	assert.Equal(2, PrintWorkloadChanges(out, before, after))
+	assert.Contains(out.String(), "KIND")
+	assert.Regexp(`Deployment\s+web\s+2 -> 0`, out.String())
+	assert.Regexp(`StatefulSet\s+db\s+1 -> 0`, out.String())
+	assert.NotContains(out.String(), "idle")
+
+	out.Reset()
+	assert.Equal(0, PrintWorkloadChanges(out, after, after))
+	assert.Empty(out.String())
+}